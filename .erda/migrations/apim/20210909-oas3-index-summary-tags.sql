@@ -0,0 +1,2 @@
+ALTER TABLE `dice_api_oas3_index` ADD `summary` varchar(191) NOT NULL DEFAULT '' COMMENT '.path.{path}.{method}.summary';
+ALTER TABLE `dice_api_oas3_index` ADD `tags` varchar(1024) NOT NULL DEFAULT '' COMMENT '.path.{path}.{method}.tags, 以逗号分隔并首尾加逗号, 便于按 tag 过滤';
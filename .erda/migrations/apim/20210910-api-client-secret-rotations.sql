@@ -0,0 +1,15 @@
+CREATE TABLE `dice_api_client_secret_rotations`
+(
+    `id`              bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+    `created_at`      datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+    `updated_at`      datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+    `creator_id`      varchar(191) NOT NULL COMMENT 'who rotated the secret',
+    `org_id`          bigint(20)   NOT NULL COMMENT 'org id',
+    `client_id`       bigint(20)   NOT NULL COMMENT 'dice_api_clients primary key',
+    `old_secret_hash` varchar(64)  NOT NULL DEFAULT '' COMMENT '旧密钥的 sha256, 宽限期结束后清空',
+    `expired_at`      datetime     NOT NULL COMMENT '旧密钥宽限期结束时间',
+    `invalidated`     tinyint(1)   NOT NULL DEFAULT 0 COMMENT '旧密钥是否已失效',
+    PRIMARY KEY (`id`),
+    KEY `idx_client_id` (`client_id`),
+    KEY `idx_expired_at` (`expired_at`, `invalidated`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='API 集市客户端密钥轮换记录表';
@@ -0,0 +1,4 @@
+ALTER TABLE `dice_api_client_secret_rotations`
+    ADD `kms_key_id` varchar(64) NOT NULL DEFAULT '' COMMENT 'kms key id of the new secret' AFTER `old_secret_hash`,
+    ADD `new_secret_ciphertext` varchar(1024) NOT NULL DEFAULT '' COMMENT '新密钥的 kms 密文, 宽限期结束切换到网关后清空' AFTER `kms_key_id`,
+    ADD KEY `idx_client_id_invalidated` (`client_id`, `invalidated`);
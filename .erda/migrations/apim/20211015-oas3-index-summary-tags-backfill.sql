@@ -0,0 +1,9 @@
+UPDATE `dice_api_oas3_index` AS `i`
+    JOIN `dice_api_oas3_fragment` AS `f` ON `f`.`index_id` = `i`.`id`
+SET `i`.`summary` = LEFT(IFNULL(JSON_UNQUOTE(JSON_EXTRACT(`f`.`operation`, '$.summary')), ''), 191),
+    `i`.`tags`    = IF(JSON_LENGTH(JSON_EXTRACT(`f`.`operation`, '$.tags')) > 0,
+                       LEFT(CONCAT(',', REPLACE(REPLACE(REPLACE(JSON_EXTRACT(`f`.`operation`, '$.tags'), '["', ''), '"]', ''), '", "', ','), ','), 1024),
+                       '')
+WHERE `i`.`summary` = ''
+  AND `i`.`tags` = ''
+  AND JSON_VALID(`f`.`operation`);
@@ -0,0 +1 @@
+ALTER TABLE `dice_config_item` ADD `kms_key_id` varchar(64) NOT NULL DEFAULT '' COMMENT 'kms key id, item_value is kms ciphertext when not empty';
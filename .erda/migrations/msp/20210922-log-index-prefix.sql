@@ -0,0 +1 @@
+ALTER TABLE sp_log_deployment ADD `index_prefix` varchar(64) NOT NULL DEFAULT '' COMMENT '日志索引前缀，为空时使用默认前缀';
@@ -0,0 +1,17 @@
+ALTER TABLE `ci_v3_build_caches` ADD COLUMN `hit_count` bigint(20) NOT NULL DEFAULT 0 COMMENT '缓存命中次数' AFTER `last_pull_at`;
+ALTER TABLE `ci_v3_build_caches` ADD COLUMN `miss_count` bigint(20) NOT NULL DEFAULT 0 COMMENT '缓存未命中次数' AFTER `hit_count`;
+
+CREATE TABLE `ci_v3_build_cache_stats`
+(
+    `id`           bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+    `name`         varchar(200) NOT NULL DEFAULT '' COMMENT '缓存名',
+    `cluster_name` varchar(200) NOT NULL DEFAULT '' COMMENT '集群名',
+    `stat_date`    varchar(16)  NOT NULL DEFAULT '' COMMENT '统计日期',
+    `hit_count`    bigint(20) NOT NULL DEFAULT 0 COMMENT '命中次数',
+    `miss_count`   bigint(20) NOT NULL DEFAULT 0 COMMENT '未命中次数',
+    `created_at`   datetime     DEFAULT NULL COMMENT '创建时间',
+    `updated_at`   datetime     DEFAULT NULL COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_cluster_name_date` (`cluster_name`, `name`, `stat_date`),
+    KEY            `idx_stat_date` (`stat_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='构建缓存每日命中统计';
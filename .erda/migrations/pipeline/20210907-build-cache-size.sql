@@ -0,0 +1 @@
+ALTER TABLE `ci_v3_build_caches` ADD COLUMN `size` bigint(20) NOT NULL DEFAULT 0 COMMENT '缓存镜像大小(字节)' AFTER `last_pull_at`;
@@ -0,0 +1,9 @@
+-- soft deleted caches are restored by upsert, so they are useless and conflict with the unique key
+DELETE FROM `ci_v3_build_caches` WHERE `deleted_at` IS NOT NULL;
+
+-- keep the earliest one of duplicated caches reported concurrently
+DELETE t1 FROM `ci_v3_build_caches` t1 JOIN `ci_v3_build_caches` t2
+    ON t1.`cluster_name` = t2.`cluster_name` AND t1.`name` = t2.`name` AND t1.`id` > t2.`id`;
+
+ALTER TABLE `ci_v3_build_caches` ADD COLUMN `last_push_at` datetime DEFAULT NULL COMMENT '缓存最近一次被推送的时间' AFTER `last_pull_at`;
+ALTER TABLE `ci_v3_build_caches` ADD UNIQUE INDEX `uk_cluster_name_name` (`cluster_name`, `name`);
@@ -0,0 +1 @@
+ALTER TABLE `dice_autotest_scene_step` ADD `run_condition` varchar(1024) NOT NULL DEFAULT '' COMMENT 'expression that decides whether the step runs';
@@ -0,0 +1,32 @@
+CREATE TABLE `dice_autotest_schedule` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `target_type` varchar(32) NOT NULL COMMENT 'scheduled target type, scene or sceneset',
+  `target_id` bigint(20) unsigned NOT NULL COMMENT 'scheduled scene or scene set id',
+  `space_id` bigint(20) unsigned NOT NULL COMMENT 'autotest space id',
+  `cron_expr` varchar(128) NOT NULL COMMENT 'cron expression',
+  `enabled` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'whether the schedule is enabled',
+  `config_namespace` varchar(255) NOT NULL DEFAULT '' COMMENT 'global config namespace used by scheduled runs',
+  `cluster_name` varchar(255) NOT NULL DEFAULT '' COMMENT 'cluster used by scheduled runs',
+  `next_fire_at` datetime DEFAULT NULL COMMENT 'next fire time',
+  `last_fire_at` datetime DEFAULT NULL COMMENT 'last fire time',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `updater_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'updater id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_target` (`target_type`, `target_id`),
+  KEY `idx_enabled_next_fire_at` (`enabled`, `next_fire_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene and scene set schedules';
+
+CREATE TABLE `dice_autotest_schedule_record` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `schedule_id` bigint(20) unsigned NOT NULL COMMENT 'schedule id',
+  `pipeline_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'triggered pipeline id',
+  `status` varchar(32) NOT NULL COMMENT 'trigger status, success, failed or skipped',
+  `message` varchar(1024) NOT NULL DEFAULT '' COMMENT 'trigger failure message',
+  `fired_at` datetime NOT NULL COMMENT 'fire time',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_schedule_id` (`schedule_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest schedule trigger records';
@@ -0,0 +1,39 @@
+CREATE TABLE `dice_autotest_scene_execution` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `scene_id` bigint(20) unsigned NOT NULL COMMENT 'scene id',
+  `space_id` bigint(20) unsigned NOT NULL COMMENT 'autotest space id',
+  `pipeline_id` bigint(20) unsigned NOT NULL COMMENT 'pipeline id of the execution',
+  `status` varchar(32) NOT NULL DEFAULT '' COMMENT 'execution status',
+  `cost_time_sec` bigint(20) NOT NULL DEFAULT '-1' COMMENT 'execution cost time in seconds',
+  `time_begin` datetime DEFAULT NULL COMMENT 'execution begin time',
+  `time_end` datetime DEFAULT NULL COMMENT 'execution end time',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_scene_id` (`scene_id`),
+  UNIQUE KEY `uk_pipeline_id` (`pipeline_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene execution history';
+
+CREATE TABLE `dice_autotest_scene_execution_step` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `execution_id` bigint(20) unsigned NOT NULL COMMENT 'scene execution id',
+  `step_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'scene step id',
+  `step_name` varchar(255) NOT NULL DEFAULT '' COMMENT 'scene step name',
+  `step_type` varchar(32) NOT NULL DEFAULT '' COMMENT 'scene step type',
+  `task_name` varchar(255) NOT NULL DEFAULT '' COMMENT 'pipeline task name',
+  `status` varchar(32) NOT NULL DEFAULT '' COMMENT 'step status',
+  `cost_time_sec` bigint(20) NOT NULL DEFAULT '-1' COMMENT 'step cost time in seconds',
+  `time_begin` datetime DEFAULT NULL COMMENT 'step begin time',
+  `time_end` datetime DEFAULT NULL COMMENT 'step end time',
+  `request` mediumtext COMMENT 'request snapshot',
+  `response` mediumtext COMMENT 'response snapshot',
+  `assert_success` varchar(16) NOT NULL DEFAULT '' COMMENT 'whether all asserts passed',
+  `assert_detail` text COMMENT 'assert result detail',
+  `message` varchar(1024) NOT NULL DEFAULT '' COMMENT 'error message',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_execution_id` (`execution_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene execution step results and snapshots';
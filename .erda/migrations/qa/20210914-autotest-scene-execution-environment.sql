@@ -0,0 +1,3 @@
+ALTER TABLE `dice_autotest_scene_execution` ADD `environment` varchar(191) NOT NULL DEFAULT '' COMMENT 'environment name used by the execution';
+ALTER TABLE `dice_autotest_scene_execution` ADD `config_namespace` varchar(255) NOT NULL DEFAULT '' COMMENT 'global config namespace used by the execution';
+ALTER TABLE `dice_autotest_scene_execution` ADD `variable_names` varchar(1024) NOT NULL DEFAULT '' COMMENT 'names of variables overridden by the execution request';
@@ -0,0 +1,2 @@
+ALTER TABLE `dice_autotest_schedule` MODIFY `target_type` varchar(32) NOT NULL COMMENT 'scheduled target type, scene, sceneset or testplan';
+ALTER TABLE `dice_autotest_schedule` MODIFY `target_id` bigint(20) unsigned NOT NULL COMMENT 'scheduled scene, scene set or test plan id';
@@ -0,0 +1 @@
+ALTER TABLE `dice_test_plans` ADD `report_email` text COMMENT 'config of emailing test plan report after execution';
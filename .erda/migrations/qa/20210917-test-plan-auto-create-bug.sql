@@ -0,0 +1 @@
+ALTER TABLE `dice_test_plans` ADD `auto_create_bug` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'whether to create bugs automatically for failed cases';
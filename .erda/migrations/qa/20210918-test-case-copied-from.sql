@@ -0,0 +1 @@
+ALTER TABLE `dice_test_cases` ADD `copied_from_id` bigint(20) NOT NULL DEFAULT '0' COMMENT 'source testcase id when created by copy';
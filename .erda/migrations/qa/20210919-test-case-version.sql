@@ -0,0 +1,17 @@
+CREATE TABLE `dice_test_case_versions` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `test_case_id` bigint(20) unsigned NOT NULL COMMENT 'test case id',
+  `version` bigint(20) unsigned NOT NULL COMMENT 'version number of the test case, starts from 1',
+  `name` varchar(191) NOT NULL DEFAULT '' COMMENT 'test case name',
+  `priority` varchar(32) NOT NULL DEFAULT '' COMMENT 'test case priority',
+  `pre_condition` text COMMENT 'pre condition',
+  `step_and_results` longtext COMMENT 'steps and expected results',
+  `apis` longtext COMMENT 'api definitions',
+  `desc` text COMMENT 'description',
+  `operator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'user who made the change',
+  `restored_from_version` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'version restored from, 0 if not a restore',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_test_case_version` (`test_case_id`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='test case version history';
@@ -0,0 +1,11 @@
+CREATE TABLE `dice_test_recycle_bin_policies` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `project_id` bigint(20) unsigned NOT NULL COMMENT 'project id',
+  `retention_days` int(11) NOT NULL DEFAULT '0' COMMENT 'days to keep recycled test cases and test sets, 0 means never purge',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `updater_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'updater id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_project_id` (`project_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='recycle bin auto clean policy of test cases and test sets';
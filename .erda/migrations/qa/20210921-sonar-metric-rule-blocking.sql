@@ -0,0 +1 @@
+ALTER TABLE `qa_sonar_metric_rules` ADD `is_blocking` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'whether violating the rule fails the pipeline';
@@ -0,0 +1,28 @@
+CREATE TABLE `dice_autotest_scene_baseline` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `scene_id` bigint(20) unsigned NOT NULL COMMENT 'scene id',
+  `execution_id` bigint(20) unsigned NOT NULL COMMENT 'scene execution the baseline was taken from',
+  `ignore_paths` text COMMENT 'response field paths ignored when comparing, json array',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `updater_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'updater id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_scene_id` (`scene_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene response baseline';
+
+CREATE TABLE `dice_autotest_scene_baseline_step` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `baseline_id` bigint(20) unsigned NOT NULL COMMENT 'scene baseline id',
+  `step_id` bigint(20) unsigned NOT NULL COMMENT 'scene step id',
+  `step_name` varchar(255) NOT NULL DEFAULT '' COMMENT 'scene step name',
+  `response` mediumtext COMMENT 'baseline response snapshot',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_baseline_id` (`baseline_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene baseline step response snapshots';
+
+ALTER TABLE `dice_autotest_scene_execution` ADD `baseline_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'baseline compared with, 0 if not compared';
+ALTER TABLE `dice_autotest_scene_execution` ADD `baseline_drift` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'whether any step response differs from the baseline';
+ALTER TABLE `dice_autotest_scene_execution_step` ADD `baseline_diff` mediumtext COMMENT 'response differences from the baseline, json array';
@@ -0,0 +1,2 @@
+ALTER TABLE `dice_autotest_scene` ADD `tags` varchar(1024) NOT NULL DEFAULT '' COMMENT 'scene tags, json array';
+ALTER TABLE `dice_autotest_schedule` ADD `scene_tags` varchar(1024) NOT NULL DEFAULT '' COMMENT 'only run scenes with any of these tags when target is scene set, json array';
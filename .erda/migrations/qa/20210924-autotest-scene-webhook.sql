@@ -0,0 +1,33 @@
+CREATE TABLE `dice_autotest_scene_webhook` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `space_id` bigint(20) unsigned NOT NULL COMMENT 'autotest space id',
+  `scene_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'scene id, 0 means all scenes of the space',
+  `url` varchar(1024) NOT NULL COMMENT 'webhook url',
+  `enabled` tinyint(1) NOT NULL DEFAULT '1' COMMENT 'whether the webhook is enabled',
+  `kms_key_id` varchar(64) NOT NULL COMMENT 'kms key id used to encrypt the signing secret',
+  `secret_ciphertext` varchar(1024) NOT NULL COMMENT 'signing secret encrypted by kms',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `updater_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'updater id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_space_scene` (`space_id`, `scene_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene execution completion webhooks';
+
+CREATE TABLE `dice_autotest_scene_webhook_delivery` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `webhook_id` bigint(20) unsigned NOT NULL COMMENT 'webhook id',
+  `execution_id` bigint(20) unsigned NOT NULL COMMENT 'scene execution id',
+  `payload` text COMMENT 'delivered payload, json',
+  `status` varchar(32) NOT NULL COMMENT 'delivery status, pending, success or failed',
+  `attempts` int(11) NOT NULL DEFAULT '0' COMMENT 'delivery attempts',
+  `response_code` int(11) NOT NULL DEFAULT '0' COMMENT 'http status code of the last attempt',
+  `message` varchar(1024) NOT NULL DEFAULT '' COMMENT 'failure message of the last attempt',
+  `next_retry_at` datetime DEFAULT NULL COMMENT 'next delivery time while pending',
+  `delivered_at` datetime DEFAULT NULL COMMENT 'time of the successful delivery',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_webhook_id` (`webhook_id`),
+  KEY `idx_status_next_retry_at` (`status`, `next_retry_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene webhook delivery records';
@@ -0,0 +1,18 @@
+CREATE TABLE `dice_autotest_scene_template` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `project_id` bigint(20) unsigned NOT NULL COMMENT 'project id, templates can be instantiated into any space of the project',
+  `name` varchar(191) NOT NULL COMMENT 'template name',
+  `description` varchar(1024) NOT NULL DEFAULT '' COMMENT 'template description',
+  `source_scene_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'scene the template was last saved from',
+  `version` bigint(20) unsigned NOT NULL DEFAULT '1' COMMENT 'content version, increased on every save from scene',
+  `content` mediumtext COMMENT 'scene inputs, outputs and steps, json',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `updater_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'updater id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_project_name` (`project_id`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene templates';
+
+ALTER TABLE `dice_autotest_scene` ADD `template_id` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'template the scene was instantiated from, 0 if none';
+ALTER TABLE `dice_autotest_scene` ADD `template_version` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'template version the scene content was taken from';
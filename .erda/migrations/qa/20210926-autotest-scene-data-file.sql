@@ -0,0 +1,16 @@
+CREATE TABLE `dice_autotest_scene_data_file` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `space_id` bigint(20) unsigned NOT NULL COMMENT 'autotest space id',
+  `scene_id` bigint(20) unsigned NOT NULL COMMENT 'scene id the file is attached to',
+  `name` varchar(191) NOT NULL COMMENT 'file name',
+  `columns` varchar(4096) NOT NULL DEFAULT '' COMMENT 'csv header, json array',
+  `row_count` int(11) NOT NULL DEFAULT '0' COMMENT 'number of data rows',
+  `size` bigint(20) NOT NULL DEFAULT '0' COMMENT 'file size in bytes',
+  `content` mediumtext COMMENT 'csv content',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_scene_name` (`scene_id`, `name`),
+  KEY `idx_space_id` (`space_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='csv data files of autotest scenes used by loop steps';
@@ -0,0 +1,3 @@
+ALTER TABLE `dice_autotest_scene` ADD `step_timeout_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT 'default timeout of api steps in ms, 0 means no limit';
+ALTER TABLE `dice_autotest_scene_execution_step` ADD `timeout_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT 'configured step timeout in ms';
+ALTER TABLE `dice_autotest_scene_execution_step` ADD `duration_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT 'actual request duration in ms';
@@ -0,0 +1,13 @@
+CREATE TABLE `dice_autotest_scene_version` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `scene_id` bigint(20) unsigned NOT NULL COMMENT 'scene id',
+  `version` bigint(20) unsigned NOT NULL COMMENT 'version number of the scene, starts from 1',
+  `step_timeout_ms` bigint(20) NOT NULL DEFAULT '0' COMMENT 'default step timeout of the scene in milliseconds',
+  `content` mediumtext COMMENT 'scene inputs, outputs and steps, json',
+  `operator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'user who made the change',
+  `restored_from_version` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'version restored from, 0 if not a restore',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_scene_version` (`scene_id`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='autotest scene version history';
@@ -0,0 +1,11 @@
+CREATE TABLE `dice_test_plan_case_exec_histories` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `test_plan_id` bigint(20) unsigned NOT NULL COMMENT 'test plan id',
+  `test_plan_case_rel_id` bigint(20) unsigned NOT NULL COMMENT 'test plan case relation id',
+  `exec_status` varchar(20) NOT NULL DEFAULT '' COMMENT 'exec status after the change',
+  `operator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'user who changed the status, empty for api test results',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_test_plan_id` (`test_plan_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='test plan case exec status history';
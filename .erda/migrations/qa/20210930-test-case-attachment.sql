@@ -0,0 +1,16 @@
+CREATE TABLE `dice_test_case_attachments` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `project_id` bigint(20) unsigned NOT NULL COMMENT 'project id',
+  `test_case_id` bigint(20) unsigned NOT NULL COMMENT 'test case id',
+  `test_plan_case_rel_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'test plan case relation id of the execution result, 0 for test case attachments',
+  `name` varchar(255) NOT NULL DEFAULT '' COMMENT 'file name',
+  `content_type` varchar(191) NOT NULL DEFAULT '' COMMENT 'content type',
+  `size` bigint(20) NOT NULL DEFAULT 0 COMMENT 'file size in bytes',
+  `file_uuid` varchar(191) NOT NULL DEFAULT '' COMMENT 'uuid of the file in file service',
+  `url` varchar(1024) NOT NULL DEFAULT '' COMMENT 'download url',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_test_case_id` (`test_case_id`, `test_plan_case_rel_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='test case and execution result attachments';
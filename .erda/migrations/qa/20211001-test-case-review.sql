@@ -0,0 +1,18 @@
+ALTER TABLE `dice_test_cases` ADD `review_state` varchar(32) NOT NULL DEFAULT 'APPROVED' COMMENT 'review state: DRAFT, IN_REVIEW, APPROVED';
+ALTER TABLE `dice_test_cases` ALTER `review_state` SET DEFAULT 'DRAFT';
+
+ALTER TABLE `dice_test_plans` ADD `approved_cases_only` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'whether only approved test cases can be added';
+
+CREATE TABLE `dice_test_case_reviews` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `test_case_id` bigint(20) unsigned NOT NULL COMMENT 'test case id',
+  `action` varchar(32) NOT NULL DEFAULT '' COMMENT 'review action: SUBMIT, APPROVE, REJECT',
+  `from_state` varchar(32) NOT NULL DEFAULT '' COMMENT 'review state before the action',
+  `to_state` varchar(32) NOT NULL DEFAULT '' COMMENT 'review state after the action',
+  `operator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'operator id',
+  `comment` text COMMENT 'review comment, required when rejecting',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_test_case_id` (`test_case_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='test case review records';
@@ -0,0 +1,13 @@
+CREATE TABLE `dice_test_case_audit_logs` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `project_id` bigint(20) unsigned NOT NULL COMMENT 'project id',
+  `test_case_id` bigint(20) unsigned NOT NULL COMMENT 'test case id',
+  `action` varchar(32) NOT NULL DEFAULT '' COMMENT 'operation: UPDATE, MOVE, COPY, RECYCLE, RECOVER, DELETE',
+  `operator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'operator id',
+  `changes` mediumtext COMMENT 'field level changes in json',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_project_test_case` (`project_id`, `test_case_id`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='test case audit logs';
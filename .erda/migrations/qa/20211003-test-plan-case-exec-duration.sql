@@ -0,0 +1,15 @@
+CREATE TABLE `dice_test_plan_case_exec_durations` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `test_plan_id` bigint(20) unsigned NOT NULL COMMENT 'test plan id',
+  `test_plan_case_rel_id` bigint(20) unsigned NOT NULL COMMENT 'test plan case relation id',
+  `test_case_id` bigint(20) unsigned NOT NULL COMMENT 'test case id',
+  `source` varchar(32) NOT NULL DEFAULT '' COMMENT 'duration source: MANUAL, AUTOMATED',
+  `duration_sec` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'execution duration in seconds',
+  `pipeline_id` bigint(20) unsigned NOT NULL DEFAULT 0 COMMENT 'api test pipeline id, only for AUTOMATED',
+  `operator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'operator id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_test_case_id` (`test_case_id`),
+  KEY `idx_test_plan_id` (`test_plan_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='execution durations of test plan cases';
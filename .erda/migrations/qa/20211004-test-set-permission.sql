@@ -0,0 +1,13 @@
+CREATE TABLE `dice_test_set_permissions` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `project_id` bigint(20) unsigned NOT NULL COMMENT 'project id',
+  `test_set_id` bigint(20) unsigned NOT NULL COMMENT 'test set id',
+  `user_id` varchar(191) NOT NULL COMMENT 'user id',
+  `role` varchar(32) NOT NULL DEFAULT '' COMMENT 'role: Editor, Viewer, None',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_test_set_user` (`test_set_id`, `user_id`),
+  KEY `idx_project_user` (`project_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='test set permissions granted to users, inherited by sub test sets';
@@ -0,0 +1 @@
+ALTER TABLE `dice_test_plans` ADD `report_fields` text COMMENT 'custom fields rendered in test plan report';
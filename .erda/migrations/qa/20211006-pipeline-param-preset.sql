@@ -0,0 +1,16 @@
+CREATE TABLE `dice_pipeline_param_presets` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key id',
+  `project_id` bigint(20) unsigned NOT NULL COMMENT 'project id',
+  `app_id` bigint(20) unsigned NOT NULL COMMENT 'application id',
+  `branch` varchar(191) NOT NULL COMMENT 'branch of the pipeline definition',
+  `pipeline_yml_name` varchar(191) NOT NULL COMMENT 'pipeline yml path, e.g. pipeline.yml, .dice/pipelines/a.yml',
+  `name` varchar(191) NOT NULL COMMENT 'preset name',
+  `params` text NOT NULL COMMENT 'run params, json array of {name, value}',
+  `is_default` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'applied automatically when running without params',
+  `creator_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'creator id',
+  `updater_id` varchar(191) NOT NULL DEFAULT '' COMMENT 'updater id',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created time',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_pipeline_name` (`app_id`, `branch`, `pipeline_yml_name`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='named run param presets of pipeline definitions';
@@ -0,0 +1 @@
+ALTER TABLE `dice_test_cases` ADD `lock_version` bigint(20) unsigned NOT NULL DEFAULT '0' COMMENT 'optimistic lock version, increased by every update';
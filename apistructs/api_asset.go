@@ -292,6 +292,25 @@ func (m ClientModel) TableName() string {
 	return "dice_api_clients"
 }
 
+// ClientSecretRotationModel 客户端密钥轮换记录, 网关在 ExpiredAt 之前仍使用旧密钥, 之后切换为新密钥
+type ClientSecretRotationModel struct {
+	ID                  uint64    `json:"id"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+	CreatorID           string    `json:"creatorID"`
+	OrgID               uint64    `json:"orgID"`
+	ClientID            uint64    `json:"clientID"` // dice_api_clients 主键
+	OldSecretHash       string    `json:"-"`
+	KMSKeyID            string    `json:"-" gorm:"column:kms_key_id"`
+	NewSecretCiphertext string    `json:"-"` // 新密钥的 kms 密文, 切换到网关后清空
+	ExpiredAt           time.Time `json:"expiredAt"`
+	Invalidated         bool      `json:"invalidated"`
+}
+
+func (m ClientSecretRotationModel) TableName() string {
+	return "dice_api_client_secret_rotations"
+}
+
 // dice_api_contracts
 type ContractModel struct {
 	BaseModel
@@ -372,12 +391,22 @@ type APIOAS3IndexModel struct {
 	Method      string    `json:"method"`
 	OperationID string    `json:"operationID" gorm:"operation_id"`
 	Description string    `json:"description"`
+	Summary     string    `json:"summary"`
+	Tags        string    `json:"tags"` // 以逗号分隔并首尾加逗号, 如 ",pet,store,"
 }
 
 func (m APIOAS3IndexModel) TableName() string {
 	return "dice_api_oas3_index"
 }
 
+// JoinOAS3IndexTags 将 operation 的 tags 转换为索引表中的格式, 便于以 LIKE '%,tag,%' 过滤
+func JoinOAS3IndexTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
 type APIOAS3FragmentModel struct {
 	ID        uint64    `json:"id"`
 	CreatedAt time.Time `json:"createdAt" gorm:"created_at"`
@@ -351,6 +351,28 @@ type DownloadSpecTextQueryParams struct {
 	SpecProtocol string
 }
 
+// MockAPIAssetVersionReq 按 API 资料版本的文档生成 mock 响应
+type MockAPIAssetVersionReq struct {
+	OrgID     uint64
+	Identity  *IdentityInfo
+	AssetID   string
+	VersionID uint64
+	Method    string
+	Path      string // 相对于文档 paths 的请求路径
+	Status    string // 调用方指定的响应状态码
+	Accept    string
+	Latency   string // 调用方指定的响应延迟, 单位毫秒
+}
+
+// DiffAPIAssetVersionsReq 比较 API 资料的两个版本, 结果为从 BaseVersionID 变为 VersionID 的变更
+type DiffAPIAssetVersionsReq struct {
+	OrgID         uint64
+	Identity      *IdentityInfo
+	AssetID       string `json:"assetID"`
+	VersionID     uint64 `json:"versionID"`
+	BaseVersionID uint64 `json:"baseVersionID" schema:"baseVersionID"`
+}
+
 type CreateClientReq struct {
 	OrgID    uint64
 	Identity *IdentityInfo
@@ -386,6 +408,31 @@ type ClientObj struct {
 	SK     *SK          `json:"sk"`
 }
 
+// RotateClientSecretReq 轮换客户端密钥
+type RotateClientSecretReq struct {
+	OrgID     uint64
+	Identity  *IdentityInfo
+	URIParams *UpdateClientURIParams
+	Body      *RotateClientSecretBody
+}
+
+type RotateClientSecretBody struct {
+	// 旧密钥的宽限期, 单位秒, 为空时使用默认值
+	GracePeriodSec *uint64 `json:"gracePeriodSec"`
+}
+
+// RotateClientSecretRsp 新密钥只在此响应中返回一次, 在 OldSecretExpiredAt 时生效, 此前网关仍使用旧密钥
+type RotateClientSecretRsp struct {
+	SK                 *SK       `json:"sk"`
+	OldSecretExpiredAt time.Time `json:"oldSecretExpiredAt"`
+}
+
+type ListClientSecretRotationsReq struct {
+	OrgID     uint64
+	Identity  *IdentityInfo
+	URIParams *UpdateClientURIParams
+}
+
 type SK struct {
 	ClientID     string `json:"clientID"`
 	ClientSecret string `json:"clientSecret"`
@@ -496,6 +543,34 @@ type CreateAccessBody struct {
 	AddonInstanceID string         `json:"addonInstanceID"`
 }
 
+// 批量创建访问管理条目的参数结构
+type BatchCreateAccessReq struct {
+	OrgID    uint64
+	Identity *IdentityInfo
+	Body     *BatchCreateAccessBody
+}
+
+type BatchCreateAccessBody struct {
+	Accesses []*CreateAccessBody `json:"accesses"`
+}
+
+type BatchCreateAccessRsp struct {
+	Total        uint64                     `json:"total"`
+	SuccessCount uint64                     `json:"successCount"`
+	FailureCount uint64                     `json:"failureCount"`
+	Results      []*BatchCreateAccessResult `json:"results"`
+}
+
+// 批量创建中单个条目的创建结果, Index 为条目在请求列表中的下标
+type BatchCreateAccessResult struct {
+	Index   int               `json:"index"`
+	AssetID string            `json:"assetID"`
+	Major   uint64            `json:"major"`
+	Success bool              `json:"success"`
+	Access  *APIAccessesModel `json:"access,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
 type UpdateAccessReq struct {
 	OrgID     uint64
 	Identity  *IdentityInfo
@@ -702,6 +777,18 @@ type UpdateContractBody struct {
 	RequestSLAID *uint64         `json:"requestSLAID"`
 }
 
+// ContractApprovalReq 管理人员审批 (通过或拒绝) 调用申请
+type ContractApprovalReq struct {
+	OrgID     uint64
+	Identity  *IdentityInfo
+	URIParams *UpdateContractURIParams
+	Body      *ContractApprovalBody
+}
+
+type ContractApprovalBody struct {
+	Reason string `json:"reason"` // 审批理由, 拒绝时必填
+}
+
 type AttempTestURIParams struct {
 	AssetID        string
 	SwaggerVersion string
@@ -864,6 +951,51 @@ type SearchOperationQueryParameters struct {
 	Keyword string
 }
 
+// SearchAPIAssetsReq 在 API 资料和接口中统一搜索
+type SearchAPIAssetsReq struct {
+	OrgID       uint64
+	Identity    *IdentityInfo
+	QueryParams SearchAPIAssetsQueryParams
+}
+
+type SearchAPIAssetsQueryParams struct {
+	Keyword  string `json:"keyword" schema:"keyword"`   // 关键字, 以空格分隔的多个词须同时命中
+	Tag      string `json:"tag" schema:"tag"`           // 按接口 tag 过滤, 过滤后只返回接口
+	PageNo   uint64 `json:"pageNo" schema:"pageNo"`     // 页码
+	PageSize uint64 `json:"pageSize" schema:"pageSize"` // 每页数量
+}
+
+const (
+	SearchAPIAssetsResultTypeAsset     = "asset"
+	SearchAPIAssetsResultTypeOperation = "operation"
+)
+
+type SearchAPIAssetsRsp struct {
+	Total int                      `json:"total"`
+	List  []*SearchAPIAssetsResult `json:"list"`
+}
+
+// SearchAPIAssetsResult 一条搜索结果, 为 API 资料或接口
+type SearchAPIAssetsResult struct {
+	Type       string             `json:"type"`
+	Score      int                `json:"score"`
+	AssetID    string             `json:"assetID"`
+	AssetName  string             `json:"assetName"`
+	VersionID  uint64             `json:"versionID"`
+	Version    string             `json:"version"`
+	IndexID    uint64             `json:"indexID,omitempty"` // 接口索引 id, 可用于查询接口详情
+	Path       string             `json:"path,omitempty"`
+	Method     string             `json:"method,omitempty"`
+	Summary    string             `json:"summary,omitempty"`
+	Highlights []*SearchHighlight `json:"highlights"`
+}
+
+// SearchHighlight 命中的字段, Fragment 为转义后的 HTML, 命中的部分以 <em></em> 包裹
+type SearchHighlight struct {
+	Field    string `json:"field"`
+	Fragment string `json:"fragment"`
+}
+
 type GetOperationReq struct {
 	OrgID     uint64
 	Identity  *IdentityInfo
@@ -15,6 +15,7 @@
 package apistructs
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 )
@@ -30,6 +31,8 @@ type APIInfo struct {
 	Body      APIBody       `json:"body"`
 	OutParams []APIOutParam `json:"outParams"`
 	Asserts   [][]APIAssert `json:"asserts"`
+
+	Proxy *APIProxyConfig `json:"proxy,omitempty"` // 请求使用的代理, 为空时直连
 }
 
 type APIInfoV2 struct {
@@ -42,6 +45,42 @@ type APIInfoV2 struct {
 	Body      APIBody       `json:"body"`
 	OutParams []APIOutParam `json:"out_params"`
 	Asserts   []APIAssert   `json:"asserts"`
+
+	JsonPathAsserts []APIJsonPathAssert `json:"jsonpath_asserts,omitempty"` // 结构化的 JSONPath 断言, 执行前转换为出参和断言
+
+	Proxy *APIProxyConfig `json:"proxy,omitempty"` // 步骤请求使用的代理, 优先于测试空间的全局配置
+}
+
+// APIProxyConfig API 测试请求使用的 HTTP 代理
+type APIProxyConfig struct {
+	URL      string   `json:"url"`                // 代理地址, 如 http://proxy.example.com:3128
+	Username string   `json:"username,omitempty"` // 代理认证用户名
+	Password string   `json:"password,omitempty"` // 代理认证密码
+	NoProxy  []string `json:"noProxy,omitempty"`  // 不走代理的地址, 格式同 NO_PROXY, 如 .example.com, 10.0.0.0/8
+
+	// 步骤上配置的密码保存时由 kms 加密, 只保留密文, 执行时再解密
+	PasswordCiphertext string `json:"passwordCiphertext,omitempty"`
+	KMSKeyID           string `json:"kmsKeyID,omitempty"`
+}
+
+// Validate 校验代理配置
+func (p APIProxyConfig) Validate() error {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %v", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy url scheme: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy url: missing host")
+	}
+	if (p.Password != "" || p.PasswordCiphertext != "") && p.Username == "" {
+		return fmt.Errorf("proxy username is required when password is set")
+	}
+	return nil
 }
 
 // APIHeader API测试请求头
@@ -111,6 +150,14 @@ type APIAssert struct {
 	Value    string `json:"value"`
 }
 
+// APIJsonPathAssert 基于 JSONPath 的结构化断言, 从响应体中提取值并与期望值比较
+type APIJsonPathAssert struct {
+	Path      string `json:"path"`                // JSONPath, 如 $.data.list[0].id
+	Operator  string `json:"operator"`            // equals, not_equals, contains, exists, not_exists, gt, gte, lt, lte, regex
+	Value     string `json:"value,omitempty"`     // 期望值
+	OutputKey string `json:"outputKey,omitempty"` // 不为空时提取的值作为出参发布, 供后续步骤引用
+}
+
 // APIResp API测试的返回结果
 type APIResp struct {
 	Status  int                 `json:"status"`
@@ -153,6 +200,7 @@ type APIRequestInfo struct {
 	Headers http.Header `json:"headers"`
 	Params  url.Values  `json:"params"`
 	Body    APIBody     `json:"body"`
+	Proxy   string      `json:"proxy,omitempty"` // 请求实际使用的代理地址, 不包含认证信息, 为空表示直连
 }
 
 // APITestsAssertResult 断言结果详情
@@ -26,6 +26,7 @@ type TemplateName string
 const (
 	// =====================Pipeline=============================
 	CancelPipelineTemplate     TemplateName = "cancelPipeline"
+	CancelPipelinesTemplate    TemplateName = "cancelPipelines"
 	DeletePipelineKeyTemplate  TemplateName = "deletePipelineKey"
 	UpdatePipelineKeyTemplate  TemplateName = "updatePipelineKey"
 	CreatePipelineTemplate     TemplateName = "createPipeline"
@@ -91,6 +91,8 @@ type AutoTestGlobalConfigCreateRequest struct {
 
 	DisplayName string `json:"displayName"`
 	Desc        string `json:"desc"`
+	// ParentNs 继承的全局配置, 执行时本地配置项覆盖继承的配置项
+	ParentNs string `json:"parentNs,omitempty"`
 
 	APIConfig *AutoTestAPIConfig `json:"apiConfig,omitempty"`
 	UIConfig  *AutoTestUIConfig  `json:"uiConfig,omitempty"`
@@ -156,6 +158,7 @@ type AutoTestGlobalConfig struct {
 	UpdaterID   string    `json:"updaterID"`
 	CreatedAt   time.Time `json:"createdAt,omitempty"`
 	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+	ParentNs    string    `json:"parentNs,omitempty"`
 
 	APIConfig *AutoTestAPIConfig `json:"apiConfig,omitempty"`
 	UIConfig  *AutoTestUIConfig  `json:"uiConfig,omitempty"`
@@ -173,6 +176,15 @@ func (p SortByUpdateTimeAutoTestGlobalConfigs) Swap(i, j int) {
 	p[i], p[j] = p[j], p[i]
 }
 
+// MaskSecrets 返回敏感配置项的值被掩码替换后的全局配置
+func (cfg AutoTestGlobalConfig) MaskSecrets() AutoTestGlobalConfig {
+	if cfg.APIConfig != nil {
+		masked := cfg.APIConfig.MaskSecrets()
+		cfg.APIConfig = &masked
+	}
+	return cfg
+}
+
 func (cfg AutoTestGlobalConfig) GetUserIDs() []string {
 	return strutil.DedupSlice([]string{cfg.CreatorID, cfg.UpdaterID}, true)
 }
@@ -181,6 +193,16 @@ type AutoTestAPIConfig struct {
 	Domain string                        `json:"domain"`
 	Header map[string]string             `json:"header"`
 	Global map[string]AutoTestConfigItem `json:"global"`
+	Proxy  *APIProxyConfig               `json:"proxy,omitempty"` // 测试空间内 api 步骤默认使用的代理
+	// Inheritance 继承得到的 domain, header 和 proxy, 仅在查询结果中返回
+	Inheritance *AutoTestAPIConfigInheritance `json:"inheritance,omitempty"`
+}
+
+// AutoTestAPIConfigInheritance 记录 api 配置中来自继承的值, 更新时这些值不会保存为本地配置
+type AutoTestAPIConfigInheritance struct {
+	Domain  bool     `json:"domain,omitempty"`
+	Headers []string `json:"headers,omitempty"`
+	Proxy   bool     `json:"proxy,omitempty"`
 }
 
 func (cfg AutoTestAPIConfig) BasicValidate() error {
@@ -196,14 +218,59 @@ func (cfg AutoTestAPIConfig) BasicValidate() error {
 			return fmt.Errorf("invalid type of global key: %s", key)
 		}
 	}
+	// proxy
+	if cfg.Proxy != nil {
+		if err := cfg.Proxy.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// HasSecret 是否包含敏感配置项
+func (cfg AutoTestAPIConfig) HasSecret() bool {
+	if cfg.Proxy != nil && cfg.Proxy.Password != "" {
+		return true
+	}
+	for _, item := range cfg.Global {
+		if item.Secret {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSecrets 返回敏感配置项的值被掩码替换后的配置
+func (cfg AutoTestAPIConfig) MaskSecrets() AutoTestAPIConfig {
+	masked := cfg
+	masked.Global = make(map[string]AutoTestConfigItem, len(cfg.Global))
+	for name, item := range cfg.Global {
+		if item.Secret {
+			item.Value = AutoTestConfigSecretMask
+		}
+		masked.Global[name] = item
+	}
+	if cfg.Proxy != nil && cfg.Proxy.Password != "" {
+		proxy := *cfg.Proxy
+		proxy.Password = AutoTestConfigSecretMask
+		masked.Proxy = &proxy
+	}
+	return masked
+}
+
+// AutoTestConfigSecretMask 敏感配置项在查询结果中展示的值, 更新时传入该值表示保持原值不变
+const AutoTestConfigSecretMask = "******"
+
 type AutoTestConfigItem struct {
 	Name  string `json:"name"`
 	Type  string `json:"type"`
 	Value string `json:"value"`
 	Desc  string `json:"desc,omitempty"`
+	// Secret 敏感配置项加密存储, 查询时掩码展示, 仅在执行时解密
+	Secret bool `json:"secret,omitempty"`
+	// Inherited 配置项来自继承的全局配置, InheritedFrom 为其所在的全局配置
+	Inherited     bool   `json:"inherited,omitempty"`
+	InheritedFrom string `json:"inheritedFrom,omitempty"`
 }
 
 type AutoTestUIConfig struct {
@@ -218,6 +285,8 @@ type AutoTestGlobalConfigUpdateRequest struct {
 
 	DisplayName string `json:"displayName"`
 	Desc        string `json:"desc"`
+	// ParentNs 为空时不修改, 传入空字符串表示取消继承
+	ParentNs *string `json:"parentNs,omitempty"`
 
 	APIConfig *AutoTestAPIConfig `json:"apiConfig,omitempty"`
 	UIConfig  *AutoTestUIConfig  `json:"uiConfig,omitempty"`
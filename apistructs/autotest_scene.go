@@ -24,18 +24,66 @@ type AutoTestRunCustom struct {
 	Image    string   `json:"image"`
 }
 
+// AutoTestRunJSScript JS 脚本步骤, 脚本在没有网络的独立进程中执行, 不继承任务的环境变量.
+// 脚本中通过 inputs 读取入参, 通过给 outputs 赋值发布出参, 如 outputs.token = inputs.body.split(".")[0]
+type AutoTestRunJSScript struct {
+	Script    string            `json:"script"`              // 脚本内容
+	Inputs    map[string]string `json:"inputs,omitempty"`    // 入参, 值可以引用之前步骤的出参, 如 ${{ outputs.123.body }}
+	TimeoutMs int               `json:"timeoutMs,omitempty"` // 脚本执行的超时时间, 为空时使用默认值
+}
+
 type AutoTestRunScene struct {
 	RunParams map[string]interface{} `json:"runParams,omitempty"`
 	SceneID   uint64                 `json:"sceneID"`
 }
 
 type AutoTestRunStep struct {
-	ApiSpec map[string]interface{} `json:"apiSpec"`
-	Loop    *PipelineTaskLoop      `json:"loop"`
+	ApiSpec   map[string]interface{}   `json:"apiSpec"`
+	Loop      *PipelineTaskLoop        `json:"loop"`
+	Retry     *AutoTestStepRetryPolicy `json:"retry,omitempty"`     // 重试策略, 为空时不重试; 场景执行时生效, 单独调试步骤时只执行一次
+	TimeoutMs int64                    `json:"timeoutMs,omitempty"` // 超时时间, 超时后中断请求, 为 0 时使用场景的默认超时时间
+	OnError   AutoTestStepOnError      `json:"onError,omitempty"`   // 步骤失败或超时后的处理策略, 为空时为 fail
+}
+
+// AutoTestStepOnError 步骤失败或超时后的处理策略
+type AutoTestStepOnError string
+
+const (
+	// AutoTestStepOnErrorFail 步骤失败, 场景执行失败
+	AutoTestStepOnErrorFail AutoTestStepOnError = "fail"
+	// AutoTestStepOnErrorContinue 忽略步骤的失败, 不影响场景的执行结果
+	AutoTestStepOnErrorContinue AutoTestStepOnError = "continue"
+)
+
+// AutoTestStepRetryPolicy 步骤的重试策略
+type AutoTestStepRetryPolicy struct {
+	MaxAttempts          int   `json:"maxAttempts"`          // 最大执行次数, 包含首次执行
+	DelayMs              int64 `json:"delayMs"`              // 两次执行之间的间隔, 按秒向上取整
+	RetryOnStatusCodes   []int `json:"retryOnStatusCodes"`   // 响应为这些状态码时重试
+	RetryOnAssertFailure bool  `json:"retryOnAssertFailure"` // 断言失败时重试
+	RetryOnInvokeError   bool  `json:"retryOnInvokeError"`   // 请求失败 (如网络错误) 时重试
+}
+
+// AutoTestRunLoop 循环步骤, 对集合中的每一项依次执行子步骤
+// 子步骤中可以通过 ${{ loop.item }}, ${{ loop.item.<field> }} 和 ${{ loop.index }} 引用当前项
+type AutoTestRunLoop struct {
+	Items      []interface{}       `json:"items,omitempty"`      // 内联的集合
+	ItemsRef   string              `json:"itemsRef,omitempty"`   // 引用的全局配置参数名, 参数值为 JSON 数组
+	DataFileID uint64              `json:"dataFileID,omitempty"` // 引用的场景数据文件 ID, 每行作为一项, 列为项的字段
+	Steps      []AutoTestSceneStep `json:"steps"`                // 每次迭代执行的子步骤
 }
 
 type AutoTestRunWait struct {
-	WaitTime int `json:"waitTime"`
+	WaitTime int               `json:"waitTime"`
+	Poll     *AutoTestWaitPoll `json:"poll,omitempty"` // 不为空时轮询等待, 忽略 WaitTime
+}
+
+// AutoTestWaitPoll 轮询等待, 每隔 IntervalSec 重新计算 Condition, 直到条件满足; 超时后步骤失败
+type AutoTestWaitPoll struct {
+	Condition   string                 `json:"condition"`         // 条件表达式, 可引用之前步骤的出参
+	IntervalSec int                    `json:"intervalSec"`       // 轮询间隔
+	TimeoutSec  int                    `json:"timeoutSec"`        // 超时时间
+	ApiSpec     map[string]interface{} `json:"apiSpec,omitempty"` // 每次轮询执行的请求, 条件中可引用当前步骤的出参
 }
 
 type AutoTestRunConfigSheet struct {
@@ -65,6 +113,12 @@ type AutoTestScene struct {
 	Output      []AutoTestSceneOutput `json:"output"`    // 输出参数
 	Steps       []AutoTestSceneStep   `json:"steps"`     // 步骤
 	RefSetID    uint64                `json:"refSetID"`  // 引用场景集ID
+	Tags        []string              `json:"tags"`      // 场景标签
+	// TemplateID 场景实例化自的模板, 为 0 时不是由模板创建
+	TemplateID      uint64 `json:"templateID"`
+	TemplateVersion uint64 `json:"templateVersion"`
+	// StepTimeoutMs 步骤的默认超时时间, 为 0 时不限制
+	StepTimeoutMs int64 `json:"stepTimeoutMs"`
 }
 
 type AutoTestSceneInput struct {
@@ -98,6 +152,9 @@ type AutoTestSceneStep struct {
 	UpdaterID string              `json:"updaterID"`
 	Children  []AutoTestSceneStep // 并行子节点
 	APISpecID uint64              `json:"apiSpecID"` // api集市id
+	Condition string              `json:"condition"` // 执行条件, 可引用之前步骤的出参, 为空时总是执行
+	// DefaultTimeoutMs 所属场景的步骤默认超时时间, 生成流水线时由场景填充
+	DefaultTimeoutMs int64 `json:"-"`
 }
 
 type AutotestSceneRequest struct {
@@ -110,6 +167,7 @@ type AutotestSceneRequest struct {
 	SetID       uint64 `json:"setID,omitempty"`       // 场景集ID
 	APISpecID   uint64 `json:"apiSpecID,omitempty"`   // api集市id
 	RefSetID    uint64 `json:"refSetID,omitempty"`    // 引用场景集的ID
+	Condition   string `json:"condition,omitempty"`   // 步骤执行条件
 
 	Type     StepAPIType `json:"type,omitempty"`
 	Target   int64       `json:"target,omitempty"`   // 目标位置
@@ -121,6 +179,9 @@ type AutotestSceneRequest struct {
 	PageNo   uint64 `json:"pageNo"`
 	PageSize uint64 `json:"pageSize"`
 
+	// Tags 查询场景列表时只返回带有其中任一标签的场景
+	Tags []string `json:"tags,omitempty"`
+
 	IdentityInfo
 }
 
@@ -138,6 +199,10 @@ type AutotestSceneSceneUpdateRequest struct {
 	Status      SceneStatus `json:"status"`
 	SetID       uint64      `json:"setID"`
 	IsStatus    bool        `json:"isStatus"` // 为true的情况下不会改变更新人
+	// Tags 场景标签, 为 nil 时不更新
+	Tags []string `json:"tags"`
+	// StepTimeoutMs 步骤的默认超时时间, 为 nil 时不更新
+	StepTimeoutMs *int64 `json:"stepTimeoutMs"`
 	IdentityInfo
 }
 
@@ -216,6 +281,9 @@ func (ats *AutotestSceneRequest) URLQueryString() map[string][]string {
 	if ats.PageNo != 0 {
 		query["pageNo"] = []string{strconv.FormatInt(int64(ats.PageNo), 10)}
 	}
+	if len(ats.Tags) > 0 {
+		query["tags"] = append(query["tags"], ats.Tags...)
+	}
 	return query
 }
 
@@ -227,10 +295,26 @@ const (
 	StepTypeScene        StepAPIType = "SCENE"
 	StepTypeCustomScript StepAPIType = "CUSTOM"
 	StepTypeConfigSheet  StepAPIType = "CONFIGSHEET"
+	StepTypeLoop         StepAPIType = "LOOP"
+	StepTypeJSScript     StepAPIType = "JSSCRIPT"
 	AutotestType                     = "AUTOTESTTYPE"
 	AutotestSceneStep                = "STEP"
 	AutotestSceneSet                 = "SCENESET"
 	AutotestScene                    = "SCENE"
+	AutotestLoopStepID               = "LOOPSTEPID" // 循环步骤展开后, 子步骤所属的循环步骤 id
+	AutotestLoopIndex                = "LOOPINDEX"  // 循环步骤展开后, 子步骤所在的迭代序号
+)
+
+// 步骤执行策略, 以 action label 传递给执行器
+const (
+	AutotestStepTimeoutMs = "STEPTIMEOUTMS" // 步骤的超时时间, 单位 ms
+	AutotestStepOnError   = "STEPONERROR"   // 步骤失败或超时后的处理策略
+)
+
+// api-test 任务的出参, 用于计算步骤的重试条件
+const (
+	AutotestStepOutputResult = "result"     // 执行结果, 成功时为 success
+	AutotestStepOutputStatus = "api_status" // 响应状态码, 请求失败时为 0
 )
 
 func (v StepAPIType) String() string {
@@ -365,6 +449,10 @@ type AutotestExecuteSceneRequest struct {
 	UserID                 string            `json:"userId"`
 	ConfigManageNamespaces string            `json:"configManageNamespaces"`
 	IdentityInfo           IdentityInfo      `json:"identityInfo"`
+	// Environment 本次执行使用的环境名称, 记录在执行记录中
+	Environment string `json:"environment"`
+	// Variables 本次执行覆盖的变量, 覆盖同名的场景入参和全局配置, 不修改已保存的配置
+	Variables map[string]string `json:"variables"`
 }
 
 type AutotestExecuteSceneStepRequest struct {
@@ -380,9 +468,12 @@ type AutotestExecuteSceneStepResp struct {
 }
 
 type AutotestExecuteSceneStepRespData struct {
-	Info    *APIRequestInfo       `json:"requestInfo"`
-	Resp    *APIResp              `json:"respInfo"`
-	Asserts *APITestsAssertResult `json:"asserts"`
+	Info       *APIRequestInfo       `json:"requestInfo"`
+	Resp       *APIResp              `json:"respInfo"`
+	Asserts    *APITestsAssertResult `json:"asserts"`
+	TimedOut   bool                  `json:"timedOut"`   // 是否超时
+	TimeoutMs  int64                 `json:"timeoutMs"`  // 配置的超时时间, 为 0 时不限制
+	DurationMs int64                 `json:"durationMs"` // 实际耗时
 }
 
 type AutotestExecuteSceneResponse struct {
@@ -400,3 +491,26 @@ type AutotestCancelSceneResponse struct {
 	Header
 	Data string `json:"data"`
 }
+
+// AutoTestSceneStepGraph 场景步骤间通过出参引用形成的依赖图
+type AutoTestSceneStepGraph struct {
+	Nodes  []AutoTestSceneStepGraphNode `json:"nodes"`
+	Edges  []AutoTestSceneStepGraphEdge `json:"edges"`
+	Cycles [][]uint64                   `json:"cycles"` // 循环引用, 每项为环上的步骤 ID, 按出参的流向排列
+}
+
+// AutoTestSceneStepGraphNode 依赖图中的步骤
+type AutoTestSceneStepGraphNode struct {
+	ID         uint64      `json:"id"`
+	Name       string      `json:"name"`
+	Type       StepAPIType `json:"type"`
+	GroupIndex int         `json:"groupIndex"`           // 所在串行分组的序号, 同一分组内的步骤并行执行
+	Unresolved []uint64    `json:"unresolved,omitempty"` // 引用了出参但不在场景中的步骤 ID
+}
+
+// AutoTestSceneStepGraphEdge 依赖图中的边, To 步骤引用了 From 步骤的出参
+type AutoTestSceneStepGraphEdge struct {
+	From    uint64   `json:"from"`
+	To      uint64   `json:"to"`
+	Outputs []string `json:"outputs"` // 引用的出参名
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// AutoTestSceneDataFile 场景的 CSV 数据文件, 循环步骤通过 dataFileID 引用, 每行作为一次迭代的 loop.item
+type AutoTestSceneDataFile struct {
+	ID        uint64    `json:"id"`
+	SpaceID   uint64    `json:"spaceID"`
+	SceneID   uint64    `json:"sceneID"`
+	Name      string    `json:"name"`
+	Columns   []string  `json:"columns"`  // 表头, 即循环中可引用的 loop.item.<column>
+	RowCount  int       `json:"rowCount"` // 数据行数, 不含表头
+	Size      int64     `json:"size"`     // 文件大小, 单位 byte
+	CreatorID string    `json:"creatorID"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AutoTestSceneDataFileCreateRequest 为场景添加数据文件, 上传文件时使用 multipart 的 file 字段, 否则引用已上传文件的 uuid
+type AutoTestSceneDataFileCreateRequest struct {
+	SceneID      uint64 `json:"-"`
+	Name         string `json:"name"`
+	DiceFileUUID string `json:"diceFileUUID"`
+
+	IdentityInfo
+}
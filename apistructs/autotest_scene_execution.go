@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// AutoTestSceneExecution 场景的一次执行记录
+type AutoTestSceneExecution struct {
+	ID          uint64         `json:"id"`
+	SceneID     uint64         `json:"sceneID"`
+	SpaceID     uint64         `json:"spaceID"`
+	PipelineID  uint64         `json:"pipelineID"`
+	Status      PipelineStatus `json:"status"`
+	CostTimeSec int64          `json:"costTimeSec"`
+	TimeBegin   *time.Time     `json:"timeBegin"`
+	TimeEnd     *time.Time     `json:"timeEnd"`
+	CreatorID   string         `json:"creatorID"`
+	CreatedAt   time.Time      `json:"createdAt"`
+
+	// Environment 执行时使用的环境名称
+	Environment            string `json:"environment"`
+	ConfigManageNamespaces string `json:"configManageNamespaces"`
+	// VariableNames 执行时覆盖的变量名
+	VariableNames []string `json:"variableNames"`
+
+	// BaselineID 对比的基线, 为 0 时未与基线对比
+	BaselineID uint64 `json:"baselineID"`
+	// BaselineDrift 是否有步骤的响应与基线不一致
+	BaselineDrift bool `json:"baselineDrift"`
+
+	// Steps 各步骤的执行结果, 仅在查询执行详情时返回
+	Steps []AutoTestSceneExecutionStep `json:"steps,omitempty"`
+}
+
+// AutoTestSceneExecutionStep 场景执行中单个步骤的执行结果及请求响应快照
+type AutoTestSceneExecutionStep struct {
+	ID            uint64         `json:"id"`
+	StepID        uint64         `json:"stepID"`
+	StepName      string         `json:"stepName"`
+	StepType      StepAPIType    `json:"stepType"`
+	TaskName      string         `json:"taskName"`
+	Status        PipelineStatus `json:"status"`
+	CostTimeSec   int64          `json:"costTimeSec"`
+	TimeBegin     *time.Time     `json:"timeBegin"`
+	TimeEnd       *time.Time     `json:"timeEnd"`
+	Request       string         `json:"request"`
+	Response      string         `json:"response"`
+	AssertSuccess string         `json:"assertSuccess"`
+	AssertDetail  string         `json:"assertDetail"`
+	Message       string         `json:"message"`
+	// TimeoutMs 步骤配置的超时时间, DurationMs 请求的实际耗时, 单位 ms; 超时的步骤状态为 Timeout
+	TimeoutMs  int64 `json:"timeoutMs"`
+	DurationMs int64 `json:"durationMs"`
+
+	// BaselineDiff 响应与基线的差异, 未与基线对比或没有差异时为空
+	BaselineDiff []AutoTestBaselineFieldDiff `json:"baselineDiff,omitempty"`
+}
+
+// AutoTestSceneExecutionListRequest 分页查询场景的执行记录
+type AutoTestSceneExecutionListRequest struct {
+	SceneID  uint64 `schema:"-"`
+	PageNo   int    `schema:"pageNo"`
+	PageSize int    `schema:"pageSize"`
+}
+
+// AutoTestSceneExecutionPagingData 场景执行记录分页结果
+type AutoTestSceneExecutionPagingData struct {
+	Total int64                    `json:"total"`
+	List  []AutoTestSceneExecution `json:"list"`
+}
+
+// AutoTestSceneBaseline 场景的响应基线, 之后的执行将各步骤的响应与基线对比
+type AutoTestSceneBaseline struct {
+	ID          uint64    `json:"id"`
+	SceneID     uint64    `json:"sceneID"`
+	ExecutionID uint64    `json:"executionID"` // 基线取自的执行记录
+	IgnorePaths []string  `json:"ignorePaths"` // 对比时忽略的字段路径
+	StepIDs     []uint64  `json:"stepIDs"`     // 基线中包含响应快照的步骤
+	CreatorID   string    `json:"creatorID"`
+	UpdaterID   string    `json:"updaterID"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// AutoTestSceneBaselineSaveRequest 保存场景的响应基线.
+// ExecutionID 不为 0 时以该次执行的响应重新生成基线, 否则只更新忽略的字段路径.
+// 忽略路径形如 $.data.list[*].id, $.data.*.createdAt, * 匹配任意一级字段或下标, 匹配的字段及其子字段都被忽略
+type AutoTestSceneBaselineSaveRequest struct {
+	SceneID     uint64   `json:"-"`
+	ExecutionID uint64   `json:"executionID"`
+	IgnorePaths []string `json:"ignorePaths"`
+
+	IdentityInfo
+}
+
+// AutoTestBaselineDiffType 响应字段相对基线的变化类型
+type AutoTestBaselineDiffType string
+
+const (
+	AutoTestBaselineDiffAdded   AutoTestBaselineDiffType = "added"
+	AutoTestBaselineDiffRemoved AutoTestBaselineDiffType = "removed"
+	AutoTestBaselineDiffChanged AutoTestBaselineDiffType = "changed"
+)
+
+// AutoTestBaselineFieldDiff 响应中单个字段相对基线的差异, 值为 JSON 格式
+type AutoTestBaselineFieldDiff struct {
+	Path     string                   `json:"path"`
+	Type     AutoTestBaselineDiffType `json:"type"`
+	Baseline string                   `json:"baseline,omitempty"`
+	Current  string                   `json:"current,omitempty"`
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// AutoTestSceneTemplate 场景模板, 可实例化到项目下任意测试空间; 模板更新不会修改已实例化的场景
+type AutoTestSceneTemplate struct {
+	ID            uint64 `json:"id"`
+	ProjectID     uint64 `json:"projectID"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	SourceSceneID uint64 `json:"sourceSceneID"`
+	// Version 每次从场景保存内容时加一
+	Version uint64 `json:"version"`
+	// Params 实例化时需要填写的参数, 即模板的场景入参
+	Params    []AutoTestSceneTemplateParam `json:"params"`
+	StepCount int                          `json:"stepCount"`
+	CreatorID string                       `json:"creatorID"`
+	UpdaterID string                       `json:"updaterID"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	UpdatedAt time.Time                    `json:"updatedAt"`
+
+	// Content 模板内容, 仅在查询详情时返回
+	Content *AutoTestSceneTemplateContent `json:"content,omitempty"`
+}
+
+// AutoTestSceneTemplateParam 模板参数
+type AutoTestSceneTemplateParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+}
+
+// AutoTestSceneTemplateContent 模板保存的场景入参、出参和步骤
+type AutoTestSceneTemplateContent struct {
+	Inputs  []AutoTestSceneInput  `json:"inputs"`
+	Outputs []AutoTestSceneOutput `json:"outputs"`
+	Steps   []AutoTestSceneStep   `json:"steps"`
+}
+
+// AutoTestSceneTemplateCreateRequest 将场景保存为模板
+type AutoTestSceneTemplateCreateRequest struct {
+	SceneID     uint64 `json:"sceneID"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	IdentityInfo
+}
+
+// AutoTestSceneTemplateUpdateRequest 更新模板, 为空的字段不更新; 指定 sceneID 时重新从场景保存内容
+type AutoTestSceneTemplateUpdateRequest struct {
+	TemplateID  uint64  `json:"-"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	SceneID     uint64  `json:"sceneID"`
+
+	IdentityInfo
+}
+
+// AutoTestSceneTemplateListRequest 查询项目下的模板列表
+type AutoTestSceneTemplateListRequest struct {
+	ProjectID uint64 `schema:"projectID"`
+	Name      string `schema:"name"`
+}
+
+// AutoTestSceneTemplateInstantiateRequest 将模板实例化为场景集下的新场景
+type AutoTestSceneTemplateInstantiateRequest struct {
+	TemplateID uint64 `json:"-"`
+	SetID      uint64 `json:"setID"`
+	// Name 新场景名称, 为空时使用模板名称
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Params 参数值, 未填写的参数使用模板中的默认值
+	Params map[string]string `json:"params"`
+
+	IdentityInfo
+}
+
+// AutoTestSceneUpdateFromTemplateRequest 用模板的最新内容覆盖场景的入参、出参和步骤
+type AutoTestSceneUpdateFromTemplateRequest struct {
+	SceneID uint64 `json:"-"`
+	// Params 参数值, 未填写的参数保留场景当前的入参值, 新增的参数使用模板中的默认值
+	Params map[string]string `json:"params"`
+
+	IdentityInfo
+}
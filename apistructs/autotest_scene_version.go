@@ -0,0 +1,41 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// AutoTestSceneVersion 场景历史版本, 记录场景的入参、出参、步骤和步骤默认超时时间, 每次修改场景都会生成新版本
+type AutoTestSceneVersion struct {
+	ID            uint64 `json:"id"`
+	SceneID       uint64 `json:"sceneID"`
+	Version       uint64 `json:"version"` // 版本号，从 1 开始递增
+	StepTimeoutMs int64  `json:"stepTimeoutMs"`
+	StepCount     int    `json:"stepCount"`
+	OperatorID    string `json:"operatorID"` // 变更人
+	// RestoredFromVersion 由哪个版本恢复而来，非恢复时为 0
+	RestoredFromVersion uint64    `json:"restoredFromVersion"`
+	CreatedAt           time.Time `json:"createdAt"` // 变更时间
+
+	// Content 版本内容, 仅在查询详情时返回
+	Content *AutoTestSceneTemplateContent `json:"content,omitempty"`
+}
+
+// AutoTestSceneVersionRestoreRequest 将场景恢复至指定版本，恢复本身会生成新版本
+type AutoTestSceneVersionRestoreRequest struct {
+	SceneID uint64 `json:"-"`
+	Version uint64 `json:"-"`
+
+	IdentityInfo
+}
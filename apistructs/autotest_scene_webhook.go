@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+const (
+	// AutoTestSceneWebhookEventExecutionFinished 场景执行结束事件
+	AutoTestSceneWebhookEventExecutionFinished = "autotest_scene_execution_finished"
+	// AutoTestSceneWebhookSignatureHeader 请求体签名, 格式为 sha256=<hex>, 签名内容为 "<timestamp>.<body>"
+	AutoTestSceneWebhookSignatureHeader = "X-Erda-Signature"
+	// AutoTestSceneWebhookTimestampHeader 签名时间戳, unix 秒, 接收方可据此拒绝过期请求
+	AutoTestSceneWebhookTimestampHeader = "X-Erda-Timestamp"
+	// AutoTestSceneWebhookDeliveryHeader 投递记录 id, 重试时不变, 接收方可据此去重
+	AutoTestSceneWebhookDeliveryHeader = "X-Erda-Delivery"
+)
+
+// AutoTestSceneWebhookDeliveryStatus webhook 投递状态
+type AutoTestSceneWebhookDeliveryStatus string
+
+const (
+	AutoTestSceneWebhookDeliveryPending AutoTestSceneWebhookDeliveryStatus = "pending"
+	AutoTestSceneWebhookDeliverySuccess AutoTestSceneWebhookDeliveryStatus = "success"
+	AutoTestSceneWebhookDeliveryFailed  AutoTestSceneWebhookDeliveryStatus = "failed"
+)
+
+// AutoTestSceneWebhook 场景执行结束时回调的 webhook
+type AutoTestSceneWebhook struct {
+	ID      uint64 `json:"id"`
+	SpaceID uint64 `json:"spaceID"`
+	// SceneID 为 0 时对测试空间下的所有场景生效
+	SceneID   uint64    `json:"sceneID"`
+	URL       string    `json:"url"`
+	Enabled   bool      `json:"enabled"`
+	CreatorID string    `json:"creatorID"`
+	UpdaterID string    `json:"updaterID"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Secret 签名密钥, 仅在创建时返回一次
+	Secret string `json:"secret,omitempty"`
+}
+
+// AutoTestSceneWebhookCreateRequest 创建 webhook, 指定 sceneID 时只对该场景生效, 否则对 spaceID 下所有场景生效
+type AutoTestSceneWebhookCreateRequest struct {
+	SpaceID uint64 `json:"spaceID"`
+	SceneID uint64 `json:"sceneID"`
+	URL     string `json:"url"`
+	Enabled bool   `json:"enabled"`
+
+	IdentityInfo
+}
+
+// AutoTestSceneWebhookUpdateRequest 更新 webhook, 为空的字段不更新
+type AutoTestSceneWebhookUpdateRequest struct {
+	WebhookID uint64  `json:"-"`
+	URL       *string `json:"url"`
+	Enabled   *bool   `json:"enabled"`
+
+	IdentityInfo
+}
+
+// AutoTestSceneWebhookListRequest 查询 webhook 列表
+type AutoTestSceneWebhookListRequest struct {
+	SpaceID uint64 `schema:"spaceID"`
+	SceneID uint64 `schema:"sceneID"`
+}
+
+// AutoTestSceneWebhookPayload 场景执行结束时投递的内容
+type AutoTestSceneWebhookPayload struct {
+	Event       string         `json:"event"`
+	ExecutionID uint64         `json:"executionID"`
+	SceneID     uint64         `json:"sceneID"`
+	SceneName   string         `json:"sceneName"`
+	SpaceID     uint64         `json:"spaceID"`
+	PipelineID  uint64         `json:"pipelineID"`
+	Status      PipelineStatus `json:"status"`
+	StepTotal   int            `json:"stepTotal"`
+	StepPassed  int            `json:"stepPassed"`
+	StepFailed  int            `json:"stepFailed"`
+	CostTimeSec int64          `json:"costTimeSec"`
+	TimeBegin   *time.Time     `json:"timeBegin"`
+	TimeEnd     *time.Time     `json:"timeEnd"`
+	Link        string         `json:"link"`
+}
+
+// AutoTestSceneWebhookDelivery webhook 的一次投递记录
+type AutoTestSceneWebhookDelivery struct {
+	ID           uint64                             `json:"id"`
+	WebhookID    uint64                             `json:"webhookID"`
+	ExecutionID  uint64                             `json:"executionID"`
+	Status       AutoTestSceneWebhookDeliveryStatus `json:"status"`
+	Attempts     int                                `json:"attempts"`
+	ResponseCode int                                `json:"responseCode"`
+	Message      string                             `json:"message"`
+	NextRetryAt  *time.Time                         `json:"nextRetryAt"`
+	DeliveredAt  *time.Time                         `json:"deliveredAt"`
+	CreatedAt    time.Time                          `json:"createdAt"`
+}
+
+// AutoTestSceneWebhookDeliveryListRequest 分页查询投递记录
+type AutoTestSceneWebhookDeliveryListRequest struct {
+	WebhookID uint64 `schema:"-"`
+	PageNo    int    `schema:"pageNo"`
+	PageSize  int    `schema:"pageSize"`
+}
+
+// AutoTestSceneWebhookDeliveryPagingData 投递记录分页结果
+type AutoTestSceneWebhookDeliveryPagingData struct {
+	Total int64                          `json:"total"`
+	List  []AutoTestSceneWebhookDelivery `json:"list"`
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// AutoTestScheduleTargetType 定时执行的对象类型
+type AutoTestScheduleTargetType string
+
+const (
+	AutoTestScheduleTargetScene    AutoTestScheduleTargetType = "scene"
+	AutoTestScheduleTargetSceneSet AutoTestScheduleTargetType = "sceneset"
+	AutoTestScheduleTargetTestPlan AutoTestScheduleTargetType = "testplan"
+)
+
+// LabelAutotestScheduleID 定时执行触发的流水线标签, 值为定时执行 id
+const LabelAutotestScheduleID = "autotestScheduleID"
+
+// AutoTestScheduleRecordStatus 定时执行记录的触发状态
+type AutoTestScheduleRecordStatus string
+
+const (
+	AutoTestScheduleRecordSuccess AutoTestScheduleRecordStatus = "success"
+	AutoTestScheduleRecordFailed  AutoTestScheduleRecordStatus = "failed"
+	// AutoTestScheduleRecordSkipped 错过触发时间过久或上次执行尚未结束, 本次不执行
+	AutoTestScheduleRecordSkipped AutoTestScheduleRecordStatus = "skipped"
+)
+
+// AutoTestSchedule 场景、场景集或测试计划的定时执行配置
+type AutoTestSchedule struct {
+	ID                     uint64                     `json:"id"`
+	TargetType             AutoTestScheduleTargetType `json:"targetType"`
+	TargetID               uint64                     `json:"targetID"`
+	SpaceID                uint64                     `json:"spaceID"`
+	CronExpr               string                     `json:"cronExpr"`
+	Enabled                bool                       `json:"enabled"`
+	ConfigManageNamespaces string                     `json:"configManageNamespaces"`
+	ClusterName            string                     `json:"clusterName"`
+	// SceneTags 执行场景集时只执行带有其中任一标签的场景, 为空时执行全部场景
+	SceneTags []string `json:"sceneTags"`
+	// NextFireAt 下次触发时间, 未启用时为空
+	NextFireAt *time.Time `json:"nextFireAt"`
+	LastFireAt *time.Time `json:"lastFireAt"`
+	CreatorID  string     `json:"creatorID"`
+	UpdaterID  string     `json:"updaterID"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// AutoTestScheduleCreateRequest 创建定时执行
+type AutoTestScheduleCreateRequest struct {
+	TargetType             AutoTestScheduleTargetType `json:"targetType"`
+	TargetID               uint64                     `json:"targetID"`
+	CronExpr               string                     `json:"cronExpr"`
+	Enabled                bool                       `json:"enabled"`
+	ConfigManageNamespaces string                     `json:"configManageNamespaces"`
+	ClusterName            string                     `json:"clusterName"`
+	// SceneTags 仅对场景集生效, 只执行带有其中任一标签的场景
+	SceneTags []string `json:"sceneTags"`
+
+	IdentityInfo
+}
+
+// AutoTestScheduleUpdateRequest 更新定时执行, 为空的字段不更新
+type AutoTestScheduleUpdateRequest struct {
+	ScheduleID             uint64  `json:"-"`
+	CronExpr               *string `json:"cronExpr"`
+	Enabled                *bool   `json:"enabled"`
+	ConfigManageNamespaces *string `json:"configManageNamespaces"`
+	ClusterName            *string `json:"clusterName"`
+	// SceneTags 为 nil 时不更新, 为空数组时清空
+	SceneTags []string `json:"sceneTags"`
+
+	IdentityInfo
+}
+
+// AutoTestScheduleListRequest 查询定时执行列表
+type AutoTestScheduleListRequest struct {
+	TargetType AutoTestScheduleTargetType `schema:"targetType"`
+	TargetID   uint64                     `schema:"targetID"`
+	SpaceID    uint64                     `schema:"spaceID"`
+}
+
+// AutoTestScheduleRecord 定时执行的触发记录
+type AutoTestScheduleRecord struct {
+	ID         uint64                       `json:"id"`
+	ScheduleID uint64                       `json:"scheduleID"`
+	PipelineID uint64                       `json:"pipelineID"`
+	Status     AutoTestScheduleRecordStatus `json:"status"`
+	Message    string                       `json:"message"`
+	FiredAt    time.Time                    `json:"firedAt"`
+}
+
+// AutoTestScheduleRecordListRequest 分页查询触发记录
+type AutoTestScheduleRecordListRequest struct {
+	ScheduleID uint64 `schema:"-"`
+	PageNo     int    `schema:"pageNo"`
+	PageSize   int    `schema:"pageSize"`
+}
+
+// AutoTestScheduleRecordPagingData 触发记录分页结果
+type AutoTestScheduleRecordPagingData struct {
+	Total int64                    `json:"total"`
+	List  []AutoTestScheduleRecord `json:"list"`
+}
+
+// AutoTestScheduleUpcomingRequest 查询定时执行接下来的触发时间
+type AutoTestScheduleUpcomingRequest struct {
+	ScheduleID uint64 `schema:"-"`
+	// Count 返回的触发时间个数, 默认 5, 最多 50
+	Count int `schema:"count"`
+}
@@ -132,10 +132,51 @@ func (t TestSpaceFileType) Valid() bool {
 type AutoTestSpaceImportRequest struct {
 	ProjectID uint64            `schema:"projectID"`
 	FileType  TestSpaceFileType `schema:"fileType"`
+	// TargetSpaceID 导入到已有的测试空间，为空时新建测试空间
+	TargetSpaceID uint64 `schema:"targetSpaceID"`
+	// Mode 导入到已有测试空间时，同名场景的处理方式，默认 skip
+	Mode AutoTestSpaceImportMode `schema:"mode"`
 
 	IdentityInfo
 }
 
+// AutoTestSpaceImportMode 导入时同名场景的处理方式
+type AutoTestSpaceImportMode string
+
+var (
+	// AutoTestSpaceImportModeSkip 跳过同名场景，保留已有场景
+	AutoTestSpaceImportModeSkip AutoTestSpaceImportMode = "skip"
+	// AutoTestSpaceImportModeOverwrite 使用导入的场景覆盖同名场景
+	AutoTestSpaceImportModeOverwrite AutoTestSpaceImportMode = "overwrite"
+	// AutoTestSpaceImportModeRename 重命名导入的场景
+	AutoTestSpaceImportModeRename AutoTestSpaceImportMode = "rename"
+)
+
+func (m AutoTestSpaceImportMode) Valid() bool {
+	switch m {
+	case AutoTestSpaceImportModeSkip, AutoTestSpaceImportModeOverwrite, AutoTestSpaceImportModeRename:
+		return true
+	default:
+		return false
+	}
+}
+
+// AutoTestSpaceImportSummary 导入结果汇总
+type AutoTestSpaceImportSummary struct {
+	SpaceID uint64                  `json:"spaceID"`
+	Mode    AutoTestSpaceImportMode `json:"mode,omitempty"`
+	// SceneSets 同名的场景集会被复用，记为 updated
+	SceneSets AutoTestSpaceImportResult `json:"sceneSets"`
+	// Scenes 场景名格式为 场景集名/场景名
+	Scenes AutoTestSpaceImportResult `json:"scenes"`
+}
+
+type AutoTestSpaceImportResult struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Skipped []string `json:"skipped"`
+}
+
 type AutoTestSpaceImportResponse struct {
 	Header
 	Data uint64 `json:"data"`
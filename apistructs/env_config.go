@@ -29,8 +29,10 @@ type EnvConfig struct {
 	Comment    string `json:"comment"`
 	Status     string `json:"status"`
 	Source     string `json:"source"`
-	Type       string `json:"type"` // dice-file/kv
-	Encrypt    bool   `json:"encrypt"`
+	Type       string `json:"type"`    // dice-file/kv
+	Encrypt    bool   `json:"encrypt"` // 敏感配置使用 kms 加密保存, 读取时返回掩码
+	// DisableEncrypt 显式取消已有配置的加密, 未传入时已加密的配置更新后仍保持加密
+	DisableEncrypt bool `json:"disableEncrypt,omitempty"`
 	// Operations 配置项操作，若为 nil，则使用默认配置: canDownload=false, canEdit=true, canDelete=true
 	Operations *pb.PipelineCmsConfigOperations `json:"operations"`
 	CreateTime time.Time                       `json:"createTime,omitempty"`
@@ -42,6 +44,78 @@ type EnvConfigAddOrUpdateRequest struct {
 	Configs []EnvConfig `json:"configs"`
 }
 
+// EnvConfigEncryptRequest 将已有配置标记为敏感配置并加密 POST /api/config/actions/encrypt
+type EnvConfigEncryptRequest struct {
+	Keys []string `json:"keys"`
+}
+
+// EnvConfigEncryptResponse 返回本次加密的配置 key
+type EnvConfigEncryptResponse struct {
+	Header
+	Data []string `json:"data"`
+}
+
+// EnvConfigImportMode 配置导入模式
+type EnvConfigImportMode string
+
+const (
+	// EnvConfigImportModeMerge 只新增目标 namespace 中不存在的配置, 已存在且不一致的配置作为冲突返回
+	EnvConfigImportModeMerge EnvConfigImportMode = "merge"
+	// EnvConfigImportModeReplace 使用文档覆盖目标 namespace, 文档中不存在的配置会被删除
+	EnvConfigImportModeReplace EnvConfigImportMode = "replace"
+)
+
+// EnvConfigDocument namespace 配置导出文档, 可导入到其他 namespace
+// GET /api/config/actions/bulk-export
+type EnvConfigDocument struct {
+	Namespace string                  `json:"namespace"`
+	Configs   []EnvConfigDocumentItem `json:"configs"`
+}
+
+// EnvConfigDocumentItem 导出文档中的配置项, 敏感配置的值以掩码导出, 导入时需重新填写
+type EnvConfigDocumentItem struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	ConfigType string `json:"configType"` // ENV, FILE
+	Comment    string `json:"comment"`
+	Encrypt    bool   `json:"encrypt"`
+}
+
+// EnvConfigDocumentResponse 导出配置响应
+type EnvConfigDocumentResponse struct {
+	Header
+	Data *EnvConfigDocument `json:"data"`
+}
+
+// EnvConfigImportRequest 导入配置文档 POST /api/config/actions/bulk-import
+type EnvConfigImportRequest struct {
+	// Mode merge 或 replace, 默认 merge
+	Mode EnvConfigImportMode `json:"mode"`
+	EnvConfigDocument
+}
+
+// EnvConfigImportResult 导入结果
+type EnvConfigImportResult struct {
+	Mode      EnvConfigImportMode       `json:"mode"`
+	Created   []string                  `json:"created"`
+	Updated   []string                  `json:"updated"`
+	Deleted   []string                  `json:"deleted"`
+	Unchanged []string                  `json:"unchanged"`
+	Conflicts []EnvConfigImportConflict `json:"conflicts"`
+}
+
+// EnvConfigImportConflict merge 模式下与目标 namespace 冲突而未导入的配置
+type EnvConfigImportConflict struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// EnvConfigImportResponse 导入配置响应
+type EnvConfigImportResponse struct {
+	Header
+	Data *EnvConfigImportResult `json:"data"`
+}
+
 // EnvConfigFetchRequest namespace 配置获取请求
 type EnvConfigFetchRequest struct {
 	Namespace string // required
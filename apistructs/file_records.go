@@ -32,6 +32,10 @@ type TestFileRecord struct {
 	CreatedAt   time.Time       `json:"createdAt"`
 	UpdatedAt   time.Time       `json:"updatedAt"`
 	OperatorID  string          `json:"operatorID"`
+	// ImportSummary 自动化测试空间导入结果
+	ImportSummary *AutoTestSpaceImportSummary `json:"importSummary,omitempty"`
+	// ImportResult 测试用例导入结果
+	ImportResult *TestCaseImportResult `json:"importResult,omitempty"`
 }
 
 type TestFileRecordRequest struct {
@@ -55,12 +59,14 @@ type ManualTestFileExtraInfo struct {
 	TestSetID     uint64                   `json:"testSetID,omitempty"`
 	ImportRequest *TestCaseImportRequest   `json:"importRequest,omitempty"`
 	ExportRequest *TestCaseExportRequest   `json:"exportRequest,omitempty"`
+	ImportResult  *TestCaseImportResult    `json:"importResult,omitempty"`
 	CopyRequest   *TestSetCopyAsyncRequest `json:"copyRequest,omitempty"`
 }
 
 type AutoTestSpaceFileExtraInfo struct {
 	ImportRequest *AutoTestSpaceImportRequest `json:"importRequest,omitempty"`
 	ExportRequest *AutoTestSpaceExportRequest `json:"exportRequest,omitempty"`
+	ImportSummary *AutoTestSpaceImportSummary `json:"importSummary,omitempty"`
 }
 
 type FileRecordState string
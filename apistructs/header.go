@@ -16,9 +16,16 @@ package apistructs
 
 // ErrorResponse 统一的 response 的 err 部分
 type ErrorResponse struct {
-	Code string      `json:"code"`
-	Msg  string      `json:"msg"`
-	Ctx  interface{} `json:"ctx"`
+	Code    string        `json:"code"`
+	Msg     string        `json:"msg"`
+	Ctx     interface{}   `json:"ctx"`
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail 字段级错误详情
+type ErrorDetail struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
 }
 
 // Header 统一的 response 的除了接口数据的 header 部分
@@ -63,6 +63,24 @@ type KMSRotateKeyVersionResponse struct {
 	Data *kmstypes.RotateKeyVersionResponse `json:"data,omitempty"`
 }
 
+// rotate all keys
+type KMSRotateAllKeysRequest struct {
+	kmstypes.RotateAllKeysRequest
+}
+type KMSRotateAllKeysResponse struct {
+	Header
+	Data *kmstypes.RotateAllKeysResponse `json:"data,omitempty"`
+}
+
+// delete key
+type KMSDeleteKeyRequest struct {
+	kmstypes.DeleteKeyRequest
+}
+type KMSDeleteKeyResponse struct {
+	Header
+	Data *kmstypes.DeleteKeyResponse `json:"data,omitempty"`
+}
+
 // describe key
 type KMSDescribeKeyRequest struct {
 	kmstypes.DescribeKeyRequest
@@ -62,8 +62,10 @@ const (
 	LabelGittarYmlPath    = "gittarYmlPath"    // app snippetConfig label in order to specify the address of yml to address
 	LabelAutotestExecType = "autotestExecType" // 新版自动化测试的snippet的执行类型
 	LabelSceneSetID       = "sceneSetID"       // 新版自动化测试的场景集的 id
+	LabelSceneSetTags     = "sceneSetTags"     // 新版自动化测试执行场景集时筛选场景的标签, 逗号分隔
 	LabelSceneID          = "sceneID"          // 新版自动化测试的场景的 id
 	LabelSpaceID          = "spaceID"          // 空间 id
+	LabelConfigNamespace  = "configNamespace"  // 新版自动化测试执行时使用的配置单命名空间
 	// FDP
 	LabelFdpWorkflowID          = "CDP_WF_ID"
 	LabelFdpWorkflowName        = "CDP_WF_NAME"
@@ -46,6 +46,29 @@ type PipelineCreateRequest struct {
 
 	UserID          string `json:"userID"`
 	IsCronTriggered bool   `json:"isCronTriggered"`
+
+	// DryRun 为 true 时只返回展开后的流水线预览，不创建也不执行
+	DryRun bool `json:"dryRun"`
+}
+
+// PipelineDryRunResult 流水线创建预览结果
+type PipelineDryRunResult struct {
+	PipelineYmlName string `json:"pipelineYmlName"`
+	Branch          string `json:"branch"`
+	Workspace       string `json:"workspace"`
+	ClusterName     string `json:"clusterName"`
+
+	// 渲染配置后的 pipeline yml
+	PipelineYml string `json:"pipelineYml"`
+	// 流水线结构，snippet 展开在 action 的 snippetStages 中
+	Stages [][]*PipelineYmlAction `json:"stages"`
+
+	// 按顺序获取配置的命名空间
+	ConfigManageNamespaces []string `json:"configManageNamespaces"`
+	// 应用下分支与环境的映射关系
+	BranchWorkspaces []*ValidBranch `json:"branchWorkspaces"`
+
+	Warnings []string `json:"warnings"`
 }
 
 type PipelineRunParam struct {
@@ -468,6 +491,8 @@ type PipelineRunRequest struct {
 	PipelineID        uint64            `json:"pipelineID"`
 	ForceRun          bool              `json:"forceRun"`
 	PipelineRunParams PipelineRunParams `json:"runParams"`
+	// PresetID 使用的运行参数预设，runParams 中同名参数优先；仅 dop 使用
+	PresetID uint64 `json:"presetID,omitempty"`
 	IdentityInfo
 }
 
@@ -485,6 +510,50 @@ type PipelineCancelResponse struct {
 	Header
 }
 
+// PipelineBatchCancelRequest 按条件批量取消应用下的流水线
+type PipelineBatchCancelRequest struct {
+	AppID uint64 `json:"appID"`
+	// 为空时不过滤分支
+	Branch string `json:"branch"`
+	// 为空时取消所有可取消状态的流水线
+	Statuses []PipelineStatus `json:"statuses"`
+
+	IdentityInfo `json:"-"`
+}
+
+type PipelineBatchCancelResponse struct {
+	Header
+	Data *PipelineBatchCancelResult `json:"data"`
+}
+
+// PipelineBatchCancelResult 批量取消结果
+type PipelineBatchCancelResult struct {
+	AppID    uint64                    `json:"appID"`
+	Branch   string                    `json:"branch"`
+	Total    int                       `json:"total"`
+	Canceled int                       `json:"canceled"`
+	Skipped  int                       `json:"skipped"`
+	Failed   int                       `json:"failed"`
+	List     []PipelineBatchCancelItem `json:"list"`
+}
+
+type PipelineBatchCancelItemResult string
+
+const (
+	PipelineBatchCancelItemCanceled PipelineBatchCancelItemResult = "canceled"
+	PipelineBatchCancelItemSkipped  PipelineBatchCancelItemResult = "skipped"
+	PipelineBatchCancelItemFailed   PipelineBatchCancelItemResult = "failed"
+)
+
+// PipelineBatchCancelItem 单条流水线的取消结果
+type PipelineBatchCancelItem struct {
+	PipelineID uint64                        `json:"pipelineID"`
+	Branch     string                        `json:"branch"`
+	Status     PipelineStatus                `json:"status"`
+	Result     PipelineBatchCancelItemResult `json:"result"`
+	Reason     string                        `json:"reason,omitempty"`
+}
+
 // pipeline rerun
 type PipelineRerunRequest struct {
 	PipelineID    uint64 `json:"pipelineID"`
@@ -565,6 +634,38 @@ type PipelineConfigNamespaceItem struct {
 	IsOldConfig bool   `json:"isOldConfig"`
 }
 
+// PipelineCmsConfigDiffRequest 比较两个流水线配置命名空间 POST /api/cicds/configs/actions/diff
+type PipelineCmsConfigDiffRequest struct {
+	SourceAppID     uint64 `json:"sourceAppID"`
+	SourceNamespace string `json:"sourceNamespace"`
+	// TargetAppID 为空时与 SourceAppID 相同
+	TargetAppID     uint64 `json:"targetAppID"`
+	TargetNamespace string `json:"targetNamespace"`
+}
+
+type PipelineCmsConfigDiffResponse struct {
+	Header
+	Data *PipelineCmsConfigDiffResult `json:"data"`
+}
+
+// PipelineCmsConfigDiffResult 配置差异, 加密配置的值以掩码返回
+type PipelineCmsConfigDiffResult struct {
+	SourceNamespace string                      `json:"sourceNamespace"`
+	TargetNamespace string                      `json:"targetNamespace"`
+	OnlyInSource    []PipelineCmsConfigDiffItem `json:"onlyInSource"`
+	OnlyInTarget    []PipelineCmsConfigDiffItem `json:"onlyInTarget"`
+	Different       []PipelineCmsConfigDiffItem `json:"different"`
+}
+
+type PipelineCmsConfigDiffItem struct {
+	Key         string `json:"key"`
+	SourceType  string `json:"sourceType,omitempty"`
+	SourceValue string `json:"sourceValue,omitempty"`
+	TargetType  string `json:"targetType,omitempty"`
+	TargetValue string `json:"targetValue,omitempty"`
+	Encrypt     bool   `json:"encrypt"`
+}
+
 type PipelineAppInvokedBranchesResponse struct {
 	Header
 	Data []string `json:"data"`
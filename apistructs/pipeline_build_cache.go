@@ -14,12 +14,101 @@
 
 package apistructs
 
+import "time"
+
 type BuildCacheImageReportRequest struct {
 	Action      string `json:"action"`
 	Name        string `json:"name"`
 	ClusterName string `json:"clusterName"`
+	// Result is the optional cache lookup result of current build, hit or miss
+	Result BuildCacheResult `json:"result,omitempty"`
+	// Size is the size of cache image in bytes, reported when push
+	Size int64 `json:"size,omitempty"`
+}
+
+type BuildCacheResult string
+
+const (
+	BuildCacheResultHit  BuildCacheResult = "hit"
+	BuildCacheResultMiss BuildCacheResult = "miss"
+)
+
+func (r BuildCacheResult) Valid() bool {
+	return r == BuildCacheResultHit || r == BuildCacheResultMiss
 }
 
 type BuildCacheImageReportResponse struct {
 	Header
 }
+
+// BuildCachePagingRequest is used to list cache images reported by runners.
+type BuildCachePagingRequest struct {
+	ClusterName string `schema:"clusterName"`
+	// NamePrefix filters cache images whose name starts with it
+	NamePrefix string `schema:"namePrefix"`
+
+	PageNo   int `schema:"pageNo"`
+	PageSize int `schema:"pageSize"`
+}
+
+type BuildCachePagingResponse struct {
+	Header
+	Data *BuildCachePagingData `json:"data"`
+}
+
+type BuildCachePagingData struct {
+	Total int64                 `json:"total"`
+	List  []*BuildCacheImageDTO `json:"list"`
+}
+
+type BuildCacheImageDTO struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	ClusterName string     `json:"clusterName"`
+	LastPullAt  *time.Time `json:"lastPullAt,omitempty"`
+	LastPushAt  *time.Time `json:"lastPushAt,omitempty"`
+	Size        int64      `json:"size"`
+	HitCount    int64      `json:"hitCount"`
+	MissCount   int64      `json:"missCount"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// BuildCacheImageDeleteRequest is used to purge a stale or broken cache image.
+type BuildCacheImageDeleteRequest struct {
+	Name        string `schema:"name"`
+	ClusterName string `schema:"clusterName"`
+}
+
+type BuildCacheImageDeleteResponse struct {
+	Header
+}
+
+// BuildCacheStatsRequest queries cache hit rate in [startTime, endTime], default is the last 7 days.
+type BuildCacheStatsRequest struct {
+	ClusterName string `schema:"clusterName"`
+	Name        string `schema:"name"`
+	// format: 2006-01-02
+	StartTime string `schema:"startTime"`
+	EndTime   string `schema:"endTime"`
+}
+
+type BuildCacheStatsResponse struct {
+	Header
+	Data *BuildCacheStatsData `json:"data"`
+}
+
+type BuildCacheStatsData struct {
+	StartTime string                `json:"startTime"`
+	EndTime   string                `json:"endTime"`
+	Clusters  []BuildCacheStatsItem `json:"clusters"`
+	Caches    []BuildCacheStatsItem `json:"caches"`
+}
+
+type BuildCacheStatsItem struct {
+	ClusterName string  `json:"clusterName"`
+	Name        string  `json:"name,omitempty"`
+	HitCount    int64   `json:"hitCount"`
+	MissCount   int64   `json:"missCount"`
+	HitRate     float64 `json:"hitRate"`
+}
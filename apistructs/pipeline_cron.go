@@ -42,13 +42,58 @@ type PipelineCronDTO struct {
 	TimeCreated time.Time `json:"timeCreated"` // 记录创建时间
 	TimeUpdated time.Time `json:"timeUpdated"` // 记录更新时间
 
-	ApplicationID   uint64     `json:"applicationID"`
-	Branch          string     `json:"branch"`
-	CronExpr        string     `json:"cronExpr"`
-	CronStartTime   *time.Time `json:"cronStartTime"`
-	PipelineYmlName string     `json:"pipelineYmlName"` // 一个分支下可以有多个 pipeline 文件，每个分支可以有单独的 cron 逻辑
-	BasePipelineID  uint64     `json:"basePipelineID"`  // 用于记录最开始创建出这条 cron 记录的 pipeline id
-	Enable          *bool      `json:"enable"`          // 1 true, 0 false
+	ApplicationID   uint64         `json:"applicationID"`
+	Branch          string         `json:"branch"`
+	CronExpr        string         `json:"cronExpr"`
+	CronStartTime   *time.Time     `json:"cronStartTime"`
+	TimeZone        string         `json:"timeZone"` // 为空时使用服务端时区
+	PipelineSource  PipelineSource `json:"pipelineSource"`
+	PipelineYmlName string         `json:"pipelineYmlName"` // 一个分支下可以有多个 pipeline 文件，每个分支可以有单独的 cron 逻辑
+	BasePipelineID  uint64         `json:"basePipelineID"`  // 用于记录最开始创建出这条 cron 记录的 pipeline id
+	Enable          *bool          `json:"enable"`          // 1 true, 0 false
+}
+
+// PipelineCronNextFireTimeResponse 定时流水线的下次触发时间
+type PipelineCronNextFireTimeResponse struct {
+	Header
+	Data *PipelineCronNextFireTime `json:"data"`
+}
+
+type PipelineCronNextFireTime struct {
+	CronID   uint64 `json:"cronID"`
+	CronExpr string `json:"cronExpr"`
+	Enable   bool   `json:"enable"`
+	// TimeZone 计算触发时间使用的时区，与流水线定时调度器保持一致
+	TimeZone string `json:"timeZone"`
+	// NextFireTimes 按时间先后排列的后续触发时间，定时未启用时为空
+	NextFireTimes []time.Time `json:"nextFireTimes"`
+}
+
+// PipelineCronHistoryRequest 分页查询定时流水线的触发记录
+type PipelineCronHistoryRequest struct {
+	CronID   uint64 `schema:"-"`
+	PageNo   int    `schema:"pageNo"`
+	PageSize int    `schema:"pageSize"`
+}
+
+type PipelineCronHistoryResponse struct {
+	Header
+	Data *PipelineCronHistoryData `json:"data"`
+}
+
+type PipelineCronHistoryData struct {
+	Total int64                     `json:"total"`
+	List  []PipelineCronHistoryItem `json:"list"`
+}
+
+// PipelineCronHistoryItem 一次定时触发的流水线及其执行结果
+type PipelineCronHistoryItem struct {
+	PipelineID  uint64         `json:"pipelineID"`
+	Status      PipelineStatus `json:"status"`
+	TriggerTime *time.Time     `json:"triggerTime,omitempty"`
+	TimeBegin   *time.Time     `json:"timeBegin,omitempty"`
+	TimeEnd     *time.Time     `json:"timeEnd,omitempty"`
+	CostTimeSec int64          `json:"costTimeSec"`
 }
 
 type PipelineCronCreateRequest struct {
@@ -68,6 +113,7 @@ type PipelineCronUpdateRequest struct {
 	ID          uint64 `json:"id"`
 	PipelineYml string `json:"pipelineYml"`
 	CronExpr    string `json:"cronExpr"`
+	TimeZone    string `json:"timeZone"`
 }
 
 type PipelineCronUpdateResponse struct {
@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import (
+	"time"
+)
+
+// PipelineParamPresetDTO 流水线运行参数预设，挂在 应用/分支/pipelineYmlName 对应的流水线定义上
+type PipelineParamPresetDTO struct {
+	ID              uint64            `json:"id"`
+	ProjectID       uint64            `json:"projectID"`
+	AppID           uint64            `json:"appID"`
+	Branch          string            `json:"branch"`
+	PipelineYmlName string            `json:"pipelineYmlName"`
+	Name            string            `json:"name"`
+	Params          PipelineRunParams `json:"params"`
+	IsDefault       bool              `json:"isDefault"` // 默认预设在未指定运行参数时自动使用
+	CreatorID       string            `json:"creatorID"`
+	UpdaterID       string            `json:"updaterID"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+type PipelineParamPresetCreateRequest struct {
+	AppID           uint64            `json:"appID"`
+	Branch          string            `json:"branch"`
+	PipelineYmlName string            `json:"pipelineYmlName"`
+	Name            string            `json:"name"`
+	Params          PipelineRunParams `json:"params"`
+	IsDefault       bool              `json:"isDefault"`
+
+	IdentityInfo `json:"-"`
+}
+
+type PipelineParamPresetCreateResponse struct {
+	Header
+	Data *PipelineParamPresetDTO `json:"data"`
+}
+
+// PipelineParamPresetUpdateRequest 只更新传入的字段，未传入的字段保持不变
+type PipelineParamPresetUpdateRequest struct {
+	ID        uint64             `json:"-"`
+	Name      string             `json:"name"`
+	Params    *PipelineRunParams `json:"params"`
+	IsDefault *bool              `json:"isDefault"`
+
+	IdentityInfo `json:"-"`
+}
+
+type PipelineParamPresetUpdateResponse struct {
+	Header
+	Data *PipelineParamPresetDTO `json:"data"`
+}
+
+type PipelineParamPresetDeleteResponse struct {
+	Header
+	Data uint64 `json:"data"`
+}
+
+type PipelineParamPresetListRequest struct {
+	AppID           uint64 `schema:"appID"`
+	Branch          string `schema:"branch"`
+	PipelineYmlName string `schema:"pipelineYmlName"`
+}
+
+type PipelineParamPresetListResponse struct {
+	Header
+	Data []PipelineParamPresetDTO `json:"data"`
+}
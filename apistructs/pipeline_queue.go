@@ -274,3 +274,27 @@ func (r PipelineQueueValidateResult) IsSuccess() bool {
 func (r PipelineQueueValidateResult) IsFailed() bool {
 	return !r.Success
 }
+
+// PipelineQueueCreateResponse .
+type PipelineQueueCreateResponse struct {
+	Header
+	Data *PipelineQueue `json:"data"`
+}
+
+// PipelineQueueGetResponse .
+type PipelineQueueGetResponse struct {
+	Header
+	Data *PipelineQueue `json:"data"`
+}
+
+// PipelineQueuePagingResponse .
+type PipelineQueuePagingResponse struct {
+	Header
+	Data *PipelineQueuePagingData `json:"data"`
+}
+
+// PipelineQueueUpdateResponse .
+type PipelineQueueUpdateResponse struct {
+	Header
+	Data *PipelineQueue `json:"data"`
+}
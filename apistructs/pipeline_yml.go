@@ -41,6 +41,7 @@ type PipelineYml struct {
 	Version         string                 `json:"version"`                   // 版本
 	Envs            map[string]string      `json:"envs,omitempty"`            // 环境变量
 	Cron            string                 `json:"cron,omitempty"`            // 定时配置
+	CronTimeZone    string                 `json:"cronTimeZone,omitempty"`    // 定时时区
 	CronCompensator *CronCompensator       `json:"cronCompensator,omitempty"` // 定时补偿配置
 	Stages          [][]*PipelineYmlAction `json:"stages"`                    // 流水线
 	FlatActions     []*PipelineYmlAction   `json:"flatActions"`               // 展平了的流水线
@@ -68,6 +68,8 @@ type SonarStoreRequest struct {
 	Coverage         []*TestIssuesTree    `json:"coverage"`
 	Duplications     []*TestIssuesTree    `json:"duplications"`
 	IssuesStatistics TestIssuesStatistics `json:"issues_statistics"`
+	// SeverityThresholds 各严重级别允许的最大问题数，超过即阻断，如 {"BLOCKER": 0} 表示出现任何 BLOCKER 问题即失败
+	SeverityThresholds map[string]int `json:"severityThresholds,omitempty"`
 }
 
 type TextRange struct {
@@ -305,6 +307,8 @@ type ApiTestsActionRequest struct {
 	ProjectTestEnvID int64    `json:"projectTestEnvID"`
 	TestPlanID       int64    `json:"testPlanID"`
 	UsecaseIDs       []uint64 `json:"usecaseIDs"`
+	// Labels 附加到流水线上的标签
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // ApiTestsActionResponse 执行api测试的响应
@@ -357,13 +361,15 @@ type APITestEnvData struct {
 	Domain  string                         `json:"domain"`
 	Header  map[string]string              `json:"header"`
 	Global  map[string]*APITestEnvVariable `json:"global"`
+	Proxy   *APIProxyConfig                `json:"proxy,omitempty"`
 }
 
 // APITestEnvVariable API 测试环境变量值信息
 type APITestEnvVariable struct {
-	Value string `json:"value"`
-	Type  string `json:"type"`
-	Desc  string `json:"desc,omitempty"`
+	Value  string `json:"value"`
+	Type   string `json:"type"`
+	Desc   string `json:"desc,omitempty"`
+	Secret bool   `json:"secret,omitempty"` // 敏感变量, 日志中不打印明文
 }
 
 // APITestEnvResponse API测试环境变量信息响应
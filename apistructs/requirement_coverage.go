@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// RequirementCoverageRequest 查询需求的测试覆盖情况, 即需求是否关联了测试计划中的用例及用例最近一次执行结果
+type RequirementCoverageRequest struct {
+	ProjectID uint64 `schema:"projectID"`
+	// IterationID 为 0 时不按迭代过滤
+	IterationID int64 `schema:"iterationID"`
+	// IssueIDs 指定需求列表, 为空时查询项目 (迭代) 下的所有需求
+	IssueIDs []uint64 `schema:"issueID"`
+
+	IdentityInfo
+}
+
+type RequirementCoverageResponse struct {
+	Header
+	Data *RequirementCoverageResponseData `json:"data"`
+}
+
+type RequirementCoverageResponseData struct {
+	Total     uint64                `json:"total"`
+	Covered   uint64                `json:"covered"`
+	Uncovered uint64                `json:"uncovered"`
+	List      []RequirementCoverage `json:"list"`
+}
+
+// RequirementCoverage 单个需求的覆盖情况
+type RequirementCoverage struct {
+	IssueID     uint64 `json:"issueID"`
+	Title       string `json:"title"`
+	IterationID int64  `json:"iterationID"`
+	Covered     bool   `json:"covered"`
+	// ExecStatusCount 关联用例最近一次执行结果的统计
+	ExecStatusCount TestPlanRelsCount         `json:"execStatusCount"`
+	TestCases       []RequirementCoverageCase `json:"testCases"`
+}
+
+// RequirementCoverageCase 需求关联的用例及其最近一次执行结果, 未执行过时为 INIT
+type RequirementCoverageCase struct {
+	TestCaseID        uint64             `json:"testCaseID"`
+	Name              string             `json:"name"`
+	TestPlanID        uint64             `json:"testPlanID"`
+	TestPlanCaseRelID uint64             `json:"testPlanCaseRelID"`
+	ExecStatus        TestCaseExecStatus `json:"execStatus"`
+	ExecutorID        string             `json:"executorID"`
+	UpdatedAt         *time.Time         `json:"updatedAt"`
+}
@@ -102,3 +102,11 @@ func (req *SceneSetRequest) URLQueryString() map[string][]string {
 	}
 	return query
 }
+
+// SceneSetExecutionStatus 场景集所在集群中场景集执行的并发情况
+type SceneSetExecutionStatus struct {
+	ClusterName string `json:"clusterName"`
+	MaxParallel int64  `json:"maxParallel"` // 同时执行的场景集数上限, 为 0 时不限制, 此时不统计执行数
+	Running     int64  `json:"running"`     // 正在执行的场景集数
+	Queued      int64  `json:"queued"`      // 排队等待执行的场景集数
+}
@@ -14,8 +14,50 @@
 
 package apistructs
 
+import "time"
+
 type SonarIssueGetRequest struct {
 	Type  string `schema:"type"`
 	Key   string `schema:"key"`
 	AppID uint64 `schema:"applicationId"`
 }
+
+// SonarIssueTrendBucket 趋势统计的时间粒度
+type SonarIssueTrendBucket string
+
+var (
+	SonarIssueTrendBucketDay   SonarIssueTrendBucket = "day"
+	SonarIssueTrendBucketWeek  SonarIssueTrendBucket = "week"
+	SonarIssueTrendBucketMonth SonarIssueTrendBucket = "month"
+)
+
+// SonarIssueTrendRequest 查询应用代码质量趋势
+type SonarIssueTrendRequest struct {
+	AppID  uint64 `schema:"applicationId"`
+	Branch string `schema:"branch"` // 为空时统计所有分支
+
+	// 时间窗口，秒级时间戳，默认最近 30 天
+	StartTime int64 `schema:"startTime"`
+	EndTime   int64 `schema:"endTime"`
+	// 时间粒度，默认 day
+	Bucket SonarIssueTrendBucket `schema:"bucket"`
+}
+
+type SonarIssueTrendResponse struct {
+	Header
+	Data []SonarIssueTrendPoint `json:"data"`
+}
+
+// SonarIssueTrendPoint 一个时间段内的代码质量，取该时间段内最后一次分析的结果
+type SonarIssueTrendPoint struct {
+	Time            time.Time      `json:"time"` // 时间段起始时间
+	CommitID        string         `json:"commitId"`
+	Branch          string         `json:"branch"`
+	AnalyzedAt      time.Time      `json:"analyzedAt"`
+	Bugs            int            `json:"bugs"`
+	Vulnerabilities int            `json:"vulnerabilities"`
+	CodeSmells      int            `json:"codeSmells"`
+	Coverage        float64        `json:"coverage"`
+	Duplications    float64        `json:"duplications"`
+	Severities      map[string]int `json:"severities"` // 各严重级别的问题数
+}
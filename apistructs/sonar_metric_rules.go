@@ -15,6 +15,8 @@
 package apistructs
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -71,6 +73,7 @@ type SonarMetricRulesUpdateRequest struct {
 	MetricValue string `json:"metricValue"`
 	ScopeType   string `json:"scopeType"`
 	ScopeID     string `json:"scopeId"`
+	IsBlocking  *bool  `json:"isBlocking,omitempty"` // 是否为阻断规则，为空时不修改
 }
 
 // 批量插入
@@ -100,6 +103,7 @@ type SonarMetricRuleDto struct {
 	MetricKeyDesc string    `json:"metricKeyDesc"`
 	DecimalScale  int       `json:"decimalScale"`
 	ValueType     string    `json:"valueType"`
+	IsBlocking    bool      `json:"isBlocking"` // 违反阻断规则时流水线失败
 }
 
 // 删除
@@ -125,3 +129,85 @@ type SonarMetricRulesListResp struct {
 	Header
 	Results []*SonarMetricKey `json:"data"`
 }
+
+// SonarMetricRulesExportRequest 导出指标规则
+type SonarMetricRulesExportRequest struct {
+	ScopeType string `json:"scopeType"`
+	ScopeID   string `json:"scopeId"`
+}
+
+// SonarMetricRulesDocumentVersion 指标规则导出文件的格式版本
+const SonarMetricRulesDocumentVersion = 1
+
+// SonarMetricRulesDocument 可在项目间共享的指标规则文件，使用 metricKey 而非数据库 ID 标识指标
+type SonarMetricRulesDocument struct {
+	Version int                            `json:"version"`
+	Rules   []SonarMetricRulesDocumentRule `json:"rules"`
+}
+
+type SonarMetricRulesDocumentRule struct {
+	MetricKey   string `json:"metricKey"`
+	MetricValue string `json:"metricValue"`
+	Description string `json:"description,omitempty"`
+	IsBlocking  bool   `json:"isBlocking"`
+}
+
+type SonarMetricRulesExportResponse struct {
+	Header
+	Data SonarMetricRulesDocument `json:"data"`
+}
+
+// SonarMetricRulesImportMode 导入方式
+type SonarMetricRulesImportMode string
+
+var (
+	// SonarMetricRulesImportModeMerge 合并：更新同名指标，新增其余指标，保留文件中未出现的已有规则
+	SonarMetricRulesImportModeMerge SonarMetricRulesImportMode = "merge"
+	// SonarMetricRulesImportModeReplace 替换：导入后项目规则与文件完全一致
+	SonarMetricRulesImportModeReplace SonarMetricRulesImportMode = "replace"
+)
+
+// SonarMetricRulesImportRequest 导入指标规则
+type SonarMetricRulesImportRequest struct {
+	ScopeType string                     `json:"scopeType"`
+	ScopeID   string                     `json:"scopeId"`
+	Mode      SonarMetricRulesImportMode `json:"mode"` // 默认 merge
+	Document  SonarMetricRulesDocument   `json:"document"`
+}
+
+type SonarMetricRulesImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+}
+
+type SonarMetricRulesImportResponse struct {
+	Header
+	Data SonarMetricRulesImportResult `json:"data"`
+}
+
+// SonarQualityGateResult 代码质量门禁评估结果
+type SonarQualityGateResult struct {
+	Passed     bool                        `json:"passed"` // 未违反任何阻断规则
+	Violations []SonarQualityGateViolation `json:"violations"`
+}
+
+// SonarQualityGateViolation 违反的质量门禁规则
+type SonarQualityGateViolation struct {
+	MetricKey   string `json:"metricKey"`   // 指标，严重级别规则为 severity:<级别>
+	Operational string `json:"operational"` // > 或 <
+	Threshold   string `json:"threshold"`
+	Actual      string `json:"actual"`
+	Blocking    bool   `json:"blocking"`
+}
+
+// BlockingViolationsMessage 汇总违反的阻断规则
+func (r *SonarQualityGateResult) BlockingViolationsMessage() string {
+	var msgs []string
+	for _, v := range r.Violations {
+		if v.Blocking {
+			msgs = append(msgs, fmt.Sprintf("%s %s %s (actual %s)", v.MetricKey, v.Operational, v.Threshold, v.Actual))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
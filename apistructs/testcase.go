@@ -14,7 +14,10 @@
 
 package apistructs
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	// 测试集、用例是否回收
@@ -41,6 +44,9 @@ type TestCase struct {
 	Labels         []ProjectLabel          `json:"labels"`         // 标签
 	APIs           []*ApiTestInfo          `json:"apis"`           // 接口测试集合
 	APICount       TestCaseAPICount        `json:"apiCount"`
+	CopiedFromID   uint64                  `json:"copiedFromID"` // 复制来源用例 ID，非复制创建时为 0
+	ReviewState    TestCaseReviewState     `json:"reviewState"`  // 评审状态
+	LockVersion    uint64                  `json:"lockVersion"`  // 乐观锁版本号，每次更新递增，更新时传回以检测并发修改
 	CreatedAt      time.Time               `json:"createdAt"`
 	UpdatedAt      time.Time               `json:"updatedAt"`
 }
@@ -68,8 +74,9 @@ type TestCasePriority string
 
 // TestCaseStepAndResult 操作步骤信息
 type TestCaseStepAndResult struct {
-	Step   string `json:"step"`   // 操作步骤
-	Result string `json:"result"` // 预期结果
+	ID     uint64 `json:"id,omitempty"` // 步骤 ID，用例内唯一，用于调整步骤顺序
+	Step   string `json:"step"`         // 操作步骤
+	Result string `json:"result"`       // 预期结果
 }
 
 var (
@@ -123,6 +130,7 @@ type TestCaseCreateRequest struct {
 	Desc           string                  `json:"desc"`           // 补充说明
 	Priority       TestCasePriority        `json:"priority"`       // 优先级
 	LabelIDs       []uint64                `json:"labelIDs"`       // 关联缺陷 IDs
+	CopiedFromID   uint64                  `json:"-"`              // 复制来源用例 ID，仅批量复制时使用
 
 	IdentityInfo
 }
@@ -177,10 +185,12 @@ type TestCasePagingRequest struct {
 	TestCaseIDs      []uint64            `schema:"testCaseID"`      // 内部使用，全量测试用例列表，最终结果为子集
 	NotInTestCaseIDs []uint64            `schema:"-"`               // 内部使用，NotInTestPlanIDs 会转换为 NotInTestCaseIDs 列表
 	TestSetCaseMap   map[uint64][]uint64 `schema:"-"`               // 内部使用,测试集和用例关系
+	ExcludeTestSetIDs []uint64          `schema:"-"`               // 内部使用，用户无权查看的测试集，其下的用例不返回
 
-	Query      string             `schema:"query"`     // title 过滤
-	Priorities []TestCasePriority `schema:"priority"`  // 优先级
-	UpdaterIDs []string           `schema:"updaterID"` // 更新人 ID 列表
+	Query        string                `schema:"query"`       // title 过滤
+	Priorities   []TestCasePriority    `schema:"priority"`    // 优先级
+	UpdaterIDs   []string              `schema:"updaterID"`   // 更新人 ID 列表
+	ReviewStates []TestCaseReviewState `schema:"reviewState"` // 评审状态
 
 	// 更新时间，外部传参使用时间戳
 	TimestampSecUpdatedAtBegin *time.Duration `schema:"timestampSecUpdatedAtBegin"` // 更新时间左值, 包含区间值
@@ -223,6 +233,101 @@ type TestCasePagingResponseData struct {
 	UserIDs  []string           `json:"userIDs,omitempty"`
 }
 
+// TestCaseSearchRequest 测试用例全文搜索，匹配标题、前置条件、操作步骤及预期结果
+type TestCaseSearchRequest struct {
+	// 分页参数
+	PageNo   int64 `schema:"pageNo"`
+	PageSize int64 `schema:"pageSize"`
+
+	ProjectID  uint64             `schema:"projectID"` // 项目 ID，必填
+	TestSetID  uint64             `schema:"testSetID"` // 限定测试集，包含子测试集，默认为整个项目
+	Query      string             `schema:"query"`     // 搜索关键字，多个关键字以空格分隔，需全部命中
+	Priorities []TestCasePriority `schema:"priority"`  // 优先级
+
+	ExcludeTestSetIDs []uint64 `schema:"-"` // 内部使用，用户无权查看的测试集，其下的用例不返回
+
+	IdentityInfo
+}
+
+type TestCaseSearchResponse struct {
+	Header
+	Data *TestCaseSearchResponseData `json:"data"`
+}
+
+type TestCaseSearchResponseData struct {
+	Total uint64              `json:"total"`
+	List  []TestCaseSearchHit `json:"list"`
+}
+
+// TestCaseSearchField 搜索命中的用例字段
+type TestCaseSearchField string
+
+var (
+	TestCaseSearchFieldName         TestCaseSearchField = "name"
+	TestCaseSearchFieldPreCondition TestCaseSearchField = "preCondition"
+	TestCaseSearchFieldStep         TestCaseSearchField = "step"
+	TestCaseSearchFieldResult       TestCaseSearchField = "result"
+)
+
+// TestCaseSearchHit 搜索结果，按相关度降序排列
+type TestCaseSearchHit struct {
+	ID         uint64                    `json:"id"`
+	Name       string                    `json:"name"`
+	Priority   TestCasePriority          `json:"priority"`
+	TestSetID  uint64                    `json:"testSetID"`
+	Directory  string                    `json:"directory"`  // 所属测试集路径
+	Score      int                       `json:"score"`      // 相关度
+	Highlights []TestCaseSearchHighlight `json:"highlights"` // 命中片段
+}
+
+// TestCaseSearchHighlight 命中片段，关键字以 <em></em> 包裹
+type TestCaseSearchHighlight struct {
+	Field   TestCaseSearchField `json:"field"`
+	Snippet string              `json:"snippet"`
+}
+
+// TestCaseDuplicateDetectRequest 检测测试集内标题、步骤高度相似的重复用例
+type TestCaseDuplicateDetectRequest struct {
+	// 分页参数，按重复用例组分页
+	PageNo   int64 `schema:"pageNo"`
+	PageSize int64 `schema:"pageSize"`
+
+	ProjectID uint64  `schema:"projectID"` // 项目 ID，必填
+	TestSetID uint64  `schema:"testSetID"` // 测试集，包含子测试集，默认为整个项目
+	Threshold float64 `schema:"threshold"` // 相似度阈值，取值 (0, 1]，默认使用服务端配置
+
+	ExcludeTestSetIDs []uint64 `schema:"-"` // 内部使用，用户无权查看的测试集，其下的用例不参与检测
+
+	IdentityInfo
+}
+
+type TestCaseDuplicateDetectResponse struct {
+	Header
+	Data *TestCaseDuplicateDetectResponseData `json:"data"`
+}
+
+type TestCaseDuplicateDetectResponseData struct {
+	Total     uint64  `json:"total"`
+	Threshold float64 `json:"threshold"`
+	// Truncated 用例数超过检测上限时为 true，此时只检测了最新创建的部分用例，可缩小测试集范围后重新检测
+	Truncated bool                       `json:"truncated"`
+	List      []TestCaseDuplicateCluster `json:"list"`
+}
+
+// TestCaseDuplicateCluster 一组疑似重复的用例，按最高相似度降序排列
+type TestCaseDuplicateCluster struct {
+	Score     float64                 `json:"score"` // 组内用例两两之间的最高相似度
+	TestCases []TestCaseDuplicateItem `json:"testCases"`
+}
+
+type TestCaseDuplicateItem struct {
+	ID        uint64           `json:"id"`
+	Name      string           `json:"name"`
+	Priority  TestCasePriority `json:"priority"`
+	TestSetID uint64           `json:"testSetID"`
+	Directory string           `json:"directory"` // 所属测试集路径
+}
+
 // TestSetWithCases 测试集且包含测试用例
 type TestSetWithCases struct {
 	TestSetID uint64     `json:"testSetID"` // 所属测试集 ID
@@ -252,6 +357,9 @@ type TestCaseUpdateRequest struct {
 	APIs           []*ApiTestInfo          `json:"apis"`               // 接口测试集合，更新、创建或删除
 	Desc           string                  `json:"desc"`               // 补充说明
 	LabelIDs       []uint64                `json:"labelIDs,omitempty"` // 标签列表
+	LockVersion    *uint64                 `json:"lockVersion"`        // 读取用例时的乐观锁版本号，用例已被他人修改时更新失败；为空时不校验
+
+	RestoredFromVersion uint64 `json:"-"` // 内部使用，从历史版本恢复时的版本号
 
 	IdentityInfo
 }
@@ -261,6 +369,73 @@ type TestCaseUpdateResponse struct {
 	Header
 }
 
+// TestCaseStepReorderRequest 调整测试用例步骤顺序，StepIDs 须与用例当前的步骤 ID 集合一致
+type TestCaseStepReorderRequest struct {
+	TestCaseID uint64   `json:"-"`
+	StepIDs    []uint64 `json:"stepIDs"` // 调整后的步骤 ID 顺序
+
+	IdentityInfo
+}
+
+type TestCaseStepReorderResponse struct {
+	Header
+	Data []TestCaseStepAndResult `json:"data"`
+}
+
+// TestCaseVersion 测试用例历史版本快照，每次更新都会生成新版本
+type TestCaseVersion struct {
+	ID                  uint64                  `json:"id"`
+	TestCaseID          uint64                  `json:"testCaseID"`
+	Version             uint64                  `json:"version"` // 版本号，从 1 开始递增
+	Name                string                  `json:"name"`
+	Priority            TestCasePriority        `json:"priority"`
+	PreCondition        string                  `json:"preCondition"`
+	StepAndResults      []TestCaseStepAndResult `json:"stepAndResults"`
+	APIs                []TestCaseVersionAPI    `json:"apis"`
+	Desc                string                  `json:"desc"`
+	OperatorID          string                  `json:"operatorID"`          // 变更人
+	RestoredFromVersion uint64                  `json:"restoredFromVersion"` // 由哪个版本恢复而来，非恢复时为 0
+	CreatedAt           time.Time               `json:"createdAt"`           // 变更时间
+}
+
+// TestCaseVersionAPI 历史版本中的接口定义
+type TestCaseVersionAPI struct {
+	ApiID   int64  `json:"apiID"`
+	ApiInfo string `json:"apiInfo"`
+}
+
+// TestCaseHistoryListRequest 查询测试用例历史版本
+type TestCaseHistoryListRequest struct {
+	TestCaseID uint64 `schema:"-"`
+
+	IdentityInfo
+}
+
+type TestCaseHistoryListResponse struct {
+	Header
+	UserInfoHeader
+	Data []TestCaseVersion `json:"data"`
+}
+
+type TestCaseHistoryGetResponse struct {
+	Header
+	UserInfoHeader
+	Data *TestCaseVersion `json:"data"`
+}
+
+// TestCaseHistoryRestoreRequest 将测试用例恢复至指定历史版本，恢复本身会生成新版本
+type TestCaseHistoryRestoreRequest struct {
+	TestCaseID uint64 `json:"-"`
+	Version    uint64 `json:"-"`
+
+	IdentityInfo
+}
+
+type TestCaseHistoryRestoreResponse struct {
+	Header
+	Data *TestCaseVersion `json:"data"`
+}
+
 // TestCaseBatchUpdateRequest 测试用例批量更新请求
 type TestCaseBatchUpdateRequest struct {
 	Priority        TestCasePriority `json:"priority"`
@@ -303,9 +478,9 @@ type TestCaseBatchUpdateResponse struct {
 }
 
 type TestCaseBatchCopyRequest struct {
-	CopyToTestSetID uint64 `json:"copyToTestSetID"`
+	CopyToTestSetID uint64 `json:"copyToTestSetID"` // 目标测试集 ID，可以属于其他项目
 
-	ProjectID   uint64   `json:"projectID"`
+	ProjectID   uint64   `json:"projectID"` // 复制到根目录时的目标项目 ID，指定目标测试集时以测试集所属项目为准
 	TestCaseIDs []uint64 `json:"testCaseIDs"`
 
 	IdentityInfo
@@ -360,9 +535,39 @@ type TestCaseImportRequest struct {
 	TestSetID uint64           `schema:"testSetID"`
 	ProjectID uint64           `schema:"projectID"`
 	FileType  TestCaseFileType `schema:"fileType"`
+	// ColumnMapping 自定义 Excel 列映射，为空时按固定模板解析
+	ColumnMapping *TestCaseExcelColumnMapping `schema:"columnMapping"`
 
 	IdentityInfo
 }
+
+// TestCaseExcelColumnMapping 自定义 Excel 列与用例字段的映射
+// 列可以填写表头名称，也可以填写列号，如 A、B、AA
+type TestCaseExcelColumnMapping struct {
+	Title        string `schema:"title"`
+	Directory    string `schema:"directory"`
+	PreCondition string `schema:"preCondition"`
+	Step         string `schema:"step"`
+	Result       string `schema:"result"`
+	Priority     string `schema:"priority"`
+	// HeaderRows 表头行数，默认 1
+	HeaderRows int `schema:"headerRows"`
+}
+
+// Validate 校验必须映射的字段
+func (m TestCaseExcelColumnMapping) Validate() error {
+	if m.Title == "" {
+		return fmt.Errorf("title column is not mapped")
+	}
+	if m.Result != "" && m.Step == "" {
+		return fmt.Errorf("step column must be mapped when result column is mapped")
+	}
+	if m.HeaderRows < 0 {
+		return fmt.Errorf("invalid headerRows: %d", m.HeaderRows)
+	}
+	return nil
+}
+
 type TestCaseImportResponse struct {
 	Header
 	Data *TestCaseImportResult `json:"data"`
@@ -370,6 +575,16 @@ type TestCaseImportResponse struct {
 type TestCaseImportResult struct {
 	SuccessCount uint64 `json:"successCount"`
 	Id           uint64 `json:"id"`
+	// FailedCount 导入失败的用例数
+	FailedCount uint64 `json:"failedCount"`
+	// Errors 导入失败的行及原因
+	Errors []TestCaseImportRowError `json:"errors,omitempty"`
+}
+
+// TestCaseImportRowError 导入失败的 Excel 行
+type TestCaseImportRowError struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
 }
 
 // TestCaseExcel 测试用例 Excel
@@ -380,6 +595,8 @@ type TestCaseExcel struct {
 	PreCondition   string                  `title:"前置条件"`
 	StepAndResults []TestCaseStepAndResult `title:"步骤与结果" group:"StepAndResults"`
 	ApiInfos       []APIInfo               `title:"接口测试" group:"ApiInfos"`
+	// LineNum 用例在 Excel 中的起始行号，用于导入错误提示
+	LineNum int `json:"-"`
 }
 
 // TestCaseXmind 测试用例 Xmind
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// TestCaseAttachment 测试用例或用例执行结果的附件, 文件保存在文件服务中
+type TestCaseAttachment struct {
+	ID         uint64 `json:"id"`
+	ProjectID  uint64 `json:"projectID"`
+	TestCaseID uint64 `json:"testCaseID"`
+	// TestPlanCaseRelID 执行结果附件所属的测试计划用例关系 ID, 用例本身的附件为 0
+	TestPlanCaseRelID uint64    `json:"testPlanCaseRelID"`
+	Name              string    `json:"name"`
+	ContentType       string    `json:"contentType"`
+	Size              int64     `json:"size"`
+	FileUUID          string    `json:"fileUUID"`
+	URL               string    `json:"url"`
+	CreatorID         string    `json:"creatorID"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+// TestCaseAttachmentTarget 附件的归属对象
+type TestCaseAttachmentTarget struct {
+	ProjectID         uint64
+	TestCaseID        uint64
+	TestPlanID        uint64
+	TestPlanCaseRelID uint64
+}
+
+// TestCaseAttachmentCreateRequest 上传附件, 文件内容以 multipart 的 file 字段上传
+type TestCaseAttachmentCreateRequest struct {
+	TestCaseAttachmentTarget
+
+	Name        string
+	ContentType string
+
+	IdentityInfo
+}
+
+type TestCaseAttachmentCreateResponse struct {
+	Header
+	Data *TestCaseAttachment `json:"data"`
+}
+
+type TestCaseAttachmentListResponse struct {
+	Header
+	Data []TestCaseAttachment `json:"data"`
+}
+
+type TestCaseAttachmentDeleteResponse struct {
+	Header
+	Data uint64 `json:"data"`
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// TestCaseAuditAction 测试用例审计操作类型
+type TestCaseAuditAction string
+
+const (
+	TestCaseAuditActionUpdate  TestCaseAuditAction = "UPDATE"
+	TestCaseAuditActionMove    TestCaseAuditAction = "MOVE"
+	TestCaseAuditActionCopy    TestCaseAuditAction = "COPY"
+	TestCaseAuditActionRecycle TestCaseAuditAction = "RECYCLE"
+	TestCaseAuditActionRecover TestCaseAuditAction = "RECOVER"
+	TestCaseAuditActionDelete  TestCaseAuditAction = "DELETE"
+)
+
+// TestCaseAuditFieldChange 单个字段的变更, Before 与 After 为字段值的文本形式
+type TestCaseAuditFieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// TestCaseAuditLog 测试用例审计日志, 记录每次变更操作的操作人、时间及字段级变更
+type TestCaseAuditLog struct {
+	ID         uint64                     `json:"id"`
+	ProjectID  uint64                     `json:"projectID"`
+	TestCaseID uint64                     `json:"testCaseID"`
+	Action     TestCaseAuditAction        `json:"action"`
+	OperatorID string                     `json:"operatorID"`
+	Changes    []TestCaseAuditFieldChange `json:"changes"`
+	CreatedAt  time.Time                  `json:"createdAt"`
+}
+
+// TestCaseAuditLogListRequest 分页查询测试用例审计日志, 用例被彻底删除后仍可查询
+type TestCaseAuditLogListRequest struct {
+	PageNo   int64 `schema:"pageNo"`
+	PageSize int64 `schema:"pageSize"`
+
+	ProjectID  uint64 `schema:"projectID"`
+	TestCaseID uint64 `schema:"-"`
+
+	IdentityInfo
+}
+
+type TestCaseAuditLogListResponse struct {
+	Header
+	UserInfoHeader
+	Data *TestCaseAuditLogListResponseData `json:"data"`
+}
+
+type TestCaseAuditLogListResponseData struct {
+	Total uint64             `json:"total"`
+	List  []TestCaseAuditLog `json:"list"`
+}
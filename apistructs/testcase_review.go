@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// TestCaseReviewState 测试用例评审状态, 只有已通过评审的用例才是生效的用例
+type TestCaseReviewState string
+
+const (
+	TestCaseReviewStateDraft    TestCaseReviewState = "DRAFT"
+	TestCaseReviewStateInReview TestCaseReviewState = "IN_REVIEW"
+	TestCaseReviewStateApproved TestCaseReviewState = "APPROVED"
+)
+
+func (s TestCaseReviewState) IsValid() bool {
+	switch s {
+	case TestCaseReviewStateDraft, TestCaseReviewStateInReview, TestCaseReviewStateApproved:
+		return true
+	default:
+		return false
+	}
+}
+
+// TestCaseReviewAction 测试用例评审操作
+type TestCaseReviewAction string
+
+const (
+	// TestCaseReviewActionSubmit 提交评审: DRAFT -> IN_REVIEW
+	TestCaseReviewActionSubmit TestCaseReviewAction = "SUBMIT"
+	// TestCaseReviewActionApprove 评审通过: IN_REVIEW -> APPROVED
+	TestCaseReviewActionApprove TestCaseReviewAction = "APPROVE"
+	// TestCaseReviewActionReject 评审驳回: IN_REVIEW -> DRAFT, 必须填写评审意见
+	TestCaseReviewActionReject TestCaseReviewAction = "REJECT"
+)
+
+// TestCaseReview 测试用例评审记录
+type TestCaseReview struct {
+	ID         uint64               `json:"id"`
+	TestCaseID uint64               `json:"testCaseID"`
+	Action     TestCaseReviewAction `json:"action"`
+	FromState  TestCaseReviewState  `json:"fromState"`
+	ToState    TestCaseReviewState  `json:"toState"`
+	OperatorID string               `json:"operatorID"`
+	Comment    string               `json:"comment"`
+	CreatedAt  time.Time            `json:"createdAt"`
+}
+
+// TestCaseReviewRequest 测试用例评审状态流转请求
+type TestCaseReviewRequest struct {
+	TestCaseID uint64               `json:"-"`
+	Action     TestCaseReviewAction `json:"-"`
+	Comment    string               `json:"comment"`
+
+	IdentityInfo
+}
+
+type TestCaseReviewResponse struct {
+	Header
+	Data *TestCaseReview `json:"data"`
+}
+
+// TestCaseReviewListResponse 按时间倒序返回测试用例的评审记录
+type TestCaseReviewListResponse struct {
+	Header
+	UserInfoHeader
+	Data []TestCaseReview `json:"data"`
+}
@@ -37,6 +37,54 @@ type TestPlan struct {
 	Type       TestPlanType      `json:"type"`
 	Inode      string            `json:"inode,omitempty"`
 	IsArchived bool              `json:"isArchived"`
+	// ReportEmail 执行完成后邮件发送测试报告的配置
+	ReportEmail *TestPlanReportEmailConfig `json:"reportEmail,omitempty"`
+	// AutoCreateBug 用例执行未通过时是否自动创建缺陷
+	AutoCreateBug bool `json:"autoCreateBug"`
+	// ApprovedCasesOnly 是否只允许关联评审通过的用例
+	ApprovedCasesOnly bool `json:"approvedCasesOnly"`
+	// ReportFields 测试报告中的自定义字段
+	ReportFields []TestPlanReportField `json:"reportFields"`
+}
+
+// TestPlanAutoBugIssueSource 用例执行未通过时自动创建的缺陷来源
+const TestPlanAutoBugIssueSource = "testplan-auto-bug"
+
+// TestPlanReportEmailConfig 测试计划执行完成后邮件发送测试报告的配置
+type TestPlanReportEmailConfig struct {
+	Enabled bool `json:"enabled"`
+	// Recipients 收件人邮箱列表
+	Recipients []string `json:"recipients"`
+	// CcMembers 是否抄送测试计划的负责人和参与者
+	CcMembers bool `json:"ccMembers"`
+}
+
+// TestPlanReportFieldType 测试报告自定义字段的类型
+type TestPlanReportFieldType string
+
+const (
+	TestPlanReportFieldTypeText TestPlanReportFieldType = "TEXT"
+	TestPlanReportFieldTypeDate TestPlanReportFieldType = "DATE" // 值的格式为 2006-01-02
+	TestPlanReportFieldTypeEnum TestPlanReportFieldType = "ENUM" // 值为 options 之一
+)
+
+func (t TestPlanReportFieldType) IsValid() bool {
+	switch t {
+	case TestPlanReportFieldTypeText, TestPlanReportFieldTypeDate, TestPlanReportFieldTypeEnum:
+		return true
+	default:
+		return false
+	}
+}
+
+// TestPlanReportField 测试报告的自定义字段, 如发布版本、构建号、签字人等, 生成报告时记录当前填写的值
+type TestPlanReportField struct {
+	Name     string                  `json:"name"`
+	Type     TestPlanReportFieldType `json:"type"`
+	Required bool                    `json:"required"`
+	// Options 枚举类型的可选值
+	Options []string `json:"options,omitempty"`
+	Value   string   `json:"value"`
 }
 
 // TestPlanRelsCount 测试计划关联的测试用例状态个数
@@ -82,6 +130,8 @@ type TestPlanCreateRequest struct {
 
 	// 是否是自动化测试计划
 	IsAutoTest bool `json:"isAutoTest"`
+	// ApprovedCasesOnly 是否只允许关联评审通过的用例
+	ApprovedCasesOnly bool `json:"approvedCasesOnly"`
 
 	IdentityInfo
 }
@@ -104,6 +154,14 @@ type TestPlanUpdateRequest struct {
 
 	TestPlanID uint64 `json:"-"`
 	IsArchived *bool  `json:"isArchived"`
+	// ReportEmail 不为空时覆盖邮件发送测试报告的配置
+	ReportEmail *TestPlanReportEmailConfig `json:"reportEmail"`
+	// AutoCreateBug 用例执行未通过时是否自动创建缺陷
+	AutoCreateBug *bool `json:"autoCreateBug"`
+	// ApprovedCasesOnly 是否只允许关联评审通过的用例
+	ApprovedCasesOnly *bool `json:"approvedCasesOnly"`
+	// ReportFields 不为空时覆盖测试报告的自定义字段及填写的值, 传空数组时清空
+	ReportFields []TestPlanReportField `json:"reportFields"`
 
 	IdentityInfo
 }
@@ -216,6 +274,75 @@ type TestPlanAPITestExecuteResponse struct {
 	Data uint64 `json:"data"` // triggered pipeline id
 }
 
+// LabelTestPlanRerunFromPipelineID 重跑失败用例触发的流水线标签, 值为被重跑的原流水线 id
+const LabelTestPlanRerunFromPipelineID = "testPlanRerunFromPipelineID"
+
+// TestPlanAPITestRerunFailedRequest 重跑测试计划某次接口测试中未通过的用例
+type TestPlanAPITestRerunFailedRequest struct {
+	TestPlanID uint64 `json:"-"`
+	// PipelineID 被重跑的接口测试流水线 id
+	PipelineID uint64 `json:"pipelineID"`
+
+	IdentityInfo
+}
+
+// TestPlanAPITestRerunFailedResult 重跑结果, 包含新流水线及本次重跑的用例
+type TestPlanAPITestRerunFailedResult struct {
+	PipelineID          uint64              `json:"pipelineID"`
+	RerunFromPipelineID uint64              `json:"rerunFromPipelineID"`
+	EnvID               uint64              `json:"envID"`
+	Cases               []TestPlanRerunCase `json:"cases"`
+}
+
+// TestPlanRerunCase 被重跑的测试计划用例
+type TestPlanRerunCase struct {
+	RelationID uint64 `json:"relationID"`
+	TestCaseID uint64 `json:"testCaseID"`
+	Name       string `json:"name"`
+	// FailedAPICount 原流水线中未通过的接口数
+	FailedAPICount int `json:"failedAPICount"`
+}
+
+type TestPlanAPITestRerunFailedResponse struct {
+	Header
+	Data *TestPlanAPITestRerunFailedResult `json:"data"`
+}
+
+// TestPlanAPITestCompareRequest 对比测试计划两次接口测试中各用例的结果
+type TestPlanAPITestCompareRequest struct {
+	TestPlanID uint64 `json:"-"`
+	// BasePipelineID 作为基准的接口测试流水线 id, 一般为较早的一次
+	BasePipelineID uint64 `json:"basePipelineID"`
+	// PipelineID 与基准对比的接口测试流水线 id
+	PipelineID uint64 `json:"pipelineID"`
+
+	IdentityInfo
+}
+
+// TestPlanAPITestCompareResult 对比结果, 只包含两次均执行过的用例;
+// 重跑流水线中未重跑的用例沿用被重跑流水线中的结果, 与重跑后合并用例执行状态的规则一致
+type TestPlanAPITestCompareResult struct {
+	BasePipelineID uint64                       `json:"basePipelineID"`
+	PipelineID     uint64                       `json:"pipelineID"`
+	NewlyFailed    []TestPlanAPITestCompareCase `json:"newlyFailed"`  // 基准中通过, 本次未通过
+	NewlyPassed    []TestPlanAPITestCompareCase `json:"newlyPassed"`  // 基准中未通过, 本次通过
+	StillFailing   []TestPlanAPITestCompareCase `json:"stillFailing"` // 两次均未通过
+}
+
+// TestPlanAPITestCompareCase 两次接口测试中结果发生变化或持续未通过的用例
+type TestPlanAPITestCompareCase struct {
+	RelationID uint64         `json:"relationID"`
+	TestCaseID uint64         `json:"testCaseID"`
+	Name       string         `json:"name"`
+	BaseStatus PipelineStatus `json:"baseStatus"`
+	Status     PipelineStatus `json:"status"`
+}
+
+type TestPlanAPITestCompareResponse struct {
+	Header
+	Data *TestPlanAPITestCompareResult `json:"data"`
+}
+
 type AutotestExecuteTestPlansRequest struct {
 	TestPlan               TestPlanV2        `json:"testPlan"`
 	ClusterName            string            `json:"clusterName"`
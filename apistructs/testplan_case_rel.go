@@ -73,6 +73,8 @@ type TestPlanCaseRelCreateResponse struct {
 }
 type TestPlanCaseRelCreateResult struct {
 	TotalCount uint64 `json:"totalCount"`
+	// UnapprovedCount 测试计划只允许关联评审通过的用例时, 被跳过的未通过评审的用例个数
+	UnapprovedCount uint64 `json:"unapprovedCount"`
 }
 
 type TestPlanCaseRelGetRequest struct {
@@ -88,6 +90,8 @@ type TestPlanCaseRelBatchUpdateRequest struct {
 	Delete     bool               `json:"delete"`
 	ExecutorID string             `json:"executorID"`
 	ExecStatus TestCaseExecStatus `json:"execStatus"`
+	// ExecDurationSec 手动执行的耗时(秒), 仅在更新单个用例的执行结果时可填
+	ExecDurationSec *uint64 `json:"execDurationSec"`
 
 	TestPlanID  uint64   `json:"-"`
 	TestSetID   *uint64  `json:"testSetID"` // 批量递归操作测试集下的所有关联；与 relationIDs 的关系为 并集
@@ -120,6 +124,7 @@ type TestPlanCaseRelListRequest struct {
 	UpdaterIDs            []string             `schema:"updaterID"`
 	ExecutorIDs           []string             `schema:"executorID"`
 	ExecStatuses          []TestCaseExecStatus `schema:"execStatus"`
+	TestCaseIDs           []uint64             `schema:"testCaseID"`
 	UpdatedAtBeginInclude *time.Time
 	UpdatedAtEndInclude   *time.Time
 	IDOnly                bool
@@ -155,10 +160,83 @@ type TestPlanReport struct {
 	RelsCount      TestPlanRelsCount            `json:"relsCount"`
 	APICount       TestCaseAPICount             `json:"apiCount"`
 	ExecutorStatus map[string]TestPlanRelsCount `json:"executorStatus"`
+	// CustomFields 生成报告时测试计划自定义字段的值
+	CustomFields []TestPlanReportField `json:"customFields"`
 
 	UserIDs []string `json:"userIDs"`
 }
 
+// TestPlanBurndownResponse 测试计划燃尽图数据响应
+type TestPlanBurndownResponse struct {
+	Header
+	Data *TestPlanBurndown `json:"data"`
+}
+
+// TestPlanBurndown 测试计划按天统计的执行进度, 用于绘制燃尽图
+type TestPlanBurndown struct {
+	TestPlanID uint64 `json:"testPlanID"`
+	StartDate  string `json:"startDate"` // 统计开始日期, 为测试计划开始时间, 未设置时为创建时间
+	EndDate    string `json:"endDate"`   // 统计结束日期, 为测试计划结束时间与当天中较早的一天
+	Total      int    `json:"total"`     // 当前测试计划内的用例总数
+
+	Days []TestPlanBurndownDay `json:"days"`
+}
+
+// TestPlanBurndownDay 截至当天结束时测试计划内用例的执行情况
+type TestPlanBurndownDay struct {
+	Date      string `json:"date"`
+	Total     int    `json:"total"`    // 当天结束时计划内的用例数
+	Executed  int    `json:"executed"` // 已执行, 即通过、未通过及阻塞之和
+	Passed    int    `json:"passed"`
+	Failed    int    `json:"failed"`
+	Blocked   int    `json:"blocked"`
+	Remaining int    `json:"remaining"` // 未执行
+}
+
+// TestCaseExecDurationSource 用例执行耗时的来源
+type TestCaseExecDurationSource string
+
+const (
+	TestCaseExecDurationSourceManual    TestCaseExecDurationSource = "MANUAL"    // 手动执行, 由执行人填写
+	TestCaseExecDurationSourceAutomated TestCaseExecDurationSource = "AUTOMATED" // 接口测试, 取流水线中任务的实际耗时
+)
+
+// TestPlanExecDurationsResponse 测试计划用例执行耗时统计响应
+type TestPlanExecDurationsResponse struct {
+	Header
+	Data *TestPlanExecDurations `json:"data"`
+}
+
+// TestPlanExecDurations 测试计划内各用例的历史执行耗时统计, 统计范围包含用例在所有测试计划中的执行记录
+type TestPlanExecDurations struct {
+	TestPlanID uint64 `json:"testPlanID"`
+	Total      int    `json:"total"`
+	// EstimatedManualSec 按手动执行平均耗时估算的总耗时, 无手动执行记录的用例不计入
+	EstimatedManualSec uint64 `json:"estimatedManualSec"`
+	// EstimatedAutomatedSec 按接口测试平均耗时估算的总耗时, 无接口测试记录的用例不计入
+	EstimatedAutomatedSec uint64 `json:"estimatedAutomatedSec"`
+
+	List []TestPlanCaseExecDuration `json:"list"`
+}
+
+// TestPlanCaseExecDuration 测试计划用例的执行耗时统计, 手动执行与接口测试分开统计, 无记录时为空
+type TestPlanCaseExecDuration struct {
+	RelationID uint64                     `json:"relationID"`
+	TestCaseID uint64                     `json:"testCaseID"`
+	Name       string                     `json:"name"`
+	Manual     *TestCaseExecDurationStats `json:"manual"`
+	Automated  *TestCaseExecDurationStats `json:"automated"`
+}
+
+// TestCaseExecDurationStats 用例最近若干次执行的耗时统计, 单位为秒
+type TestCaseExecDurationStats struct {
+	Count          int       `json:"count"`
+	AvgSec         uint64    `json:"avgSec"`
+	MedianSec      uint64    `json:"medianSec"`
+	LastSec        uint64    `json:"lastSec"`
+	LastExecutedAt time.Time `json:"lastExecutedAt"`
+}
+
 // TestPlanCaseRelIssueRelationRemoveRequest 解除测试计划用例与缺陷关联关系请求
 type TestPlanCaseRelIssueRelationRemoveRequest struct {
 	IssueTestCaseRelationIDs []uint64 `json:"issueTestCaseRelationIDs"`
@@ -28,13 +28,14 @@ type TestPlanMember struct {
 type TestPlanMemberRole string
 
 var (
-	TestPlanMemberRoleOwner   TestPlanMemberRole = "Owner"
-	TestPlanMemberRolePartner TestPlanMemberRole = "Partner"
+	TestPlanMemberRoleOwner   TestPlanMemberRole = "Owner"   // 所有者, 可执行及管理测试计划
+	TestPlanMemberRolePartner TestPlanMemberRole = "Partner" // 执行者, 可执行测试计划, 不可修改或删除测试计划
+	TestPlanMemberRoleViewer  TestPlanMemberRole = "Viewer"  // 观察者, 只读
 )
 
 func (role TestPlanMemberRole) Valid() bool {
 	switch role {
-	case TestPlanMemberRoleOwner, TestPlanMemberRolePartner:
+	case TestPlanMemberRoleOwner, TestPlanMemberRolePartner, TestPlanMemberRoleViewer:
 		return true
 	default:
 		return false
@@ -49,3 +50,67 @@ func (role TestPlanMemberRole) IsOwner() bool {
 func (role TestPlanMemberRole) IsPartner() bool {
 	return role == TestPlanMemberRolePartner
 }
+func (role TestPlanMemberRole) IsViewer() bool {
+	return role == TestPlanMemberRoleViewer
+}
+
+// Allow 角色是否允许进行该类操作
+func (role TestPlanMemberRole) Allow(permission TestPlanMemberPermission) bool {
+	switch permission {
+	case TestPlanMemberPermissionExecute:
+		return role.IsOwner() || role.IsPartner()
+	case TestPlanMemberPermissionManage:
+		return role.IsOwner()
+	default:
+		return false
+	}
+}
+
+// TestPlanMemberPermission 受成员角色限制的测试计划操作
+type TestPlanMemberPermission string
+
+var (
+	// TestPlanMemberPermissionExecute 执行用例、更新执行结果、执行接口测试等
+	TestPlanMemberPermissionExecute TestPlanMemberPermission = "Execute"
+	// TestPlanMemberPermissionManage 修改、删除测试计划, 增删计划内用例及管理成员
+	TestPlanMemberPermissionManage TestPlanMemberPermission = "Manage"
+)
+
+// TestPlanMemberCreateRequest 添加测试计划成员
+type TestPlanMemberCreateRequest struct {
+	TestPlanID uint64             `json:"-"`
+	UserID     string             `json:"userID"`
+	Role       TestPlanMemberRole `json:"role"`
+
+	IdentityInfo
+}
+
+type TestPlanMemberCreateResponse struct {
+	Header
+	Data *TestPlanMember `json:"data"`
+}
+
+// TestPlanMemberUpdateRequest 修改测试计划成员的角色
+type TestPlanMemberUpdateRequest struct {
+	TestPlanID uint64             `json:"-"`
+	UserID     string             `json:"-"`
+	Role       TestPlanMemberRole `json:"role"`
+
+	IdentityInfo
+}
+
+type TestPlanMemberUpdateResponse struct {
+	Header
+	Data *TestPlanMember `json:"data"`
+}
+
+type TestPlanMemberListResponse struct {
+	Header
+	UserInfoHeader
+	Data []TestPlanMember `json:"data"`
+}
+
+type TestPlanMemberDeleteResponse struct {
+	Header
+	Data string `json:"data"`
+}
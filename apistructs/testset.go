@@ -14,6 +14,8 @@
 
 package apistructs
 
+import "time"
+
 // TestSetCreateRequest POST /api/testsets 创建测试集返回结构
 type TestSet struct {
 	// 测试集ID
@@ -114,6 +116,32 @@ type TestSetCopyRequest struct {
 	IdentityInfo
 }
 
+// TestSetMoveRequest 移动测试集至新的父测试集下，子测试集和测试用例一并移动
+type TestSetMoveRequest struct {
+	MoveToParentID uint64 `json:"moveToParentID"` // 为 0 时移动至根目录
+
+	TestSetID uint64 `json:"-"`
+
+	IdentityInfo
+}
+type TestSetMoveResponse struct {
+	Header
+	Data *TestSet `json:"data"`
+}
+
+// TestSetMergeRequest 将测试集合并至目标测试集，测试用例和子测试集移至目标测试集下，原测试集被删除
+type TestSetMergeRequest struct {
+	MergeToTestSetID uint64 `json:"mergeToTestSetID"`
+
+	TestSetID uint64 `json:"-"`
+
+	IdentityInfo
+}
+type TestSetMergeResponse struct {
+	Header
+	Data *TestSet `json:"data"`
+}
+
 type TestSetCopyAsyncRequest struct {
 	SourceTestSet *TestSet
 	DestTestSet   *TestSet
@@ -160,6 +188,33 @@ type TestSetRecoverFromRecycleBinResponse struct {
 	Header
 }
 
+// TestRecycleBinPolicy 项目回收站自动清理策略，回收超过保留天数的测试用例及测试集会被彻底删除
+type TestRecycleBinPolicy struct {
+	ProjectID     uint64     `json:"projectID"`
+	RetentionDays int        `json:"retentionDays"` // 保留天数，小于等于 0 表示不自动清理
+	IsDefault     bool       `json:"isDefault"`     // 项目未单独配置，使用平台默认保留天数
+	UpdaterID     string     `json:"updaterID,omitempty"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+}
+
+// TestRecycleBinPolicyUpdateRequest 更新项目回收站自动清理策略
+type TestRecycleBinPolicyUpdateRequest struct {
+	ProjectID     uint64 `json:"-"`
+	RetentionDays int    `json:"retentionDays"`
+
+	IdentityInfo
+}
+
+type TestRecycleBinPolicyGetResponse struct {
+	Header
+	Data *TestRecycleBinPolicy `json:"data"`
+}
+
+type TestRecycleBinPolicyUpdateResponse struct {
+	Header
+	Data *TestRecycleBinPolicy `json:"data"`
+}
+
 //  TestSetCommonResponse 通用返回结构
 type TestSetCommonResponse struct {
 	Header
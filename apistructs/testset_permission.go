@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apistructs
+
+import "time"
+
+// TestSetPermissionRole 用户在测试集上被授予的角色, 子测试集未单独授权时继承上级测试集的授权
+type TestSetPermissionRole string
+
+var (
+	TestSetPermissionRoleEditor TestSetPermissionRole = "Editor" // 编辑者, 可查看及修改测试集
+	TestSetPermissionRoleViewer TestSetPermissionRole = "Viewer" // 观察者, 只读
+	TestSetPermissionRoleNone   TestSetPermissionRole = "None"   // 无权限, 用于覆盖从上级测试集继承的授权
+)
+
+func (role TestSetPermissionRole) Valid() bool {
+	switch role {
+	case TestSetPermissionRoleEditor, TestSetPermissionRoleViewer, TestSetPermissionRoleNone:
+		return true
+	default:
+		return false
+	}
+}
+func (role TestSetPermissionRole) Invalid() bool {
+	return !role.Valid()
+}
+
+// Allow 角色是否允许进行该类操作, 空角色表示未授权, 仅由项目权限控制
+func (role TestSetPermissionRole) Allow(action TestSetPermissionAction) bool {
+	switch role {
+	case "":
+		return true
+	case TestSetPermissionRoleEditor:
+		return action == TestSetPermissionActionRead || action == TestSetPermissionActionEdit
+	case TestSetPermissionRoleViewer:
+		return action == TestSetPermissionActionRead
+	default:
+		return false
+	}
+}
+
+// TestSetPermissionAction 受测试集授权限制的操作
+type TestSetPermissionAction string
+
+var (
+	// TestSetPermissionActionRead 查看测试集
+	TestSetPermissionActionRead TestSetPermissionAction = "Read"
+	// TestSetPermissionActionEdit 创建子测试集, 修改、移动、合并、复制到、回收测试集
+	TestSetPermissionActionEdit TestSetPermissionAction = "Edit"
+)
+
+// TestSetPermission 用户在测试集上被显式授予的角色
+type TestSetPermission struct {
+	ID        uint64                `json:"id"`
+	ProjectID uint64                `json:"projectID"`
+	TestSetID uint64                `json:"testSetID"`
+	UserID    string                `json:"userID"`
+	Role      TestSetPermissionRole `json:"role"`
+	CreatorID string                `json:"creatorID"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// TestSetPermissionGrantRequest 授予用户在测试集上的角色, 已授权时覆盖原角色
+type TestSetPermissionGrantRequest struct {
+	TestSetID uint64                `json:"-"`
+	UserID    string                `json:"userID"`
+	Role      TestSetPermissionRole `json:"role"`
+
+	IdentityInfo
+}
+
+type TestSetPermissionGrantResponse struct {
+	Header
+	Data *TestSetPermission `json:"data"`
+}
+
+type TestSetPermissionRevokeResponse struct {
+	Header
+	Data string `json:"data"`
+}
+
+type TestSetPermissionListResponse struct {
+	Header
+	UserInfoHeader
+	Data []TestSetPermission `json:"data"`
+}
+
+// TestSetEffectivePermissionRequest 查询用户在测试集上的有效权限
+type TestSetEffectivePermissionRequest struct {
+	TestSetID uint64 `json:"-"`
+	// 默认为当前用户
+	UserID string `schema:"userID"`
+
+	IdentityInfo
+}
+
+type TestSetEffectivePermissionResponse struct {
+	Header
+	Data *TestSetEffectivePermission `json:"data"`
+}
+
+// TestSetEffectivePermission 用户在测试集上的有效权限
+type TestSetEffectivePermission struct {
+	TestSetID uint64 `json:"testSetID"`
+	UserID    string `json:"userID"`
+	// Role 生效的授权角色, 为空表示该测试集及其上级均未授权, 仅由项目权限控制
+	Role TestSetPermissionRole `json:"role"`
+	// SourceTestSetID 授权所在的测试集, 与 testSetID 不同时表示继承自上级测试集
+	SourceTestSetID uint64 `json:"sourceTestSetID"`
+	Inherited       bool   `json:"inherited"`
+	// CanRead、CanEdit 综合项目权限与测试集授权后是否可查看、修改
+	CanRead bool `json:"canRead"`
+	CanEdit bool `json:"canEdit"`
+}
@@ -258,6 +258,41 @@ func (req UnifiedFileTreeNodeMoveRequest) BasicValidate() error {
 	return nil
 }
 
+// 应用流水线节点保存
+type UnifiedFileTreeNodeSavePipelineRequest struct {
+	Inode string `json:"inode"`
+
+	PipelineYml string `json:"pipelineYml"`
+	// Force 为 true 时跳过校验强制保存，用于保存草稿
+	Force bool `json:"force"`
+
+	IdentityInfo
+}
+type UnifiedFileTreeNodeSavePipelineResponse struct {
+	Header
+	Data *UnifiedFileTreeNode `json:"data,omitempty"`
+}
+
+func (req UnifiedFileTreeNodeSavePipelineRequest) BasicValidate() error {
+	if err := strutil.Validate(req.Inode, strutil.MinLenValidator(1)); err != nil {
+		return fmt.Errorf("invalid inode: %v", err)
+	}
+	return nil
+}
+
+// UnifiedFileTreeNodeMetaKeyValidationErrors 强制保存时流水线 yml 的校验错误，value 为 []PipelineYmlValidationError
+const UnifiedFileTreeNodeMetaKeyValidationErrors = "validationErrors"
+
+// PipelineYmlValidationError 流水线 yml 校验错误
+type PipelineYmlValidationError struct {
+	// 出错的行列号，从 1 开始，为 0 表示无法定位
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// 出错的位置，例如 stages[0].stage[1]
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
 // 节点拷贝
 type UnifiedFileTreeNodeCopyRequest struct {
 	Inode string `json:"inode"`
@@ -343,6 +378,9 @@ type UnifiedFileTreeNodeFuzzySearchRequest struct {
 	// 是否需要递归，若不递归，则只返回当前层
 	Recursive bool `schema:"recursive" default:"false"`
 
+	// 返回结果数量上限，目前仅应用目录树使用；不传或小于等于 0 时返回全部结果
+	Limit int `schema:"limit,omitempty"`
+
 	// fuzzy search
 	PrefixFuzzy  string `schema:"prefixFuzzy,omitempty"`
 	SuffixFuzzy  string `schema:"suffixFuzzy,omitempty"`
@@ -355,6 +393,19 @@ type UnifiedFileTreeNodeFuzzySearchResponse struct {
 	Data []UnifiedFileTreeNode `json:"data,omitempty"`
 }
 
+// UnifiedFileTreeNodeMetaKeyFuzzyMatches 模糊搜索命中的路径片段，value 为 []UnifiedFileTreeNodeFuzzyMatch
+const UnifiedFileTreeNodeMetaKeyFuzzyMatches = "fuzzyMatches"
+
+// UnifiedFileTreeNodeFuzzyMatch 模糊搜索命中的路径片段，用于前端高亮
+type UnifiedFileTreeNodeFuzzyMatch struct {
+	// 片段在路径中的下标，从 0 开始
+	Index   int    `json:"index"`
+	Segment string `json:"segment"`
+	// 命中部分在片段中的区间 [start, end)
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
 func (req UnifiedFileTreeNodeFuzzySearchRequest) BasicValidate() error {
 	// 需要指定 scope & scopeID
 	if err := strutil.Validate(req.Scope, strutil.MinLenValidator(1)); err != nil {
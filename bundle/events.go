@@ -154,6 +154,11 @@ func (b *Bundle) CreateMboxNotify(templatename string, params map[string]string,
 }
 
 func (b *Bundle) CreateEmailNotify(templatename string, params map[string]string, locale string, orgid uint64, emailaddrs []string) error {
+	return b.CreateEmailNotifyWithCc(templatename, params, locale, orgid, emailaddrs, nil)
+}
+
+// CreateEmailNotifyWithCc 发送邮件通知, ccAddrs 为抄送地址
+func (b *Bundle) CreateEmailNotifyWithCc(templatename string, params map[string]string, locale string, orgid uint64, emailaddrs, ccAddrs []string) error {
 	host, err := b.urls.EventBox()
 	if err != nil {
 		return err
@@ -166,6 +171,9 @@ func (b *Bundle) CreateEmailNotify(templatename string, params map[string]string
 		"params":   params,
 		"orgID":    int64(orgid),
 	}
+	if len(ccAddrs) > 0 {
+		request["cc"] = ccAddrs
+	}
 
 	eventBoxRequest := &apistructs.EventBoxRequest{
 		Sender: "bundle",
@@ -235,6 +235,30 @@ func (b *Bundle) ResetClientCredentials(clientID string) (dto *apistructs.Client
 	return
 }
 
+// 将调用方密钥更新为指定的值
+func (b *Bundle) UpdateClientCredentials(clientID, clientSecret string) (dto *apistructs.ClientInfoDto, err error) {
+	host, err := b.urls.Hepa()
+	if err != nil {
+		return
+	}
+	var fetchResp apistructs.ClientInfoResponse
+	resp, err := b.hc.Patch(host).
+		Path(fmt.Sprintf("/api/gateway/openapi/clients/%s/credentials", clientID)).
+		Header(httputil.InternalHeader, "bundle").
+		Param("clientSecret", clientSecret).
+		Do().JSON(&fetchResp)
+	if err != nil {
+		err = apierrors.ErrInvoke.InternalError(err)
+		return
+	}
+	if !resp.IsOK() || !fetchResp.Success {
+		err = toAPIError(resp.StatusCode(), fetchResp.Error)
+		return
+	}
+	dto = &fetchResp.Data
+	return
+}
+
 // 授权调用方流量入口权限
 func (b *Bundle) GrantEndpointToClient(clientID, endpointID string) (err error) {
 	host, err := b.urls.Hepa()
@@ -134,6 +134,48 @@ func (b *Bundle) KMSRotateKeyVersion(req apistructs.KMSRotateKeyVersionRequest)
 	return rotateResp.Data, nil
 }
 
+func (b *Bundle) KMSRotateAllKeys(req apistructs.KMSRotateAllKeysRequest) (*kmstypes.RotateAllKeysResponse, error) {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var rotateResp apistructs.KMSRotateAllKeysResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/rotate-all").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&rotateResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !rotateResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), rotateResp.Error)
+	}
+	return rotateResp.Data, nil
+}
+
+func (b *Bundle) KMSDeleteKey(req apistructs.KMSDeleteKeyRequest) error {
+	host, err := b.urls.KMS()
+	if err != nil {
+		return err
+	}
+	hc := b.hc
+
+	var deleteResp apistructs.KMSDeleteKeyResponse
+	httpResp, err := hc.Post(host).Path("/api/kms/delete-key").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&deleteResp)
+	if err != nil {
+		return apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !deleteResp.Success {
+		return toAPIError(httpResp.StatusCode(), deleteResp.Error)
+	}
+	return nil
+}
+
 func (b *Bundle) KMSDescribeKey(req apistructs.KMSDescribeKeyRequest) (*kmstypes.DescribeKeyResponse, error) {
 	host, err := b.urls.KMS()
 	if err != nil {
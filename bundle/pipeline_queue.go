@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle/apierrors"
+	"github.com/erda-project/erda/pkg/http/httputil"
+)
+
+// CreatePipelineQueue create pipeline queue
+func (b *Bundle) CreatePipelineQueue(req apistructs.PipelineQueueCreateRequest) (*apistructs.PipelineQueue, error) {
+	host, err := b.urls.Pipeline()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var createResp apistructs.PipelineQueueCreateResponse
+	httpResp, err := hc.Post(host).Path("/api/pipeline-queues").
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&createResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !createResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), createResp.Error)
+	}
+	return createResp.Data, nil
+}
+
+// GetPipelineQueue get pipeline queue with usage
+func (b *Bundle) GetPipelineQueue(queueID uint64) (*apistructs.PipelineQueue, error) {
+	host, err := b.urls.Pipeline()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var getResp apistructs.PipelineQueueGetResponse
+	httpResp, err := hc.Get(host).Path(fmt.Sprintf("/api/pipeline-queues/%d", queueID)).
+		Header(httputil.InternalHeader, "bundle").
+		Do().JSON(&getResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !getResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), getResp.Error)
+	}
+	return getResp.Data, nil
+}
+
+// PagingPipelineQueues paging pipeline queues
+func (b *Bundle) PagingPipelineQueues(req apistructs.PipelineQueuePagingRequest) (*apistructs.PipelineQueuePagingData, error) {
+	host, err := b.urls.Pipeline()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+	sources := make([]string, 0, len(req.PipelineSources))
+	for _, v := range req.PipelineSources {
+		sources = append(sources, v.String())
+	}
+
+	var pageResp apistructs.PipelineQueuePagingResponse
+	httpResp, err := hc.Get(host).Path("/api/pipeline-queues").
+		Header(httputil.InternalHeader, "bundle").
+		Param("name", req.Name).
+		Param("clusterName", req.ClusterName).
+		Params(map[string][]string{"pipelineSource": sources}).
+		Params(map[string][]string{"mustMatchLabel": req.MustMatchLabels}).
+		Param("pageNo", strconv.Itoa(req.PageNo)).
+		Param("pageSize", strconv.Itoa(req.PageSize)).
+		Do().JSON(&pageResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !pageResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), pageResp.Error)
+	}
+	return pageResp.Data, nil
+}
+
+// UpdatePipelineQueue update pipeline queue
+func (b *Bundle) UpdatePipelineQueue(req apistructs.PipelineQueueUpdateRequest) (*apistructs.PipelineQueue, error) {
+	host, err := b.urls.Pipeline()
+	if err != nil {
+		return nil, err
+	}
+	hc := b.hc
+
+	var updateResp apistructs.PipelineQueueUpdateResponse
+	httpResp, err := hc.Put(host).Path(fmt.Sprintf("/api/pipeline-queues/%d", req.ID)).
+		Header(httputil.InternalHeader, "bundle").
+		JSONBody(&req).
+		Do().JSON(&updateResp)
+	if err != nil {
+		return nil, apierrors.ErrInvoke.InternalError(err)
+	}
+	if !httpResp.IsOK() || !updateResp.Success {
+		return nil, toAPIError(httpResp.StatusCode(), updateResp.Error)
+	}
+	return updateResp.Data, nil
+}
@@ -36,6 +36,8 @@ type config struct {
 	}
 	Output         kafka.ProducerConfig `file:"output"`
 	TaSamplingRate float64              `file:"ta_sampling_rate" default:"100"`
+	// max time to wait for buffered messages to be flushed on shutdown
+	CloseTimeout time.Duration `file:"close_timeout" default:"10s"`
 
 	SignAuth signAuthConfig `file:"sign_auth"`
 }
@@ -59,10 +61,12 @@ type provider struct {
 	Kafka            kafka.Interface
 	AccessKeyService akpb.AccessKeyServiceServer `autowired:"erda.core.services.authentication.credentials.accesskey.AccessKeyService"`
 
-	auth *Authenticator
+	auth    *Authenticator
+	closeCh chan struct{}
 }
 
 func (p *provider) Init(ctx servicehub.Context) error {
+	p.closeCh = make(chan struct{})
 	if err := p.initAuthenticator(context.TODO()); err != nil {
 		return err
 	}
@@ -98,6 +102,8 @@ func (p *provider) initAuthenticator(ctx context.Context) error {
 		for {
 			select {
 			case <-tick.C:
+			case <-p.closeCh:
+				return
 			}
 			if err := p.auth.syncAccessKey(ctx); err != nil {
 				p.Logger.Errorf("auth.syncAccessKey failed. err: %s", err)
@@ -107,6 +113,26 @@ func (p *provider) initAuthenticator(ctx context.Context) error {
 	return nil
 }
 
+// Close flushes the messages buffered in the writer, waiting at most CloseTimeout.
+func (p *provider) Close() error {
+	if p.closeCh != nil {
+		close(p.closeCh)
+	}
+	if p.writer == nil {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- p.writer.Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(p.Cfg.CloseTimeout):
+		return fmt.Errorf("timeout to flush output after %s", p.Cfg.CloseTimeout)
+	}
+}
+
 func init() {
 	servicehub.RegisterProvider("monitor-collector", &define{})
 }
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bufferedWriter struct {
+	buf     []interface{}
+	flushed []interface{}
+	delay   time.Duration
+}
+
+func (w *bufferedWriter) Write(data interface{}) error {
+	w.buf = append(w.buf, data)
+	return nil
+}
+
+func (w *bufferedWriter) WriteN(data ...interface{}) (int, error) {
+	w.buf = append(w.buf, data...)
+	return len(data), nil
+}
+
+func (w *bufferedWriter) Close() error {
+	time.Sleep(w.delay)
+	w.flushed = append(w.flushed, w.buf...)
+	w.buf = nil
+	return nil
+}
+
+func TestProviderClose(t *testing.T) {
+	w := &bufferedWriter{}
+	p := &provider{
+		Cfg:     &config{CloseTimeout: time.Second},
+		writer:  w,
+		closeCh: make(chan struct{}),
+	}
+	assert.Nil(t, p.send("metrics", []byte("m1")))
+	assert.Nil(t, p.send("trace", []byte("t1")))
+	assert.Len(t, w.flushed, 0)
+
+	assert.Nil(t, p.Close())
+	assert.Len(t, w.buf, 0)
+	assert.Len(t, w.flushed, 2)
+}
+
+func TestProviderCloseTimeout(t *testing.T) {
+	p := &provider{
+		Cfg:     &config{CloseTimeout: 10 * time.Millisecond},
+		writer:  &bufferedWriter{delay: time.Second},
+		closeCh: make(chan struct{}),
+	}
+	assert.NotNil(t, p.Close())
+}
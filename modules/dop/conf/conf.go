@@ -16,6 +16,7 @@ package conf
 
 import (
 	"strings"
+	"time"
 
 	"github.com/erda-project/erda/pkg/envconf"
 	"github.com/erda-project/erda/pkg/http/httpclientutil"
@@ -69,7 +70,33 @@ type Conf struct {
 	TestSetSyncCopyMaxNum       int `env:"TEST_SET_SYNC_COPY_MAX_NUM" default:"300"`
 	TestFileRecordPurgeCycleDay int `env:"TEST_FILE_RECORD_PURGE_CYCLE_DAY" default:"7"`
 
+	TestCaseAttachmentMaxSize      int64  `env:"TEST_CASE_ATTACHMENT_MAX_SIZE" default:"10485760"`
+	TestCaseAttachmentMaxTotalSize int64  `env:"TEST_CASE_ATTACHMENT_MAX_TOTAL_SIZE" default:"52428800"`
+	TestCaseAttachmentContentTypes string `env:"TEST_CASE_ATTACHMENT_CONTENT_TYPES" default:"image/*,video/*,text/*,application/json,application/pdf,application/zip,application/gzip,application/x-gzip"`
+
+	TestCaseDuplicateThreshold float64 `env:"TEST_CASE_DUPLICATE_THRESHOLD" default:"0.85"`
+
+	AutotestSceneMaxParallelSteps  int   `env:"AUTOTEST_SCENE_MAX_PARALLEL_STEPS" default:"10"`
+	AutotestSceneMaxLoopIterations int   `env:"AUTOTEST_SCENE_MAX_LOOP_ITERATIONS" default:"100"`
+	AutotestSceneDataFileMaxSize   int64 `env:"AUTOTEST_SCENE_DATA_FILE_MAX_SIZE" default:"1048576"`
+
+	AutotestJSScriptImage string `env:"AUTOTEST_JS_SCRIPT_IMAGE" default:"node:14-alpine"`
+
+	AutotestSceneSetMaxParallel int64 `env:"AUTOTEST_SCENE_SET_MAX_PARALLEL" default:"0"`
+
 	ProjectStatsCacheCron string `env:"PROJECT_STATS_CACHE_CRON" default:"0 0 1 * * ?"`
+
+	APIClientSecretGracePeriodSec uint64 `env:"API_CLIENT_SECRET_GRACE_PERIOD_SEC" default:"86400"`
+
+	AutotestScheduleMisfireThresholdSec int64 `env:"AUTOTEST_SCHEDULE_MISFIRE_THRESHOLD_SEC" default:"600"`
+
+	AutotestGlobalConfigSecretKeys string `env:"AUTOTEST_GLOBAL_CONFIG_SECRET_KEYS"`
+
+	AutotestSceneExecutionRetentionDays int `env:"AUTOTEST_SCENE_EXECUTION_RETENTION_DAYS" default:"30"`
+
+	TestRecycleBinRetentionDays int `env:"TEST_RECYCLE_BIN_RETENTION_DAYS" default:"0"`
+
+	TestCaseAuditLogRetentionDays int `env:"TEST_CASE_AUDIT_LOG_RETENTION_DAYS" default:"180"`
 }
 
 var cfg Conf
@@ -239,3 +266,90 @@ func TestSetSyncCopyMaxNum() int {
 func TestFileRecordPurgeCycleDay() int {
 	return cfg.TestFileRecordPurgeCycleDay
 }
+
+// TestCaseAttachmentMaxSize 单个测试用例附件的大小上限, 单位 byte
+func TestCaseAttachmentMaxSize() int64 {
+	return cfg.TestCaseAttachmentMaxSize
+}
+
+// TestCaseAttachmentMaxTotalSize 单个测试用例 (包含执行结果) 附件的总大小上限, 单位 byte
+func TestCaseAttachmentMaxTotalSize() int64 {
+	return cfg.TestCaseAttachmentMaxTotalSize
+}
+
+// TestCaseAttachmentContentTypes 允许上传的附件类型, 多个以逗号分隔, 支持 image/* 形式的通配
+func TestCaseAttachmentContentTypes() []string {
+	var types []string
+	for _, t := range strings.Split(cfg.TestCaseAttachmentContentTypes, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// TestCaseDuplicateThreshold 重复用例检测默认的相似度阈值
+func TestCaseDuplicateThreshold() float64 {
+	return cfg.TestCaseDuplicateThreshold
+}
+
+// AutotestSceneMaxParallelSteps 场景中同时执行的并行步骤数上限, 小于等于 0 时不限制
+func AutotestSceneMaxParallelSteps() int {
+	return cfg.AutotestSceneMaxParallelSteps
+}
+
+// AutotestSceneMaxLoopIterations 循环步骤的最大迭代次数
+func AutotestSceneMaxLoopIterations() int {
+	return cfg.AutotestSceneMaxLoopIterations
+}
+
+// AutotestSceneDataFileMaxSize 场景数据文件的大小上限, 单位 byte
+func AutotestSceneDataFileMaxSize() int64 {
+	return cfg.AutotestSceneDataFileMaxSize
+}
+
+// AutotestSceneSetMaxParallel 同一集群中同时执行的场景集数上限, 超出的执行排队等待, 小于等于 0 时不限制
+func AutotestSceneSetMaxParallel() int64 {
+	return cfg.AutotestSceneSetMaxParallel
+}
+
+// AutotestJSScriptImage 执行 JS 脚本步骤的镜像, 需要 node 14 及以上版本
+func AutotestJSScriptImage() string {
+	return cfg.AutotestJSScriptImage
+}
+
+// APIClientSecretGracePeriodSec 轮换客户端密钥后旧密钥默认的宽限期
+func APIClientSecretGracePeriodSec() uint64 {
+	return cfg.APIClientSecretGracePeriodSec
+}
+
+// AutotestScheduleMisfireThreshold 定时执行错过触发时间超过该阈值时不再补偿执行
+func AutotestScheduleMisfireThreshold() time.Duration {
+	return time.Duration(cfg.AutotestScheduleMisfireThresholdSec) * time.Second
+}
+
+// AutotestGlobalConfigSecretKeys 需要迁移为敏感配置项的自动化测试全局配置项名称, 多个以逗号分隔
+func AutotestGlobalConfigSecretKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(cfg.AutotestGlobalConfigSecretKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// AutotestSceneExecutionRetentionDays 场景执行记录的保留天数, 小于等于 0 表示不清理
+func AutotestSceneExecutionRetentionDays() int {
+	return cfg.AutotestSceneExecutionRetentionDays
+}
+
+// TestRecycleBinRetentionDays 未单独配置策略的项目，回收站中测试用例及测试集的默认保留天数, 小于等于 0 表示不清理
+func TestRecycleBinRetentionDays() int {
+	return cfg.TestRecycleBinRetentionDays
+}
+
+// TestCaseAuditLogRetentionDays 测试用例审计日志的保留天数, 小于等于 0 表示不清理
+func TestCaseAuditLogRetentionDays() int {
+	return cfg.TestCaseAuditLogRetentionDays
+}
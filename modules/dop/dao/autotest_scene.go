@@ -15,6 +15,8 @@
 package dao
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -35,6 +37,41 @@ type AutoTestScene struct {
 	UpdaterID   string                 `gorm:"updater_id"`
 	Status      apistructs.SceneStatus `gorm:"status"`
 	RefSetID    uint64                 `gorm:"ref_set_id"` // 引用场景集ID
+	Tags        autoTestSceneTags      `gorm:"tags"`       // 场景标签
+	// 实例化自的模板及模板版本
+	TemplateID      uint64 `gorm:"template_id"`
+	TemplateVersion uint64 `gorm:"template_version"`
+	StepTimeoutMs   int64  `gorm:"step_timeout_ms"` // 步骤的默认超时时间
+}
+
+// autoTestSceneTags 场景标签, 以 json 数组存储
+type autoTestSceneTags []string
+
+func (tags autoTestSceneTags) Value() (driver.Value, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	if b, err := json.Marshal(tags); err != nil {
+		return nil, fmt.Errorf("failed to marshal scene tags, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (tags *autoTestSceneTags) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid scan source for scene tags")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, tags); err != nil {
+		return fmt.Errorf("failed to unmarshal scene tags, err: %v", err)
+	}
+	return nil
 }
 
 func (AutoTestScene) TableName() string {
@@ -57,7 +94,20 @@ func (s *AutoTestScene) Convert() apistructs.AutoTestScene {
 		UpdateAt:    &s.UpdatedAt,
 		Status:      s.Status,
 		RefSetID:    s.RefSetID,
+		Tags:        s.Tags.List(),
+
+		TemplateID:      s.TemplateID,
+		TemplateVersion: s.TemplateVersion,
+		StepTimeoutMs:   s.StepTimeoutMs,
+	}
+}
+
+// List 返回标签列表, 无标签时返回空列表而不是 nil
+func (tags autoTestSceneTags) List() []string {
+	if tags == nil {
+		return []string{}
 	}
+	return []string(tags)
 }
 
 func (db *DBClient) CreateAutotestScene(node *AutoTestScene) error {
@@ -77,6 +127,15 @@ func (db *DBClient) GetAutotestScene(id uint64) (*AutoTestScene, error) {
 	return &scene, nil
 }
 
+// LockAutotestScene 查询场景并加行锁, 需要在事务中调用, 事务结束前其他修改该场景的事务等待
+func (db *DBClient) LockAutotestScene(id uint64) (*AutoTestScene, error) {
+	var scene AutoTestScene
+	if err := db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&scene).Error; err != nil {
+		return nil, err
+	}
+	return &scene, nil
+}
+
 func (db *DBClient) GetAutotestSceneByPreID(preID uint64) (*AutoTestScene, error) {
 	var scene AutoTestScene
 	err := db.Where("pre_id = ?", preID).Find(&scene).Error
@@ -135,6 +194,15 @@ func (db *DBClient) ListAutotestScenes(setIDs []uint64) ([]AutoTestScene, error)
 	return scenes, nil
 }
 
+// ListAutotestScenesByIDs 根据 id 批量查询场景
+func (db *DBClient) ListAutotestScenesByIDs(ids []uint64) ([]AutoTestScene, error) {
+	var scenes []AutoTestScene
+	if err := db.Where("id in (?)", ids).Find(&scenes).Error; err != nil {
+		return nil, err
+	}
+	return scenes, nil
+}
+
 func (db *DBClient) UpdateAutotestSceneUpdater(sceneID uint64, userID string) error {
 	return db.Table("dice_autotest_scene").Where("id = ?", sceneID).Update("updater_id", userID).Error
 }
@@ -182,10 +250,26 @@ func (db *DBClient) DeleteAutoTestScene(id uint64) (err error) {
 		if err := tx.Where(AutoTestSceneStep{}).Where("scene_id = ?", scene.ID).Delete(AutoTestSceneStep{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("scene_id = ?", scene.ID).Delete(AutoTestSceneVersion{}).Error; err != nil {
+			return err
+		}
 		return nil
 	})
 }
 
+// ClearAutoTestSceneContents 删除场景下的全部入参、出参和步骤，保留场景本身
+func (db *DBClient) ClearAutoTestSceneContents(sceneID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scene_id = ?", sceneID).Delete(AutoTestSceneInput{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("scene_id = ?", sceneID).Delete(AutoTestSceneOutput{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("scene_id = ?", sceneID).Delete(AutoTestSceneStep{}).Error
+	})
+}
+
 // like linklist change to node index
 func (db *DBClient) MoveAutoTestScene(id, newPreID, newSetID uint64) (err error) {
 	return db.Transaction(func(tx *gorm.DB) error {
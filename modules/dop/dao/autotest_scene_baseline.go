@@ -0,0 +1,177 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+type autoTestBaselineIgnorePaths []string
+type autoTestBaselineDiff []apistructs.AutoTestBaselineFieldDiff
+
+// AutoTestSceneBaseline 场景的响应基线
+type AutoTestSceneBaseline struct {
+	dbengine.BaseModel
+	SceneID     uint64                      `gorm:"scene_id"`
+	ExecutionID uint64                      `gorm:"execution_id"`
+	IgnorePaths autoTestBaselineIgnorePaths `gorm:"ignore_paths"`
+	CreatorID   string                      `gorm:"creator_id"`
+	UpdaterID   string                      `gorm:"updater_id"`
+}
+
+func (AutoTestSceneBaseline) TableName() string {
+	return "dice_autotest_scene_baseline"
+}
+
+func (b AutoTestSceneBaseline) Convert() apistructs.AutoTestSceneBaseline {
+	ignorePaths := []string(b.IgnorePaths)
+	if ignorePaths == nil {
+		ignorePaths = []string{}
+	}
+	return apistructs.AutoTestSceneBaseline{
+		ID:          b.ID,
+		SceneID:     b.SceneID,
+		ExecutionID: b.ExecutionID,
+		IgnorePaths: ignorePaths,
+		CreatorID:   b.CreatorID,
+		UpdaterID:   b.UpdaterID,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+// AutoTestSceneBaselineStep 基线中单个步骤的响应快照
+type AutoTestSceneBaselineStep struct {
+	dbengine.BaseModel
+	BaselineID uint64 `gorm:"baseline_id"`
+	StepID     uint64 `gorm:"step_id"`
+	StepName   string `gorm:"step_name"`
+	Response   string `gorm:"response"`
+}
+
+func (AutoTestSceneBaselineStep) TableName() string {
+	return "dice_autotest_scene_baseline_step"
+}
+
+func (paths autoTestBaselineIgnorePaths) Value() (driver.Value, error) {
+	if len(paths) == 0 {
+		return "", nil
+	}
+	if b, err := json.Marshal(paths); err != nil {
+		return nil, fmt.Errorf("failed to marshal baseline ignore paths, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (paths *autoTestBaselineIgnorePaths) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid scan source for baseline ignore paths")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, paths); err != nil {
+		return fmt.Errorf("failed to unmarshal baseline ignore paths, err: %v", err)
+	}
+	return nil
+}
+func (diff autoTestBaselineDiff) Value() (driver.Value, error) {
+	if len(diff) == 0 {
+		return "", nil
+	}
+	if b, err := json.Marshal(diff); err != nil {
+		return nil, fmt.Errorf("failed to marshal baseline diff, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (diff *autoTestBaselineDiff) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid scan source for baseline diff")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, diff); err != nil {
+		return fmt.Errorf("failed to unmarshal baseline diff, err: %v", err)
+	}
+	return nil
+}
+
+func (db *DBClient) GetAutoTestSceneBaseline(sceneID uint64) (*AutoTestSceneBaseline, error) {
+	var baseline AutoTestSceneBaseline
+	if err := db.Where("scene_id = ?", sceneID).First(&baseline).Error; err != nil {
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// SaveAutoTestSceneBaseline 保存场景的基线, steps 不为 nil 时替换基线中的步骤响应快照
+func (db *DBClient) SaveAutoTestSceneBaseline(baseline *AutoTestSceneBaseline, steps []AutoTestSceneBaselineStep) error {
+	tx := db.Begin()
+	if err := tx.Save(baseline).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if steps != nil {
+		if err := tx.Where("baseline_id = ?", baseline.ID).Delete(AutoTestSceneBaselineStep{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		for i := range steps {
+			steps[i].BaselineID = baseline.ID
+			if err := tx.Create(&steps[i]).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit().Error
+}
+
+func (db *DBClient) ListAutoTestSceneBaselineSteps(baselineID uint64) ([]AutoTestSceneBaselineStep, error) {
+	var steps []AutoTestSceneBaselineStep
+	if err := db.Where("baseline_id = ?", baselineID).Order("id").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// DeleteAutoTestSceneBaseline 删除场景的基线及其步骤响应快照
+func (db *DBClient) DeleteAutoTestSceneBaseline(baselineID uint64) error {
+	tx := db.Begin()
+	if err := tx.Where("baseline_id = ?", baselineID).Delete(AutoTestSceneBaselineStep{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("id = ?", baselineID).Delete(AutoTestSceneBaseline{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// AutoTestSceneDataFile 场景的 CSV 数据文件, 保存原始内容, 执行时解析
+type AutoTestSceneDataFile struct {
+	dbengine.BaseModel
+	SpaceID   uint64          `gorm:"space_id"`
+	SceneID   uint64          `gorm:"scene_id"`
+	Name      string          `gorm:"name"`
+	Columns   dataFileColumns `gorm:"columns"`
+	RowCount  int             `gorm:"row_count"`
+	Size      int64           `gorm:"size"`
+	Content   string          `gorm:"content"`
+	CreatorID string          `gorm:"creator_id"`
+}
+
+// dataFileColumns CSV 表头, 以 json 数组存储
+type dataFileColumns []string
+
+func (columns dataFileColumns) Value() (driver.Value, error) {
+	if b, err := json.Marshal(columns); err != nil {
+		return nil, fmt.Errorf("failed to marshal data file columns, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+
+func (columns *dataFileColumns) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid scan source for data file columns")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, columns); err != nil {
+		return fmt.Errorf("failed to unmarshal data file columns, err: %v", err)
+	}
+	return nil
+}
+
+func (AutoTestSceneDataFile) TableName() string {
+	return "dice_autotest_scene_data_file"
+}
+
+func (f AutoTestSceneDataFile) Convert() apistructs.AutoTestSceneDataFile {
+	return apistructs.AutoTestSceneDataFile{
+		ID:        f.ID,
+		SpaceID:   f.SpaceID,
+		SceneID:   f.SceneID,
+		Name:      f.Name,
+		Columns:   f.Columns,
+		RowCount:  f.RowCount,
+		Size:      f.Size,
+		CreatorID: f.CreatorID,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
+
+// CreateAutoTestSceneDataFile 创建场景数据文件
+func (db *DBClient) CreateAutoTestSceneDataFile(file *AutoTestSceneDataFile) error {
+	return db.Create(file).Error
+}
+
+// DeleteAutoTestSceneDataFile 删除场景数据文件
+func (db *DBClient) DeleteAutoTestSceneDataFile(id uint64) error {
+	return db.Where("id = ?", id).Delete(AutoTestSceneDataFile{}).Error
+}
+
+// GetAutoTestSceneDataFile 获取场景数据文件, 包含文件内容
+func (db *DBClient) GetAutoTestSceneDataFile(id uint64) (*AutoTestSceneDataFile, error) {
+	var file AutoTestSceneDataFile
+	if err := db.Where("id = ?", id).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// CountAutoTestSceneDataFileByName 统计场景下同名数据文件的数量
+func (db *DBClient) CountAutoTestSceneDataFileByName(sceneID uint64, name string) (int, error) {
+	var count int
+	err := db.Model(&AutoTestSceneDataFile{}).Where("scene_id = ? AND name = ?", sceneID, name).Count(&count).Error
+	return count, err
+}
+
+// ListAutoTestSceneDataFiles 查询场景的数据文件, 不包含文件内容
+func (db *DBClient) ListAutoTestSceneDataFiles(sceneID uint64) ([]AutoTestSceneDataFile, error) {
+	var files []AutoTestSceneDataFile
+	err := db.Select("id, space_id, scene_id, name, columns, row_count, size, creator_id, created_at, updated_at").
+		Where("scene_id = ?", sceneID).Order("id").Find(&files).Error
+	return files, err
+}
+
+// ListAutoTestSpaceLoopSteps 查询测试空间下的循环步骤, 用于检查数据文件的引用
+func (db *DBClient) ListAutoTestSpaceLoopSteps(spaceID uint64) ([]AutoTestSceneStep, error) {
+	var steps []AutoTestSceneStep
+	err := db.Where("space_id = ? AND type = ?", spaceID, apistructs.StepTypeLoop).Order("id").Find(&steps).Error
+	return steps, err
+}
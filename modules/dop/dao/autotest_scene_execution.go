@@ -0,0 +1,199 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// AutoTestSceneExecution 场景执行记录
+type AutoTestSceneExecution struct {
+	dbengine.BaseModel
+	SceneID     uint64                    `gorm:"scene_id"`
+	SpaceID     uint64                    `gorm:"space_id"`
+	PipelineID  uint64                    `gorm:"pipeline_id"`
+	Status      apistructs.PipelineStatus `gorm:"status"`
+	CostTimeSec int64                     `gorm:"cost_time_sec"`
+	TimeBegin   *time.Time                `gorm:"time_begin"`
+	TimeEnd     *time.Time                `gorm:"time_end"`
+	CreatorID   string                    `gorm:"creator_id"`
+	// 执行时使用的环境、配置单命名空间及覆盖的变量名
+	Environment     string `gorm:"environment"`
+	ConfigNamespace string `gorm:"config_namespace"`
+	VariableNames   string `gorm:"variable_names"`
+	// 对比的基线及是否与基线不一致
+	BaselineID    uint64 `gorm:"baseline_id"`
+	BaselineDrift bool   `gorm:"baseline_drift"`
+}
+
+func (AutoTestSceneExecution) TableName() string {
+	return "dice_autotest_scene_execution"
+}
+
+func (e AutoTestSceneExecution) Convert() apistructs.AutoTestSceneExecution {
+	return apistructs.AutoTestSceneExecution{
+		ID:          e.ID,
+		SceneID:     e.SceneID,
+		SpaceID:     e.SpaceID,
+		PipelineID:  e.PipelineID,
+		Status:      e.Status,
+		CostTimeSec: e.CostTimeSec,
+		TimeBegin:   e.TimeBegin,
+		TimeEnd:     e.TimeEnd,
+		CreatorID:   e.CreatorID,
+		CreatedAt:   e.CreatedAt,
+
+		Environment:            e.Environment,
+		ConfigManageNamespaces: e.ConfigNamespace,
+		VariableNames:          strutil.Split(e.VariableNames, ",", true),
+		BaselineID:             e.BaselineID,
+		BaselineDrift:          e.BaselineDrift,
+	}
+}
+
+// AutoTestSceneExecutionStep 场景执行中单个步骤的执行结果及快照
+type AutoTestSceneExecutionStep struct {
+	dbengine.BaseModel
+	ExecutionID   uint64                    `gorm:"execution_id"`
+	StepID        uint64                    `gorm:"step_id"`
+	StepName      string                    `gorm:"step_name"`
+	StepType      apistructs.StepAPIType    `gorm:"step_type"`
+	TaskName      string                    `gorm:"task_name"`
+	Status        apistructs.PipelineStatus `gorm:"status"`
+	CostTimeSec   int64                     `gorm:"cost_time_sec"`
+	TimeBegin     *time.Time                `gorm:"time_begin"`
+	TimeEnd       *time.Time                `gorm:"time_end"`
+	Request       string                    `gorm:"request"`
+	Response      string                    `gorm:"response"`
+	AssertSuccess string                    `gorm:"assert_success"`
+	AssertDetail  string                    `gorm:"assert_detail"`
+	Message       string                    `gorm:"message"`
+	TimeoutMs     int64                     `gorm:"timeout_ms"`
+	DurationMs    int64                     `gorm:"duration_ms"`
+	BaselineDiff  autoTestBaselineDiff      `gorm:"baseline_diff"`
+}
+
+func (AutoTestSceneExecutionStep) TableName() string {
+	return "dice_autotest_scene_execution_step"
+}
+
+func (s AutoTestSceneExecutionStep) Convert() apistructs.AutoTestSceneExecutionStep {
+	return apistructs.AutoTestSceneExecutionStep{
+		ID:            s.ID,
+		StepID:        s.StepID,
+		StepName:      s.StepName,
+		StepType:      s.StepType,
+		TaskName:      s.TaskName,
+		Status:        s.Status,
+		CostTimeSec:   s.CostTimeSec,
+		TimeBegin:     s.TimeBegin,
+		TimeEnd:       s.TimeEnd,
+		Request:       s.Request,
+		Response:      s.Response,
+		AssertSuccess: s.AssertSuccess,
+		AssertDetail:  s.AssertDetail,
+		Message:       s.Message,
+		TimeoutMs:     s.TimeoutMs,
+		DurationMs:    s.DurationMs,
+		BaselineDiff:  s.BaselineDiff,
+	}
+}
+
+func (db *DBClient) CreateAutoTestSceneExecution(execution *AutoTestSceneExecution) error {
+	return db.Create(execution).Error
+}
+
+func (db *DBClient) GetAutoTestSceneExecution(id uint64) (*AutoTestSceneExecution, error) {
+	var execution AutoTestSceneExecution
+	if err := db.Where("id = ?", id).First(&execution).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+func (db *DBClient) GetAutoTestSceneExecutionByPipelineID(pipelineID uint64) (*AutoTestSceneExecution, error) {
+	var execution AutoTestSceneExecution
+	if err := db.Where("pipeline_id = ?", pipelineID).First(&execution).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// FinishAutoTestSceneExecution 保存执行结果, 并替换已保存的步骤结果
+func (db *DBClient) FinishAutoTestSceneExecution(execution *AutoTestSceneExecution, steps []AutoTestSceneExecutionStep) error {
+	tx := db.Begin()
+	if err := tx.Save(execution).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("execution_id = ?", execution.ID).Delete(AutoTestSceneExecutionStep{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for i := range steps {
+		steps[i].ExecutionID = execution.ID
+		if err := tx.Create(&steps[i]).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+func (db *DBClient) PagingAutoTestSceneExecutions(req apistructs.AutoTestSceneExecutionListRequest) (int64, []AutoTestSceneExecution, error) {
+	var (
+		executions []AutoTestSceneExecution
+		total      int64
+	)
+	if err := db.Where("scene_id = ?", req.SceneID).Order("id DESC").
+		Offset((req.PageNo - 1) * req.PageSize).Limit(req.PageSize).Find(&executions).Error; err != nil {
+		return 0, nil, err
+	}
+	if err := db.Model(&AutoTestSceneExecution{}).Where("scene_id = ?", req.SceneID).Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	return total, executions, nil
+}
+
+func (db *DBClient) ListAutoTestSceneExecutionSteps(executionID uint64) ([]AutoTestSceneExecutionStep, error) {
+	var steps []AutoTestSceneExecutionStep
+	if err := db.Where("execution_id = ?", executionID).Order("id").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// DeleteAutoTestSceneExecutionsBefore 分批删除 before 之前创建的执行记录及其步骤结果, 返回删除的执行记录数
+func (db *DBClient) DeleteAutoTestSceneExecutionsBefore(before time.Time, batchSize int) (int, error) {
+	var ids []uint64
+	if err := db.Model(&AutoTestSceneExecution{}).Where("created_at < ?", before).
+		Order("id").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := db.Where("execution_id in (?)", ids).Delete(AutoTestSceneExecutionStep{}).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Where("id in (?)", ids).Delete(AutoTestSceneExecution{}).Error; err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
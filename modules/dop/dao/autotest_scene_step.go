@@ -25,14 +25,15 @@ import (
 
 type AutoTestSceneStep struct {
 	dbengine.BaseModel
-	Type      apistructs.StepAPIType `gorm:"type"`               // 类型
-	Value     string                 `gorm:"value"`              // 值
-	Name      string                 `gorm:"name"`               // 名称
-	PreID     uint64                 `gorm:"pre_id"`             // 排序id
-	PreType   apistructs.PreType     `gorm:"pre_type"`           // 串行/并行类型
-	SceneID   uint64                 `gorm:"scene_id"`           // 场景ID
-	SpaceID   uint64                 `gorm:"space_id"`           // 所属测试空间ID
-	APISpecID uint64                 `gorm:"column:api_spec_id"` // api集市id
+	Type      apistructs.StepAPIType `gorm:"type"`                 // 类型
+	Value     string                 `gorm:"value"`                // 值
+	Name      string                 `gorm:"name"`                 // 名称
+	PreID     uint64                 `gorm:"pre_id"`               // 排序id
+	PreType   apistructs.PreType     `gorm:"pre_type"`             // 串行/并行类型
+	SceneID   uint64                 `gorm:"scene_id"`             // 场景ID
+	SpaceID   uint64                 `gorm:"space_id"`             // 所属测试空间ID
+	APISpecID uint64                 `gorm:"column:api_spec_id"`   // api集市id
+	Condition string                 `gorm:"column:run_condition"` // 执行条件, 为空时总是执行
 	CreatorID string                 `gorm:"creator_id"`
 	UpdaterID string                 `gorm:"updater_id"`
 }
@@ -62,6 +63,7 @@ func (v *AutoTestSceneStep) Convert() *apistructs.AutoTestSceneStep {
 		SceneID:   v.SceneID,
 		SpaceID:   v.SpaceID,
 		APISpecID: v.APISpecID,
+		Condition: v.Condition,
 	}
 }
 
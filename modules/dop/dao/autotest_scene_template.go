@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// AutoTestSceneTemplateContent 模板内容, 以 json 保存
+type AutoTestSceneTemplateContent apistructs.AutoTestSceneTemplateContent
+
+// AutoTestSceneTemplate 场景模板, 内容以 json 保存
+type AutoTestSceneTemplate struct {
+	dbengine.BaseModel
+	ProjectID     uint64                       `gorm:"project_id"`
+	Name          string                       `gorm:"name"`
+	Description   string                       `gorm:"description"`
+	SourceSceneID uint64                       `gorm:"source_scene_id"`
+	Version       uint64                       `gorm:"version"`
+	Content       AutoTestSceneTemplateContent `gorm:"content"`
+	CreatorID     string                       `gorm:"creator_id"`
+	UpdaterID     string                       `gorm:"updater_id"`
+}
+
+func (AutoTestSceneTemplate) TableName() string {
+	return "dice_autotest_scene_template"
+}
+
+func (t AutoTestSceneTemplate) Convert() apistructs.AutoTestSceneTemplate {
+	params := make([]apistructs.AutoTestSceneTemplateParam, 0, len(t.Content.Inputs))
+	for _, input := range t.Content.Inputs {
+		params = append(params, apistructs.AutoTestSceneTemplateParam{
+			Name:        input.Name,
+			Description: input.Description,
+			Default:     input.Value,
+		})
+	}
+	stepCount := 0
+	for _, step := range t.Content.Steps {
+		stepCount += 1 + len(step.Children)
+	}
+	return apistructs.AutoTestSceneTemplate{
+		ID:            t.ID,
+		ProjectID:     t.ProjectID,
+		Name:          t.Name,
+		Description:   t.Description,
+		SourceSceneID: t.SourceSceneID,
+		Version:       t.Version,
+		Params:        params,
+		StepCount:     stepCount,
+		CreatorID:     t.CreatorID,
+		UpdaterID:     t.UpdaterID,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+}
+
+func (content AutoTestSceneTemplateContent) Value() (driver.Value, error) {
+	if b, err := json.Marshal(content); err != nil {
+		return nil, fmt.Errorf("failed to marshal scene template content, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (content *AutoTestSceneTemplateContent) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid scan source for scene template content")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, content); err != nil {
+		return fmt.Errorf("failed to unmarshal scene template content, err: %v", err)
+	}
+	return nil
+}
+
+func (db *DBClient) CreateAutoTestSceneTemplate(template *AutoTestSceneTemplate) error {
+	return db.Create(template).Error
+}
+
+func (db *DBClient) UpdateAutoTestSceneTemplate(template *AutoTestSceneTemplate) error {
+	return db.Save(template).Error
+}
+
+// DeleteAutoTestSceneTemplate 删除模板, 已实例化的场景保留内容并解除与模板的关联
+func (db *DBClient) DeleteAutoTestSceneTemplate(id uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&AutoTestScene{}).Where("template_id = ?", id).
+			Updates(map[string]interface{}{"template_id": 0, "template_version": 0}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(AutoTestSceneTemplate{}).Error
+	})
+}
+
+func (db *DBClient) GetAutoTestSceneTemplate(id uint64) (*AutoTestSceneTemplate, error) {
+	var template AutoTestSceneTemplate
+	if err := db.Where("id = ?", id).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetAutoTestSceneTemplateByName 查询项目下的同名模板, 不存在时返回 nil
+func (db *DBClient) GetAutoTestSceneTemplateByName(projectID uint64, name string) (*AutoTestSceneTemplate, error) {
+	var template AutoTestSceneTemplate
+	if err := db.Where("project_id = ? AND name = ?", projectID, name).First(&template).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (db *DBClient) ListAutoTestSceneTemplates(req apistructs.AutoTestSceneTemplateListRequest) ([]AutoTestSceneTemplate, error) {
+	var templates []AutoTestSceneTemplate
+	sql := db.Where("project_id = ?", req.ProjectID)
+	if req.Name != "" {
+		sql = sql.Where("name LIKE ?", "%"+req.Name+"%")
+	}
+	if err := sql.Order("id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pkg/mysql"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// sceneVersionCreateAttempts 并发创建版本时版本号冲突的最大尝试次数
+const sceneVersionCreateAttempts = 3
+
+// AutoTestSceneVersion 场景历史版本, 内容以 json 保存
+type AutoTestSceneVersion struct {
+	dbengine.BaseModel
+	SceneID             uint64                       `gorm:"scene_id"`
+	Version             uint64                       `gorm:"version"`
+	StepTimeoutMs       int64                        `gorm:"step_timeout_ms"`
+	Content             AutoTestSceneTemplateContent `gorm:"content"`
+	OperatorID          string                       `gorm:"operator_id"`
+	RestoredFromVersion uint64                       `gorm:"restored_from_version"`
+}
+
+func (AutoTestSceneVersion) TableName() string {
+	return "dice_autotest_scene_version"
+}
+
+// Convert 转换为不包含内容的版本信息
+func (v AutoTestSceneVersion) Convert() apistructs.AutoTestSceneVersion {
+	stepCount := 0
+	for _, step := range v.Content.Steps {
+		stepCount += 1 + len(step.Children)
+	}
+	return apistructs.AutoTestSceneVersion{
+		ID:                  v.ID,
+		SceneID:             v.SceneID,
+		Version:             v.Version,
+		StepTimeoutMs:       v.StepTimeoutMs,
+		StepCount:           stepCount,
+		OperatorID:          v.OperatorID,
+		RestoredFromVersion: v.RestoredFromVersion,
+		CreatedAt:           v.CreatedAt,
+	}
+}
+
+// CreateAutoTestSceneVersion 创建历史版本，版本号为当前最大版本号加一;
+// 并发创建时由 (scene_id, version) 唯一索引保证版本号不重复, 冲突后重新获取版本号
+func (db *DBClient) CreateAutoTestSceneVersion(v *AutoTestSceneVersion) (err error) {
+	for i := 0; i < sceneVersionCreateAttempts; i++ {
+		var result struct {
+			Version uint64
+		}
+		// 加锁读取最新提交的版本号, 在事务中重试时不会读到事务开始时的快照
+		if err := db.Model(&AutoTestSceneVersion{}).Set("gorm:query_option", "FOR UPDATE").
+			Select("IFNULL(MAX(`version`), 0) AS version").
+			Where("scene_id = ?", v.SceneID).Scan(&result).Error; err != nil {
+			return err
+		}
+		v.Version = result.Version + 1
+		if err = db.Create(v).Error; err == nil || !mysql.IsUniqueConstraintError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// GetLatestAutoTestSceneVersion 获取场景的最新版本，无历史版本时返回 nil
+func (db *DBClient) GetLatestAutoTestSceneVersion(sceneID uint64) (*AutoTestSceneVersion, error) {
+	var v AutoTestSceneVersion
+	if err := db.Where("scene_id = ?", sceneID).Order("version DESC").First(&v).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (db *DBClient) GetAutoTestSceneVersion(sceneID, version uint64) (*AutoTestSceneVersion, error) {
+	var v AutoTestSceneVersion
+	if err := db.Where("scene_id = ? AND version = ?", sceneID, version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListAutoTestSceneVersions 按版本号倒序列出历史版本
+func (db *DBClient) ListAutoTestSceneVersions(sceneID uint64) ([]AutoTestSceneVersion, error) {
+	var versions []AutoTestSceneVersion
+	if err := db.Where("scene_id = ?", sceneID).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// AutoTestSceneWebhook 场景执行结束时回调的 webhook, 签名密钥经 kms 加密后保存
+type AutoTestSceneWebhook struct {
+	dbengine.BaseModel
+	SpaceID          uint64 `gorm:"space_id"`
+	SceneID          uint64 `gorm:"scene_id"` // 为 0 时对测试空间下所有场景生效
+	URL              string `gorm:"url"`
+	Enabled          bool   `gorm:"enabled"`
+	KMSKeyID         string `gorm:"kms_key_id"`
+	SecretCiphertext string `gorm:"secret_ciphertext"`
+	CreatorID        string `gorm:"creator_id"`
+	UpdaterID        string `gorm:"updater_id"`
+}
+
+func (AutoTestSceneWebhook) TableName() string {
+	return "dice_autotest_scene_webhook"
+}
+
+func (w AutoTestSceneWebhook) Convert() apistructs.AutoTestSceneWebhook {
+	return apistructs.AutoTestSceneWebhook{
+		ID:        w.ID,
+		SpaceID:   w.SpaceID,
+		SceneID:   w.SceneID,
+		URL:       w.URL,
+		Enabled:   w.Enabled,
+		CreatorID: w.CreatorID,
+		UpdaterID: w.UpdaterID,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// AutoTestSceneWebhookDelivery webhook 的投递记录, 失败后按退避时间重试
+type AutoTestSceneWebhookDelivery struct {
+	dbengine.BaseModel
+	WebhookID    uint64                                        `gorm:"webhook_id"`
+	ExecutionID  uint64                                        `gorm:"execution_id"`
+	Payload      string                                        `gorm:"payload"`
+	Status       apistructs.AutoTestSceneWebhookDeliveryStatus `gorm:"status"`
+	Attempts     int                                           `gorm:"attempts"`
+	ResponseCode int                                           `gorm:"response_code"`
+	Message      string                                        `gorm:"message"`
+	NextRetryAt  *time.Time                                    `gorm:"next_retry_at"`
+	DeliveredAt  *time.Time                                    `gorm:"delivered_at"`
+}
+
+func (AutoTestSceneWebhookDelivery) TableName() string {
+	return "dice_autotest_scene_webhook_delivery"
+}
+
+func (d AutoTestSceneWebhookDelivery) Convert() apistructs.AutoTestSceneWebhookDelivery {
+	return apistructs.AutoTestSceneWebhookDelivery{
+		ID:           d.ID,
+		WebhookID:    d.WebhookID,
+		ExecutionID:  d.ExecutionID,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		Message:      d.Message,
+		NextRetryAt:  d.NextRetryAt,
+		DeliveredAt:  d.DeliveredAt,
+		CreatedAt:    d.CreatedAt,
+	}
+}
+
+func (db *DBClient) CreateAutoTestSceneWebhook(webhook *AutoTestSceneWebhook) error {
+	return db.Create(webhook).Error
+}
+
+func (db *DBClient) UpdateAutoTestSceneWebhook(webhook *AutoTestSceneWebhook) error {
+	return db.Save(webhook).Error
+}
+
+func (db *DBClient) DeleteAutoTestSceneWebhook(id uint64) error {
+	if err := db.Where("webhook_id = ?", id).Delete(AutoTestSceneWebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id = ?", id).Delete(AutoTestSceneWebhook{}).Error
+}
+
+func (db *DBClient) GetAutoTestSceneWebhook(id uint64) (*AutoTestSceneWebhook, error) {
+	var webhook AutoTestSceneWebhook
+	if err := db.Where("id = ?", id).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (db *DBClient) ListAutoTestSceneWebhooks(req apistructs.AutoTestSceneWebhookListRequest) ([]AutoTestSceneWebhook, error) {
+	var webhooks []AutoTestSceneWebhook
+	sql := db.DB
+	if req.SpaceID > 0 {
+		sql = sql.Where("space_id = ?", req.SpaceID)
+	}
+	if req.SceneID > 0 {
+		sql = sql.Where("scene_id = ?", req.SceneID)
+	}
+	if err := sql.Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// ListEnabledAutoTestSceneWebhooks 查询对场景生效的 webhook, 包括场景自身的和所属测试空间的
+func (db *DBClient) ListEnabledAutoTestSceneWebhooks(spaceID, sceneID uint64) ([]AutoTestSceneWebhook, error) {
+	var webhooks []AutoTestSceneWebhook
+	if err := db.Where("enabled = ? AND space_id = ? AND scene_id in (?)", true, spaceID, []uint64{0, sceneID}).
+		Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (db *DBClient) CreateAutoTestSceneWebhookDelivery(delivery *AutoTestSceneWebhookDelivery) error {
+	return db.Create(delivery).Error
+}
+
+func (db *DBClient) UpdateAutoTestSceneWebhookDelivery(delivery *AutoTestSceneWebhookDelivery) error {
+	return db.Save(delivery).Error
+}
+
+// ListDueAutoTestSceneWebhookDeliveries 查询到达重试时间的待投递记录
+func (db *DBClient) ListDueAutoTestSceneWebhookDeliveries(now time.Time, limit int) ([]AutoTestSceneWebhookDelivery, error) {
+	var deliveries []AutoTestSceneWebhookDelivery
+	if err := db.Where("status = ? AND next_retry_at <= ?", apistructs.AutoTestSceneWebhookDeliveryPending, now).
+		Order("next_retry_at").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimAutoTestSceneWebhookDelivery 以 next_retry_at 作为乐观锁将投递记录的重试时间推迟到 leaseUntil, 返回是否抢占成功;
+// 多实例同时投递时只有一个实例能抢占成功, 抢占后实例异常退出时到达 leaseUntil 会被重新投递
+func (db *DBClient) ClaimAutoTestSceneWebhookDelivery(id uint64, nextRetryAt time.Time, leaseUntil time.Time) (bool, error) {
+	res := db.Model(&AutoTestSceneWebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_retry_at = ?", id, apistructs.AutoTestSceneWebhookDeliveryPending, nextRetryAt).
+		Update("next_retry_at", leaseUntil)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (db *DBClient) PagingAutoTestSceneWebhookDeliveries(req apistructs.AutoTestSceneWebhookDeliveryListRequest) (int64, []AutoTestSceneWebhookDelivery, error) {
+	var (
+		deliveries []AutoTestSceneWebhookDelivery
+		total      int64
+	)
+	sql := db.Where("webhook_id = ?", req.WebhookID)
+	if err := sql.Order("id DESC").Offset((req.PageNo - 1) * req.PageSize).Limit(req.PageSize).Find(&deliveries).Error; err != nil {
+		return 0, nil, err
+	}
+	if err := db.Model(&AutoTestSceneWebhookDelivery{}).Where("webhook_id = ?", req.WebhookID).Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	return total, deliveries, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// AutoTestSchedule 场景、场景集或测试计划的定时执行配置
+type AutoTestSchedule struct {
+	dbengine.BaseModel
+	TargetType      apistructs.AutoTestScheduleTargetType `gorm:"target_type"`
+	TargetID        uint64                                `gorm:"target_id"`
+	SpaceID         uint64                                `gorm:"space_id"`
+	CronExpr        string                                `gorm:"cron_expr"`
+	Enabled         bool                                  `gorm:"enabled"`
+	ConfigNamespace string                                `gorm:"config_namespace"`
+	ClusterName     string                                `gorm:"cluster_name"`
+	SceneTags       autoTestSceneTags                     `gorm:"scene_tags"` // 执行场景集时筛选场景的标签
+	NextFireAt      *time.Time                            `gorm:"next_fire_at"`
+	LastFireAt      *time.Time                            `gorm:"last_fire_at"`
+	CreatorID       string                                `gorm:"creator_id"`
+	UpdaterID       string                                `gorm:"updater_id"`
+}
+
+func (AutoTestSchedule) TableName() string {
+	return "dice_autotest_schedule"
+}
+
+func (s AutoTestSchedule) Convert() apistructs.AutoTestSchedule {
+	return apistructs.AutoTestSchedule{
+		ID:                     s.ID,
+		TargetType:             s.TargetType,
+		TargetID:               s.TargetID,
+		SpaceID:                s.SpaceID,
+		CronExpr:               s.CronExpr,
+		Enabled:                s.Enabled,
+		ConfigManageNamespaces: s.ConfigNamespace,
+		ClusterName:            s.ClusterName,
+		SceneTags:              s.SceneTags.List(),
+		NextFireAt:             s.NextFireAt,
+		LastFireAt:             s.LastFireAt,
+		CreatorID:              s.CreatorID,
+		UpdaterID:              s.UpdaterID,
+		CreatedAt:              s.CreatedAt,
+		UpdatedAt:              s.UpdatedAt,
+	}
+}
+
+// AutoTestScheduleRecord 定时执行的触发记录
+type AutoTestScheduleRecord struct {
+	dbengine.BaseModel
+	ScheduleID uint64                                  `gorm:"schedule_id"`
+	PipelineID uint64                                  `gorm:"pipeline_id"`
+	Status     apistructs.AutoTestScheduleRecordStatus `gorm:"status"`
+	Message    string                                  `gorm:"message"`
+	FiredAt    time.Time                               `gorm:"fired_at"`
+}
+
+func (AutoTestScheduleRecord) TableName() string {
+	return "dice_autotest_schedule_record"
+}
+
+func (r AutoTestScheduleRecord) Convert() apistructs.AutoTestScheduleRecord {
+	return apistructs.AutoTestScheduleRecord{
+		ID:         r.ID,
+		ScheduleID: r.ScheduleID,
+		PipelineID: r.PipelineID,
+		Status:     r.Status,
+		Message:    r.Message,
+		FiredAt:    r.FiredAt,
+	}
+}
+
+func (db *DBClient) CreateAutoTestSchedule(schedule *AutoTestSchedule) error {
+	return db.Create(schedule).Error
+}
+
+func (db *DBClient) UpdateAutoTestSchedule(schedule *AutoTestSchedule) error {
+	return db.Save(schedule).Error
+}
+
+func (db *DBClient) DeleteAutoTestSchedule(id uint64) error {
+	if err := db.Where("schedule_id = ?", id).Delete(AutoTestScheduleRecord{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id = ?", id).Delete(AutoTestSchedule{}).Error
+}
+
+func (db *DBClient) GetAutoTestSchedule(id uint64) (*AutoTestSchedule, error) {
+	var schedule AutoTestSchedule
+	if err := db.Where("id = ?", id).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (db *DBClient) ListAutoTestSchedules(req apistructs.AutoTestScheduleListRequest) ([]AutoTestSchedule, error) {
+	var schedules []AutoTestSchedule
+	sql := db.DB
+	if req.TargetType != "" {
+		sql = sql.Where("target_type = ?", req.TargetType)
+	}
+	if req.TargetID > 0 {
+		sql = sql.Where("target_id = ?", req.TargetID)
+	}
+	if req.SpaceID > 0 {
+		sql = sql.Where("space_id = ?", req.SpaceID)
+	}
+	if err := sql.Order("id").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ListDueAutoTestSchedules 查询已启用且到达触发时间的定时执行
+func (db *DBClient) ListDueAutoTestSchedules(now time.Time) ([]AutoTestSchedule, error) {
+	var schedules []AutoTestSchedule
+	if err := db.Where("enabled = ? AND next_fire_at <= ?", true, now).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ClaimAutoTestSchedule 以 next_fire_at 作为乐观锁将定时执行推进到下次触发时间, 返回是否抢占成功;
+// 多实例部署时同一次触发只会被一个实例抢占
+func (db *DBClient) ClaimAutoTestSchedule(id uint64, fireAt time.Time, firedAt time.Time, nextFireAt time.Time) (bool, error) {
+	res := db.Model(&AutoTestSchedule{}).
+		Where("id = ? AND enabled = ? AND next_fire_at = ?", id, true, fireAt).
+		Updates(map[string]interface{}{
+			"next_fire_at": nextFireAt,
+			"last_fire_at": firedAt,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// DeleteAutoTestSchedulesByTargets 删除场景或场景集时删除其定时执行
+func (db *DBClient) DeleteAutoTestSchedulesByTargets(targetType apistructs.AutoTestScheduleTargetType, targetIDs []uint64) error {
+	if len(targetIDs) == 0 {
+		return nil
+	}
+	var ids []uint64
+	if err := db.Model(&AutoTestSchedule{}).Where("target_type = ? AND target_id in (?)", targetType, targetIDs).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.Where("schedule_id in (?)", ids).Delete(AutoTestScheduleRecord{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id in (?)", ids).Delete(AutoTestSchedule{}).Error
+}
+
+func (db *DBClient) CreateAutoTestScheduleRecord(record *AutoTestScheduleRecord) error {
+	return db.Create(record).Error
+}
+
+func (db *DBClient) PagingAutoTestScheduleRecords(req apistructs.AutoTestScheduleRecordListRequest) (int64, []AutoTestScheduleRecord, error) {
+	var (
+		records []AutoTestScheduleRecord
+		total   int64
+	)
+	sql := db.Where("schedule_id = ?", req.ScheduleID)
+	if err := sql.Order("fired_at DESC").Offset((req.PageNo - 1) * req.PageSize).Limit(req.PageSize).Find(&records).Error; err != nil {
+		return 0, nil, err
+	}
+	if err := db.Model(&AutoTestScheduleRecord{}).Where("schedule_id = ?", req.ScheduleID).Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+	return total, records, nil
+}
@@ -30,6 +30,23 @@ func (client *DBClient) SoftDeleteEnvConfig(config *model.ConfigItem) error {
 	return client.Model(config).Update("is_deleted", IsDeleteValue).Error
 }
 
+// ImportEnvConfigs 在同一事务中保存和软删除配置, 任一失败时整体回滚
+func (client *DBClient) ImportEnvConfigs(saves, deletes []*model.ConfigItem) error {
+	return client.Transaction(func(tx *gorm.DB) error {
+		for _, config := range saves {
+			if err := tx.Save(config).Error; err != nil {
+				return err
+			}
+		}
+		for _, config := range deletes {
+			if err := tx.Model(config).Update("is_deleted", IsDeleteValue).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetEnvConfigByID 根据 ID 获取 配置信息
 func (client *DBClient) GetEnvConfigByID(configID int64) (*model.ConfigItem, error) {
 	configItem := &model.ConfigItem{}
@@ -70,3 +87,16 @@ func (client *DBClient) GetEnvConfigByKey(namespaceID int64, key string) (*model
 
 	return configItem, nil
 }
+
+// GetEnvConfigKMSKeyID 获取 namespace 下加密配置使用的 kms key, 不存在时返回空
+func (client *DBClient) GetEnvConfigKMSKeyID(namespaceID int64) (string, error) {
+	var configItems []model.ConfigItem
+	if err := client.Where("namespace_id = ?", namespaceID).Where("kms_key_id != ?", "").
+		Limit(1).Find(&configItems).Error; err != nil {
+		return "", err
+	}
+	if len(configItems) == 0 {
+		return "", nil
+	}
+	return configItems[0].KMSKeyID, nil
+}
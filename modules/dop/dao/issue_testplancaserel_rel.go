@@ -64,6 +64,18 @@ func (client *DBClient) ListIssueTestCaseRelations(req apistructs.IssueTestCaseR
 	return results, nil
 }
 
+// ListIssueTestCaseRelationsByIssueIDs 根据 issue ids 查询关联关系
+func (client *DBClient) ListIssueTestCaseRelationsByIssueIDs(issueIDs []uint64) ([]IssueTestCaseRelation, error) {
+	var results []IssueTestCaseRelation
+	if len(issueIDs) == 0 {
+		return results, nil
+	}
+	if err := client.Where("`issue_id` IN (?)", issueIDs).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 // BatchCreateIssueTestCaseRelations 批量创建关联关系
 func (client *DBClient) BatchCreateIssueTestCaseRelations(rels []IssueTestCaseRelation) error {
 	return client.BulkInsert(rels)
@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// PipelineParamPreset 流水线运行参数预设
+type PipelineParamPreset struct {
+	dbengine.BaseModel
+	ProjectID       uint64
+	AppID           uint64
+	Branch          string
+	PipelineYmlName string
+	Name            string
+	Params          string // json 格式的 apistructs.PipelineRunParams
+	IsDefault       bool
+	CreatorID       string
+	UpdaterID       string
+}
+
+// TableName 表名
+func (PipelineParamPreset) TableName() string {
+	return "dice_pipeline_param_presets"
+}
+
+// CreatePipelineParamPreset 创建预设，若为默认预设则取消同一流水线下其他预设的默认标记
+func (client *DBClient) CreatePipelineParamPreset(preset *PipelineParamPreset) error {
+	return client.Transaction(func(tx *gorm.DB) error {
+		if preset.IsDefault {
+			if err := clearDefaultPipelineParamPreset(tx, preset); err != nil {
+				return err
+			}
+		}
+		return tx.Create(preset).Error
+	})
+}
+
+// UpdatePipelineParamPreset 更新预设，若为默认预设则取消同一流水线下其他预设的默认标记
+func (client *DBClient) UpdatePipelineParamPreset(preset *PipelineParamPreset) error {
+	return client.Transaction(func(tx *gorm.DB) error {
+		if preset.IsDefault {
+			if err := clearDefaultPipelineParamPreset(tx, preset); err != nil {
+				return err
+			}
+		}
+		return tx.Save(preset).Error
+	})
+}
+
+func clearDefaultPipelineParamPreset(tx *gorm.DB, preset *PipelineParamPreset) error {
+	return tx.Model(&PipelineParamPreset{}).
+		Where("`app_id` = ? AND `branch` = ? AND `pipeline_yml_name` = ? AND `id` != ?",
+			preset.AppID, preset.Branch, preset.PipelineYmlName, preset.ID).
+		Update("is_default", false).Error
+}
+
+// GetPipelineParamPreset 根据 id 获取预设
+func (client *DBClient) GetPipelineParamPreset(id uint64) (*PipelineParamPreset, error) {
+	var preset PipelineParamPreset
+	if err := client.Where("`id` = ?", id).First(&preset).Error; err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// GetPipelineParamPresetByName 根据名称获取流水线下的预设，不存在时返回 nil
+func (client *DBClient) GetPipelineParamPresetByName(appID uint64, branch, pipelineYmlName, name string) (*PipelineParamPreset, error) {
+	var presets []PipelineParamPreset
+	if err := client.Where("`app_id` = ? AND `branch` = ? AND `pipeline_yml_name` = ? AND `name` = ?",
+		appID, branch, pipelineYmlName, name).Limit(1).Find(&presets).Error; err != nil {
+		return nil, err
+	}
+	if len(presets) == 0 {
+		return nil, nil
+	}
+	return &presets[0], nil
+}
+
+// GetDefaultPipelineParamPreset 获取流水线的默认预设，不存在时返回 nil
+func (client *DBClient) GetDefaultPipelineParamPreset(appID uint64, branch, pipelineYmlName string) (*PipelineParamPreset, error) {
+	var presets []PipelineParamPreset
+	if err := client.Where("`app_id` = ? AND `branch` = ? AND `pipeline_yml_name` = ? AND `is_default` = ?",
+		appID, branch, pipelineYmlName, true).Limit(1).Find(&presets).Error; err != nil {
+		return nil, err
+	}
+	if len(presets) == 0 {
+		return nil, nil
+	}
+	return &presets[0], nil
+}
+
+// ListPipelineParamPresets 查询流水线下的预设，默认预设排在最前
+func (client *DBClient) ListPipelineParamPresets(appID uint64, branch, pipelineYmlName string) ([]PipelineParamPreset, error) {
+	var presets []PipelineParamPreset
+	if err := client.Where("`app_id` = ? AND `branch` = ? AND `pipeline_yml_name` = ?", appID, branch, pipelineYmlName).
+		Order("`is_default` DESC, `id` ASC").Find(&presets).Error; err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// DeletePipelineParamPreset 删除预设
+func (client *DBClient) DeletePipelineParamPreset(id uint64) error {
+	return client.Where("`id` = ?", id).Delete(&PipelineParamPreset{}).Error
+}
@@ -89,6 +89,9 @@ func (client *DBClient) DeleteSceneSet(sceneSet *SceneSet, scenes []uint64) erro
 		if err := tx.Where(AutoTestSceneStep{}).Where("scene_id IN (?)", scenes).Delete(AutoTestSceneStep{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("scene_id IN (?)", scenes).Delete(AutoTestSceneVersion{}).Error; err != nil {
+			return err
+		}
 
 		var next SceneSet
 		if err := tx.Where("pre_id = ?", sceneSet.ID).Find(&next).Error; err != nil {
@@ -33,6 +33,7 @@ type QASonarMetricRules struct {
 	ScopeID     string `gorm:"scope_id" json:"scopeId"`
 	MetricKeyID int64  `gorm:"metric_key_id" json:"metricKeyId"`
 	MetricValue string `gorm:"metric_value" json:"metricValue"`
+	IsBlocking  bool   `gorm:"is_blocking" json:"isBlocking"`
 }
 
 func (rule *QASonarMetricRules) ToApi() *apistructs.SonarMetricRuleDto {
@@ -45,6 +46,7 @@ func (rule *QASonarMetricRules) ToApi() *apistructs.SonarMetricRuleDto {
 		ScopeID:     rule.ScopeID,
 		MetricValue: rule.MetricValue,
 		MetricKeyID: rule.MetricKeyID,
+		IsBlocking:  rule.IsBlocking,
 	}
 
 	keys := apistructs.SonarMetricKeys[dto.MetricKeyID]
@@ -144,3 +146,20 @@ func (client *DBClient) ListSonarMetricRules(query *QASonarMetricRules, otherQue
 	}
 	return dbRules, nil
 }
+
+// ImportSonarMetricRules 在同一事务中保存导入的规则并删除被替换的规则
+func (client *DBClient) ImportSonarMetricRules(rules []*QASonarMetricRules, deletes []QASonarMetricRules) error {
+	return client.Transaction(func(tx *gorm.DB) error {
+		for _, rule := range rules {
+			if err := tx.Save(rule).Error; err != nil {
+				return err
+			}
+		}
+		for _, rule := range deletes {
+			if err := tx.Delete(&QASonarMetricRules{}, "scope_type = ? and scope_id = ? and id = ?", rule.ScopeType, rule.ScopeID, rule.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
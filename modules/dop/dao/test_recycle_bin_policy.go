@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestRecycleBinPolicy 项目回收站自动清理策略
+type TestRecycleBinPolicy struct {
+	dbengine.BaseModel
+	ProjectID     uint64
+	RetentionDays int
+	CreatorID     string
+	UpdaterID     string
+}
+
+// TableName 设置模型对应数据库表名称
+func (TestRecycleBinPolicy) TableName() string {
+	return "dice_test_recycle_bin_policies"
+}
+
+// GetTestRecycleBinPolicy 获取项目回收站清理策略，项目未配置时返回 nil
+func (client *DBClient) GetTestRecycleBinPolicy(projectID uint64) (*TestRecycleBinPolicy, error) {
+	var policy TestRecycleBinPolicy
+	if err := client.Where("`project_id` = ?", projectID).First(&policy).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SaveTestRecycleBinPolicy 创建或更新项目回收站清理策略
+func (client *DBClient) SaveTestRecycleBinPolicy(projectID uint64, retentionDays int, operatorID string) (*TestRecycleBinPolicy, error) {
+	policy, err := client.GetTestRecycleBinPolicy(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &TestRecycleBinPolicy{ProjectID: projectID, CreatorID: operatorID}
+	}
+	policy.RetentionDays = retentionDays
+	policy.UpdaterID = operatorID
+	if err := client.Save(policy).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ListProjectIDsWithRecycledTestItems 获取回收站中存在测试集或测试用例的项目
+func (client *DBClient) ListProjectIDsWithRecycledTestItems() ([]uint64, error) {
+	var tsProjectIDs, tcProjectIDs []uint64
+	if err := client.Model(&TestSet{}).Where("`recycled` = ?", true).
+		Pluck("DISTINCT `project_id`", &tsProjectIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := client.Model(&TestCase{}).Where("`recycled` = ?", true).
+		Pluck("DISTINCT `project_id`", &tcProjectIDs).Error; err != nil {
+		return nil, err
+	}
+	return append(tsProjectIDs, tcProjectIDs...), nil
+}
+
+// ListRecycledTestSetsBefore 获取回收时间早于 before 的测试集，以最后更新时间作为回收时间
+func (client *DBClient) ListRecycledTestSetsBefore(projectID uint64, before time.Time, limit int) ([]TestSet, error) {
+	var testSets []TestSet
+	if err := client.Where("`project_id` = ?", projectID).Where("`recycled` = ?", true).
+		Where("`updated_at` < ?", before).Order("`id`").Limit(limit).Find(&testSets).Error; err != nil {
+		return nil, err
+	}
+	return testSets, nil
+}
+
+// ListRecycledTestCaseIDsBefore 获取回收时间早于 before 的测试用例 ID，以最后更新时间作为回收时间
+func (client *DBClient) ListRecycledTestCaseIDsBefore(projectID uint64, before time.Time, limit int) ([]uint64, error) {
+	var ids []uint64
+	if err := client.Model(&TestCase{}).Where("`project_id` = ?", projectID).Where("`recycled` = ?", true).
+		Where("`updated_at` < ?", before).Order("`id`").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -18,7 +18,9 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 
 	"github.com/erda-project/erda/apistructs"
@@ -37,10 +39,16 @@ type TestCase struct {
 	Desc           string
 	Recycled       *bool
 	From           apistructs.TestCaseFrom
+	CopiedFromID   uint64 // 复制来源用例 ID，非复制创建时为 0
+	ReviewState    apistructs.TestCaseReviewState
+	LockVersion    uint64 // 乐观锁版本号，每次更新递增
 	CreatorID      string
 	UpdaterID      string
 }
 
+// ErrTestCaseModified 用例在读取后已被他人修改
+var ErrTestCaseModified = errors.New("test case has been modified")
+
 type TestCaseStepAndResults []apistructs.TestCaseStepAndResult
 
 // TableName 设置模型对应数据库表名称
@@ -78,8 +86,46 @@ func (client *DBClient) CreateTestCase(uc *TestCase) error {
 }
 
 // UpdateTestCase 更新测试用例
+// UpdateTestCase 以 LockVersion 作乐观锁更新用例内容，用例在读取后被他人修改时返回 ErrTestCaseModified
 func (client *DBClient) UpdateTestCase(uc *TestCase) error {
-	return client.Save(uc).Error
+	result := client.Model(&TestCase{}).Where("`id` = ? AND `lock_version` = ?", uc.ID, uc.LockVersion).
+		Updates(map[string]interface{}{
+			"name":             uc.Name,
+			"priority":         uc.Priority,
+			"pre_condition":    uc.PreCondition,
+			"step_and_results": uc.StepAndResults,
+			"desc":             uc.Desc,
+			"review_state":     uc.ReviewState,
+			"updater_id":       uc.UpdaterID,
+			"lock_version":     gorm.Expr("`lock_version` + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTestCaseModified
+	}
+	uc.LockVersion++
+	return nil
+}
+
+// UpdateTestCaseSteps 以 lockVersion 作乐观锁更新步骤及评审状态，用例在读取后被他人修改时返回 ErrTestCaseModified
+func (client *DBClient) UpdateTestCaseSteps(id, lockVersion uint64, steps TestCaseStepAndResults,
+	reviewState apistructs.TestCaseReviewState, updaterID string) error {
+	result := client.Model(&TestCase{}).Where("`id` = ? AND `lock_version` = ?", id, lockVersion).
+		Updates(map[string]interface{}{
+			"step_and_results": steps,
+			"review_state":     reviewState,
+			"updater_id":       updaterID,
+			"lock_version":     gorm.Expr("`lock_version` + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTestCaseModified
+	}
+	return nil
 }
 
 func (client *DBClient) GetTestCaseByID(id uint64) (*TestCase, error) {
@@ -132,6 +178,34 @@ func (client *DBClient) ListTestCasesByTestSetIDs(req apistructs.TestCaseListReq
 	return tcs, nil
 }
 
+// SearchTestCases 在标题、前置条件、步骤及结果中搜索用例，每个关键字都需命中任一字段
+func (client *DBClient) SearchTestCases(projectID uint64, testSetIDs, excludeTestSetIDs []uint64, priorities []apistructs.TestCasePriority,
+	keywords []string, limit int) ([]TestCase, error) {
+	sql := client.Where("`project_id` = ?", projectID).Where("`recycled` = ?", false)
+	if len(testSetIDs) > 0 {
+		sql = sql.Where("`test_set_id` IN (?)", testSetIDs)
+	}
+	if len(excludeTestSetIDs) > 0 {
+		sql = sql.Where("`test_set_id` NOT IN (?)", excludeTestSetIDs)
+	}
+	if len(priorities) > 0 {
+		sql = sql.Where("`priority` IN (?)", priorities)
+	}
+	for _, keyword := range keywords {
+		like := "%" + escapeLike(keyword) + "%"
+		sql = sql.Where("`name` LIKE ? OR `pre_condition` LIKE ? OR `step_and_results` LIKE ?", like, like, like)
+	}
+	var tcs []TestCase
+	if err := sql.Order("`id` DESC").Limit(limit).Find(&tcs).Error; err != nil {
+		return nil, err
+	}
+	return tcs, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
 func (client *DBClient) BatchUpdateTestCases(req apistructs.TestCaseBatchUpdateRequest) error {
 	if len(req.TestCaseIDs) == 0 {
 		return fmt.Errorf("no testcase selected")
@@ -150,6 +224,10 @@ func (client *DBClient) BatchUpdateTestCases(req apistructs.TestCaseBatchUpdateR
 	if req.MoveToTestSetID != nil {
 		kvs["test_set_id"] = *req.MoveToTestSetID
 	}
+	if len(kvs) == 0 {
+		return nil
+	}
+	kvs["lock_version"] = gorm.Expr("`lock_version` + 1")
 
 	return sql.Updates(kvs).Error
 }
@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestCaseAttachment 测试用例及执行结果的附件
+type TestCaseAttachment struct {
+	dbengine.BaseModel
+	ProjectID         uint64
+	TestCaseID        uint64
+	TestPlanCaseRelID uint64
+	Name              string
+	ContentType       string
+	Size              int64
+	FileUUID          string
+	URL               string
+	CreatorID         string
+}
+
+// TableName 表名
+func (TestCaseAttachment) TableName() string {
+	return "dice_test_case_attachments"
+}
+
+func (a TestCaseAttachment) Convert() apistructs.TestCaseAttachment {
+	return apistructs.TestCaseAttachment{
+		ID:                a.ID,
+		ProjectID:         a.ProjectID,
+		TestCaseID:        a.TestCaseID,
+		TestPlanCaseRelID: a.TestPlanCaseRelID,
+		Name:              a.Name,
+		ContentType:       a.ContentType,
+		Size:              a.Size,
+		FileUUID:          a.FileUUID,
+		URL:               a.URL,
+		CreatorID:         a.CreatorID,
+		CreatedAt:         a.CreatedAt,
+	}
+}
+
+// CreateTestCaseAttachmentWithinQuota 锁定用例后校验附件总大小并创建附件, 超出 maxTotalSize 时不创建并返回 false
+func (client *DBClient) CreateTestCaseAttachmentWithinQuota(attachment *TestCaseAttachment, maxTotalSize int64) (bool, error) {
+	var created bool
+	err := client.Transaction(func(tx *gorm.DB) error {
+		var tc TestCase
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("`id` = ?", attachment.TestCaseID).First(&tc).Error; err != nil {
+			return err
+		}
+		var result struct {
+			Total int64
+		}
+		if err := tx.Model(&TestCaseAttachment{}).Select("COALESCE(SUM(`size`), 0) AS total").
+			Where("`test_case_id` = ?", attachment.TestCaseID).Scan(&result).Error; err != nil {
+			return err
+		}
+		if result.Total+attachment.Size > maxTotalSize {
+			return nil
+		}
+		created = true
+		return tx.Create(attachment).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+func (client *DBClient) GetTestCaseAttachment(id uint64) (*TestCaseAttachment, error) {
+	var attachment TestCaseAttachment
+	if err := client.Where("`id` = ?", id).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (client *DBClient) DeleteTestCaseAttachment(id uint64) error {
+	return client.Where("`id` = ?", id).Delete(TestCaseAttachment{}).Error
+}
+
+// ListTestCaseAttachments 查询用例本身 (relID 为 0) 或指定执行结果的附件
+func (client *DBClient) ListTestCaseAttachments(testCaseID, relID uint64) ([]TestCaseAttachment, error) {
+	var attachments []TestCaseAttachment
+	if err := client.Where("`test_case_id` = ? AND `test_plan_case_rel_id` = ?", testCaseID, relID).
+		Order("`id` ASC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// ListTestCaseAttachmentsByTestCaseIDs 批量查询用例的附件, withResults 为 false 时不包含执行结果的附件
+func (client *DBClient) ListTestCaseAttachmentsByTestCaseIDs(testCaseIDs []uint64, withResults bool) ([]TestCaseAttachment, error) {
+	var attachments []TestCaseAttachment
+	if len(testCaseIDs) == 0 {
+		return attachments, nil
+	}
+	sql := client.Where("`test_case_id` IN (?)", testCaseIDs)
+	if !withResults {
+		sql = sql.Where("`test_plan_case_rel_id` = 0")
+	}
+	if err := sql.Order("`id` ASC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// SumTestCaseAttachmentSize 统计用例及其执行结果的附件总大小
+func (client *DBClient) SumTestCaseAttachmentSize(testCaseID uint64) (int64, error) {
+	var result struct {
+		Total int64
+	}
+	if err := client.Model(&TestCaseAttachment{}).Select("COALESCE(SUM(`size`), 0) AS total").
+		Where("`test_case_id` = ?", testCaseID).Scan(&result).Error; err != nil {
+		return 0, err
+	}
+	return result.Total, nil
+}
+
+func (client *DBClient) DeleteTestCaseAttachmentsByTestCaseIDs(testCaseIDs []uint64) error {
+	if len(testCaseIDs) == 0 {
+		return nil
+	}
+	return client.Where("`test_case_id` IN (?)", testCaseIDs).Delete(TestCaseAttachment{}).Error
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestCaseAuditLog 测试用例审计日志
+type TestCaseAuditLog struct {
+	dbengine.BaseModel
+	ProjectID  uint64
+	TestCaseID uint64
+	Action     apistructs.TestCaseAuditAction
+	OperatorID string
+	Changes    TestCaseAuditFieldChanges
+}
+
+type TestCaseAuditFieldChanges []apistructs.TestCaseAuditFieldChange
+
+// TableName 设置模型对应数据库表名称
+func (TestCaseAuditLog) TableName() string {
+	return "dice_test_case_audit_logs"
+}
+
+func (c TestCaseAuditFieldChanges) Value() (driver.Value, error) {
+	if b, err := json.Marshal(c); err != nil {
+		return nil, errors.Errorf("failed to marshal changes, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (c *TestCaseAuditFieldChanges) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid scan source for changes")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, c); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal changes")
+	}
+	return nil
+}
+
+func (l TestCaseAuditLog) Convert() apistructs.TestCaseAuditLog {
+	return apistructs.TestCaseAuditLog{
+		ID:         l.ID,
+		ProjectID:  l.ProjectID,
+		TestCaseID: l.TestCaseID,
+		Action:     l.Action,
+		OperatorID: l.OperatorID,
+		Changes:    l.Changes,
+		CreatedAt:  l.CreatedAt,
+	}
+}
+
+// BatchCreateTestCaseAuditLogs 批量创建审计日志
+func (client *DBClient) BatchCreateTestCaseAuditLogs(logs []TestCaseAuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return client.BulkInsert(logs)
+}
+
+// PagingTestCaseAuditLogs 按时间倒序分页查询用例的审计日志
+func (client *DBClient) PagingTestCaseAuditLogs(projectID, testCaseID uint64, pageNo, pageSize int64) ([]TestCaseAuditLog, uint64, error) {
+	var (
+		logs  []TestCaseAuditLog
+		total uint64
+	)
+	sql := client.Model(&TestCaseAuditLog{}).Where("`project_id` = ? AND `test_case_id` = ?", projectID, testCaseID)
+	if err := sql.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := sql.Order("`id` DESC").Offset((pageNo - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// DeleteTestCaseAuditLogsBefore 分批删除 before 之前创建的审计日志, 返回删除的数量
+func (client *DBClient) DeleteTestCaseAuditLogsBefore(before time.Time, batchSize int) (int, error) {
+	var ids []uint64
+	if err := client.Model(&TestCaseAuditLog{}).Where("`created_at` < ?", before).
+		Order("`id`").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := client.Where("`id` IN (?)", ids).Delete(TestCaseAuditLog{}).Error; err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
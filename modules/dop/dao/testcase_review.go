@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestCaseReview 测试用例评审记录
+type TestCaseReview struct {
+	dbengine.BaseModel
+	TestCaseID uint64
+	Action     apistructs.TestCaseReviewAction
+	FromState  apistructs.TestCaseReviewState
+	ToState    apistructs.TestCaseReviewState
+	OperatorID string
+	Comment    string
+}
+
+// TableName 设置模型对应数据库表名称
+func (TestCaseReview) TableName() string {
+	return "dice_test_case_reviews"
+}
+
+func (r TestCaseReview) Convert() apistructs.TestCaseReview {
+	return apistructs.TestCaseReview{
+		ID:         r.ID,
+		TestCaseID: r.TestCaseID,
+		Action:     r.Action,
+		FromState:  r.FromState,
+		ToState:    r.ToState,
+		OperatorID: r.OperatorID,
+		Comment:    r.Comment,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+// TransitTestCaseReviewState 仅当用例仍处于 review.FromState 时流转至 review.ToState 并记录评审记录;
+// 用例状态已被并发修改时返回 false
+func (client *DBClient) TransitTestCaseReviewState(review *TestCaseReview) (bool, error) {
+	var transited bool
+	err := client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&TestCase{}).
+			Where("`id` = ? AND `review_state` = ?", review.TestCaseID, review.FromState).
+			Updates(map[string]interface{}{"review_state": review.ToState, "lock_version": gorm.Expr("`lock_version` + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		transited = true
+		return tx.Create(review).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return transited, nil
+}
+
+// ListTestCaseReviews 按时间倒序列出评审记录
+func (client *DBClient) ListTestCaseReviews(testCaseID uint64) ([]TestCaseReview, error) {
+	var reviews []TestCaseReview
+	if err := client.Where("`test_case_id` = ?", testCaseID).Order("`id` DESC").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// DeleteTestCaseReviewsByTestCaseIDs 删除用例的所有评审记录
+func (client *DBClient) DeleteTestCaseReviewsByTestCaseIDs(testCaseIDs []uint64) error {
+	return client.Where("`test_case_id` IN (?)", testCaseIDs).Delete(TestCaseReview{}).Error
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/pkg/mysql"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// testCaseVersionCreateAttempts 并发创建版本时版本号冲突的最大尝试次数
+const testCaseVersionCreateAttempts = 3
+
+// TestCaseVersion 测试用例历史版本
+type TestCaseVersion struct {
+	dbengine.BaseModel
+	TestCaseID          uint64
+	Version             uint64
+	Name                string
+	Priority            apistructs.TestCasePriority
+	PreCondition        string
+	StepAndResults      TestCaseStepAndResults
+	APIs                TestCaseVersionAPIs `gorm:"column:apis"`
+	Desc                string
+	OperatorID          string
+	RestoredFromVersion uint64
+}
+
+type TestCaseVersionAPIs []apistructs.TestCaseVersionAPI
+
+// TableName 设置模型对应数据库表名称
+func (TestCaseVersion) TableName() string {
+	return "dice_test_case_versions"
+}
+
+func (apis TestCaseVersionAPIs) Value() (driver.Value, error) {
+	if b, err := json.Marshal(apis); err != nil {
+		return nil, errors.Errorf("failed to marshal apis, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (apis *TestCaseVersionAPIs) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid scan source for apis")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, apis); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal apis")
+	}
+	return nil
+}
+
+func (v TestCaseVersion) Convert() apistructs.TestCaseVersion {
+	return apistructs.TestCaseVersion{
+		ID:                  v.ID,
+		TestCaseID:          v.TestCaseID,
+		Version:             v.Version,
+		Name:                v.Name,
+		Priority:            v.Priority,
+		PreCondition:        v.PreCondition,
+		StepAndResults:      v.StepAndResults,
+		APIs:                v.APIs,
+		Desc:                v.Desc,
+		OperatorID:          v.OperatorID,
+		RestoredFromVersion: v.RestoredFromVersion,
+		CreatedAt:           v.CreatedAt,
+	}
+}
+
+// CreateTestCaseVersion 创建历史版本，版本号为当前最大版本号加一;
+// 并发创建时由 (test_case_id, version) 唯一索引保证版本号不重复, 冲突后重新获取版本号
+func (client *DBClient) CreateTestCaseVersion(v *TestCaseVersion) (err error) {
+	for i := 0; i < testCaseVersionCreateAttempts; i++ {
+		var latest uint64
+		if latest, err = client.GetLatestTestCaseVersion(v.TestCaseID); err != nil {
+			return err
+		}
+		v.Version = latest + 1
+		if err = client.Create(v).Error; err == nil || !mysql.IsUniqueConstraintError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// GetLatestTestCaseVersion 获取最新版本号，无历史版本时返回 0
+func (client *DBClient) GetLatestTestCaseVersion(testCaseID uint64) (uint64, error) {
+	var result struct {
+		Version uint64
+	}
+	if err := client.Model(&TestCaseVersion{}).Select("IFNULL(MAX(`version`), 0) AS version").
+		Where("`test_case_id` = ?", testCaseID).Scan(&result).Error; err != nil {
+		return 0, err
+	}
+	return result.Version, nil
+}
+
+// ListTestCaseVersions 按版本号倒序列出历史版本
+func (client *DBClient) ListTestCaseVersions(testCaseID uint64) ([]TestCaseVersion, error) {
+	var versions []TestCaseVersion
+	if err := client.Where("`test_case_id` = ?", testCaseID).Order("`version` DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (client *DBClient) GetTestCaseVersion(testCaseID, version uint64) (*TestCaseVersion, error) {
+	var v TestCaseVersion
+	if err := client.Where("`test_case_id` = ?", testCaseID).Where("`version` = ?", version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// DeleteTestCaseVersionsByTestCaseIDs 删除用例的所有历史版本
+func (client *DBClient) DeleteTestCaseVersionsByTestCaseIDs(testCaseIDs []uint64) error {
+	return client.Where("`test_case_id` IN (?)", testCaseIDs).Delete(TestCaseVersion{}).Error
+}
@@ -41,6 +41,68 @@ type TestPlan struct {
 	Type       apistructs.TestPlanType
 	IsArchived bool
 	Inode      string
+	// ReportEmail 执行完成后邮件发送测试报告的配置
+	ReportEmail *TestPlanReportEmail
+	// AutoCreateBug 用例执行未通过时是否自动创建缺陷
+	AutoCreateBug bool
+	// ApprovedCasesOnly 是否只允许关联评审通过的用例
+	ApprovedCasesOnly bool
+	// ReportFields 测试报告中的自定义字段
+	ReportFields TestPlanReportFields
+}
+
+// TestPlanReportEmail 以 json 格式存储的邮件发送测试报告配置
+type TestPlanReportEmail apistructs.TestPlanReportEmailConfig
+
+func (c TestPlanReportEmail) Value() (driver.Value, error) {
+	if b, err := json.Marshal(c); err != nil {
+		return nil, errors.Errorf("failed to marshal report_email, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (c *TestPlanReportEmail) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid scan source for report_email")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, c); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal report_email")
+	}
+	return nil
+}
+
+// TestPlanReportFields 以 json 格式存储的测试报告自定义字段
+type TestPlanReportFields []apistructs.TestPlanReportField
+
+func (fields TestPlanReportFields) Value() (driver.Value, error) {
+	if b, err := json.Marshal(fields); err != nil {
+		return nil, errors.Errorf("failed to marshal report_fields, err: %v", err)
+	} else {
+		return string(b), nil
+	}
+}
+func (fields *TestPlanReportFields) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid scan source for report_fields")
+	}
+	if len(v) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(v, fields); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal report_fields")
+	}
+	return nil
 }
 
 type PartnerIDs []string
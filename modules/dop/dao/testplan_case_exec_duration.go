@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestPlanCaseExecDuration 测试计划用例单次执行的耗时记录
+type TestPlanCaseExecDuration struct {
+	dbengine.BaseModel
+	TestPlanID        uint64
+	TestPlanCaseRelID uint64
+	TestCaseID        uint64
+	Source            apistructs.TestCaseExecDurationSource
+	DurationSec       uint64
+	PipelineID        uint64
+	OperatorID        string
+}
+
+// TableName 表名
+func (TestPlanCaseExecDuration) TableName() string {
+	return "dice_test_plan_case_exec_durations"
+}
+
+func (client *DBClient) BatchCreateTestPlanCaseExecDurations(durations []TestPlanCaseExecDuration) error {
+	return client.BulkInsert(durations)
+}
+
+// ListTestPlanCaseExecDurationsByTestCaseIDs 按时间顺序查询用例在所有测试计划中的执行耗时记录
+func (client *DBClient) ListTestPlanCaseExecDurationsByTestCaseIDs(testCaseIDs []uint64) ([]TestPlanCaseExecDuration, error) {
+	var durations []TestPlanCaseExecDuration
+	if len(testCaseIDs) == 0 {
+		return durations, nil
+	}
+	if err := client.Where("`test_case_id` IN (?)", testCaseIDs).
+		Order("`created_at` ASC, `id` ASC").
+		Find(&durations).Error; err != nil {
+		return nil, err
+	}
+	return durations, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestPlanCaseExecHistory 测试计划用例执行状态的变更记录
+type TestPlanCaseExecHistory struct {
+	dbengine.BaseModel
+	TestPlanID        uint64
+	TestPlanCaseRelID uint64
+	ExecStatus        apistructs.TestCaseExecStatus
+	OperatorID        string
+}
+
+// TableName 表名
+func (TestPlanCaseExecHistory) TableName() string {
+	return "dice_test_plan_case_exec_histories"
+}
+
+func (client *DBClient) BatchCreateTestPlanCaseExecHistories(histories []TestPlanCaseExecHistory) error {
+	return client.BulkInsert(histories)
+}
+
+// ListTestPlanCaseExecHistories 按时间顺序查询测试计划在 before 之前的执行状态变更记录
+func (client *DBClient) ListTestPlanCaseExecHistories(testPlanID uint64, before time.Time) ([]TestPlanCaseExecHistory, error) {
+	var histories []TestPlanCaseExecHistory
+	if err := client.Where("`test_plan_id` = ?", testPlanID).
+		Where("`created_at` < ?", before).
+		Order("`created_at` ASC, `id` ASC").
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
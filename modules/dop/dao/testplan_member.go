@@ -17,6 +17,8 @@ package dao
 import (
 	"fmt"
 
+	"github.com/jinzhu/gorm"
+
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/pkg/database/dbengine"
 	"github.com/erda-project/erda/pkg/strutil"
@@ -185,3 +187,55 @@ func (client *DBClient) ListTestPlanOwnersByOwners(owners []string) ([]TestPlanM
 func (client *DBClient) DeleteTestPlanMemberByPlanID(planID uint64) error {
 	return client.Where("test_plan_id = ?", planID).Delete(TestPlanMember{}).Error
 }
+
+// TestPlanMembersCheck 在修改成员前对测试计划当前的成员列表做校验
+type TestPlanMembersCheck func(members []TestPlanMember) error
+
+// lockTestPlanMembers 在事务中锁定测试计划并返回其成员, 保证校验与修改之间成员列表不被并发修改
+func lockTestPlanMembers(tx *gorm.DB, testPlanID uint64) ([]TestPlanMember, error) {
+	var plan TestPlan
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("`id` = ?", testPlanID).First(&plan).Error; err != nil {
+		return nil, err
+	}
+	var members []TestPlanMember
+	if err := tx.Where("`test_plan_id` = ?", testPlanID).Order("`id` ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// OverwriteTestPlanMemberRole 将用户在测试计划中的角色覆盖为 role, check 与修改在同一事务中执行
+func (client *DBClient) OverwriteTestPlanMemberRole(testPlanID uint64, userID string, role apistructs.TestPlanMemberRole, check TestPlanMembersCheck) (*TestPlanMember, error) {
+	member := TestPlanMember{TestPlanID: testPlanID, UserID: userID, Role: role}
+	err := client.Transaction(func(tx *gorm.DB) error {
+		members, err := lockTestPlanMembers(tx, testPlanID)
+		if err != nil {
+			return err
+		}
+		if err := check(members); err != nil {
+			return err
+		}
+		if err := tx.Where("`test_plan_id` = ? AND `user_id` = ?", testPlanID, userID).Delete(&TestPlanMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// DeleteTestPlanMember 移除测试计划成员, check 与删除在同一事务中执行
+func (client *DBClient) DeleteTestPlanMember(testPlanID uint64, userID string, check TestPlanMembersCheck) error {
+	return client.Transaction(func(tx *gorm.DB) error {
+		members, err := lockTestPlanMembers(tx, testPlanID)
+		if err != nil {
+			return err
+		}
+		if err := check(members); err != nil {
+			return err
+		}
+		return tx.Where("`test_plan_id` = ? AND `user_id` = ?", testPlanID, userID).Delete(&TestPlanMember{}).Error
+	})
+}
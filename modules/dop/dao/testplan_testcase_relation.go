@@ -172,6 +172,9 @@ func (client *DBClient) ListTestPlanCaseRels(req apistructs.TestPlanCaseRelListR
 	if len(req.ExecStatuses) > 0 {
 		sql = sql.Where("`exec_status` IN (?)", req.ExecStatuses)
 	}
+	if len(req.TestCaseIDs) > 0 {
+		sql = sql.Where("`test_case_id` IN (?)", req.TestCaseIDs)
+	}
 	if req.UpdatedAtBeginInclude != nil {
 		sql = sql.Where("`updated_at` >= ?", req.UpdatedAtBeginInclude)
 	}
@@ -312,3 +312,21 @@ func (db *DBClient) RecoverTestSet(testSetID, targetTestSetID uint64, name strin
 		"name":      name,
 	}).Error
 }
+
+// MergeTestSet 将测试集下的测试用例及测试计划中的关联移至目标测试集，并删除原测试集
+func (db *DBClient) MergeTestSet(projectID, srcTestSetID, dstTestSetID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TestCase{}).
+			Where("project_id = ?", projectID).
+			Where("test_set_id = ?", srcTestSetID).
+			Update("test_set_id", dstTestSetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&TestPlanCaseRel{}).
+			Where("test_set_id = ?", srcTestSetID).
+			Update("test_set_id", dstTestSetID).Error; err != nil {
+			return err
+		}
+		return tx.Where("`id` = ?", srcTestSetID).Delete(TestSet{}).Error
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// TestSetPermission 用户在测试集上被显式授予的角色
+type TestSetPermission struct {
+	dbengine.BaseModel
+	ProjectID uint64
+	TestSetID uint64
+	UserID    string
+	Role      apistructs.TestSetPermissionRole
+	CreatorID string
+}
+
+// TableName 表名
+func (TestSetPermission) TableName() string {
+	return "dice_test_set_permissions"
+}
+
+// OverwriteTestSetPermission 将用户在测试集上的角色覆盖为 perm.Role
+func (client *DBClient) OverwriteTestSetPermission(perm *TestSetPermission) error {
+	return client.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("`test_set_id` = ? AND `user_id` = ?", perm.TestSetID, perm.UserID).
+			Delete(&TestSetPermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(perm).Error
+	})
+}
+
+// DeleteTestSetPermission 删除用户在测试集上的授权, 返回是否存在该授权
+func (client *DBClient) DeleteTestSetPermission(testSetID uint64, userID string) (bool, error) {
+	result := client.Where("`test_set_id` = ? AND `user_id` = ?", testSetID, userID).Delete(&TestSetPermission{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (client *DBClient) DeleteTestSetPermissionsByTestSetIDs(testSetIDs []uint64) error {
+	if len(testSetIDs) == 0 {
+		return nil
+	}
+	return client.Where("`test_set_id` IN (?)", testSetIDs).Delete(&TestSetPermission{}).Error
+}
+
+// ListTestSetPermissions 查询测试集上的显式授权
+func (client *DBClient) ListTestSetPermissions(testSetID uint64) ([]TestSetPermission, error) {
+	var perms []TestSetPermission
+	if err := client.Where("`test_set_id` = ?", testSetID).Order("`id` ASC").Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// ListUserTestSetPermissions 查询用户在项目下所有测试集上的显式授权
+func (client *DBClient) ListUserTestSetPermissions(projectID uint64, userID string) ([]TestSetPermission, error) {
+	var perms []TestSetPermission
+	if err := client.Where("`project_id` = ? AND `user_id` = ?", projectID, userID).Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/jinzhu/gorm"
 
 	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/pkg/database/dbengine"
 )
 
 type TX struct {
@@ -44,3 +45,10 @@ func (db *DBClient) Sq() *gorm.DB {
 	}
 	return db.DB
 }
+
+// WithTransaction 在事务中执行 fn, fn 中通过 tx 调用的方法均属于同一事务, 方法内部开启的事务并入该事务
+func (db *DBClient) WithTransaction(fn func(tx *DBClient) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return fn(&DBClient{DBEngine: &dbengine.DBEngine{DB: tx}})
+	})
+}
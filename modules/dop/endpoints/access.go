@@ -57,6 +57,37 @@ func (e *Endpoints) CreateAccess(ctx context.Context, r *http.Request, vars map[
 	return httpserver.OkResp(data)
 }
 
+// BatchCreateAccess creates Accesses in batch
+func (e *Endpoints) BatchCreateAccess(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.BatchCreateAccess.NotLogin().ToResp(), nil
+	}
+
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.BatchCreateAccess.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	var body apistructs.BatchCreateAccessBody
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return apierrors.BatchCreateAccess.InvalidParameter("invalid request body").ToResp(), nil
+	}
+
+	var req = apistructs.BatchCreateAccessReq{
+		OrgID:    orgID,
+		Identity: &identity,
+		Body:     &body,
+	}
+
+	data, apiError := e.assetSvc.BatchCreateAccess(&req)
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	return httpserver.OkResp(data)
+}
+
 // ListAccess lists Accesses
 func (e *Endpoints) ListAccess(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identity, err := user.GetIdentityInfo(r)
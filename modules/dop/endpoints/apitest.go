@@ -282,6 +282,13 @@ func (e *Endpoints) CancelApiTestPipeline(ctx context.Context, r *http.Request,
 	if !access.Access {
 		return apierrors.ErrTestPlanCancelAPITest.AccessDenied().ToResp(), nil
 	}
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrTestPlanCancelAPITest.InvalidParameter(err).ToResp(), nil
+	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, testPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+		return errorresp.ErrResp(err)
+	}
 	err = e.bdl.CancelPipeline(apistructs.PipelineCancelRequest{
 		PipelineID:   pipelineID,
 		IdentityInfo: identityInfo,
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
@@ -28,6 +29,7 @@ import (
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 	"github.com/erda-project/erda/pkg/strutil"
+	"github.com/erda-project/erda/pkg/swagger/oas3"
 )
 
 // CreateAPIVersion 创建 API 资料版本
@@ -228,6 +230,133 @@ func (e *Endpoints) DownloadSpecText(ctx context.Context, w http.ResponseWriter,
 	return nil
 }
 
+// DiffAssetVersions 比较 API 资料的两个版本
+func (e *Endpoints) DiffAssetVersions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.DiffAssetVersions.NotLogin().ToResp(), nil
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.DiffAssetVersions.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	versionID, err := strconv.ParseUint(vars[urlPathVersionID], 10, 64)
+	if err != nil {
+		return apierrors.DiffAssetVersions.InvalidParameter(err).ToResp(), nil
+	}
+
+	var req = apistructs.DiffAPIAssetVersionsReq{
+		OrgID:     orgID,
+		Identity:  &identityInfo,
+		AssetID:   vars[urlPathAssetID],
+		VersionID: versionID,
+	}
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.DiffAssetVersions.InvalidParameter(err).ToResp(), nil
+	}
+
+	report, apiError := e.assetSvc.DiffAssetVersions(&req)
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	return httpserver.OkResp(report)
+}
+
+// MockAPIAssetVersion 按 API 资料版本的文档返回 mock 响应
+func (e *Endpoints) MockAPIAssetVersion(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) (err error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.MockAPIAssetVersion.NotLogin().Write(w)
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.MockAPIAssetVersion.MissingParameter(apierrors.MissingOrgID).Write(w)
+	}
+
+	versionID, err := strconv.ParseUint(vars[urlPathVersionID], 10, 64)
+	if err != nil {
+		return apierrors.MockAPIAssetVersion.InvalidParameter(err).Write(w)
+	}
+
+	var req = apistructs.MockAPIAssetVersionReq{
+		OrgID:     orgID,
+		Identity:  &identity,
+		AssetID:   vars[urlPathAssetID],
+		VersionID: versionID,
+		Method:    r.Method,
+		Path:      "/" + vars["path"],
+		Status:    r.Header.Get(oas3.MockStatusHeader),
+		Accept:    r.Header.Get("Accept"),
+		Latency:   r.Header.Get(oas3.MockLatencyHeader),
+	}
+
+	mock, apiError := e.assetSvc.MockAPIAssetVersion(&req)
+	if apiError != nil {
+		return apiError.Write(w)
+	}
+
+	if mock.Latency > 0 {
+		select {
+		case <-time.After(mock.Latency):
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+
+	if mock.ContentType != "" {
+		w.Header().Set("Content-Type", mock.ContentType)
+	}
+	w.WriteHeader(mock.StatusCode)
+	_, err = w.Write(mock.Body)
+	return err
+}
+
+// ExportPostmanCollection 导出 API 资料版本为 Postman Collection
+func (e *Endpoints) ExportPostmanCollection(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) (err error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ExportPostmanCollection.NotLogin().Write(w)
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.ExportPostmanCollection.MissingParameter(apierrors.MissingOrgID).Write(w)
+	}
+
+	versionID, err := strconv.ParseUint(vars[urlPathVersionID], 10, 64)
+	if err != nil {
+		return apierrors.ExportPostmanCollection.InvalidParameter(err).Write(w)
+	}
+
+	var req = apistructs.DownloadSpecTextReq{
+		OrgID:    orgID,
+		Identity: &identity,
+		URIParams: &apistructs.DownloadSpecTextURIParams{
+			AssetID:   vars[urlPathAssetID],
+			VersionID: versionID,
+		},
+		QueryParams: &apistructs.DownloadSpecTextQueryParams{},
+	}
+
+	data, apiError := e.assetSvc.ExportPostmanCollection(&req)
+	if apiError != nil {
+		return apiError.Write(w)
+	}
+
+	attachment := fmt.Sprintf(`attachment; filename="%s-%d.postman_collection.json"`, req.URIParams.AssetID, req.URIParams.VersionID)
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Content-Disposition", attachment)
+
+	if _, err = w.Write(data); err != nil {
+		w.Header().Del("Content-Disposition")
+		return apierrors.ExportPostmanCollection.InternalError(err).Write(w)
+	}
+
+	return nil
+}
+
 // 修改版本 (标记为不建议使用)
 func (e *Endpoints) UpdateAssetVersion(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identity, err := user.GetIdentityInfo(r)
@@ -174,11 +174,14 @@ func (e *Endpoints) UpdateAutoTestScene(ctx context.Context, r *http.Request, va
 			return apierrors.ErrUpdateAutoTestScene.AccessDenied().ToResp(), nil
 		}
 	}
+	// 先记录修改前的内容, 保证启用版本记录前的场景也有可恢复的版本
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	sceneID, err := e.autotestV2.UpdateAutotestScene(req)
 	if err != nil {
 		return apierrors.ErrUpdateAutoTestScene.InternalError(err).ToResp(), nil
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(sceneID)
 }
 
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// CreateAutoTestSceneDataFile 为场景添加 CSV 数据文件, 支持 multipart 上传或引用已上传文件的 uuid
+func (e *Endpoints) CreateAutoTestSceneDataFile(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateAutoTestSceneDataFile.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.AutoTestSceneDataFileCreateRequest
+	var reader io.Reader
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, fileHeader, err := r.FormFile("file")
+		if err != nil {
+			return apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(err).ToResp(), nil
+		}
+		defer f.Close()
+		req.Name = r.FormValue("name")
+		if req.Name == "" {
+			req.Name = fileHeader.Filename
+		}
+		reader = f
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(err).ToResp(), nil
+	}
+	req.SceneID = sceneID
+	req.IdentityInfo = identityInfo
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	file, err := e.autotestV2.CreateAutoTestSceneDataFile(req, reader)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(file)
+}
+
+// ListAutoTestSceneDataFiles 查询场景的数据文件
+func (e *Endpoints) ListAutoTestSceneDataFiles(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneDataFile.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneDataFile.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	files, err := e.autotestV2.ListAutoTestSceneDataFiles(sceneID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(files)
+}
+
+// DeleteAutoTestSceneDataFile 删除场景数据文件
+func (e *Endpoints) DeleteAutoTestSceneDataFile(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	fileID, err := strconv.ParseUint(vars["fileID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneDataFile.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneDataFile.NotLogin().ToResp(), nil
+	}
+
+	file, err := e.autotestV2.GetAutoTestSceneDataFile(fileID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, file.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.autotestV2.DeleteAutoTestSceneDataFile(fileID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(fileID)
+}
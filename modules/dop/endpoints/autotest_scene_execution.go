@@ -0,0 +1,163 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// ListAutoTestSceneExecutions 分页查询场景的执行记录
+func (e *Endpoints) ListAutoTestSceneExecutions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneExecutions.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneExecutions.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneExecutionListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestSceneExecutions.InvalidParameter(err).ToResp(), nil
+	}
+	req.SceneID = sceneID
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.ListAutoTestSceneExecutions(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// GetAutoTestSceneExecution 获取场景执行详情, 包含各步骤的执行结果及请求响应快照
+func (e *Endpoints) GetAutoTestSceneExecution(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	executionID, err := strconv.ParseUint(vars["executionID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneExecution.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneExecution.NotLogin().ToResp(), nil
+	}
+
+	execution, err := e.autotestV2.GetAutoTestSceneExecution(executionID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, execution.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(execution)
+}
+
+// GetAutoTestSceneBaseline 获取场景的响应基线
+func (e *Endpoints) GetAutoTestSceneBaseline(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneBaseline.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneBaseline.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.GetAutoTestSceneBaseline(sceneID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// SaveAutoTestSceneBaseline 以一次执行的响应作为场景的基线, 或更新基线对比时忽略的字段
+func (e *Endpoints) SaveAutoTestSceneBaseline(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrSaveAutoTestSceneBaseline.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrSaveAutoTestSceneBaseline.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneBaselineSaveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrSaveAutoTestSceneBaseline.InvalidParameter(err).ToResp(), nil
+	}
+	req.SceneID = sceneID
+	req.IdentityInfo = identityInfo
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.SaveAutoTestSceneBaseline(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// DeleteAutoTestSceneBaseline 删除场景的响应基线
+func (e *Endpoints) DeleteAutoTestSceneBaseline(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneBaseline.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneBaseline.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.autotestV2.DeleteAutoTestSceneBaseline(sceneID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(sceneID)
+}
@@ -76,6 +76,7 @@ func (e *Endpoints) CreateAutoTestSceneInput(ctx context.Context, r *http.Reques
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	sceneID, err := e.autotestV2.CreateAutoTestSceneInput(req)
 	if err != nil {
 		return errorresp.ErrResp(err)
@@ -84,6 +85,7 @@ func (e *Endpoints) CreateAutoTestSceneInput(ctx context.Context, r *http.Reques
 	if err := e.autotestV2.UpdateAutotestSceneUpdateTime(sc.ID); err != nil {
 		return errorresp.ErrResp(err)
 	}
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(sceneID)
 }
 
@@ -137,11 +139,13 @@ func (e *Endpoints) UpdateAutoTestSceneInput(ctx context.Context, r *http.Reques
 	}
 
 	req.SpaceID = sp.ID
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	sceneID, err := e.autotestV2.UpdateAutoTestSceneInput(req)
 	if err != nil {
 		return apierrors.ErrUpdateAutoTestSceneInput.InternalError(err).ToResp(), nil
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(sceneID)
 }
 
@@ -220,6 +224,7 @@ func (e *Endpoints) DeleteAutoTestSceneInput(ctx context.Context, r *http.Reques
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	id, err = e.autotestV2.DeleteAutoTestSceneInput(id)
 	if err != nil {
 		return apierrors.ErrDeleteAutoTestSceneInput.InternalError(err).ToResp(), nil
@@ -229,5 +234,6 @@ func (e *Endpoints) DeleteAutoTestSceneInput(ctx context.Context, r *http.Reques
 		return errorresp.ErrResp(err)
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(id)
 }
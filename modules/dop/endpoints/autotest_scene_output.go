@@ -76,6 +76,7 @@ func (e *Endpoints) CreateAutoTestSceneOutput(ctx context.Context, r *http.Reque
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	sceneID, err := e.autotestV2.CreateAutoTestSceneOutput(req)
 	if err != nil {
 		return errorresp.ErrResp(err)
@@ -85,6 +86,7 @@ func (e *Endpoints) CreateAutoTestSceneOutput(ctx context.Context, r *http.Reque
 		return errorresp.ErrResp(err)
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(sceneID)
 }
 
@@ -137,11 +139,13 @@ func (e *Endpoints) UpdateAutoTestSceneOutput(ctx context.Context, r *http.Reque
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	outputID, err := e.autotestV2.UpdateAutoTestSceneOutput(req)
 	if err != nil {
 		return apierrors.ErrUpdateAutoTestSceneOutput.InternalError(err).ToResp(), nil
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(outputID)
 }
 
@@ -220,6 +224,7 @@ func (e *Endpoints) DeleteAutoTestSceneOutput(ctx context.Context, r *http.Reque
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	outputID, err := e.autotestV2.DeleteAutoTestSceneOutput(id)
 	if err != nil {
 		return apierrors.ErrDeleteAutoTestSceneOutput.InternalError(err).ToResp(), nil
@@ -228,5 +233,6 @@ func (e *Endpoints) DeleteAutoTestSceneOutput(ctx context.Context, r *http.Reque
 	if err := e.autotestV2.UpdateAutotestSceneUpdateTime(sc.ID); err != nil {
 		return errorresp.ErrResp(err)
 	}
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(outputID)
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	atv2 "github.com/erda-project/erda/modules/dop/services/autotest_v2"
 	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
@@ -78,6 +79,7 @@ func (e *Endpoints) CreateAutoTestSceneStep(ctx context.Context, r *http.Request
 
 	req.SetID = sc.SetID
 	req.SpaceID = sc.SpaceID
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	sceneID, err := e.autotestV2.CreateAutoTestSceneStep(req)
 	if err != nil {
 		return errorresp.ErrResp(err)
@@ -87,6 +89,7 @@ func (e *Endpoints) CreateAutoTestSceneStep(ctx context.Context, r *http.Request
 		return errorresp.ErrResp(err)
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(sceneID)
 }
 
@@ -143,11 +146,16 @@ func (e *Endpoints) UpdateAutoTestSceneStep(ctx context.Context, r *http.Request
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	sceneID, err := e.autotestV2.UpdateAutoTestSceneStep(req)
 	if err != nil {
+		if apiErr, ok := err.(*errorresp.APIError); ok {
+			return apiErr.ToResp(), nil
+		}
 		return apierrors.ErrUpdateAutoTestSceneStep.InternalError(err).ToResp(), nil
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(sceneID)
 }
 
@@ -196,10 +204,12 @@ func (e *Endpoints) MoveAutoTestSceneStep(ctx context.Context, r *http.Request,
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	if err := e.autotestV2.MoveAutoTestSceneStep(req); err != nil {
 		return apierrors.ErrUpdateAutoTestSceneStep.InternalError(err).ToResp(), nil
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp(req.ID)
 }
 
@@ -215,8 +225,10 @@ func (e *Endpoints) GetAutoTestSceneStep(ctx context.Context, r *http.Request, v
 	if err != nil {
 		return apierrors.ErrListAutoTestSceneStep.InvalidParameter(err).ToResp(), nil
 	}
+	result := []apistructs.AutoTestSceneStep{*step.Convert()}
+	atv2.MaskAutoTestSceneStepSecrets(result)
 
-	return httpserver.OkResp(step.Convert())
+	return httpserver.OkResp(result[0])
 }
 
 // ListAutoTestSceneStep 获取场景步骤列表
@@ -245,10 +257,37 @@ func (e *Endpoints) ListAutoTestSceneStep(ctx context.Context, r *http.Request,
 	if err != nil {
 		return apierrors.ErrListAutoTestSceneStep.InternalError(err).ToResp(), nil
 	}
+	atv2.MaskAutoTestSceneStepSecrets(sceneID)
 
 	return httpserver.OkResp(sceneID)
 }
 
+// GetAutoTestSceneStepGraph 获取场景步骤间通过出参引用形成的依赖图, 包含其中的循环引用
+func (e *Endpoints) GetAutoTestSceneStepGraph(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneStepGraph.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneStepGraph.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	graph, err := e.autotestV2.GetAutoTestSceneStepGraph(sceneID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(graph)
+}
+
 // DeleteAutoTestSceneStep 删除场景步骤
 func (e *Endpoints) DeleteAutoTestSceneStep(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	// 解析请求
@@ -298,6 +337,7 @@ func (e *Endpoints) DeleteAutoTestSceneStep(ctx context.Context, r *http.Request
 		}
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, sc.UpdaterID)
 	err = e.autotestV2.DeleteAutoTestSceneStep(id)
 	if err != nil {
 		return apierrors.ErrDeleteAutoTestSceneStep.InternalError(err).ToResp(), nil
@@ -312,6 +352,7 @@ func (e *Endpoints) DeleteAutoTestSceneStep(ctx context.Context, r *http.Request
 		return errorresp.ErrResp(err)
 	}
 
+	e.autotestV2.RecordAutoTestSceneVersion(sc.ID, identityInfo.UserID)
 	return httpserver.OkResp("delete success")
 }
 
@@ -0,0 +1,232 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// CreateAutoTestSceneTemplate 将场景保存为项目下的模板
+func (e *Endpoints) CreateAutoTestSceneTemplate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateAutoTestSceneTemplate.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneTemplateCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	if req.SceneID == 0 {
+		return apierrors.ErrCreateAutoTestSceneTemplate.MissingParameter("sceneID").ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: req.SceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	template, err := e.autotestV2.CreateAutoTestSceneTemplate(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(template)
+}
+
+// UpdateAutoTestSceneTemplate 更新模板, 指定场景时以场景当前内容发布新版本
+func (e *Endpoints) UpdateAutoTestSceneTemplate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	templateID, err := strconv.ParseUint(vars["templateID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSceneTemplate.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneTemplateUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	req.TemplateID = templateID
+	req.IdentityInfo = identityInfo
+
+	template, err := e.autotestV2.GetAutoTestSceneTemplate(templateID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestProjectPermission(identityInfo, template.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.UpdateAutoTestSceneTemplate(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// DeleteAutoTestSceneTemplate 删除模板
+func (e *Endpoints) DeleteAutoTestSceneTemplate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	templateID, err := strconv.ParseUint(vars["templateID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneTemplate.NotLogin().ToResp(), nil
+	}
+
+	template, err := e.autotestV2.GetAutoTestSceneTemplate(templateID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestProjectPermission(identityInfo, template.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.autotestV2.DeleteAutoTestSceneTemplate(templateID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(templateID)
+}
+
+// GetAutoTestSceneTemplate 获取模板详情
+func (e *Endpoints) GetAutoTestSceneTemplate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	templateID, err := strconv.ParseUint(vars["templateID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneTemplate.NotLogin().ToResp(), nil
+	}
+
+	template, err := e.autotestV2.GetAutoTestSceneTemplateDetail(templateID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestProjectPermission(identityInfo, template.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(template)
+}
+
+// ListAutoTestSceneTemplates 查询项目下的模板列表
+func (e *Endpoints) ListAutoTestSceneTemplates(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneTemplate.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneTemplateListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	if req.ProjectID == 0 {
+		return apierrors.ErrListAutoTestSceneTemplate.MissingParameter("projectID").ToResp(), nil
+	}
+	if err := e.checkAutoTestProjectPermission(identityInfo, req.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	templates, err := e.autotestV2.ListAutoTestSceneTemplates(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(templates)
+}
+
+// InstantiateAutoTestSceneTemplate 在场景集中创建模板的实例场景
+func (e *Endpoints) InstantiateAutoTestSceneTemplate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	templateID, err := strconv.ParseUint(vars["templateID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrInstantiateAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrInstantiateAutoTestSceneTemplate.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneTemplateInstantiateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrInstantiateAutoTestSceneTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	if req.SetID == 0 {
+		return apierrors.ErrInstantiateAutoTestSceneTemplate.MissingParameter("setID").ToResp(), nil
+	}
+	req.TemplateID = templateID
+	req.IdentityInfo = identityInfo
+
+	// 场景集所属的测试空间必须在模板所属项目下, 由 service 校验
+	template, err := e.autotestV2.GetAutoTestSceneTemplate(templateID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestProjectPermission(identityInfo, template.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	sceneID, err := e.autotestV2.InstantiateAutoTestSceneTemplate(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(sceneID)
+}
+
+// UpdateAutoTestSceneFromTemplate 用模板最新版本覆盖场景内容
+func (e *Endpoints) UpdateAutoTestSceneFromTemplate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSceneFromTemplate.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSceneFromTemplate.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneUpdateFromTemplateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return apierrors.ErrUpdateAutoTestSceneFromTemplate.InvalidParameter(err).ToResp(), nil
+		}
+	}
+	req.SceneID = sceneID
+	req.IdentityInfo = identityInfo
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	e.autotestV2.RecordAutoTestSceneVersion(scene.ID, scene.UpdaterID)
+	result, err := e.autotestV2.UpdateAutoTestSceneFromTemplate(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	e.autotestV2.RecordAutoTestSceneVersion(scene.ID, identityInfo.UserID)
+	return httpserver.OkResp(result)
+}
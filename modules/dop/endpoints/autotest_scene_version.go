@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// ListAutoTestSceneVersions 查询场景的历史版本
+func (e *Endpoints) ListAutoTestSceneVersions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneVersion.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneVersion.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	versions, err := e.autotestV2.ListAutoTestSceneVersions(sceneID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	userIDs := make([]string, 0, len(versions))
+	for _, v := range versions {
+		userIDs = append(userIDs, v.OperatorID)
+	}
+	return httpserver.OkResp(versions, userIDs)
+}
+
+// GetAutoTestSceneVersion 获取场景的历史版本详情
+func (e *Endpoints) GetAutoTestSceneVersion(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneVersion.InvalidParameter(err).ToResp(), nil
+	}
+	version, err := strconv.ParseUint(vars["version"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneVersion.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneVersion.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, scene.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.GetAutoTestSceneVersion(sceneID, version)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result, []string{result.OperatorID})
+}
+
+// RestoreAutoTestSceneVersion 将场景恢复至历史版本, 恢复后生成新版本
+func (e *Endpoints) RestoreAutoTestSceneVersion(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	sceneID, err := strconv.ParseUint(vars["sceneID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRestoreAutoTestSceneVersion.InvalidParameter(err).ToResp(), nil
+	}
+	version, err := strconv.ParseUint(vars["version"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRestoreAutoTestSceneVersion.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrRestoreAutoTestSceneVersion.NotLogin().ToResp(), nil
+	}
+
+	scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: sceneID})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	sp, err := e.autotestV2.GetSpace(scene.SpaceID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if !sp.IsOpen() {
+		return apierrors.ErrRestoreAutoTestSceneVersion.InvalidState("所属测试空间已锁定").ToResp(), nil
+	}
+	if err := e.checkAutoTestProjectPermission(identityInfo, uint64(sp.ProjectID), apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.RestoreAutoTestSceneVersion(apistructs.AutoTestSceneVersionRestoreRequest{
+		SceneID:      sceneID,
+		Version:      version,
+		IdentityInfo: identityInfo,
+	})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result, []string{result.OperatorID})
+}
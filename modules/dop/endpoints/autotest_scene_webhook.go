@@ -0,0 +1,181 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// CreateAutoTestSceneWebhook 创建场景执行结束的 webhook, 返回的签名密钥仅展示一次
+func (e *Endpoints) CreateAutoTestSceneWebhook(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateAutoTestSceneWebhook.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneWebhookCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateAutoTestSceneWebhook.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	if req.SceneID > 0 {
+		scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: req.SceneID})
+		if err != nil {
+			return errorresp.ErrResp(err)
+		}
+		req.SpaceID = scene.SpaceID
+	}
+	if req.SpaceID == 0 {
+		return apierrors.ErrCreateAutoTestSceneWebhook.MissingParameter("spaceID or sceneID").ToResp(), nil
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, req.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	webhook, err := e.autotestV2.CreateAutoTestSceneWebhook(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(webhook)
+}
+
+// UpdateAutoTestSceneWebhook 更新 webhook, 包括启用和停用
+func (e *Endpoints) UpdateAutoTestSceneWebhook(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	webhookID, err := strconv.ParseUint(vars["webhookID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSceneWebhook.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSceneWebhook.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneWebhookUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateAutoTestSceneWebhook.InvalidParameter(err).ToResp(), nil
+	}
+	req.WebhookID = webhookID
+	req.IdentityInfo = identityInfo
+
+	webhook, err := e.autotestV2.GetAutoTestSceneWebhook(webhookID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, webhook.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.UpdateAutoTestSceneWebhook(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// DeleteAutoTestSceneWebhook 删除 webhook
+func (e *Endpoints) DeleteAutoTestSceneWebhook(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	webhookID, err := strconv.ParseUint(vars["webhookID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneWebhook.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneWebhook.NotLogin().ToResp(), nil
+	}
+
+	webhook, err := e.autotestV2.GetAutoTestSceneWebhook(webhookID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, webhook.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.autotestV2.DeleteAutoTestSceneWebhook(webhookID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(webhookID)
+}
+
+// ListAutoTestSceneWebhooks 查询测试空间或场景的 webhook 列表
+func (e *Endpoints) ListAutoTestSceneWebhooks(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneWebhook.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneWebhookListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestSceneWebhook.InvalidParameter(err).ToResp(), nil
+	}
+
+	if req.SceneID > 0 {
+		scene, err := e.autotestV2.GetAutotestScene(apistructs.AutotestSceneRequest{SceneID: req.SceneID})
+		if err != nil {
+			return errorresp.ErrResp(err)
+		}
+		req.SpaceID = scene.SpaceID
+	}
+	if req.SpaceID == 0 {
+		return apierrors.ErrListAutoTestSceneWebhook.MissingParameter("spaceID or sceneID").ToResp(), nil
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, req.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	webhooks, err := e.autotestV2.ListAutoTestSceneWebhooks(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(webhooks)
+}
+
+// ListAutoTestSceneWebhookDeliveries 分页查询 webhook 的投递记录
+func (e *Endpoints) ListAutoTestSceneWebhookDeliveries(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	webhookID, err := strconv.ParseUint(vars["webhookID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneWebhookDelivery.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSceneWebhookDelivery.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestSceneWebhookDeliveryListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestSceneWebhookDelivery.InvalidParameter(err).ToResp(), nil
+	}
+	req.WebhookID = webhookID
+
+	webhook, err := e.autotestV2.GetAutoTestSceneWebhook(webhookID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, webhook.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.ListAutoTestSceneWebhookDeliveries(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// CreateAutoTestSchedule 创建场景、场景集或测试计划的定时执行
+func (e *Endpoints) CreateAutoTestSchedule(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateAutoTestSchedule.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestScheduleCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateAutoTestSchedule.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	spaceID, err := e.autotestV2.GetScheduleTargetSpaceID(req.TargetType, req.TargetID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, spaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	schedule, err := e.autotestV2.CreateAutoTestSchedule(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(schedule)
+}
+
+// UpdateAutoTestSchedule 更新定时执行, 包括启用和停用
+func (e *Endpoints) UpdateAutoTestSchedule(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	scheduleID, err := strconv.ParseUint(vars["scheduleID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSchedule.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestSchedule.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestScheduleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateAutoTestSchedule.InvalidParameter(err).ToResp(), nil
+	}
+	req.ScheduleID = scheduleID
+	req.IdentityInfo = identityInfo
+
+	schedule, err := e.autotestV2.GetAutoTestSchedule(scheduleID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, schedule.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.UpdateAutoTestSchedule(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// DeleteAutoTestSchedule 删除定时执行
+func (e *Endpoints) DeleteAutoTestSchedule(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	scheduleID, err := strconv.ParseUint(vars["scheduleID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSchedule.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSchedule.NotLogin().ToResp(), nil
+	}
+
+	schedule, err := e.autotestV2.GetAutoTestSchedule(scheduleID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, schedule.SpaceID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.autotestV2.DeleteAutoTestSchedule(scheduleID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(scheduleID)
+}
+
+// ListAutoTestSchedules 查询定时执行列表, 结果中包含下次触发时间
+func (e *Endpoints) ListAutoTestSchedules(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestSchedule.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestScheduleListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestSchedule.InvalidParameter(err).ToResp(), nil
+	}
+
+	spaceID := req.SpaceID
+	if req.TargetID > 0 {
+		if spaceID, err = e.autotestV2.GetScheduleTargetSpaceID(req.TargetType, req.TargetID); err != nil {
+			return errorresp.ErrResp(err)
+		}
+		req.SpaceID = 0
+	}
+	if spaceID == 0 {
+		return apierrors.ErrListAutoTestSchedule.MissingParameter("spaceID or targetID").ToResp(), nil
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, spaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	schedules, err := e.autotestV2.ListAutoTestSchedules(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(schedules)
+}
+
+// ListAutoTestScheduleRecords 分页查询定时执行的触发记录
+func (e *Endpoints) ListAutoTestScheduleRecords(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	scheduleID, err := strconv.ParseUint(vars["scheduleID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListAutoTestScheduleRecord.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestScheduleRecord.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestScheduleRecordListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestScheduleRecord.InvalidParameter(err).ToResp(), nil
+	}
+	req.ScheduleID = scheduleID
+
+	schedule, err := e.autotestV2.GetAutoTestSchedule(scheduleID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, schedule.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.ListAutoTestScheduleRecords(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// ListAutoTestScheduleUpcoming 查询定时执行接下来的触发时间
+func (e *Endpoints) ListAutoTestScheduleUpcoming(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	scheduleID, err := strconv.ParseUint(vars["scheduleID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListAutoTestScheduleUpcoming.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListAutoTestScheduleUpcoming.NotLogin().ToResp(), nil
+	}
+	var req apistructs.AutoTestScheduleUpcomingRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListAutoTestScheduleUpcoming.InvalidParameter(err).ToResp(), nil
+	}
+	req.ScheduleID = scheduleID
+
+	schedule, err := e.autotestV2.GetAutoTestSchedule(scheduleID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, schedule.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.autotestV2.ListAutoTestScheduleUpcoming(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(result)
+}
+
+// checkAutoTestSpacePermission 校验用户对测试空间所属项目中自动化测试场景的权限
+func (e *Endpoints) checkAutoTestSpacePermission(identityInfo apistructs.IdentityInfo, spaceID uint64, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	sp, err := e.autotestV2.GetSpace(spaceID)
+	if err != nil {
+		return err
+	}
+	return e.checkAutoTestProjectPermission(identityInfo, uint64(sp.ProjectID), action)
+}
+
+// checkAutoTestProjectPermission 校验用户对项目下自动化测试场景的权限
+func (e *Endpoints) checkAutoTestProjectPermission(identityInfo apistructs.IdentityInfo, projectID uint64, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  projectID,
+		Resource: apistructs.AutotestSceneResource,
+		Action:   action,
+	})
+	if err != nil {
+		return err
+	}
+	if !access.Access {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	return nil
+}
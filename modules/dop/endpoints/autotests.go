@@ -17,7 +17,9 @@ package endpoints
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
@@ -427,7 +429,10 @@ func (e *Endpoints) CreateAutoTestGlobalConfig(ctx context.Context, r *http.Requ
 	}
 	req.IdentityInfo = identityInfo
 
-	// TODO: 鉴权
+	// 鉴权, 继承的全局配置需与当前配置属于同一 scope, 由 service 校验
+	if err := e.checkAutoTestGlobalConfigPermission(identityInfo, req.Scope, req.ScopeID, apistructs.CreateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	cfg, err := e.autotest.CreateGlobalConfig(req)
 	if err != nil {
@@ -454,7 +459,14 @@ func (e *Endpoints) UpdateAutoTestGlobalConfig(ctx context.Context, r *http.Requ
 	req.PipelineCmsNs = vars["ns"]
 	req.IdentityInfo = identityInfo
 
-	// TODO: 鉴权
+	// 鉴权
+	scope, scopeID, err := e.autotest.GetGlobalConfigScope(req.PipelineCmsNs)
+	if err != nil {
+		return apierrors.ErrUpdateAutoTestGlobalConfig.InternalError(err).ToResp(), nil
+	}
+	if err := e.checkAutoTestGlobalConfigPermission(identityInfo, scope, scopeID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	cfg, err := e.autotest.UpdateGlobalConfig(req)
 	if err != nil {
@@ -470,7 +482,14 @@ func (e *Endpoints) DeleteAutoTestGlobalConfig(ctx context.Context, r *http.Requ
 		return apierrors.ErrDeleteAutoTestGlobalConfig.NotLogin().ToResp(), nil
 	}
 
-	// TODO: 鉴权
+	// 鉴权
+	scope, scopeID, err := e.autotest.GetGlobalConfigScope(vars["ns"])
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestGlobalConfig.InternalError(err).ToResp(), nil
+	}
+	if err := e.checkAutoTestGlobalConfigPermission(identityInfo, scope, scopeID, apistructs.DeleteAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	cfg, err := e.autotest.DeleteGlobalConfig(apistructs.AutoTestGlobalConfigDeleteRequest{
 		PipelineCmsNs: vars["ns"],
@@ -489,10 +508,14 @@ func (e *Endpoints) ListAutoTestGlobalConfigs(ctx context.Context, r *http.Reque
 		return apierrors.ErrListAutoTestGlobalConfigs.NotLogin().ToResp(), nil
 	}
 
-	// TODO: 鉴权
+	// 鉴权
+	scope, scopeID := r.URL.Query().Get("scope"), r.URL.Query().Get("scopeID")
+	if err := e.checkAutoTestGlobalConfigPermission(identityInfo, scope, scopeID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
 	cfgs, err := e.autotest.ListGlobalConfigs(apistructs.AutoTestGlobalConfigListRequest{
-		Scope:        r.URL.Query().Get("scope"),
-		ScopeID:      r.URL.Query().Get("scopeID"),
+		Scope:        scope,
+		ScopeID:      scopeID,
 		IdentityInfo: identityInfo,
 	})
 	if err != nil {
@@ -508,3 +531,18 @@ func (e *Endpoints) ListAutoTestGlobalConfigs(ctx context.Context, r *http.Reque
 
 	return httpserver.OkResp(cfgs, userIDs)
 }
+
+// checkAutoTestGlobalConfigPermission 校验用户对全局配置所属项目的自动化测试权限, 仅支持项目级 scope
+func (e *Endpoints) checkAutoTestGlobalConfigPermission(identityInfo apistructs.IdentityInfo, scope, scopeID, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	if scope != apistructs.FileTreeScopeAutoTest {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	projectID, err := strconv.ParseUint(scopeID, 10, 64)
+	if err != nil {
+		return apierrors.ErrCheckPermission.InvalidParameter(fmt.Sprintf("scopeID: %s", scopeID))
+	}
+	return e.checkAutoTestProjectPermission(identityInfo, projectID, action)
+}
@@ -184,6 +184,77 @@ func (e *Endpoints) UpdateClient(ctx context.Context, r *http.Request, vars map[
 	return httpserver.OkResp(map[string]interface{}{"client": client, "sk": sk})
 }
 
+// RotateClientSecret 轮换客户端密钥
+func (e *Endpoints) RotateClientSecret(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.RotateClientSecret.NotLogin().ToResp(), nil
+	}
+
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.RotateClientSecret.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	clientModelID, err := strconv.ParseUint(vars[urlPathClientID], 10, 64)
+	if err != nil {
+		return apierrors.RotateClientSecret.InvalidParameter("invalid client primary id").ToResp(), nil
+	}
+	var req = apistructs.RotateClientSecretReq{
+		OrgID:     orgID,
+		Identity:  &identity,
+		URIParams: &apistructs.UpdateClientURIParams{ClientID: clientModelID},
+		Body:      new(apistructs.RotateClientSecretBody),
+	}
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(req.Body); err != nil {
+			return apierrors.RotateClientSecret.InvalidParameter("invalid body").ToResp(), nil
+		}
+	}
+
+	data, apiError := e.assetSvc.RotateClientSecret(&req)
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	return httpserver.OkResp(data)
+}
+
+// ListClientSecretRotations 查询客户端密钥轮换记录
+func (e *Endpoints) ListClientSecretRotations(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ListSecretRotations.NotLogin().ToResp(), nil
+	}
+
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.ListSecretRotations.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	clientModelID, err := strconv.ParseUint(vars[urlPathClientID], 10, 64)
+	if err != nil {
+		return apierrors.ListSecretRotations.InvalidParameter("invalid client primary id").ToResp(), nil
+	}
+	var req = apistructs.ListClientSecretRotationsReq{
+		OrgID:     orgID,
+		Identity:  &identity,
+		URIParams: &apistructs.UpdateClientURIParams{ClientID: clientModelID},
+	}
+
+	rotations, apiError := e.assetSvc.ListClientSecretRotations(&req)
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	var userIDs []string
+	for _, rotation := range rotations {
+		userIDs = append(userIDs, rotation.CreatorID)
+	}
+
+	return httpserver.OkResp(rotations, userIDs)
+}
+
 func (e *Endpoints) DeleteClient(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identity, err := user.GetIdentityInfo(r)
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 )
 
 // AddConfigs 添加配置条目
@@ -242,6 +243,79 @@ func (e *Endpoints) ImportConfigs(ctx context.Context, r *http.Request, vars map
 	return httpserver.OkResp(nil)
 }
 
+// BulkExportConfigs 导出 namespace 的所有配置为可导入其他 namespace 的文档
+func (e *Endpoints) BulkExportConfigs(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrExportEnvConfig.NotLogin().ToResp(), nil
+	}
+
+	namespace := r.URL.Query().Get("namespace_name")
+	if namespace == "" {
+		return apierrors.ErrExportEnvConfig.MissingParameter("namespace_name").ToResp(), nil
+	}
+
+	doc, err := e.envConfig.ExportConfigs(e.permission, identityInfo, namespace)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(doc)
+}
+
+// BulkImportConfigs 将配置文档导入到指定 namespace
+func (e *Endpoints) BulkImportConfigs(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrImportEnvConfig.NotLogin().ToResp(), nil
+	}
+
+	namespace := r.URL.Query().Get("namespace_name")
+	if namespace == "" {
+		return apierrors.ErrImportEnvConfig.MissingParameter("namespace_name").ToResp(), nil
+	}
+
+	var req apistructs.EnvConfigImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrImportEnvConfig.InvalidParameter(err).ToResp(), nil
+	}
+
+	result, err := e.envConfig.ImportConfigs(e.permission, identityInfo, namespace, &req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
+// EncryptConfigs 将已有的明文配置标记为敏感配置并加密
+func (e *Endpoints) EncryptConfigs(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrEncryptEnvConfig.NotLogin().ToResp(), nil
+	}
+
+	namespace := r.URL.Query().Get("namespace_name")
+	if namespace == "" {
+		return apierrors.ErrEncryptEnvConfig.MissingParameter("namespace_name").ToResp(), nil
+	}
+
+	var req apistructs.EnvConfigEncryptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrEncryptEnvConfig.InvalidParameter(err).ToResp(), nil
+	}
+
+	keys, err := e.envConfig.EncryptConfigs(e.permission, identityInfo, namespace, req.Keys)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(keys)
+}
+
 // GetMultiNamespaceConfigs 获取多个 namespace 的所有环境变量配置
 func (e *Endpoints) GetMultiNamespaceConfigs(ctx context.Context, r *http.Request, vars map[string]string) (
 	httpserver.Responser, error) {
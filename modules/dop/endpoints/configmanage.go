@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -220,6 +221,137 @@ func (e *Endpoints) getCmsNsConfigs(ctx context.Context, r *http.Request, vars m
 	return httpserver.OkResp(configsResp)
 }
 
+// cmsConfigMaskedValue 加密配置在比较结果中以掩码返回
+const cmsConfigMaskedValue = "******"
+
+// diffCmsNsConfigs 比较两个命名空间的流水线配置, 用户需要同时拥有两个命名空间所属应用的配置查看权限
+func (e *Endpoints) diffCmsNsConfigs(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	// 鉴权
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetPipelineCmsConfigs.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.PipelineCmsConfigDiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrGetPipelineCmsConfigs.InvalidParameter(err).ToResp(), nil
+	}
+	if req.SourceNamespace == "" {
+		return apierrors.ErrGetPipelineCmsConfigs.MissingParameter("sourceNamespace").ToResp(), nil
+	}
+	if req.TargetNamespace == "" {
+		return apierrors.ErrGetPipelineCmsConfigs.MissingParameter("targetNamespace").ToResp(), nil
+	}
+	if req.TargetAppID == 0 {
+		req.TargetAppID = req.SourceAppID
+	}
+
+	sourceConfigs, err := e.getCmsNsConfigsForDiff(ctx, identityInfo, req.SourceAppID, req.SourceNamespace)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	targetConfigs, err := e.getCmsNsConfigsForDiff(ctx, identityInfo, req.TargetAppID, req.TargetNamespace)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result := diffCmsConfigs(sourceConfigs, targetConfigs)
+	result.SourceNamespace = req.SourceNamespace
+	result.TargetNamespace = req.TargetNamespace
+
+	return httpserver.OkResp(result)
+}
+
+// getCmsNsConfigsForDiff 校验权限及命名空间归属后获取解密的配置, 解密的值只用于比较, 不可返回或打印
+func (e *Endpoints) getCmsNsConfigsForDiff(ctx context.Context, identityInfo apistructs.IdentityInfo,
+	appID uint64, ns string) ([]*cmspb.PipelineCmsConfig, error) {
+	if appID == 0 {
+		return nil, apierrors.ErrGetPipelineCmsConfigs.MissingParameter("appID")
+	}
+	if err := e.permission.CheckAppConfig(identityInfo, appID, apistructs.GetAction); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(ns, fmt.Sprintf("%s-%d-", cms.PipelineAppConfigNameSpacePrefix, appID)) {
+		return nil, apierrors.ErrGetPipelineCmsConfigs.InvalidParameter(
+			fmt.Errorf("namespace %s does not belong to application %d", ns, appID))
+	}
+
+	pipelineSource, err := e.getPipelineSource(appID)
+	if err != nil {
+		return nil, apierrors.ErrGetPipelineCmsConfigs.InvalidParameter(err)
+	}
+	kvs, err := e.pipelineCms.GetCmsNsConfigs(utils.WithInternalClientContext(ctx), &cmspb.CmsNsConfigsGetRequest{
+		Ns:             ns,
+		PipelineSource: pipelineSource,
+		GlobalDecrypt:  true,
+	})
+	if err != nil {
+		return nil, apierrors.ErrGetPipelineCmsConfigs.InternalError(err)
+	}
+	return kvs.Data, nil
+}
+
+// diffCmsConfigs 按 key 比较配置, 类型或值不同即视为差异, 加密配置的值以掩码返回
+func diffCmsConfigs(source, target []*cmspb.PipelineCmsConfig) *apistructs.PipelineCmsConfigDiffResult {
+	result := &apistructs.PipelineCmsConfigDiffResult{
+		OnlyInSource: []apistructs.PipelineCmsConfigDiffItem{},
+		OnlyInTarget: []apistructs.PipelineCmsConfigDiffItem{},
+		Different:    []apistructs.PipelineCmsConfigDiffItem{},
+	}
+	maskValue := func(config *cmspb.PipelineCmsConfig) string {
+		if config.EncryptInDB && config.Value != "" {
+			return cmsConfigMaskedValue
+		}
+		return config.Value
+	}
+
+	targetMap := make(map[string]*cmspb.PipelineCmsConfig, len(target))
+	for _, config := range target {
+		targetMap[config.Key] = config
+	}
+	sourceMap := make(map[string]*cmspb.PipelineCmsConfig, len(source))
+	for _, config := range source {
+		sourceMap[config.Key] = config
+		targetConfig, ok := targetMap[config.Key]
+		if !ok {
+			result.OnlyInSource = append(result.OnlyInSource, apistructs.PipelineCmsConfigDiffItem{
+				Key:         config.Key,
+				SourceType:  config.Type,
+				SourceValue: maskValue(config),
+				Encrypt:     config.EncryptInDB,
+			})
+			continue
+		}
+		if config.Type == targetConfig.Type && config.Value == targetConfig.Value {
+			continue
+		}
+		result.Different = append(result.Different, apistructs.PipelineCmsConfigDiffItem{
+			Key:         config.Key,
+			SourceType:  config.Type,
+			SourceValue: maskValue(config),
+			TargetType:  targetConfig.Type,
+			TargetValue: maskValue(targetConfig),
+			Encrypt:     config.EncryptInDB || targetConfig.EncryptInDB,
+		})
+	}
+	for _, config := range target {
+		if _, ok := sourceMap[config.Key]; ok {
+			continue
+		}
+		result.OnlyInTarget = append(result.OnlyInTarget, apistructs.PipelineCmsConfigDiffItem{
+			Key:         config.Key,
+			TargetType:  config.Type,
+			TargetValue: maskValue(config),
+			Encrypt:     config.EncryptInDB,
+		})
+	}
+
+	for _, items := range [][]apistructs.PipelineCmsConfigDiffItem{result.OnlyInSource, result.OnlyInTarget, result.Different} {
+		sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	}
+	return result
+}
+
 func (e *Endpoints) getPipelineSource(appID uint64) (string, error) {
 	// 获取 app 类型
 	appInfo, err := e.bdl.GetApp(appID)
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestDiffCmsConfigs(t *testing.T) {
+	source := []*cmspb.PipelineCmsConfig{
+		{Key: "B", Value: "same", Type: "kv"},
+		{Key: "A", Value: "only-source", Type: "kv"},
+		{Key: "C", Value: "source-secret", Type: "kv", EncryptInDB: true},
+		{Key: "D", Value: "same-secret", Type: "kv", EncryptInDB: true},
+		{Key: "E", Value: "v", Type: "kv"},
+	}
+	target := []*cmspb.PipelineCmsConfig{
+		{Key: "B", Value: "same", Type: "kv"},
+		{Key: "C", Value: "target-secret", Type: "kv", EncryptInDB: true},
+		{Key: "D", Value: "same-secret", Type: "kv", EncryptInDB: true},
+		{Key: "E", Value: "v", Type: "dice-file"},
+		{Key: "F", Value: "only-target-secret", Type: "kv", EncryptInDB: true},
+	}
+
+	result := diffCmsConfigs(source, target)
+	assert.Equal(t, []apistructs.PipelineCmsConfigDiffItem{
+		{Key: "A", SourceType: "kv", SourceValue: "only-source"},
+	}, result.OnlyInSource)
+	assert.Equal(t, []apistructs.PipelineCmsConfigDiffItem{
+		{Key: "F", TargetType: "kv", TargetValue: cmsConfigMaskedValue, Encrypt: true},
+	}, result.OnlyInTarget)
+	assert.Equal(t, []apistructs.PipelineCmsConfigDiffItem{
+		{Key: "C", SourceType: "kv", SourceValue: cmsConfigMaskedValue, TargetType: "kv", TargetValue: cmsConfigMaskedValue, Encrypt: true},
+		{Key: "E", SourceType: "kv", SourceValue: "v", TargetType: "dice-file", TargetValue: "v"},
+	}, result.Different)
+}
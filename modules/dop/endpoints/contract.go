@@ -23,6 +23,7 @@ import (
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -193,6 +194,50 @@ func (e *Endpoints) UpdateContract(ctx context.Context, r *http.Request, vars ma
 	return httpserver.OkResp(map[string]interface{}{"client": client, "contract": contract})
 }
 
+// ApproveContract 通过调用申请
+func (e *Endpoints) ApproveContract(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	return e.approveOrRejectContract(r, vars, apierrors.ApproveContract, e.assetSvc.ApproveContract)
+}
+
+// RejectContract 拒绝调用申请
+func (e *Endpoints) RejectContract(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	return e.approveOrRejectContract(r, vars, apierrors.RejectContract, e.assetSvc.RejectContract)
+}
+
+func (e *Endpoints) approveOrRejectContract(r *http.Request, vars map[string]string, apiError *errorresp.APIError,
+	handle func(*apistructs.ContractApprovalReq) (*apistructs.ContractModel, *errorresp.APIError)) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apiError.NotLogin().ToResp(), nil
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apiError.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	var req = apistructs.ContractApprovalReq{
+		OrgID:    orgID,
+		Identity: &identity,
+		URIParams: &apistructs.UpdateContractURIParams{
+			ClientID:   vars[urlPathClientID],
+			ContractID: vars[urlPathContractID],
+		},
+		Body: new(apistructs.ContractApprovalBody),
+	}
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(req.Body); err != nil {
+			return apiError.InvalidParameter(err).ToResp(), nil
+		}
+	}
+
+	contract, apiErr := handle(&req)
+	if apiErr != nil {
+		return apiErr.ToResp(), nil
+	}
+
+	return httpserver.OkResp(contract, []string{contract.CreatorID, contract.UpdaterID})
+}
+
 func (e *Endpoints) DeleteContract(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identity, err := user.GetIdentityInfo(r)
 	if err != nil {
@@ -84,6 +84,8 @@ const (
 	GitDeleteTagCallback    = "/api/actions/git-tag-delete-callback"
 	IssueCallback           = "/api/actions/issue-callback"
 	MrCheckRunCallback      = "/api/actions/check-run-callback"
+	AutotestCallback        = "/api/actions/autotest-callback"
+	APITestCallback         = "/api/actions/apitest-callback"
 )
 
 type EventCallback struct {
@@ -104,6 +106,8 @@ var eventCallbacks = []EventCallback{
 	{Name: "issue", Path: IssueCallback, Events: []string{"issue"}},
 	{Name: "check-run", Path: MrCheckRunCallback, Events: []string{"check-run"}},
 	{Name: "qa_git_mr_create", Path: "/api/callbacks/git-mr-create", Events: []string{"git_create_mr"}},
+	{Name: "autotest_pipeline", Path: AutotestCallback, Events: []string{"pipeline"}},
+	{Name: "apitest_pipeline", Path: APITestCallback, Events: []string{"pipeline"}},
 }
 
 // Routes 返回 endpoints 的所有 endpoint 方法，也就是 route.
@@ -126,6 +130,13 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/api-assets/{assetID}/versions/{versionID}", Method: http.MethodPut, Handler: e.UpdateAssetVersion},
 		{Path: "/api/api-assets/{assetID}/versions/{versionID}", Method: http.MethodDelete, Handler: e.DeleteAPIAssetVersion},
 		{Path: "/api/api-assets/{assetID}/versions/{versionID}/export", Method: http.MethodGet, WriterHandler: e.DownloadSpecText},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/postman-collection", Method: http.MethodGet, WriterHandler: e.ExportPostmanCollection},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/diff", Method: http.MethodGet, Handler: e.DiffAssetVersions},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/mock/{path:.*}", Method: http.MethodGet, WriterHandler: e.MockAPIAssetVersion},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/mock/{path:.*}", Method: http.MethodPost, WriterHandler: e.MockAPIAssetVersion},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/mock/{path:.*}", Method: http.MethodPut, WriterHandler: e.MockAPIAssetVersion},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/mock/{path:.*}", Method: http.MethodPatch, WriterHandler: e.MockAPIAssetVersion},
+		{Path: "/api/api-assets/{assetID}/versions/{versionID}/mock/{path:.*}", Method: http.MethodDelete, WriterHandler: e.MockAPIAssetVersion},
 
 		{Path: "/api/api-assets/{assetID}/swagger-versions", Method: http.MethodGet, Handler: e.ListSwaggerVersions},
 
@@ -147,16 +158,21 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/api-clients/{clientID}", Method: http.MethodGet, Handler: e.GetClient},
 		{Path: "/api/api-clients/{clientID}", Method: http.MethodPut, Handler: e.UpdateClient},
 		{Path: "/api/api-clients/{clientID}", Method: http.MethodDelete, Handler: e.DeleteClient},
+		{Path: "/api/api-clients/{clientID}/actions/rotate-secret", Method: http.MethodPost, Handler: e.RotateClientSecret},
+		{Path: "/api/api-clients/{clientID}/secret-rotations", Method: http.MethodGet, Handler: e.ListClientSecretRotations},
 
 		{Path: "/api/api-clients/{clientID}/contracts", Method: http.MethodPost, Handler: e.CreateContract},
 		{Path: "/api/api-clients/{clientID}/contracts", Method: http.MethodGet, Handler: e.ListContract},
 		{Path: "/api/api-clients/{clientID}/contracts/{contractID}", Method: http.MethodGet, Handler: e.GetContract},
 		{Path: "/api/api-clients/{clientID}/contracts/{contractID}", Method: http.MethodPut, Handler: e.UpdateContract},
 		{Path: "/api/api-clients/{clientID}/contracts/{contractID}", Method: http.MethodDelete, Handler: e.DeleteContract},
+		{Path: "/api/api-clients/{clientID}/contracts/{contractID}/actions/approve", Method: http.MethodPost, Handler: e.ApproveContract},
+		{Path: "/api/api-clients/{clientID}/contracts/{contractID}/actions/reject", Method: http.MethodPost, Handler: e.RejectContract},
 
 		{Path: "/api/api-clients/{clientID}/contracts/{contractID}/operation-records", Method: http.MethodGet, Handler: e.ListContractRecords},
 
 		{Path: "/api/api-access", Method: http.MethodPost, Handler: e.CreateAccess},
+		{Path: "/api/api-access/actions/batch-create", Method: http.MethodPost, Handler: e.BatchCreateAccess},
 		{Path: "/api/api-access", Method: http.MethodGet, Handler: e.ListAccess},
 		{Path: "/api/api-access/{accessID}", Method: http.MethodGet, Handler: e.GetAccess},
 		{Path: "/api/api-access/{accessID}", Method: http.MethodPut, Handler: e.UpdateAccess},
@@ -174,6 +190,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 
 		{Path: "/api/apim/operations", Method: http.MethodGet, Handler: e.SearchOperations},
 		{Path: "/api/apim/operations/{id}", Method: http.MethodGet, Handler: e.GetOperation},
+		{Path: "/api/apim/search", Method: http.MethodGet, Handler: e.SearchAPIAssets},
 
 		{Path: "/api/apim/validate-swagger", Method: http.MethodPost, Handler: e.ValidateSwagger},
 
@@ -183,6 +200,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 
 		// cdp 事件回调
 		{Path: CDPCallbackPath, Method: http.MethodPost, Handler: e.CDPCallback},
+		{Path: AutotestCallback, Method: http.MethodPost, Handler: e.AutotestCallback},
+		{Path: APITestCallback, Method: http.MethodPost, Handler: e.APITestCallback},
 		{Path: GitCreateMrCallback, Method: http.MethodPost, Handler: e.RepoMrEventCallback},
 		{Path: GitMergeMrCallback, Method: http.MethodPost, Handler: e.RepoMrEventCallback},
 		{Path: GitCloseMrCallback, Method: http.MethodPost, Handler: e.RepoMrEventCallback},
@@ -204,6 +223,11 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/cicds/actions/app-invoked-combos", Method: http.MethodGet, Handler: e.pipelineAppInvokedCombos},
 		{Path: "/api/cicds/actions/fetch-pipeline-id", Method: http.MethodGet, Handler: e.fetchPipelineByAppInfo},
 		{Path: "/api/cicds/actions/app-all-valid-branch-workspaces", Method: http.MethodGet, Handler: e.branchWorkspaceMap},
+		{Path: "/api/cicds/actions/batch-cancel", Method: http.MethodPost, Handler: e.pipelineBatchCancel},
+		{Path: "/api/cicds/param-presets", Method: http.MethodPost, Handler: e.createPipelineParamPreset},
+		{Path: "/api/cicds/param-presets", Method: http.MethodGet, Handler: e.listPipelineParamPresets},
+		{Path: "/api/cicds/param-presets/{presetID}", Method: http.MethodPut, Handler: e.updatePipelineParamPreset},
+		{Path: "/api/cicds/param-presets/{presetID}", Method: http.MethodDelete, Handler: e.deletePipelineParamPreset},
 		{Path: "/api/cicds/{pipelineID}/actions/run", Method: http.MethodPost, Handler: e.pipelineRun},
 		{Path: "/api/cicds/{pipelineID}/actions/cancel", Method: http.MethodPost, Handler: e.pipelineCancel},
 		{Path: "/api/cicds/{pipelineID}/actions/rerun", Method: http.MethodPost, Handler: e.pipelineRerun},
@@ -216,6 +240,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/cicd-crons", Method: http.MethodGet, Handler: e.pipelineCronPaging},
 		{Path: "/api/cicd-crons/{cronID}/actions/start", Method: http.MethodPut, Handler: e.pipelineCronStart},
 		{Path: "/api/cicd-crons/{cronID}/actions/stop", Method: http.MethodPut, Handler: e.pipelineCronStop},
+		{Path: "/api/cicd-crons/{cronID}/actions/next-fire-time", Method: http.MethodGet, Handler: e.pipelineCronNextFireTime},
+		{Path: "/api/cicd-crons/{cronID}/actions/history", Method: http.MethodGet, Handler: e.pipelineCronHistory},
 		{Path: "/api/cicd-crons", Method: http.MethodPost, Handler: e.pipelineCronCreate},
 		// eventBox call back only support post method
 		{Path: "/api/cicd-crons/actions/hook-for-update", Method: http.MethodPost, Handler: e.pipelineCronUpdate},
@@ -228,6 +254,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/cicds/configs", Method: http.MethodPost, Handler: e.createOrUpdateCmsNsConfigs},
 		{Path: "/api/cicds/configs", Method: http.MethodDelete, Handler: e.deleteCmsNsConfigs},
 		{Path: "/api/cicds/multinamespace/configs", Method: http.MethodPost, Handler: e.getCmsNsConfigs},
+		{Path: "/api/cicds/configs/actions/diff", Method: http.MethodPost, Handler: e.diffCmsNsConfigs},
 		{Path: "/api/cicds/actions/fetch-config-namespaces", Method: http.MethodGet, Handler: e.getConfigNamespaces},
 		{Path: "/api/cicds/actions/list-workspaces", Method: http.MethodGet, Handler: e.listConfigWorkspaces},
 
@@ -237,6 +264,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/cicd-pipeline/filetree/actions/get-inode-by-pipeline", Method: http.MethodGet, Handler: e.GetGittarFileByPipelineId},
 		{Path: "/api/cicd-pipeline/filetree", Method: http.MethodPost, Handler: e.CreateGittarFileTreeNode},
 		{Path: "/api/cicd-pipeline/filetree/{inode}", Method: http.MethodDelete, Handler: e.DeleteGittarFileTreeNode},
+		{Path: "/api/cicd-pipeline/filetree/{inode}/actions/move", Method: http.MethodPost, Handler: e.MoveGittarFileTreeNode},
+		{Path: "/api/cicd-pipeline/filetree/{inode}/actions/save-pipeline", Method: http.MethodPost, Handler: e.SaveGittarFileTreeNodePipeline},
 		{Path: "/api/cicd-pipeline/filetree", Method: http.MethodGet, Handler: e.ListGittarFileTreeNodes},
 		{Path: "/api/cicd-pipeline/filetree/{inode}", Method: http.MethodGet, Handler: e.GetGittarFileTreeNode},
 		{Path: "/api/cicd-pipeline/filetree/actions/fuzzy-search", Method: http.MethodGet, Handler: e.FuzzySearchGittarFileTreeNodes},
@@ -248,6 +277,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		// sonar issues.
 		{Path: "/api/qa/actions/sonar-results-store", Method: http.MethodPost, Handler: e.SonarIssuesStore},
 		{Path: "/api/qa", Method: http.MethodGet, Handler: e.SonarIssues},
+		{Path: "/api/qa/actions/sonar-trend", Method: http.MethodGet, Handler: e.SonarIssueTrend},
 
 		// sonar metric key
 		{Path: "/api/sonar-metric-rules", Method: http.MethodGet, Handler: e.PagingSonarMetricRules},
@@ -258,6 +288,8 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/sonar-metric-rules/{id}", Method: http.MethodDelete, Handler: e.DeleteSonarMetricRules},
 		{Path: "/api/sonar-metric-rules/actions/query-metric-definition", Method: http.MethodGet, Handler: e.QuerySonarMetricRulesDefinition},
 		{Path: "/api/sonar-metric-rules/actions/query-list", Method: http.MethodGet, Handler: e.QuerySonarMetricRules},
+		{Path: "/api/sonar-metric-rules/actions/export", Method: http.MethodGet, Handler: e.ExportSonarMetricRules},
+		{Path: "/api/sonar-metric-rules/actions/import", Method: http.MethodPost, Handler: e.ImportSonarMetricRules},
 
 		// test platform
 		{Path: "/api/qa/actions/all-test-type", Method: http.MethodGet, Handler: e.GetTestTypes},
@@ -293,8 +325,22 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/testcases/actions/batch-create", Method: http.MethodPost, Handler: e.BatchCreateTestCases},
 		{Path: "/api/testcases", Method: http.MethodGet, Handler: e.PagingTestCases},
 		{Path: "/api/testcases/{testCaseID}", Method: http.MethodPut, Handler: e.UpdateTestCase},
+		{Path: "/api/testcases/{testCaseID}/actions/reorder-steps", Method: http.MethodPost, Handler: e.ReorderTestCaseSteps},
+		{Path: "/api/testcases/{testCaseID}/histories", Method: http.MethodGet, Handler: e.GetTestCaseHistory},
+		{Path: "/api/testcases/{testCaseID}/histories/{version}", Method: http.MethodGet, Handler: e.GetTestCaseHistoryVersion},
+		{Path: "/api/testcases/{testCaseID}/histories/{version}/actions/restore", Method: http.MethodPost, Handler: e.RestoreTestCaseHistory},
+		{Path: "/api/testcases/{testCaseID}/attachments", Method: http.MethodPost, Handler: e.CreateTestCaseAttachment},
+		{Path: "/api/testcases/{testCaseID}/attachments", Method: http.MethodGet, Handler: e.ListTestCaseAttachments},
+		{Path: "/api/testcases/{testCaseID}/attachments/{attachmentID}", Method: http.MethodDelete, Handler: e.DeleteTestCaseAttachment},
+		{Path: "/api/testcases/{testCaseID}/actions/submit-review", Method: http.MethodPost, Handler: e.SubmitTestCaseReview},
+		{Path: "/api/testcases/{testCaseID}/actions/approve", Method: http.MethodPost, Handler: e.ApproveTestCase},
+		{Path: "/api/testcases/{testCaseID}/actions/reject", Method: http.MethodPost, Handler: e.RejectTestCase},
+		{Path: "/api/testcases/{testCaseID}/reviews", Method: http.MethodGet, Handler: e.ListTestCaseReviews},
+		{Path: "/api/testcases/{testCaseID}/audit-logs", Method: http.MethodGet, Handler: e.ListTestCaseAuditLogs},
 		{Path: "/api/testcases/actions/batch-update", Method: http.MethodPost, Handler: e.BatchUpdateTestCases},
 		{Path: "/api/testcases/actions/batch-copy", Method: http.MethodPost, Handler: e.BatchCopyTestCases},
+		{Path: "/api/testcases/actions/search", Method: http.MethodGet, Handler: e.SearchTestCases},
+		{Path: "/api/testcases/actions/detect-duplicates", Method: http.MethodGet, Handler: e.DetectDuplicateTestCases},
 		{Path: "/api/testcases/actions/batch-clean-from-recycle-bin", Method: http.MethodDelete, Handler: e.BatchCleanTestCasesFromRecycleBin},
 		{Path: "/api/testcases/actions/export", Method: http.MethodGet, Handler: e.ExportTestCases},
 		{Path: "/api/testcases/actions/import", Method: http.MethodPost, Handler: e.ImportTestCases},
@@ -305,9 +351,17 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/testsets/{testSetID}", Method: http.MethodGet, Handler: e.GetTestSet},
 		{Path: "/api/testsets/{testSetID}", Method: http.MethodPut, Handler: e.UpdateTestSet},
 		{Path: "/api/testsets/{testSetID}/actions/copy", Method: http.MethodPost, Handler: e.CopyTestSet},
+		{Path: "/api/testsets/{testSetID}/actions/move", Method: http.MethodPost, Handler: e.MoveTestSet},
+		{Path: "/api/testsets/{testSetID}/actions/merge", Method: http.MethodPost, Handler: e.MergeTestSet},
 		{Path: "/api/testsets/{testSetID}/actions/recycle", Method: http.MethodPost, Handler: e.RecycleTestSet},
 		{Path: "/api/testsets/{testSetID}/actions/clean-from-recycle-bin", Method: http.MethodDelete, Handler: e.CleanTestSetFromRecycleBin},
 		{Path: "/api/testsets/{testSetID}/actions/recover-from-recycle-bin", Method: http.MethodPost, Handler: e.RecoverTestSetFromRecycleBin},
+		{Path: "/api/testsets/{testSetID}/permissions", Method: http.MethodGet, Handler: e.ListTestSetPermissions},
+		{Path: "/api/testsets/{testSetID}/permissions", Method: http.MethodPut, Handler: e.GrantTestSetPermission},
+		{Path: "/api/testsets/{testSetID}/permissions/{userID}", Method: http.MethodDelete, Handler: e.RevokeTestSetPermission},
+		{Path: "/api/testsets/{testSetID}/actions/effective-permission", Method: http.MethodGet, Handler: e.GetTestSetEffectivePermission},
+		{Path: "/api/projects/{projectID}/test-recycle-bin-policy", Method: http.MethodGet, Handler: e.GetTestRecycleBinPolicy},
+		{Path: "/api/projects/{projectID}/test-recycle-bin-policy", Method: http.MethodPut, Handler: e.UpdateTestRecycleBinPolicy},
 
 		// 测试计划
 		{Path: "/api/testplans", Method: http.MethodPost, Handler: e.CreateTestPlan},
@@ -315,6 +369,10 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/testplans/{testPlanID}", Method: http.MethodGet, Handler: e.GetTestPlan},
 		{Path: "/api/testplans/{testPlanID}", Method: http.MethodPut, Handler: e.UpdateTestPlan},
 		{Path: "/api/testplans/{testPlanID}", Method: http.MethodDelete, Handler: e.DeleteTestPlan},
+		{Path: "/api/testplans/{testPlanID}/members", Method: http.MethodGet, Handler: e.ListTestPlanMembers},
+		{Path: "/api/testplans/{testPlanID}/members", Method: http.MethodPost, Handler: e.CreateTestPlanMember},
+		{Path: "/api/testplans/{testPlanID}/members/{userID}", Method: http.MethodPut, Handler: e.UpdateTestPlanMember},
+		{Path: "/api/testplans/{testPlanID}/members/{userID}", Method: http.MethodDelete, Handler: e.DeleteTestPlanMember},
 		{Path: "/api/testplans/{testPlanID}/testcase-relations", Method: http.MethodPost, Handler: e.CreateTestPlanCaseRelations},
 		{Path: "/api/testplans/{testPlanID}/testcase-relations", Method: http.MethodGet, Handler: e.PagingTestPlanCaseRelations},
 		{Path: "/api/testplans/testcase-relations/actions/internal-list", Method: http.MethodGet, Handler: e.InternalListTestPlanCaseRels},
@@ -322,12 +380,19 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/testplans/{testPlanID}/testcase-relations/{relationID}/actions/add-issue-relations", Method: http.MethodPost, Handler: e.AddTestPlanCaseRelIssueRelations},
 		{Path: "/api/testplans/{testPlanID}/testcase-relations/{relationID}/actions/remove-issue-relations", Method: http.MethodPost, Handler: e.RemoveTestPlanCaseRelIssueRelations},
 		{Path: "/api/testplans/testcase-relations/actions/internal-remove-issue-relations", Method: http.MethodDelete, Handler: e.InternalRemoveTestPlanCaseRelIssueRelations},
+		{Path: "/api/testplans/{testPlanID}/testcase-relations/{relationID}/attachments", Method: http.MethodPost, Handler: e.CreateTestCaseAttachment},
+		{Path: "/api/testplans/{testPlanID}/testcase-relations/{relationID}/attachments", Method: http.MethodGet, Handler: e.ListTestCaseAttachments},
+		{Path: "/api/testplans/{testPlanID}/testcase-relations/{relationID}/attachments/{attachmentID}", Method: http.MethodDelete, Handler: e.DeleteTestCaseAttachment},
 		{Path: "/api/testplans/{testPlanID}/testcase-relations/actions/batch-update", Method: http.MethodPost, Handler: e.BatchUpdateTestPlanCaseRelations},
 		{Path: "/api/testplans/{testPlanID}/actions/execute-apitest", Method: http.MethodPost, Handler: e.ExecuteTestPlanAPITest},
+		{Path: "/api/testplans/{testPlanID}/actions/rerun-failed-apitest", Method: http.MethodPost, Handler: e.RerunFailedTestPlanAPITest},
+		{Path: "/api/testplans/{testPlanID}/actions/compare-apitest", Method: http.MethodGet, Handler: e.CompareTestPlanAPITests},
 		{Path: "/api/testplans/{testPlanID}/actions/cancel-apitest/{pipelineID}", Method: http.MethodPost, Handler: e.CancelApiTestPipeline},
 		{Path: "/api/testplans/{testPlanID}/actions/export", Method: http.MethodGet, WriterHandler: e.ExportTestPlanCaseRels},
 		{Path: "/api/testplans/{testPlanID}/testsets", Method: http.MethodGet, Handler: e.ListTestPlanTestSets},
 		{Path: "/api/testplans/{testPlanID}/actions/generate-report", Method: http.MethodGet, Handler: e.GenerateTestPlanReport},
+		{Path: "/api/testplans/{testPlanID}/burndown", Method: http.MethodGet, Handler: e.GetTestPlanBurndown},
+		{Path: "/api/testplans/{testPlanID}/exec-durations", Method: http.MethodGet, Handler: e.GetTestPlanExecDurations},
 
 		// 自动化测试 - 测试集
 		{Path: "/api/autotests/filetree", Method: http.MethodPost, Handler: e.CreateAutoTestFileTreeNode},
@@ -386,6 +451,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/autotests/scenes-step/{stepID}", Method: http.MethodPut, Handler: e.UpdateAutoTestSceneStep},
 		{Path: "/api/autotests/scenes-step/actions/move", Method: http.MethodPut, Handler: e.MoveAutoTestSceneStep},
 		{Path: "/api/autotests/scenes/{sceneID}/actions/get-step", Method: http.MethodGet, Handler: e.ListAutoTestSceneStep},
+		{Path: "/api/autotests/scenes/{sceneID}/actions/get-step-graph", Method: http.MethodGet, Handler: e.GetAutoTestSceneStepGraph},
 		{Path: "/api/autotests/scenes-step-output", Method: http.MethodGet, Handler: e.ListAutoTestSceneStepOutPut},
 		{Path: "/api/autotests/scenes-step/{stepID}", Method: http.MethodGet, Handler: e.GetAutoTestSceneStep},
 
@@ -406,6 +472,35 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		// 场景 执行取消
 		{Path: "/api/autotests/scenes-step/{stepID}/actions/execute", Method: http.MethodPost, Handler: e.ExecuteDiceAutotestSceneStep},
 		{Path: "/api/autotests/scenes/{sceneID}/actions/execute", Method: http.MethodPost, Handler: e.ExecuteDiceAutotestScene},
+		{Path: "/api/autotests/scenes/{sceneID}/executions", Method: http.MethodGet, Handler: e.ListAutoTestSceneExecutions},
+		{Path: "/api/autotests/scenes/executions/{executionID}", Method: http.MethodGet, Handler: e.GetAutoTestSceneExecution},
+		{Path: "/api/autotests/scenes/{sceneID}/baseline", Method: http.MethodGet, Handler: e.GetAutoTestSceneBaseline},
+		{Path: "/api/autotests/scenes/{sceneID}/baseline", Method: http.MethodPut, Handler: e.SaveAutoTestSceneBaseline},
+		{Path: "/api/autotests/scenes/{sceneID}/baseline", Method: http.MethodDelete, Handler: e.DeleteAutoTestSceneBaseline},
+		{Path: "/api/autotests/schedules", Method: http.MethodPost, Handler: e.CreateAutoTestSchedule},
+		{Path: "/api/autotests/schedules", Method: http.MethodGet, Handler: e.ListAutoTestSchedules},
+		{Path: "/api/autotests/schedules/{scheduleID}", Method: http.MethodPut, Handler: e.UpdateAutoTestSchedule},
+		{Path: "/api/autotests/schedules/{scheduleID}", Method: http.MethodDelete, Handler: e.DeleteAutoTestSchedule},
+		{Path: "/api/autotests/schedules/{scheduleID}/records", Method: http.MethodGet, Handler: e.ListAutoTestScheduleRecords},
+		{Path: "/api/autotests/schedules/{scheduleID}/upcoming", Method: http.MethodGet, Handler: e.ListAutoTestScheduleUpcoming},
+		{Path: "/api/autotests/scene-webhooks", Method: http.MethodPost, Handler: e.CreateAutoTestSceneWebhook},
+		{Path: "/api/autotests/scene-webhooks", Method: http.MethodGet, Handler: e.ListAutoTestSceneWebhooks},
+		{Path: "/api/autotests/scene-webhooks/{webhookID}", Method: http.MethodPut, Handler: e.UpdateAutoTestSceneWebhook},
+		{Path: "/api/autotests/scene-webhooks/{webhookID}", Method: http.MethodDelete, Handler: e.DeleteAutoTestSceneWebhook},
+		{Path: "/api/autotests/scene-webhooks/{webhookID}/deliveries", Method: http.MethodGet, Handler: e.ListAutoTestSceneWebhookDeliveries},
+		{Path: "/api/autotests/scene-templates", Method: http.MethodPost, Handler: e.CreateAutoTestSceneTemplate},
+		{Path: "/api/autotests/scene-templates", Method: http.MethodGet, Handler: e.ListAutoTestSceneTemplates},
+		{Path: "/api/autotests/scene-templates/{templateID}", Method: http.MethodGet, Handler: e.GetAutoTestSceneTemplate},
+		{Path: "/api/autotests/scene-templates/{templateID}", Method: http.MethodPut, Handler: e.UpdateAutoTestSceneTemplate},
+		{Path: "/api/autotests/scene-templates/{templateID}", Method: http.MethodDelete, Handler: e.DeleteAutoTestSceneTemplate},
+		{Path: "/api/autotests/scene-templates/{templateID}/actions/instantiate", Method: http.MethodPost, Handler: e.InstantiateAutoTestSceneTemplate},
+		{Path: "/api/autotests/scenes/{sceneID}/actions/update-from-template", Method: http.MethodPost, Handler: e.UpdateAutoTestSceneFromTemplate},
+		{Path: "/api/autotests/scenes/{sceneID}/data-files", Method: http.MethodPost, Handler: e.CreateAutoTestSceneDataFile},
+		{Path: "/api/autotests/scenes/{sceneID}/data-files", Method: http.MethodGet, Handler: e.ListAutoTestSceneDataFiles},
+		{Path: "/api/autotests/scene-data-files/{fileID}", Method: http.MethodDelete, Handler: e.DeleteAutoTestSceneDataFile},
+		{Path: "/api/autotests/scenes/{sceneID}/versions", Method: http.MethodGet, Handler: e.ListAutoTestSceneVersions},
+		{Path: "/api/autotests/scenes/{sceneID}/versions/{version}", Method: http.MethodGet, Handler: e.GetAutoTestSceneVersion},
+		{Path: "/api/autotests/scenes/{sceneID}/versions/{version}/actions/restore", Method: http.MethodPost, Handler: e.RestoreAutoTestSceneVersion},
 		{Path: "/api/autotests/scenes/{sceneID}/actions/cancel", Method: http.MethodPost, Handler: e.CancelDiceAutotestScene},
 
 		// 计划 执行取消
@@ -417,6 +512,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 
 		//场景集
 		{Path: "/api/autotests/scenesets/{setID}", Method: http.MethodGet, Handler: e.GetSceneSet},
+		{Path: "/api/autotests/scenesets/{setID}/actions/execution-status", Method: http.MethodGet, Handler: e.GetSceneSetExecutionStatus},
 		{Path: "/api/autotests/scenesets", Method: http.MethodGet, Handler: e.GetSceneSets},
 		{Path: "/api/autotests/scenesets", Method: http.MethodPost, Handler: e.CreateSceneSet},
 		{Path: "/api/autotests/scenesets/{setID}", Method: http.MethodPut, Handler: e.UpdateSceneSet},
@@ -461,6 +557,9 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/config", Method: http.MethodDelete, Handler: e.DeleteConfig},
 		{Path: "/api/config/actions/export", Method: http.MethodGet, Handler: e.ExportConfigs},
 		{Path: "/api/config/actions/import", Method: http.MethodPost, Handler: e.ImportConfigs},
+		{Path: "/api/config/actions/encrypt", Method: http.MethodPost, Handler: e.EncryptConfigs},
+		{Path: "/api/config/actions/bulk-export", Method: http.MethodGet, Handler: e.BulkExportConfigs},
+		{Path: "/api/config/actions/bulk-import", Method: http.MethodPost, Handler: e.BulkImportConfigs},
 		{Path: "/api/config/deployment", Method: http.MethodGet, Handler: e.GetDeployConfigs},
 		//{"/api/configmanage/configs/publish",Method:http.MethodPost,Handler: e.PublishConfig},
 		//{"/api/configmanage/configs/publish/all",Method:http.MethodPost,Handler: e.PublishConfigs},
@@ -480,6 +579,7 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/issues/actions/man-hour", Method: http.MethodGet, Handler: e.GetIssueManHourSum},
 		{Path: "/api/issues/actions/bug-percentage", Method: http.MethodGet, Handler: e.GetIssueBugPercentage},
 		{Path: "/api/issues/actions/bug-status-percentage", Method: http.MethodGet, Handler: e.GetIssueBugStatusPercentage},
+		{Path: "/api/issues/actions/requirement-coverage", Method: http.MethodGet, Handler: e.GetRequirementCoverage},
 		{Path: "/api/issues/actions/bug-severity-percentage", Method: http.MethodGet, Handler: e.GetIssueBugSeverityPercentage},
 		{Path: "/api/issues/{id}/streams", Method: http.MethodPost, Handler: e.CreateCommentIssueStream},
 		{Path: "/api/issues/{id}/streams", Method: http.MethodGet, Handler: e.PagingIssueStreams},
@@ -992,10 +1092,19 @@ func init() {
 	queryStringDecoder.IgnoreUnknownKeys(true)
 }
 
+// AssetService 返回 API 集市 service
+func (e *Endpoints) AssetService() *assetsvc.Service {
+	return e.assetSvc
+}
+
 func (e *Endpoints) TestCaseService() *testcase.Service {
 	return e.testcase
 }
 
+func (e *Endpoints) AutotestService() *autotest.Service {
+	return e.autotest
+}
+
 func (e *Endpoints) AutotestV2Service() *atv2.Service {
 	return e.autotestV2
 }
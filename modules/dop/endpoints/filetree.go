@@ -437,6 +437,69 @@ func (e *Endpoints) DeleteGittarFileTreeNode(ctx context.Context, r *http.Reques
 	return httpserver.OkResp(unifiedNode)
 }
 
+func (e *Endpoints) MoveGittarFileTreeNode(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrMoveGittarFileTreeNode.NotLogin().ToResp(), nil
+	}
+
+	// 校验 body 合法性
+	if r.ContentLength == 0 {
+		return apierrors.ErrMoveGittarFileTreeNode.InvalidParameter("missing request body").ToResp(), nil
+	}
+	var req apistructs.UnifiedFileTreeNodeMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrMoveGittarFileTreeNode.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+	req.Inode = vars["inode"]
+
+	// 获取企业id
+	orgID, err := getOrgId(r)
+	if err != nil {
+		return apierrors.ErrMoveGittarFileTreeNode.MissingParameter("org id").ToResp(), nil
+	}
+
+	// 源目录树和目标目录树的写权限在 service 中校验
+	node, err := e.fileTree.MoveFileTreeNode(req, orgID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(node)
+}
+
+func (e *Endpoints) SaveGittarFileTreeNodePipeline(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrSaveGittarFileTreeNodePipeline.NotLogin().ToResp(), nil
+	}
+
+	// 校验 body 合法性
+	if r.ContentLength == 0 {
+		return apierrors.ErrSaveGittarFileTreeNodePipeline.InvalidParameter("missing request body").ToResp(), nil
+	}
+	var req apistructs.UnifiedFileTreeNodeSavePipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrSaveGittarFileTreeNodePipeline.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+	req.Inode = vars["inode"]
+
+	// 获取企业id
+	orgID, err := getOrgId(r)
+	if err != nil {
+		return apierrors.ErrSaveGittarFileTreeNodePipeline.MissingParameter("org id").ToResp(), nil
+	}
+
+	node, err := e.fileTree.SaveFileTreeNodePipeline(req, orgID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(node)
+}
+
 func (e *Endpoints) FindGittarFileTreeNodeAncestors(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identityInfo, err := user.GetIdentityInfo(r)
 	if err != nil {
@@ -59,6 +59,35 @@ func (e *Endpoints) SearchOperations(ctx context.Context, r *http.Request, vars
 	return httpserver.OkResp(data)
 }
 
+// 在 API 资料及其接口中统一搜索
+func (e *Endpoints) SearchAPIAssets(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identity, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.SearchAPIAssets.NotLogin().ToResp(), nil
+	}
+	orgID, err := user.GetOrgID(r)
+	if err != nil {
+		return apierrors.SearchAPIAssets.MissingParameter(apierrors.MissingOrgID).ToResp(), nil
+	}
+
+	var params apistructs.SearchAPIAssetsQueryParams
+	if err = e.queryStringDecoder.Decode(&params, r.URL.Query()); err != nil {
+		return apierrors.SearchAPIAssets.InvalidParameter("invalid query parameters").ToResp(), nil
+	}
+
+	var req = apistructs.SearchAPIAssetsReq{
+		OrgID:       orgID,
+		Identity:    &identity,
+		QueryParams: params,
+	}
+	data, apiError := e.assetSvc.SearchAPIAssets(&req)
+	if apiError != nil {
+		return apiError.ToResp(), nil
+	}
+
+	return httpserver.OkResp(data)
+}
+
 // 查询文档中的接口详情
 func (e *Endpoints) GetOperation(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identity, err := user.GetIdentityInfo(r)
@@ -114,6 +114,19 @@ func (e *Endpoints) pipelineCreate(ctx context.Context, r *http.Request, vars ma
 		reqPipeline.PipelineYml = string(convertedyml)
 	}
 
+	// dry run 只返回预览，不创建也不执行
+	if createReq.DryRun {
+		result, err := e.pipeline.DryRunPipelineV2(reqPipeline)
+		if err != nil {
+			return errorresp.ErrResp(err)
+		}
+		result.BranchWorkspaces, err = e.branchRule.GetAllValidBranchWorkspaces(int64(app.ID))
+		if err != nil {
+			return apierrors.ErrGetBranchWorkspaceMap.InternalError(err).ToResp(), nil
+		}
+		return httpserver.OkResp(result)
+	}
+
 	resp, err := e.pipeline.CreatePipelineV2(reqPipeline)
 	if err != nil {
 		logrus.Errorf("create pipeline failed, reqPipeline: %+v, (%+v)", reqPipeline, err)
@@ -365,10 +378,16 @@ func (e *Endpoints) pipelineRun(ctx context.Context, r *http.Request, vars map[s
 		return errorresp.ErrResp(err)
 	}
 
+	// 使用指定的运行参数预设，未指定且未传参数时使用默认预设
+	runParams, err := e.pipeline.ApplyParamPreset(&p.PipelineDTO, runRequest.PresetID, runRequest.PipelineRunParams)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
 	if err = e.bdl.RunPipeline(apistructs.PipelineRunRequest{
 		PipelineID:        pipelineID,
 		IdentityInfo:      identityInfo,
-		PipelineRunParams: runRequest.PipelineRunParams,
+		PipelineRunParams: runParams,
 	}); err != nil {
 		var apiError, ok = err.(*errorresp.APIError)
 		if !ok {
@@ -440,6 +459,34 @@ func (e *Endpoints) pipelineCancel(ctx context.Context, r *http.Request, vars ma
 	return httpserver.OkResp(nil)
 }
 
+// pipelineBatchCancel 按应用、分支、状态批量取消流水线
+func (e *Endpoints) pipelineBatchCancel(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	var req apistructs.PipelineBatchCancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCancelPipeline.InvalidParameter(err).ToResp(), nil
+	}
+	if req.AppID == 0 {
+		return apierrors.ErrCancelPipeline.MissingParameter("appID").ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	result, err := e.pipeline.BatchCancelPipelines(req, func(branch string) error {
+		return e.permission.CheckRuntimeBranch(identityInfo, req.AppID, branch, apistructs.OperateAction)
+	})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
 // pipelineRerun 重跑整个 pipeline，相当于一个全新的 pipeline，不需要注入上一次的上下文。
 func (e *Endpoints) pipelineRerun(ctx context.Context, r *http.Request, vars map[string]string) (
 	httpserver.Responser, error) {
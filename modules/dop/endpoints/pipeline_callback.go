@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 
@@ -48,3 +49,57 @@ func (e *Endpoints) CDPCallback(ctx context.Context, r *http.Request, vars map[s
 	}()
 	return httpserver.OkResp(runningTaskID)
 }
+
+// AutotestCallback 自动化测试场景流水线结束后保存执行结果
+func (e *Endpoints) AutotestCallback(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.PipelineInstanceEvent
+	if r.Body == nil {
+		return apierrors.ErrDealAutotestCallback.MissingParameter("body").ToResp(), nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrDealAutotestCallback.InvalidParameter(err).ToResp(), nil
+	}
+	if req.Content.Source != apistructs.PipelineSourceAutoTest.String() ||
+		!apistructs.PipelineStatus(req.Content.Status).IsEndStatus() {
+		return httpserver.OkResp(nil)
+	}
+
+	go func() {
+		if err := e.autotestV2.FinishAutoTestSceneExecution(req.Content.PipelineID); err != nil {
+			logrus.Errorf("failed to save autotest scene execution, pipelineID: %d, err: %v", req.Content.PipelineID, err)
+		}
+	}()
+	return httpserver.OkResp(nil)
+}
+
+// APITestCallback 测试计划接口测试流水线结束后, 合并重跑结果、记录用例执行耗时并按配置邮件发送测试报告
+func (e *Endpoints) APITestCallback(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.PipelineInstanceEvent
+	if r.Body == nil {
+		return apierrors.ErrDealAPITestCallback.MissingParameter("body").ToResp(), nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrDealAPITestCallback.InvalidParameter(err).ToResp(), nil
+	}
+	if req.Content.Source != apistructs.PipelineSourceAPITest.String() ||
+		!apistructs.PipelineStatus(req.Content.Status).IsEndStatus() {
+		return httpserver.OkResp(nil)
+	}
+	testPlanID, err := strconv.ParseUint(req.Content.Labels[apistructs.LabelTestPlanID], 10, 64)
+	if err != nil {
+		return httpserver.OkResp(nil)
+	}
+
+	go func() {
+		if err := e.testPlan.MergeRerunAPITestResult(req.Content.PipelineID, req.Content.UserID, req.Content.Labels); err != nil {
+			logrus.Errorf("failed to merge rerun api test result, pipelineID: %d, err: %v", req.Content.PipelineID, err)
+		}
+		if err := e.testPlan.RecordAPITestDurations(testPlanID, req.Content.PipelineID, req.Content.UserID); err != nil {
+			logrus.Errorf("failed to record api test durations of test plan %d, pipelineID: %d, err: %v", testPlanID, req.Content.PipelineID, err)
+		}
+		if err := e.testPlan.SendReportEmail(testPlanID, req.Content.PipelineID); err != nil {
+			logrus.Errorf("failed to send report email of test plan %d, pipelineID: %d, err: %v", testPlanID, req.Content.PipelineID, err)
+		}
+	}()
+	return httpserver.OkResp(nil)
+}
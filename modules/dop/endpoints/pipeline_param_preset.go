@@ -0,0 +1,155 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// createPipelineParamPreset 创建流水线运行参数预设
+func (e *Endpoints) createPipelineParamPreset(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetUser.InvalidParameter(err).ToResp(), nil
+	}
+
+	var req apistructs.PipelineParamPresetCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateParamPreset.InvalidParameter(err).ToResp(), nil
+	}
+	if req.AppID == 0 {
+		return apierrors.ErrCreateParamPreset.MissingParameter("appID").ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	if err := e.permission.CheckRuntimeBranch(identityInfo, req.AppID, req.Branch, apistructs.OperateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	preset, err := e.pipeline.CreateParamPreset(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(preset)
+}
+
+// updatePipelineParamPreset 更新流水线运行参数预设
+func (e *Endpoints) updatePipelineParamPreset(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetUser.InvalidParameter(err).ToResp(), nil
+	}
+
+	presetID, err := strconv.ParseUint(vars["presetID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateParamPreset.InvalidParameter(err).ToResp(), nil
+	}
+	var req apistructs.PipelineParamPresetUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateParamPreset.InvalidParameter(err).ToResp(), nil
+	}
+	req.ID = presetID
+	req.IdentityInfo = identityInfo
+
+	preset, err := e.pipeline.GetParamPreset(presetID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.permission.CheckRuntimeBranch(identityInfo, preset.AppID, preset.Branch, apistructs.OperateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	updated, err := e.pipeline.UpdateParamPreset(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(updated)
+}
+
+// deletePipelineParamPreset 删除流水线运行参数预设
+func (e *Endpoints) deletePipelineParamPreset(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetUser.InvalidParameter(err).ToResp(), nil
+	}
+
+	presetID, err := strconv.ParseUint(vars["presetID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteParamPreset.InvalidParameter(err).ToResp(), nil
+	}
+
+	preset, err := e.pipeline.GetParamPreset(presetID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.permission.CheckRuntimeBranch(identityInfo, preset.AppID, preset.Branch, apistructs.OperateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.pipeline.DeleteParamPreset(presetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(presetID)
+}
+
+// listPipelineParamPresets 查询流水线定义下的运行参数预设
+func (e *Endpoints) listPipelineParamPresets(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetUser.InvalidParameter(err).ToResp(), nil
+	}
+
+	var req apistructs.PipelineParamPresetListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListParamPreset.InvalidParameter(err).ToResp(), nil
+	}
+	if req.AppID == 0 {
+		return apierrors.ErrListParamPreset.MissingParameter("appID").ToResp(), nil
+	}
+	if req.Branch == "" {
+		return apierrors.ErrListParamPreset.MissingParameter("branch").ToResp(), nil
+	}
+
+	if err := e.permission.CheckAppAction(identityInfo, req.AppID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	presets, err := e.pipeline.ListParamPresets(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(presets)
+}
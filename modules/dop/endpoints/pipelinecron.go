@@ -107,6 +107,81 @@ func (e *Endpoints) pipelineCronStop(ctx context.Context, r *http.Request, vars
 	return httpserver.OkResp(cron)
 }
 
+// pipelineCronNextFireTime 查询定时流水线后续的触发时间
+func (e *Endpoints) pipelineCronNextFireTime(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	cronID, err := strconv.ParseUint(vars[pathCronID], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetCronFireTime.InvalidParameter(err).ToResp(), nil
+	}
+	var count int
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return apierrors.ErrGetCronFireTime.InvalidParameter(err).ToResp(), nil
+		}
+	}
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetUser.InvalidParameter(err).ToResp(), nil
+	}
+
+	// get cron info for check permission
+	cronInfo, err := e.bdl.GetPipelineCron(cronID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.permission.CheckAppAction(identityInfo, cronInfo.ApplicationID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	fireTime, err := e.pipeline.GetCronNextFireTime(cronID, count)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(fireTime)
+}
+
+// pipelineCronHistory 分页查询定时触发的流水线及其执行结果
+func (e *Endpoints) pipelineCronHistory(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	cronID, err := strconv.ParseUint(vars[pathCronID], 10, 64)
+	if err != nil {
+		return apierrors.ErrListCronHistory.InvalidParameter(err).ToResp(), nil
+	}
+	var req apistructs.PipelineCronHistoryRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListCronHistory.InvalidParameter(err).ToResp(), nil
+	}
+	req.CronID = cronID
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetUser.InvalidParameter(err).ToResp(), nil
+	}
+
+	// get cron info for check permission
+	cronInfo, err := e.bdl.GetPipelineCron(cronID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.permission.CheckAppAction(identityInfo, cronInfo.ApplicationID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	history, err := e.pipeline.ListCronHistory(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(history)
+}
+
 // pipelineCronCreate accept
 func (e *Endpoints) pipelineCronCreate(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 
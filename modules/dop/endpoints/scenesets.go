@@ -231,3 +231,29 @@ func (e *Endpoints) CopySceneSet(ctx context.Context, r *http.Request, vars map[
 	}
 	return httpserver.OkResp(setId)
 }
+
+// GetSceneSetExecutionStatus 获取场景集所在集群中场景集执行的并发情况, 包含正在执行和排队的数量
+func (e *Endpoints) GetSceneSetExecutionStatus(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	setID, err := strconv.ParseUint(vars["setID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneSet.InvalidParameter(err).ToResp(), nil
+	}
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetAutoTestSceneSet.NotLogin().ToResp(), nil
+	}
+
+	sceneSet, err := e.autotestV2.GetSceneSet(setID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkAutoTestSpacePermission(identityInfo, sceneSet.SpaceID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	status, err := e.autotestV2.GetSceneSetExecutionStatus(setID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return httpserver.OkResp(status)
+}
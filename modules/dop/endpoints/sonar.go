@@ -113,6 +113,19 @@ func (e *Endpoints) SonarIssuesStore(ctx context.Context, r *http.Request, vars
 		return apierrors.ErrStoreSonarIssue.InternalError(err).ToResp(), nil
 	}
 
+	// 质量门禁：结果已保存，违反阻断规则时返回错误使流水线任务失败
+	gate, err := e.sonarMetricRule.EvaluateQualityGate(&req)
+	if err != nil {
+		logrus.Warningf("failed to evaluate sonar quality gate, key: %s, err: %v", req.Key, err)
+		return httpserver.OkResp(resp)
+	}
+	if err := e.sonarMetricRule.PushQualityGateLog(req.LogID, gate); err != nil {
+		logrus.Warningf("failed to push sonar quality gate log, logID: %s, err: %v", req.LogID, err)
+	}
+	if !gate.Passed {
+		return apierrors.ErrSonarQualityGateFailed.InvalidState(gate.BlockingViolationsMessage()).ToResp(), nil
+	}
+
 	return httpserver.OkResp(resp)
 }
 
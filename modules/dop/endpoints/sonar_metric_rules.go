@@ -24,6 +24,7 @@ import (
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 )
 
 func (e *Endpoints) PagingSonarMetricRules(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
@@ -212,6 +213,41 @@ func (e *Endpoints) QuerySonarMetricRulesDefinition(ctx context.Context, r *http
 	return resp, nil
 }
 
+func (e *Endpoints) ExportSonarMetricRules(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	request := apistructs.SonarMetricRulesExportRequest{}
+	if err := e.queryStringDecoder.Decode(&request, r.URL.Query()); err != nil {
+		return apierrors.ErrExportSonarMetricRules.InvalidParameter(err).ToResp(), nil
+	}
+	if err := checkScopeTypeAndID(request.ScopeType, request.ScopeID); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.sonarMetricRule.Export(&request)
+	if err != nil {
+		return apierrors.ErrExportSonarMetricRules.InternalError(err).ToResp(), nil
+	}
+	return resp, nil
+}
+
+func (e *Endpoints) ImportSonarMetricRules(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	request := apistructs.SonarMetricRulesImportRequest{}
+	if r.ContentLength == 0 {
+		return apierrors.ErrImportSonarMetricRules.InvalidParameter("missing request body").ToResp(), nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return apierrors.ErrImportSonarMetricRules.InvalidParameter(err).ToResp(), nil
+	}
+	if err := checkScopeTypeAndID(request.ScopeType, request.ScopeID); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.sonarMetricRule.Import(&request)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	return resp, nil
+}
+
 func checkScopeTypeAndID(scopeType, scopeID string) error {
 	if scopeType != apistructs.ProjectScopeType {
 		return fmt.Errorf("missing params scopeType")
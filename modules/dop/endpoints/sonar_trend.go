@@ -0,0 +1,196 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/database/cimysql"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+)
+
+const (
+	sonarTrendDefaultDays = 30
+	sonarTrendMaxBuckets  = 366
+)
+
+var errTooManySonarTrendBuckets = fmt.Errorf("time window is too large, at most %d buckets", sonarTrendMaxBuckets)
+
+func errInvalidSonarTrendBucket(bucket apistructs.SonarIssueTrendBucket) error {
+	return fmt.Errorf("invalid bucket: %s", bucket)
+}
+
+// SonarIssueTrend 按时间段统计应用的代码质量趋势
+func (e *Endpoints) SonarIssueTrend(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req apistructs.SonarIssueTrendRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrGetSonarIssueTrend.InvalidParameter(err).ToResp(), nil
+	}
+	if req.AppID == 0 {
+		return apierrors.ErrGetSonarIssueTrend.MissingParameter("applicationId").ToResp(), nil
+	}
+	if req.Bucket == "" {
+		req.Bucket = apistructs.SonarIssueTrendBucketDay
+	}
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.AddDate(0, 0, -sonarTrendDefaultDays)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	if !start.Before(end) {
+		return apierrors.ErrGetSonarIssueTrend.InvalidParameter("startTime must be before endTime").ToResp(), nil
+	}
+	buckets, err := sonarTrendBuckets(start, end, req.Bucket)
+	if err != nil {
+		return apierrors.ErrGetSonarIssueTrend.InvalidParameter(err).ToResp(), nil
+	}
+
+	// 先只查询时间，选出每个时间段内最后一次分析，避免加载所有分析的问题详情
+	var analyses []dbclient.QASonar
+	sql := cimysql.Engine.Cols("id", "updated_at").Where("app_id = ?", req.AppID).
+		And("updated_at >= ?", start).And("updated_at < ?", end)
+	if req.Branch != "" {
+		sql = sql.And("branch = ?", req.Branch)
+	}
+	if err := sql.Asc("updated_at").Find(&analyses); err != nil {
+		return apierrors.ErrGetSonarIssueTrend.InternalError(err).ToResp(), nil
+	}
+	latestIDs := latestSonarPerBucket(analyses, buckets)
+	if len(latestIDs) == 0 {
+		return httpserver.OkResp([]apistructs.SonarIssueTrendPoint{})
+	}
+
+	var ids []int64
+	for _, id := range latestIDs {
+		ids = append(ids, id)
+	}
+	var sonars []dbclient.QASonar
+	if err := cimysql.Engine.In("id", ids).Find(&sonars); err != nil {
+		return apierrors.ErrGetSonarIssueTrend.InternalError(err).ToResp(), nil
+	}
+	sonarMap := make(map[int64]dbclient.QASonar, len(sonars))
+	for _, sonar := range sonars {
+		sonarMap[sonar.ID] = sonar
+	}
+
+	points := make([]apistructs.SonarIssueTrendPoint, 0, len(latestIDs))
+	for _, bucket := range buckets {
+		id, ok := latestIDs[bucket]
+		if !ok {
+			continue
+		}
+		sonar, ok := sonarMap[id]
+		if !ok {
+			continue
+		}
+		points = append(points, convertSonarTrendPoint(bucket, sonar))
+	}
+
+	return httpserver.OkResp(points)
+}
+
+// sonarTrendBuckets 将时间窗口按粒度切分，返回各时间段的起始时间
+func sonarTrendBuckets(start, end time.Time, bucket apistructs.SonarIssueTrendBucket) ([]time.Time, error) {
+	var next func(time.Time) time.Time
+	switch bucket {
+	case apistructs.SonarIssueTrendBucketDay:
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case apistructs.SonarIssueTrendBucketWeek:
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		// 以周一作为一周的开始
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case apistructs.SonarIssueTrendBucketMonth:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, errInvalidSonarTrendBucket(bucket)
+	}
+	var buckets []time.Time
+	for t := start; t.Before(end); t = next(t) {
+		if len(buckets) >= sonarTrendMaxBuckets {
+			return nil, errTooManySonarTrendBuckets
+		}
+		buckets = append(buckets, t)
+	}
+	return buckets, nil
+}
+
+// latestSonarPerBucket 返回每个时间段内最后一次分析的 ID，analyses 需按时间升序
+func latestSonarPerBucket(analyses []dbclient.QASonar, buckets []time.Time) map[time.Time]int64 {
+	result := make(map[time.Time]int64)
+	i := 0
+	for _, analysis := range analyses {
+		for i+1 < len(buckets) && !analysis.UpdatedAt.Before(buckets[i+1]) {
+			i++
+		}
+		if len(buckets) == 0 || analysis.UpdatedAt.Before(buckets[i]) {
+			continue
+		}
+		result[buckets[i]] = analysis.ID
+	}
+	return result
+}
+
+func convertSonarTrendPoint(bucket time.Time, sonar dbclient.QASonar) apistructs.SonarIssueTrendPoint {
+	point := apistructs.SonarIssueTrendPoint{
+		Time:       bucket,
+		CommitID:   sonar.CommitID,
+		Branch:     sonar.Branch,
+		AnalyzedAt: sonar.UpdatedAt,
+		Severities: make(map[string]int),
+	}
+	var statistics apistructs.TestIssuesStatistics
+	if sonar.IssuesStatistics != "" {
+		if err := json.Unmarshal([]byte(sonar.IssuesStatistics), &statistics); err != nil {
+			logrus.Warningf("failed to unmarshal sonar issues statistics, id: %d, err: %v", sonar.ID, err)
+		}
+	}
+	point.Bugs, _ = strconv.Atoi(statistics.Bugs)
+	point.Vulnerabilities, _ = strconv.Atoi(statistics.Vulnerabilities)
+	point.CodeSmells, _ = strconv.Atoi(statistics.CodeSmells)
+	point.Coverage, _ = strconv.ParseFloat(strings.TrimSuffix(statistics.Coverage, "%"), 64)
+	point.Duplications, _ = strconv.ParseFloat(strings.TrimSuffix(statistics.Duplications, "%"), 64)
+
+	for _, raw := range []string{sonar.Bugs, sonar.Vulnerabilities, sonar.CodeSmells} {
+		if raw == "" {
+			continue
+		}
+		var issues []apistructs.TestIssues
+		if err := json.Unmarshal([]byte(raw), &issues); err != nil {
+			logrus.Warningf("failed to unmarshal sonar issues, id: %d, err: %v", sonar.ID, err)
+			continue
+		}
+		for _, issue := range issues {
+			point.Severities[strings.ToUpper(issue.Severity)]++
+		}
+	}
+	return point
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+)
+
+func TestSonarTrendBuckets(t *testing.T) {
+	// 2021-09-08 是周三
+	start := time.Date(2021, 9, 8, 15, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	buckets, err := sonarTrendBuckets(start, end, apistructs.SonarIssueTrendBucketDay)
+	assert.NoError(t, err)
+	assert.Equal(t, 24, len(buckets))
+	assert.Equal(t, time.Date(2021, 9, 8, 0, 0, 0, 0, time.UTC), buckets[0])
+
+	buckets, err = sonarTrendBuckets(start, end, apistructs.SonarIssueTrendBucketWeek)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(buckets))
+	assert.Equal(t, time.Date(2021, 9, 6, 0, 0, 0, 0, time.UTC), buckets[0])
+
+	buckets, err = sonarTrendBuckets(start, end, apistructs.SonarIssueTrendBucketMonth)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+	}, buckets)
+
+	_, err = sonarTrendBuckets(start, end, "hour")
+	assert.Error(t, err)
+
+	_, err = sonarTrendBuckets(start.AddDate(-2, 0, 0), end, apistructs.SonarIssueTrendBucketDay)
+	assert.Error(t, err)
+}
+
+func TestLatestSonarPerBucket(t *testing.T) {
+	day1 := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	day3 := day2.AddDate(0, 0, 1)
+	analyses := []dbclient.QASonar{
+		{ID: 1, UpdatedAt: day1.Add(time.Hour)},
+		{ID: 2, UpdatedAt: day1.Add(2 * time.Hour)},
+		{ID: 3, UpdatedAt: day3.Add(time.Hour)},
+	}
+	result := latestSonarPerBucket(analyses, []time.Time{day1, day2, day3})
+	assert.Equal(t, map[time.Time]int64{day1: 2, day3: 3}, result)
+}
@@ -45,7 +45,9 @@ func (e *Endpoints) CreateTestCase(ctx context.Context, r *http.Request, vars ma
 	}
 	req.IdentityInfo = identityInfo
 
-	// TODO:鉴权
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, req.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	tcID, err := e.testcase.CreateTestCase(req)
 	if err != nil {
@@ -71,7 +73,13 @@ func (e *Endpoints) BatchCreateTestCases(ctx context.Context, r *http.Request, v
 	}
 	req.IdentityInfo = identityInfo
 
-	// TODO:鉴权
+	testSetIDs := make([]uint64, 0, len(req.TestCases))
+	for _, tc := range req.TestCases {
+		testSetIDs = append(testSetIDs, tc.TestSetID)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, testSetIDs...); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	testCaseIDs, err := e.testcase.BatchCreateTestCases(req)
 	if err != nil {
@@ -93,7 +101,9 @@ func (e *Endpoints) UpdateTestCase(ctx context.Context, r *http.Request, vars ma
 		return apierrors.ErrUpdateTestCase.InvalidParameter("testCaseID").ToResp(), nil
 	}
 
-	// TODO:鉴权
+	if err := e.checkTestCaseSetPermission(identityInfo, apistructs.TestSetPermissionActionEdit, testCaseID); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	// 校验 body 合法性
 	if r.ContentLength == 0 {
@@ -113,19 +123,177 @@ func (e *Endpoints) UpdateTestCase(ctx context.Context, r *http.Request, vars ma
 	return httpserver.OkResp(nil)
 }
 
+// ReorderTestCaseSteps 调整测试用例步骤顺序
+func (e *Endpoints) ReorderTestCaseSteps(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateTestCase.NotLogin().ToResp(), nil
+	}
+
+	testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateTestCase.InvalidParameter("testCaseID").ToResp(), nil
+	}
+
+	// 校验 body 合法性
+	if r.ContentLength == 0 {
+		return apierrors.ErrUpdateTestCase.MissingParameter("request body").ToResp(), nil
+	}
+	var req apistructs.TestCaseStepReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateTestCase.InvalidParameter(err).ToResp(), nil
+	}
+
+	tc, err := e.testcase.GetTestCase(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCasePermission(identityInfo, tc.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, tc.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	req.TestCaseID = testCaseID
+	req.IdentityInfo = identityInfo
+	steps, err := e.testcase.ReorderTestCaseSteps(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(steps)
+}
+
+// GetTestCaseHistory 查询测试用例历史版本列表
+func (e *Endpoints) GetTestCaseHistory(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestCaseHistory.NotLogin().ToResp(), nil
+	}
+
+	testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestCaseHistory.InvalidParameter("testCaseID").ToResp(), nil
+	}
+	tc, err := e.testcase.GetTestCase(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCasePermission(identityInfo, tc.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, tc.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	versions, err := e.testcase.ListTestCaseHistory(apistructs.TestCaseHistoryListRequest{
+		TestCaseID:   testCaseID,
+		IdentityInfo: identityInfo,
+	})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	var userIDs []string
+	for _, v := range versions {
+		userIDs = append(userIDs, v.OperatorID)
+	}
+
+	return httpserver.OkResp(versions, strutil.DedupSlice(userIDs, true))
+}
+
+// GetTestCaseHistoryVersion 查看测试用例指定历史版本
+func (e *Endpoints) GetTestCaseHistoryVersion(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestCaseHistory.NotLogin().ToResp(), nil
+	}
+
+	testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestCaseHistory.InvalidParameter("testCaseID").ToResp(), nil
+	}
+	version, err := strconv.ParseUint(vars["version"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestCaseHistory.InvalidParameter("version").ToResp(), nil
+	}
+	tc, err := e.testcase.GetTestCase(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCasePermission(identityInfo, tc.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, tc.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	v, err := e.testcase.GetTestCaseHistoryVersion(testCaseID, version)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(v, []string{v.OperatorID})
+}
+
+// RestoreTestCaseHistory 将测试用例恢复至指定历史版本
+func (e *Endpoints) RestoreTestCaseHistory(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrRestoreTestCaseHistory.NotLogin().ToResp(), nil
+	}
+
+	testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRestoreTestCaseHistory.InvalidParameter("testCaseID").ToResp(), nil
+	}
+	version, err := strconv.ParseUint(vars["version"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRestoreTestCaseHistory.InvalidParameter("version").ToResp(), nil
+	}
+	tc, err := e.testcase.GetTestCase(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCasePermission(identityInfo, tc.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, tc.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	v, err := e.testcase.RestoreTestCaseHistory(apistructs.TestCaseHistoryRestoreRequest{
+		TestCaseID:   testCaseID,
+		Version:      version,
+		IdentityInfo: identityInfo,
+	})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(v)
+}
+
 // GetTestCase 获取测试用例详情
 func (e *Endpoints) GetTestCase(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestCase.NotLogin().ToResp(), nil
+	}
+
 	tcID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
 	if err != nil {
 		return apierrors.ErrGetTestCase.InvalidParameter("testCaseID").ToResp(), nil
 	}
 
-	// TODO: 操作鉴权
-
 	tc, err := e.testcase.GetTestCase(tcID)
 	if err != nil {
 		return errorresp.ErrResp(err)
 	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, tc.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	return httpserver.OkResp(*tc, strutil.DedupSlice([]string{tc.CreatorID, tc.UpdaterID}, true))
 }
@@ -137,8 +305,6 @@ func (e *Endpoints) BatchUpdateTestCases(ctx context.Context, r *http.Request, v
 		return apierrors.ErrBatchUpdateTestCases.NotLogin().ToResp(), nil
 	}
 
-	// TODO:鉴权
-
 	// 校验 body 合法性
 	var req apistructs.TestCaseBatchUpdateRequest
 	if r.ContentLength == 0 {
@@ -149,6 +315,16 @@ func (e *Endpoints) BatchUpdateTestCases(ctx context.Context, r *http.Request, v
 	}
 	req.IdentityInfo = identityInfo
 
+	// 移动用例需要同时拥有源测试集和目标测试集的编辑权限
+	if err := e.checkTestCaseSetPermission(identityInfo, apistructs.TestSetPermissionActionEdit, req.TestCaseIDs...); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if req.MoveToTestSetID != nil {
+		if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, *req.MoveToTestSetID); err != nil {
+			return errorresp.ErrResp(err)
+		}
+	}
+
 	if err := e.testcase.BatchUpdateTestCases(req); err != nil {
 		return errorresp.ErrResp(err)
 	}
@@ -156,7 +332,7 @@ func (e *Endpoints) BatchUpdateTestCases(ctx context.Context, r *http.Request, v
 	return httpserver.OkResp(nil)
 }
 
-// BatchUpdateTestCases 批量复制测试用例
+// BatchCopyTestCases 批量复制测试用例，支持复制到其他项目的测试集
 func (e *Endpoints) BatchCopyTestCases(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identityInfo, err := user.GetIdentityInfo(r)
 	if err != nil {
@@ -173,7 +349,13 @@ func (e *Endpoints) BatchCopyTestCases(ctx context.Context, r *http.Request, var
 	}
 	req.IdentityInfo = identityInfo
 
-	// TODO:鉴权
+	// 复制用例需要拥有源测试集的查看权限及目标测试集的编辑权限
+	if err := e.checkTestCaseSetPermission(identityInfo, apistructs.TestSetPermissionActionRead, req.TestCaseIDs...); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, req.CopyToTestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	copiedTestCaseIDs, err := e.testcase.BatchCopyTestCases(req)
 	if err != nil {
@@ -205,14 +387,83 @@ func (e *Endpoints) BatchCleanTestCasesFromRecycleBin(ctx context.Context, r *ht
 	return httpserver.OkResp(nil)
 }
 
+// SearchTestCases 测试用例全文搜索
+func (e *Endpoints) SearchTestCases(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrSearchTestCases.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.TestCaseSearchRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrSearchTestCases.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	// 测试集鉴权，并排除无权查看的子测试集
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, req.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if req.ExcludeTestSetIDs, err = e.testset.ListUnreadableTestSetIDs(identityInfo, req.ProjectID, false); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.testcase.SearchTestCases(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
+// DetectDuplicateTestCases 检测测试集内疑似重复的测试用例
+func (e *Endpoints) DetectDuplicateTestCases(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDetectDuplicateTestCases.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.TestCaseDuplicateDetectRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrDetectDuplicateTestCases.InvalidParameter(err).ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	// 测试集鉴权，并排除无权查看的子测试集
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, req.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if req.ExcludeTestSetIDs, err = e.testset.ListUnreadableTestSetIDs(identityInfo, req.ProjectID, false); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.testcase.DetectDuplicateTestCases(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
 // PagingTestCases 获取测试用例列表
 func (e *Endpoints) PagingTestCases(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrPagingTestCases.NotLogin().ToResp(), nil
+	}
+
 	var req apistructs.TestCasePagingRequest
 	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
 		return apierrors.ErrPagingTestCases.InvalidParameter(err).ToResp(), nil
 	}
 
-	// TODO: 操作鉴权
+	// 测试集鉴权，并排除无权查看的子测试集
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, req.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if req.ExcludeTestSetIDs, err = e.testset.ListUnreadableTestSetIDs(identityInfo, req.ProjectID, req.Recycled); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	//判断UpdaterIDs在项目内是否有权限
 	if len(req.UpdaterIDs) > 0 {
@@ -262,7 +513,13 @@ func (e *Endpoints) ExportTestCases(ctx context.Context, r *http.Request, vars m
 	l := e.bdl.GetLocaleByRequest(r)
 	req.Locale = l.Name()
 
-	// TODO:鉴权
+	// 测试集鉴权，并排除无权查看的子测试集
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, req.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if req.ExcludeTestSetIDs, err = e.testset.ListUnreadableTestSetIDs(identityInfo, req.ProjectID, req.Recycled); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	fileID, err := e.testcase.Export(req)
 	if err != nil {
@@ -316,3 +573,40 @@ func (e *Endpoints) ImportTestCases(ctx context.Context, r *http.Request, vars m
 		Content: importResult,
 	}, nil
 }
+
+// checkTestCasePermission 校验用户对项目下测试用例的操作权限
+func (e *Endpoints) checkTestCasePermission(identityInfo apistructs.IdentityInfo, projectID uint64, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  projectID,
+		Resource: apistructs.TestPlanResource,
+		Action:   action,
+	})
+	if err != nil {
+		return err
+	}
+	if !access.Access {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	return nil
+}
+
+// checkTestCaseSetPermission 校验用户在测试用例所属测试集上的授权
+func (e *Endpoints) checkTestCaseSetPermission(identityInfo apistructs.IdentityInfo, action apistructs.TestSetPermissionAction, testCaseIDs ...uint64) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	tcs, err := e.db.ListTestCasesByIDs(testCaseIDs)
+	if err != nil {
+		return apierrors.ErrCheckPermission.InternalError(err)
+	}
+	testSetIDs := make([]uint64, 0, len(tcs))
+	for _, tc := range tcs {
+		testSetIDs = append(testSetIDs, tc.TestSetID)
+	}
+	return e.testset.CheckPermission(identityInfo, action, testSetIDs...)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// CreateTestCaseAttachment 上传测试用例或测试计划中用例执行结果的附件
+func (e *Endpoints) CreateTestCaseAttachment(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateTestCaseAttachment.NotLogin().ToResp(), nil
+	}
+
+	target, err := e.getTestCaseAttachmentTarget(vars)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCaseAttachmentPermission(identityInfo, target.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if target.TestPlanCaseRelID != 0 {
+		if err := e.testPlan.CheckMemberPermission(identityInfo, target.TestPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+			return errorresp.ErrResp(err)
+		}
+	}
+
+	f, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		return apierrors.ErrCreateTestCaseAttachment.InvalidParameter(err).ToResp(), nil
+	}
+	defer f.Close()
+	req := apistructs.TestCaseAttachmentCreateRequest{
+		TestCaseAttachmentTarget: target,
+		Name:                     r.FormValue("name"),
+		ContentType:              fileHeader.Header.Get("Content-Type"),
+		IdentityInfo:             identityInfo,
+	}
+	if req.Name == "" {
+		req.Name = fileHeader.Filename
+	}
+
+	attachment, err := e.testcase.CreateAttachment(req, f)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(attachment, []string{attachment.CreatorID})
+}
+
+// ListTestCaseAttachments 查询测试用例或测试计划中用例执行结果的附件
+func (e *Endpoints) ListTestCaseAttachments(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListTestCaseAttachments.NotLogin().ToResp(), nil
+	}
+
+	target, err := e.getTestCaseAttachmentTarget(vars)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCaseAttachmentPermission(identityInfo, target.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	attachments, err := e.testcase.ListAttachments(target)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	var userIDs []string
+	for _, attachment := range attachments {
+		userIDs = append(userIDs, attachment.CreatorID)
+	}
+
+	return httpserver.OkResp(attachments, strutil.DedupSlice(userIDs, true))
+}
+
+// DeleteTestCaseAttachment 删除测试用例或测试计划中用例执行结果的附件
+func (e *Endpoints) DeleteTestCaseAttachment(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteTestCaseAttachment.NotLogin().ToResp(), nil
+	}
+
+	attachmentID, err := strconv.ParseUint(vars["attachmentID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteTestCaseAttachment.InvalidParameter("attachmentID").ToResp(), nil
+	}
+	target, err := e.getTestCaseAttachmentTarget(vars)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCaseAttachmentPermission(identityInfo, target.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if target.TestPlanCaseRelID != 0 {
+		if err := e.testPlan.CheckMemberPermission(identityInfo, target.TestPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+			return errorresp.ErrResp(err)
+		}
+	}
+
+	if err := e.testcase.DeleteAttachment(target, attachmentID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(attachmentID)
+}
+
+// getTestCaseAttachmentTarget 根据路径参数确定附件归属: 带 relationID 时为测试计划中用例的执行结果, 否则为用例本身
+func (e *Endpoints) getTestCaseAttachmentTarget(vars map[string]string) (apistructs.TestCaseAttachmentTarget, error) {
+	var target apistructs.TestCaseAttachmentTarget
+	if _, ok := vars["relationID"]; ok {
+		testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+		if err != nil {
+			return target, apierrors.ErrGetTestPlanCaseRel.InvalidParameter("testPlanID")
+		}
+		relID, err := strconv.ParseUint(vars["relationID"], 10, 64)
+		if err != nil {
+			return target, apierrors.ErrGetTestPlanCaseRel.InvalidParameter("relationID")
+		}
+		rel, err := e.testPlan.GetRel(relID)
+		if err != nil {
+			return target, err
+		}
+		if rel.TestPlanID != testPlanID {
+			return target, apierrors.ErrGetTestPlanCaseRel.NotFound()
+		}
+		target.TestCaseID = rel.TestCaseID
+		target.TestPlanID = rel.TestPlanID
+		target.TestPlanCaseRelID = rel.ID
+	} else {
+		testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+		if err != nil {
+			return target, apierrors.ErrGetTestCase.InvalidParameter("testCaseID")
+		}
+		target.TestCaseID = testCaseID
+	}
+
+	tc, err := e.testcase.GetTestCase(target.TestCaseID)
+	if err != nil {
+		return target, err
+	}
+	target.ProjectID = tc.ProjectID
+	return target, nil
+}
+
+func (e *Endpoints) checkTestCaseAttachmentPermission(identityInfo apistructs.IdentityInfo, projectID uint64, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  projectID,
+		Resource: apistructs.TestPlanResource,
+		Action:   action,
+	})
+	if err != nil {
+		return err
+	}
+	if !access.Access {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// ListTestCaseAuditLogs 分页查询测试用例审计日志
+func (e *Endpoints) ListTestCaseAuditLogs(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListTestCaseAuditLogs.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.TestCaseAuditLogListRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListTestCaseAuditLogs.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestCaseID, err = strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListTestCaseAuditLogs.InvalidParameter("testCaseID").ToResp(), nil
+	}
+	req.IdentityInfo = identityInfo
+
+	// 用例可能已被彻底删除，以请求中的项目鉴权，查询时同样按项目过滤
+	if req.ProjectID == 0 {
+		return apierrors.ErrListTestCaseAuditLogs.MissingParameter("projectID").ToResp(), nil
+	}
+	if err := e.checkTestCasePermission(identityInfo, req.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	data, err := e.testcase.ListTestCaseAuditLogs(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	var userIDs []string
+	for _, l := range data.List {
+		userIDs = append(userIDs, l.OperatorID)
+	}
+
+	return httpserver.OkResp(data, strutil.DedupSlice(userIDs, true))
+}
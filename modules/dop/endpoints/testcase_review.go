@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// SubmitTestCaseReview 提交测试用例评审
+func (e *Endpoints) SubmitTestCaseReview(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	return e.reviewTestCase(r, vars, apistructs.TestCaseReviewActionSubmit)
+}
+
+// ApproveTestCase 测试用例评审通过
+func (e *Endpoints) ApproveTestCase(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	return e.reviewTestCase(r, vars, apistructs.TestCaseReviewActionApprove)
+}
+
+// RejectTestCase 驳回测试用例评审
+func (e *Endpoints) RejectTestCase(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	return e.reviewTestCase(r, vars, apistructs.TestCaseReviewActionReject)
+}
+
+func (e *Endpoints) reviewTestCase(r *http.Request, vars map[string]string, action apistructs.TestCaseReviewAction) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrReviewTestCase.NotLogin().ToResp(), nil
+	}
+
+	testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrReviewTestCase.InvalidParameter("testCaseID").ToResp(), nil
+	}
+
+	// 评审意见可选，驳回时必填
+	var req apistructs.TestCaseReviewRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return apierrors.ErrReviewTestCase.InvalidParameter(err).ToResp(), nil
+		}
+	}
+	req.TestCaseID = testCaseID
+	req.Action = action
+	req.IdentityInfo = identityInfo
+
+	tc, err := e.testcase.GetTestCase(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCasePermission(identityInfo, tc.ProjectID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	review, err := e.testcase.ReviewTestCase(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(review)
+}
+
+// ListTestCaseReviews 查询测试用例评审记录
+func (e *Endpoints) ListTestCaseReviews(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListTestCaseReviews.NotLogin().ToResp(), nil
+	}
+
+	testCaseID, err := strconv.ParseUint(vars["testCaseID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListTestCaseReviews.InvalidParameter("testCaseID").ToResp(), nil
+	}
+
+	tc, err := e.testcase.GetTestCase(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.checkTestCasePermission(identityInfo, tc.ProjectID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	reviews, err := e.testcase.ListTestCaseReviews(testCaseID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	var userIDs []string
+	for _, review := range reviews {
+		userIDs = append(userIDs, review.OperatorID)
+	}
+
+	return httpserver.OkResp(reviews, strutil.DedupSlice(userIDs, true))
+}
@@ -102,6 +102,9 @@ func (e *Endpoints) UpdateTestPlan(ctx context.Context, r *http.Request, vars ma
 			return apierrors.ErrUpdateTestPlan.AccessDenied().ToResp(), nil
 		}
 	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, apistructs.TestPlanMemberPermissionManage); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	if err := e.testPlan.Update(req); err != nil {
 		return errorresp.ErrResp(err)
@@ -144,6 +147,9 @@ func (e *Endpoints) DeleteTestPlan(ctx context.Context, r *http.Request, vars ma
 		return apierrors.ErrDeleteTestPlan.InvalidParameter(err).ToResp(), nil
 	}
 
+	if err := e.testPlan.CheckMemberPermission(identityInfo, testPlanID, apistructs.TestPlanMemberPermissionManage); err != nil {
+		return errorresp.ErrResp(err)
+	}
 	if err := e.testPlan.Delete(identityInfo, testPlanID); err != nil {
 		return errorresp.ErrResp(err)
 	}
@@ -306,6 +312,9 @@ func (e *Endpoints) ExecuteTestPlanAPITest(ctx context.Context, r *http.Request,
 			return apierrors.ErrTestPlanExecuteAPITest.AccessDenied().ToResp(), nil
 		}
 	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	triggeredPipelineID, err := e.testPlan.ExecuteAPITest(req)
 	if err != nil {
@@ -315,6 +324,61 @@ func (e *Endpoints) ExecuteTestPlanAPITest(ctx context.Context, r *http.Request,
 	return httpserver.OkResp(triggeredPipelineID)
 }
 
+// RerunFailedTestPlanAPITest 重跑测试计划某次接口测试中未通过的用例
+func (e *Endpoints) RerunFailedTestPlanAPITest(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrTestPlanRerunFailedAPITest.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrTestPlanRerunFailedAPITest.InvalidParameter(err).ToResp(), nil
+	}
+
+	if r.ContentLength == 0 {
+		return apierrors.ErrTestPlanRerunFailedAPITest.MissingParameter("request body").ToResp(), nil
+	}
+	var req apistructs.TestPlanAPITestRerunFailedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrTestPlanRerunFailedAPITest.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestPlanID = testPlanID
+	req.IdentityInfo = identityInfo
+
+	tp, err := e.testPlan.Get(req.TestPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if !req.IsInternalClient() {
+		// Authorize
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   req.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  tp.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.OperateAction,
+		})
+		if err != nil {
+			return errorresp.ErrResp(err)
+		}
+		if !access.Access {
+			return apierrors.ErrTestPlanRerunFailedAPITest.AccessDenied().ToResp(), nil
+		}
+	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	result, err := e.testPlan.RerunFailedAPITest(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
 // ListTestPlanTestSets 获取测试计划下的测试集列表
 func (e *Endpoints) ListTestPlanTestSets(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identityInfo, err := user.GetIdentityInfo(r)
@@ -374,6 +438,60 @@ func (e *Endpoints) ExportTestPlanCaseRels(ctx context.Context, w http.ResponseW
 	return nil
 }
 
+// CompareTestPlanAPITests 对比测试计划两次接口测试的用例结果
+func (e *Endpoints) CompareTestPlanAPITests(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrTestPlanCompareAPITest.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrTestPlanCompareAPITest.InvalidParameter(err).ToResp(), nil
+	}
+	basePipelineID, err := strconv.ParseUint(r.URL.Query().Get("basePipelineID"), 10, 64)
+	if err != nil {
+		return apierrors.ErrTestPlanCompareAPITest.InvalidParameter("basePipelineID").ToResp(), nil
+	}
+	pipelineID, err := strconv.ParseUint(r.URL.Query().Get("pipelineID"), 10, 64)
+	if err != nil {
+		return apierrors.ErrTestPlanCompareAPITest.InvalidParameter("pipelineID").ToResp(), nil
+	}
+	req := apistructs.TestPlanAPITestCompareRequest{
+		TestPlanID:     testPlanID,
+		BasePipelineID: basePipelineID,
+		PipelineID:     pipelineID,
+		IdentityInfo:   identityInfo,
+	}
+
+	tp, err := e.testPlan.Get(req.TestPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if !req.IsInternalClient() {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   req.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  tp.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return errorresp.ErrResp(err)
+		}
+		if !access.Access {
+			return apierrors.ErrTestPlanCompareAPITest.AccessDenied().ToResp(), nil
+		}
+	}
+
+	result, err := e.testPlan.CompareAPITests(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
 // GenerateTestPlanReport 生成测试计划报告
 func (e *Endpoints) GenerateTestPlanReport(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	identityInfo, err := user.GetIdentityInfo(r)
@@ -394,3 +512,83 @@ func (e *Endpoints) GenerateTestPlanReport(ctx context.Context, r *http.Request,
 
 	return httpserver.OkResp(report, report.UserIDs)
 }
+
+// GetTestPlanBurndown 获取测试计划按天统计的执行进度
+func (e *Endpoints) GetTestPlanBurndown(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestPlanBurndown.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestPlanBurndown.InvalidParameter(err).ToResp(), nil
+	}
+
+	tp, err := e.testPlan.Get(testPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if !identityInfo.IsInternalClient() {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  tp.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return apierrors.ErrGetTestPlanBurndown.InternalError(err).ToResp(), nil
+		}
+		if !access.Access {
+			return apierrors.ErrGetTestPlanBurndown.AccessDenied().ToResp(), nil
+		}
+	}
+
+	burndown, err := e.testPlan.GetBurndown(testPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(burndown)
+}
+
+// GetTestPlanExecDurations 获取测试计划内各用例的历史执行耗时统计
+func (e *Endpoints) GetTestPlanExecDurations(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestPlanExecDurations.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestPlanExecDurations.InvalidParameter(err).ToResp(), nil
+	}
+
+	tp, err := e.testPlan.Get(testPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if !identityInfo.IsInternalClient() {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  tp.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return apierrors.ErrGetTestPlanExecDurations.InternalError(err).ToResp(), nil
+		}
+		if !access.Access {
+			return apierrors.ErrGetTestPlanExecDurations.AccessDenied().ToResp(), nil
+		}
+	}
+
+	durations, err := e.testPlan.GetExecDurations(testPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(durations)
+}
@@ -72,6 +72,9 @@ func (e *Endpoints) CreateTestPlanCaseRelations(ctx context.Context, r *http.Req
 			return apierrors.ErrCreateTestPlanCaseRel.AccessDenied().ToResp(), nil
 		}
 	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, apistructs.TestPlanMemberPermissionManage); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	result, err := e.testPlan.CreateCaseRelations(req)
 	if err != nil {
@@ -146,6 +149,14 @@ func (e *Endpoints) BatchUpdateTestPlanCaseRelations(ctx context.Context, r *htt
 			return apierrors.ErrBatchUpdateTestPlanCaseRels.AccessDenied().ToResp(), nil
 		}
 	}
+	// 移除用例、指派执行人需要管理权限, 仅更新执行结果需要执行权限
+	permission := apistructs.TestPlanMemberPermissionExecute
+	if req.Delete || req.ExecutorID != "" {
+		permission = apistructs.TestPlanMemberPermissionManage
+	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, permission); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	if err = e.testPlan.BatchUpdateTestPlanCaseRels(req); err != nil {
 		return errorresp.ErrResp(err)
@@ -204,6 +215,9 @@ func (e *Endpoints) RemoveTestPlanCaseRelIssueRelations(ctx context.Context, r *
 			return apierrors.ErrRemoveTestPlanCaseRelIssueRelation.AccessDenied().ToResp(), nil
 		}
 	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	if err = e.testPlan.RemoveTestPlanCaseRelIssueRelations(req); err != nil {
 		return errorresp.ErrResp(err)
@@ -262,6 +276,9 @@ func (e *Endpoints) AddTestPlanCaseRelIssueRelations(ctx context.Context, r *htt
 			return apierrors.ErrAddTestPlanCaseRelIssueRelation.AccessDenied().ToResp(), nil
 		}
 	}
+	if err := e.testPlan.CheckMemberPermission(identityInfo, req.TestPlanID, apistructs.TestPlanMemberPermissionExecute); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	if err = e.testPlan.AddTestPlanCaseRelIssueRelations(req); err != nil {
 		return errorresp.ErrResp(err)
@@ -0,0 +1,174 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// ListTestPlanMembers 查询测试计划成员及其角色
+func (e *Endpoints) ListTestPlanMembers(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListTestPlanMembers.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrListTestPlanMembers.InvalidParameter(err).ToResp(), nil
+	}
+	if err := e.checkTestPlanMemberPermission(identityInfo, testPlanID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	members, err := e.testPlan.ListMembers(testPlanID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	userIDs := make([]string, 0, len(members))
+	for _, mem := range members {
+		userIDs = append(userIDs, mem.UserID)
+	}
+
+	return httpserver.OkResp(members, userIDs)
+}
+
+// CreateTestPlanMember 添加测试计划成员
+func (e *Endpoints) CreateTestPlanMember(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrCreateTestPlanMember.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrCreateTestPlanMember.InvalidParameter(err).ToResp(), nil
+	}
+	if r.ContentLength == 0 {
+		return apierrors.ErrCreateTestPlanMember.MissingParameter("request body").ToResp(), nil
+	}
+	var req apistructs.TestPlanMemberCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrCreateTestPlanMember.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestPlanID = testPlanID
+	req.IdentityInfo = identityInfo
+
+	if err := e.checkTestPlanMemberPermission(identityInfo, testPlanID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	member, err := e.testPlan.CreateMember(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(member, []string{member.UserID})
+}
+
+// UpdateTestPlanMember 修改测试计划成员的角色
+func (e *Endpoints) UpdateTestPlanMember(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateTestPlanMember.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateTestPlanMember.InvalidParameter(err).ToResp(), nil
+	}
+	if r.ContentLength == 0 {
+		return apierrors.ErrUpdateTestPlanMember.MissingParameter("request body").ToResp(), nil
+	}
+	var req apistructs.TestPlanMemberUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateTestPlanMember.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestPlanID = testPlanID
+	req.UserID = vars["userID"]
+	req.IdentityInfo = identityInfo
+
+	if err := e.checkTestPlanMemberPermission(identityInfo, testPlanID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	member, err := e.testPlan.UpdateMember(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(member, []string{member.UserID})
+}
+
+// DeleteTestPlanMember 移除测试计划成员
+func (e *Endpoints) DeleteTestPlanMember(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteTestPlanMember.NotLogin().ToResp(), nil
+	}
+
+	testPlanID, err := strconv.ParseUint(vars[urlPathTestPlanID], 10, 64)
+	if err != nil {
+		return apierrors.ErrDeleteTestPlanMember.InvalidParameter(err).ToResp(), nil
+	}
+	userID := vars["userID"]
+
+	if err := e.checkTestPlanMemberPermission(identityInfo, testPlanID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.testPlan.DeleteMember(testPlanID, userID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(userID)
+}
+
+// checkTestPlanMemberPermission 校验项目权限, 修改成员时还需要是测试计划的所有者
+func (e *Endpoints) checkTestPlanMemberPermission(identityInfo apistructs.IdentityInfo, testPlanID uint64, action string) error {
+	tp, err := e.testPlan.Get(testPlanID)
+	if err != nil {
+		return err
+	}
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  tp.ProjectID,
+		Resource: apistructs.TestPlanResource,
+		Action:   action,
+	})
+	if err != nil {
+		return err
+	}
+	if !access.Access {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	if action == apistructs.GetAction {
+		return nil
+	}
+	return e.testPlan.CheckMemberPermission(identityInfo, testPlanID, apistructs.TestPlanMemberPermissionManage)
+}
@@ -46,6 +46,11 @@ func (e *Endpoints) CreateTestSet(ctx context.Context, r *http.Request, vars map
 		return apierrors.ErrCreateTestSet.InvalidParameter(err).ToResp(), nil
 	}
 	req.IdentityInfo = identityInfo
+	if req.ParentID != nil {
+		if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, *req.ParentID); err != nil {
+			return errorresp.ErrResp(err)
+		}
+	}
 
 	// create
 	result, err := e.testset.Create(req)
@@ -61,7 +66,6 @@ func (e *Endpoints) GetTestSet(ctx context.Context, r *http.Request, vars map[st
 	if err != nil {
 		return apierrors.ErrGetTestSet.NotLogin().ToResp(), nil
 	}
-	_ = identityInfo
 
 	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
 	if err != nil {
@@ -73,6 +77,14 @@ func (e *Endpoints) GetTestSet(ctx context.Context, r *http.Request, vars map[st
 	if err != nil {
 		return errorresp.ErrResp(err)
 	}
+	// 测试集自身或上级测试集的授权不允许查看
+	readable, err := e.testset.FilterReadable(identityInfo, ts.ProjectID, []apistructs.TestSet{*ts})
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if len(readable) == 0 {
+		return apierrors.ErrGetTestSet.AccessDenied().ToResp(), nil
+	}
 
 	tsWithAncestors := apistructs.TestSetWithAncestors{TestSet: *ts}
 
@@ -100,6 +112,12 @@ func (e *Endpoints) ListTestSets(ctx context.Context, r *http.Request, vars map[
 	if err != nil {
 		return errorresp.ErrResp(err)
 	}
+	// 过滤掉根据测试集授权无权查看的测试集
+	if identityInfo, err := user.GetIdentityInfo(r); err == nil && identityInfo.UserID != "" {
+		if testSets, err = e.testset.FilterReadable(identityInfo, *req.ProjectID, testSets); err != nil {
+			return errorresp.ErrResp(err)
+		}
+	}
 
 	return httpserver.OkResp(testSets)
 }
@@ -120,6 +138,14 @@ func (e *Endpoints) UpdateTestSet(ctx context.Context, r *http.Request, vars map
 	}
 	req.IdentityInfo = identityInfo
 
+	editTestSetIDs := []uint64{req.TestSetID}
+	if req.MoveToParentID != nil {
+		editTestSetIDs = append(editTestSetIDs, *req.MoveToParentID)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, editTestSetIDs...); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
 	if err := e.testset.Update(req); err != nil {
 		return errorresp.ErrResp(err)
 	}
@@ -144,6 +170,9 @@ func (e *Endpoints) RecycleTestSet(ctx context.Context, r *http.Request, vars ma
 		logrus.Errorf("failed to parse testSetID from path, value: %s, err: %v", vars["testSetID"], err)
 		return apierrors.ErrRecycleTestSet.InvalidParameter("testSetID").ToResp(), nil
 	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, testSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
 
 	if err := e.testset.Recycle(apistructs.TestSetRecycleRequest{
 		TestSetID:    testSetID,
@@ -299,6 +328,13 @@ func (e *Endpoints) CopyTestSet(ctx context.Context, r *http.Request, vars map[s
 	req.TestSetID = testSetID
 	req.IdentityInfo = identityInfo
 
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionRead, req.TestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if err := e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, req.CopyToTestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
 	id, isAsync, err := e.testset.Copy(req)
 	if err != nil {
 		return errorresp.ErrResp(err)
@@ -320,3 +356,191 @@ func (e *Endpoints) CopyTestSet(ctx context.Context, r *http.Request, vars map[s
 		Content: id,
 	}, nil
 }
+
+// MoveTestSet 移动测试集至新的父测试集下
+func (e *Endpoints) MoveTestSet(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrMoveTestSet.NotLogin().ToResp(), nil
+	}
+
+	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
+	if err != nil {
+		logrus.Errorf("failed to parse testSetID, input: %s, err: %v", vars["testSetID"], err)
+		return apierrors.ErrMoveTestSet.InvalidParameter("testSetID").ToResp(), nil
+	}
+
+	var req apistructs.TestSetMoveRequest
+	if r.ContentLength == 0 {
+		return apierrors.ErrMoveTestSet.MissingParameter("request body").ToResp(), nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrMoveTestSet.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestSetID = testSetID
+	req.IdentityInfo = identityInfo
+
+	if err := e.checkTestSetPermission(identityInfo, req.TestSetID, req.MoveToParentID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	ts, err := e.testset.Move(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(ts)
+}
+
+// MergeTestSet 将测试集合并至目标测试集
+func (e *Endpoints) MergeTestSet(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrMergeTestSet.NotLogin().ToResp(), nil
+	}
+
+	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
+	if err != nil {
+		logrus.Errorf("failed to parse testSetID, input: %s, err: %v", vars["testSetID"], err)
+		return apierrors.ErrMergeTestSet.InvalidParameter("testSetID").ToResp(), nil
+	}
+
+	var req apistructs.TestSetMergeRequest
+	if r.ContentLength == 0 {
+		return apierrors.ErrMergeTestSet.MissingParameter("request body").ToResp(), nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrMergeTestSet.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestSetID = testSetID
+	req.IdentityInfo = identityInfo
+
+	if err := e.checkTestSetPermission(identityInfo, req.TestSetID, req.MergeToTestSetID); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	ts, err := e.testset.Merge(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(ts)
+}
+
+// checkTestSetPermission 校验用户对测试集所属项目的更新权限及对测试集的编辑授权，0 表示根目录，不需要校验
+func (e *Endpoints) checkTestSetPermission(identityInfo apistructs.IdentityInfo, testSetIDs ...uint64) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	checked := make(map[uint64]struct{})
+	for _, testSetID := range testSetIDs {
+		if testSetID == 0 {
+			continue
+		}
+		ts, err := e.testset.Get(testSetID)
+		if err != nil {
+			return err
+		}
+		if _, ok := checked[ts.ProjectID]; ok {
+			continue
+		}
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  ts.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.UpdateAction,
+		})
+		if err != nil {
+			return err
+		}
+		if !access.Access {
+			return apierrors.ErrCheckPermission.AccessDenied()
+		}
+		checked[ts.ProjectID] = struct{}{}
+	}
+	return e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, testSetIDs...)
+}
+
+// GetTestRecycleBinPolicy 获取项目回收站自动清理策略
+func (e *Endpoints) GetTestRecycleBinPolicy(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestRecycleBinPolicy.NotLogin().ToResp(), nil
+	}
+
+	projectID, err := strconv.ParseUint(vars["projectID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestRecycleBinPolicy.InvalidParameter("projectID").ToResp(), nil
+	}
+
+	if !identityInfo.IsInternalClient() {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  projectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return apierrors.ErrGetTestRecycleBinPolicy.InternalError(err).ToResp(), nil
+		}
+		if !access.Access {
+			return apierrors.ErrGetTestRecycleBinPolicy.AccessDenied().ToResp(), nil
+		}
+	}
+
+	policy, err := e.testset.GetRecycleBinPolicy(projectID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(policy)
+}
+
+// UpdateTestRecycleBinPolicy 更新项目回收站自动清理策略
+func (e *Endpoints) UpdateTestRecycleBinPolicy(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrUpdateTestRecycleBinPolicy.NotLogin().ToResp(), nil
+	}
+
+	projectID, err := strconv.ParseUint(vars["projectID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrUpdateTestRecycleBinPolicy.InvalidParameter("projectID").ToResp(), nil
+	}
+
+	if r.ContentLength == 0 {
+		return apierrors.ErrUpdateTestRecycleBinPolicy.MissingParameter("request body").ToResp(), nil
+	}
+	var req apistructs.TestRecycleBinPolicyUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrUpdateTestRecycleBinPolicy.InvalidParameter(err).ToResp(), nil
+	}
+	req.ProjectID = projectID
+	req.IdentityInfo = identityInfo
+
+	// 彻底删除数据，仅项目管理员可配置
+	if !identityInfo.IsInternalClient() {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  projectID,
+			Resource: apistructs.ProjectResource,
+			Action:   apistructs.UpdateAction,
+		})
+		if err != nil {
+			return apierrors.ErrUpdateTestRecycleBinPolicy.InternalError(err).ToResp(), nil
+		}
+		if !access.Access {
+			return apierrors.ErrUpdateTestRecycleBinPolicy.AccessDenied().ToResp(), nil
+		}
+	}
+
+	policy, err := e.testset.UpdateRecycleBinPolicy(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(policy)
+}
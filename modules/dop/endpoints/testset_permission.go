@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/user"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// ListTestSetPermissions 查询测试集上的显式授权
+func (e *Endpoints) ListTestSetPermissions(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrListTestSetPermissions.NotLogin().ToResp(), nil
+	}
+
+	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrListTestSetPermissions.InvalidParameter("testSetID").ToResp(), nil
+	}
+	if err := e.checkTestSetGrantPermission(identityInfo, testSetID, apistructs.GetAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	perms, err := e.testset.ListPermissions(testSetID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	userIDs := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		userIDs = append(userIDs, perm.UserID)
+	}
+
+	return httpserver.OkResp(perms, userIDs)
+}
+
+// GrantTestSetPermission 授予用户在测试集上的角色, 子测试集未单独授权时继承该角色
+func (e *Endpoints) GrantTestSetPermission(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGrantTestSetPermission.NotLogin().ToResp(), nil
+	}
+
+	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGrantTestSetPermission.InvalidParameter("testSetID").ToResp(), nil
+	}
+	if r.ContentLength == 0 {
+		return apierrors.ErrGrantTestSetPermission.MissingParameter("request body").ToResp(), nil
+	}
+	var req apistructs.TestSetPermissionGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return apierrors.ErrGrantTestSetPermission.InvalidParameter(err).ToResp(), nil
+	}
+	req.TestSetID = testSetID
+	req.IdentityInfo = identityInfo
+
+	if err := e.checkTestSetGrantPermission(identityInfo, testSetID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	perm, err := e.testset.GrantPermission(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(perm, []string{perm.UserID, perm.CreatorID})
+}
+
+// RevokeTestSetPermission 撤销用户在测试集上的授权
+func (e *Endpoints) RevokeTestSetPermission(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrRevokeTestSetPermission.NotLogin().ToResp(), nil
+	}
+
+	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrRevokeTestSetPermission.InvalidParameter("testSetID").ToResp(), nil
+	}
+	if err := e.checkTestSetGrantPermission(identityInfo, testSetID, apistructs.UpdateAction); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	if err := e.testset.RevokePermission(testSetID, vars["userID"]); err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(nil)
+}
+
+// GetTestSetEffectivePermission 查询用户在测试集上的有效权限, 综合项目权限及自身或继承自上级测试集的授权
+func (e *Endpoints) GetTestSetEffectivePermission(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrGetTestSetPermission.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.TestSetEffectivePermissionRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrGetTestSetPermission.InvalidParameter(err).ToResp(), nil
+	}
+	testSetID, err := strconv.ParseUint(vars["testSetID"], 10, 64)
+	if err != nil {
+		return apierrors.ErrGetTestSetPermission.InvalidParameter("testSetID").ToResp(), nil
+	}
+	req.TestSetID = testSetID
+	req.IdentityInfo = identityInfo
+	if req.UserID == "" {
+		req.UserID = identityInfo.UserID
+	}
+	if req.UserID == "" {
+		return apierrors.ErrGetTestSetPermission.MissingParameter("userID").ToResp(), nil
+	}
+
+	ts, err := e.testset.Get(req.TestSetID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	if !identityInfo.IsInternalClient() {
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   identityInfo.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  ts.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return apierrors.ErrGetTestSetPermission.InternalError(err).ToResp(), nil
+		}
+		if !access.Access {
+			return apierrors.ErrGetTestSetPermission.AccessDenied().ToResp(), nil
+		}
+	}
+
+	perm, err := e.testset.GetEffectivePermission(req.TestSetID, req.UserID)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+	// 测试集授权只能收紧项目权限, 最终权限还需满足项目权限
+	for _, check := range []struct {
+		action string
+		allow  *bool
+	}{
+		{apistructs.GetAction, &perm.CanRead},
+		{apistructs.UpdateAction, &perm.CanEdit},
+	} {
+		if !*check.allow {
+			continue
+		}
+		access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   req.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  ts.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   check.action,
+		})
+		if err != nil {
+			return apierrors.ErrGetTestSetPermission.InternalError(err).ToResp(), nil
+		}
+		*check.allow = access.Access
+	}
+
+	return httpserver.OkResp(perm, []string{perm.UserID})
+}
+
+// checkTestSetGrantPermission 查看授权需要项目的查看权限, 修改授权还需要项目的更新权限及对测试集的编辑授权
+func (e *Endpoints) checkTestSetGrantPermission(identityInfo apistructs.IdentityInfo, testSetID uint64, action string) error {
+	ts, err := e.testset.Get(testSetID)
+	if err != nil {
+		return err
+	}
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	access, err := e.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  ts.ProjectID,
+		Resource: apistructs.TestPlanResource,
+		Action:   action,
+	})
+	if err != nil {
+		return err
+	}
+	if !access.Access {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	if action == apistructs.GetAction {
+		return nil
+	}
+	return e.testset.CheckPermission(identityInfo, apistructs.TestSetPermissionActionEdit, testSetID)
+}
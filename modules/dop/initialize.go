@@ -167,6 +167,54 @@ func (p *provider) Initialize() error {
 		}
 	}()
 
+	// Invalidate rotated api client secrets whose grace period has ended
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			ep.AssetService().SweepClientSecretRotations()
+		}
+	}()
+
+	// Encrypt plaintext autotest global config items marked as secret by operators
+	if err := ep.AutotestService().MigrateGlobalConfigSecrets(conf.AutotestGlobalConfigSecretKeys()); err != nil {
+		logrus.Errorf("failed to migrate autotest global config secrets, err: %v", err)
+	}
+
+	// Trigger due autotest scene and scene set schedules
+	go func() {
+		ticker := time.NewTicker(time.Second * 10)
+		for range ticker.C {
+			ep.AutotestV2Service().FireDueAutoTestSchedules()
+		}
+	}()
+
+	// Retry failed autotest scene webhook deliveries
+	go func() {
+		ticker := time.NewTicker(time.Second * 10)
+		for range ticker.C {
+			ep.AutotestV2Service().DeliverDueAutoTestSceneWebhooks()
+		}
+	}()
+
+	// Purge expired autotest scene executions
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		for range ticker.C {
+			ep.AutotestV2Service().PurgeAutoTestSceneExecutions()
+		}
+	}()
+
+	// Purge expired test cases and test sets from recycle bin
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		for range ticker.C {
+			ep.TestSetService().PurgeExpiredRecycleBin()
+		}
+	}()
+
+	// Purge expired test case audit logs, only one replica runs it
+	go ep.TestCaseService().RunTestCaseAuditLogPurger()
+
 	// Daily clear test file records
 	go func() {
 		day := time.NewTicker(time.Hour * 24 * time.Duration(purgeCycle))
@@ -286,14 +334,6 @@ func (p *provider) initEndpoints(db *dao.DBClient) (*endpoints.Endpoints, error)
 		branchrule.WithDBClient(db),
 		branchrule.WithBundle(bdl.Bdl),
 	)
-	gittarFileTreeSvc := filetree.New(filetree.WithBundle(bdl.Bdl), filetree.WithBranchRule(branchRule))
-
-	// 查询
-	pFileTree := projectpipelinefiletree.New(
-		projectpipelinefiletree.WithBundle(bdl.Bdl),
-		projectpipelinefiletree.WithFileTreeSvc(gittarFileTreeSvc),
-		projectpipelinefiletree.WithAutoTestSvc(autotest),
-	)
 
 	// init permission
 	perm := permission.New(permission.WithBundle(bdl.Bdl), permission.WithBranchRule(branchRule))
@@ -351,6 +391,7 @@ func (p *provider) initEndpoints(db *dao.DBClient) (*endpoints.Endpoints, error)
 		testplan.WithAutoTest(autotest),
 		testplan.WithIssue(issue),
 		testplan.WithIssueState(issueState),
+		testplan.WithIssueStream(issueStream),
 	)
 
 	workBench := workbench.New(
@@ -382,6 +423,24 @@ func (p *provider) initEndpoints(db *dao.DBClient) (*endpoints.Endpoints, error)
 		publisher.WithNexusSvc(nexusSvc),
 	)
 
+	pipelineSvc := pipeline.New(
+		pipeline.WithDBClient(db),
+		pipeline.WithBundle(bdl.Bdl),
+		pipeline.WithBranchRuleSvc(branchRule),
+		pipeline.WithPublisherSvc(pub),
+		pipeline.WithPipelineCms(p.PipelineCms),
+	)
+
+	gittarFileTreeSvc := filetree.New(filetree.WithBundle(bdl.Bdl), filetree.WithBranchRule(branchRule),
+		filetree.WithPipelineSvc(pipelineSvc))
+
+	// 查询
+	pFileTree := projectpipelinefiletree.New(
+		projectpipelinefiletree.WithBundle(bdl.Bdl),
+		projectpipelinefiletree.WithFileTreeSvc(gittarFileTreeSvc),
+		projectpipelinefiletree.WithAutoTestSvc(autotest),
+	)
+
 	// init certificate service
 	cer := certificate.New(
 		certificate.WithDBClient(db),
@@ -413,12 +472,7 @@ func (p *provider) initEndpoints(db *dao.DBClient) (*endpoints.Endpoints, error)
 	// compose endpoints
 	ep := endpoints.New(
 		endpoints.WithBundle(bdl.Bdl),
-		endpoints.WithPipeline(pipeline.New(
-			pipeline.WithBundle(bdl.Bdl),
-			pipeline.WithBranchRuleSvc(branchRule),
-			pipeline.WithPublisherSvc(pub),
-			pipeline.WithPipelineCms(p.PipelineCms),
-		)),
+		endpoints.WithPipeline(pipelineSvc),
 		endpoints.WithPipelineCms(p.PipelineCms),
 		endpoints.WithEvent(e),
 		endpoints.WithCDP(c),
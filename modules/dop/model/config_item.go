@@ -23,7 +23,7 @@ type ConfigItem struct {
 	UpdatedAt    time.Time `json:"updatedAt" gorm:"column:update_time"`
 	IsSync       bool      // deprecated
 	Dynamic      bool      // deprecated
-	Encrypt      bool      // deprecated
+	Encrypt      bool      // 是否为需要加密的敏感配置
 	DeleteRemote bool      // deprecated
 	IsDeleted    string
 	NamespaceID  uint64 `gorm:"index:namespace_id"`
@@ -33,6 +33,8 @@ type ConfigItem struct {
 	ItemType     string // FILE, ENV
 	Source       string
 	Status       string // deprecated
+	// KMSKeyID 不为空时 ItemValue 为 kms 加密后的密文
+	KMSKeyID string `gorm:"column:kms_key_id"`
 }
 
 // TableName 设置模型对应数据库表名称
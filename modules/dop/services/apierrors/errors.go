@@ -16,6 +16,8 @@
 package apierrors
 
 import (
+	"net/http"
+
 	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 )
 
@@ -27,21 +29,21 @@ const (
 
 var (
 	CreateAPIAsset  = err("ErrCreateAPIAsset", "创建 API 资料失败")
-	GetAPIAsset     = err("ErrGetAPIAsset", "查询 API 资料失败")
+	GetAPIAsset     = errWithStatus("ErrGetAPIAsset", "查询 API 资料失败", http.StatusNotFound)
 	UpdateAPIAsset  = err("ErrUpdateAPIAsset", "修改 API 资料失败")
 	PagingAPIAssets = err("ErrPagingAPIAssets", "分页查询 API 资料失败")
 	DeleteAPIAsset  = err("ErrDeleteAPIAsset", "删除 API 资料失败")
 
 	CreateAPIAssetVersion  = err("ErrCreateAPIAssetVersion", "创建 API 资料版本失败")
 	PagingAPIAssetVersions = err("ErrPagingAPIAssetVersions", "获取 API 资料版本列表失败")
-	GetAPIAssetVersion     = err("ErrGetAPIAssetVersion", "查询 API 资料版本详情失败")
+	GetAPIAssetVersion     = errWithStatus("ErrGetAPIAssetVersion", "查询 API 资料版本详情失败", http.StatusNotFound)
 	UpdateAssetVersion     = err("ErrUpdateAssetVersion", "修改 API 资料版本失败")
 	DeleteAPIAssetVersion  = err("ErrDeleteAPIAssetVersion", "删除 API 资料详情失败")
 
-	ValidateAPISpec        = err("ErrValidateAPISpec", "校验 API Spec 失败")
+	ValidateAPISpec        = errWithStatus("ErrValidateAPISpec", "校验 API Spec 失败", http.StatusBadRequest)
 	GetAPIAssetVersionSpec = err("GetAPIAssetVersionSpec", "查询 API 资料版本 Spec 失败")
 
-	ValidateAPIInstance = err("ErrValidateAPIInstance", "校验 API 实例失败")
+	ValidateAPIInstance = errWithStatus("ErrValidateAPIInstance", "校验 API 实例失败", http.StatusBadRequest)
 	CreateAPIInstance   = err("ErrCreateAPIInstance", "创建 API 实例失败")
 	ListAPIInstances    = err("ListAPIInstances", "查询 API 实例列表失败")
 
@@ -52,27 +54,35 @@ var (
 	UpdateInstantiation = err("ErrUpdateInstantiation", "更新实例化记录失败")
 	ListRuntimeServices = err("ErrListRuntimeServices", "列举应用下 Runtime Service 失败")
 
-	DownloadSpecText = err("ErrDownloadSpecText", "下载 Swagger 文本失败")
+	DownloadSpecText        = err("ErrDownloadSpecText", "下载 Swagger 文本失败")
+	ExportPostmanCollection = err("ErrExportPostmanCollection", "导出 Postman Collection 失败")
+	DiffAssetVersions       = err("ErrDiffAssetVersions", "比较 API 资料版本失败")
+	MockAPIAssetVersion     = err("ErrMockAPIAssetVersion", "生成 mock 响应失败")
 
-	CreateClient       = err("ErrCreateClient", "创建客户端失败")
-	ListClients        = err("ErrGetClients", "查询客户端失败")
-	GetClient          = err("ErrGetClient", "查询客户端详情")
-	ListSwaggerClients = err("ErrListSwaggerClients", "查询 SwaggerVersion 下的客户端列表失败")
-	UpdateClient       = err("ErrUpdateClient", "修改客户端失败")
-	DeleteClient       = err("ErrDeleteClient", "删除客户端失败")
+	CreateClient        = err("ErrCreateClient", "创建客户端失败")
+	ListClients         = err("ErrGetClients", "查询客户端失败")
+	GetClient           = errWithStatus("ErrGetClient", "查询客户端详情", http.StatusNotFound)
+	ListSwaggerClients  = err("ErrListSwaggerClients", "查询 SwaggerVersion 下的客户端列表失败")
+	UpdateClient        = err("ErrUpdateClient", "修改客户端失败")
+	DeleteClient        = err("ErrDeleteClient", "删除客户端失败")
+	RotateClientSecret  = err("ErrRotateClientSecret", "轮换客户端密钥失败")
+	ListSecretRotations = err("ErrListSecretRotations", "查询客户端密钥轮换记录失败")
 
 	CreateContract      = err("ErrCreateContract", "创建合约失败")
 	ListContracts       = err("ErrListContracts", "查询合约列表失败")
-	GetContract         = err("ErrGetContract", "查询合约详情失败")
+	GetContract         = errWithStatus("ErrGetContract", "查询合约详情失败", http.StatusNotFound)
 	ListContractRecords = err("ErrGetContractRecords", "查询合约操作记录失败")
 	UpdateContract      = err("ErrUpdateContract", "更新合约失败")
 	DeleteContract      = err("ErrDeleteContract", "删除调用申请记录失败")
+	ApproveContract     = err("ErrApproveContract", "通过调用申请失败")
+	RejectContract      = err("ErrRejectContract", "拒绝调用申请失败")
 
-	CreateAccess = err("ErrCreateAccess", "创建访问管理条目失败")
-	ListAccess   = err("ErrListAccess", "查询访问管理列表失败")
-	GetAccess    = err("ErrGetAccess", "查询访问管理条目失败")
-	DeleteAccess = err("ErrDeleteAccess", "删除访问管理条目失败")
-	UpdateAccess = err("ErrUpdateAccess", "更新访问管理条目失败")
+	CreateAccess      = err("ErrCreateAccess", "创建访问管理条目失败")
+	BatchCreateAccess = err("ErrBatchCreateAccess", "批量创建访问管理条目失败")
+	ListAccess        = err("ErrListAccess", "查询访问管理列表失败")
+	GetAccess         = errWithStatus("ErrGetAccess", "查询访问管理条目失败", http.StatusNotFound)
+	DeleteAccess      = err("ErrDeleteAccess", "删除访问管理条目失败")
+	UpdateAccess      = err("ErrUpdateAccess", "更新访问管理条目失败")
 
 	ListAPIGateways = err("ErrListAPIGateways", "获取 API Gateway 列表失败")
 
@@ -80,7 +90,7 @@ var (
 
 	ListSLAs  = err("ErrListSLAs", "查询 SLA 列表失败")
 	CreateSLA = err("ErrCreateSLAs", "创建 SLA 失败")
-	GetSLA    = err("ErrGetSLA", "查询 SLA 失败")
+	GetSLA    = errWithStatus("ErrGetSLA", "查询 SLA 失败", http.StatusNotFound)
 	DeleteSLA = err("ErrDeleteSLA", "删除 SLA 失败")
 	UpdateSLA = err("ErrUpdateSLA", "修改 SLA 失败")
 
@@ -98,6 +108,7 @@ var (
 	ListSchemas = err("ErrListSchemas", "查询 schema 列表失败")
 
 	SearchOperations = err("ErrSearchOperations", "搜索失败")
+	SearchAPIAssets  = err("ErrSearchAPIAssets", "搜索 API 资料失败")
 	GetOperation     = err("GetOperation", "查询接口详情失败")
 
 	// ErrReleaseCallback 回调函数错误信息
@@ -108,12 +119,15 @@ var (
 
 	ErrDealCDPCallback = err("ErrDealCDPCallback", "cdp hook回调失败")
 
+	ErrDealAutotestCallback = err("ErrDealAutotestCallback", "自动化测试流水线 hook 回调失败")
+	ErrDealAPITestCallback  = err("ErrDealAPITestCallback", "接口测试流水线 hook 回调失败")
+
 	ErrGetCICDTaskLog      = err("ErrGetCICDTaskLog", "查询 CICD 任务日志失败")
 	ErrDownloadCICDTaskLog = err("ErrDownloadCICDTaskLog", "下载 CICD 任务日志失败")
 
-	ErrCheckPermission = err("ErrCheckPermission", "权限校验失败")
+	ErrCheckPermission = errWithStatus("ErrCheckPermission", "权限校验失败", http.StatusForbidden)
 
-	ErrGetUser    = err("ErrGetUser", "获取用户信息失败，请登录")
+	ErrGetUser    = errWithStatus("ErrGetUser", "获取用户信息失败，请登录", http.StatusUnauthorized)
 	ErrGetApp     = err("ErrGetApp", "获取应用信息失败")
 	ErrGetProject = err("ErrGetProject", "获取项目失败")
 
@@ -131,6 +145,11 @@ var (
 	ErrRerunFailedPipeline    = err("ErrRerunFailedPipeline", "重试失败节点失败")
 	ErrRerunPipeline          = err("ErrRerunPipeline", "重试全流程失败")
 	ErrCreateCheckRun         = err("ErrCreateCheckRun", "创建流水线失败")
+	ErrCreateParamPreset      = err("ErrCreateParamPreset", "创建流水线运行参数预设失败")
+	ErrUpdateParamPreset      = err("ErrUpdateParamPreset", "更新流水线运行参数预设失败")
+	ErrDeleteParamPreset      = err("ErrDeleteParamPreset", "删除流水线运行参数预设失败")
+	ErrGetParamPreset         = err("ErrGetParamPreset", "获取流水线运行参数预设失败")
+	ErrListParamPreset        = err("ErrListParamPreset", "获取流水线运行参数预设列表失败")
 
 	ErrFetchConfigNamespace  = err("ErrFetchConfigNamespace", "获取私有配置命名空间失败")
 	ErrMakeConfigNamespace   = err("ErrMakeConfigNamespace", "创建私有配置命名空间失败")
@@ -145,12 +164,15 @@ var (
 	ErrStartPipelineCron  = err("ErrStartPipelineCron", "启动定时流水线失败")
 	ErrStopPipelineCron   = err("ErrStopPipelineCron", "停止定时流水线失败")
 	ErrDeletePipelineCron = err("ErrDeletePipelineCron", "删除流水线定时配置失败")
+	ErrGetCronFireTime    = err("ErrGetCronFireTime", "获取定时流水线触发时间失败")
+	ErrListCronHistory    = err("ErrListCronHistory", "获取定时流水线触发记录失败")
 
 	ErrAddEnvConfig          = err("ErrAddEnvConfig", "添加环境变量配置失败")
 	ErrUpdateEnvConfig       = err("ErrUpdateEnvConfig", "更新环境变量配置失败")
 	ErrDeleteEnvConfig       = err("ErrDeleteEnvConfig", "删除环境变量配置失败")
 	ErrGetEnvConfig          = err("ErrGetEnvConfig", "获取环境变量配置失败")
 	ErrGetNamespaceEnvConfig = err("ErrGetNamespaceEnvConfig", "获取指定namespace环境变量配置失败")
+	ErrEncryptEnvConfig      = err("ErrEncryptEnvConfig", "加密环境变量配置失败")
 
 	ErrDeletePipelineCmsNs              = err("ErrDeletePipelineCmsNs", "删除流水线配置管理命名空间失败")
 	ErrCreateOrUpdatePipelineCmsConfigs = err("ErrUpdatePipelineCmsConfigs", "创建或更新流水线配置管理配置失败")
@@ -197,23 +219,35 @@ var (
 	ErrGetPipelineDetail = err("ErrGetPipelineDetail", "查询流水线详情失败")
 	ErrGetPipelineLog    = err("ErrGetPipelineLog", "查询流水线日志失败")
 
-	ErrStoreSonarIssue = err("ErrStoreSonarIssue", "保存 Sonar 分析结果失败")
-	ErrGetSonarIssue   = err("ErrGetSonarIssue", "查询 Sonar 分析结果失败")
+	ErrStoreSonarIssue        = err("ErrStoreSonarIssue", "保存 Sonar 分析结果失败")
+	ErrGetSonarIssue          = err("ErrGetSonarIssue", "查询 Sonar 分析结果失败")
+	ErrSonarQualityGateFailed = err("ErrSonarQualityGateFailed", "代码质量门禁未通过")
+	ErrGetSonarIssueTrend     = err("ErrGetSonarIssueTrend", "查询代码质量趋势失败")
 
 	ErrPagingTestCases                   = err("ErrPagingTestCases", "分页查询测试用例失败")
 	ErrListTestCases                     = err("ErrListTestCases", "获取测试用例列表失败")
-	ErrGetTestCase                       = err("ErrGetTestCase", "获取指定测试用例失败")
+	ErrSearchTestCases                   = err("ErrSearchTestCases", "搜索测试用例失败")
+	ErrDetectDuplicateTestCases          = err("ErrDetectDuplicateTestCases", "检测重复测试用例失败")
+	ErrGetTestCase                       = errWithStatus("ErrGetTestCase", "获取指定测试用例失败", http.StatusNotFound)
 	ErrCreateTestCase                    = err("ErrCreateTestCase", "创建测试用例失败")
 	ErrBatchCreateTestCases              = err("ErrBatchCreateTestCases", "批量创建测试用例失败")
 	ErrUpdateTestCase                    = err("ErrUpdateTestCase", "更新测试用例失败")
 	ErrBatchUpdateTestCases              = err("ErrBatchUpdateTestCases", "批量更新测试用例失败")
 	ErrBatchCopyTestCases                = err("ErrBatchCopyTestCases", "批量复制测试用例失败")
+	ErrGetTestCaseHistory                = err("ErrGetTestCaseHistory", "查询测试用例历史版本失败")
+	ErrRestoreTestCaseHistory            = err("ErrRestoreTestCaseHistory", "恢复测试用例历史版本失败")
+	ErrReviewTestCase                    = err("ErrReviewTestCase", "测试用例评审失败")
+	ErrListTestCaseReviews               = err("ErrListTestCaseReviews", "查询测试用例评审记录失败")
+	ErrListTestCaseAuditLogs             = err("ErrListTestCaseAuditLogs", "查询测试用例审计日志失败")
 	ErrDeleteTestCase                    = err("ErrDeleteTestCase", "删除测试用例失败")
 	ErrExportTestCases                   = err("ErrExportTestCases", "导出测试用例失败")
 	ErrImportTestCases                   = err("ErrImportTestCases", "导入测试用例失败")
-	ErrInvalidTestCaseExcelFormat        = err("ErrInvalidTestCaseExcelFormat", "文件格式不正确，请对比 Excel 导入模板")
+	ErrInvalidTestCaseExcelFormat        = errWithStatus("ErrInvalidTestCaseExcelFormat", "文件格式不正确，请对比 Excel 导入模板", http.StatusBadRequest)
 	ErrGetApiTestInfo                    = err("ErrErrGetApiTestInfo", "查询接口测试信息失败")
 	ErrBatchCleanTestCasesFromRecycleBin = err("ErrBatchCleanTestCasesFromRecycleBin", "从回收站批量删除测试用例失败")
+	ErrCreateTestCaseAttachment          = err("ErrCreateTestCaseAttachment", "上传测试用例附件失败")
+	ErrListTestCaseAttachments           = err("ErrListTestCaseAttachments", "查询测试用例附件失败")
+	ErrDeleteTestCaseAttachment          = err("ErrDeleteTestCaseAttachment", "删除测试用例附件失败")
 	ErrExportTestPlanCaseRels            = err("ErrExportTestPlanCaseRels", "导出测试计划下的测试用例失败")
 	ErrGenerateTestPlanReport            = err("ErrGenerateTestPlanReport", "生成测试计划报告失败")
 	ErrExecuteTestPlanReport             = err("ErrExecuteTestPlanReport", "执行测试计划失败")
@@ -224,25 +258,38 @@ var (
 	ErrUpdateTestSet                = err("ErrUpdateTestSet", "更新测试集失败")
 	ErrDeleteTestSet                = err("ErrDeleteTestSet", "删除测试集失败")
 	ErrCopyTestSet                  = err("ErrCopyTestSet", "复制测试集失败")
-	ErrGetTestSet                   = err("ErrGetTestSet", "获取指定测试集失败")
+	ErrMoveTestSet                  = err("ErrMoveTestSet", "移动测试集失败")
+	ErrMergeTestSet                 = err("ErrMergeTestSet", "合并测试集失败")
+	ErrGetTestSet                   = errWithStatus("ErrGetTestSet", "获取指定测试集失败", http.StatusNotFound)
 	ErrRecycleTestSet               = err("ErrRecycleTestSet", "回收测试集失败")
 	ErrCleanTestSetFromRecycleBin   = err("ErrCleanTestSetFromRecycleBin", "从回收站彻底删除测试集失败")
 	ErrRecoverTestSetFromRecycleBin = err("ErrRecoverTestSetFromRecycleBin", "从回收站恢复测试集失败")
+	ErrGetTestRecycleBinPolicy      = err("ErrGetTestRecycleBinPolicy", "获取回收站自动清理策略失败")
+	ErrUpdateTestRecycleBinPolicy   = err("ErrUpdateTestRecycleBinPolicy", "更新回收站自动清理策略失败")
+	ErrListTestSetPermissions       = err("ErrListTestSetPermissions", "查询测试集授权失败")
+	ErrGrantTestSetPermission       = err("ErrGrantTestSetPermission", "测试集授权失败")
+	ErrRevokeTestSetPermission      = err("ErrRevokeTestSetPermission", "撤销测试集授权失败")
+	ErrGetTestSetPermission         = err("ErrGetTestSetPermission", "查询测试集有效权限失败")
 
 	ErrCreateTestPlan                     = err("ErrCreateTestPlan", "创建测试计划失败")
 	ErrUpdateTestPlan                     = err("ErrUpdateTestPlan", "更新测试计划失败")
 	ErrDeleteTestPlan                     = err("ErrDeleteTestPlan", "删除测试计划失败")
-	ErrGetTestPlan                        = err("ErrGetTestPlan", "获取测试计划详情失败")
+	ErrGetTestPlan                        = errWithStatus("ErrGetTestPlan", "获取测试计划详情失败", http.StatusNotFound)
 	ErrAddTestPlanStep                    = err("ErrAddTestPlanStep", "添加测试计划步骤失败")
 	ErrDeleteTestPlanStep                 = err("ErrDeleteTestPlanStep", "删除测试计划步骤失败")
 	ErrUpdateTestPlanStep                 = err("ErrUpdateTestPlanStep", "更新测试计划步骤失败")
 	ErrCreateTestPlanMember               = err("ErrCreateTestPlanMember", "测试计划关联成员失败")
 	ErrUpdateTestPlanMember               = err("ErrUpdateTestPlanMember", "测试计划更新成员失败")
 	ErrListTestPlanMembers                = err("ErrListTestPlanMembers", "查询测试计划关联成员列表失败")
+	ErrDeleteTestPlanMember               = err("ErrDeleteTestPlanMember", "测试计划移除成员失败")
 	ErrPagingTestPlans                    = err("ErrPagingTestPlans", "分页查询测试计划失败")
 	ErrPagingTestPlanCaseRels             = err("ErrPagingTestPlanCaseRels", "获取测试计划内测试用例列表失败")
 	ErrTestPlanExecuteAPITest             = err("ErrTestPlanExecuteAPITest", "执行测试计划接口测试失败")
 	ErrTestPlanCancelAPITest              = err("ErrTestPlanCancelAPITest", "取消测试计划接口测试失败")
+	ErrTestPlanRerunFailedAPITest         = err("ErrTestPlanRerunFailedAPITest", "重跑测试计划未通过用例失败")
+	ErrTestPlanCompareAPITest             = err("ErrTestPlanCompareAPITest", "对比测试计划接口测试结果失败")
+	ErrGetTestPlanBurndown                = err("ErrGetTestPlanBurndown", "获取测试计划燃尽图数据失败")
+	ErrGetTestPlanExecDurations           = err("ErrGetTestPlanExecDurations", "获取测试计划用例执行耗时统计失败")
 	ErrCreateTestPlanCaseRel              = err("ErrCreateTestPlanCaseRel", "引用测试用例失败")
 	ErrBatchUpdateTestPlanCaseRels        = err("ErrBatchUpdateTestPlanCaseRels", "批量更新测试用例引用失败")
 	ErrRemoveTestPlanCaseRelIssueRelation = err("ErrRemoveTestPlanCaseRelIssueRelation", "解除测试计划用例与缺陷关联关系失败")
@@ -258,6 +305,7 @@ var (
 	ErrBatchCreateIssueTestCaseRel = err("ErrBatchCreateIssueTestCaseRel", "事件批量关联测试计划用例失败")
 	ErrDeleteIssueTestCaseRel      = err("ErrDeleteIssueTestCaseRel", "事件取消关联测试计划用例失败")
 	ErrListIssueTestCaseRels       = err("ErrListIssueTestCaseRels", "查询事件用例关联列表失败")
+	ErrGetRequirementCoverage      = err("ErrGetRequirementCoverage", "查询需求测试覆盖情况失败")
 
 	ErrCreateAutoTestFileTreeNode        = err("ErrCreateAutoTestFileTreeNode", "创建自动化测试目录树节点失败")
 	ErrDeleteAutoTestFileTreeNode        = err("ErrDeleteAutoTestFileTreeNode", "删除自动化测试目录树节点失败")
@@ -280,7 +328,7 @@ var (
 	ErrUpdateAutoTestSpace = err("ErrUpdateAutoTestSpace", "更新自动化测试空间失败")
 	ErrDeleteAutoTestSpace = err("ErrDeleteAutoTestSpace", "删除自动化测试空间失败")
 	ErrCopyAutoTestSpace   = err("ErrCopyAutoTestSpace", "复制自动化测试空间失败")
-	ErrGetAutoTestSpace    = err("ErrGetAutoTestSpace", "获取自动化测试空间失败")
+	ErrGetAutoTestSpace    = errWithStatus("ErrGetAutoTestSpace", "获取自动化测试空间失败", http.StatusNotFound)
 	ErrListAutoTestSpace   = err("ErrListAutoTestSpace", "获取自动化测试空间列表失败")
 	ErrExportAutoTestSpace = err("ErrExportAutoTestSpace", "导出自动化测试空间失败")
 	ErrImportAutoTestSpace = err("ErrImportAutoTestSpace", "导入自动化测试空间失败")
@@ -288,7 +336,7 @@ var (
 	ErrCreateAutoTestScene      = err("ErrCreateAutoTestScene", "创建自动化测试场景失败")
 	ErrUpdateAutoTestScene      = err("ErrUpdateAutoTestScene", "更新自动化测试场景失败")
 	ErrDeleteAutoTestScene      = err("ErrDeleteAutoTestScene", "删除自动化测试场景失败")
-	ErrGetAutoTestScene         = err("ErrGetAutoTestScene", "获取自动化测试场景失败")
+	ErrGetAutoTestScene         = errWithStatus("ErrGetAutoTestScene", "获取自动化测试场景失败", http.StatusNotFound)
 	ErrListAutoTestScene        = err("ErrListAutoTestScene", "获取自动化测试场景列表失败")
 	ErrExecuteAutoTestScene     = err("ErrExecuteAutoTestScene", "执行自动化测试场景失败")
 	ErrExecuteAutoTestSceneStep = err("ErrExecuteAutoTestSceneStep", "执行自动化测试场景步骤失败")
@@ -296,6 +344,46 @@ var (
 	ErrMoveAutoTestScene        = err("ErrMoveAutoTestScene", "拖动自动化测试场景失败")
 	ErrCopyAutoTestScene        = err("ErrCopyAutoTestScene", "复制自动化测试场景失败")
 
+	ErrGetAutoTestSceneExecution   = errWithStatus("ErrGetAutoTestSceneExecution", "获取自动化测试场景执行记录失败", http.StatusNotFound)
+	ErrListAutoTestSceneExecutions = err("ErrListAutoTestSceneExecutions", "获取自动化测试场景执行记录列表失败")
+	ErrGetAutoTestSceneStepGraph   = err("ErrGetAutoTestSceneStepGraph", "获取自动化测试场景步骤依赖图失败")
+
+	ErrGetAutoTestSceneBaseline    = errWithStatus("ErrGetAutoTestSceneBaseline", "获取自动化测试场景基线失败", http.StatusNotFound)
+	ErrSaveAutoTestSceneBaseline   = err("ErrSaveAutoTestSceneBaseline", "保存自动化测试场景基线失败")
+	ErrDeleteAutoTestSceneBaseline = err("ErrDeleteAutoTestSceneBaseline", "删除自动化测试场景基线失败")
+
+	ErrCreateAutoTestSceneWebhook       = err("ErrCreateAutoTestSceneWebhook", "创建自动化测试场景 webhook 失败")
+	ErrUpdateAutoTestSceneWebhook       = err("ErrUpdateAutoTestSceneWebhook", "更新自动化测试场景 webhook 失败")
+	ErrDeleteAutoTestSceneWebhook       = err("ErrDeleteAutoTestSceneWebhook", "删除自动化测试场景 webhook 失败")
+	ErrGetAutoTestSceneWebhook          = errWithStatus("ErrGetAutoTestSceneWebhook", "获取自动化测试场景 webhook 失败", http.StatusNotFound)
+	ErrListAutoTestSceneWebhook         = err("ErrListAutoTestSceneWebhook", "获取自动化测试场景 webhook 列表失败")
+	ErrListAutoTestSceneWebhookDelivery = err("ErrListAutoTestSceneWebhookDelivery", "获取自动化测试场景 webhook 投递记录失败")
+
+	ErrCreateAutoTestSceneTemplate      = err("ErrCreateAutoTestSceneTemplate", "创建自动化测试场景模板失败")
+	ErrUpdateAutoTestSceneTemplate      = err("ErrUpdateAutoTestSceneTemplate", "更新自动化测试场景模板失败")
+	ErrDeleteAutoTestSceneTemplate      = err("ErrDeleteAutoTestSceneTemplate", "删除自动化测试场景模板失败")
+	ErrGetAutoTestSceneTemplate         = errWithStatus("ErrGetAutoTestSceneTemplate", "获取自动化测试场景模板失败", http.StatusNotFound)
+	ErrListAutoTestSceneTemplate        = err("ErrListAutoTestSceneTemplate", "获取自动化测试场景模板列表失败")
+	ErrInstantiateAutoTestSceneTemplate = err("ErrInstantiateAutoTestSceneTemplate", "从模板创建自动化测试场景失败")
+	ErrUpdateAutoTestSceneFromTemplate  = err("ErrUpdateAutoTestSceneFromTemplate", "从模板更新自动化测试场景失败")
+
+	ErrCreateAutoTestSceneDataFile = err("ErrCreateAutoTestSceneDataFile", "添加自动化测试场景数据文件失败")
+	ErrDeleteAutoTestSceneDataFile = err("ErrDeleteAutoTestSceneDataFile", "删除自动化测试场景数据文件失败")
+	ErrGetAutoTestSceneDataFile    = errWithStatus("ErrGetAutoTestSceneDataFile", "获取自动化测试场景数据文件失败", http.StatusNotFound)
+	ErrListAutoTestSceneDataFile   = err("ErrListAutoTestSceneDataFile", "获取自动化测试场景数据文件列表失败")
+
+	ErrListAutoTestSceneVersion    = err("ErrListAutoTestSceneVersion", "获取自动化测试场景历史版本列表失败")
+	ErrGetAutoTestSceneVersion     = errWithStatus("ErrGetAutoTestSceneVersion", "获取自动化测试场景历史版本失败", http.StatusNotFound)
+	ErrRestoreAutoTestSceneVersion = err("ErrRestoreAutoTestSceneVersion", "恢复自动化测试场景历史版本失败")
+
+	ErrCreateAutoTestSchedule       = err("ErrCreateAutoTestSchedule", "创建自动化测试定时执行失败")
+	ErrUpdateAutoTestSchedule       = err("ErrUpdateAutoTestSchedule", "更新自动化测试定时执行失败")
+	ErrDeleteAutoTestSchedule       = err("ErrDeleteAutoTestSchedule", "删除自动化测试定时执行失败")
+	ErrGetAutoTestSchedule          = errWithStatus("ErrGetAutoTestSchedule", "获取自动化测试定时执行失败", http.StatusNotFound)
+	ErrListAutoTestSchedule         = err("ErrListAutoTestSchedule", "获取自动化测试定时执行列表失败")
+	ErrListAutoTestScheduleRecord   = err("ErrListAutoTestScheduleRecord", "获取自动化测试定时执行记录失败")
+	ErrListAutoTestScheduleUpcoming = err("ErrListAutoTestScheduleUpcoming", "获取自动化测试定时执行触发时间失败")
+
 	ErrCreateAutoTestSceneInput = err("ErrCreateAutoTestSceneInput", "创建自动化测试场景入参失败")
 	ErrUpdateAutoTestSceneInput = err("ErrUpdateAutoTestSceneInput", "更新自动化测试场景入参失败")
 	ErrDeleteAutoTestSceneInput = err("ErrDeleteAutoTestSceneInput", "删除自动化测试场景入参失败")
@@ -318,6 +406,8 @@ var (
 	ErrUpdateSonarMetricRules          = err("ErrUpdateSonarMetricRules", "更新指标规则失败")
 	ErrDeleteSonarMetricRules          = err("ErrDeleteSonarMetricRules", "删除指标规则失败")
 	ErrQuerySonarMetricRuleDefinitions = err("ErrQuerySonarMetricRuleDefinitions", "查询未添加的指标规则失败")
+	ErrExportSonarMetricRules          = err("ErrExportSonarMetricRules", "导出指标规则失败")
+	ErrImportSonarMetricRules          = err("ErrImportSonarMetricRules", "导入指标规则失败")
 
 	ErrCreateAutoTestSceneSet = err("ErrCreateAutoTestSceneSet", "创建自动化测试场景集失败")
 	ErrUpdateAutoTestSceneSet = err("ErrUpdateAutoTestSceneSet", "更新自动化测试场景集失败")
@@ -332,7 +422,7 @@ var (
 	ErrCloseTicket  = err("ErrCloseTicket", "关闭工单失败")
 	ErrReopenTicket = err("ErrReopenTicket", "重新打开工单失败")
 	ErrListTicket   = err("ErrListTicket", "获取工单列表失败")
-	ErrGetTicket    = err("ErrGetTicket", "获取工单失败")
+	ErrGetTicket    = errWithStatus("ErrGetTicket", "获取工单失败", http.StatusNotFound)
 
 	ErrCreateComment = err("ErrCreateComment", "创建评论失败")
 	ErrUpdateComment = err("ErrUpdateComment", "更新评论失败")
@@ -360,7 +450,7 @@ var (
 	ErrDeleteIssue                   = err("ErrDeleteIssue", "删除 issue 失败")
 	ErrBatchUpdateIssue              = err("ErrBatchUpdateIssue", "批量更新 issue 失败")
 	ErrUpdateIssueState              = err("ErrUpdateIssueState", "更新 issue 状态失败")
-	ErrGetIssue                      = err("ErrGetIssue", "查询 issue 失败")
+	ErrGetIssue                      = errWithStatus("ErrGetIssue", "查询 issue 失败", http.StatusNotFound)
 	ErrSubscribeIssue                = err("ErrSubscribeIssue", "订阅 issue 失败")
 	ErrExportExcelIssue              = err("ErrExportExcelIssue", "导出 issue 失败")
 	ErrImportExcelIssue              = err("ErrImportExcelIssue", "导入 issue 失败")
@@ -373,8 +463,8 @@ var (
 	ErrUpdateIssueProperty      = err("ErrUpdateIssueProperty", "更新事项字段失败")
 	ErrDeleteIssueProperty      = err("ErrDeleteIssueProperty", "删除事项字段失败")
 	ErrGetIssueProperty         = err("ErrGetIssueProperty", "查询事项字段失败")
-	ErrCreateIssuePropertyValue = err("ErrCreateIssuePropertyValue ", "创建事项字段枚举值失败")
-	ErrDeleteIssuePropertyValue = err("ErrDeleteIssuePropertyValue ", "删除事项字段枚举值失败")
+	ErrCreateIssuePropertyValue = err("ErrCreateIssuePropertyValue", "创建事项字段枚举值失败")
+	ErrDeleteIssuePropertyValue = err("ErrDeleteIssuePropertyValue", "删除事项字段枚举值失败")
 
 	ErrGetIssueStateRelation    = err("ErrGetIssueStateRelation", "事件获取状态关联失败")
 	ErrUpdateIssueStateRelation = err("ErrUpdateIssueStateRelation", "事件修改状态关联失败")
@@ -391,7 +481,7 @@ var (
 	ErrPagingIssueStream = err("ErrPagingIssueStream", "分页查询活动记录失败")
 	ErrListIssueStream   = err("ErrListIssueStream", "获取活动记录列表失败")
 
-	ErrGetIteration           = err("ErrGetIteration", "查询迭代失败")
+	ErrGetIteration           = errWithStatus("ErrGetIteration", "查询迭代失败", http.StatusNotFound)
 	ErrCreateIteration        = err("ErrCreateIteration", "创建迭代失败")
 	ErrUpdateIteration        = err("ErrUpdateIteration", "更新迭代失败")
 	ErrDeleteIteration        = err("ErrDeleteIteration", "删除迭代失败")
@@ -399,7 +489,7 @@ var (
 	ErrCreateCertificate      = err("ErrCreateCertificate", "创建证书失败")
 	ErrUpdateCertificate      = err("ErrUpdateCertificate", "更新证书失败")
 	ErrDeleteCertificate      = err("ErrDeleteCertificate", "删除证书失败")
-	ErrGetCertificate         = err("ErrGetCertificate", "获取证书失败")
+	ErrGetCertificate         = errWithStatus("ErrGetCertificate", "获取证书失败", http.StatusNotFound)
 	ErrListCertificate        = err("ErrListCertificate", "获取证书列表失败")
 	ErrQuoteCertificate       = err("ErrQuoteCertificate", "应用引用证书失败")
 	ErrCancelQuoteCertificate = err("ErrCancelQuoteCertificate", "取消引用证书失败")
@@ -408,13 +498,13 @@ var (
 
 	ErrUploadFile          = err("ErrUploadFile", "上传文件失败")
 	ErrUploadFileEncrypt   = err("ErrUploadFileEncrypt", "加密上传文件失败")
-	ErrUploadTooLargeFile  = err("ErrUploadTooLargeFile", "上传的文件超过限制大小")
+	ErrUploadTooLargeFile  = errWithStatus("ErrUploadTooLargeFile", "上传的文件超过限制大小", http.StatusRequestEntityTooLarge)
 	ErrDownloadFile        = err("ErrDownloadFile", "下载文件失败")
 	ErrDownloadFileDecrypt = err("ErrDownloadFileDecrypt", "解密下载文件失败")
 	ErrCleanExpiredFile    = err("ErrCleanExpiredFile", "清理过期文件失败")
 	ErrDeleteFile          = err("ErrDeleteFile", "删除文件失败")
 	ErrBackup              = err("ErrBackup", "备份失败")
-	ErrInvalidRef          = err("ErrInvalidRef", "invalid ref")
+	ErrInvalidRef          = errWithStatus("ErrInvalidRef", "invalid ref", http.StatusBadRequest)
 	ErrUploadImage         = err("ErrUploadImage", "上传图片失败")
 
 	ErrGetWorkBenchData = err("ErrGetWorkBenchData", "failed to query workbench data")
@@ -472,14 +562,24 @@ var (
 	ErrCreatePublisher = err("ErrCreatePublisher", "创建Publisher失败")
 	ErrUpdatePublisher = err("ErrUpdatePublisher", "更新Publisher失败")
 	ErrDeletePublisher = err("ErrDeletePublisher", "删除Publisher失败")
-	ErrGetPublisher    = err("ErrGetPublisher", "获取Publisher失败")
+	ErrGetPublisher    = errWithStatus("ErrGetPublisher", "获取Publisher失败", http.StatusNotFound)
 	ErrListPublisher   = err("ErrListPublisher", "获取Publisher列表失败")
 
-	ErrParallelRunPipeline = err("ErrParallelRunPipeline", "已有流水线正在运行中")
+	ErrParallelRunPipeline = errWithStatus("ErrParallelRunPipeline", "已有流水线正在运行中", http.StatusConflict)
 
 	ErrListFileRecords = err("ErrListFileRecords", "failed to list file records")
 )
 
+// templates 所有错误模板名及其中文默认文案, 模板名同时作为多语言文案的 key
+var templates = map[string]string{}
+
 func err(template, defaultValue string) *errorresp.APIError {
+	templates[template] = defaultValue
 	return errorresp.New(errorresp.WithTemplateMessage(template, defaultValue))
 }
+
+// errWithStatus 同 err, 并指定默认 HTTP 错误码, 调用方未显式覆盖时使用
+func errWithStatus(template, defaultValue string, httpCode int) *errorresp.APIError {
+	templates[template] = defaultValue
+	return errorresp.New(errorresp.WithTemplateMessage(template, defaultValue), errorresp.WithHttpCode(httpCode))
+}
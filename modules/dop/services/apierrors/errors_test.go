@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apierrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/i18n"
+)
+
+const catalogDir = "../../../../pkg/erda-configs/i18n"
+
+func TestCatalogCoverage(t *testing.T) {
+	loader := i18n.NewLoader()
+	assert.NoError(t, loader.LoadDir(catalogDir))
+	en := loader.Locale(i18n.EN)
+	for template := range templates {
+		assert.True(t, en.ExistKey(template), "missing en message of %s", template)
+	}
+}
+
+func TestLocaleMessage(t *testing.T) {
+	loader := i18n.NewLoader()
+	assert.NoError(t, loader.LoadDir(catalogDir))
+
+	e := CreateAPIAsset.InvalidParameter("name")
+	assert.Equal(t, "failed to create API asset: InvalidParameter name", e.Render(loader.Locale(i18n.EN)))
+	assert.Equal(t, "创建 API 资料失败: 参数错误 name", e.Render(loader.Locale(i18n.ZH)))
+	// 多次渲染结果一致
+	assert.Equal(t, "创建 API 资料失败: 参数错误 name", e.Error())
+	assert.Equal(t, "InvalidParameter", e.Code())
+
+	assert.Equal(t, "ErrCreateAPIAsset", CreateAPIAsset.Code())
+}
+
+func TestDefaultHttpCode(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, GetAPIAsset.HttpCode())
+	assert.Equal(t, http.StatusConflict, ErrParallelRunPipeline.HttpCode())
+	// 调用方显式指定时覆盖默认值
+	assert.Equal(t, http.StatusBadRequest, GetAPIAsset.InvalidParameter("assetID").HttpCode())
+	assert.Equal(t, http.StatusInternalServerError, GetAPIAsset.InternalError(assert.AnError).HttpCode())
+	// 未指定默认值
+	assert.Equal(t, http.StatusInternalServerError, UpdateContract.HttpCode())
+	assert.Equal(t, http.StatusInternalServerError, UpdateContract.ToResp().GetStatus())
+}
+
+func TestErrorDetails(t *testing.T) {
+	e := ErrCreateTestCase.MissingParameter("name").AppendDetail("name", "required")
+	b, err := json.Marshal(e.ToResp().GetContent())
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"details":[{"field":"name","reason":"required"}]`)
+	// 不影响原错误
+	assert.Empty(t, ErrCreateTestCase.Details())
+
+	b, err = json.Marshal(ErrCreateTestCase.MissingParameter("name").ToResp().GetContent())
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "details")
+}
@@ -18,6 +18,7 @@ package assetsvc
 
 import (
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/bdl"
@@ -114,6 +115,56 @@ func (svc *Service) createOrUpdateClientLimits(endpointID string, clientID strin
 	return bdl.Bdl.CreateOrUpdateClientLimits(clientID, endpointID, svc.limitModels2Types([]*apistructs.SLALimitModel{&limitModel}))
 }
 
+// syncSLALimits 将 SLA 的流量限制推送到网关上所有使用该 SLA 的已授权客户端.
+// 某个客户端推送失败时, 将已推送的客户端恢复为 oldLimit, 并返回该错误. limit 或 oldLimit 为 nil 表示不限制流量.
+func (svc *Service) syncSLALimits(endpointID string, slaID uint64, limit, oldLimit *apistructs.SLALimitModel) error {
+	if endpointID == "" {
+		return nil
+	}
+
+	clients, contracts, err := svc.slaAffectClients(slaID)
+	if err != nil {
+		return err
+	}
+	return svc.pushClientLimits(endpointID, clients, contracts, limit, oldLimit)
+}
+
+// pushClientLimits 将流量限制推送到网关上已授权的客户端, 失败时将已推送的客户端恢复为 oldLimit
+func (svc *Service) pushClientLimits(endpointID string, clients []*apistructs.ClientModel, contracts []*apistructs.ContractModel,
+	limit, oldLimit *apistructs.SLALimitModel) error {
+	var clientsM = make(map[uint64]*apistructs.ClientModel, len(clients))
+	for _, client := range clients {
+		clientsM[client.ID] = client
+	}
+
+	var synced []string
+	for _, contract := range contracts {
+		client, ok := clientsM[contract.ClientID]
+		if !ok || contract.Status.ToLower() != apistructs.ContractApproved {
+			continue
+		}
+		if err := bdl.Bdl.CreateOrUpdateClientLimits(client.ClientID, endpointID, svc.slaLimitTypes(limit)); err != nil {
+			for _, clientID := range synced {
+				if rollbackErr := bdl.Bdl.CreateOrUpdateClientLimits(clientID, endpointID, svc.slaLimitTypes(oldLimit)); rollbackErr != nil {
+					logrus.Errorf("failed to restore client limits, clientID: %s, err: %v", clientID, rollbackErr)
+				}
+			}
+			return errors.Wrapf(err, "client: %s", client.DisplayName)
+		}
+		synced = append(synced, client.ClientID)
+	}
+
+	return nil
+}
+
+// slaLimitTypes 将 SLA 的流量限制转换为网关的限制规则, limit 为 nil 时返回 nil, 即不限制流量
+func (svc *Service) slaLimitTypes(limit *apistructs.SLALimitModel) []apistructs.LimitType {
+	if limit == nil {
+		return nil
+	}
+	return svc.limitModels2Types([]*apistructs.SLALimitModel{limit})
+}
+
 func (svc *Service) limitModels2Types(models []*apistructs.SLALimitModel) []apistructs.LimitType {
 	var limitTypes = make([]apistructs.LimitType, len(models))
 	for i, v := range models {
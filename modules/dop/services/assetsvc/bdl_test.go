@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/bdl"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+func TestPushClientLimits(t *testing.T) {
+	if bdl.Bdl == nil {
+		bdl.Bdl = bundle.New()
+	}
+	// 网关上每个客户端当前生效的流量限制, 未设置的客户端不限制
+	var (
+		gateway map[string][]apistructs.LimitType
+		failed  string
+	)
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "CreateOrUpdateClientLimits", func(_ *bundle.Bundle, clientID, _ string, limits []apistructs.LimitType) error {
+		if clientID == failed {
+			return errors.New("gateway error")
+		}
+		gateway[clientID] = limits
+		return nil
+	})
+	defer monkey.UnpatchAll()
+
+	svc := New()
+	clients := []*apistructs.ClientModel{
+		{BaseModel: apistructs.BaseModel{ID: 1}, ClientID: "client-a"},
+		{BaseModel: apistructs.BaseModel{ID: 2}, ClientID: "client-b"},
+		{BaseModel: apistructs.BaseModel{ID: 3}, ClientID: "client-c"},
+	}
+	contracts := []*apistructs.ContractModel{
+		{ClientID: 1, Status: apistructs.ContractApproved},
+		{ClientID: 2, Status: apistructs.ContractApproving},
+		{ClientID: 3, Status: apistructs.ContractApproved},
+	}
+	limit := &apistructs.SLALimitModel{Limit: 100, Unit: apistructs.DurationMinute}
+	oldLimit := &apistructs.SLALimitModel{Limit: 10, Unit: apistructs.DurationMinute}
+	oldLimits := svc.slaLimitTypes(oldLimit)
+
+	tests := []struct {
+		name     string
+		oldLimit *apistructs.SLALimitModel
+		failed   string
+		wantErr  bool
+		want     map[string][]apistructs.LimitType
+	}{
+		{
+			name:     "all synced",
+			oldLimit: oldLimit,
+			want:     map[string][]apistructs.LimitType{"client-a": svc.slaLimitTypes(limit), "client-c": svc.slaLimitTypes(limit)},
+		},
+		{
+			name:     "restore old limit",
+			oldLimit: oldLimit,
+			failed:   "client-c",
+			wantErr:  true,
+			want:     map[string][]apistructs.LimitType{"client-a": oldLimits},
+		},
+		{
+			name:    "remove limits when there was no old limit",
+			failed:  "client-c",
+			wantErr: true,
+			want:    map[string][]apistructs.LimitType{"client-a": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, failed = make(map[string][]apistructs.LimitType), tt.failed
+			err := svc.pushClientLimits("endpoint-a", clients, contracts, limit, tt.oldLimit)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, gateway)
+		})
+	}
+}
+
+func TestSLALimitsSyncError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{name: "gateway rejected", err: errors.Wrap(apierrors.UpdateSLA.InvalidParameter("invalid limit"), "client: a"), wantCode: "InvalidParameter"},
+		{name: "gateway internal error", err: errors.Wrap(apierrors.UpdateSLA.InternalError(errors.New("timeout")), "client: a"), wantCode: "InternalError"},
+		{name: "other error", err: errors.New("connection refused"), wantCode: "InternalError"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, slaLimitsSyncError(tt.err).Code())
+		})
+	}
+}
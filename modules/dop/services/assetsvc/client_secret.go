@@ -0,0 +1,283 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/bdl"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// 旧密钥宽限期的上限
+const maxClientSecretGracePeriod = 30 * 24 * time.Hour
+
+// RotateClientSecret 轮换客户端密钥. 新密钥立即返回给调用方, 网关在宽限期内仍使用旧密钥,
+// 宽限期结束后由 SweepClientSecretRotations 将网关切换为新密钥. 新密钥只在本次响应中返回.
+func (svc *Service) RotateClientSecret(req *apistructs.RotateClientSecretReq) (*apistructs.RotateClientSecretRsp, *errorresp.APIError) {
+	if req == nil || req.URIParams == nil {
+		return nil, apierrors.RotateClientSecret.InvalidParameter("invalid parameter")
+	}
+	if req.Body == nil {
+		req.Body = new(apistructs.RotateClientSecretBody)
+	}
+
+	gracePeriod := time.Duration(conf.APIClientSecretGracePeriodSec()) * time.Second
+	if req.Body.GracePeriodSec != nil {
+		gracePeriod = time.Duration(*req.Body.GracePeriodSec) * time.Second
+	}
+	if gracePeriod > maxClientSecretGracePeriod {
+		return nil, apierrors.RotateClientSecret.InvalidParameter(errors.Errorf("宽限期不可超过 %s", maxClientSecretGracePeriod))
+	}
+
+	tx := dbclient.Tx()
+	defer tx.RollbackUnlessCommitted()
+
+	// 锁定客户端记录, 保证同一客户端同时只有一个未生效的轮换
+	var client apistructs.ClientModel
+	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&client, map[string]interface{}{
+		"org_id": req.OrgID,
+		"id":     req.URIParams.ClientID,
+	}).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.RotateClientSecret.NotFound()
+		}
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+
+	// 只有客户端创建者和企业管理员可以轮换密钥
+	rolesSet := bdl.FetchAssetRolesSet(req.OrgID, req.Identity.UserID)
+	if !inSlice(strconv.FormatUint(req.OrgID, 10), rolesSet.RolesOrgs(bdl.OrgMRoles...)) && req.Identity.UserID != client.CreatorID {
+		return nil, apierrors.RotateClientSecret.AccessDenied()
+	}
+
+	var pending uint64
+	if err := tx.Model(new(apistructs.ClientSecretRotationModel)).
+		Where(map[string]interface{}{"client_id": client.ID, "invalidated": false}).
+		Count(&pending).Error; err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+	if pending > 0 {
+		return nil, apierrors.RotateClientSecret.InvalidState("上一次轮换的新密钥尚未生效")
+	}
+
+	oldCredentials, err := bdl.Bdl.GetClientCredentials(client.ClientID)
+	if err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+
+	// 新密钥由 kms 生成, 密文保存到宽限期结束时切换网关
+	key, err := bdl.Bdl.KMSCreateKey(apistructs.KMSCreateKeyRequest{
+		CreateKeyRequest: kmstypes.CreateKeyRequest{PluginKind: kmstypes.PluginKind_DICE_KMS},
+	})
+	if err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+	dataKey, err := bdl.Bdl.KMSGenerateDataKey(apistructs.KMSGenerateDataKeyRequest{
+		GenerateDataKeyRequest: kmstypes.GenerateDataKeyRequest{KeyID: key.KeyMetadata.KeyID},
+	})
+	if err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+	newSecret, err := clientSecretFromDataKey(dataKey.PlaintextBase64)
+	if err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+
+	timeNow := time.Now()
+	rotation := apistructs.ClientSecretRotationModel{
+		CreatedAt:           timeNow,
+		UpdatedAt:           timeNow,
+		CreatorID:           req.Identity.UserID,
+		OrgID:               req.OrgID,
+		ClientID:            client.ID,
+		OldSecretHash:       hashClientSecret(oldCredentials.ClientSecret),
+		KMSKeyID:            key.KeyMetadata.KeyID,
+		NewSecretCiphertext: dataKey.CiphertextBase64,
+		ExpiredAt:           timeNow.Add(gracePeriod),
+	}
+	// 无宽限期时立即切换网关
+	if gracePeriod == 0 {
+		rotation.Invalidated = true
+		rotation.OldSecretHash = ""
+		rotation.NewSecretCiphertext = ""
+	}
+
+	if err := tx.Create(&rotation).Error; err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+
+	// 先记录轮换再切换网关, 网关切换失败则不提交
+	if rotation.Invalidated {
+		if _, err := bdl.Bdl.UpdateClientCredentials(client.ClientID, newSecret); err != nil {
+			return nil, apierrors.RotateClientSecret.InternalError(err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, apierrors.RotateClientSecret.InternalError(err)
+	}
+
+	return &apistructs.RotateClientSecretRsp{
+		SK: &apistructs.SK{
+			ClientID:     client.ClientID,
+			ClientSecret: newSecret,
+		},
+		OldSecretExpiredAt: rotation.ExpiredAt,
+	}, nil
+}
+
+// ListClientSecretRotations 查询客户端的密钥轮换记录
+func (svc *Service) ListClientSecretRotations(req *apistructs.ListClientSecretRotationsReq) ([]*apistructs.ClientSecretRotationModel, *errorresp.APIError) {
+	var client apistructs.ClientModel
+	if err := svc.FirstRecord(&client, map[string]interface{}{
+		"org_id": req.OrgID,
+		"id":     req.URIParams.ClientID,
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ListSecretRotations.NotFound()
+		}
+		return nil, apierrors.ListSecretRotations.InternalError(err)
+	}
+
+	rolesSet := bdl.FetchAssetRolesSet(req.OrgID, req.Identity.UserID)
+	if !inSlice(strconv.FormatUint(req.OrgID, 10), rolesSet.RolesOrgs(bdl.OrgMRoles...)) && req.Identity.UserID != client.CreatorID {
+		return nil, apierrors.ListSecretRotations.AccessDenied()
+	}
+
+	var rotations []*apistructs.ClientSecretRotationModel
+	if err := dbclient.Sq().Where(map[string]interface{}{"client_id": client.ID}).
+		Order("created_at DESC").
+		Find(&rotations).Error; err != nil {
+		return nil, apierrors.ListSecretRotations.InternalError(err)
+	}
+
+	return rotations, nil
+}
+
+// SweepClientSecretRotations 宽限期已结束的轮换, 将网关切换为新密钥, 旧密钥随之失效
+func (svc *Service) SweepClientSecretRotations() {
+	sweeper := clientSecretSweeper{
+		listPending: func(now time.Time) ([]*apistructs.ClientSecretRotationModel, error) {
+			var rotations []*apistructs.ClientSecretRotationModel
+			err := dbclient.Sq().Where("invalidated = ?", false).
+				Where("expired_at <= ?", now).
+				Find(&rotations).Error
+			return rotations, err
+		},
+		getClientID: func(id uint64) (string, error) {
+			var client apistructs.ClientModel
+			if err := svc.FirstRecord(&client, map[string]interface{}{"id": id}); err != nil {
+				return "", err
+			}
+			return client.ClientID, nil
+		},
+		decrypt: func(rotation *apistructs.ClientSecretRotationModel) (string, error) {
+			decrypted, err := bdl.Bdl.KMSDecrypt(apistructs.KMSDecryptRequest{
+				DecryptRequest: kmstypes.DecryptRequest{
+					KeyID:            rotation.KMSKeyID,
+					CiphertextBase64: rotation.NewSecretCiphertext,
+				},
+			})
+			if err != nil {
+				return "", err
+			}
+			return clientSecretFromDataKey(decrypted.PlaintextBase64)
+		},
+		applySecret: func(clientID, secret string) error {
+			_, err := bdl.Bdl.UpdateClientCredentials(clientID, secret)
+			return err
+		},
+		markApplied: func(rotation *apistructs.ClientSecretRotationModel) error {
+			return dbclient.Sq().Model(new(apistructs.ClientSecretRotationModel)).
+				Where("id = ? AND invalidated = ?", rotation.ID, false).
+				Updates(map[string]interface{}{
+					"invalidated":           true,
+					"old_secret_hash":       "",
+					"new_secret_ciphertext": "",
+				}).Error
+		},
+	}
+	sweeper.run(time.Now())
+}
+
+// clientSecretSweeper 宽限期结束时将新密钥切换到网关
+type clientSecretSweeper struct {
+	listPending func(now time.Time) ([]*apistructs.ClientSecretRotationModel, error)
+	getClientID func(id uint64) (string, error)
+	decrypt     func(rotation *apistructs.ClientSecretRotationModel) (string, error)
+	applySecret func(clientID, secret string) error
+	markApplied func(rotation *apistructs.ClientSecretRotationModel) error
+}
+
+func (s clientSecretSweeper) run(now time.Time) {
+	rotations, err := s.listPending(now)
+	if err != nil {
+		logrus.Errorf("failed to list pending client secret rotations, err: %v", err)
+		return
+	}
+	for _, rotation := range rotations {
+		// 宽限期内网关保持旧密钥
+		if rotation.Invalidated || rotation.ExpiredAt.After(now) {
+			continue
+		}
+		if err := s.apply(rotation); err != nil {
+			logrus.Errorf("failed to apply rotated secret of api client %d, err: %v", rotation.ClientID, err)
+		}
+	}
+}
+
+func (s clientSecretSweeper) apply(rotation *apistructs.ClientSecretRotationModel) error {
+	clientID, err := s.getClientID(rotation.ClientID)
+	if err != nil {
+		return err
+	}
+	secret, err := s.decrypt(rotation)
+	if err != nil {
+		return err
+	}
+	// 多副本同时执行时写入的是同一个新密钥, 切换是幂等的
+	if err := s.applySecret(clientID, secret); err != nil {
+		return err
+	}
+	return s.markApplied(rotation)
+}
+
+// clientSecretFromDataKey 将 kms 数据密钥转换为客户端密钥
+func clientSecretFromDataKey(plaintextBase64 string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(plaintextBase64)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
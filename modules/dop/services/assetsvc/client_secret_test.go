@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestClientSecretSweeper(t *testing.T) {
+	const (
+		oldSecret = "old-secret"
+		newSecret = "new-secret"
+	)
+	// 网关上每个客户端只有一个有效密钥
+	gateway := map[string]string{"client-a": oldSecret}
+	authenticate := func(secret string) bool { return gateway["client-a"] == secret }
+
+	rotatedAt := time.Date(2021, 10, 8, 10, 0, 0, 0, time.UTC)
+	rotation := &apistructs.ClientSecretRotationModel{
+		ID:                  1,
+		ClientID:            100,
+		NewSecretCiphertext: "ciphertext",
+		ExpiredAt:           rotatedAt.Add(time.Hour),
+	}
+	sweeper := clientSecretSweeper{
+		listPending: func(now time.Time) ([]*apistructs.ClientSecretRotationModel, error) {
+			return []*apistructs.ClientSecretRotationModel{rotation}, nil
+		},
+		getClientID: func(id uint64) (string, error) {
+			assert.Equal(t, uint64(100), id)
+			return "client-a", nil
+		},
+		decrypt: func(r *apistructs.ClientSecretRotationModel) (string, error) {
+			assert.Equal(t, "ciphertext", r.NewSecretCiphertext)
+			return newSecret, nil
+		},
+		applySecret: func(clientID, secret string) error {
+			gateway[clientID] = secret
+			return nil
+		},
+		markApplied: func(r *apistructs.ClientSecretRotationModel) error {
+			r.Invalidated = true
+			r.NewSecretCiphertext = ""
+			return nil
+		},
+	}
+
+	// 宽限期内旧密钥仍然可以通过网关鉴权
+	sweeper.run(rotatedAt.Add(30 * time.Minute))
+	assert.True(t, authenticate(oldSecret))
+	assert.False(t, rotation.Invalidated)
+
+	// 宽限期结束后切换为新密钥, 旧密钥失效
+	sweeper.run(rotatedAt.Add(time.Hour))
+	assert.False(t, authenticate(oldSecret))
+	assert.True(t, authenticate(newSecret))
+	assert.True(t, rotation.Invalidated)
+	assert.Empty(t, rotation.NewSecretCiphertext)
+}
+
+func TestClientSecretFromDataKey(t *testing.T) {
+	secret, err := clientSecretFromDataKey(base64.StdEncoding.EncodeToString([]byte{0x01, 0xab, 0xff}))
+	assert.NoError(t, err)
+	assert.Equal(t, "01abff", secret)
+
+	_, err = clientSecretFromDataKey("not base64!")
+	assert.Error(t, err)
+}
@@ -942,6 +942,80 @@ func (svc *Service) createContractFirstTime(req *apistructs.CreateContractReq, a
 }
 
 func (svc *Service) CreateAccess(req *apistructs.CreateAccessReq) (map[string]interface{}, *errorresp.APIError) {
+	access, apiError := svc.createAccess(dbclient.Sq(), req)
+	if apiError != nil {
+		return nil, apiError
+	}
+
+	return map[string]interface{}{"access": access}, nil
+}
+
+// 单次批量创建访问管理条目的数量上限
+const maxBatchCreateAccess = 100
+
+// BatchCreateAccess 批量创建访问管理条目. 单个条目校验或创建失败不影响其他条目, 逐条返回结果;
+// 所有条目在同一事务中写入, 事务提交失败时回滚并删除已创建的流量入口.
+func (svc *Service) BatchCreateAccess(req *apistructs.BatchCreateAccessReq) (*apistructs.BatchCreateAccessRsp, *errorresp.APIError) {
+	if req == nil || req.Body == nil {
+		return nil, apierrors.BatchCreateAccess.InvalidParameter("invalid parameters")
+	}
+	if req.OrgID == 0 {
+		return nil, apierrors.BatchCreateAccess.InvalidParameter("invalid orgID")
+	}
+	if len(req.Body.Accesses) == 0 {
+		return nil, apierrors.BatchCreateAccess.MissingParameter("accesses")
+	}
+	if len(req.Body.Accesses) > maxBatchCreateAccess {
+		return nil, apierrors.BatchCreateAccess.InvalidParameter(fmt.Sprintf("一次最多创建 %d 个访问管理条目", maxBatchCreateAccess))
+	}
+
+	tx := dbclient.Tx()
+	defer tx.RollbackUnlessCommitted()
+
+	var (
+		rsp         = apistructs.BatchCreateAccessRsp{Total: uint64(len(req.Body.Accesses))}
+		endpointIDs []string
+	)
+	for i, body := range req.Body.Accesses {
+		result := &apistructs.BatchCreateAccessResult{Index: i}
+		rsp.Results = append(rsp.Results, result)
+		if body == nil {
+			result.Error = "invalid access"
+			rsp.FailureCount++
+			continue
+		}
+		result.AssetID = body.AssetID
+		result.Major = body.Major
+
+		access, apiError := svc.createAccess(tx.DB, &apistructs.CreateAccessReq{
+			OrgID:    req.OrgID,
+			Identity: req.Identity,
+			Body:     body,
+		})
+		if apiError != nil {
+			logrus.Errorf("failed to create access in batch, index: %d, assetID: %s, err: %v", i, body.AssetID, apiError)
+			result.Error = apiError.Error()
+			rsp.FailureCount++
+			continue
+		}
+		result.Success = true
+		result.Access = access
+		rsp.SuccessCount++
+		endpointIDs = append(endpointIDs, access.EndpointID)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		for _, endpointID := range endpointIDs {
+			_ = bdl.Bdl.DeleteEndpoint(endpointID)
+		}
+		return nil, apierrors.BatchCreateAccess.InternalError(errors.Wrap(err, "failed to commit"))
+	}
+
+	return &rsp, nil
+}
+
+// createAccess 创建访问管理条目及其流量入口, 条目写入 db; 写入失败时删除已创建的流量入口
+func (svc *Service) createAccess(db *gorm.DB, req *apistructs.CreateAccessReq) (*apistructs.APIAccessesModel, *errorresp.APIError) {
 	// 参数校验
 	if req == nil || req.Body == nil {
 		return nil, apierrors.CreateAccess.InvalidParameter("invalid parameters")
@@ -967,7 +1041,7 @@ func (svc *Service) CreateAccess(req *apistructs.CreateAccessReq) (map[string]in
 	swaggerVersion := version.SwaggerVersion
 
 	// 检查 access 是否已存在
-	switch err := dbclient.Sq().First(new(apistructs.APIAccessesModel), map[string]interface{}{
+	switch err := db.First(new(apistructs.APIAccessesModel), map[string]interface{}{
 		"org_id":          req.OrgID,
 		"asset_id":        req.Body.AssetID,
 		"swagger_version": swaggerVersion,
@@ -1092,12 +1166,12 @@ func (svc *Service) CreateAccess(req *apistructs.CreateAccessReq) (map[string]in
 		BindDomain:      strings.Join(req.Body.BindDomain, ","),
 		ProjectName:     project.Name,
 	}
-	if err := dbclient.Sq().Create(&access).Error; err != nil {
+	if err := db.Create(&access).Error; err != nil {
 		_ = bdl.Bdl.DeleteEndpoint(endpointID)
 		return nil, apierrors.CreateAccess.InternalError(errors.Wrap(err, "failed to CreateAccess"))
 	}
 
-	return map[string]interface{}{"access": access}, nil
+	return &access, nil
 }
 
 func (svc *Service) CreateSLA(req *apistructs.CreateSLAReq) *errorresp.APIError {
@@ -1326,6 +1400,8 @@ func (svc *Service) createOAS3IndexFragments(v3 *openapi3.Swagger, assetID, asse
 				Method:      method,
 				OperationID: operation.OperationID,
 				Description: operation.Description,
+				Summary:     operation.Summary,
+				Tags:        apistructs.JoinOAS3IndexTags(operation.Tags),
 			}
 			if create := tx.Create(&index); create.Error != nil {
 				continue
@@ -1394,9 +1470,10 @@ func parseSpec(protocol *apistructs.APISpecProtocol, spec string) (v3 *openapi3.
 			return nil, err
 		}
 	case "oas3":
+		// 3.1 文档在加载时转换为 3.0 兼容的结构
 		v3, err = oas3.LoadFromData(data)
 		if err != nil {
-			return nil, err
+			return nil, validateSpecErr(err)
 		}
 	default:
 		return nil, apierrors.ValidateAPISpec.InvalidParameter("specProtocol")
@@ -1407,11 +1484,19 @@ func parseSpec(protocol *apistructs.APISpecProtocol, spec string) (v3 *openapi3.
 	}
 
 	if err = oas3.ValidateOAS3(context.TODO(), *v3); err != nil {
-		return nil, err
+		return nil, validateSpecErr(err)
 	}
 	return v3, nil
 }
 
+// validateSpecErr 校验错误附带出错的位置
+func validateSpecErr(err error) error {
+	if ve, ok := err.(*oas3.ValidateError); ok {
+		return apierrors.ValidateAPISpec.InvalidParameter(err).AppendDetail(ve.Path(), ve.Reason())
+	}
+	return apierrors.ValidateAPISpec.InvalidParameter(err)
+}
+
 // parseVersionInstanceRequest 校验 instanceType 和 对应字段
 func validateVersionInstanceRequest(req apistructs.APIAssetVersionInstanceCreateRequest) error {
 	if !req.InstanceType.Valid() {
@@ -0,0 +1,162 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/bdl"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// patchCreateAccess 替换创建访问管理条目依赖的查询和网关调用, 返回已删除的流量入口
+func patchCreateAccess(svc *Service) *[]string {
+	if bdl.Bdl == nil {
+		bdl.Bdl = bundle.New()
+	}
+	var (
+		endpoints int
+		deleted   []string
+	)
+	monkey.PatchInstanceMethod(reflect.TypeOf(svc), "FirstRecord", func(_ *Service, model interface{}, where map[string]interface{}) error {
+		switch m := model.(type) {
+		case *apistructs.APIAssetsModel:
+			*m = apistructs.APIAssetsModel{AssetID: where["asset_id"].(string), AssetName: where["asset_id"].(string)}
+		case *apistructs.APIAssetVersionsModel:
+			*m = apistructs.APIAssetVersionsModel{SwaggerVersion: fmt.Sprintf("v%d", where["major"])}
+		}
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(svc), "GetInstantiation", func(_ *Service, _ *apistructs.GetInstantiationsReq) (*apistructs.InstantiationModel, bool, *errorresp.APIError) {
+		return &apistructs.InstantiationModel{Type: "other", URL: "http://svc.default:8080/api"}, true, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "CreateEndpoint", func(_ *bundle.Bundle, _, _ uint64, _ string, _ apistructs.PackageDto) (string, error) {
+		endpoints++
+		return fmt.Sprintf("endpoint-%d", endpoints), nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "CreateOrUpdateEndpointRootRoute", func(_ *bundle.Bundle, _, _, _ string) error {
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "GetProject", func(_ *bundle.Bundle, id uint64) (*apistructs.ProjectDTO, error) {
+		return &apistructs.ProjectDTO{ID: id, Name: "project"}, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "DeleteEndpoint", func(_ *bundle.Bundle, endpointID string) error {
+		deleted = append(deleted, endpointID)
+		return nil
+	})
+	return &deleted
+}
+
+func newBatchCreateAccessReq(accesses ...*apistructs.CreateAccessBody) *apistructs.BatchCreateAccessReq {
+	return &apistructs.BatchCreateAccessReq{
+		OrgID:    1,
+		Identity: &apistructs.IdentityInfo{UserID: "1"},
+		Body:     &apistructs.BatchCreateAccessBody{Accesses: accesses},
+	}
+}
+
+func TestBatchCreateAccessBatchSize(t *testing.T) {
+	svc := New()
+
+	_, err := svc.BatchCreateAccess(newBatchCreateAccessReq())
+	assert.NotNil(t, err)
+	assert.Equal(t, "MissingParameter", err.Code())
+
+	accesses := make([]*apistructs.CreateAccessBody, maxBatchCreateAccess+1)
+	_, err = svc.BatchCreateAccess(newBatchCreateAccessReq(accesses...))
+	assert.NotNil(t, err)
+	assert.Equal(t, "InvalidParameter", err.Code())
+}
+
+func TestBatchCreateAccess(t *testing.T) {
+	svc := New()
+	defer monkey.UnpatchAll()
+	deleted := patchCreateAccess(svc)
+	mock := newMockDB(t)
+
+	access := func(assetID string, authentication apistructs.Authentication) *apistructs.CreateAccessBody {
+		return &apistructs.CreateAccessBody{AssetID: assetID, Major: 1, ProjectID: 1, Workspace: "DEV", Authentication: authentication}
+	}
+	mock.ExpectBegin()
+	// asset-a 不存在访问管理条目, 创建成功
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+	// asset-b 的认证方式不支持, 不创建流量入口
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// 同一批次中重复的 asset-a 由事务内的查询发现
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	rsp, err := svc.BatchCreateAccess(newBatchCreateAccessReq(
+		access("asset-a", apistructs.AuthenticationKeyAuth),
+		nil,
+		access("asset-b", apistructs.AuthenticationOAuth2),
+		access("asset-a", apistructs.AuthenticationKeyAuth),
+	))
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, *deleted)
+
+	assert.Equal(t, uint64(4), rsp.Total)
+	assert.Equal(t, uint64(1), rsp.SuccessCount)
+	assert.Equal(t, uint64(3), rsp.FailureCount)
+	assert.Len(t, rsp.Results, 4)
+	for i, result := range rsp.Results {
+		assert.Equal(t, i, result.Index)
+	}
+	assert.True(t, rsp.Results[0].Success)
+	assert.Equal(t, "endpoint-1", rsp.Results[0].Access.EndpointID)
+	assert.Equal(t, "v1", rsp.Results[0].Access.SwaggerVersion)
+	assert.False(t, rsp.Results[1].Success)
+	assert.Equal(t, "invalid access", rsp.Results[1].Error)
+	assert.False(t, rsp.Results[2].Success)
+	assert.Equal(t, "asset-b", rsp.Results[2].AssetID)
+	assert.Contains(t, rsp.Results[2].Error, "OAuth2")
+	assert.False(t, rsp.Results[3].Success)
+	assert.Contains(t, rsp.Results[3].Error, "already exists")
+}
+
+func TestBatchCreateAccessCommitFailed(t *testing.T) {
+	svc := New()
+	defer monkey.UnpatchAll()
+	deleted := patchCreateAccess(svc)
+	mock := newMockDB(t)
+
+	mock.ExpectBegin()
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+	}
+	mock.ExpectCommit().WillReturnError(errors.New("connection lost"))
+
+	rsp, err := svc.BatchCreateAccess(newBatchCreateAccessReq(
+		&apistructs.CreateAccessBody{AssetID: "asset-a", Major: 1, Authentication: apistructs.AuthenticationKeyAuth},
+		&apistructs.CreateAccessBody{AssetID: "asset-b", Major: 2, Authentication: apistructs.AuthenticationSignAuth},
+	))
+	assert.Nil(t, rsp)
+	assert.NotNil(t, err)
+	assert.Equal(t, "InternalError", err.Code())
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, []string{"endpoint-1", "endpoint-2"}, *deleted)
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -359,6 +360,134 @@ func (svc *Service) DownloadSpecText(req *apistructs.DownloadSpecTextReq) ([]byt
 	}
 }
 
+// ExportPostmanCollection 将 API 资料版本的 swagger 导出为 Postman Collection v2.1
+func (svc *Service) ExportPostmanCollection(req *apistructs.DownloadSpecTextReq) ([]byte, *errorresp.APIError) {
+	// 参数校验
+	if req.OrgID == 0 {
+		return nil, apierrors.ExportPostmanCollection.MissingParameter(apierrors.MissingOrgID)
+	}
+
+	model, err := dbclient.GetAPIAssetVersionSpec(&apistructs.GetAPIAssetVersionReq{
+		OrgID:    req.OrgID,
+		Identity: req.Identity,
+		URIParams: &apistructs.AssetVersionDetailURI{
+			AssetID:   req.URIParams.AssetID,
+			VersionID: req.URIParams.VersionID,
+		},
+		QueryParams: &apistructs.GetAPIAssetVersionQueryParams{
+			Asset: false,
+			Spec:  true,
+		},
+	})
+	if err != nil {
+		return nil, apierrors.ExportPostmanCollection.InternalError(err)
+	}
+
+	v3, err := swagger.LoadFromData([]byte(model.Spec))
+	if err != nil {
+		return nil, apierrors.ExportPostmanCollection.InvalidParameter(err)
+	}
+	collection, err := oasconv.OAS3ConvToPostman(v3, "")
+	if err != nil {
+		return nil, apierrors.ExportPostmanCollection.InternalError(err)
+	}
+	if collection.Info.Name == "" {
+		collection.Info.Name = req.URIParams.AssetID
+	}
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return nil, apierrors.ExportPostmanCollection.InternalError(err)
+	}
+
+	return data, nil
+}
+
+// DiffAssetVersions 比较 API 资料的两个版本的 swagger, 返回结构化的接口变更及其兼容性
+func (svc *Service) DiffAssetVersions(req *apistructs.DiffAPIAssetVersionsReq) (*oas3.DiffReport, *errorresp.APIError) {
+	// 参数校验
+	if req.OrgID == 0 {
+		return nil, apierrors.DiffAssetVersions.MissingParameter(apierrors.MissingOrgID)
+	}
+	if req.BaseVersionID == 0 {
+		return nil, apierrors.DiffAssetVersions.MissingParameter("baseVersionID")
+	}
+
+	var specs = make([]*openapi3.Swagger, 0, 2)
+	for _, versionID := range []uint64{req.BaseVersionID, req.VersionID} {
+		model, err := dbclient.GetAPIAssetVersionSpec(&apistructs.GetAPIAssetVersionReq{
+			OrgID:    req.OrgID,
+			Identity: req.Identity,
+			URIParams: &apistructs.AssetVersionDetailURI{
+				AssetID:   req.AssetID,
+				VersionID: versionID,
+			},
+			QueryParams: &apistructs.GetAPIAssetVersionQueryParams{
+				Asset: false,
+				Spec:  true,
+			},
+		})
+		if err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, apierrors.DiffAssetVersions.NotFound()
+			}
+			return nil, apierrors.DiffAssetVersions.InternalError(err)
+		}
+		v3, err := swagger.LoadFromData([]byte(model.Spec))
+		if err != nil {
+			return nil, apierrors.DiffAssetVersions.InvalidParameter(errors.Wrapf(err, "versionID: %d", versionID))
+		}
+		specs = append(specs, v3)
+	}
+
+	return oas3.Diff(specs[0], specs[1]), nil
+}
+
+// MockAPIAssetVersion 按 API 资料版本的文档为请求生成 mock 响应
+func (svc *Service) MockAPIAssetVersion(req *apistructs.MockAPIAssetVersionReq) (*oas3.MockResponse, *errorresp.APIError) {
+	// 参数校验
+	if req.OrgID == 0 {
+		return nil, apierrors.MockAPIAssetVersion.MissingParameter(apierrors.MissingOrgID)
+	}
+
+	model, err := dbclient.GetAPIAssetVersionSpec(&apistructs.GetAPIAssetVersionReq{
+		OrgID:    req.OrgID,
+		Identity: req.Identity,
+		URIParams: &apistructs.AssetVersionDetailURI{
+			AssetID:   req.AssetID,
+			VersionID: req.VersionID,
+		},
+		QueryParams: &apistructs.GetAPIAssetVersionQueryParams{
+			Asset: false,
+			Spec:  true,
+		},
+	})
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.MockAPIAssetVersion.NotFound()
+		}
+		return nil, apierrors.MockAPIAssetVersion.InternalError(err)
+	}
+
+	v3, err := swagger.LoadFromData([]byte(model.Spec))
+	if err != nil {
+		return nil, apierrors.MockAPIAssetVersion.InternalError(err)
+	}
+
+	_, operation := oas3.FindOperation(v3, req.Method, req.Path)
+	if operation == nil {
+		return nil, apierrors.MockAPIAssetVersion.NotFound()
+	}
+	mock, err := oas3.Mock(operation, req.Status, req.Accept)
+	if err != nil {
+		return nil, apierrors.MockAPIAssetVersion.InvalidParameter(err)
+	}
+	if mock.Latency, err = oas3.MockLatency(operation, req.Latency); err != nil {
+		return nil, apierrors.MockAPIAssetVersion.InvalidParameter(err)
+	}
+
+	return mock, nil
+}
+
 func (svc *Service) GetMyClient(req *apistructs.GetClientReq) (*apistructs.ClientObj, *errorresp.APIError) {
 	// 参数校验
 	if req.OrgID == 0 {
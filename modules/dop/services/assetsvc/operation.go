@@ -27,10 +27,35 @@ import (
 )
 
 func (svc *Service) SearchOperations(req *apistructs.SearchOperationsReq) (results []*apistructs.APIOAS3IndexModel, apiError *errorresp.APIError) {
+	_, versionIDs, err := svc.searchableVersions(req.OrgID, req.Identity)
+	if err != nil {
+		return nil, apierrors.SearchOperations.InternalError(err)
+	}
+	if len(versionIDs) == 0 {
+		return nil, nil
+	}
+
+	// 在筛选出的 version 下搜索
+	keyword := "%" + req.QueryParams.Keyword + "%"
+	if find := dbclient.Sq().Where("asset_id like ? OR asset_name like ? OR operation_id like ? OR path like ? OR description like ?",
+		keyword, keyword, keyword, keyword, keyword).
+		Where("version_id IN (?)", versionIDs).
+		Find(&results); find.Error != nil {
+		if gorm.IsRecordNotFoundError(find.Error) {
+			return nil, nil
+		}
+		return nil, apierrors.SearchOperations.InternalError(find.Error)
+	}
+
+	return results, nil
+}
+
+// searchableVersions 查询用户可以查看的 API 资料, 及这些资料每个 swaggerVersion 下的最新版本
+func (svc *Service) searchableVersions(orgID uint64, identity *apistructs.IdentityInfo) ([]*apistructs.PagingAssetRspObj, []uint64, error) {
 	// 查询用户可以查看 API 集市
 	response, err := svc.PagingAsset(apistructs.PagingAPIAssetsReq{
-		OrgID:    req.OrgID,
-		Identity: req.Identity,
+		OrgID:    orgID,
+		Identity: identity,
 		QueryParams: &apistructs.PagingAPIAssetsQueryParams{
 			Paging:        false,
 			PageNo:        0,
@@ -44,11 +69,11 @@ func (svc *Service) SearchOperations(req *apistructs.SearchOperationsReq) (resul
 		},
 	})
 	if err != nil {
-		return nil, apierrors.SearchOperations.InternalError(err)
+		return nil, nil, err
 	}
 
 	if response.Total == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	var assetIDs []string
@@ -58,15 +83,15 @@ func (svc *Service) SearchOperations(req *apistructs.SearchOperationsReq) (resul
 
 	// 查询这些集市下的所有版本
 	var versions []*apistructs.APIAssetVersionsModel
-	if find := dbclient.Sq().Where("org_id = ?", req.OrgID).
+	if find := dbclient.Sq().Where("org_id = ?", orgID).
 		Where("deprecated = ?", false).
 		Where("asset_id IN (?)", assetIDs).
 		Order("swagger_version DESC, major DESC, minor DESC, patch DESC").
 		Find(&versions); find.Error != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			return nil, nil
+		if gorm.IsRecordNotFoundError(find.Error) {
+			return response.List, nil, nil
 		}
-		return nil, apierrors.SearchOperations.InternalError(err)
+		return nil, nil, find.Error
 	}
 
 	// 筛选出每个 swaggerVersion 下的最新版本
@@ -96,19 +121,7 @@ func (svc *Service) SearchOperations(req *apistructs.SearchOperationsReq) (resul
 		versionIDs = append(versionIDs, v.ID)
 	}
 
-	// 在筛选出的 version 下搜索
-	keyword := "%" + req.QueryParams.Keyword + "%"
-	if find := dbclient.Sq().Where("asset_id like ? OR asset_name like ? OR operation_id like ? OR path like ? OR description like ?",
-		keyword, keyword, keyword, keyword, keyword).
-		Where("version_id IN (?)", versionIDs).
-		Find(&results); find.Error != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			return nil, nil
-		}
-		return nil, apierrors.SearchOperations.InternalError(err)
-	}
-
-	return results, nil
+	return response.List, versionIDs, nil
 }
 
 // node 包含 assert_id, info_version, path, method 四个字段的信息
@@ -0,0 +1,233 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+const (
+	searchDefaultPageSize = 20
+	searchMaxPageSize     = 100
+
+	// 高亮片段中命中部分前后保留的字符数
+	searchFragmentContext = 30
+)
+
+// 各字段命中时的权重, 名称和摘要比描述更能代表搜索意图
+var searchFieldWeights = map[string]int{
+	"assetName":   10,
+	"summary":     8,
+	"path":        6,
+	"operationID": 6,
+	"assetID":     5,
+	"method":      3,
+	"version":     3,
+	"description": 1,
+	"desc":        1,
+}
+
+// SearchAPIAssets 在用户可见的 API 资料及其接口中搜索, 结果按相关度排序并分页
+func (svc *Service) SearchAPIAssets(req *apistructs.SearchAPIAssetsReq) (*apistructs.SearchAPIAssetsRsp, *errorresp.APIError) {
+	keywords := strings.Fields(strings.ToLower(req.QueryParams.Keyword))
+	if len(keywords) == 0 {
+		return nil, apierrors.SearchAPIAssets.MissingParameter("keyword")
+	}
+
+	assets, versionIDs, err := svc.searchableVersions(req.OrgID, req.Identity)
+	if err != nil {
+		return nil, apierrors.SearchAPIAssets.InternalError(err)
+	}
+
+	var results []*apistructs.SearchAPIAssetsResult
+
+	// 按 tag 过滤时只搜索接口
+	if req.QueryParams.Tag == "" {
+		for _, obj := range assets {
+			asset := obj.Asset
+			result := matchSearchFields(keywords, []searchField{
+				{"assetName", asset.AssetName},
+				{"assetID", asset.AssetID},
+				{"desc", asset.Desc},
+			})
+			if result == nil {
+				continue
+			}
+			result.Type = apistructs.SearchAPIAssetsResultTypeAsset
+			result.AssetID = asset.AssetID
+			result.AssetName = asset.AssetName
+			result.VersionID = asset.CurVersionID
+			results = append(results, result)
+		}
+	}
+
+	if len(versionIDs) > 0 {
+		var indexes []*apistructs.APIOAS3IndexModel
+		sq := dbclient.Sq().Where("version_id IN (?)", versionIDs)
+		for _, keyword := range keywords {
+			like := "%" + keyword + "%"
+			sq = sq.Where("asset_name LIKE ? OR info_version LIKE ? OR path LIKE ? OR method LIKE ? OR operation_id LIKE ? OR summary LIKE ? OR description LIKE ?",
+				like, like, like, like, like, like, like)
+		}
+		if req.QueryParams.Tag != "" {
+			sq = sq.Where("tags LIKE ?", "%,"+req.QueryParams.Tag+",%")
+		}
+		if find := sq.Find(&indexes); find.Error != nil && !gorm.IsRecordNotFoundError(find.Error) {
+			return nil, apierrors.SearchAPIAssets.InternalError(find.Error)
+		}
+
+		for _, index := range indexes {
+			result := matchSearchFields(keywords, []searchField{
+				{"assetName", index.AssetName},
+				{"version", index.InfoVersion},
+				{"path", index.Path},
+				{"method", index.Method},
+				{"operationID", index.OperationID},
+				{"summary", index.Summary},
+				{"description", index.Description},
+			})
+			if result == nil {
+				continue
+			}
+			result.Type = apistructs.SearchAPIAssetsResultTypeOperation
+			result.AssetID = index.AssetID
+			result.AssetName = index.AssetName
+			result.VersionID = index.VersionID
+			result.Version = index.InfoVersion
+			result.IndexID = index.ID
+			result.Path = index.Path
+			result.Method = index.Method
+			result.Summary = index.Summary
+			results = append(results, result)
+		}
+	}
+
+	sortSearchResults(results)
+
+	pageNo, pageSize := req.QueryParams.PageNo, req.QueryParams.PageSize
+	if pageNo == 0 {
+		pageNo = 1
+	}
+	if pageSize == 0 {
+		pageSize = searchDefaultPageSize
+	}
+	if pageSize > searchMaxPageSize {
+		pageSize = searchMaxPageSize
+	}
+	var rsp = apistructs.SearchAPIAssetsRsp{Total: len(results), List: []*apistructs.SearchAPIAssetsResult{}}
+	if begin := (pageNo - 1) * pageSize; begin < uint64(len(results)) {
+		end := begin + pageSize
+		if end > uint64(len(results)) {
+			end = uint64(len(results))
+		}
+		rsp.List = results[begin:end]
+	}
+
+	return &rsp, nil
+}
+
+type searchField struct {
+	name  string
+	value string
+}
+
+// matchSearchFields 计算关键字在各字段上的命中情况, 每个关键字都须命中至少一个字段, 否则返回 nil.
+// 完全匹配和前缀匹配的得分高于包含匹配.
+func matchSearchFields(keywords []string, fields []searchField) *apistructs.SearchAPIAssetsResult {
+	var (
+		result  apistructs.SearchAPIAssetsResult
+		matched = make(map[string]bool)
+	)
+	for _, keyword := range keywords {
+		var hit bool
+		for _, field := range fields {
+			value := strings.ToLower(field.value)
+			pos := strings.Index(value, keyword)
+			if pos < 0 {
+				continue
+			}
+			hit = true
+			weight := searchFieldWeights[field.name]
+			switch {
+			case value == keyword:
+				result.Score += weight * 3
+			case pos == 0:
+				result.Score += weight * 2
+			default:
+				result.Score += weight
+			}
+			if !matched[field.name] {
+				matched[field.name] = true
+				result.Highlights = append(result.Highlights, &apistructs.SearchHighlight{
+					Field:    field.name,
+					Fragment: highlightFragment(field.value, pos, len(keyword)),
+				})
+			}
+		}
+		if !hit {
+			return nil
+		}
+	}
+	sort.SliceStable(result.Highlights, func(i, j int) bool {
+		return searchFieldWeights[result.Highlights[i].Field] > searchFieldWeights[result.Highlights[j].Field]
+	})
+	return &result
+}
+
+// highlightFragment 以 <em></em> 包裹 value 中 [pos, pos+length) 的部分, 过长时截取命中部分前后的上下文.
+// 片段作为 HTML 展示, 字段内容中的 HTML 字符在截取后转义, 只保留 <em> 标签
+func highlightFragment(value string, pos, length int) string {
+	// strings.ToLower 可能改变非 ASCII 字符的字节长度, 此时 pos 不再可靠
+	if len(strings.ToLower(value)) != len(value) || pos+length > len(value) {
+		return html.EscapeString(value)
+	}
+	prefix, hit, suffix := value[:pos], value[pos:pos+length], value[pos+length:]
+	if r := []rune(prefix); len(r) > searchFragmentContext {
+		prefix = "..." + string(r[len(r)-searchFragmentContext:])
+	}
+	if r := []rune(suffix); len(r) > searchFragmentContext {
+		suffix = string(r[:searchFragmentContext]) + "..."
+	}
+	return html.EscapeString(prefix) + "<em>" + html.EscapeString(hit) + "</em>" + html.EscapeString(suffix)
+}
+
+// sortSearchResults 按得分降序排列, 得分相同时 API 资料在前, 再按资料名称, 路径, 方法排序
+func sortSearchResults(results []*apistructs.SearchAPIAssetsResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Type != b.Type {
+			return a.Type == apistructs.SearchAPIAssetsResultTypeAsset
+		}
+		if a.AssetName != b.AssetName {
+			return a.AssetName < b.AssetName
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestMatchSearchFields(t *testing.T) {
+	fields := []searchField{
+		{"assetName", "Pet Store"},
+		{"path", "/pet/{petId}"},
+		{"summary", "Find pet by ID"},
+	}
+
+	result := matchSearchFields([]string{"pet", "find"}, fields)
+	assert.NotNil(t, result)
+	assert.Equal(t, "assetName", result.Highlights[0].Field)
+	assert.Equal(t, "<em>Pet</em> Store", result.Highlights[0].Fragment)
+	assert.Equal(t, "summary", result.Highlights[1].Field)
+
+	// 每个关键字都须命中
+	assert.Nil(t, matchSearchFields([]string{"pet", "order"}, fields))
+}
+
+func TestHighlightFragment(t *testing.T) {
+	value := "this operation returns a single pet identified by the id passed in the path of the request"
+	assert.Equal(t, "...ns a single pet identified by <em>the</em> id passed in the path of the ...",
+		highlightFragment(value, 50, 3))
+
+	// 字段内容中的 HTML 转义后再高亮
+	assert.Equal(t, "&lt;img src=x onerror=alert(1)&gt; <em>pet</em> &amp; store",
+		highlightFragment("<img src=x onerror=alert(1)> pet & store", 29, 3))
+	assert.Equal(t, "&lt;b&gt;İ&lt;/b&gt;", highlightFragment("<b>İ</b>", 0, 3))
+}
+
+func TestSortSearchResults(t *testing.T) {
+	results := []*apistructs.SearchAPIAssetsResult{
+		{Type: apistructs.SearchAPIAssetsResultTypeOperation, Score: 10, Path: "/b"},
+		{Type: apistructs.SearchAPIAssetsResultTypeOperation, Score: 20, Path: "/a"},
+		{Type: apistructs.SearchAPIAssetsResultTypeAsset, Score: 10},
+	}
+	sortSearchResults(results)
+	assert.Equal(t, "/a", results[0].Path)
+	assert.Equal(t, apistructs.SearchAPIAssetsResultTypeAsset, results[1].Type)
+	assert.Equal(t, "/b", results[2].Path)
+}
@@ -387,56 +387,169 @@ func (svc *Service) UpdateContract(req *apistructs.UpdateContractReq) (*apistruc
 	return &client, &contract, nil
 }
 
-// the manager modifies the contract status (approve the contract)
-func (svc *Service) updateContractStatus(req *apistructs.UpdateContractReq, client *apistructs.ClientModel, access *apistructs.APIAccessesModel,
-	contract *apistructs.ContractModel) error {
-	if req.Body.Status == nil {
-		return nil
+// ApproveContract 管理人员通过待审批的调用申请
+func (svc *Service) ApproveContract(req *apistructs.ContractApprovalReq) (*apistructs.ContractModel, *errorresp.APIError) {
+	return svc.approveOrRejectContract(req, apistructs.ContractApproved, apierrors.ApproveContract)
+}
+
+// RejectContract 管理人员拒绝待审批的调用申请, 并释放申请时预占的 SLA
+func (svc *Service) RejectContract(req *apistructs.ContractApprovalReq) (*apistructs.ContractModel, *errorresp.APIError) {
+	if req.Body == nil || strings.TrimSpace(req.Body.Reason) == "" {
+		return nil, apierrors.RejectContract.MissingParameter("reason")
 	}
+	return svc.approveOrRejectContract(req, apistructs.ContractDisapproved, apierrors.RejectContract)
+}
 
-	// do something depends on contract status
-	status := req.Body.Status.ToLower()
-	switch status {
-	case apistructs.ContractApproved:
-		// the manager change the contract status to "approved", call the api-gateway to grant to the client
-		if err := bdl.Bdl.GrantEndpointToClient(client.ClientID, access.EndpointID); err != nil {
-			return err
+func (svc *Service) approveOrRejectContract(req *apistructs.ContractApprovalReq, status apistructs.ContractStatus,
+	apiError *errorresp.APIError) (*apistructs.ContractModel, *errorresp.APIError) {
+	if req == nil || req.URIParams == nil {
+		return nil, apiError.InvalidParameter("invalid parameter")
+	}
+	if req.Body == nil {
+		req.Body = new(apistructs.ContractApprovalBody)
+	}
+	contractID, err := strconv.ParseUint(req.URIParams.ContractID, 10, 64)
+	if err != nil {
+		return nil, apiError.InvalidParameter("invalid contract id")
+	}
+
+	var (
+		client   apistructs.ClientModel
+		asset    apistructs.APIAssetsModel
+		contract apistructs.ContractModel
+		access   apistructs.APIAccessesModel
+	)
+	if err := svc.FirstRecord(&client, map[string]interface{}{
+		"org_id": req.OrgID,
+		"id":     req.URIParams.ClientID,
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apiError.NotFound()
 		}
-	case apistructs.ContractDisapproved:
-		// do nothing with api-gateway
-	case apistructs.ContractUnapproved:
-		//  call the api-gateway to revoke the grant
-		if err := bdl.Bdl.RevokeEndpointFromClient(client.ClientID, access.EndpointID); err != nil {
-			return err
+		return nil, apiError.InternalError(err)
+	}
+	if err := svc.FirstRecord(&contract, map[string]interface{}{
+		"org_id":    req.OrgID,
+		"id":        contractID,
+		"client_id": client.ID,
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apiError.NotFound()
 		}
-	default:
-		return errors.New("invalid contract status")
+		return nil, apiError.InternalError(err)
+	}
+	if err := svc.FirstRecord(&asset, map[string]interface{}{
+		"org_id":   req.OrgID,
+		"asset_id": contract.AssetID,
+	}); err != nil {
+		logrus.Errorf("failed to FirstRecord asset, err: %v", err)
+		return nil, apiError.InternalError(err)
 	}
 
-	timeNow := time.Now()
-	tx := dbclient.Tx()
-	defer tx.RollbackUnlessCommitted()
+	// 只有具备 API 资料写权限的管理人员可以审批
+	rolesSet := bdl.FetchAssetRolesSet(req.OrgID, req.Identity.UserID)
+	if !writePermission(rolesSet, &asset) {
+		return nil, apiError.AccessDenied()
+	}
 
-	if err := tx.Model(contract).
-		Where(map[string]interface{}{"org_id": req.OrgID, "id": req.URIParams.ContractID}).
-		Updates(map[string]interface{}{"status": status, "updated_at": timeNow}).
-		Error; err != nil {
-		return errors.Wrap(err, "failed to Updates contractModel")
+	if contract.Status.ToLower() != apistructs.ContractApproving {
+		return nil, apiError.InvalidState(fmt.Sprintf("调用申请当前状态为 %s, 只能审批待审批的调用申请", contract.Status))
 	}
 
-	action := status2Action(status)
-	if err := tx.Create(&apistructs.ContractRecordModel{
-		ID:         0,
+	if err := svc.FirstRecord(&access, map[string]interface{}{
+		"org_id":          req.OrgID,
+		"asset_id":        contract.AssetID,
+		"swagger_version": contract.SwaggerVersion,
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apiError.InternalError(errors.New("access not found"))
+		}
+		return nil, apiError.InternalError(err)
+	}
+
+	timeNow := time.Now()
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": timeNow,
+		"updater_id": req.Identity.UserID,
+	}
+	// 拒绝时释放申请时预占的 SLA
+	if status == apistructs.ContractDisapproved {
+		updates["cur_sla_id"] = nil
+		updates["request_sla_id"] = nil
+		updates["sla_committed_at"] = nil
+	}
+	action := fmt.Sprintf("%s对该调用申请的授权", status2Action(status))
+	if reason := strings.TrimSpace(req.Body.Reason); reason != "" {
+		action += fmt.Sprintf(", 理由: %s", reason)
+	}
+	record := apistructs.ContractRecordModel{
 		OrgID:      req.OrgID,
 		ContractID: contract.ID,
-		Action:     fmt.Sprintf("%s对该调用申请的授权", action),
+		Action:     action,
 		CreatorID:  req.Identity.UserID,
 		CreatedAt:  timeNow,
-	}).Error; err != nil {
-		return errors.Wrap(err, "failed to Create contractRecordModel")
 	}
 
-	tx.Commit()
+	// 只更新仍处于待审批状态的调用申请, 避免并发审批时重复授权或覆盖他人的审批结果
+	if err := commitContractStatus(req.OrgID, contract.ID, apistructs.ContractApproving, updates, &record,
+		contractGatewayOperation(status, client.ClientID, access.EndpointID)); err != nil {
+		if err == errContractStatusChanged {
+			return nil, apiError.InvalidState("调用申请已被审批")
+		}
+		logrus.Errorf("failed to commit contract status, err: %v", err)
+		return nil, apiError.InternalError(err)
+	}
+
+	contract.Status = status
+	contract.UpdatedAt = timeNow
+	contract.UpdaterID = req.Identity.UserID
+	if status == apistructs.ContractDisapproved {
+		contract.CurSLAID = nil
+		contract.RequestSLAID = nil
+		contract.SLACommittedAt = nil
+	}
+
+	if status == apistructs.ContractApproved {
+		if err := svc.createOrUpdateClientLimits(access.EndpointID, client.ClientID, contract.ID); err != nil {
+			logrus.Errorf("createOrUpdateClientLimits failed, err:%+v", err)
+		}
+	}
+
+	go svc.contractMsgToUser(req.OrgID, contract.CreatorID, access.AssetName, &client, ApprovalResultFromStatus(status))
+
+	return &contract, nil
+}
+
+// the manager modifies the contract status (approve the contract)
+func (svc *Service) updateContractStatus(req *apistructs.UpdateContractReq, client *apistructs.ClientModel, access *apistructs.APIAccessesModel,
+	contract *apistructs.ContractModel) error {
+	if req.Body.Status == nil {
+		return nil
+	}
+
+	status := req.Body.Status.ToLower()
+	switch status {
+	case apistructs.ContractApproved, apistructs.ContractDisapproved, apistructs.ContractUnapproved:
+	default:
+		return errors.New("invalid contract status")
+	}
+
+	timeNow := time.Now()
+	if err := commitContractStatus(req.OrgID, contract.ID, "",
+		map[string]interface{}{"status": status, "updated_at": timeNow},
+		&apistructs.ContractRecordModel{
+			OrgID:      req.OrgID,
+			ContractID: contract.ID,
+			Action:     fmt.Sprintf("%s对该调用申请的授权", status2Action(status)),
+			CreatorID:  req.Identity.UserID,
+			CreatedAt:  timeNow,
+		},
+		contractGatewayOperation(status, client.ClientID, access.EndpointID)); err != nil {
+		return err
+	}
+	contract.Status = status
+	contract.UpdatedAt = timeNow
 
 	// notification by mail and in-site letter
 	go svc.contractMsgToUser(req.OrgID, contract.CreatorID, access.AssetName, client, ApprovalResultFromStatus(status))
@@ -444,6 +557,54 @@ func (svc *Service) updateContractStatus(req *apistructs.UpdateContractReq, clie
 	return nil
 }
 
+// errContractStatusChanged 调用申请的状态已被他人修改
+var errContractStatusChanged = errors.New("contract status changed")
+
+// contractGatewayOperation 调用申请变更为 status 时需要在网关上执行的操作:
+// 通过时授权客户端访问, 撤销时收回授权, 其余状态不需要操作网关
+func contractGatewayOperation(status apistructs.ContractStatus, clientID, endpointID string) func() error {
+	switch status {
+	case apistructs.ContractApproved:
+		return func() error { return bdl.Bdl.GrantEndpointToClient(clientID, endpointID) }
+	case apistructs.ContractUnapproved:
+		return func() error { return bdl.Bdl.RevokeEndpointFromClient(clientID, endpointID) }
+	default:
+		return nil
+	}
+}
+
+// commitContractStatus 在事务中更新调用申请的状态并新增操作记录, 随后执行网关操作, 网关操作失败时不提交.
+// fromStatus 非空时只更新当前处于该状态的调用申请, 未更新到时返回 errContractStatusChanged
+func commitContractStatus(orgID, contractID uint64, fromStatus apistructs.ContractStatus, updates map[string]interface{},
+	record *apistructs.ContractRecordModel, gateway func() error) error {
+	tx := dbclient.Tx()
+	defer tx.RollbackUnlessCommitted()
+
+	where := map[string]interface{}{"org_id": orgID, "id": contractID}
+	if fromStatus != "" {
+		where["status"] = fromStatus
+	}
+	result := tx.Model(new(apistructs.ContractModel)).Where(where).Updates(updates)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "failed to Updates contract")
+	}
+	if fromStatus != "" && result.RowsAffected == 0 {
+		return errContractStatusChanged
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return errors.Wrap(err, "failed to Create contract record")
+	}
+	if gateway != nil {
+		if err := gateway(); err != nil {
+			return errors.Wrap(err, "failed to operate api gateway")
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errors.Wrap(err, "failed to commit")
+	}
+	return nil
+}
+
 // 管理人员修改合约的 SLA
 func (svc *Service) updateContractCurSLA(req *apistructs.UpdateContractReq, contract *apistructs.ContractModel, client *apistructs.ClientModel,
 	access *apistructs.APIAccessesModel) error {
@@ -824,6 +985,13 @@ func (svc *Service) UpdateSLA(req *apistructs.UpdateSLAReq) *errorresp.APIError
 		}
 	}
 
+	// 查出原有的 limit, 网关同步失败时用于恢复已同步的客户端
+	var oldLimit *apistructs.SLALimitModel
+	var exLimit apistructs.SLALimitModel
+	if err := svc.FirstRecord(&exLimit, map[string]interface{}{"sla_id": sla.ID}); err == nil {
+		oldLimit = &exLimit
+	}
+
 	tx := dbclient.Tx()
 	defer tx.RollbackUnlessCommitted()
 
@@ -852,7 +1020,7 @@ func (svc *Service) UpdateSLA(req *apistructs.UpdateSLAReq) *errorresp.APIError
 		logrus.Errorf("failed to Delete SLALimitModel, err: %v", err)
 		return apierrors.DeleteSLA.InternalError(errors.New("覆盖原有限制条件失败"))
 	}
-	if err := tx.Create(&apistructs.SLALimitModel{
+	var limitModel = apistructs.SLALimitModel{
 		BaseModel: apistructs.BaseModel{
 			ID:        0,
 			CreatedAt: timeNow,
@@ -863,7 +1031,8 @@ func (svc *Service) UpdateSLA(req *apistructs.UpdateSLAReq) *errorresp.APIError
 		SLAID: sla.ID,
 		Limit: limit.Limit,
 		Unit:  limit.Unit,
-	}).Error; err != nil {
+	}
+	if err := tx.Create(&limitModel).Error; err != nil {
 		logrus.Errorf("failed to Create limits, err: %v", err)
 		return apierrors.UpdateSLA.InternalError(errors.New("更新限制条件失败"))
 	}
@@ -884,9 +1053,22 @@ func (svc *Service) UpdateSLA(req *apistructs.UpdateSLAReq) *errorresp.APIError
 		}
 	}
 
-	tx.Commit()
+	// 调用 hepa 依赖, 更新网关侧流量限制. 网关拒绝时不提交本次修改, 以免 SLA 与实际生效的限制不一致
+	if err := svc.syncSLALimits(access.EndpointID, sla.ID, &limitModel, oldLimit); err != nil {
+		logrus.Errorf("failed to syncSLALimits, slaID: %d, err: %v", sla.ID, err)
+		return slaLimitsSyncError(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logrus.Errorf("failed to commit, slaID: %d, err: %v", sla.ID, err)
+		// 提交失败时将网关恢复为修改前的流量限制
+		if revertErr := svc.syncSLALimits(access.EndpointID, sla.ID, oldLimit, &limitModel); revertErr != nil {
+			logrus.Errorf("failed to revert client limits, slaID: %d, err: %v", sla.ID, revertErr)
+		}
+		return apierrors.UpdateSLA.InternalError(errors.New("更新SLA失败"))
+	}
 
-	// 调用 hepa 依赖, 更新网关侧流量限制
+	// 推送消息
 	go func() {
 		// 查询受影响的客户端列表
 		affectedClients, affectedContracts, err := svc.slaAffectClients(sla.ID)
@@ -895,18 +1077,13 @@ func (svc *Service) UpdateSLA(req *apistructs.UpdateSLAReq) *errorresp.APIError
 			return
 		}
 
-		// 推送消息
-		sentContractIDs := make(map[uint64]bool)
+		clientsM := make(map[uint64]*apistructs.ClientModel, len(affectedClients))
 		for _, affectedClient := range affectedClients {
-			for _, affectedContract := range affectedContracts {
-				if _, ok := sentContractIDs[affectedContract.ID]; ok {
-					continue
-				}
+			clientsM[affectedClient.ID] = affectedClient
+		}
+		for _, affectedContract := range affectedContracts {
+			if affectedClient, ok := clientsM[affectedContract.ClientID]; ok {
 				svc.contractMsgToUser(req.OrgID, affectedContract.CreatorID, asset.AssetName, affectedClient, ManagerRewriteSLA(sla.Name))
-				err = svc.createOrUpdateClientLimits(access.EndpointID, affectedClient.ClientID, affectedContract.ID)
-				if err != nil {
-					logrus.Errorf("createOrUpdateClientLimits failed, err:%+v", err)
-				}
 			}
 		}
 	}()
@@ -914,6 +1091,14 @@ func (svc *Service) UpdateSLA(req *apistructs.UpdateSLAReq) *errorresp.APIError
 	return nil
 }
 
+// slaLimitsSyncError 网关拒绝流量限制配置 (4xx) 时视为参数错误, 其余为内部错误
+func slaLimitsSyncError(err error) *errorresp.APIError {
+	if apiError, ok := errors.Cause(err).(*errorresp.APIError); ok && apiError.HttpCode() >= 400 && apiError.HttpCode() < 500 {
+		return apierrors.UpdateSLA.InvalidParameter(errors.Errorf("网关拒绝了流量限制配置: %v", err))
+	}
+	return apierrors.UpdateSLA.InternalError(errors.Wrap(err, "同步网关流量限制失败"))
+}
+
 // 修改 asset version
 func (svc *Service) UpdateAssetVersion(req *apistructs.UpdateAssetVersionReq) (*apistructs.APIAssetVersionsModel, *errorresp.APIError) {
 	if req == nil || req.URIParams == nil {
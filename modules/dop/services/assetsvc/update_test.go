@@ -0,0 +1,242 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assetsvc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/bdl"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+// newMockDB 使用 sqlmock 替换全局的 dbclient.DB, 测试结束后恢复
+func newMockDB(t *testing.T) sqlmock.Sqlmock {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db, err := gorm.Open("mysql", sqlDB)
+	assert.NoError(t, err)
+	origin := dbclient.DB
+	dbclient.DB = &dbclient.DBClient{DBEngine: &dbengine.DBEngine{DB: db}}
+	t.Cleanup(func() {
+		dbclient.DB = origin
+		db.Close()
+	})
+	return mock
+}
+
+func TestRejectContractWithoutReason(t *testing.T) {
+	svc := New()
+	for _, body := range []*apistructs.ContractApprovalBody{nil, {}, {Reason: "  "}} {
+		_, err := svc.RejectContract(&apistructs.ContractApprovalReq{
+			OrgID:     1,
+			Identity:  &apistructs.IdentityInfo{UserID: "1"},
+			URIParams: &apistructs.UpdateContractURIParams{ClientID: "1", ContractID: "1"},
+			Body:      body,
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, "MissingParameter", err.Code())
+	}
+}
+
+func TestApproveOrRejectContract(t *testing.T) {
+	var (
+		svc           = New()
+		contract      apistructs.ContractModel
+		written       bool
+		commitErr     error
+		committed     bool
+		limitsUpdated bool
+		fromStatus    apistructs.ContractStatus
+		updates       map[string]interface{}
+		record        *apistructs.ContractRecordModel
+	)
+	monkey.PatchInstanceMethod(reflect.TypeOf(svc), "FirstRecord", func(_ *Service, model interface{}, _ map[string]interface{}) error {
+		switch m := model.(type) {
+		case *apistructs.ClientModel:
+			*m = apistructs.ClientModel{ClientID: "client-a"}
+		case *apistructs.ContractModel:
+			*m = contract
+			if committed && commitErr == nil {
+				m.Status = updates["status"].(apistructs.ContractStatus)
+			}
+		case *apistructs.APIAssetsModel:
+			*m = apistructs.APIAssetsModel{AssetID: "asset-a"}
+		case *apistructs.APIAccessesModel:
+			*m = apistructs.APIAccessesModel{EndpointID: "endpoint-a"}
+		}
+		return nil
+	})
+	monkey.Patch(bdl.FetchAssetRolesSet, func(_ uint64, _ string) *bdl.RolesSet { return &bdl.RolesSet{} })
+	monkey.Patch(writePermission, func(_ *bdl.RolesSet, _ *apistructs.APIAssetsModel) bool { return written })
+	monkey.Patch(commitContractStatus, func(_, _ uint64, from apistructs.ContractStatus, u map[string]interface{},
+		r *apistructs.ContractRecordModel, _ func() error) error {
+		committed, fromStatus, updates, record = true, from, u, r
+		return commitErr
+	})
+	// 通过后按当前 SLA 设置网关流量限制, 并异步通知申请人
+	if bdl.Bdl == nil {
+		bdl.Bdl = bundle.New()
+	}
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "CreateOrUpdateClientLimits", func(_ *bundle.Bundle, _, _ string, _ []apistructs.LimitType) error {
+		limitsUpdated = true
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl.Bdl), "GetOrg", func(_ *bundle.Bundle, _ interface{}) (*apistructs.OrgDTO, error) {
+		return nil, errors.New("org not found")
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(svc), "MboxNotify", func(_ *Service, _, _ string, _ map[string]string, _ string, _ uint64, _ []string) error {
+		return nil
+	})
+	defer monkey.UnpatchAll()
+
+	slaID := uint64(2)
+	tests := []struct {
+		name      string
+		reject    bool
+		status    apistructs.ContractStatus
+		written   bool
+		commitErr error
+		wantCode  string
+		wantLimit bool
+	}{
+		{name: "approve", status: apistructs.ContractApproving, written: true, wantLimit: true},
+		{name: "reject", reject: true, status: apistructs.ContractApproving, written: true},
+		{name: "reject not approving", reject: true, status: apistructs.ContractApproved, written: true, wantCode: "InvalidState"},
+		{name: "permission denied", status: apistructs.ContractApproving, wantCode: "AccessDenied"},
+		{name: "approved concurrently", status: apistructs.ContractApproving, written: true, commitErr: errContractStatusChanged, wantCode: "InvalidState"},
+		{name: "gateway failed", status: apistructs.ContractApproving, written: true, commitErr: errors.New("gateway error"), wantCode: "InternalError"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contract = apistructs.ContractModel{BaseModel: apistructs.BaseModel{ID: 1}, Status: tt.status, RequestSLAID: &slaID}
+			written, commitErr = tt.written, tt.commitErr
+			committed, limitsUpdated, updates, record = false, false, nil, nil
+
+			req := &apistructs.ContractApprovalReq{
+				OrgID:     1,
+				Identity:  &apistructs.IdentityInfo{UserID: "1"},
+				URIParams: &apistructs.UpdateContractURIParams{ClientID: "1", ContractID: "1"},
+				Body:      &apistructs.ContractApprovalBody{Reason: "not allowed"},
+			}
+			var (
+				result *apistructs.ContractModel
+				err    *errorresp.APIError
+			)
+			if tt.reject {
+				result, err = svc.RejectContract(req)
+			} else {
+				result, err = svc.ApproveContract(req)
+			}
+			assert.Equal(t, tt.wantLimit, limitsUpdated)
+			if tt.wantCode != "" {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.wantCode, err.Code())
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, committed)
+			assert.Equal(t, apistructs.ContractApproving, fromStatus)
+			if tt.reject {
+				assert.Equal(t, apistructs.ContractDisapproved, result.Status)
+				assert.Equal(t, apistructs.ContractDisapproved, updates["status"])
+				assert.Contains(t, updates, "request_sla_id")
+				assert.Nil(t, result.RequestSLAID)
+				assert.Equal(t, "拒绝了对该调用申请的授权, 理由: not allowed", record.Action)
+			} else {
+				assert.Equal(t, apistructs.ContractApproved, result.Status)
+				assert.NotContains(t, updates, "request_sla_id")
+			}
+		})
+	}
+}
+
+func TestCommitContractStatus(t *testing.T) {
+	record := &apistructs.ContractRecordModel{OrgID: 1, ContractID: 1, Action: "通过了对该调用申请的授权"}
+	updates := map[string]interface{}{"status": apistructs.ContractApproved}
+	gatewayErr := errors.New("gateway error")
+
+	tests := []struct {
+		name       string
+		expect     func(mock sqlmock.Sqlmock)
+		gatewayErr error
+		wantErr    bool
+		wantCalled bool
+	}{
+		{
+			name: "committed",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantCalled: true,
+		},
+		{
+			name: "status changed",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "gateway failed",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectRollback()
+			},
+			gatewayErr: gatewayErr,
+			wantErr:    true,
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockDB(t)
+			tt.expect(mock)
+			var called bool
+			err := commitContractStatus(1, 1, apistructs.ContractApproving, updates, record, func() error {
+				called = true
+				return tt.gatewayErr
+			})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.wantCalled, called)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	// 状态被并发修改时不执行网关操作
+	mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	err := commitContractStatus(1, 1, apistructs.ContractApproving, updates, record, nil)
+	assert.Equal(t, errContractStatusChanged, err)
+}
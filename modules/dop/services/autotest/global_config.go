@@ -19,8 +19,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
 	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
@@ -40,6 +43,7 @@ const (
 	CmsCfgKeyUpdatedAt       = "AUTOTEST_UPDATED_AT"
 	CmsCfgKeyAPIGlobalConfig = "AUTOTEST_API_GLOBAL_CONFIG"
 	CmsCfgKeyUIGlobalConfig  = "AUTOTEST_UI_GLOBAL_CONFIG"
+	CmsCfgKeyParentNs        = "AUTOTEST_PARENT_NS"
 )
 
 func (svc *Service) CreateGlobalConfig(req apistructs.AutoTestGlobalConfigCreateRequest) (*apistructs.AutoTestGlobalConfig, error) {
@@ -47,6 +51,11 @@ func (svc *Service) CreateGlobalConfig(req apistructs.AutoTestGlobalConfigCreate
 	if err := req.BasicValidate(); err != nil {
 		return nil, apierrors.ErrCreateAutoTestGlobalConfig.InvalidParameter(err)
 	}
+	if err := svc.checkGlobalConfigParent("", req.Scope, req.ScopeID, req.ParentNs); err != nil {
+		return nil, apierrors.ErrCreateAutoTestGlobalConfig.InvalidParameter(err)
+	}
+	// 查询结果中继承的值不保存为本地配置
+	stripInheritedValues(req.APIConfig)
 	// req -> globalConfig
 	globalConfig := apistructs.AutoTestGlobalConfig{
 		Scope:       req.Scope,
@@ -57,13 +66,15 @@ func (svc *Service) CreateGlobalConfig(req apistructs.AutoTestGlobalConfigCreate
 		UpdaterID:   req.IdentityInfo.UserID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+		ParentNs:    req.ParentNs,
 		APIConfig:   req.APIConfig,
 		UIConfig:    req.UIConfig,
 	}
 	if err := svc.createOrUpdatePipelineCmsGlobalConfigs(&globalConfig); err != nil {
 		return nil, apierrors.ErrCreateAutoTestGlobalConfig.InternalError(err)
 	}
-	return &globalConfig, nil
+	masked := globalConfig.MaskSecrets()
+	return &masked, nil
 }
 
 func (svc *Service) UpdateGlobalConfig(req apistructs.AutoTestGlobalConfigUpdateRequest) (*apistructs.AutoTestGlobalConfig, error) {
@@ -83,9 +94,18 @@ func (svc *Service) UpdateGlobalConfig(req apistructs.AutoTestGlobalConfigUpdate
 	globalConfig.Desc = req.Desc
 	globalConfig.UpdaterID = req.IdentityInfo.UserID
 	globalConfig.UpdatedAt = time.Now()
+	if req.ParentNs != nil {
+		if err := svc.checkGlobalConfigParent(req.PipelineCmsNs, globalConfig.Scope, globalConfig.ScopeID, *req.ParentNs); err != nil {
+			return nil, apierrors.ErrUpdateAutoTestGlobalConfig.InvalidParameter(err)
+		}
+		globalConfig.ParentNs = *req.ParentNs
+	}
 
 	// 更新 globalConfig
 	if req.APIConfig != nil {
+		stripInheritedValues(req.APIConfig)
+		// 敏感配置项传入掩码表示保持原值
+		restoreSecretValues(req.APIConfig, globalConfig.APIConfig)
 		globalConfig.APIConfig = req.APIConfig
 	}
 	if req.UIConfig != nil {
@@ -97,7 +117,17 @@ func (svc *Service) UpdateGlobalConfig(req apistructs.AutoTestGlobalConfigUpdate
 		return nil, apierrors.ErrCreateAutoTestGlobalConfig.InternalError(err)
 	}
 
-	return globalConfig, nil
+	masked := globalConfig.MaskSecrets()
+	return &masked, nil
+}
+
+// GetGlobalConfigScope 查询全局配置所属的 scope, 用于鉴权
+func (svc *Service) GetGlobalConfigScope(ns string) (string, string, error) {
+	cfg, err := svc.parseGlobalConfigFromCmsNs(ns)
+	if err != nil {
+		return "", "", err
+	}
+	return cfg.Scope, cfg.ScopeID, nil
 }
 
 func (svc *Service) parseGlobalConfigFromCmsNs(ns string) (*apistructs.AutoTestGlobalConfig, error) {
@@ -128,7 +158,7 @@ func (svc *Service) parseGlobalConfigFromCmsNs(ns string) (*apistructs.AutoTestG
 		case CmsCfgKeyCreatorID:
 			result.CreatorID = cfg.Value
 		case CmsCfgKeyUpdaterID:
-			result.CreatorID = cfg.Value
+			result.UpdaterID = cfg.Value
 		case CmsCfgKeyCreatedAt:
 			var createdAt time.Time
 			if err := json.Unmarshal([]byte(cfg.Value), &createdAt); err == nil {
@@ -139,6 +169,8 @@ func (svc *Service) parseGlobalConfigFromCmsNs(ns string) (*apistructs.AutoTestG
 			if err := json.Unmarshal([]byte(cfg.Value), &updatedAt); err == nil {
 				result.UpdatedAt = updatedAt
 			}
+		case CmsCfgKeyParentNs:
+			result.ParentNs = cfg.Value
 		case CmsCfgKeyAPIGlobalConfig:
 			var apiConfig apistructs.AutoTestAPIConfig
 			if err := json.Unmarshal([]byte(cfg.Value), &apiConfig); err != nil {
@@ -201,6 +233,11 @@ func (svc *Service) createOrUpdatePipelineCmsGlobalConfigs(cfg *apistructs.AutoT
 		Value:       cfg.Desc,
 		EncryptInDB: false,
 	}
+	// parentNs 为空表示不继承
+	kvs[CmsCfgKeyParentNs] = &cmspb.PipelineCmsConfigValue{
+		Value:       cfg.ParentNs,
+		EncryptInDB: false,
+	}
 	if cfg.CreatorID != "" {
 		kvs[CmsCfgKeyCreatorID] = &cmspb.PipelineCmsConfigValue{
 			Value:       cfg.CreatorID,
@@ -241,7 +278,7 @@ func (svc *Service) createOrUpdatePipelineCmsGlobalConfigs(cfg *apistructs.AutoT
 		}
 		kvs[CmsCfgKeyAPIGlobalConfig] = &cmspb.PipelineCmsConfigValue{
 			Value:       string(b),
-			EncryptInDB: false,
+			EncryptInDB: cfg.APIConfig.HasSecret(),
 			Type:        cms.ConfigTypeKV,
 			Operations:  &cms.DefaultOperationsForKV,
 			Comment:     "auto test api global config",
@@ -252,7 +289,7 @@ func (svc *Service) createOrUpdatePipelineCmsGlobalConfigs(cfg *apistructs.AutoT
 		for _, item := range cfg.APIConfig.Global {
 			kvs[apistructs.PipelineSourceAutoTest.String()+"."+item.Name] = &cmspb.PipelineCmsConfigValue{
 				Value:       item.Value,
-				EncryptInDB: false,
+				EncryptInDB: item.Secret,
 				Type:        cms.ConfigTypeKV,
 				Operations:  &cms.DefaultOperationsForKV,
 				Comment:     "auto test api global config",
@@ -284,8 +321,29 @@ func (svc *Service) createOrUpdatePipelineCmsGlobalConfigs(cfg *apistructs.AutoT
 	return nil
 }
 
+// restoreSecretValues 将值为掩码的敏感配置项恢复为原值
+func restoreSecretValues(cfg, origin *apistructs.AutoTestAPIConfig) {
+	if origin == nil {
+		return
+	}
+	for name, item := range cfg.Global {
+		if !item.Secret || item.Value != apistructs.AutoTestConfigSecretMask {
+			continue
+		}
+		if originItem, ok := origin.Global[name]; ok {
+			item.Value = originItem.Value
+			cfg.Global[name] = item
+		}
+	}
+	if cfg.Proxy != nil && cfg.Proxy.Password == apistructs.AutoTestConfigSecretMask && origin.Proxy != nil {
+		cfg.Proxy.Password = origin.Proxy.Password
+	}
+}
+
+const globalConfigPipelineCmsNsPrefix = "autotest^"
+
 func generateGlobalConfigPipelineCmsNsPrefix(scope, scopeID string) string {
-	return fmt.Sprintf("autotest^scope-%s^scopeid-%s^", scope, scopeID)
+	return fmt.Sprintf(globalConfigPipelineCmsNsPrefix+"scope-%s^scopeid-%s^", scope, scopeID)
 }
 
 func generateGlobalConfigPipelineCmsNs(scope, scopeID string) string {
@@ -303,6 +361,14 @@ func (svc *Service) DeleteGlobalConfig(req apistructs.AutoTestGlobalConfigDelete
 	if err != nil {
 		return nil, apierrors.ErrDeleteAutoTestGlobalConfig.InternalError(err)
 	}
+	children, err := svc.listInheritingGlobalConfigs(req.PipelineCmsNs)
+	if err != nil {
+		return nil, apierrors.ErrDeleteAutoTestGlobalConfig.InternalError(err)
+	}
+	if len(children) > 0 {
+		return nil, apierrors.ErrDeleteAutoTestGlobalConfig.InvalidState(
+			fmt.Sprintf("global config is inherited by %s", strings.Join(children, ", ")))
+	}
 
 	// 删除
 	if _, err := svc.cms.DeleteCmsNsConfigs(utils.WithInternalClientContext(context.Background()), &cmspb.CmsNsConfigsDeleteRequest{
@@ -314,8 +380,10 @@ func (svc *Service) DeleteGlobalConfig(req apistructs.AutoTestGlobalConfigDelete
 	}); err != nil {
 		return nil, apierrors.ErrDeleteAutoTestGlobalConfig.InternalError(err)
 	}
+	svc.deleteEffectiveGlobalConfigNs(req.PipelineCmsNs)
 
-	return globalConfig, nil
+	masked := globalConfig.MaskSecrets()
+	return &masked, nil
 }
 
 func (svc *Service) ListGlobalConfigs(req apistructs.AutoTestGlobalConfigListRequest) ([]apistructs.AutoTestGlobalConfig, error) {
@@ -337,14 +405,60 @@ func (svc *Service) ListGlobalConfigs(req apistructs.AutoTestGlobalConfigListReq
 	var sortResult apistructs.SortByUpdateTimeAutoTestGlobalConfigs
 
 	for _, ns := range namespaces.Data {
-		cfg, err := svc.parseGlobalConfigFromCmsNs(ns.Ns)
+		// 合并继承的配置, 继承得到的值会被标记
+		cfg, err := svc.resolveGlobalConfig(ns.Ns)
 		if err != nil {
 			return nil, apierrors.ErrListAutoTestGlobalConfigs.InternalError(err)
 		}
-		sortResult = append(sortResult, *cfg)
+		sortResult = append(sortResult, cfg.MaskSecrets())
 	}
 	// sort by update time
 	sort.Sort(sortResult)
 
 	return sortResult, nil
 }
+
+// MigrateGlobalConfigSecrets 将所有全局配置中名称在 secretNames 中的配置项标记为敏感配置项并重新加密存储,
+// 用于迁移此前明文存储的敏感配置
+func (svc *Service) MigrateGlobalConfigSecrets(secretNames []string) error {
+	if len(secretNames) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, len(secretNames))
+	for _, name := range secretNames {
+		names[name] = true
+	}
+	namespaces, err := svc.cms.ListCmsNs(utils.WithInternalClientContext(context.Background()), &cmspb.CmsListNsRequest{
+		PipelineSource: apistructs.PipelineSourceAutoTest.String(),
+		NsPrefix:       globalConfigPipelineCmsNsPrefix,
+	})
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces.Data {
+		cfg, err := svc.parseGlobalConfigFromCmsNs(ns.Ns)
+		if err != nil {
+			return err
+		}
+		if cfg.APIConfig == nil {
+			continue
+		}
+		var migrated []string
+		for name, item := range cfg.APIConfig.Global {
+			if item.Secret || !names[name] {
+				continue
+			}
+			item.Secret = true
+			cfg.APIConfig.Global[name] = item
+			migrated = append(migrated, name)
+		}
+		if len(migrated) == 0 {
+			continue
+		}
+		if err := svc.createOrUpdatePipelineCmsGlobalConfigs(cfg); err != nil {
+			return fmt.Errorf("failed to migrate secrets of global config %s, err: %v", ns.Ns, err)
+		}
+		logrus.Infof("migrated secrets of autotest global config %s, keys: %v", ns.Ns, migrated)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/utils"
+)
+
+const (
+	// maxGlobalConfigInheritDepth 全局配置继承链上祖先配置的最大数量
+	maxGlobalConfigInheritDepth = 5
+	// effectiveGlobalConfigPipelineCmsNsPrefix 执行时使用的合并后全局配置的命名空间前缀, 不会出现在全局配置列表中
+	effectiveGlobalConfigPipelineCmsNsPrefix = "autotest-effective^"
+)
+
+// getGlobalConfigChain 返回全局配置及其祖先配置, 第一个为 ns 对应的全局配置.
+// 祖先配置必须与 ns 属于同一 scope, 避免通过继承读取其他项目的配置
+func (svc *Service) getGlobalConfigChain(ns string) ([]*apistructs.AutoTestGlobalConfig, error) {
+	var chain []*apistructs.AutoTestGlobalConfig
+	visited := make(map[string]bool)
+	for ns != "" {
+		if visited[ns] {
+			return nil, fmt.Errorf("global config %s is inherited circularly", ns)
+		}
+		if len(chain) > maxGlobalConfigInheritDepth {
+			return nil, fmt.Errorf("global config inheritance exceeds max depth %d", maxGlobalConfigInheritDepth)
+		}
+		visited[ns] = true
+		cfg, err := svc.parseGlobalConfigFromCmsNs(ns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get global config %s, err: %v", ns, err)
+		}
+		if len(chain) > 0 && !sameGlobalConfigScope(chain[0], cfg) {
+			return nil, fmt.Errorf("global config %s is not in scope %s/%s", ns, chain[0].Scope, chain[0].ScopeID)
+		}
+		chain = append(chain, cfg)
+		ns = cfg.ParentNs
+	}
+	return chain, nil
+}
+
+// checkGlobalConfigParent 校验继承的全局配置存在且与当前配置属于同一 scope, 且继承后不会形成循环或超过最大深度
+func (svc *Service) checkGlobalConfigParent(ns, scope, scopeID, parentNs string) error {
+	if parentNs == "" {
+		return nil
+	}
+	if parentNs == ns {
+		return fmt.Errorf("global config can not inherit itself")
+	}
+	if !strings.HasPrefix(parentNs, generateGlobalConfigPipelineCmsNsPrefix(scope, scopeID)) {
+		return fmt.Errorf("invalid parent global config: %s", parentNs)
+	}
+	chain, err := svc.getGlobalConfigChain(parentNs)
+	if err != nil {
+		return fmt.Errorf("invalid parent global config, err: %v", err)
+	}
+	if chain[0].Scope != scope || chain[0].ScopeID != scopeID {
+		return fmt.Errorf("parent global config is not in scope %s/%s", scope, scopeID)
+	}
+	for _, cfg := range chain {
+		if cfg.Ns == ns {
+			return fmt.Errorf("global config is inherited circularly")
+		}
+	}
+	if len(chain) > maxGlobalConfigInheritDepth {
+		return fmt.Errorf("global config inheritance exceeds max depth %d", maxGlobalConfigInheritDepth)
+	}
+	return nil
+}
+
+// sameGlobalConfigScope 两个全局配置是否属于同一 scope
+func sameGlobalConfigScope(a, b *apistructs.AutoTestGlobalConfig) bool {
+	return a.Scope == b.Scope && a.ScopeID == b.ScopeID
+}
+
+// resolveGlobalConfig 查询全局配置并合并继承链上的配置, 本地配置项覆盖继承的配置项
+func (svc *Service) resolveGlobalConfig(ns string) (*apistructs.AutoTestGlobalConfig, error) {
+	chain, err := svc.getGlobalConfigChain(ns)
+	if err != nil {
+		return nil, err
+	}
+	// 从最上层的祖先开始逐层合并
+	apiConfig := chain[len(chain)-1].APIConfig
+	for i := len(chain) - 2; i >= 0; i-- {
+		apiConfig = mergeInheritedAPIConfig(chain[i].APIConfig, apiConfig, chain[i+1].Ns)
+	}
+	result := chain[0]
+	result.APIConfig = apiConfig
+	return result, nil
+}
+
+// mergeInheritedAPIConfig 合并继承的 api 配置, 本地已有的值优先, 继承得到的值会被标记
+func mergeInheritedAPIConfig(local, parent *apistructs.AutoTestAPIConfig, parentNs string) *apistructs.AutoTestAPIConfig {
+	if parent == nil {
+		return local
+	}
+	merged := apistructs.AutoTestAPIConfig{
+		Header: make(map[string]string),
+		Global: make(map[string]apistructs.AutoTestConfigItem),
+	}
+	if local != nil {
+		merged.Domain = local.Domain
+		merged.Proxy = local.Proxy
+		for k, v := range local.Header {
+			merged.Header[k] = v
+		}
+		for name, item := range local.Global {
+			merged.Global[name] = item
+		}
+	}
+
+	var inheritance apistructs.AutoTestAPIConfigInheritance
+	if merged.Domain == "" && parent.Domain != "" {
+		merged.Domain = parent.Domain
+		inheritance.Domain = true
+	}
+	if merged.Proxy == nil && parent.Proxy != nil {
+		merged.Proxy = parent.Proxy
+		inheritance.Proxy = true
+	}
+	for k, v := range parent.Header {
+		if _, ok := merged.Header[k]; ok {
+			continue
+		}
+		merged.Header[k] = v
+		inheritance.Headers = append(inheritance.Headers, k)
+	}
+	sort.Strings(inheritance.Headers)
+	for name, item := range parent.Global {
+		if _, ok := merged.Global[name]; ok {
+			continue
+		}
+		item.Inherited = true
+		if item.InheritedFrom == "" {
+			item.InheritedFrom = parentNs
+		}
+		merged.Global[name] = item
+	}
+	if inheritance.Domain || inheritance.Proxy || len(inheritance.Headers) > 0 {
+		merged.Inheritance = &inheritance
+	}
+	return &merged
+}
+
+// stripInheritedValues 去除查询结果中继承得到的值, 只保留本地配置
+func stripInheritedValues(cfg *apistructs.AutoTestAPIConfig) {
+	if cfg == nil {
+		return
+	}
+	for name, item := range cfg.Global {
+		if item.Inherited {
+			delete(cfg.Global, name)
+		}
+	}
+	if cfg.Inheritance != nil {
+		if cfg.Inheritance.Domain {
+			cfg.Domain = ""
+		}
+		if cfg.Inheritance.Proxy {
+			cfg.Proxy = nil
+		}
+		for _, k := range cfg.Inheritance.Headers {
+			delete(cfg.Header, k)
+		}
+		cfg.Inheritance = nil
+	}
+}
+
+// clearInheritanceMarks 清除合并后配置中的继承标记
+func clearInheritanceMarks(cfg *apistructs.AutoTestAPIConfig) {
+	if cfg == nil {
+		return
+	}
+	for name, item := range cfg.Global {
+		item.Inherited = false
+		item.InheritedFrom = ""
+		cfg.Global[name] = item
+	}
+	cfg.Inheritance = nil
+}
+
+// listInheritingGlobalConfigs 返回直接继承 ns 的全局配置名称
+func (svc *Service) listInheritingGlobalConfigs(ns string) ([]string, error) {
+	namespaces, err := svc.cms.ListCmsNs(utils.WithInternalClientContext(context.Background()), &cmspb.CmsListNsRequest{
+		PipelineSource: apistructs.PipelineSourceAutoTest.String(),
+		NsPrefix:       globalConfigPipelineCmsNsPrefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var children []string
+	for _, item := range namespaces.Data {
+		if item.Ns == ns {
+			continue
+		}
+		cfg, err := svc.parseGlobalConfigFromCmsNs(item.Ns)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.ParentNs == ns {
+			children = append(children, cfg.DisplayName)
+		}
+	}
+	return children, nil
+}
+
+// EffectiveGlobalConfigNs 返回执行时使用的全局配置命名空间.
+// 全局配置继承了其他配置时, 每次执行前将继承链合并后写入单独的命名空间, 继承的配置修改后无需重新复制即可生效
+func (svc *Service) EffectiveGlobalConfigNs(ns string) (string, error) {
+	if !strings.HasPrefix(ns, globalConfigPipelineCmsNsPrefix) {
+		return ns, nil
+	}
+	cfg, err := svc.resolveGlobalConfig(ns)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ParentNs == "" {
+		return ns, nil
+	}
+
+	effective := *cfg
+	effective.Ns = effectiveGlobalConfigPipelineCmsNsPrefix + ns
+	effective.ParentNs = ""
+	clearInheritanceMarks(effective.APIConfig)
+
+	ctx := utils.WithInternalClientContext(context.Background())
+	origin, err := svc.cms.GetCmsNsConfigs(ctx, &cmspb.CmsNsConfigsGetRequest{
+		Ns:             effective.Ns,
+		PipelineSource: apistructs.PipelineSourceAutoTest.String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := svc.createOrUpdatePipelineCmsGlobalConfigs(&effective); err != nil {
+		return "", err
+	}
+	// 先写入再删除已不存在的配置项, 避免执行中的流水线读取到不完整的配置
+	var staleKeys []string
+	for _, c := range origin.Data {
+		name := strings.TrimPrefix(c.Key, apistructs.PipelineSourceAutoTest.String()+".")
+		if name == c.Key {
+			continue
+		}
+		if effective.APIConfig != nil {
+			if _, ok := effective.APIConfig.Global[name]; ok {
+				continue
+			}
+		}
+		staleKeys = append(staleKeys, c.Key)
+	}
+	if len(staleKeys) > 0 {
+		if _, err := svc.cms.DeleteCmsNsConfigs(ctx, &cmspb.CmsNsConfigsDeleteRequest{
+			Ns:             effective.Ns,
+			PipelineSource: apistructs.PipelineSourceAutoTest.String(),
+			DeleteForce:    true,
+			DeleteKeys:     staleKeys,
+		}); err != nil {
+			return "", err
+		}
+	}
+	return effective.Ns, nil
+}
+
+// deleteEffectiveGlobalConfigNs 删除全局配置执行时使用的合并后命名空间, 失败不影响全局配置的删除
+func (svc *Service) deleteEffectiveGlobalConfigNs(ns string) {
+	if _, err := svc.cms.DeleteCmsNsConfigs(utils.WithInternalClientContext(context.Background()), &cmspb.CmsNsConfigsDeleteRequest{
+		Ns:             effectiveGlobalConfigPipelineCmsNsPrefix + ns,
+		PipelineSource: apistructs.PipelineSourceAutoTest.String(),
+		DeleteNs:       true,
+		DeleteForce:    true,
+	}); err != nil {
+		logrus.Warnf("failed to delete effective global config of %s, err: %v", ns, err)
+	}
+}
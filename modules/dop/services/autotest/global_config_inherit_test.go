@@ -0,0 +1,119 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
+	"github.com/erda-project/erda/apistructs"
+)
+
+type fakeGlobalConfigCms struct {
+	cmspb.CmsServiceServer
+	configs map[string]*apistructs.AutoTestGlobalConfig
+}
+
+func (f *fakeGlobalConfigCms) GetCmsNsConfigs(ctx context.Context, req *cmspb.CmsNsConfigsGetRequest) (*cmspb.CmsNsConfigsGetResponse, error) {
+	cfg, ok := f.configs[req.Ns]
+	if !ok {
+		return &cmspb.CmsNsConfigsGetResponse{}, nil
+	}
+	apiConfig, _ := json.Marshal(cfg.APIConfig)
+	scopeID := cfg.ScopeID
+	if scopeID == "" {
+		scopeID = "1"
+	}
+	return &cmspb.CmsNsConfigsGetResponse{Data: []*cmspb.PipelineCmsConfig{
+		{Key: CmsCfgKeyScope, Value: "project-autotest-testcase"},
+		{Key: CmsCfgKeyScopeID, Value: scopeID},
+		{Key: CmsCfgKeyDisplayName, Value: cfg.DisplayName},
+		{Key: CmsCfgKeyParentNs, Value: cfg.ParentNs},
+		{Key: CmsCfgKeyAPIGlobalConfig, Value: string(apiConfig)},
+	}}, nil
+}
+
+func TestResolveGlobalConfig(t *testing.T) {
+	svc := New(WithPipelineCms(&fakeGlobalConfigCms{configs: map[string]*apistructs.AutoTestGlobalConfig{
+		"autotest^org": {DisplayName: "org", APIConfig: &apistructs.AutoTestAPIConfig{
+			Domain: "https://base.example.com",
+			Header: map[string]string{"X-Org": "1", "X-Env": "org"},
+			Global: map[string]apistructs.AutoTestConfigItem{
+				"user":  {Name: "user", Type: "string", Value: "admin"},
+				"token": {Name: "token", Type: "string", Value: "org-token", Secret: true},
+			},
+		}},
+		"autotest^project": {DisplayName: "project", ParentNs: "autotest^org", APIConfig: &apistructs.AutoTestAPIConfig{
+			Global: map[string]apistructs.AutoTestConfigItem{
+				"tenant": {Name: "tenant", Type: "string", Value: "t1"},
+			},
+		}},
+		"autotest^space": {DisplayName: "space", ParentNs: "autotest^project", APIConfig: &apistructs.AutoTestAPIConfig{
+			Header: map[string]string{"X-Env": "space"},
+			Global: map[string]apistructs.AutoTestConfigItem{
+				"token": {Name: "token", Type: "string", Value: "space-token"},
+			},
+		}},
+	}}))
+
+	cfg, err := svc.resolveGlobalConfig("autotest^space")
+	assert.NoError(t, err)
+	api := cfg.APIConfig
+	assert.Equal(t, "https://base.example.com", api.Domain)
+	assert.Equal(t, "space", api.Header["X-Env"])
+	assert.Equal(t, &apistructs.AutoTestAPIConfigInheritance{Domain: true, Headers: []string{"X-Org"}}, api.Inheritance)
+	// 本地配置项覆盖继承的配置项
+	assert.Equal(t, "space-token", api.Global["token"].Value)
+	assert.False(t, api.Global["token"].Inherited)
+	assert.True(t, api.Global["tenant"].Inherited)
+	assert.Equal(t, "autotest^project", api.Global["tenant"].InheritedFrom)
+	assert.Equal(t, "autotest^org", api.Global["user"].InheritedFrom)
+
+	// 保存时去除继承得到的值
+	stripInheritedValues(api)
+	assert.Equal(t, "", api.Domain)
+	assert.Equal(t, map[string]string{"X-Env": "space"}, api.Header)
+	assert.Len(t, api.Global, 1)
+	assert.Nil(t, api.Inheritance)
+}
+
+func TestCheckGlobalConfigParent(t *testing.T) {
+	const scope = "project-autotest-testcase"
+	prefix := generateGlobalConfigPipelineCmsNsPrefix(scope, "1")
+	otherPrefix := generateGlobalConfigPipelineCmsNsPrefix(scope, "2")
+	svc := New(WithPipelineCms(&fakeGlobalConfigCms{configs: map[string]*apistructs.AutoTestGlobalConfig{
+		prefix + "a":      {DisplayName: "a"},
+		prefix + "b":      {DisplayName: "b", ParentNs: prefix + "a"},
+		otherPrefix + "x": {DisplayName: "x", ScopeID: "2"},
+		// 历史数据中跨项目继承的配置
+		prefix + "y": {DisplayName: "y", ParentNs: otherPrefix + "x"},
+	}}))
+	assert.NoError(t, svc.checkGlobalConfigParent("", scope, "1", ""))
+	assert.NoError(t, svc.checkGlobalConfigParent(prefix+"c", scope, "1", prefix+"b"))
+	assert.Error(t, svc.checkGlobalConfigParent(prefix+"a", scope, "1", prefix+"a"))
+	assert.Error(t, svc.checkGlobalConfigParent(prefix+"a", scope, "1", prefix+"b"))
+	assert.Error(t, svc.checkGlobalConfigParent(prefix+"a", scope, "1", prefix+"missing"))
+	assert.Error(t, svc.checkGlobalConfigParent(prefix+"a", scope, "1", effectiveGlobalConfigPipelineCmsNsPrefix+prefix+"b"))
+	// 不允许继承其他项目的全局配置
+	assert.Error(t, svc.checkGlobalConfigParent("", scope, "1", otherPrefix+"x"))
+	assert.Error(t, svc.checkGlobalConfigParent("", scope, "2", prefix+"a"))
+	assert.Error(t, svc.checkGlobalConfigParent("", scope, "1", prefix+"y"))
+	_, err := svc.resolveGlobalConfig(prefix + "y")
+	assert.Error(t, err)
+}
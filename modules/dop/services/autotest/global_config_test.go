@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestRestoreSecretValues(t *testing.T) {
+	origin := &apistructs.AutoTestAPIConfig{Global: map[string]apistructs.AutoTestConfigItem{
+		"token":    {Name: "token", Type: "string", Value: "t0ken", Secret: true},
+		"password": {Name: "password", Type: "string", Value: "pa55"},
+	}}
+	cfg := &apistructs.AutoTestAPIConfig{Global: map[string]apistructs.AutoTestConfigItem{
+		"token":    {Name: "token", Type: "string", Value: apistructs.AutoTestConfigSecretMask, Secret: true},
+		"password": {Name: "password", Type: "string", Value: apistructs.AutoTestConfigSecretMask, Secret: true},
+		"key":      {Name: "key", Type: "string", Value: "new", Secret: true},
+	}}
+	restoreSecretValues(cfg, origin)
+	assert.Equal(t, "t0ken", cfg.Global["token"].Value)
+	assert.Equal(t, "pa55", cfg.Global["password"].Value)
+	assert.Equal(t, "new", cfg.Global["key"].Value)
+
+	masked := apistructs.AutoTestGlobalConfig{APIConfig: cfg}.MaskSecrets()
+	assert.Equal(t, apistructs.AutoTestConfigSecretMask, masked.APIConfig.Global["token"].Value)
+	assert.Equal(t, "t0ken", cfg.Global["token"].Value)
+}
+
+func TestRestoreProxySecret(t *testing.T) {
+	origin := &apistructs.AutoTestAPIConfig{Proxy: &apistructs.APIProxyConfig{URL: "http://proxy:3128", Username: "u", Password: "p"}}
+	cfg := &apistructs.AutoTestAPIConfig{Proxy: &apistructs.APIProxyConfig{URL: "http://proxy:3128", Username: "u", Password: apistructs.AutoTestConfigSecretMask}}
+	restoreSecretValues(cfg, origin)
+	assert.Equal(t, "p", cfg.Proxy.Password)
+	assert.True(t, cfg.HasSecret())
+
+	masked := cfg.MaskSecrets()
+	assert.Equal(t, apistructs.AutoTestConfigSecretMask, masked.Proxy.Password)
+	assert.Equal(t, "p", cfg.Proxy.Password)
+}
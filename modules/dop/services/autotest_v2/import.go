@@ -371,6 +371,22 @@ func (svc *Service) Import(req apistructs.AutoTestSpaceImportRequest, r *http.Re
 		return 0, apierrors.ErrImportAutoTestSpace.InvalidParameter(fmt.Errorf("project not found, id: %d", req.ProjectID))
 	}
 
+	if req.TargetSpaceID != 0 {
+		if req.Mode == "" {
+			req.Mode = apistructs.AutoTestSpaceImportModeSkip
+		}
+		if !req.Mode.Valid() {
+			return 0, apierrors.ErrImportAutoTestSpace.InvalidParameter("mode")
+		}
+		space, err := svc.GetSpace(req.TargetSpaceID)
+		if err != nil {
+			return 0, apierrors.ErrImportAutoTestSpace.InvalidParameter(fmt.Errorf("space not found, id: %d", req.TargetSpaceID))
+		}
+		if uint64(space.ProjectID) != req.ProjectID {
+			return 0, apierrors.ErrImportAutoTestSpace.InvalidParameter("targetSpaceID")
+		}
+	}
+
 	f, fileHeader, err := r.FormFile("file")
 	if err != nil {
 		return 0, err
@@ -433,6 +449,7 @@ func (svc *Service) ImportFile(record *dao.TestFileRecord) {
 		return
 	}
 
+	var summary *apistructs.AutoTestSpaceImportSummary
 	switch req.FileType {
 	case apistructs.TestSpaceFileTypeExcel:
 		sheets, err := excel.Decode(f)
@@ -469,7 +486,14 @@ func (svc *Service) ImportFile(record *dao.TestFileRecord) {
 			return
 		}
 		data := creator.Creator.GetSpaceData()
-		_, err = data.Copy()
+		if req.TargetSpaceID != 0 {
+			summary, err = data.ImportToSpace(req.TargetSpaceID, req.Mode)
+		} else {
+			var space *apistructs.AutoTestSpace
+			if space, err = data.Copy(); err == nil {
+				summary = data.NewSpaceImportSummary(space.ID)
+			}
+		}
 		if err != nil {
 			logrus.Error(apierrors.ErrImportAutoTestSpace.InternalError(err))
 			if err := svc.UpdateFileRecord(apistructs.TestFileRecordRequest{ID: id, State: apistructs.FileRecordStateFail}); err != nil {
@@ -479,7 +503,15 @@ func (svc *Service) ImportFile(record *dao.TestFileRecord) {
 		}
 	default:
 	}
-	if err := svc.UpdateFileRecord(apistructs.TestFileRecordRequest{ID: id, State: apistructs.FileRecordStateSuccess}); err != nil {
+	if err := svc.UpdateFileRecord(apistructs.TestFileRecordRequest{
+		ID:    id,
+		State: apistructs.FileRecordStateSuccess,
+		Extra: apistructs.TestFileExtra{
+			AutotestSpaceFileExtraInfo: &apistructs.AutoTestSpaceFileExtraInfo{
+				ImportSummary: summary,
+			},
+		},
+	}); err != nil {
 		logrus.Error(apierrors.ErrImportAutoTestSpace.InternalError(err))
 	}
 }
@@ -32,6 +32,7 @@ import (
 
 	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
 	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/dop/services/autotest"
@@ -190,6 +191,19 @@ func (svc *Service) UpdateAutotestScene(req apistructs.AutotestSceneSceneUpdateR
 		scene.Status = req.Status
 	}
 	scene.Description = req.Description
+	if req.Tags != nil {
+		tags, err := normalizeSceneTags(req.Tags)
+		if err != nil {
+			return 0, apierrors.ErrUpdateAutoTestScene.InvalidParameter(err)
+		}
+		scene.Tags = tags
+	}
+	if req.StepTimeoutMs != nil {
+		if err := checkStepTimeoutMs(*req.StepTimeoutMs); err != nil {
+			return 0, apierrors.ErrUpdateAutoTestScene.InvalidParameter(err)
+		}
+		scene.StepTimeoutMs = *req.StepTimeoutMs
+	}
 	if !req.IsStatus {
 		scene.UpdaterID = req.IdentityInfo.UserID
 	}
@@ -288,7 +302,10 @@ func (svc *Service) ListAutotestScene(req apistructs.AutotestSceneRequest) (uint
 		return 0, nil, err
 	}
 
-	list := getList(scene, req.PageNo, req.PageSize)
+	matched, list := getList(scene, req.PageNo, req.PageSize, req.Tags)
+	if len(req.Tags) > 0 {
+		total = matched
+	}
 
 	var sceneIDs []uint64
 	// 通过pre_id获取顺序列表
@@ -362,6 +379,7 @@ func (svc *Service) ListAutotestScenes(setIDs []uint64) (map[uint64][]apistructs
 			Inputs:      inputsList,
 			Output:      outputList,
 			RefSetID:    each.RefSetID,
+			Tags:        each.Tags.List(),
 		})
 	}
 	return lists, nil
@@ -384,7 +402,10 @@ func (svc *Service) CutAutotestScene(sc *dao.AutoTestScene) error {
 
 // DeleteAutotestScene 删除场景
 func (svc *Service) DeleteAutotestScene(id uint64) error {
-	return svc.db.DeleteAutoTestScene(id)
+	if err := svc.db.DeleteAutoTestScene(id); err != nil {
+		return err
+	}
+	return svc.db.DeleteAutoTestSchedulesByTargets(apistructs.AutoTestScheduleTargetScene, []uint64{id})
 }
 
 // UpdateAutotestSceneUpdater 更新场景更新人
@@ -397,6 +418,14 @@ func (svc *Service) UpdateAutotestSceneUpdateTime(sceneID uint64) error {
 	return svc.db.UpdateAutotestSceneUpdateAt(sceneID, time.Now())
 }
 
+// effectiveConfigNs 返回执行时使用的全局配置命名空间, 全局配置继承了其他配置时返回合并后的命名空间
+func (svc *Service) effectiveConfigNs(configNs string) (string, error) {
+	if configNs == "" {
+		return "", nil
+	}
+	return svc.autotestSvc.EffectiveGlobalConfigNs(configNs)
+}
+
 func (svc *Service) ExecuteDiceAutotestScene(req apistructs.AutotestExecuteSceneRequest) (*apistructs.PipelineDTO, error) {
 	var autotestSceneRequest apistructs.AutotestSceneRequest
 	autotestSceneRequest.SceneID = req.AutoTestScene.ID
@@ -404,19 +433,27 @@ func (svc *Service) ExecuteDiceAutotestScene(req apistructs.AutotestExecuteScene
 	if err != nil {
 		return nil, err
 	}
+	configNs, err := svc.effectiveConfigNs(req.ConfigManageNamespaces)
+	if err != nil {
+		return nil, err
+	}
 
 	sceneInputs, err := svc.ListAutoTestSceneInput(scene.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	yml, err := svc.SceneToYml(scene.ID)
+	yml, err := svc.sceneToYml(scene.ID, configNs, req.Variables)
 	if err != nil {
 		return nil, err
 	}
 
 	var params []apistructs.PipelineRunParam
 	for _, input := range sceneInputs {
+		// 请求传入的变量优先于场景入参
+		if v, ok := req.Variables[input.Name]; ok {
+			input.Temp = v
+		}
 		// replace mock temp before create pipeline
 		// and so steps can use the same mock temp
 		replacedTemp := expression.ReplaceRandomParams(input.Temp)
@@ -438,8 +475,8 @@ func (svc *Service) ExecuteDiceAutotestScene(req apistructs.AutotestExecuteScene
 		IdentityInfo:    req.IdentityInfo,
 	}
 
-	if req.ConfigManageNamespaces != "" {
-		reqPipeline.ConfigManageNamespaces = append(reqPipeline.ConfigManageNamespaces, req.ConfigManageNamespaces)
+	if configNs != "" {
+		reqPipeline.ConfigManageNamespaces = append(reqPipeline.ConfigManageNamespaces, configNs)
 	}
 
 	if reqPipeline.ClusterName == "" {
@@ -454,6 +491,7 @@ func (svc *Service) ExecuteDiceAutotestScene(req apistructs.AutotestExecuteScene
 	if err != nil {
 		return nil, err
 	}
+	svc.recordSceneExecution(scene, pipelineDTO, req)
 
 	return pipelineDTO, nil
 }
@@ -470,10 +508,14 @@ func (svc *Service) ExecuteDiceAutotestSceneStep(req apistructs.AutotestExecuteS
 		return nil, fmt.Errorf("no api is referenced")
 	}
 
+	configNs, err := svc.effectiveConfigNs(req.ConfigManageNamespaces)
+	if err != nil {
+		return nil, err
+	}
 	var pipelineCmsGetConfigsRequest cmspb.CmsNsConfigsGetRequest
 	pipelineCmsGetConfigsRequest.PipelineSource = apistructs.PipelineSourceAutoTest.String()
 	pipelineCmsGetConfigsRequest.GlobalDecrypt = true
-	pipelineCmsGetConfigsRequest.Ns = req.ConfigManageNamespaces
+	pipelineCmsGetConfigsRequest.Ns = configNs
 	configs, _ := svc.cms.GetCmsNsConfigs(utils.WithInternalClientContext(context.Background()), &pipelineCmsGetConfigsRequest)
 
 	caseParams := make(map[string]*apistructs.CaseParams)
@@ -487,6 +529,7 @@ func (svc *Service) ExecuteDiceAutotestSceneStep(req apistructs.AutotestExecuteS
 			}
 			apiTestEnvData.Domain = apiConfig.Domain
 			apiTestEnvData.Header = apiConfig.Header
+			apiTestEnvData.Proxy = apiConfig.Proxy
 			apiTestEnvData.Global = make(map[string]*apistructs.APITestEnvVariable)
 			for name, item := range apiConfig.Global {
 				apiTestEnvData.Global[name] = &apistructs.APITestEnvVariable{
@@ -549,6 +592,104 @@ func (svc *Service) ExecuteDiceAutotestSceneStep(req apistructs.AutotestExecuteS
 		return nil, err
 	}
 
+	jsonPathOutParams, jsonPathAsserts, err := apitestsv2.CompileJsonPathAsserts(apiInfoV2.JsonPathAsserts)
+	if err != nil {
+		return nil, err
+	}
+	apiInfoV2.OutParams = append(apiInfoV2.OutParams, jsonPathOutParams...)
+	apiInfoV2.Asserts = append(apiInfoV2.Asserts, jsonPathAsserts...)
+	if err := svc.decryptStepProxyPassword(apiInfoV2.Proxy); err != nil {
+		return nil, err
+	}
+
+	var runStep apistructs.AutoTestRunStep
+	if err = json.Unmarshal([]byte(step.Value), &runStep); err != nil {
+		return nil, err
+	}
+	scene, err := svc.db.GetAutotestScene(step.SceneID)
+	if err != nil {
+		return nil, err
+	}
+
+	return invokeSceneStepAPI(apiInfoV2, apiTestEnvData, caseParams, stepTimeoutMs(runStep, scene.StepTimeoutMs))
+}
+
+const (
+	maxSceneStepRetryAttempts = 10
+	maxSceneStepRetryDelayMs  = 60 * 1000
+)
+
+// normalizeStepRetryPolicy 补全重试策略, 未配置时只执行一次
+func normalizeStepRetryPolicy(policy *apistructs.AutoTestStepRetryPolicy) apistructs.AutoTestStepRetryPolicy {
+	if policy == nil {
+		return apistructs.AutoTestStepRetryPolicy{MaxAttempts: 1}
+	}
+	normalized := *policy
+	if normalized.MaxAttempts < 1 {
+		normalized.MaxAttempts = 1
+	}
+	if normalized.MaxAttempts > maxSceneStepRetryAttempts {
+		normalized.MaxAttempts = maxSceneStepRetryAttempts
+	}
+	if normalized.DelayMs < 0 {
+		normalized.DelayMs = 0
+	}
+	if normalized.DelayMs > maxSceneStepRetryDelayMs {
+		normalized.DelayMs = maxSceneStepRetryDelayMs
+	}
+	return normalized
+}
+
+// stepRetryLoop 将重试策略转换为流水线任务的循环配置, 由流水线在任务结束后判断是否重新执行;
+// 退出条件根据 api-test 任务的出参计算, 未配置重试条件时返回 nil
+func stepRetryLoop(stepID uint64, retry *apistructs.AutoTestStepRetryPolicy) *apistructs.PipelineTaskLoop {
+	policy := normalizeStepRetryPolicy(retry)
+	if policy.MaxAttempts <= 1 {
+		return nil
+	}
+	output := func(key string) string {
+		return "'" + expression.LeftPlaceholder + " " + expression.Outputs + "." + strconv.FormatUint(stepID, 10) + "." + key + " " + expression.RightPlaceholder + "'"
+	}
+	status := output(apistructs.AutotestStepOutputStatus)
+	var conditions []string
+	// 请求失败时没有响应, 状态码为 0
+	if policy.RetryOnInvokeError {
+		conditions = append(conditions, status+" == '0'")
+	}
+	for _, code := range policy.RetryOnStatusCodes {
+		conditions = append(conditions, status+" == '"+strconv.Itoa(code)+"'")
+	}
+	if policy.RetryOnAssertFailure {
+		conditions = append(conditions, "("+status+" != '0' && "+output(apistructs.AutotestStepOutputResult)+" != 'success')")
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	intervalSec := uint64((policy.DelayMs + 999) / 1000)
+	if intervalSec == 0 {
+		intervalSec = 1
+	}
+	return &apistructs.PipelineTaskLoop{
+		Break: "!(" + strings.Join(conditions, " || ") + ")",
+		Strategy: &apistructs.LoopStrategy{
+			MaxTimes:        int64(policy.MaxAttempts),
+			DeclineRatio:    1,
+			DeclineLimitSec: int64(intervalSec),
+			IntervalSec:     intervalSec,
+		},
+	}
+}
+
+// invokeSceneStepAPI 执行一次 api 步骤, 单个 API 执行失败不返回错误, 失败信息记录在结果中.
+// timeoutMs 大于 0 时超时后取消请求
+func invokeSceneStepAPI(apiInfoV2 apistructs.APIInfoV2, apiTestEnvData *apistructs.APITestEnvData,
+	caseParams map[string]*apistructs.CaseParams, timeoutMs int64) (*apistructs.AutotestExecuteSceneStepRespData, error) {
+	ctx := context.Background()
+	if timeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+		defer cancel()
+	}
 	apiTest := apitestsv2.New(&apistructs.APIInfo{
 		ID:        apiInfoV2.ID,
 		Name:      apiInfoV2.Name,
@@ -559,15 +700,19 @@ func (svc *Service) ExecuteDiceAutotestSceneStep(req apistructs.AutotestExecuteS
 		Body:      apiInfoV2.Body,
 		OutParams: apiInfoV2.OutParams,
 		Asserts:   [][]apistructs.APIAssert{apiInfoV2.Asserts},
-	})
-	var respData apistructs.AutotestExecuteSceneStepRespData
+		Proxy:     apiInfoV2.Proxy,
+	}, apitestsv2.WithContext(ctx))
+	respData := apistructs.AutotestExecuteSceneStepRespData{TimeoutMs: timeoutMs}
 	cookieJar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	if err != nil {
 		return nil, err
 	}
 	hc := http.Client{Jar: cookieJar}
+	begin := time.Now()
 	apiReq, apiResp, err := apiTest.Invoke(&hc, apiTestEnvData, caseParams)
+	respData.DurationMs = time.Since(begin).Milliseconds()
 	if err != nil {
+		respData.TimedOut = ctx.Err() == context.DeadlineExceeded
 		// 单个 API 执行失败，不返回失败，继续执行下一个
 		logrus.Warningf("invoke api error, apiInfo:%+v, (%+v)", apiTest.API, err)
 		respData.Resp = &apistructs.APIResp{
@@ -598,7 +743,13 @@ func (svc *Service) ExecuteDiceAutotestSceneStep(req apistructs.AutotestExecuteS
 	return &respData, nil
 }
 
-func (svc *Service) SceneToYml(scene uint64) (string, error) {
+// SceneToYml 将场景转换为流水线 yml, configNs 为执行时使用的配置单命名空间, 用于解析循环步骤引用的全局配置
+func (svc *Service) SceneToYml(scene uint64, configNs string) (string, error) {
+	return svc.sceneToYml(scene, configNs, nil)
+}
+
+// sceneToYml 将场景转换为流水线 yml, variables 为本次执行覆盖的变量
+func (svc *Service) sceneToYml(scene uint64, configNs string, variables map[string]string) (string, error) {
 	sceneInputs, err := svc.ListAutoTestSceneInput(scene)
 	if err != nil {
 		return "", err
@@ -614,20 +765,30 @@ func (svc *Service) SceneToYml(scene uint64) (string, error) {
 		return "", err
 	}
 
-	return svc.DoSceneToYml(sceneSteps, sceneInputs, sceneOutputs)
+	sc, err := svc.db.GetAutotestScene(scene)
+	if err != nil {
+		return "", err
+	}
+	sceneSteps = applySceneStepTimeout(applyVariableOverrides(sceneSteps, variables), sc.StepTimeoutMs)
+
+	return svc.DoSceneToYml(sceneSteps, sceneInputs, sceneOutputs, configNs)
 }
 
-func (svc *Service) DoSceneToYml(sceneSteps []apistructs.AutoTestSceneStep, sceneInputs []apistructs.AutoTestSceneInput, sceneOutputs []apistructs.AutoTestSceneOutput) (string, error) {
-	sceneStages := StepToStages(sceneSteps)
+func (svc *Service) DoSceneToYml(sceneSteps []apistructs.AutoTestSceneStep, sceneInputs []apistructs.AutoTestSceneInput, sceneOutputs []apistructs.AutoTestSceneOutput, configNs string) (string, error) {
+	if err := checkSceneStepCycles(sceneSteps); err != nil {
+		return "", err
+	}
+	sceneStages := StepToStages(sceneSteps, conf.AutotestSceneMaxParallelSteps())
 
-	yml, err := SceneToPipelineYml(sceneInputs, sceneOutputs, sceneStages)
+	yml, err := SceneToPipelineYml(sceneInputs, sceneOutputs, sceneStages, svc.newGlobalConfigResolver(configNs), svc.newDataFileResolver())
 	if err != nil {
 		return "", err
 	}
 	return yml, err
 }
 
-func SceneToPipelineYml(inputs []apistructs.AutoTestSceneInput, outputs []apistructs.AutoTestSceneOutput, stages [][]apistructs.AutoTestSceneStep) (string, error) {
+func SceneToPipelineYml(inputs []apistructs.AutoTestSceneInput, outputs []apistructs.AutoTestSceneOutput, stages [][]apistructs.AutoTestSceneStep,
+	resolveGlobal globalConfigResolver, resolveDataFile dataFileResolver) (string, error) {
 	var spec pipelineyml.Spec
 	spec.Params = make([]*pipelineyml.PipelineParam, len(inputs))
 	spec.Outputs = make([]*pipelineyml.PipelineOutput, len(outputs))
@@ -648,6 +809,23 @@ func SceneToPipelineYml(inputs []apistructs.AutoTestSceneInput, outputs []apistr
 
 	var stagesValue []*pipelineyml.Stage
 	for _, stage := range stages {
+		// 循环步骤展开为多个串行的 stage
+		if loopStages, ok, err := loopStepToStages(stage, resolveGlobal, resolveDataFile); ok || err != nil {
+			if err != nil {
+				return "", err
+			}
+			stagesValue = append(stagesValue, loopStages...)
+			continue
+		}
+		// 轮询等待步骤展开为轮询和超时检查两个 stage
+		if pollStages, ok, err := waitPollStepToStages(stage); ok || err != nil {
+			if err != nil {
+				return "", err
+			}
+			stagesValue = append(stagesValue, pollStages...)
+			continue
+		}
+
 		var specStage pipelineyml.Stage
 		var index = 0
 		for _, step := range stage {
@@ -688,6 +866,10 @@ func StepToAction(step apistructs.AutoTestSceneStep) (map[pipelineyml.ActionType
 	action.Labels[apistructs.AutotestType] = apistructs.AutotestSceneStep
 	action.Alias = pipelineyml.ActionAlias(strconv.Itoa(int(step.ID)))
 	action.If = expression.LeftPlaceholder + " 1 == 1 " + expression.RightPlaceholder
+	// 条件不满足时流水线跳过该步骤, 状态与失败区分
+	if step.Condition != "" {
+		action.If = step.Condition
+	}
 
 	switch step.Type {
 	case apistructs.StepTypeCustomScript:
@@ -701,6 +883,16 @@ func StepToAction(step apistructs.AutoTestSceneStep) (map[pipelineyml.ActionType
 		action.Version = "1.0"
 		action.Commands = value.Commands
 		action.Image = value.Image
+	case apistructs.StepTypeJSScript:
+		var value apistructs.AutoTestRunJSScript
+		err := json.Unmarshal([]byte(step.Value), &value)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := jsScriptStepToAction(value, &action); err != nil {
+			return nil, err
+		}
 	case apistructs.StepTypeScene:
 		var value apistructs.AutoTestRunScene
 		err := json.Unmarshal([]byte(step.Value), &value)
@@ -730,12 +922,20 @@ func StepToAction(step apistructs.AutoTestSceneStep) (map[pipelineyml.ActionType
 			return nil, err
 		}
 
+		if err := compileJsonPathAsserts(value.ApiSpec); err != nil {
+			return nil, err
+		}
+
 		action.Type = "api-test"
 		action.Version = "2.0"
 		action.Params = value.ApiSpec
+		// 步骤自定义的循环优先于重试策略
 		if value.Loop != nil && value.Loop.Strategy != nil && value.Loop.Strategy.MaxTimes > 0 {
 			action.Loop = value.Loop
+		} else {
+			action.Loop = stepRetryLoop(step.ID, value.Retry)
 		}
+		setStepPolicyLabels(&action, value, step.DefaultTimeoutMs)
 	case apistructs.StepTypeWait:
 		var value apistructs.AutoTestRunWait
 		err := json.Unmarshal([]byte(step.Value), &value)
@@ -774,17 +974,93 @@ func StepToAction(step apistructs.AutoTestSceneStep) (map[pipelineyml.ActionType
 	}, nil
 }
 
-func StepToStages(steps []apistructs.AutoTestSceneStep) [][]apistructs.AutoTestSceneStep {
-	var stages = make([][]apistructs.AutoTestSceneStep, len(steps))
-	for index, step := range steps {
-		stages[index] = append(stages[index], step)
-		for _, childStep := range step.Children {
-			stages[index] = append(stages[index], childStep)
+// 匹配步骤中对其他步骤出参的引用, 如 ${{ outputs.123.name }}, 分组为被引用步骤的 ID
+var stepOutputRefRe = regexp.MustCompile(`\b` + expression.Outputs + `\.(\d+)\.`)
+
+// StepToStages 将步骤转换为流水线的 stage, 同一 stage 内的步骤并发执行, stage 之间串行执行.
+// 每组并行步骤按组内的出参引用拆分为多个 stage, 保证被引用的步骤先执行;
+// maxParallel 大于 0 时, 单个 stage 内的步骤数不超过 maxParallel.
+func StepToStages(steps []apistructs.AutoTestSceneStep, maxParallel int) [][]apistructs.AutoTestSceneStep {
+	var stages [][]apistructs.AutoTestSceneStep
+	for _, step := range steps {
+		group := append([]apistructs.AutoTestSceneStep{step}, step.Children...)
+		for _, layer := range layerParallelSteps(group) {
+			stages = append(stages, chunkSteps(layer, maxParallel)...)
 		}
 	}
 	return stages
 }
 
+// layerParallelSteps 按组内步骤间的出参引用分层, 每层只依赖之前的层; 步骤的值及执行条件中的引用都视为依赖,
+// 存在循环引用时剩余步骤逐个串行
+func layerParallelSteps(group []apistructs.AutoTestSceneStep) [][]apistructs.AutoTestSceneStep {
+	inGroup := make(map[uint64]bool, len(group))
+	for _, step := range group {
+		inGroup[step.ID] = true
+	}
+	deps := make(map[uint64][]uint64, len(group))
+	for _, step := range group {
+		for _, match := range stepOutputRefRe.FindAllStringSubmatch(step.Value+"\n"+step.Condition, -1) {
+			refID, err := strconv.ParseUint(match[1], 10, 64)
+			if err != nil || refID == step.ID || !inGroup[refID] {
+				continue
+			}
+			deps[step.ID] = append(deps[step.ID], refID)
+		}
+	}
+
+	var (
+		layers    [][]apistructs.AutoTestSceneStep
+		placed    = make(map[uint64]bool, len(group))
+		remaining = group
+	)
+	for len(remaining) > 0 {
+		var layer, rest []apistructs.AutoTestSceneStep
+		for _, step := range remaining {
+			ready := true
+			for _, dep := range deps[step.ID] {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				layer = append(layer, step)
+			} else {
+				rest = append(rest, step)
+			}
+		}
+		if len(layer) == 0 {
+			for _, step := range rest {
+				layers = append(layers, []apistructs.AutoTestSceneStep{step})
+			}
+			break
+		}
+		for _, step := range layer {
+			placed[step.ID] = true
+		}
+		layers = append(layers, layer)
+		remaining = rest
+	}
+	return layers
+}
+
+// chunkSteps 将步骤按 size 切分, size 小于等于 0 时不切分
+func chunkSteps(steps []apistructs.AutoTestSceneStep, size int) [][]apistructs.AutoTestSceneStep {
+	if size <= 0 || len(steps) <= size {
+		return [][]apistructs.AutoTestSceneStep{steps}
+	}
+	var chunks [][]apistructs.AutoTestSceneStep
+	for start := 0; start < len(steps); start += size {
+		end := start + size
+		if end > len(steps) {
+			end = len(steps)
+		}
+		chunks = append(chunks, steps[start:end])
+	}
+	return chunks
+}
+
 func (svc *Service) CancelDiceAutotestScene(req apistructs.AutotestCancelSceneRequest) error {
 	var autotestSceneRequest apistructs.AutotestSceneRequest
 	autotestSceneRequest.SceneID = req.AutoTestScene.ID
@@ -888,6 +1164,9 @@ func (svc *Service) CopyAutotestScene(req apistructs.AutotestSceneCopyRequest, i
 		CreatorID:   req.UserID,
 		Status:      apistructs.DefaultSceneStatus,
 		RefSetID:    oldScene.RefSetID,
+		Tags:        oldScene.Tags,
+
+		StepTimeoutMs: oldScene.StepTimeoutMs,
 	}
 
 	if err = svc.db.Insert(newScene, req.PreID); err != nil {
@@ -922,6 +1201,7 @@ func (svc *Service) CopyAutotestScene(req apistructs.AutotestSceneCopyRequest, i
 	var replaceIdMap = map[uint64]uint64{}
 	for _, v := range step {
 		v.Value = replacePreStepValue(v.Value, replaceIdMap)
+		v.Condition = replacePreStepValue(v.Condition, replaceIdMap)
 
 		newStep := &dao.AutoTestSceneStep{
 			Type:      v.Type,
@@ -932,6 +1212,7 @@ func (svc *Service) CopyAutotestScene(req apistructs.AutotestSceneCopyRequest, i
 			SceneID:   newId,
 			SpaceID:   req.SpaceID,
 			APISpecID: v.APISpecID,
+			Condition: v.Condition,
 			CreatorID: req.UserID,
 		}
 		if err := svc.db.CreateAutoTestSceneStep(newStep); err != nil {
@@ -943,6 +1224,7 @@ func (svc *Service) CopyAutotestScene(req apistructs.AutotestSceneCopyRequest, i
 		var childStepIdMap = map[uint64]uint64{}
 		for _, pv := range v.Children {
 			pv.Value = replacePreStepValue(pv.Value, replaceIdMap)
+			pv.Condition = replacePreStepValue(pv.Condition, replaceIdMap)
 
 			newPStep := &dao.AutoTestSceneStep{
 				Type:      pv.Type,
@@ -953,6 +1235,7 @@ func (svc *Service) CopyAutotestScene(req apistructs.AutotestSceneCopyRequest, i
 				SceneID:   newId,
 				SpaceID:   req.SpaceID,
 				APISpecID: pv.APISpecID,
+				Condition: pv.Condition,
 				CreatorID: req.UserID,
 			}
 
@@ -1093,7 +1376,8 @@ func sortAutoTestSceneList(list []apistructs.AutoTestScene, pageNo, pageSize uin
 	return rsp
 }
 
-func getList(list []dao.AutoTestScene, pageNo, pageSize uint64) []apistructs.AutoTestScene {
+// getList 按 pre_id 链表顺序分页, tags 不为空时只保留带有其中任一标签的场景, 同时返回筛选后的场景总数
+func getList(list []dao.AutoTestScene, pageNo, pageSize uint64, tags []string) (uint64, []apistructs.AutoTestScene) {
 	mp := make(map[uint64]dao.AutoTestScene)
 	var rsp []apistructs.AutoTestScene
 	for _, v := range list {
@@ -1108,6 +1392,9 @@ func getList(list []dao.AutoTestScene, pageNo, pageSize uint64) []apistructs.Aut
 			break
 		}
 		head = s.ID
+		if !matchSceneTags(s.Tags, tags) {
+			continue
+		}
 		index++
 		if index > l && index <= r {
 			sc := s.Convert()
@@ -1115,7 +1402,7 @@ func getList(list []dao.AutoTestScene, pageNo, pageSize uint64) []apistructs.Aut
 			rsp = append(rsp, sc)
 		}
 	}
-	return rsp
+	return index, rsp
 }
 
 func (svc *Service) GetAutotestScenesByIDs(sceneIDs []uint64) (map[uint64]apistructs.AutoTestScene, error) {
@@ -1186,6 +1473,16 @@ func (svc *Service) GetAutotestScenesByIDs(sceneIDs []uint64) (map[uint64]apistr
 		}
 		mp[v] = scene
 	}
+	// step timeout
+	scenes, err := svc.db.ListAutotestScenesByIDs(sceneIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range scenes {
+		s := mp[v.ID]
+		s.StepTimeoutMs = v.StepTimeoutMs
+		mp[v.ID] = s
+	}
 	// output
 	outputs, err := svc.db.ListAutoTestSceneOutputByScenes(sceneIDs)
 	if err != nil {
@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/apitestsv2"
+)
+
+const (
+	apiSpecKeyOutParams       = "out_params"
+	apiSpecKeyAsserts         = "asserts"
+	apiSpecKeyJsonPathAsserts = "jsonpath_asserts"
+)
+
+// validateJsonPathAsserts 校验 api 步骤中的 JSONPath 断言
+func validateJsonPathAsserts(stepValue string) error {
+	if stepValue == "" {
+		return nil
+	}
+	var value apistructs.AutoTestRunStep
+	if err := json.Unmarshal([]byte(stepValue), &value); err != nil {
+		return err
+	}
+	asserts, err := jsonPathAssertsOf(value.ApiSpec)
+	if err != nil {
+		return err
+	}
+	for _, ast := range asserts {
+		if err := apitestsv2.ValidateJsonPathAssert(ast); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileJsonPathAsserts 将 apiSpec 中的 JSONPath 断言转换为出参和断言, 追加到 apiSpec 中, 供 api-test action 执行
+func compileJsonPathAsserts(apiSpec map[string]interface{}) error {
+	asserts, err := jsonPathAssertsOf(apiSpec)
+	if err != nil || len(asserts) == 0 {
+		return err
+	}
+	outParams, results, err := apitestsv2.CompileJsonPathAsserts(asserts)
+	if err != nil {
+		return err
+	}
+	if err := appendToAPISpec(apiSpec, apiSpecKeyOutParams, outParams); err != nil {
+		return err
+	}
+	if err := appendToAPISpec(apiSpec, apiSpecKeyAsserts, results); err != nil {
+		return err
+	}
+	delete(apiSpec, apiSpecKeyJsonPathAsserts)
+	return nil
+}
+
+func jsonPathAssertsOf(apiSpec map[string]interface{}) ([]apistructs.APIJsonPathAssert, error) {
+	raw, ok := apiSpec[apiSpecKeyJsonPathAsserts]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var asserts []apistructs.APIJsonPathAssert
+	if err := json.Unmarshal(b, &asserts); err != nil {
+		return nil, err
+	}
+	return asserts, nil
+}
+
+// appendToAPISpec 将 items 追加到 apiSpec 中 key 对应的列表
+func appendToAPISpec(apiSpec map[string]interface{}, key string, items interface{}) error {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var appended []interface{}
+	if err := json.Unmarshal(b, &appended); err != nil {
+		return err
+	}
+	existing, _ := apiSpec[key].([]interface{})
+	apiSpec[key] = append(existing, appended...)
+	return nil
+}
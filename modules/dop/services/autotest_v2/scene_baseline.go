@@ -0,0 +1,349 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const (
+	// maxBaselineDiffs 单个步骤保存的最大差异数, 超出部分丢弃
+	maxBaselineDiffs = 100
+	// maxBaselineIgnorePaths 基线最多可以配置的忽略路径数
+	maxBaselineIgnorePaths = 100
+
+	baselineRootPath = "$"
+)
+
+// GetAutoTestSceneBaseline 获取场景的响应基线
+func (svc *Service) GetAutoTestSceneBaseline(sceneID uint64) (*apistructs.AutoTestSceneBaseline, error) {
+	baseline, err := svc.db.GetAutoTestSceneBaseline(sceneID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneBaseline.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSceneBaseline.InternalError(err)
+	}
+	steps, err := svc.db.ListAutoTestSceneBaselineSteps(baseline.ID)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneBaseline.InternalError(err)
+	}
+	return convertSceneBaseline(baseline, steps), nil
+}
+
+// SaveAutoTestSceneBaseline 以一次成功执行的响应作为场景的基线, 或更新基线的忽略路径
+func (svc *Service) SaveAutoTestSceneBaseline(req apistructs.AutoTestSceneBaselineSaveRequest) (*apistructs.AutoTestSceneBaseline, error) {
+	if len(req.IgnorePaths) > maxBaselineIgnorePaths {
+		return nil, apierrors.ErrSaveAutoTestSceneBaseline.InvalidParameter(fmt.Sprintf("最多配置 %d 个忽略路径", maxBaselineIgnorePaths))
+	}
+	if _, err := compileBaselineIgnorePaths(req.IgnorePaths); err != nil {
+		return nil, apierrors.ErrSaveAutoTestSceneBaseline.InvalidParameter(err)
+	}
+
+	baseline, err := svc.db.GetAutoTestSceneBaseline(req.SceneID)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrSaveAutoTestSceneBaseline.InternalError(err)
+		}
+		if req.ExecutionID == 0 {
+			return nil, apierrors.ErrSaveAutoTestSceneBaseline.MissingParameter("executionID")
+		}
+		baseline = &dao.AutoTestSceneBaseline{SceneID: req.SceneID, CreatorID: req.UserID}
+	}
+	baseline.IgnorePaths = req.IgnorePaths
+	baseline.UpdaterID = req.UserID
+
+	var steps []dao.AutoTestSceneBaselineStep
+	if req.ExecutionID > 0 {
+		execution, err := svc.db.GetAutoTestSceneExecution(req.ExecutionID)
+		if err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, apierrors.ErrSaveAutoTestSceneBaseline.InvalidParameter("执行记录不存在")
+			}
+			return nil, apierrors.ErrSaveAutoTestSceneBaseline.InternalError(err)
+		}
+		if execution.SceneID != req.SceneID {
+			return nil, apierrors.ErrSaveAutoTestSceneBaseline.InvalidParameter("执行记录不属于该场景")
+		}
+		if !execution.Status.IsSuccessStatus() {
+			return nil, apierrors.ErrSaveAutoTestSceneBaseline.InvalidState("只能以执行成功的记录作为基线")
+		}
+		executionSteps, err := svc.db.ListAutoTestSceneExecutionSteps(execution.ID)
+		if err != nil {
+			return nil, apierrors.ErrSaveAutoTestSceneBaseline.InternalError(err)
+		}
+		steps = make([]dao.AutoTestSceneBaselineStep, 0, len(executionSteps))
+		for _, step := range executionSteps {
+			if step.StepID == 0 || step.Response == "" {
+				continue
+			}
+			steps = append(steps, dao.AutoTestSceneBaselineStep{
+				StepID:   step.StepID,
+				StepName: step.StepName,
+				Response: step.Response,
+			})
+		}
+		baseline.ExecutionID = execution.ID
+	}
+	if err := svc.db.SaveAutoTestSceneBaseline(baseline, steps); err != nil {
+		return nil, apierrors.ErrSaveAutoTestSceneBaseline.InternalError(err)
+	}
+	return svc.GetAutoTestSceneBaseline(req.SceneID)
+}
+
+// DeleteAutoTestSceneBaseline 删除场景的响应基线, 之后的执行不再与基线对比
+func (svc *Service) DeleteAutoTestSceneBaseline(sceneID uint64) error {
+	baseline, err := svc.db.GetAutoTestSceneBaseline(sceneID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return apierrors.ErrDeleteAutoTestSceneBaseline.InternalError(err)
+	}
+	if err := svc.db.DeleteAutoTestSceneBaseline(baseline.ID); err != nil {
+		return apierrors.ErrDeleteAutoTestSceneBaseline.InternalError(err)
+	}
+	return nil
+}
+
+// compareSceneExecutionWithBaseline 将执行中各步骤的响应与场景的基线对比, 结果保存在执行记录中; 失败不影响执行结果的保存
+func (svc *Service) compareSceneExecutionWithBaseline(execution *dao.AutoTestSceneExecution, steps []dao.AutoTestSceneExecutionStep) {
+	baseline, err := svc.db.GetAutoTestSceneBaseline(execution.SceneID)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			logrus.Errorf("failed to get baseline of autotest scene %d, err: %v", execution.SceneID, err)
+		}
+		return
+	}
+	baselineSteps, err := svc.db.ListAutoTestSceneBaselineSteps(baseline.ID)
+	if err != nil {
+		logrus.Errorf("failed to list baseline steps of autotest scene %d, err: %v", execution.SceneID, err)
+		return
+	}
+	ignores, err := compileBaselineIgnorePaths(baseline.IgnorePaths)
+	if err != nil {
+		logrus.Errorf("invalid baseline ignore paths of autotest scene %d, err: %v", execution.SceneID, err)
+		return
+	}
+	execution.BaselineID = baseline.ID
+	execution.BaselineDrift = compareStepsWithBaseline(steps, baselineSteps, ignores)
+}
+
+// compareStepsWithBaseline 对比步骤响应与基线, 差异保存在步骤中, 返回是否存在差异
+func compareStepsWithBaseline(steps []dao.AutoTestSceneExecutionStep, baselineSteps []dao.AutoTestSceneBaselineStep, ignores []*regexp.Regexp) bool {
+	responses := make(map[uint64]string, len(baselineSteps))
+	for _, step := range baselineSteps {
+		responses[step.StepID] = step.Response
+	}
+	var drift bool
+	for i := range steps {
+		baselineResp, ok := responses[steps[i].StepID]
+		if !ok || steps[i].Response == "" {
+			continue
+		}
+		diffs, err := diffResponseSnapshot(baselineResp, steps[i].Response, ignores)
+		if err != nil {
+			logrus.Warnf("failed to compare response of step %d with baseline, err: %v", steps[i].StepID, err)
+			continue
+		}
+		if len(diffs) > 0 {
+			steps[i].BaselineDiff = diffs
+			drift = true
+		}
+	}
+	return drift
+}
+
+// diffResponseSnapshot 对比两个响应快照的 body, body 为 JSON 时逐字段对比, 否则整体对比
+func diffResponseSnapshot(baseline, current string, ignores []*regexp.Regexp) ([]apistructs.AutoTestBaselineFieldDiff, error) {
+	var baselineResp, currentResp apistructs.APIResp
+	if err := json.Unmarshal([]byte(baseline), &baselineResp); err != nil {
+		return nil, fmt.Errorf("invalid baseline response snapshot: %v", err)
+	}
+	if err := json.Unmarshal([]byte(current), &currentResp); err != nil {
+		return nil, fmt.Errorf("invalid response snapshot: %v", err)
+	}
+	baselineBody, baselineIsJson := decodeJsonBody(baselineResp.BodyStr)
+	currentBody, currentIsJson := decodeJsonBody(currentResp.BodyStr)
+	if !baselineIsJson || !currentIsJson {
+		baselineBody, currentBody = baselineResp.BodyStr, currentResp.BodyStr
+	}
+	d := &baselineDiffer{ignores: ignores}
+	d.diff(baselineRootPath, baselineBody, currentBody)
+	return d.diffs, nil
+}
+
+func decodeJsonBody(body string) (interface{}, bool) {
+	if strings.TrimSpace(body) == "" {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+type baselineDiffer struct {
+	ignores []*regexp.Regexp
+	diffs   []apistructs.AutoTestBaselineFieldDiff
+}
+
+func (d *baselineDiffer) diff(path string, baseline, current interface{}) {
+	if len(d.diffs) >= maxBaselineDiffs || d.ignored(path) {
+		return
+	}
+	switch b := baseline.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(b)+len(c))
+		for key := range b {
+			keys = append(keys, key)
+		}
+		for key := range c {
+			if _, ok := b[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			d.diffField(path+"."+key, b, c, key)
+		}
+		return
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(b) || i < len(c); i++ {
+			itemPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(c):
+				d.add(itemPath, apistructs.AutoTestBaselineDiffRemoved, b[i], nil)
+			case i >= len(b):
+				d.add(itemPath, apistructs.AutoTestBaselineDiffAdded, nil, c[i])
+			default:
+				d.diff(itemPath, b[i], c[i])
+			}
+		}
+		return
+	}
+	if !jsonValueEqual(baseline, current) {
+		d.add(path, apistructs.AutoTestBaselineDiffChanged, baseline, current)
+	}
+}
+
+func (d *baselineDiffer) diffField(path string, baseline, current map[string]interface{}, key string) {
+	b, inBaseline := baseline[key]
+	c, inCurrent := current[key]
+	switch {
+	case !inCurrent:
+		d.add(path, apistructs.AutoTestBaselineDiffRemoved, b, nil)
+	case !inBaseline:
+		d.add(path, apistructs.AutoTestBaselineDiffAdded, nil, c)
+	default:
+		d.diff(path, b, c)
+	}
+}
+
+func (d *baselineDiffer) add(path string, typ apistructs.AutoTestBaselineDiffType, baseline, current interface{}) {
+	if len(d.diffs) >= maxBaselineDiffs || d.ignored(path) {
+		return
+	}
+	diff := apistructs.AutoTestBaselineFieldDiff{Path: path, Type: typ}
+	if typ != apistructs.AutoTestBaselineDiffAdded {
+		diff.Baseline = jsonValueString(baseline)
+	}
+	if typ != apistructs.AutoTestBaselineDiffRemoved {
+		diff.Current = jsonValueString(current)
+	}
+	d.diffs = append(d.diffs, diff)
+}
+
+func (d *baselineDiffer) ignored(path string) bool {
+	for _, re := range d.ignores {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonValueEqual(a, b interface{}) bool {
+	return jsonValueString(a) == jsonValueString(b)
+}
+
+func jsonValueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		b, _ := json.Marshal(s)
+		return string(b)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// compileBaselineIgnorePaths 将忽略路径转换为正则, 匹配路径本身及其子字段.
+// 路径以 $ 开头, 省略时视为从根开始; [*] 匹配任意下标, * 匹配任意一级字段
+func compileBaselineIgnorePaths(paths []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, fmt.Errorf("empty ignore path")
+		}
+		if !strings.HasPrefix(path, baselineRootPath) {
+			path = baselineRootPath + "." + strings.TrimPrefix(path, ".")
+		}
+		pattern := regexp.QuoteMeta(path)
+		pattern = strings.ReplaceAll(pattern, `\[\*\]`, `\[\d+\]`)
+		pattern = strings.ReplaceAll(pattern, `\*`, `[^.\[]+`)
+		re, err := regexp.Compile(`^` + pattern + `(?:$|[.\[])`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore path %q: %v", path, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func convertSceneBaseline(baseline *dao.AutoTestSceneBaseline, steps []dao.AutoTestSceneBaselineStep) *apistructs.AutoTestSceneBaseline {
+	result := baseline.Convert()
+	result.StepIDs = make([]uint64, 0, len(steps))
+	for _, step := range steps {
+		result.StepIDs = append(result.StepIDs, step.StepID)
+	}
+	return &result
+}
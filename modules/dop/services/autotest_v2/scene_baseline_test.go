@@ -0,0 +1,95 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func respSnapshot(t *testing.T, body string) string {
+	b, err := json.Marshal(apistructs.APIResp{Status: 200, BodyStr: body})
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestDiffResponseSnapshot(t *testing.T) {
+	baseline := respSnapshot(t, `{"code":0,"data":{"id":1,"name":"a","createdAt":"2021-09-01","list":[{"id":1,"v":1},{"id":2,"v":2}],"old":true}}`)
+	current := respSnapshot(t, `{"code":0,"data":{"id":2,"name":"b","createdAt":"2021-09-02","list":[{"id":3,"v":1}],"new":1}}`)
+
+	ignores, err := compileBaselineIgnorePaths([]string{"$.data.id", "data.createdAt", "$.data.list[*].id"})
+	assert.NoError(t, err)
+	diffs, err := diffResponseSnapshot(baseline, current, ignores)
+	assert.NoError(t, err)
+	assert.Equal(t, []apistructs.AutoTestBaselineFieldDiff{
+		{Path: "$.data.list[1]", Type: apistructs.AutoTestBaselineDiffRemoved, Baseline: `{"id":2,"v":2}`},
+		{Path: "$.data.name", Type: apistructs.AutoTestBaselineDiffChanged, Baseline: `"a"`, Current: `"b"`},
+		{Path: "$.data.new", Type: apistructs.AutoTestBaselineDiffAdded, Current: `1`},
+		{Path: "$.data.old", Type: apistructs.AutoTestBaselineDiffRemoved, Baseline: `true`},
+	}, diffs)
+
+	// body 不是 JSON 时整体对比
+	diffs, err = diffResponseSnapshot(respSnapshot(t, "ok"), respSnapshot(t, "failed"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []apistructs.AutoTestBaselineFieldDiff{
+		{Path: "$", Type: apistructs.AutoTestBaselineDiffChanged, Baseline: `"ok"`, Current: `"failed"`},
+	}, diffs)
+
+	// 类型变化
+	diffs, err = diffResponseSnapshot(respSnapshot(t, `{"a":[1]}`), respSnapshot(t, `{"a":{"b":1}}`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []apistructs.AutoTestBaselineFieldDiff{
+		{Path: "$.a", Type: apistructs.AutoTestBaselineDiffChanged, Baseline: `[1]`, Current: `{"b":1}`},
+	}, diffs)
+
+	// 截断的快照无法对比
+	_, err = diffResponseSnapshot(baseline, current[:10], nil)
+	assert.Error(t, err)
+}
+
+func TestCompileBaselineIgnorePaths(t *testing.T) {
+	ignores, err := compileBaselineIgnorePaths([]string{"$.data.*.updatedAt", "$.meta"})
+	assert.NoError(t, err)
+	d := &baselineDiffer{ignores: ignores}
+	assert.True(t, d.ignored("$.data.user.updatedAt"))
+	assert.True(t, d.ignored("$.meta"))
+	assert.True(t, d.ignored("$.meta.requestId"))
+	assert.False(t, d.ignored("$.metadata"))
+	assert.False(t, d.ignored("$.data.user.name"))
+
+	_, err = compileBaselineIgnorePaths([]string{" "})
+	assert.Error(t, err)
+}
+
+func TestCompareStepsWithBaseline(t *testing.T) {
+	steps := []dao.AutoTestSceneExecutionStep{
+		{StepID: 1, Response: respSnapshot(t, `{"a":1}`)},
+		{StepID: 2, Response: respSnapshot(t, `{"a":1}`)},
+		{StepID: 3, Response: respSnapshot(t, `{"a":1}`)},
+	}
+	baselineSteps := []dao.AutoTestSceneBaselineStep{
+		{StepID: 1, Response: respSnapshot(t, `{"a":1}`)},
+		{StepID: 2, Response: respSnapshot(t, `{"a":2}`)},
+	}
+	assert.True(t, compareStepsWithBaseline(steps, baselineSteps, nil))
+	assert.Empty(t, steps[0].BaselineDiff)
+	assert.Len(t, steps[1].BaselineDiff, 1)
+	assert.Empty(t, steps[2].BaselineDiff)
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const defaultSceneDataFileMaxSize int64 = 1 << 20
+
+var dataFileColumnRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// sceneDataFile 解析后的 CSV 数据文件
+type sceneDataFile struct {
+	columns []string
+	rows    [][]string
+}
+
+// items 将每行转为以列名为字段的循环项
+func (f *sceneDataFile) items() []interface{} {
+	items := make([]interface{}, 0, len(f.rows))
+	for _, row := range f.rows {
+		item := make(map[string]interface{}, len(f.columns))
+		for i, column := range f.columns {
+			item[column] = row[i]
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// dataFileResolver 根据 ID 查询并解析场景数据文件
+type dataFileResolver func(id uint64) (*sceneDataFile, error)
+
+// newDataFileResolver 返回从数据库中查询数据文件的 resolver, 同一文件只解析一次
+func (svc *Service) newDataFileResolver() dataFileResolver {
+	files := make(map[uint64]*sceneDataFile)
+	return func(id uint64) (*sceneDataFile, error) {
+		if file, ok := files[id]; ok {
+			return file, nil
+		}
+		record, err := svc.db.GetAutoTestSceneDataFile(id)
+		if err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, fmt.Errorf("data file %d not found", id)
+			}
+			return nil, err
+		}
+		file, err := parseSceneDataFile([]byte(record.Content))
+		if err != nil {
+			return nil, fmt.Errorf("data file %s: %v", record.Name, err)
+		}
+		files[id] = file
+		return file, nil
+	}
+}
+
+// CreateAutoTestSceneDataFile 为场景添加 CSV 数据文件, r 为空时下载请求中引用的文件
+func (svc *Service) CreateAutoTestSceneDataFile(req apistructs.AutoTestSceneDataFileCreateRequest, r io.Reader) (*apistructs.AutoTestSceneDataFile, error) {
+	scene, err := svc.db.GetAutotestScene(req.SceneID)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(err)
+	}
+	sp, err := svc.GetSpace(scene.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !sp.IsOpen() {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InvalidState("所属测试空间已锁定")
+	}
+	if req.Name == "" {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.MissingParameter("name")
+	}
+	if err := strutil.Validate(req.Name, strutil.MaxRuneCountValidator(nameMaxLength)); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(err)
+	}
+	count, err := svc.db.CountAutoTestSceneDataFileByName(scene.ID, req.Name)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InternalError(err)
+	}
+	if count > 0 {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.AlreadyExists()
+	}
+
+	if r == nil {
+		if req.DiceFileUUID == "" {
+			return nil, apierrors.ErrCreateAutoTestSceneDataFile.MissingParameter("file or diceFileUUID")
+		}
+		f, err := svc.bdl.DownloadDiceFile(req.DiceFileUUID)
+		if err != nil {
+			return nil, apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(err)
+		}
+		defer f.Close()
+		r = f
+	}
+	maxSize := sceneDataFileMaxSize()
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InternalError(err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(fmt.Sprintf("数据文件不能超过 %d 字节", maxSize))
+	}
+	file, err := parseSceneDataFile(content)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InvalidParameter(fmt.Sprintf("无效的 CSV 文件: %v", err))
+	}
+
+	record := dao.AutoTestSceneDataFile{
+		SpaceID:   scene.SpaceID,
+		SceneID:   scene.ID,
+		Name:      req.Name,
+		Columns:   file.columns,
+		RowCount:  len(file.rows),
+		Size:      int64(len(content)),
+		Content:   string(content),
+		CreatorID: req.UserID,
+	}
+	if err := svc.db.CreateAutoTestSceneDataFile(&record); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneDataFile.InternalError(err)
+	}
+	result := record.Convert()
+	return &result, nil
+}
+
+// GetAutoTestSceneDataFile 获取场景数据文件
+func (svc *Service) GetAutoTestSceneDataFile(id uint64) (*dao.AutoTestSceneDataFile, error) {
+	file, err := svc.db.GetAutoTestSceneDataFile(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneDataFile.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSceneDataFile.InternalError(err)
+	}
+	return file, nil
+}
+
+// ListAutoTestSceneDataFiles 查询场景的数据文件
+func (svc *Service) ListAutoTestSceneDataFiles(sceneID uint64) ([]apistructs.AutoTestSceneDataFile, error) {
+	files, err := svc.db.ListAutoTestSceneDataFiles(sceneID)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSceneDataFile.InternalError(err)
+	}
+	results := make([]apistructs.AutoTestSceneDataFile, 0, len(files))
+	for _, file := range files {
+		results = append(results, file.Convert())
+	}
+	return results, nil
+}
+
+// DeleteAutoTestSceneDataFile 删除场景数据文件, 仍被循环步骤引用时拒绝删除并返回引用的步骤
+func (svc *Service) DeleteAutoTestSceneDataFile(id uint64) error {
+	file, err := svc.db.GetAutoTestSceneDataFile(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return apierrors.ErrDeleteAutoTestSceneDataFile.NotFound()
+		}
+		return apierrors.ErrDeleteAutoTestSceneDataFile.InternalError(err)
+	}
+	steps, err := svc.db.ListAutoTestSpaceLoopSteps(file.SpaceID)
+	if err != nil {
+		return apierrors.ErrDeleteAutoTestSceneDataFile.InternalError(err)
+	}
+	if refs := dataFileReferences(steps, id); len(refs) > 0 {
+		return apierrors.ErrDeleteAutoTestSceneDataFile.InvalidState(fmt.Sprintf("数据文件仍被以下循环步骤引用: %s", strings.Join(refs, ", ")))
+	}
+	if err := svc.db.DeleteAutoTestSceneDataFile(id); err != nil {
+		return apierrors.ErrDeleteAutoTestSceneDataFile.InternalError(err)
+	}
+	return nil
+}
+
+// dataFileReferences 返回引用了数据文件的循环步骤, 格式为 场景ID/步骤名称(步骤ID)
+func dataFileReferences(steps []dao.AutoTestSceneStep, fileID uint64) []string {
+	var refs []string
+	for _, step := range steps {
+		if step.Type != apistructs.StepTypeLoop || step.Value == "" {
+			continue
+		}
+		var loop apistructs.AutoTestRunLoop
+		if err := json.Unmarshal([]byte(step.Value), &loop); err != nil || loop.DataFileID != fileID {
+			continue
+		}
+		refs = append(refs, fmt.Sprintf("%d/%s(%d)", step.SceneID, step.Name, step.ID))
+	}
+	return refs
+}
+
+// validateLoopStep 校验循环步骤, 引用数据文件时文件需属于同一测试空间, 且表头包含子步骤引用的全部列
+func (svc *Service) validateLoopStep(step *dao.AutoTestSceneStep, value string) error {
+	if value == "" {
+		return nil
+	}
+	var loop apistructs.AutoTestRunLoop
+	if err := json.Unmarshal([]byte(value), &loop); err != nil {
+		return apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("无效的循环步骤: %v", err))
+	}
+	if loop.DataFileID == 0 {
+		return nil
+	}
+	if len(loop.Items) > 0 || loop.ItemsRef != "" {
+		return apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter("引用数据文件时不能同时指定 items 或 itemsRef")
+	}
+	file, err := svc.db.GetAutoTestSceneDataFile(loop.DataFileID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter("数据文件不存在")
+		}
+		return apierrors.ErrUpdateAutoTestSceneStep.InternalError(err)
+	}
+	if file.SpaceID != step.SpaceID {
+		return apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter("数据文件不属于当前测试空间")
+	}
+	if err := checkLoopDataFileColumns(loop.Steps, file.Columns); err != nil {
+		return apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("数据文件表头与步骤不匹配: %v", err))
+	}
+	return nil
+}
+
+// checkLoopDataFileColumns 检查子步骤通过 ${{ loop.item.<column> }} 引用的列是否都在表头中
+func checkLoopDataFileColumns(steps []apistructs.AutoTestSceneStep, columns []string) error {
+	header := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		header[column] = true
+	}
+	missing := make(map[string]bool)
+	for _, step := range steps {
+		for _, text := range []string{step.Value, step.Condition} {
+			for _, subs := range pexpr.PhRe.FindAllStringSubmatch(text, -1) {
+				ss := strings.Split(strings.Trim(subs[1], " "), ".")
+				if len(ss) < 3 || ss[0] != loopVarPrefix || ss[1] != loopVarItem {
+					continue
+				}
+				if !header[ss[2]] {
+					missing[ss[2]] = true
+				}
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("columns %s are referenced but missing in the header", strings.Join(names, ", "))
+}
+
+// parseSceneDataFile 解析 CSV, 第一行为表头, 列名用于 ${{ loop.item.<column> }} 引用, 行数不能超过循环的最大迭代次数
+func parseSceneDataFile(content []byte) (*sceneDataFile, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+	columns := records[0]
+	seen := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		if !dataFileColumnRe.MatchString(column) {
+			return nil, fmt.Errorf("invalid column name %q, only letters, digits, underscores and hyphens are allowed", column)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("duplicate column %s", column)
+		}
+		seen[column] = true
+	}
+	rows := records[1:]
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no data rows")
+	}
+	if max := maxLoopIterations(); len(rows) > max {
+		return nil, fmt.Errorf("%d rows exceeds the limit of %d", len(rows), max)
+	}
+	return &sceneDataFile{columns: columns, rows: rows}, nil
+}
+
+func sceneDataFileMaxSize() int64 {
+	if max := conf.AutotestSceneDataFileMaxSize(); max > 0 {
+		return max
+	}
+	return defaultSceneDataFileMaxSize
+}
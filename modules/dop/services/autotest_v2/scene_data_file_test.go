@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestParseSceneDataFile(t *testing.T) {
+	file, err := parseSceneDataFile([]byte("\xef\xbb\xbfuser,password\nalice,\"a,1\"\nbob,b2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user", "password"}, file.columns)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"user": "alice", "password": "a,1"},
+		map[string]interface{}{"user": "bob", "password": "b2"},
+	}, file.items())
+
+	for _, content := range []string{
+		"",
+		"user,password\n",
+		"user,user\nalice,bob\n",
+		"user.name\nalice\n",
+		"user,password\nalice\n",
+	} {
+		_, err := parseSceneDataFile([]byte(content))
+		assert.Error(t, err, content)
+	}
+
+	rows := []string{"user"}
+	for i := 0; i <= maxLoopIterations(); i++ {
+		rows = append(rows, fmt.Sprintf("u%d", i))
+	}
+	_, err = parseSceneDataFile([]byte(strings.Join(rows, "\n")))
+	assert.Error(t, err)
+}
+
+func TestCheckLoopDataFileColumns(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		{Value: `{"body":"${{ loop.item.user }}:${{ loop.item.password }}","index":"${{ loop.index }}"}`},
+		{Value: `{}`, Condition: `"${{ loop.item.role }}" == "admin"`},
+	}
+	assert.NoError(t, checkLoopDataFileColumns(steps, []string{"user", "password", "role"}))
+	err := checkLoopDataFileColumns(steps, []string{"user"})
+	assert.EqualError(t, err, "columns password, role are referenced but missing in the header")
+}
+
+func TestLoopItemsFromDataFile(t *testing.T) {
+	resolver := func(id uint64) (*sceneDataFile, error) {
+		if id != 1 {
+			return nil, fmt.Errorf("data file %d not found", id)
+		}
+		return &sceneDataFile{columns: []string{"user"}, rows: [][]string{{"alice"}, {"bob"}}}, nil
+	}
+	loop := apistructs.AutoTestRunLoop{
+		DataFileID: 1,
+		Steps:      []apistructs.AutoTestSceneStep{{Value: `{"user":"${{ loop.item.user }}"}`}},
+	}
+	items, err := loopItems(loop, nil, resolver)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"user": "alice"}, map[string]interface{}{"user": "bob"}}, items)
+
+	loop.Steps = []apistructs.AutoTestSceneStep{{Value: `{"user":"${{ loop.item.name }}"}`}}
+	_, err = loopItems(loop, nil, resolver)
+	assert.Error(t, err)
+
+	loop.DataFileID = 2
+	_, err = loopItems(loop, nil, resolver)
+	assert.Error(t, err)
+	_, err = loopItems(loop, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestDataFileReferences(t *testing.T) {
+	steps := []dao.AutoTestSceneStep{
+		{BaseModel: dbengine.BaseModel{ID: 1}, SceneID: 10, Name: "login", Type: apistructs.StepTypeLoop, Value: `{"dataFileID":1,"steps":[]}`},
+		{BaseModel: dbengine.BaseModel{ID: 2}, SceneID: 10, Name: "other", Type: apistructs.StepTypeLoop, Value: `{"dataFileID":2,"steps":[]}`},
+		{BaseModel: dbengine.BaseModel{ID: 3}, SceneID: 11, Name: "inline", Type: apistructs.StepTypeLoop, Value: `{"items":[1],"steps":[]}`},
+		{BaseModel: dbengine.BaseModel{ID: 4}, SceneID: 11, Name: "empty", Type: apistructs.StepTypeLoop},
+		{BaseModel: dbengine.BaseModel{ID: 5}, SceneID: 12, Name: "api", Type: apistructs.StepTypeAPI, Value: `{"dataFileID":1}`},
+		{BaseModel: dbengine.BaseModel{ID: 6}, SceneID: 12, Name: "login", Type: apistructs.StepTypeLoop, Value: `{"dataFileID":1,"steps":[]}`},
+	}
+	assert.Equal(t, []string{"10/login(1)", "12/login(6)"}, dataFileReferences(steps, 1))
+	assert.Equal(t, []string{"10/other(2)"}, dataFileReferences(steps, 2))
+	assert.Empty(t, dataFileReferences(steps, 3))
+}
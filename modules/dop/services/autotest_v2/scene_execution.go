@@ -0,0 +1,240 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const (
+	// sceneExecutionSnapshotMaxSize 单个请求或响应快照保存的最大长度, 超出部分截断
+	sceneExecutionSnapshotMaxSize = 64 * 1024
+	// sceneExecutionPurgeBatchSize 清理过期执行记录时每批删除的数量
+	sceneExecutionPurgeBatchSize = 500
+
+	metaKeyAPIRequest       = "api_request"
+	metaKeyAPIResponse      = "api_response"
+	metaKeyAPIAssertSuccess = "api_assert_success"
+	metaKeyAPIAssertDetail  = "api_assert_detail"
+	metaKeyAPITimeoutMs     = "api_timeout_ms"
+	metaKeyAPIDurationMs    = "api_duration_ms"
+)
+
+// recordSceneExecution 记录场景的一次执行, 失败不影响执行本身
+func (svc *Service) recordSceneExecution(scene *apistructs.AutoTestScene, pipelineDTO *apistructs.PipelineDTO, req apistructs.AutotestExecuteSceneRequest) {
+	// 仅记录覆盖的变量名, 变量值可能包含敏感信息
+	variableNames := make([]string, 0, len(req.Variables))
+	for name := range req.Variables {
+		variableNames = append(variableNames, name)
+	}
+	sort.Strings(variableNames)
+	execution := dao.AutoTestSceneExecution{
+		SceneID:         scene.ID,
+		SpaceID:         scene.SpaceID,
+		PipelineID:      pipelineDTO.ID,
+		Status:          pipelineDTO.Status,
+		CostTimeSec:     -1,
+		Environment:     req.Environment,
+		ConfigNamespace: req.ConfigManageNamespaces,
+		VariableNames:   strings.Join(variableNames, ","),
+		CreatorID:       req.IdentityInfo.UserID,
+	}
+	if err := svc.db.CreateAutoTestSceneExecution(&execution); err != nil {
+		logrus.Errorf("failed to record execution of autotest scene %d, pipelineID: %d, err: %v", scene.ID, pipelineDTO.ID, err)
+	}
+}
+
+// FinishAutoTestSceneExecution 流水线结束后保存场景执行结果及各步骤的请求响应快照
+func (svc *Service) FinishAutoTestSceneExecution(pipelineID uint64) error {
+	execution, err := svc.db.GetAutoTestSceneExecutionByPipelineID(pipelineID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			// 非场景执行的流水线
+			return nil
+		}
+		return err
+	}
+	if execution.Status.IsEndStatus() {
+		return nil
+	}
+
+	pipelineDetail, err := svc.bdl.GetPipeline(pipelineID)
+	if err != nil {
+		return err
+	}
+	if !pipelineDetail.Status.IsEndStatus() {
+		return nil
+	}
+	execution.Status = pipelineDetail.Status
+	execution.CostTimeSec = pipelineDetail.CostTimeSec
+	execution.TimeBegin = pipelineDetail.TimeBegin
+	execution.TimeEnd = pipelineDetail.TimeEnd
+
+	var steps []dao.AutoTestSceneExecutionStep
+	for _, stage := range pipelineDetail.PipelineStages {
+		for _, task := range stage.PipelineTasks {
+			steps = append(steps, convertTaskToExecutionStep(task))
+		}
+	}
+	if execution.Status.IsSuccessStatus() || execution.Status.IsFailedStatus() {
+		svc.compareSceneExecutionWithBaseline(execution, steps)
+	}
+	if err := svc.db.FinishAutoTestSceneExecution(execution, steps); err != nil {
+		return err
+	}
+	svc.notifySceneWebhooks(execution, steps)
+	return nil
+}
+
+// GetAutoTestSceneExecution 获取场景执行详情, 包含各步骤的执行结果
+func (svc *Service) GetAutoTestSceneExecution(id uint64) (*apistructs.AutoTestSceneExecution, error) {
+	execution, err := svc.db.GetAutoTestSceneExecution(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneExecution.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSceneExecution.InternalError(err)
+	}
+	steps, err := svc.db.ListAutoTestSceneExecutionSteps(id)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneExecution.InternalError(err)
+	}
+	result := execution.Convert()
+	result.Steps = make([]apistructs.AutoTestSceneExecutionStep, 0, len(steps))
+	for _, step := range steps {
+		result.Steps = append(result.Steps, step.Convert())
+	}
+	return &result, nil
+}
+
+// ListAutoTestSceneExecutions 分页查询场景的执行记录, 按执行时间倒序
+func (svc *Service) ListAutoTestSceneExecutions(req apistructs.AutoTestSceneExecutionListRequest) (*apistructs.AutoTestSceneExecutionPagingData, error) {
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	total, executions, err := svc.db.PagingAutoTestSceneExecutions(req)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSceneExecutions.InternalError(err)
+	}
+	result := &apistructs.AutoTestSceneExecutionPagingData{
+		Total: total,
+		List:  make([]apistructs.AutoTestSceneExecution, 0, len(executions)),
+	}
+	for _, execution := range executions {
+		result.List = append(result.List, execution.Convert())
+	}
+	return result, nil
+}
+
+// PurgeAutoTestSceneExecutions 删除超过保留天数的场景执行记录
+func (svc *Service) PurgeAutoTestSceneExecutions() {
+	retentionDays := conf.AutotestSceneExecutionRetentionDays()
+	if retentionDays <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -retentionDays)
+	for {
+		deleted, err := svc.db.DeleteAutoTestSceneExecutionsBefore(before, sceneExecutionPurgeBatchSize)
+		if err != nil {
+			logrus.Errorf("failed to purge autotest scene executions, err: %v", err)
+			return
+		}
+		if deleted < sceneExecutionPurgeBatchSize {
+			return
+		}
+	}
+}
+
+// convertTaskToExecutionStep 从流水线任务中提取步骤的执行结果及快照
+func convertTaskToExecutionStep(task apistructs.PipelineTaskDTO) dao.AutoTestSceneExecutionStep {
+	step := dao.AutoTestSceneExecutionStep{
+		TaskName:    task.Name,
+		StepName:    task.Name,
+		Status:      task.Status,
+		CostTimeSec: task.CostTimeSec,
+	}
+	if !task.TimeBegin.IsZero() {
+		timeBegin := task.TimeBegin
+		step.TimeBegin = &timeBegin
+	}
+	if !task.TimeEnd.IsZero() {
+		timeEnd := task.TimeEnd
+		step.TimeEnd = &timeEnd
+	}
+	if encoded, ok := task.Labels[apistructs.AutotestSceneStep]; ok {
+		var sceneStep apistructs.AutoTestSceneStep
+		if b, err := base64.StdEncoding.DecodeString(encoded); err == nil && json.Unmarshal(b, &sceneStep) == nil {
+			step.StepID = sceneStep.ID
+			step.StepName = sceneStep.Name
+			step.StepType = sceneStep.Type
+		}
+	}
+	for _, meta := range task.Result.Metadata {
+		switch meta.Name {
+		case metaKeyAPIRequest:
+			step.Request = truncateSnapshot(meta.Value)
+		case metaKeyAPIResponse:
+			step.Response = truncateSnapshot(meta.Value)
+		case metaKeyAPIAssertSuccess:
+			step.AssertSuccess = meta.Value
+		case metaKeyAPIAssertDetail:
+			step.AssertDetail = truncateSnapshot(meta.Value)
+		case metaKeyAPITimeoutMs:
+			step.TimeoutMs, _ = strconv.ParseInt(meta.Value, 10, 64)
+		case metaKeyAPIDurationMs:
+			step.DurationMs, _ = strconv.ParseInt(meta.Value, 10, 64)
+		}
+	}
+	var msgs []string
+	for _, e := range task.Result.Errors {
+		if e != nil && e.Msg != "" {
+			msgs = append(msgs, e.Msg)
+		}
+	}
+	step.Message = truncateString(strings.Join(msgs, "; "), 1024)
+	return step
+}
+
+func truncateSnapshot(s string) string {
+	return truncateString(s, sceneExecutionSnapshotMaxSize)
+}
+
+// truncateString 按字节截断, 保证不截断多字节字符
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestConvertTaskToExecutionStep(t *testing.T) {
+	stepJson, err := json.Marshal(apistructs.AutoTestSceneStep{
+		AutoTestSceneParams: apistructs.AutoTestSceneParams{ID: 12},
+		Name:                "login",
+		Type:                apistructs.StepTypeAPI,
+	})
+	assert.NoError(t, err)
+
+	step := convertTaskToExecutionStep(apistructs.PipelineTaskDTO{
+		Name:        "12",
+		Status:      apistructs.PipelineStatusFailed,
+		CostTimeSec: 3,
+		Labels: map[string]string{
+			apistructs.AutotestSceneStep: base64.StdEncoding.EncodeToString(stepJson),
+		},
+		Result: apistructs.PipelineTaskResult{
+			Metadata: apistructs.Metadata{
+				{Name: metaKeyAPIRequest, Value: `{"url":"/login"}`},
+				{Name: metaKeyAPIResponse, Value: strings.Repeat("a", sceneExecutionSnapshotMaxSize+1)},
+				{Name: metaKeyAPIAssertSuccess, Value: "false"},
+			},
+			Errors: []*apistructs.PipelineTaskErrResponse{{Msg: "assert failed"}},
+		},
+	})
+	assert.Equal(t, uint64(12), step.StepID)
+	assert.Equal(t, "login", step.StepName)
+	assert.Equal(t, apistructs.StepTypeAPI, step.StepType)
+	assert.Equal(t, apistructs.PipelineStatusFailed, step.Status)
+	assert.Equal(t, `{"url":"/login"}`, step.Request)
+	assert.Equal(t, sceneExecutionSnapshotMaxSize, len(step.Response))
+	assert.Equal(t, "false", step.AssertSuccess)
+	assert.Equal(t, "assert failed", step.Message)
+	assert.Nil(t, step.TimeBegin)
+}
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "abc", truncateString("abc", 5))
+	assert.Equal(t, "ab", truncateString("abc", 2))
+	// 不截断多字节字符
+	assert.Equal(t, "a", truncateString("a中", 2))
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// 匹配步骤中对其他步骤出参的引用, 如 ${{ outputs.123.name }}, 分组为被引用步骤的 ID 及出参名
+var stepOutputNameRefRe = regexp.MustCompile(`\b` + expression.Outputs + `\.(\d+)\.([^\s.}'"]+)`)
+
+// GetAutoTestSceneStepGraph 获取场景步骤间的依赖图
+func (svc *Service) GetAutoTestSceneStepGraph(sceneID uint64) (*apistructs.AutoTestSceneStepGraph, error) {
+	steps, err := svc.ListAutoTestSceneStep(sceneID)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneStepGraph.InternalError(err)
+	}
+	graph := BuildSceneStepGraph(steps)
+	return &graph, nil
+}
+
+// BuildSceneStepGraph 根据步骤的内容及执行条件中对其他步骤出参的引用构建依赖图, 并找出其中的循环引用.
+// 步骤引用自身的出参 (如轮询等待的条件) 不视为依赖
+func BuildSceneStepGraph(steps []apistructs.AutoTestSceneStep) apistructs.AutoTestSceneStepGraph {
+	graph := apistructs.AutoTestSceneStepGraph{
+		Nodes:  []apistructs.AutoTestSceneStepGraphNode{},
+		Edges:  []apistructs.AutoTestSceneStepGraphEdge{},
+		Cycles: [][]uint64{},
+	}
+	var flat []apistructs.AutoTestSceneStep
+	nodeIndex := make(map[uint64]int)
+	for groupIndex, step := range steps {
+		for _, s := range append([]apistructs.AutoTestSceneStep{step}, step.Children...) {
+			nodeIndex[s.ID] = len(graph.Nodes)
+			graph.Nodes = append(graph.Nodes, apistructs.AutoTestSceneStepGraphNode{
+				ID:         s.ID,
+				Name:       s.Name,
+				Type:       s.Type,
+				GroupIndex: groupIndex,
+			})
+			flat = append(flat, s)
+		}
+	}
+
+	adjacency := make(map[uint64][]uint64)
+	for i, step := range flat {
+		outputs := make(map[uint64][]string)
+		var from []uint64
+		for _, match := range stepOutputNameRefRe.FindAllStringSubmatch(step.Value+"\n"+step.Condition, -1) {
+			refID, err := strconv.ParseUint(match[1], 10, 64)
+			if err != nil || refID == step.ID {
+				continue
+			}
+			if _, ok := outputs[refID]; !ok {
+				from = append(from, refID)
+			}
+			if !strutil.Exist(outputs[refID], match[2]) {
+				outputs[refID] = append(outputs[refID], match[2])
+			}
+		}
+		sort.Slice(from, func(a, b int) bool { return from[a] < from[b] })
+		for _, refID := range from {
+			if _, ok := nodeIndex[refID]; !ok {
+				graph.Nodes[i].Unresolved = append(graph.Nodes[i].Unresolved, refID)
+				continue
+			}
+			sort.Strings(outputs[refID])
+			graph.Edges = append(graph.Edges, apistructs.AutoTestSceneStepGraphEdge{
+				From:    refID,
+				To:      step.ID,
+				Outputs: outputs[refID],
+			})
+			adjacency[refID] = append(adjacency[refID], step.ID)
+		}
+	}
+
+	ids := make([]uint64, 0, len(flat))
+	for _, step := range flat {
+		ids = append(ids, step.ID)
+	}
+	graph.Cycles = findStepCycles(ids, adjacency)
+	return graph
+}
+
+// checkSceneStepCycles 检查场景步骤间是否存在循环引用, 存在时返回包含环上步骤的错误, 避免执行时才因出参缺失失败
+func checkSceneStepCycles(steps []apistructs.AutoTestSceneStep) error {
+	graph := BuildSceneStepGraph(steps)
+	if len(graph.Cycles) == 0 {
+		return nil
+	}
+	names := make(map[uint64]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		names[node.ID] = node.Name
+	}
+	var cycles []string
+	for _, cycle := range graph.Cycles {
+		var path []string
+		for _, id := range append(cycle, cycle[0]) {
+			path = append(path, fmt.Sprintf("%s(%d)", names[id], id))
+		}
+		cycles = append(cycles, strings.Join(path, " -> "))
+	}
+	return fmt.Errorf("steps reference each other's outputs in a cycle: %s", strings.Join(cycles, "; "))
+}
+
+// findStepCycles 使用 Tarjan 算法找出强连通分量, 每个包含多个步骤的分量中取一个环
+func findStepCycles(ids []uint64, adjacency map[uint64][]uint64) [][]uint64 {
+	var (
+		index   int
+		indices = make(map[uint64]int, len(ids))
+		lowLink = make(map[uint64]int, len(ids))
+		onStack = make(map[uint64]bool, len(ids))
+		stack   []uint64
+		cycles  = [][]uint64{}
+	)
+	var strongConnect func(id uint64)
+	strongConnect = func(id uint64) {
+		indices[id] = index
+		lowLink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, next := range adjacency[id] {
+			if _, visited := indices[next]; !visited {
+				strongConnect(next)
+				if lowLink[next] < lowLink[id] {
+					lowLink[id] = lowLink[next]
+				}
+			} else if onStack[next] && indices[next] < lowLink[id] {
+				lowLink[id] = indices[next]
+			}
+		}
+		if lowLink[id] != indices[id] {
+			return
+		}
+		component := make(map[uint64]bool)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component[top] = true
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 {
+			cycles = append(cycles, cycleInComponent(component, adjacency))
+		}
+	}
+	for _, id := range ids {
+		if _, visited := indices[id]; !visited {
+			strongConnect(id)
+		}
+	}
+	return cycles
+}
+
+// cycleInComponent 从强连通分量中 ID 最小的步骤出发, 沿分量内的边找到回到起点的环
+func cycleInComponent(component map[uint64]bool, adjacency map[uint64][]uint64) []uint64 {
+	var start uint64
+	first := true
+	for id := range component {
+		if first || id < start {
+			start, first = id, false
+		}
+	}
+	visited := make(map[uint64]bool)
+	var path []uint64
+	var walk func(id uint64) bool
+	walk = func(id uint64) bool {
+		visited[id] = true
+		path = append(path, id)
+		for _, next := range adjacency[id] {
+			if next == start {
+				return true
+			}
+			if component[next] && !visited[next] && walk(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	walk(start)
+	return path
+}
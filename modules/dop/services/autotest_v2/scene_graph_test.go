@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func graphStep(id uint64, name, value, condition string, children ...apistructs.AutoTestSceneStep) apistructs.AutoTestSceneStep {
+	step := apistructs.AutoTestSceneStep{Name: name, Type: apistructs.StepTypeAPI, Value: value, Condition: condition, Children: children}
+	step.ID = id
+	return step
+}
+
+func TestBuildSceneStepGraph(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		graphStep(1, "login", `{"apiSpec":{"url":"/login"}}`, ""),
+		graphStep(2, "list", `{"apiSpec":{"headers":[{"value":"${{ outputs.1.token }}"}],"url":"/list?u=${{ outputs.1.userID }}&t=${{ outputs.1.token }}"}}`, "",
+			graphStep(3, "detail", `{"apiSpec":{"url":"/detail/${{ outputs.1.userID }}"}}`, `${{ outputs.9.ok }} == true`)),
+		graphStep(4, "wait", `{"poll":{"condition":"${{ outputs.4.status }} == 'done'"}}`, `${{ outputs.2.total }} > 0`),
+	}
+	graph := BuildSceneStepGraph(steps)
+	assert.Len(t, graph.Nodes, 4)
+	assert.Equal(t, 1, graph.Nodes[2].GroupIndex)
+	assert.Equal(t, []uint64{9}, graph.Nodes[2].Unresolved)
+	assert.Equal(t, []apistructs.AutoTestSceneStepGraphEdge{
+		{From: 1, To: 2, Outputs: []string{"token", "userID"}},
+		{From: 1, To: 3, Outputs: []string{"userID"}},
+		{From: 2, To: 4, Outputs: []string{"total"}},
+	}, graph.Edges)
+	assert.Empty(t, graph.Cycles)
+	assert.NoError(t, checkSceneStepCycles(steps))
+}
+
+func TestCheckSceneStepCycles(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		graphStep(1, "a", `${{ outputs.3.x }}`, ""),
+		graphStep(2, "b", `${{ outputs.1.x }}`, ""),
+		graphStep(3, "c", "", `${{ outputs.2.x }} == 1`),
+		graphStep(4, "d", `${{ outputs.5.x }}`, ""),
+		graphStep(5, "e", `${{ outputs.4.x }}`, ""),
+	}
+	graph := BuildSceneStepGraph(steps)
+	assert.ElementsMatch(t, [][]uint64{{1, 2, 3}, {4, 5}}, graph.Cycles)
+
+	err := checkSceneStepCycles(steps)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "a(1) -> b(2) -> c(3) -> a(1)")
+		assert.Contains(t, err.Error(), "d(4) -> e(5) -> d(4)")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	defaultJSScriptTimeoutMs = 1000
+	maxJSScriptTimeoutMs     = 10 * 1000
+	maxJSScriptSize          = 32 * 1024
+	maxJSScriptInputs        = 20
+	// jsScriptStartupSec node 进程启动及写出参的预留时间
+	jsScriptStartupSec = 5
+
+	jsScriptRunnerPath = "/tmp/jsscript-runner.js"
+)
+
+var jsScriptInputNameRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// jsScriptRunner 在 node 的 vm 上下文中执行脚本, 上下文中只有 inputs, outputs 和 console.
+// vm 不是安全边界, 脚本可能逃逸到 node 进程, 隔离由 jsScriptStepToAction 中的运行方式保证.
+const jsScriptRunner = `'use strict';
+const vm = require('vm');
+const fs = require('fs');
+const env = process.env;
+
+const inputs = {};
+(env.ACTION_INPUT_NAMES || '').split(',').filter(Boolean).forEach(function (name, i) {
+  inputs[name] = env['ACTION_INPUT_' + i] || '';
+});
+const script = Buffer.from(env.ACTION_SCRIPT || '', 'base64').toString('utf8');
+const timeout = parseInt(env.ACTION_TIMEOUT_MS, 10);
+
+const context = vm.createContext(Object.create(null), {
+  codeGeneration: { strings: false, wasm: false },
+  microtaskMode: 'afterEvaluate',
+});
+const run = function (code, filename) {
+  return vm.runInContext(code, context, { filename: filename, timeout: timeout });
+};
+
+run('var inputs = JSON.parse(' + JSON.stringify(JSON.stringify(inputs)) + ');' +
+  'var outputs = {};' +
+  'var __logs = [];' +
+  'var console = { log: function () { __logs.push(Array.prototype.map.call(arguments, String).join(" ")); } };' +
+  'console.info = console.warn = console.error = console.log;', 'init.js');
+
+let failed = false;
+try {
+  run(script, 'script.js');
+} catch (e) {
+  failed = true;
+  console.error(String(e && e.stack || e));
+}
+const logs = JSON.parse(run('JSON.stringify(Array.isArray(__logs) ? __logs : [])', 'logs.js'));
+logs.forEach(function (line) { console.log(line); });
+if (failed) {
+  process.exit(1);
+}
+
+const outputs = JSON.parse(run('JSON.stringify(outputs === undefined ? {} : outputs)', 'outputs.js') || 'null');
+if (outputs === null || typeof outputs !== 'object' || Array.isArray(outputs)) {
+  console.error('outputs must be an object');
+  process.exit(1);
+}
+const metadata = Object.keys(outputs).map(function (name) {
+  const value = outputs[name];
+  return { name: name, value: typeof value === 'string' ? value : JSON.stringify(value) };
+});
+if (env.METAFILE) {
+  fs.writeFileSync(env.METAFILE, JSON.stringify({ metadata: metadata }));
+}
+`
+
+// validateJSScriptStep 校验 JS 脚本步骤
+func validateJSScriptStep(stepValue string) error {
+	if stepValue == "" {
+		return nil
+	}
+	var value apistructs.AutoTestRunJSScript
+	if err := json.Unmarshal([]byte(stepValue), &value); err != nil {
+		return err
+	}
+	return checkJSScript(value)
+}
+
+func checkJSScript(value apistructs.AutoTestRunJSScript) error {
+	if strings.TrimSpace(value.Script) == "" {
+		return fmt.Errorf("missing script")
+	}
+	if len(value.Script) > maxJSScriptSize {
+		return fmt.Errorf("script is too large, max size %d bytes", maxJSScriptSize)
+	}
+	if value.TimeoutMs < 0 || value.TimeoutMs > maxJSScriptTimeoutMs {
+		return fmt.Errorf("timeout must be between 1 and %d ms", maxJSScriptTimeoutMs)
+	}
+	if len(value.Inputs) > maxJSScriptInputs {
+		return fmt.Errorf("too many inputs, max %d", maxJSScriptInputs)
+	}
+	for name := range value.Inputs {
+		if !jsScriptInputNameRe.MatchString(name) {
+			return fmt.Errorf("invalid input name %q", name)
+		}
+	}
+	return nil
+}
+
+// jsScriptStepToAction 将 JS 脚本步骤转换为 custom-script 任务.
+// 脚本以 base64 传入, 避免脚本内容被当作表达式渲染; 入参按序号传入, 由流水线渲染其中对其他步骤出参的引用
+func jsScriptStepToAction(value apistructs.AutoTestRunJSScript, action *pipelineyml.Action) error {
+	if err := checkJSScript(value); err != nil {
+		return err
+	}
+	timeoutMs := value.TimeoutMs
+	if timeoutMs == 0 {
+		timeoutMs = defaultJSScriptTimeoutMs
+	}
+
+	names := make([]string, 0, len(value.Inputs))
+	for name := range value.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	action.Type = "custom-script"
+	action.Version = "1.0"
+	action.Image = conf.AutotestJSScriptImage()
+	action.Params = map[string]interface{}{
+		"script":      base64.StdEncoding.EncodeToString([]byte(value.Script)),
+		"input_names": strings.Join(names, ","),
+		"timeout_ms":  strconv.Itoa(timeoutMs),
+	}
+	for i, name := range names {
+		action.Params["input_"+strconv.Itoa(i)] = value.Inputs[name]
+	}
+	processTimeoutSec := (timeoutMs+999)/1000 + jsScriptStartupSec
+	action.Commands = []string{
+		"echo " + base64.StdEncoding.EncodeToString([]byte(jsScriptRunner)) + " | base64 -d > " + jsScriptRunnerPath,
+		jsScriptIsolatedCommand(len(names)) + " timeout " + strconv.Itoa(processTimeoutSec) + " node --max-old-space-size=64 " + jsScriptRunnerPath,
+	}
+	action.Resources = pipelineyml.Resources{CPU: 0.1, MaxCPU: 0.5, Mem: 128}
+	return nil
+}
+
+// jsScriptIsolatedCommand 返回运行脚本进程的命令前缀: env -i 清空环境变量, 只传入脚本及入参, 避免读取任务的凭证;
+// unshare -rn 在只有回环网卡的网络命名空间中运行, 脚本无法访问网络. 运行环境不支持创建命名空间时步骤失败, 不会降级为不隔离执行
+func jsScriptIsolatedCommand(inputCount int) string {
+	envs := []string{"PATH", "METAFILE", "ACTION_SCRIPT", "ACTION_INPUT_NAMES", "ACTION_TIMEOUT_MS"}
+	for i := 0; i < inputCount; i++ {
+		envs = append(envs, "ACTION_INPUT_"+strconv.Itoa(i))
+	}
+	for i, env := range envs {
+		envs[i] = env + `="$` + env + `"`
+	}
+	return "env -i " + strings.Join(envs, " ") + " unshare -rn"
+}
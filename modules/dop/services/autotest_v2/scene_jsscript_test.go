@@ -0,0 +1,64 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestValidateJSScriptStep(t *testing.T) {
+	assert.NoError(t, validateJSScriptStep(""))
+	assert.NoError(t, validateJSScriptStep(`{"script":"outputs.a = inputs.body","inputs":{"body":"${{ outputs.1.body }}"}}`))
+	assert.Error(t, validateJSScriptStep(`{"script":" "}`))
+	assert.Error(t, validateJSScriptStep(`{"script":"1","timeoutMs":60000}`))
+	assert.Error(t, validateJSScriptStep(`{"script":"1","inputs":{"a-b":"1"}}`))
+	assert.Error(t, validateJSScriptStep(`{"script":"`+strings.Repeat("1", maxJSScriptSize+1)+`"}`))
+}
+
+func TestStepToActionJSScript(t *testing.T) {
+	script := `outputs.token = "${{ outputs.1.token }}"`
+	step := apistructs.AutoTestSceneStep{
+		Type:  apistructs.StepTypeJSScript,
+		Value: `{"script":"outputs.token = \"${{ outputs.1.token }}\"","inputs":{"name":"${{ outputs.2.name }}","body":"${{ outputs.1.body }}"},"timeoutMs":2000}`,
+	}
+	step.ID = 3
+	actions, err := StepToAction(step)
+	assert.NoError(t, err)
+	action := actions["custom-script"]
+	if !assert.NotNil(t, action) {
+		return
+	}
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(script)), action.Params["script"])
+	assert.Equal(t, "body,name", action.Params["input_names"])
+	assert.Equal(t, "${{ outputs.1.body }}", action.Params["input_0"])
+	assert.Equal(t, "${{ outputs.2.name }}", action.Params["input_1"])
+	assert.Equal(t, "2000", action.Params["timeout_ms"])
+	assert.Len(t, action.Commands, 2)
+	assert.Contains(t, action.Commands[1], "timeout 7 node")
+	// 脚本进程不继承任务环境变量, 并在独立的网络命名空间中运行
+	assert.True(t, strings.HasPrefix(action.Commands[1], `env -i PATH="$PATH" METAFILE="$METAFILE" ACTION_SCRIPT="$ACTION_SCRIPT" `+
+		`ACTION_INPUT_NAMES="$ACTION_INPUT_NAMES" ACTION_TIMEOUT_MS="$ACTION_TIMEOUT_MS" `+
+		`ACTION_INPUT_0="$ACTION_INPUT_0" ACTION_INPUT_1="$ACTION_INPUT_1" unshare -rn timeout 7 node`))
+
+	step.Value = `{"script":""}`
+	_, err = StepToAction(step)
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/services/autotest"
+	"github.com/erda-project/erda/modules/dop/utils"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	loopVarPrefix            = "loop"
+	loopVarItem              = "item"
+	loopVarIndex             = "index"
+	defaultMaxLoopIterations = 100
+)
+
+// globalConfigResolver 根据参数名查询自动化测试全局配置的值
+type globalConfigResolver func(name string) (string, error)
+
+// newGlobalConfigResolver 返回从配置单命名空间中查询全局配置的 resolver, 配置在第一次查询时加载
+func (svc *Service) newGlobalConfigResolver(configNs string) globalConfigResolver {
+	var global map[string]apistructs.AutoTestConfigItem
+	return func(name string) (string, error) {
+		if configNs == "" {
+			return "", fmt.Errorf("no config namespace specified, failed to resolve global config %s", name)
+		}
+		if global == nil {
+			configs, err := svc.cms.GetCmsNsConfigs(utils.WithInternalClientContext(context.Background()), &cmspb.CmsNsConfigsGetRequest{
+				Ns:             configNs,
+				PipelineSource: apistructs.PipelineSourceAutoTest.String(),
+				GlobalDecrypt:  true,
+			})
+			if err != nil {
+				return "", err
+			}
+			global = make(map[string]apistructs.AutoTestConfigItem)
+			for _, cfg := range configs.Data {
+				if cfg.Key != autotest.CmsCfgKeyAPIGlobalConfig {
+					continue
+				}
+				var apiConfig apistructs.AutoTestAPIConfig
+				if err := json.Unmarshal([]byte(cfg.Value), &apiConfig); err != nil {
+					return "", fmt.Errorf("failed to unmarshal apiConfig, err: %v", err)
+				}
+				for key, item := range apiConfig.Global {
+					global[key] = item
+				}
+			}
+		}
+		item, ok := global[name]
+		if !ok {
+			return "", fmt.Errorf("global config %s not found", name)
+		}
+		return item.Value, nil
+	}
+}
+
+// loopStepToStages 将循环步骤展开为串行的 stage, 每次迭代依次执行子步骤; stage 中没有循环步骤时返回 false
+func loopStepToStages(stage []apistructs.AutoTestSceneStep, resolveGlobal globalConfigResolver, resolveDataFile dataFileResolver) ([]*pipelineyml.Stage, bool, error) {
+	var loopStep *apistructs.AutoTestSceneStep
+	for i := range stage {
+		if stage[i].Type == apistructs.StepTypeLoop {
+			loopStep = &stage[i]
+			break
+		}
+	}
+	if loopStep == nil {
+		return nil, false, nil
+	}
+	if len(stage) > 1 {
+		return nil, true, fmt.Errorf("loop step %s can not run in parallel with other steps", loopStep.Name)
+	}
+	if loopStep.Value == "" {
+		return nil, true, nil
+	}
+
+	var value apistructs.AutoTestRunLoop
+	if err := json.Unmarshal([]byte(loopStep.Value), &value); err != nil {
+		return nil, true, err
+	}
+	items, err := loopItems(value, resolveGlobal, resolveDataFile)
+	if err != nil {
+		return nil, true, fmt.Errorf("loop step %s: %v", loopStep.Name, err)
+	}
+	if max := maxLoopIterations(); len(items) > max {
+		return nil, true, fmt.Errorf("loop step %s has %d items, exceeds the limit of %d", loopStep.Name, len(items), max)
+	}
+
+	var stages []*pipelineyml.Stage
+	for index, item := range items {
+		for childIndex, child := range value.Steps {
+			if child.Value == "" {
+				continue
+			}
+			if child.Type == apistructs.StepTypeLoop {
+				return nil, true, fmt.Errorf("loop step %s can not contain loop steps", loopStep.Name)
+			}
+			child.Children = nil
+			child.SceneID = loopStep.SceneID
+			child.SpaceID = loopStep.SpaceID
+			child.DefaultTimeoutMs = loopStep.DefaultTimeoutMs
+			child.Name = fmt.Sprintf("%s #%d %s", loopStep.Name, index+1, child.Name)
+			if child.Condition == "" {
+				child.Condition = loopStep.Condition
+			}
+			if child.Value, err = renderLoopVars(child.Value, index, item); err != nil {
+				return nil, true, fmt.Errorf("loop step %s: %v", loopStep.Name, err)
+			}
+			action, err := StepToAction(child)
+			if err != nil {
+				return nil, true, err
+			}
+			for _, a := range action {
+				a.Alias = pipelineyml.ActionAlias(fmt.Sprintf("%d_%d_%d", loopStep.ID, index, childIndex))
+				a.Labels[apistructs.AutotestLoopStepID] = strconv.FormatUint(loopStep.ID, 10)
+				a.Labels[apistructs.AutotestLoopIndex] = strconv.Itoa(index)
+			}
+			var specStage pipelineyml.Stage
+			specStage.Actions = append(specStage.Actions, action)
+			stages = append(stages, &specStage)
+		}
+	}
+	return stages, true, nil
+}
+
+// loopItems 返回循环的集合, 引用全局配置时配置值需为 JSON 数组, 引用数据文件时每行为一项
+func loopItems(value apistructs.AutoTestRunLoop, resolveGlobal globalConfigResolver, resolveDataFile dataFileResolver) ([]interface{}, error) {
+	if value.DataFileID > 0 {
+		if resolveDataFile == nil {
+			return nil, fmt.Errorf("failed to resolve data file %d", value.DataFileID)
+		}
+		file, err := resolveDataFile(value.DataFileID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkLoopDataFileColumns(value.Steps, file.columns); err != nil {
+			return nil, err
+		}
+		return file.items(), nil
+	}
+	if value.ItemsRef == "" {
+		return value.Items, nil
+	}
+	if resolveGlobal == nil {
+		return nil, fmt.Errorf("failed to resolve global config %s", value.ItemsRef)
+	}
+	raw, err := resolveGlobal(value.ItemsRef)
+	if err != nil {
+		return nil, err
+	}
+	var items []interface{}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, fmt.Errorf("global config %s is not a json array", value.ItemsRef)
+	}
+	return items, nil
+}
+
+// renderLoopVars 替换步骤中的循环变量, 步骤的值为 json, 替换的内容按 json 字符串转义
+func renderLoopVars(value string, index int, item interface{}) (string, error) {
+	var renderErr error
+	rendered := strutil.ReplaceAllStringSubmatchFunc(pexpr.PhRe, value, func(subs []string) string {
+		ss := strings.Split(strings.Trim(subs[1], " "), ".")
+		if len(ss) < 2 || ss[0] != loopVarPrefix {
+			return subs[0]
+		}
+		var v interface{}
+		switch {
+		case ss[1] == loopVarIndex && len(ss) == 2:
+			v = index
+		case ss[1] == loopVarItem:
+			v = item
+			for _, field := range ss[2:] {
+				m, ok := v.(map[string]interface{})
+				if !ok {
+					renderErr = fmt.Errorf("invalid loop variable %s", subs[0])
+					return subs[0]
+				}
+				v = m[field]
+			}
+		default:
+			renderErr = fmt.Errorf("invalid loop variable %s", subs[0])
+			return subs[0]
+		}
+		s, ok := v.(string)
+		if !ok {
+			b, _ := json.Marshal(v)
+			s = string(b)
+		}
+		escaped, _ := json.Marshal(s)
+		return strings.Trim(string(escaped), `"`)
+	})
+	return rendered, renderErr
+}
+
+func maxLoopIterations() int {
+	if max := conf.AutotestSceneMaxLoopIterations(); max > 0 {
+		return max
+	}
+	return defaultMaxLoopIterations
+}
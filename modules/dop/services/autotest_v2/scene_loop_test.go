@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestRenderLoopVars(t *testing.T) {
+	item := map[string]interface{}{"name": `a"b`, "age": 18.0}
+
+	rendered, err := renderLoopVars(`{"url":"/users/${{ loop.index }}?name=${{ loop.item.name }}&age=${{ loop.item.age }}"}`, 2, item)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"url":"/users/2?name=a\"b&age=18"}`, rendered)
+
+	rendered, err = renderLoopVars(`{"body":"${{ loop.item }}","token":"${{ outputs.1.token }}"}`, 0, map[string]interface{}{"id": 1.0})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"body":"{\"id\":1}","token":"${{ outputs.1.token }}"}`, rendered)
+
+	_, err = renderLoopVars(`{"url":"${{ loop.item.name }}"}`, 0, "plain")
+	assert.Error(t, err)
+}
+
+func TestLoopItems(t *testing.T) {
+	items, err := loopItems(apistructs.AutoTestRunLoop{Items: []interface{}{"a", "b"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, items)
+
+	resolver := func(name string) (string, error) {
+		switch name {
+		case "rows":
+			return `[{"id":1},{"id":2}]`, nil
+		case "text":
+			return "abc", nil
+		}
+		return "", errors.New("not found")
+	}
+	items, err = loopItems(apistructs.AutoTestRunLoop{ItemsRef: "rows"}, resolver, nil)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	_, err = loopItems(apistructs.AutoTestRunLoop{ItemsRef: "text"}, resolver, nil)
+	assert.Error(t, err)
+	_, err = loopItems(apistructs.AutoTestRunLoop{ItemsRef: "missing"}, resolver, nil)
+	assert.Error(t, err)
+}
+
+func TestLoopStepToStages(t *testing.T) {
+	_, ok, err := loopStepToStages([]apistructs.AutoTestSceneStep{newStep(1, "{}")}, nil, nil)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	loopValue, _ := json.Marshal(apistructs.AutoTestRunLoop{
+		Items: []interface{}{"a", "b"},
+		Steps: []apistructs.AutoTestSceneStep{
+			{Type: apistructs.StepTypeWait, Name: "wait", Value: `{"waitTime":1}`},
+			{Type: apistructs.StepTypeCustomScript, Name: "echo", Value: `{"commands":["echo ${{ loop.item }}"]}`},
+		},
+	})
+	loopStep := newStep(5, string(loopValue))
+	loopStep.Type = apistructs.StepTypeLoop
+	loopStep.Name = "rows"
+
+	stages, ok, err := loopStepToStages([]apistructs.AutoTestSceneStep{loopStep}, nil, nil)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Len(t, stages, 4)
+	for _, action := range stages[3].Actions[0] {
+		assert.Equal(t, "5_1_1", string(action.Alias))
+		assert.Equal(t, []string{"echo b"}, action.Commands)
+		assert.Equal(t, "1", action.Labels[apistructs.AutotestLoopIndex])
+	}
+
+	_, _, err = loopStepToStages([]apistructs.AutoTestSceneStep{loopStep, newStep(6, "{}")}, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+const (
+	apiSpecKeyProxy = "proxy"
+
+	proxyKeyPassword           = "password"
+	proxyKeyPasswordCiphertext = "passwordCiphertext"
+	proxyKeyKMSKeyID           = "kmsKeyID"
+)
+
+// validateStepProxy 校验 api 步骤中的代理配置
+func validateStepProxy(stepValue string) error {
+	if stepValue == "" {
+		return nil
+	}
+	var value apistructs.AutoTestRunStep
+	if err := json.Unmarshal([]byte(stepValue), &value); err != nil {
+		return err
+	}
+	raw, ok := value.ApiSpec[apiSpecKeyProxy]
+	if !ok || raw == nil {
+		return nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var proxy apistructs.APIProxyConfig
+	if err := json.Unmarshal(b, &proxy); err != nil {
+		return err
+	}
+	return proxy.Validate()
+}
+
+// parseStepProxy 解析步骤值, 返回整个步骤值及其中的代理配置, 未配置代理时 proxy 为 nil.
+// 数字按原样保留, 避免重新序列化时丢失精度
+func parseStepProxy(stepValue string) (value map[string]interface{}, proxy map[string]interface{}, err error) {
+	if stepValue == "" {
+		return nil, nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewBufferString(stepValue))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, nil, err
+	}
+	apiSpec, _ := value["apiSpec"].(map[string]interface{})
+	proxy, _ = apiSpec[apiSpecKeyProxy].(map[string]interface{})
+	return value, proxy, nil
+}
+
+// sealStepProxyPassword 使用 kms 加密 api 步骤中的代理密码, 步骤值中只保存密文.
+// 密码为掩码时沿用原步骤中的密文, 密码为空时清除密文
+func (svc *Service) sealStepProxyPassword(stepValue, originValue string) (string, error) {
+	value, proxy, err := parseStepProxy(stepValue)
+	if err != nil || proxy == nil {
+		return stepValue, err
+	}
+	_, originProxy, _ := parseStepProxy(originValue)
+	originCiphertext, _ := originProxy[proxyKeyPasswordCiphertext].(string)
+	originKeyID, _ := originProxy[proxyKeyKMSKeyID].(string)
+
+	password, _ := proxy[proxyKeyPassword].(string)
+	delete(proxy, proxyKeyPassword)
+	delete(proxy, proxyKeyPasswordCiphertext)
+	delete(proxy, proxyKeyKMSKeyID)
+	switch password {
+	case "":
+	case apistructs.AutoTestConfigSecretMask:
+		if originCiphertext == "" {
+			return "", fmt.Errorf("proxy password is not set")
+		}
+		proxy[proxyKeyPasswordCiphertext] = originCiphertext
+		proxy[proxyKeyKMSKeyID] = originKeyID
+	default:
+		keyID := originKeyID
+		if keyID == "" {
+			key, err := svc.bdl.KMSCreateKey(apistructs.KMSCreateKeyRequest{
+				CreateKeyRequest: kmstypes.CreateKeyRequest{
+					PluginKind: kmstypes.PluginKind_DICE_KMS,
+				},
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to create kms key, err: %v", err)
+			}
+			keyID = key.KeyMetadata.KeyID
+		}
+		encrypted, err := svc.bdl.KMSEncrypt(apistructs.KMSEncryptRequest{
+			EncryptRequest: kmstypes.EncryptRequest{
+				KeyID:           keyID,
+				PlaintextBase64: base64.StdEncoding.EncodeToString([]byte(password)),
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt proxy password, err: %v", err)
+		}
+		proxy[proxyKeyPasswordCiphertext] = encrypted.CiphertextBase64
+		proxy[proxyKeyKMSKeyID] = keyID
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// maskStepProxyPassword 返回代理密码被掩码替换后的步骤值, 查询结果中不返回密文
+func maskStepProxyPassword(stepValue string) string {
+	value, proxy, err := parseStepProxy(stepValue)
+	if err != nil || proxy == nil {
+		return stepValue
+	}
+	if ciphertext, _ := proxy[proxyKeyPasswordCiphertext].(string); ciphertext == "" {
+		return stepValue
+	}
+	proxy[proxyKeyPassword] = apistructs.AutoTestConfigSecretMask
+	delete(proxy, proxyKeyPasswordCiphertext)
+	delete(proxy, proxyKeyKMSKeyID)
+	b, err := json.Marshal(value)
+	if err != nil {
+		return stepValue
+	}
+	return string(b)
+}
+
+// MaskAutoTestSceneStepSecrets 对步骤及其并行步骤中的敏感配置进行掩码, 用于接口返回
+func MaskAutoTestSceneStepSecrets(steps []apistructs.AutoTestSceneStep) {
+	for i := range steps {
+		steps[i].Value = maskStepProxyPassword(steps[i].Value)
+		MaskAutoTestSceneStepSecrets(steps[i].Children)
+	}
+}
+
+// decryptStepProxyPassword 解密步骤代理的密码, 仅在执行时调用
+func (svc *Service) decryptStepProxyPassword(proxy *apistructs.APIProxyConfig) error {
+	if proxy == nil || proxy.PasswordCiphertext == "" {
+		return nil
+	}
+	decrypted, err := svc.bdl.KMSDecrypt(apistructs.KMSDecryptRequest{
+		DecryptRequest: kmstypes.DecryptRequest{
+			KeyID:            proxy.KMSKeyID,
+			CiphertextBase64: proxy.PasswordCiphertext,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decrypt proxy password, err: %v", err)
+	}
+	password, err := base64.StdEncoding.DecodeString(decrypted.PlaintextBase64)
+	if err != nil {
+		return fmt.Errorf("failed to decode proxy password, err: %v", err)
+	}
+	proxy.Password = string(password)
+	proxy.PasswordCiphertext = ""
+	proxy.KMSKeyID = ""
+	return nil
+}
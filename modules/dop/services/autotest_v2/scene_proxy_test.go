@@ -0,0 +1,114 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+func TestValidateStepProxy(t *testing.T) {
+	assert.NoError(t, validateStepProxy(""))
+	assert.NoError(t, validateStepProxy(`{"apiSpec":{"url":"http://example.com"}}`))
+	assert.NoError(t, validateStepProxy(`{"apiSpec":{"url":"http://example.com","proxy":{"url":"http://proxy:3128","noProxy":[".svc.cluster.local"]}}}`))
+	assert.Error(t, validateStepProxy(`{"apiSpec":{"url":"http://example.com","proxy":{"url":"proxy:3128"}}}`))
+	assert.Error(t, validateStepProxy(`{"apiSpec":{"url":"http://example.com","proxy":{"url":"http://proxy:3128","password":"p"}}}`))
+}
+
+func TestSealStepProxyPassword(t *testing.T) {
+	bdl := bundle.New()
+	svc := New(WithBundle(bdl))
+	created := 0
+	m1 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "KMSCreateKey",
+		func(_ *bundle.Bundle, req apistructs.KMSCreateKeyRequest) (*kmstypes.CreateKeyResponse, error) {
+			created++
+			return &kmstypes.CreateKeyResponse{KeyMetadata: kmstypes.KeyMetadata{KeyID: "key"}}, nil
+		})
+	defer m1.Unpatch()
+	m2 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "KMSEncrypt",
+		func(_ *bundle.Bundle, req apistructs.KMSEncryptRequest) (*kmstypes.EncryptResponse, error) {
+			assert.Equal(t, "key", req.KeyID)
+			return &kmstypes.EncryptResponse{CiphertextBase64: "cipher-" + req.PlaintextBase64}, nil
+		})
+	defer m2.Unpatch()
+
+	// 无代理时保持原值
+	value := `{"apiSpec":{"url":"http://example.com"},"loop":{"strategy":{"maxTimes":12345678901234}}}`
+	sealed, err := svc.sealStepProxyPassword(value, "")
+	assert.NoError(t, err)
+	assert.Equal(t, value, sealed)
+
+	// 明文密码加密后只保留密文
+	sealed, err = svc.sealStepProxyPassword(`{"apiSpec":{"url":"http://example.com","proxy":{"url":"http://proxy:3128","username":"u","password":"p"}},"id":12345678901234}`, "")
+	assert.NoError(t, err)
+	cipher := "cipher-" + base64.StdEncoding.EncodeToString([]byte("p"))
+	assert.Equal(t, `{"apiSpec":{"proxy":{"kmsKeyID":"key","passwordCiphertext":"`+cipher+`","url":"http://proxy:3128","username":"u"},"url":"http://example.com"},"id":12345678901234}`, sealed)
+	assert.Equal(t, 1, created)
+
+	// 掩码沿用原密文
+	masked := maskStepProxyPassword(sealed)
+	assert.NotContains(t, masked, cipher)
+	assert.Contains(t, masked, `"password":"`+apistructs.AutoTestConfigSecretMask+`"`)
+	resealed, err := svc.sealStepProxyPassword(masked, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, sealed, resealed)
+	_, err = svc.sealStepProxyPassword(masked, "")
+	assert.Error(t, err)
+
+	// 修改密码时复用原步骤的密钥
+	_, err = svc.sealStepProxyPassword(`{"apiSpec":{"proxy":{"url":"http://proxy:3128","username":"u","password":"q"}}}`, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	// 清空密码时清除密文
+	cleared, err := svc.sealStepProxyPassword(`{"apiSpec":{"proxy":{"url":"http://proxy:3128"}}}`, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"apiSpec":{"proxy":{"url":"http://proxy:3128"}}}`, cleared)
+}
+
+func TestMaskAutoTestSceneStepSecrets(t *testing.T) {
+	value := `{"apiSpec":{"proxy":{"url":"http://proxy:3128","username":"u","passwordCiphertext":"c","kmsKeyID":"k"}}}`
+	steps := []apistructs.AutoTestSceneStep{{Value: value, Children: []apistructs.AutoTestSceneStep{{Value: value}}}}
+	MaskAutoTestSceneStepSecrets(steps)
+	expected := `{"apiSpec":{"proxy":{"password":"******","url":"http://proxy:3128","username":"u"}}}`
+	assert.Equal(t, expected, steps[0].Value)
+	assert.Equal(t, expected, steps[0].Children[0].Value)
+	assert.Equal(t, `{"apiSpec":{}}`, maskStepProxyPassword(`{"apiSpec":{}}`))
+}
+
+func TestDecryptStepProxyPassword(t *testing.T) {
+	bdl := bundle.New()
+	svc := New(WithBundle(bdl))
+	m := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "KMSDecrypt",
+		func(_ *bundle.Bundle, req apistructs.KMSDecryptRequest) (*kmstypes.DecryptResponse, error) {
+			assert.Equal(t, "k", req.KeyID)
+			assert.Equal(t, "c", req.CiphertextBase64)
+			return &kmstypes.DecryptResponse{PlaintextBase64: base64.StdEncoding.EncodeToString([]byte("p"))}, nil
+		})
+	defer m.Unpatch()
+
+	assert.NoError(t, svc.decryptStepProxyPassword(nil))
+	proxy := &apistructs.APIProxyConfig{URL: "http://proxy:3128", Username: "u", PasswordCiphertext: "c", KMSKeyID: "k"}
+	assert.NoError(t, svc.decryptStepProxyPassword(proxy))
+	assert.Equal(t, apistructs.APIProxyConfig{URL: "http://proxy:3128", Username: "u", Password: "p"}, *proxy)
+}
@@ -16,13 +16,18 @@ package autotestv2
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm"
+	"gopkg.in/Knetic/govaluate.v3"
 
 	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+	"github.com/erda-project/erda/pkg/strutil"
 
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 
@@ -68,6 +73,11 @@ func (svc *Service) CreateAutoTestSceneStep(req apistructs.AutotestSceneRequest)
 		return step.ID, err
 	}
 
+	if req.Type == apistructs.StepTypeAPI {
+		if req.Value, err = svc.sealStepProxyPassword(req.Value, ""); err != nil {
+			return 0, apierrors.ErrCreateAutoTestSceneStep.InternalError(err)
+		}
+	}
 	preID, err := svc.FindStepPosition(&req)
 	if err != nil {
 		return 0, err
@@ -110,8 +120,37 @@ func (svc *Service) UpdateAutoTestSceneStep(req apistructs.AutotestSceneRequest)
 		return 0, apierrors.ErrUpdateAutoTestSceneStep.InvalidState("所属测试空间已锁定")
 	}
 
+	if err := svc.validateStepCondition(step, req.Condition); err != nil {
+		return 0, err
+	}
+	if step.Type == apistructs.StepTypeAPI {
+		if err := validateJsonPathAsserts(req.Value); err != nil {
+			return 0, apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("无效的 JSONPath 断言: %v", err))
+		}
+		if err := validateStepProxy(req.Value); err != nil {
+			return 0, apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("无效的代理配置: %v", err))
+		}
+		if err := validateStepPolicy(req.Value); err != nil {
+			return 0, apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("无效的超时配置: %v", err))
+		}
+		if req.Value, err = svc.sealStepProxyPassword(req.Value, step.Value); err != nil {
+			return 0, apierrors.ErrUpdateAutoTestSceneStep.InternalError(err)
+		}
+	}
+	if step.Type == apistructs.StepTypeJSScript {
+		if err := validateJSScriptStep(req.Value); err != nil {
+			return 0, apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("无效的脚本: %v", err))
+		}
+	}
+	if step.Type == apistructs.StepTypeLoop {
+		if err := svc.validateLoopStep(step, req.Value); err != nil {
+			return 0, err
+		}
+	}
+
 	step.Value = req.Value
 	step.Name = req.Name
+	step.Condition = req.Condition
 	step.UpdaterID = req.UserID
 	step.APISpecID = req.APISpecID
 	if err := svc.db.UpdateAutotestSceneStep(step); err != nil {
@@ -128,6 +167,58 @@ func (svc *Service) UpdateAutoTestSceneStep(req apistructs.AutotestSceneRequest)
 	return step.ID, nil
 }
 
+// validateStepCondition 校验步骤的执行条件, 条件中只能引用同一场景中其他步骤的出参、场景入参和全局配置
+func (svc *Service) validateStepCondition(step *dao.AutoTestSceneStep, condition string) error {
+	if strings.TrimSpace(condition) == "" {
+		return nil
+	}
+	steps, err := svc.ListAutoTestSceneStep(step.SceneID)
+	if err != nil {
+		return err
+	}
+	sceneStepIDs := make(map[uint64]bool)
+	for _, v := range steps {
+		sceneStepIDs[v.ID] = true
+		for _, child := range v.Children {
+			sceneStepIDs[child.ID] = true
+		}
+	}
+	if err := checkStepCondition(condition, step.ID, sceneStepIDs); err != nil {
+		return apierrors.ErrUpdateAutoTestSceneStep.InvalidParameter(fmt.Sprintf("无效的执行条件: %v", err))
+	}
+	return nil
+}
+
+// checkStepCondition 检查条件中的引用并校验表达式语法, 引用在运行时由流水线渲染, 校验时以占位值代替
+func checkStepCondition(condition string, stepID uint64, sceneStepIDs map[uint64]bool) error {
+	var refErr error
+	replaced := strutil.ReplaceAllStringSubmatchFunc(pexpr.PhRe, condition, func(subs []string) string {
+		ss := strings.SplitN(strings.Trim(subs[1], " "), ".", 3)
+		switch ss[0] {
+		case expression.Outputs:
+			if len(ss) < 3 {
+				refErr = fmt.Errorf("invalid output reference %s", subs[0])
+				break
+			}
+			refID, err := strconv.ParseUint(ss[1], 10, 64)
+			if err != nil || refID == stepID || !sceneStepIDs[refID] {
+				refErr = fmt.Errorf("step %s referenced by %s does not exist in the scene", ss[1], subs[0])
+			}
+		case expression.Params, expression.Configs, expression.Globals, expression.Random:
+		default:
+			refErr = fmt.Errorf("unsupported reference %s", subs[0])
+		}
+		return "1"
+	})
+	if refErr != nil {
+		return refErr
+	}
+	if _, err := govaluate.NewEvaluableExpression(replaced); err != nil {
+		return err
+	}
+	return nil
+}
+
 // MoveAutoTestSceneStep 更新场景步骤顺序
 func (svc *Service) MoveAutoTestSceneStep(req apistructs.AutotestSceneRequest) error {
 	// 如果是整组移动逻辑不一样
@@ -0,0 +1,37 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStepCondition(t *testing.T) {
+	sceneStepIDs := map[uint64]bool{1: true, 2: true, 3: true}
+
+	assert.NoError(t, checkStepCondition("${{ outputs.1.status }} == 200", 3, sceneStepIDs))
+	assert.NoError(t, checkStepCondition("${{ outputs.1.status }} != 200 && ${{ params.env }} == 'test'", 3, sceneStepIDs))
+	assert.NoError(t, checkStepCondition("${{ outputs.2.token }} != ''", 3, sceneStepIDs))
+
+	// 语法错误
+	assert.Error(t, checkStepCondition("${{ outputs.1.status }} == ", 3, sceneStepIDs))
+	// 引用不存在的步骤或自身
+	assert.Error(t, checkStepCondition("${{ outputs.9.status }} == 200", 3, sceneStepIDs))
+	assert.Error(t, checkStepCondition("${{ outputs.3.status }} == 200", 3, sceneStepIDs))
+	// 不支持的引用
+	assert.Error(t, checkStepCondition("${{ dirs.1 }} == 200", 3, sceneStepIDs))
+}
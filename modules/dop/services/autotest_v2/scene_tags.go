@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	sceneTagsMaxCount  = 10
+	sceneTagsMaxLength = 20
+)
+
+var sceneTagRe = regexp.MustCompile("^[a-zA-Z\u4e00-\u9fa50-9_-]+$")
+
+// normalizeSceneTags 去除标签首尾空白、空标签及重复标签, 并校验标签数量和格式
+func normalizeSceneTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || strutil.Exist(result, tag) {
+			continue
+		}
+		if err := strutil.Validate(tag, strutil.MaxRuneCountValidator(sceneTagsMaxLength)); err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %v", tag, err)
+		}
+		if !sceneTagRe.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: only chinese, letters, digits, '-' and '_' are allowed", tag)
+		}
+		result = append(result, tag)
+	}
+	if len(result) > sceneTagsMaxCount {
+		return nil, fmt.Errorf("too many tags, at most %d", sceneTagsMaxCount)
+	}
+	return result, nil
+}
+
+// matchSceneTags 场景带有 filter 中任一标签时返回 true, filter 为空时不筛选
+func matchSceneTags(sceneTags, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, tag := range sceneTags {
+		if strutil.Exist(filter, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterScenesByTags 按标签筛选场景, 保持原有顺序
+func filterScenesByTags(scenes []apistructs.AutoTestScene, tags []string) []apistructs.AutoTestScene {
+	if len(tags) == 0 {
+		return scenes
+	}
+	var result []apistructs.AutoTestScene
+	for _, scene := range scenes {
+		if matchSceneTags(scene.Tags, tags) {
+			result = append(result, scene)
+		}
+	}
+	return result
+}
+
+// sceneSetTagsFromLabels 解析 snippet 配置中执行场景集时指定的标签
+func sceneSetTagsFromLabels(labels map[string]string) []string {
+	if labels[apistructs.LabelSceneSetTags] == "" {
+		return nil
+	}
+	return strings.Split(labels[apistructs.LabelSceneSetTags], ",")
+}
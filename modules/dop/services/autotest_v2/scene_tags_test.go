@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestNormalizeSceneTags(t *testing.T) {
+	tags, err := normalizeSceneTags([]string{" smoke ", "", "回归", "smoke"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"smoke", "回归"}, tags)
+
+	_, err = normalizeSceneTags([]string{"a,b"})
+	assert.Error(t, err)
+	_, err = normalizeSceneTags([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"})
+	assert.Error(t, err)
+
+	_, err = normalizeScheduleSceneTags(apistructs.AutoTestScheduleTargetScene, []string{"smoke"})
+	assert.Error(t, err)
+	tags, err = normalizeScheduleSceneTags(apistructs.AutoTestScheduleTargetSceneSet, []string{"smoke"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"smoke"}, tags)
+}
+
+func TestGetListWithTags(t *testing.T) {
+	newScene := func(id, preID uint64, tags ...string) dao.AutoTestScene {
+		scene := dao.AutoTestScene{PreID: preID, Tags: tags}
+		scene.ID = id
+		return scene
+	}
+	list := []dao.AutoTestScene{
+		newScene(3, 2, "smoke"),
+		newScene(1, 0, "smoke", "slow"),
+		newScene(2, 1),
+		newScene(4, 3, "regression"),
+	}
+
+	total, scenes := getList(list, 1, 10, nil)
+	assert.Equal(t, uint64(4), total)
+	assert.Len(t, scenes, 4)
+
+	total, scenes = getList(list, 1, 10, []string{"smoke", "regression"})
+	assert.Equal(t, uint64(3), total)
+	var ids []uint64
+	for _, scene := range scenes {
+		ids = append(ids, scene.ID)
+	}
+	assert.Equal(t, []uint64{1, 3, 4}, ids)
+
+	total, scenes = getList(list, 2, 1, []string{"smoke"})
+	assert.Equal(t, uint64(2), total)
+	assert.Equal(t, uint64(3), scenes[0].ID)
+	assert.Equal(t, []string{"smoke"}, scenes[0].Tags)
+
+	assert.Equal(t, []string{"smoke", "slow"}, sceneSetTagsFromLabels(map[string]string{apistructs.LabelSceneSetTags: "smoke,slow"}))
+	assert.Nil(t, sceneSetTagsFromLabels(map[string]string{}))
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"regexp"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+var sceneTemplateNameRe = regexp.MustCompile("^[a-zA-Z\u4e00-\u9fa50-9_-]+$")
+
+// CreateAutoTestSceneTemplate 将场景的入参、出参和步骤保存为项目下的模板
+func (svc *Service) CreateAutoTestSceneTemplate(req apistructs.AutoTestSceneTemplateCreateRequest) (*apistructs.AutoTestSceneTemplate, error) {
+	if err := checkSceneTemplateName(req.Name, req.Description); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneTemplate.InvalidParameter(err)
+	}
+	scene, projectID, err := svc.getSceneTemplateSource(req.SceneID)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneTemplate.InvalidParameter(err)
+	}
+	exist, err := svc.db.GetAutoTestSceneTemplateByName(projectID, req.Name)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneTemplate.InternalError(err)
+	}
+	if exist != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneTemplate.AlreadyExists()
+	}
+	content, err := svc.snapshotSceneTemplateContent(scene.ID)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneTemplate.InternalError(err)
+	}
+	template := dao.AutoTestSceneTemplate{
+		ProjectID:     projectID,
+		Name:          req.Name,
+		Description:   req.Description,
+		SourceSceneID: scene.ID,
+		Version:       1,
+		Content:       dao.AutoTestSceneTemplateContent(*content),
+		CreatorID:     req.UserID,
+		UpdaterID:     req.UserID,
+	}
+	if err := svc.db.CreateAutoTestSceneTemplate(&template); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneTemplate.InternalError(err)
+	}
+	result := template.Convert()
+	return &result, nil
+}
+
+// UpdateAutoTestSceneTemplate 更新模板名称和描述, 指定场景时重新保存模板内容并升级版本, 已实例化的场景不受影响
+func (svc *Service) UpdateAutoTestSceneTemplate(req apistructs.AutoTestSceneTemplateUpdateRequest) (*apistructs.AutoTestSceneTemplate, error) {
+	template, err := svc.GetAutoTestSceneTemplate(req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil && *req.Name != template.Name {
+		exist, err := svc.db.GetAutoTestSceneTemplateByName(template.ProjectID, *req.Name)
+		if err != nil {
+			return nil, apierrors.ErrUpdateAutoTestSceneTemplate.InternalError(err)
+		}
+		if exist != nil {
+			return nil, apierrors.ErrUpdateAutoTestSceneTemplate.AlreadyExists()
+		}
+		template.Name = *req.Name
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if err := checkSceneTemplateName(template.Name, template.Description); err != nil {
+		return nil, apierrors.ErrUpdateAutoTestSceneTemplate.InvalidParameter(err)
+	}
+	if req.SceneID > 0 {
+		scene, projectID, err := svc.getSceneTemplateSource(req.SceneID)
+		if err != nil {
+			return nil, apierrors.ErrUpdateAutoTestSceneTemplate.InvalidParameter(err)
+		}
+		if projectID != template.ProjectID {
+			return nil, apierrors.ErrUpdateAutoTestSceneTemplate.InvalidParameter("scene does not belong to the project of the template")
+		}
+		content, err := svc.snapshotSceneTemplateContent(scene.ID)
+		if err != nil {
+			return nil, apierrors.ErrUpdateAutoTestSceneTemplate.InternalError(err)
+		}
+		template.Content = dao.AutoTestSceneTemplateContent(*content)
+		template.SourceSceneID = scene.ID
+		template.Version++
+	}
+	template.UpdaterID = req.UserID
+	if err := svc.db.UpdateAutoTestSceneTemplate(template); err != nil {
+		return nil, apierrors.ErrUpdateAutoTestSceneTemplate.InternalError(err)
+	}
+	result := template.Convert()
+	return &result, nil
+}
+
+// DeleteAutoTestSceneTemplate 删除模板, 已实例化的场景保留并解除与模板的关联
+func (svc *Service) DeleteAutoTestSceneTemplate(id uint64) error {
+	if err := svc.db.DeleteAutoTestSceneTemplate(id); err != nil {
+		return apierrors.ErrDeleteAutoTestSceneTemplate.InternalError(err)
+	}
+	return nil
+}
+
+// GetAutoTestSceneTemplate 获取模板
+func (svc *Service) GetAutoTestSceneTemplate(id uint64) (*dao.AutoTestSceneTemplate, error) {
+	template, err := svc.db.GetAutoTestSceneTemplate(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneTemplate.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSceneTemplate.InternalError(err)
+	}
+	return template, nil
+}
+
+// GetAutoTestSceneTemplateDetail 获取模板详情, 包含模板内容
+func (svc *Service) GetAutoTestSceneTemplateDetail(id uint64) (*apistructs.AutoTestSceneTemplate, error) {
+	template, err := svc.GetAutoTestSceneTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	result := template.Convert()
+	content := apistructs.AutoTestSceneTemplateContent(template.Content)
+	result.Content = &content
+	return &result, nil
+}
+
+// ListAutoTestSceneTemplates 查询项目下的模板列表
+func (svc *Service) ListAutoTestSceneTemplates(req apistructs.AutoTestSceneTemplateListRequest) ([]apistructs.AutoTestSceneTemplate, error) {
+	templates, err := svc.db.ListAutoTestSceneTemplates(req)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSceneTemplate.InternalError(err)
+	}
+	results := make([]apistructs.AutoTestSceneTemplate, 0, len(templates))
+	for _, template := range templates {
+		results = append(results, template.Convert())
+	}
+	return results, nil
+}
+
+// InstantiateAutoTestSceneTemplate 在场景集末尾创建模板的实例场景, 参数值写入场景入参
+func (svc *Service) InstantiateAutoTestSceneTemplate(req apistructs.AutoTestSceneTemplateInstantiateRequest) (uint64, error) {
+	template, err := svc.GetAutoTestSceneTemplate(req.TemplateID)
+	if err != nil {
+		return 0, err
+	}
+	sceneSet, err := svc.db.GetSceneSet(req.SetID)
+	if err != nil {
+		return 0, apierrors.ErrInstantiateAutoTestSceneTemplate.InvalidParameter(err)
+	}
+	space, err := svc.db.GetAutoTestSpace(sceneSet.SpaceID)
+	if err != nil {
+		return 0, apierrors.ErrInstantiateAutoTestSceneTemplate.InvalidParameter(err)
+	}
+	if uint64(space.ProjectID) != template.ProjectID {
+		return 0, apierrors.ErrInstantiateAutoTestSceneTemplate.InvalidParameter("scene set does not belong to the project of the template")
+	}
+	if space.Status != apistructs.TestSpaceOpen {
+		return 0, apierrors.ErrInstantiateAutoTestSceneTemplate.InvalidState("所属测试空间已锁定")
+	}
+
+	name := req.Name
+	if name == "" {
+		if name, err = svc.GenerateSceneName(template.Name, sceneSet.ID); err != nil {
+			return 0, apierrors.ErrInstantiateAutoTestSceneTemplate.InternalError(err)
+		}
+	}
+	description := req.Description
+	if description == "" {
+		description = template.Description
+	}
+	var sceneReq apistructs.AutotestSceneRequest
+	sceneReq.Name = name
+	sceneReq.Description = description
+	sceneReq.SpaceID = sceneSet.SpaceID
+	sceneReq.SetID = sceneSet.ID
+	sceneReq.IdentityInfo = req.IdentityInfo
+	// 场景与其内容在同一事务中创建, 失败时不留下空场景
+	var sceneID uint64
+	err = svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		if sceneID, err = txSvc.CreateAutotestScene(sceneReq); err != nil {
+			return err
+		}
+		scene, err := txSvc.db.GetAutotestScene(sceneID)
+		if err != nil {
+			return apierrors.ErrInstantiateAutoTestSceneTemplate.InternalError(err)
+		}
+		if err := txSvc.applySceneTemplate(scene, template, req.Params, req.UserID); err != nil {
+			return apierrors.ErrInstantiateAutoTestSceneTemplate.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sceneID, nil
+}
+
+// UpdateAutoTestSceneFromTemplate 用模板的最新内容覆盖场景, 未填写的参数保留场景当前的入参值
+func (svc *Service) UpdateAutoTestSceneFromTemplate(req apistructs.AutoTestSceneUpdateFromTemplateRequest) (uint64, error) {
+	scene, err := svc.db.GetAutotestScene(req.SceneID)
+	if err != nil {
+		return 0, apierrors.ErrUpdateAutoTestSceneFromTemplate.InvalidParameter(err)
+	}
+	if scene.TemplateID == 0 {
+		return 0, apierrors.ErrUpdateAutoTestSceneFromTemplate.InvalidState("场景不是由模板创建")
+	}
+	template, err := svc.GetAutoTestSceneTemplate(scene.TemplateID)
+	if err != nil {
+		return 0, err
+	}
+	inputs, err := svc.db.ListAutoTestSceneInput(scene.ID)
+	if err != nil {
+		return 0, apierrors.ErrUpdateAutoTestSceneFromTemplate.InternalError(err)
+	}
+	params := mergeSceneTemplateParams(inputs, req.Params)
+	// 清空与重建在同一事务中执行, 失败时场景保持原内容
+	err = svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		if err := txSvc.db.ClearAutoTestSceneContents(scene.ID); err != nil {
+			return err
+		}
+		return txSvc.applySceneTemplate(scene, template, params, req.UserID)
+	})
+	if err != nil {
+		return 0, apierrors.ErrUpdateAutoTestSceneFromTemplate.InternalError(err)
+	}
+	return scene.ID, nil
+}
+
+// applySceneTemplate 按模板内容创建场景的入参、步骤和出参, 并记录场景对应的模板版本
+func (svc *Service) applySceneTemplate(scene *dao.AutoTestScene, template *dao.AutoTestSceneTemplate, params map[string]string, userID string) error {
+	if err := svc.applySceneContent(scene, apistructs.AutoTestSceneTemplateContent(template.Content), params, userID); err != nil {
+		return err
+	}
+	scene.TemplateID = template.ID
+	scene.TemplateVersion = template.Version
+	scene.UpdaterID = userID
+	return svc.db.UpdateAutotestScene(scene)
+}
+
+// applySceneContent 按保存的内容创建场景的入参、步骤和出参, params 中的值覆盖同名入参
+func (svc *Service) applySceneContent(scene *dao.AutoTestScene, content apistructs.AutoTestSceneTemplateContent, params map[string]string, userID string) error {
+	for _, v := range content.Inputs {
+		value, temp := v.Value, v.Temp
+		if param, ok := params[v.Name]; ok {
+			value, temp = param, param
+		}
+		if err := svc.db.CreateAutoTestSceneInput(&dao.AutoTestSceneInput{
+			Name:        v.Name,
+			Value:       value,
+			Temp:        temp,
+			Description: v.Description,
+			SceneID:     scene.ID,
+			SpaceID:     scene.SpaceID,
+			CreatorID:   userID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 内容中保存的是原场景的步骤 id, 创建后替换步骤间出参引用的 id
+	var head uint64
+	var replaceIdMap = map[uint64]uint64{}
+	for _, v := range content.Steps {
+		newStep := &dao.AutoTestSceneStep{
+			Type:      v.Type,
+			Value:     replacePreStepValue(v.Value, replaceIdMap),
+			Name:      v.Name,
+			PreID:     head,
+			PreType:   v.PreType,
+			SceneID:   scene.ID,
+			SpaceID:   scene.SpaceID,
+			APISpecID: v.APISpecID,
+			Condition: replacePreStepValue(v.Condition, replaceIdMap),
+			CreatorID: userID,
+		}
+		if err := svc.db.CreateAutoTestSceneStep(newStep); err != nil {
+			return err
+		}
+		head = newStep.ID
+		pHead := newStep.ID
+
+		var childStepIdMap = map[uint64]uint64{}
+		for _, pv := range v.Children {
+			newPStep := &dao.AutoTestSceneStep{
+				Type:      pv.Type,
+				Value:     replacePreStepValue(pv.Value, replaceIdMap),
+				Name:      pv.Name,
+				PreID:     pHead,
+				PreType:   pv.PreType,
+				SceneID:   scene.ID,
+				SpaceID:   scene.SpaceID,
+				APISpecID: pv.APISpecID,
+				Condition: replacePreStepValue(pv.Condition, replaceIdMap),
+				CreatorID: userID,
+			}
+			if err := svc.db.CreateAutoTestSceneStep(newPStep); err != nil {
+				return err
+			}
+			pHead = newPStep.ID
+			childStepIdMap[pv.ID] = newPStep.ID
+		}
+
+		replaceIdMap[v.ID] = newStep.ID
+		for key, v := range childStepIdMap {
+			replaceIdMap[key] = v
+		}
+	}
+
+	for _, v := range content.Outputs {
+		if err := svc.db.CreateAutoTestSceneOutput(&dao.AutoTestSceneOutput{
+			Name:        v.Name,
+			Value:       replacePreStepValue(v.Value, replaceIdMap),
+			Description: v.Description,
+			SceneID:     scene.ID,
+			SpaceID:     scene.SpaceID,
+			CreatorID:   userID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getSceneTemplateSource 获取保存为模板的场景及其所属项目, 引用场景集的场景依赖测试空间内的场景集, 不能保存为模板
+func (svc *Service) getSceneTemplateSource(sceneID uint64) (*dao.AutoTestScene, uint64, error) {
+	scene, err := svc.db.GetAutotestScene(sceneID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if scene.RefSetID > 0 {
+		return nil, 0, apierrors.ErrCreateAutoTestSceneTemplate.InvalidState("引用场景集的场景不能保存为模板")
+	}
+	space, err := svc.db.GetAutoTestSpace(scene.SpaceID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scene, uint64(space.ProjectID), nil
+}
+
+// snapshotSceneTemplateContent 读取场景当前的入参、出参和步骤
+func (svc *Service) snapshotSceneTemplateContent(sceneID uint64) (*apistructs.AutoTestSceneTemplateContent, error) {
+	inputs, err := svc.ListAutoTestSceneInput(sceneID)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := svc.ListAutoTestSceneOutput(sceneID)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := svc.ListAutoTestSceneStep(sceneID)
+	if err != nil {
+		return nil, err
+	}
+	return &apistructs.AutoTestSceneTemplateContent{Inputs: inputs, Outputs: outputs, Steps: steps}, nil
+}
+
+// mergeSceneTemplateParams 以场景当前的入参值为基础, 覆盖本次指定的参数
+func mergeSceneTemplateParams(inputs []dao.AutoTestSceneInput, override map[string]string) map[string]string {
+	params := make(map[string]string, len(inputs)+len(override))
+	for _, input := range inputs {
+		params[input.Name] = input.Value
+	}
+	for name, value := range override {
+		params[name] = value
+	}
+	return params
+}
+
+func checkSceneTemplateName(name, description string) error {
+	if err := strutil.Validate(name, strutil.MaxRuneCountValidator(nameMaxLength)); err != nil {
+		return err
+	}
+	if err := strutil.Validate(description, strutil.MaxRuneCountValidator(descMaxLength)); err != nil {
+		return err
+	}
+	if !sceneTemplateNameRe.MatchString(name) {
+		return apierrors.ErrCreateAutoTestSceneTemplate.InvalidParameter("只可输入中文、英文、数字、中划线或下划线")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestCheckSceneTemplateName(t *testing.T) {
+	assert.NoError(t, checkSceneTemplateName("登录_login-1", ""))
+	assert.Error(t, checkSceneTemplateName("", ""))
+	assert.Error(t, checkSceneTemplateName("login flow", ""))
+}
+
+func TestMergeSceneTemplateParams(t *testing.T) {
+	inputs := []dao.AutoTestSceneInput{
+		{Name: "host", Value: "dev.example.com"},
+		{Name: "user", Value: "admin"},
+	}
+	params := mergeSceneTemplateParams(inputs, map[string]string{"host": "test.example.com", "token": "t"})
+	assert.Equal(t, map[string]string{"host": "test.example.com", "user": "admin", "token": "t"}, params)
+}
+
+func TestAutoTestSceneTemplateConvert(t *testing.T) {
+	template := dao.AutoTestSceneTemplate{
+		Version: 2,
+		Content: dao.AutoTestSceneTemplateContent{
+			Inputs: []apistructs.AutoTestSceneInput{{Name: "host", Value: "dev.example.com", Description: "域名"}},
+			Steps: []apistructs.AutoTestSceneStep{
+				{Name: "login", Children: []apistructs.AutoTestSceneStep{{Name: "wait"}}},
+				{Name: "query"},
+			},
+		},
+	}
+	result := template.Convert()
+	assert.Equal(t, []apistructs.AutoTestSceneTemplateParam{{Name: "host", Description: "域名", Default: "dev.example.com"}}, result.Params)
+	assert.Equal(t, 3, result.StepCount)
+	assert.Nil(t, result.Content)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func newStep(id uint64, value string, children ...apistructs.AutoTestSceneStep) apistructs.AutoTestSceneStep {
+	step := apistructs.AutoTestSceneStep{Value: value, Children: children}
+	step.ID = id
+	return step
+}
+
+func stageIDs(stages [][]apistructs.AutoTestSceneStep) [][]uint64 {
+	var ids [][]uint64
+	for _, stage := range stages {
+		var stageIDs []uint64
+		for _, step := range stage {
+			stageIDs = append(stageIDs, step.ID)
+		}
+		ids = append(ids, stageIDs)
+	}
+	return ids
+}
+
+func TestStepToStages(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		newStep(1, "{}"),
+		newStep(2, "{}",
+			newStep(3, `{"url":"${{ outputs.1.token }}"}`),
+			newStep(4, `{"url":"${{ outputs.2.id }}"}`),
+			newStep(5, "{}"),
+		),
+		newStep(6, "{}"),
+	}
+
+	assert.Equal(t, [][]uint64{{1}, {2, 3, 5}, {4}, {6}}, stageIDs(StepToStages(steps, 0)))
+	assert.Equal(t, [][]uint64{{1}, {2, 3}, {5}, {4}, {6}}, stageIDs(StepToStages(steps, 2)))
+}
+
+func TestStepToStagesCycle(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		newStep(1, `{"a":"${{ outputs.2.x }}"}`,
+			newStep(2, `{"a":"${{ outputs.1.x }}"}`),
+			newStep(3, "{}"),
+		),
+	}
+
+	assert.Equal(t, [][]uint64{{3}, {1}, {2}}, stageIDs(StepToStages(steps, 0)))
+}
+
+func TestStepToStagesCondition(t *testing.T) {
+	conditional := newStep(4, "{}")
+	conditional.Condition = "${{ outputs.3.status }} == 200"
+	steps := []apistructs.AutoTestSceneStep{
+		newStep(1, "{}"),
+		newStep(2, "{}",
+			newStep(3, "{}"),
+			conditional,
+		),
+	}
+
+	// 执行条件引用的步骤需先执行
+	assert.Equal(t, [][]uint64{{1}, {2, 3}, {4}}, stageIDs(StepToStages(steps, 0)))
+}
+
+func TestNormalizeStepRetryPolicy(t *testing.T) {
+	assert.Equal(t, 1, normalizeStepRetryPolicy(nil).MaxAttempts)
+
+	policy := normalizeStepRetryPolicy(&apistructs.AutoTestStepRetryPolicy{MaxAttempts: 100, DelayMs: -1})
+	assert.Equal(t, maxSceneStepRetryAttempts, policy.MaxAttempts)
+	assert.Equal(t, int64(0), policy.DelayMs)
+}
+
+func TestStepRetryLoop(t *testing.T) {
+	assert.Nil(t, stepRetryLoop(3, nil))
+	assert.Nil(t, stepRetryLoop(3, &apistructs.AutoTestStepRetryPolicy{MaxAttempts: 1, RetryOnInvokeError: true}))
+	// 未配置重试条件时不重试
+	assert.Nil(t, stepRetryLoop(3, &apistructs.AutoTestStepRetryPolicy{MaxAttempts: 3}))
+
+	loop := stepRetryLoop(3, &apistructs.AutoTestStepRetryPolicy{
+		MaxAttempts:          3,
+		DelayMs:              1500,
+		RetryOnStatusCodes:   []int{502, 503},
+		RetryOnAssertFailure: true,
+		RetryOnInvokeError:   true,
+	})
+	if !assert.NotNil(t, loop) {
+		return
+	}
+	assert.Equal(t, "!('${{ outputs.3.api_status }}' == '0' || '${{ outputs.3.api_status }}' == '502' || "+
+		"'${{ outputs.3.api_status }}' == '503' || "+
+		"('${{ outputs.3.api_status }}' != '0' && '${{ outputs.3.result }}' != 'success'))", loop.Break)
+	assert.Equal(t, int64(3), loop.Strategy.MaxTimes)
+	assert.Equal(t, uint64(2), loop.Strategy.IntervalSec)
+	assert.Equal(t, int64(2), loop.Strategy.DeclineLimitSec)
+}
+
+func TestStepToActionRetry(t *testing.T) {
+	step := apistructs.AutoTestSceneStep{
+		Type:  apistructs.StepTypeAPI,
+		Value: `{"apiSpec":{},"retry":{"maxAttempts":2,"retryOnStatusCodes":[503]}}`,
+	}
+	step.ID = 3
+	actions, err := StepToAction(step)
+	assert.NoError(t, err)
+	action := actions["api-test"]
+	if !assert.NotNil(t, action) || !assert.NotNil(t, action.Loop) {
+		return
+	}
+	assert.Equal(t, "!('${{ outputs.3.api_status }}' == '503')", action.Loop.Break)
+
+	// 步骤自定义的循环优先
+	step.Value = `{"apiSpec":{},"loop":{"break":"1 == 1","strategy":{"max_times":5}},"retry":{"maxAttempts":2,"retryOnStatusCodes":[503]}}`
+	actions, err = StepToAction(step)
+	assert.NoError(t, err)
+	assert.Equal(t, "1 == 1", actions["api-test"].Loop.Break)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+// maxSceneStepTimeoutMs 步骤超时时间的上限
+const maxSceneStepTimeoutMs = 30 * 60 * 1000
+
+func checkStepTimeoutMs(timeoutMs int64) error {
+	if timeoutMs < 0 || timeoutMs > maxSceneStepTimeoutMs {
+		return fmt.Errorf("timeout must be between 0 and %d ms", maxSceneStepTimeoutMs)
+	}
+	return nil
+}
+
+// validateStepPolicy 校验 api 步骤的超时时间和失败处理策略
+func validateStepPolicy(stepValue string) error {
+	if stepValue == "" {
+		return nil
+	}
+	var value apistructs.AutoTestRunStep
+	if err := json.Unmarshal([]byte(stepValue), &value); err != nil {
+		return err
+	}
+	if err := checkStepTimeoutMs(value.TimeoutMs); err != nil {
+		return err
+	}
+	switch value.OnError {
+	case "", apistructs.AutoTestStepOnErrorFail, apistructs.AutoTestStepOnErrorContinue:
+		return nil
+	default:
+		return fmt.Errorf("invalid onError policy %s", value.OnError)
+	}
+}
+
+// applySceneStepTimeout 为场景的步骤填充场景的默认超时时间
+func applySceneStepTimeout(steps []apistructs.AutoTestSceneStep, timeoutMs int64) []apistructs.AutoTestSceneStep {
+	if timeoutMs <= 0 {
+		return steps
+	}
+	for i := range steps {
+		steps[i].DefaultTimeoutMs = timeoutMs
+		for j := range steps[i].Children {
+			steps[i].Children[j].DefaultTimeoutMs = timeoutMs
+		}
+	}
+	return steps
+}
+
+// stepTimeoutMs 返回步骤生效的超时时间, 步骤未配置时使用场景的默认超时时间
+func stepTimeoutMs(value apistructs.AutoTestRunStep, defaultTimeoutMs int64) int64 {
+	if value.TimeoutMs > 0 {
+		return value.TimeoutMs
+	}
+	return defaultTimeoutMs
+}
+
+// setStepPolicyLabels 将步骤的超时时间和失败处理策略写入 action label, 由 api-test 执行器读取
+func setStepPolicyLabels(action *pipelineyml.Action, value apistructs.AutoTestRunStep, defaultTimeoutMs int64) {
+	if timeoutMs := stepTimeoutMs(value, defaultTimeoutMs); timeoutMs > 0 {
+		action.Labels[apistructs.AutotestStepTimeoutMs] = strconv.FormatInt(timeoutMs, 10)
+	}
+	if value.OnError != "" {
+		action.Labels[apistructs.AutotestStepOnError] = string(value.OnError)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+func TestValidateStepPolicy(t *testing.T) {
+	assert.NoError(t, validateStepPolicy(""))
+	assert.NoError(t, validateStepPolicy(`{"timeoutMs":5000,"onError":"continue"}`))
+	assert.Error(t, validateStepPolicy(`{"timeoutMs":-1}`))
+	assert.Error(t, validateStepPolicy(`{"timeoutMs":3600000}`))
+	assert.Error(t, validateStepPolicy(`{"onError":"ignore"}`))
+}
+
+func TestApplySceneStepTimeout(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		{Children: []apistructs.AutoTestSceneStep{{}}},
+	}
+	steps = applySceneStepTimeout(steps, 3000)
+	assert.Equal(t, int64(3000), steps[0].DefaultTimeoutMs)
+	assert.Equal(t, int64(3000), steps[0].Children[0].DefaultTimeoutMs)
+}
+
+func TestSetStepPolicyLabels(t *testing.T) {
+	action := pipelineyml.Action{Labels: map[string]string{}}
+	setStepPolicyLabels(&action, apistructs.AutoTestRunStep{}, 0)
+	assert.Empty(t, action.Labels)
+
+	// 步骤未配置超时时间时使用场景的默认值
+	setStepPolicyLabels(&action, apistructs.AutoTestRunStep{OnError: apistructs.AutoTestStepOnErrorContinue}, 3000)
+	assert.Equal(t, "3000", action.Labels[apistructs.AutotestStepTimeoutMs])
+	assert.Equal(t, "continue", action.Labels[apistructs.AutotestStepOnError])
+
+	setStepPolicyLabels(&action, apistructs.AutoTestRunStep{TimeoutMs: 500}, 3000)
+	assert.Equal(t, "500", action.Labels[apistructs.AutotestStepTimeoutMs])
+}
+
+func TestConvertTaskToExecutionStepTimeout(t *testing.T) {
+	step := convertTaskToExecutionStep(apistructs.PipelineTaskDTO{
+		Name:   "12",
+		Status: apistructs.PipelineStatusTimeout,
+		Result: apistructs.PipelineTaskResult{
+			Metadata: apistructs.Metadata{
+				{Name: metaKeyAPITimeoutMs, Value: "1000"},
+				{Name: metaKeyAPIDurationMs, Value: "1002"},
+			},
+		},
+	})
+	assert.Equal(t, apistructs.PipelineStatusTimeout, step.Status)
+	assert.Equal(t, int64(1000), step.TimeoutMs)
+	assert.Equal(t, int64(1002), step.DurationMs)
+}
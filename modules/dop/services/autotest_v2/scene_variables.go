@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/expression"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml/pexpr"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// applyVariableOverrides 用执行请求传入的变量替换步骤中对同名场景入参和全局配置的引用, 仅影响本次执行;
+// 优先级为: 请求传入的变量 > 场景入参 > 全局配置
+func applyVariableOverrides(steps []apistructs.AutoTestSceneStep, variables map[string]string) []apistructs.AutoTestSceneStep {
+	if len(variables) == 0 {
+		return steps
+	}
+	result := make([]apistructs.AutoTestSceneStep, 0, len(steps))
+	for _, step := range steps {
+		step.Value = renderVariableOverrides(step.Value, variables, true)
+		step.Condition = renderVariableOverrides(step.Condition, variables, false)
+		step.Children = applyVariableOverrides(step.Children, variables)
+		result = append(result, step)
+	}
+	return result
+}
+
+// renderVariableOverrides 替换 ${{ params.xxx }}、${{ configs.autotest.xxx }} 以及旧语法 ${params.xxx}、${configs.autotest.xxx},
+// 步骤的值为 json, jsonEscape 为 true 时替换的内容按 json 字符串转义
+func renderVariableOverrides(value string, variables map[string]string, jsonEscape bool) string {
+	if value == "" {
+		return value
+	}
+	lookup := func(ref string) (string, bool) {
+		var name string
+		switch {
+		case strings.HasPrefix(ref, expression.Params+"."):
+			name = strings.TrimPrefix(ref, expression.Params+".")
+		case strings.HasPrefix(ref, expression.Configs+"."+apistructs.PipelineSourceAutoTest.String()+"."):
+			name = strings.TrimPrefix(ref, expression.Configs+"."+apistructs.PipelineSourceAutoTest.String()+".")
+		default:
+			return "", false
+		}
+		v, ok := variables[name]
+		if !ok || !jsonEscape {
+			return v, ok
+		}
+		escaped, _ := json.Marshal(v)
+		return strings.Trim(string(escaped), `"`), true
+	}
+
+	rendered := strutil.ReplaceAllStringSubmatchFunc(pexpr.PhRe, value, func(subs []string) string {
+		if v, ok := lookup(strings.TrimSpace(subs[1])); ok {
+			return v
+		}
+		return subs[0]
+	})
+	for name := range variables {
+		for _, ref := range []string{
+			expression.Params + "." + name,
+			expression.Configs + "." + apistructs.PipelineSourceAutoTest.String() + "." + name,
+		} {
+			old := expression.OldLeftPlaceholder + ref + expression.OldRightPlaceholder
+			if !strings.Contains(rendered, old) {
+				continue
+			}
+			v, _ := lookup(ref)
+			rendered = strings.ReplaceAll(rendered, old, v)
+		}
+	}
+	return rendered
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestRenderVariableOverrides(t *testing.T) {
+	variables := map[string]string{
+		"host":  "staging.example.com",
+		"token": `a"b`,
+	}
+	assert.Equal(t, `{"url":"http://staging.example.com/api","token":"a\"b"}`,
+		renderVariableOverrides(`{"url":"http://${{ params.host }}/api","token":"${{ configs.autotest.token }}"}`, variables, true))
+	assert.Equal(t, `{"url":"staging.example.com","token":"a\"b"}`,
+		renderVariableOverrides(`{"url":"${params.host}","token":"${configs.autotest.token}"}`, variables, true))
+	assert.Equal(t, `"a"b" == "x"`, renderVariableOverrides(`"${{ configs.autotest.token }}" == "x"`, variables, false))
+	// 未覆盖的变量和其他表达式保持不变
+	assert.Equal(t, `{"a":"${{ params.user }}","b":"${{ outputs.1.id }}","c":"${{ configs.other.host }}"}`,
+		renderVariableOverrides(`{"a":"${{ params.user }}","b":"${{ outputs.1.id }}","c":"${{ configs.other.host }}"}`, variables, true))
+}
+
+func TestApplyVariableOverrides(t *testing.T) {
+	steps := []apistructs.AutoTestSceneStep{
+		{
+			Value:     `{"url":"${{ params.host }}"}`,
+			Condition: `${{ params.host }} != ""`,
+			Children:  []apistructs.AutoTestSceneStep{{Value: `{"url":"${{ configs.autotest.host }}"}`}},
+		},
+	}
+	result := applyVariableOverrides(steps, map[string]string{"host": "dev"})
+	assert.Equal(t, `{"url":"dev"}`, result[0].Value)
+	assert.Equal(t, `dev != ""`, result[0].Condition)
+	assert.Equal(t, `{"url":"dev"}`, result[0].Children[0].Value)
+	// 不修改原步骤
+	assert.Equal(t, `{"url":"${{ params.host }}"}`, steps[0].Value)
+	assert.Equal(t, `{"url":"${{ configs.autotest.host }}"}`, steps[0].Children[0].Value)
+}
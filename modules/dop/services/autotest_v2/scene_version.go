@@ -0,0 +1,152 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// RecordAutoTestSceneVersion 记录场景当前的内容为新版本, 内容与最新版本一致时不记录; 失败不影响场景的修改, 只打印日志
+func (svc *Service) RecordAutoTestSceneVersion(sceneID uint64, operatorID string) {
+	if _, err := svc.recordSceneVersion(sceneID, operatorID, 0); err != nil {
+		logrus.Errorf("failed to record autotest scene version, sceneID: %d, err: %v", sceneID, err)
+	}
+}
+
+// recordSceneVersion 记录场景当前的内容, restoredFrom 不为 0 时表示由该版本恢复, 总是生成新版本
+func (svc *Service) recordSceneVersion(sceneID uint64, operatorID string, restoredFrom uint64) (*dao.AutoTestSceneVersion, error) {
+	scene, err := svc.db.GetAutotestScene(sceneID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := svc.snapshotSceneTemplateContent(sceneID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := svc.db.GetLatestAutoTestSceneVersion(sceneID)
+	if err != nil {
+		return nil, err
+	}
+	if restoredFrom == 0 && latest != nil && sameSceneVersion(latest, content, scene.StepTimeoutMs) {
+		return latest, nil
+	}
+	version := dao.AutoTestSceneVersion{
+		SceneID:             sceneID,
+		StepTimeoutMs:       scene.StepTimeoutMs,
+		Content:             dao.AutoTestSceneTemplateContent(*content),
+		OperatorID:          operatorID,
+		RestoredFromVersion: restoredFrom,
+	}
+	if err := svc.db.CreateAutoTestSceneVersion(&version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// ListAutoTestSceneVersions 按版本号倒序列出场景的历史版本
+func (svc *Service) ListAutoTestSceneVersions(sceneID uint64) ([]apistructs.AutoTestSceneVersion, error) {
+	versions, err := svc.db.ListAutoTestSceneVersions(sceneID)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSceneVersion.InternalError(err)
+	}
+	results := make([]apistructs.AutoTestSceneVersion, 0, len(versions))
+	for _, v := range versions {
+		results = append(results, v.Convert())
+	}
+	return results, nil
+}
+
+// GetAutoTestSceneVersion 获取场景的历史版本, 包含版本内容
+func (svc *Service) GetAutoTestSceneVersion(sceneID, version uint64) (*apistructs.AutoTestSceneVersion, error) {
+	v, err := svc.db.GetAutoTestSceneVersion(sceneID, version)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneVersion.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSceneVersion.InternalError(err)
+	}
+	result := v.Convert()
+	content := apistructs.AutoTestSceneTemplateContent(v.Content)
+	result.Content = &content
+	return &result, nil
+}
+
+// RestoreAutoTestSceneVersion 用历史版本的内容覆盖场景, 并将恢复后的内容记录为新版本, 不删除任何历史版本
+func (svc *Service) RestoreAutoTestSceneVersion(req apistructs.AutoTestSceneVersionRestoreRequest) (*apistructs.AutoTestSceneVersion, error) {
+	target, err := svc.db.GetAutoTestSceneVersion(req.SceneID, req.Version)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneVersion.NotFound()
+		}
+		return nil, apierrors.ErrRestoreAutoTestSceneVersion.InternalError(err)
+	}
+	// 恢复在同一事务中执行, 并锁定场景, 并发恢复同一场景时依次执行, 失败时场景保持原内容
+	var version *dao.AutoTestSceneVersion
+	err = svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		scene, err := txSvc.db.LockAutotestScene(req.SceneID)
+		if err != nil {
+			return apierrors.ErrRestoreAutoTestSceneVersion.InvalidParameter(err)
+		}
+		// 先保留场景当前未被记录的修改, 避免恢复后丢失
+		if _, err := txSvc.recordSceneVersion(scene.ID, scene.UpdaterID, 0); err != nil {
+			return apierrors.ErrRestoreAutoTestSceneVersion.InternalError(err)
+		}
+		if err := txSvc.db.ClearAutoTestSceneContents(scene.ID); err != nil {
+			return apierrors.ErrRestoreAutoTestSceneVersion.InternalError(err)
+		}
+		if err := txSvc.applySceneContent(scene, apistructs.AutoTestSceneTemplateContent(target.Content), nil, req.UserID); err != nil {
+			return apierrors.ErrRestoreAutoTestSceneVersion.InternalError(err)
+		}
+		scene.StepTimeoutMs = target.StepTimeoutMs
+		scene.UpdaterID = req.UserID
+		if err := txSvc.db.UpdateAutotestScene(scene); err != nil {
+			return apierrors.ErrRestoreAutoTestSceneVersion.InternalError(err)
+		}
+		if version, err = txSvc.recordSceneVersion(scene.ID, req.UserID, target.Version); err != nil {
+			return apierrors.ErrRestoreAutoTestSceneVersion.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := version.Convert()
+	return &result, nil
+}
+
+// sameSceneVersion 判断场景当前的内容与版本是否一致
+func sameSceneVersion(version *dao.AutoTestSceneVersion, content *apistructs.AutoTestSceneTemplateContent, stepTimeoutMs int64) bool {
+	if version.StepTimeoutMs != stepTimeoutMs {
+		return false
+	}
+	prev, err := json.Marshal(version.Content)
+	if err != nil {
+		return false
+	}
+	cur, err := json.Marshal(dao.AutoTestSceneTemplateContent(*content))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(prev, cur)
+}
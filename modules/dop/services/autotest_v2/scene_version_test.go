@@ -0,0 +1,59 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestSameSceneVersion(t *testing.T) {
+	content := apistructs.AutoTestSceneTemplateContent{
+		Inputs: []apistructs.AutoTestSceneInput{{Name: "host", Value: "dev.example.com"}},
+		Steps:  []apistructs.AutoTestSceneStep{{Name: "login", Value: `{"apiSpec":{}}`}},
+	}
+	version := &dao.AutoTestSceneVersion{StepTimeoutMs: 1000, Content: dao.AutoTestSceneTemplateContent(content)}
+
+	same := content
+	assert.True(t, sameSceneVersion(version, &same, 1000))
+	assert.False(t, sameSceneVersion(version, &same, 2000))
+
+	changed := content
+	changed.Steps = []apistructs.AutoTestSceneStep{{Name: "login", Value: `{"apiSpec":{"url":"/login"}}`}}
+	assert.False(t, sameSceneVersion(version, &changed, 1000))
+}
+
+func TestAutoTestSceneVersionConvert(t *testing.T) {
+	version := dao.AutoTestSceneVersion{
+		SceneID:             1,
+		Version:             3,
+		OperatorID:          "2",
+		RestoredFromVersion: 1,
+		Content: dao.AutoTestSceneTemplateContent{
+			Steps: []apistructs.AutoTestSceneStep{
+				{Name: "login", Children: []apistructs.AutoTestSceneStep{{Name: "wait"}}},
+			},
+		},
+	}
+	result := version.Convert()
+	assert.Equal(t, uint64(3), result.Version)
+	assert.Equal(t, uint64(1), result.RestoredFromVersion)
+	assert.Equal(t, 2, result.StepCount)
+	assert.Nil(t, result.Content)
+}
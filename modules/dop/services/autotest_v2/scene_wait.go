@@ -0,0 +1,125 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	defaultWaitPollIntervalSec = 5
+	maxWaitPollTimeoutSec      = 60 * 60
+)
+
+// waitPollStepToStages 将轮询等待步骤展开为两个 stage:
+// 轮询 stage 通过流水线的 loop 每隔 intervalSec 重新执行, 直到条件满足或达到超时对应的次数;
+// 检查 stage 只在条件仍不满足时执行并失败, 条件满足时被跳过.
+// stage 中没有轮询等待步骤时返回 false
+func waitPollStepToStages(stage []apistructs.AutoTestSceneStep) ([]*pipelineyml.Stage, bool, error) {
+	var (
+		pollStep *apistructs.AutoTestSceneStep
+		value    apistructs.AutoTestRunWait
+	)
+	for i := range stage {
+		if stage[i].Type != apistructs.StepTypeWait || stage[i].Value == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(stage[i].Value), &value); err != nil {
+			return nil, true, err
+		}
+		if value.Poll != nil {
+			pollStep = &stage[i]
+			break
+		}
+	}
+	if pollStep == nil {
+		return nil, false, nil
+	}
+	if len(stage) > 1 {
+		return nil, true, fmt.Errorf("wait step %s can not poll in parallel with other steps", pollStep.Name)
+	}
+	poll := value.Poll
+	if poll.Condition == "" {
+		return nil, true, fmt.Errorf("wait step %s: missing poll condition", pollStep.Name)
+	}
+	if poll.TimeoutSec <= 0 || poll.TimeoutSec > maxWaitPollTimeoutSec {
+		return nil, true, fmt.Errorf("wait step %s: poll timeout must be between 1 and %d seconds", pollStep.Name, maxWaitPollTimeoutSec)
+	}
+	if poll.IntervalSec <= 0 {
+		poll.IntervalSec = defaultWaitPollIntervalSec
+	}
+	maxTimes := (poll.TimeoutSec + poll.IntervalSec - 1) / poll.IntervalSec
+
+	pollAction, err := StepToAction(*pollStep)
+	if err != nil {
+		return nil, true, err
+	}
+	for _, action := range pollAction {
+		if len(poll.ApiSpec) > 0 {
+			action.Type = "api-test"
+			action.Version = "2.0"
+			action.Params = poll.ApiSpec
+			action.Commands = nil
+		} else {
+			action.Commands = []string{"echo polling wait condition"}
+		}
+		action.Loop = &apistructs.PipelineTaskLoop{
+			Break: poll.Condition,
+			Strategy: &apistructs.LoopStrategy{
+				MaxTimes:        int64(maxTimes),
+				DeclineRatio:    1,
+				DeclineLimitSec: int64(poll.IntervalSec),
+				IntervalSec:     uint64(poll.IntervalSec),
+			},
+		}
+	}
+	// action 以类型为 key, 类型变化后重新构造
+	var typedPollAction = map[pipelineyml.ActionType]*pipelineyml.Action{}
+	for _, action := range pollAction {
+		typedPollAction[action.Type] = action
+	}
+
+	checkStep := *pollStep
+	checkStep.Name = "等待条件超时检查"
+	if pollStep.Name != "" {
+		checkStep.Name = pollStep.Name + " 超时检查"
+	}
+	checkAction, err := StepToAction(checkStep)
+	if err != nil {
+		return nil, true, err
+	}
+	for _, action := range checkAction {
+		action.Alias = pipelineyml.ActionAlias(strconv.FormatUint(pollStep.ID, 10) + "_timeout")
+		action.If = "!(" + poll.Condition + ")"
+		// 步骤本身因执行条件被跳过时, 检查也跳过
+		if pollStep.Condition != "" {
+			action.If = "(" + pollStep.Condition + ") && " + action.If
+		}
+		action.Commands = []string{
+			fmt.Sprintf("echo wait condition is not satisfied within %d seconds", poll.TimeoutSec),
+			"exit 1",
+		}
+	}
+
+	var pollStage, checkStage pipelineyml.Stage
+	pollStage.Actions = append(pollStage.Actions, typedPollAction)
+	checkStage.Actions = append(checkStage.Actions, checkAction)
+	return []*pipelineyml.Stage{&pollStage, &checkStage}, true, nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestWaitPollStepToStages(t *testing.T) {
+	fixed := newStep(1, `{"waitTime":3}`)
+	fixed.Type = apistructs.StepTypeWait
+	_, ok, err := waitPollStepToStages([]apistructs.AutoTestSceneStep{fixed})
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	value, _ := json.Marshal(apistructs.AutoTestRunWait{Poll: &apistructs.AutoTestWaitPoll{
+		Condition:   "${{ outputs.1.status }} == 'done'",
+		IntervalSec: 10,
+		TimeoutSec:  25,
+	}})
+	poll := newStep(2, string(value))
+	poll.Type = apistructs.StepTypeWait
+
+	stages, ok, err := waitPollStepToStages([]apistructs.AutoTestSceneStep{poll})
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Len(t, stages, 2)
+	for _, action := range stages[0].Actions[0] {
+		assert.Equal(t, "2", string(action.Alias))
+		assert.Equal(t, "${{ outputs.1.status }} == 'done'", action.Loop.Break)
+		assert.Equal(t, int64(3), action.Loop.Strategy.MaxTimes)
+		assert.Equal(t, uint64(10), action.Loop.Strategy.IntervalSec)
+	}
+	for _, action := range stages[1].Actions[0] {
+		assert.Equal(t, "2_timeout", string(action.Alias))
+		assert.Equal(t, "!(${{ outputs.1.status }} == 'done')", action.If)
+	}
+
+	_, _, err = waitPollStepToStages([]apistructs.AutoTestSceneStep{poll, fixed})
+	assert.Error(t, err)
+
+	value, _ = json.Marshal(apistructs.AutoTestRunWait{Poll: &apistructs.AutoTestWaitPoll{Condition: "1 == 1"}})
+	poll.Value = string(value)
+	_, _, err = waitPollStepToStages([]apistructs.AutoTestSceneStep{poll})
+	assert.Error(t, err)
+}
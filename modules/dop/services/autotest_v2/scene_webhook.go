@@ -0,0 +1,457 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+const (
+	// sceneWebhookMaxAttempts 单次投递的最大尝试次数, 超过后不再重试
+	sceneWebhookMaxAttempts = 6
+	// sceneWebhookRetryBackoff 首次重试的等待时间, 之后每次翻倍
+	sceneWebhookRetryBackoff = 30 * time.Second
+	// sceneWebhookClaimLease 抢占投递记录后的租约时间, 实例异常退出时租约到期后重新投递
+	sceneWebhookClaimLease = time.Minute
+	// sceneWebhookDeliveryBatchSize 每轮处理的待投递记录数量
+	sceneWebhookDeliveryBatchSize = 100
+	// sceneWebhookMessageMaxLength 投递失败信息保存的最大长度
+	sceneWebhookMessageMaxLength = 1024
+)
+
+// sceneWebhookClient 投递 webhook 使用的客户端, 建立连接时校验实际连接的地址, 防止域名解析变化或重定向后访问内网;
+// 不使用环境变量中的代理, 否则无法校验目标地址
+var sceneWebhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: sceneWebhookDialControl,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// sceneWebhookLookupIP 解析 webhook 地址中的域名
+var sceneWebhookLookupIP = net.LookupIP
+
+// sceneWebhookBlockedNetworks 不允许 webhook 访问的网段: 本机、私有网络、链路本地地址等
+var sceneWebhookBlockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.168.0.0/16", "::/128", "::1/128", "fc00::/7", "fe80::/10",
+)
+
+// CreateAutoTestSceneWebhook 创建场景执行结束的 webhook, 签名密钥由 kms 生成并加密保存, 明文仅在创建时返回一次
+func (svc *Service) CreateAutoTestSceneWebhook(req apistructs.AutoTestSceneWebhookCreateRequest) (*apistructs.AutoTestSceneWebhook, error) {
+	if err := checkSceneWebhookURL(req.URL); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneWebhook.InvalidParameter(err)
+	}
+	key, err := svc.bdl.KMSCreateKey(apistructs.KMSCreateKeyRequest{
+		CreateKeyRequest: kmstypes.CreateKeyRequest{
+			PluginKind: kmstypes.PluginKind_DICE_KMS,
+		},
+	})
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneWebhook.InternalError(err)
+	}
+	dataKey, err := svc.bdl.KMSGenerateDataKey(apistructs.KMSGenerateDataKeyRequest{
+		GenerateDataKeyRequest: kmstypes.GenerateDataKeyRequest{
+			KeyID: key.KeyMetadata.KeyID,
+		},
+	})
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneWebhook.InternalError(err)
+	}
+	webhook := dao.AutoTestSceneWebhook{
+		SpaceID:          req.SpaceID,
+		SceneID:          req.SceneID,
+		URL:              req.URL,
+		Enabled:          req.Enabled,
+		KMSKeyID:         key.KeyMetadata.KeyID,
+		SecretCiphertext: dataKey.CiphertextBase64,
+		CreatorID:        req.UserID,
+		UpdaterID:        req.UserID,
+	}
+	if err := svc.db.CreateAutoTestSceneWebhook(&webhook); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSceneWebhook.InternalError(err)
+	}
+	result := webhook.Convert()
+	result.Secret = dataKey.PlaintextBase64
+	return &result, nil
+}
+
+// UpdateAutoTestSceneWebhook 更新 webhook 地址或启用状态
+func (svc *Service) UpdateAutoTestSceneWebhook(req apistructs.AutoTestSceneWebhookUpdateRequest) (*apistructs.AutoTestSceneWebhook, error) {
+	webhook, err := svc.GetAutoTestSceneWebhook(req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		if err := checkSceneWebhookURL(*req.URL); err != nil {
+			return nil, apierrors.ErrUpdateAutoTestSceneWebhook.InvalidParameter(err)
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	webhook.UpdaterID = req.UserID
+	if err := svc.db.UpdateAutoTestSceneWebhook(webhook); err != nil {
+		return nil, apierrors.ErrUpdateAutoTestSceneWebhook.InternalError(err)
+	}
+	result := webhook.Convert()
+	return &result, nil
+}
+
+// DeleteAutoTestSceneWebhook 删除 webhook 及其投递记录, 同时删除签名密钥对应的 kms 密钥.
+// 先删除密钥, 删除记录失败时可以重试
+func (svc *Service) DeleteAutoTestSceneWebhook(id uint64) error {
+	webhook, err := svc.GetAutoTestSceneWebhook(id)
+	if err != nil {
+		return err
+	}
+	if webhook.KMSKeyID != "" {
+		if err := svc.bdl.KMSDeleteKey(apistructs.KMSDeleteKeyRequest{
+			DeleteKeyRequest: kmstypes.DeleteKeyRequest{KeyID: webhook.KMSKeyID},
+		}); err != nil {
+			return apierrors.ErrDeleteAutoTestSceneWebhook.InternalError(err)
+		}
+	}
+	if err := svc.db.DeleteAutoTestSceneWebhook(id); err != nil {
+		return apierrors.ErrDeleteAutoTestSceneWebhook.InternalError(err)
+	}
+	return nil
+}
+
+// GetAutoTestSceneWebhook 获取 webhook
+func (svc *Service) GetAutoTestSceneWebhook(id uint64) (*dao.AutoTestSceneWebhook, error) {
+	webhook, err := svc.db.GetAutoTestSceneWebhook(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSceneWebhook.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSceneWebhook.InternalError(err)
+	}
+	return webhook, nil
+}
+
+// ListAutoTestSceneWebhooks 查询 webhook 列表
+func (svc *Service) ListAutoTestSceneWebhooks(req apistructs.AutoTestSceneWebhookListRequest) ([]apistructs.AutoTestSceneWebhook, error) {
+	webhooks, err := svc.db.ListAutoTestSceneWebhooks(req)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSceneWebhook.InternalError(err)
+	}
+	results := make([]apistructs.AutoTestSceneWebhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		results = append(results, webhook.Convert())
+	}
+	return results, nil
+}
+
+// ListAutoTestSceneWebhookDeliveries 分页查询 webhook 的投递记录
+func (svc *Service) ListAutoTestSceneWebhookDeliveries(req apistructs.AutoTestSceneWebhookDeliveryListRequest) (*apistructs.AutoTestSceneWebhookDeliveryPagingData, error) {
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	total, deliveries, err := svc.db.PagingAutoTestSceneWebhookDeliveries(req)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSceneWebhookDelivery.InternalError(err)
+	}
+	result := &apistructs.AutoTestSceneWebhookDeliveryPagingData{
+		Total: total,
+		List:  make([]apistructs.AutoTestSceneWebhookDelivery, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		result.List = append(result.List, delivery.Convert())
+	}
+	return result, nil
+}
+
+// notifySceneWebhooks 场景执行结束后为生效的 webhook 创建投递记录, 并在后台立即投递一次, 不阻塞场景执行结果的保存;
+// 失败的由定时任务重试
+func (svc *Service) notifySceneWebhooks(execution *dao.AutoTestSceneExecution, steps []dao.AutoTestSceneExecutionStep) {
+	webhooks, err := svc.db.ListEnabledAutoTestSceneWebhooks(execution.SpaceID, execution.SceneID)
+	if err != nil {
+		logrus.Errorf("failed to list webhooks of autotest scene %d, err: %v", execution.SceneID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(svc.buildSceneWebhookPayload(execution, steps))
+	if err != nil {
+		logrus.Errorf("failed to marshal webhook payload of autotest scene execution %d, err: %v", execution.ID, err)
+		return
+	}
+	// 数据库中的时间精度为秒, 截断后才能作为乐观锁比较
+	now := time.Now().Truncate(time.Second)
+	var deliveries []dao.AutoTestSceneWebhookDelivery
+	for _, webhook := range webhooks {
+		delivery := dao.AutoTestSceneWebhookDelivery{
+			WebhookID:   webhook.ID,
+			ExecutionID: execution.ID,
+			Payload:     string(payload),
+			Status:      apistructs.AutoTestSceneWebhookDeliveryPending,
+			NextRetryAt: &now,
+		}
+		if err := svc.db.CreateAutoTestSceneWebhookDelivery(&delivery); err != nil {
+			logrus.Errorf("failed to create delivery of autotest scene webhook %d, err: %v", webhook.ID, err)
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	go func() {
+		for _, delivery := range deliveries {
+			svc.attemptSceneWebhookDelivery(delivery, now)
+		}
+	}()
+}
+
+// DeliverDueAutoTestSceneWebhooks 重试到达重试时间的 webhook 投递
+func (svc *Service) DeliverDueAutoTestSceneWebhooks() {
+	now := time.Now().Truncate(time.Second)
+	deliveries, err := svc.db.ListDueAutoTestSceneWebhookDeliveries(now, sceneWebhookDeliveryBatchSize)
+	if err != nil {
+		logrus.Errorf("failed to list due autotest scene webhook deliveries, err: %v", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		svc.attemptSceneWebhookDelivery(delivery, now)
+	}
+}
+
+// attemptSceneWebhookDelivery 抢占并投递一次, 根据结果更新投递状态及下次重试时间
+func (svc *Service) attemptSceneWebhookDelivery(delivery dao.AutoTestSceneWebhookDelivery, now time.Time) {
+	if delivery.NextRetryAt == nil {
+		return
+	}
+	claimed, err := svc.db.ClaimAutoTestSceneWebhookDelivery(delivery.ID, *delivery.NextRetryAt, now.Add(sceneWebhookClaimLease))
+	if err != nil {
+		logrus.Errorf("failed to claim autotest scene webhook delivery %d, err: %v", delivery.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	var code int
+	webhook, err := svc.db.GetAutoTestSceneWebhook(delivery.WebhookID)
+	if err == nil && !webhook.Enabled {
+		err = fmt.Errorf("webhook is disabled")
+	}
+	if err == nil {
+		code, err = svc.sendSceneWebhook(webhook, delivery)
+	}
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.Message = ""
+	delivery.NextRetryAt = nil
+	switch {
+	case err == nil:
+		delivery.Status = apistructs.AutoTestSceneWebhookDeliverySuccess
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= sceneWebhookMaxAttempts || (webhook != nil && !webhook.Enabled):
+		delivery.Status = apistructs.AutoTestSceneWebhookDeliveryFailed
+		delivery.Message = truncateString(err.Error(), sceneWebhookMessageMaxLength)
+	default:
+		delivery.Message = truncateString(err.Error(), sceneWebhookMessageMaxLength)
+		nextRetryAt := now.Add(sceneWebhookRetryDelay(delivery.Attempts))
+		delivery.NextRetryAt = &nextRetryAt
+	}
+	if err := svc.db.UpdateAutoTestSceneWebhookDelivery(&delivery); err != nil {
+		logrus.Errorf("failed to update autotest scene webhook delivery %d, err: %v", delivery.ID, err)
+	}
+}
+
+// sendSceneWebhook 签名并投递, 返回接收方的响应状态码, 非 2xx 视为失败
+func (svc *Service) sendSceneWebhook(webhook *dao.AutoTestSceneWebhook, delivery dao.AutoTestSceneWebhookDelivery) (int, error) {
+	decrypted, err := svc.bdl.KMSDecrypt(apistructs.KMSDecryptRequest{
+		DecryptRequest: kmstypes.DecryptRequest{
+			KeyID:            webhook.KMSKeyID,
+			CiphertextBase64: webhook.SecretCiphertext,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt webhook secret, err: %v", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apistructs.AutoTestSceneWebhookTimestampHeader, timestamp)
+	req.Header.Set(apistructs.AutoTestSceneWebhookDeliveryHeader, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(apistructs.AutoTestSceneWebhookSignatureHeader,
+		"sha256="+signSceneWebhookPayload(decrypted.PlaintextBase64, timestamp, []byte(delivery.Payload)))
+	resp, err := sceneWebhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 256))
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d, body: %s", resp.StatusCode, string(body))
+	}
+	return resp.StatusCode, nil
+}
+
+// buildSceneWebhookPayload 汇总场景执行结果, 获取场景名称或链接失败时对应字段留空
+func (svc *Service) buildSceneWebhookPayload(execution *dao.AutoTestSceneExecution, steps []dao.AutoTestSceneExecutionStep) apistructs.AutoTestSceneWebhookPayload {
+	payload := apistructs.AutoTestSceneWebhookPayload{
+		Event:       apistructs.AutoTestSceneWebhookEventExecutionFinished,
+		ExecutionID: execution.ID,
+		SceneID:     execution.SceneID,
+		SpaceID:     execution.SpaceID,
+		PipelineID:  execution.PipelineID,
+		Status:      execution.Status,
+		StepTotal:   len(steps),
+		CostTimeSec: execution.CostTimeSec,
+		TimeBegin:   execution.TimeBegin,
+		TimeEnd:     execution.TimeEnd,
+	}
+	for _, step := range steps {
+		if step.Status.IsSuccessStatus() {
+			payload.StepPassed++
+		} else if step.Status.IsFailedStatus() {
+			payload.StepFailed++
+		}
+	}
+	if scene, err := svc.db.GetAutotestScene(execution.SceneID); err == nil {
+		payload.SceneName = scene.Name
+	}
+	if link, err := svc.sceneExecutionLink(execution); err != nil {
+		logrus.Warnf("failed to generate link of autotest scene execution %d, err: %v", execution.ID, err)
+	} else {
+		payload.Link = link
+	}
+	return payload
+}
+
+// sceneExecutionLink 生成场景执行结果的页面链接
+func (svc *Service) sceneExecutionLink(execution *dao.AutoTestSceneExecution) (string, error) {
+	space, err := svc.db.GetAutoTestSpace(execution.SpaceID)
+	if err != nil {
+		return "", err
+	}
+	project, err := svc.bdl.GetProject(uint64(space.ProjectID))
+	if err != nil {
+		return "", err
+	}
+	org, err := svc.bdl.GetOrg(project.OrgID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/dop/projects/%d/testing/autotest/space/%d/scenes?sceneID=%d&pipelineID=%d",
+		conf.UIPublicURL(), org.Name, project.ID, space.ID, execution.SceneID, execution.PipelineID), nil
+}
+
+// signSceneWebhookPayload 使用 HMAC-SHA256 对 "<timestamp>.<body>" 签名, 返回十六进制签名
+func signSceneWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sceneWebhookRetryDelay 第 attempts 次投递失败后的重试等待时间
+func sceneWebhookRetryDelay(attempts int) time.Duration {
+	return sceneWebhookRetryBackoff << uint(attempts-1)
+}
+
+// checkSceneWebhookURL 校验 webhook 地址, 域名解析到的地址均不能位于内网;
+// 投递时建立连接前会再次校验实际连接的地址
+func checkSceneWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url: %s", rawURL)
+	}
+	ips, err := sceneWebhookLookupIP(u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s, err: %v", u.Hostname(), err)
+	}
+	for _, ip := range ips {
+		if err := checkSceneWebhookIP(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSceneWebhookIP 校验 webhook 访问的地址不是本机、私有网络或链路本地地址
+func checkSceneWebhookIP(ip net.IP) error {
+	if ip == nil {
+		return fmt.Errorf("invalid webhook address")
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("webhook address %s is not allowed", ip)
+	}
+	for _, network := range sceneWebhookBlockedNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("webhook address %s is not allowed", ip)
+		}
+	}
+	return nil
+}
+
+// sceneWebhookDialControl 在建立连接前校验域名解析后实际连接的地址
+func sceneWebhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	return checkSceneWebhookIP(net.ParseIP(host))
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
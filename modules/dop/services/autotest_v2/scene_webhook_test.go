@@ -0,0 +1,126 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+func TestSignSceneWebhookPayload(t *testing.T) {
+	// echo -n '1632900000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "e58169fd23a1699c6210fb83c0ac14b8e177bfeda4296ce1e78239e15b3ed361",
+		signSceneWebhookPayload("secret", "1632900000", []byte(`{"a":1}`)))
+}
+
+func TestSceneWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, sceneWebhookRetryDelay(1))
+	assert.Equal(t, time.Minute, sceneWebhookRetryDelay(2))
+	assert.Equal(t, 8*time.Minute, sceneWebhookRetryDelay(5))
+}
+
+func TestCheckSceneWebhookURL(t *testing.T) {
+	lookup := sceneWebhookLookupIP
+	defer func() { sceneWebhookLookupIP = lookup }()
+	sceneWebhookLookupIP = func(host string) ([]net.IP, error) {
+		switch host {
+		case "ci.example.com":
+			return []net.IP{net.ParseIP("93.184.216.34")}, nil
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("10.0.0.8")}, nil
+		case "metadata.example.com":
+			return []net.IP{net.ParseIP("169.254.169.254")}, nil
+		case "127.0.0.1", "::1", "192.168.1.1":
+			return []net.IP{net.ParseIP(host)}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+
+	assert.NoError(t, checkSceneWebhookURL("https://ci.example.com/hooks/erda"))
+	assert.Error(t, checkSceneWebhookURL("ftp://ci.example.com"))
+	assert.Error(t, checkSceneWebhookURL("/hooks/erda"))
+	assert.Error(t, checkSceneWebhookURL("https://internal.example.com/hooks/erda"))
+	assert.Error(t, checkSceneWebhookURL("http://metadata.example.com/latest"))
+	assert.Error(t, checkSceneWebhookURL("http://127.0.0.1:8080"))
+	assert.Error(t, checkSceneWebhookURL("http://[::1]:8080"))
+	assert.Error(t, checkSceneWebhookURL("http://192.168.1.1"))
+	assert.Error(t, checkSceneWebhookURL("http://unknown.example.com"))
+}
+
+func TestSceneWebhookClientRefusesInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook should not be delivered to loopback address")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, nil)
+	assert.NoError(t, err)
+	_, err = sceneWebhookClient.Do(req)
+	assert.Error(t, err)
+}
+
+func TestSendSceneWebhook(t *testing.T) {
+	bdl := bundle.New()
+	svc := New(WithBundle(bdl))
+	m := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "KMSDecrypt",
+		func(_ *bundle.Bundle, req apistructs.KMSDecryptRequest) (*kmstypes.DecryptResponse, error) {
+			assert.Equal(t, "key", req.KeyID)
+			assert.Equal(t, "ciphertext", req.CiphertextBase64)
+			return &kmstypes.DecryptResponse{PlaintextBase64: "secret"}, nil
+		})
+	defer m.Unpatch()
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, `{"event":"x"}`, string(body))
+		assert.Equal(t, "7", r.Header.Get(apistructs.AutoTestSceneWebhookDeliveryHeader))
+		timestamp := r.Header.Get(apistructs.AutoTestSceneWebhookTimestampHeader)
+		assert.Equal(t, "sha256="+signSceneWebhookPayload("secret", timestamp, body),
+			r.Header.Get(apistructs.AutoTestSceneWebhookSignatureHeader))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	client := sceneWebhookClient
+	defer func() { sceneWebhookClient = client }()
+	sceneWebhookClient = server.Client()
+
+	webhook := &dao.AutoTestSceneWebhook{URL: server.URL, KMSKeyID: "key", SecretCiphertext: "ciphertext"}
+	delivery := dao.AutoTestSceneWebhookDelivery{Payload: `{"event":"x"}`}
+	delivery.ID = 7
+	code, err := svc.sendSceneWebhook(webhook, delivery)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	status = http.StatusBadGateway
+	code, err = svc.sendSceneWebhook(webhook, delivery)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, code)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// sceneSetQueueName 场景集执行使用的流水线队列名, 每个集群一个
+const sceneSetQueueName = "autotest-scene-set"
+
+// sceneSetQueuePageSize 查询场景集队列的数量, 并发创建时同一集群可能短暂存在多个同名队列
+const sceneSetQueuePageSize = 10
+
+// bindSceneSetQueue maxParallel 大于 0 时, 将执行场景集的流水线绑定到所在集群的队列, 超出上限的执行在队列中排队.
+// 单条流水线内场景集及其场景均为一个 stage 一个, 依次执行, 因此同一时刻执行的场景集数量不超过队列并发数.
+// 单独执行场景集和执行测试计划都需要绑定
+func (svc *Service) bindSceneSetQueue(req *apistructs.PipelineCreateRequestV2, maxParallel int64) error {
+	if maxParallel <= 0 {
+		return nil
+	}
+	queue, err := svc.getSceneSetQueue(req.ClusterName, maxParallel, true)
+	if err != nil {
+		return err
+	}
+	labels := make(map[string]string, len(req.Labels)+1)
+	for k, v := range req.Labels {
+		labels[k] = v
+	}
+	labels[apistructs.LabelBindPipelineQueueID] = strconv.FormatUint(queue.ID, 10)
+	req.Labels = labels
+	return nil
+}
+
+// getSceneSetQueue 获取集群中场景集执行的队列, 并发数与配置不一致时更新; 队列不存在且 create 为 false 时返回 nil.
+// 多个实例同时创建时可能产生多个同名队列, 统一使用 ID 最小的队列, 保证所有执行共用同一个队列
+func (svc *Service) getSceneSetQueue(clusterName string, maxParallel int64, create bool) (*apistructs.PipelineQueue, error) {
+	queueReq := apistructs.PipelineQueueCreateRequest{
+		Name:           sceneSetQueueName,
+		PipelineSource: apistructs.PipelineSourceAutoTest,
+		ClusterName:    clusterName,
+		Concurrency:    maxParallel,
+	}
+	queue, err := svc.findSceneSetQueue(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if queue == nil {
+		if !create {
+			return nil, nil
+		}
+		if _, err := svc.bdl.CreatePipelineQueue(queueReq); err != nil {
+			return nil, err
+		}
+		// 创建后重新查询, 与并发创建的实例选择同一个队列
+		if queue, err = svc.findSceneSetQueue(clusterName); err != nil {
+			return nil, err
+		}
+		if queue == nil {
+			return nil, fmt.Errorf("scene set queue of cluster %s not found after creation", clusterName)
+		}
+	}
+	if queue.Concurrency != maxParallel {
+		return svc.bdl.UpdatePipelineQueue(apistructs.PipelineQueueUpdateRequest{
+			ID:                         queue.ID,
+			PipelineQueueCreateRequest: queueReq,
+		})
+	}
+	return queue, nil
+}
+
+// findSceneSetQueue 返回集群中 ID 最小的场景集队列, 不存在时返回 nil
+func (svc *Service) findSceneSetQueue(clusterName string) (*apistructs.PipelineQueue, error) {
+	pages, err := svc.bdl.PagingPipelineQueues(apistructs.PipelineQueuePagingRequest{
+		Name:            sceneSetQueueName,
+		PipelineSources: []apistructs.PipelineSource{apistructs.PipelineSourceAutoTest},
+		ClusterName:     clusterName,
+		PageNo:          1,
+		PageSize:        sceneSetQueuePageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var oldest *apistructs.PipelineQueue
+	for _, queue := range pages.Queues {
+		if oldest == nil || queue.ID < oldest.ID {
+			oldest = queue
+		}
+	}
+	return oldest, nil
+}
+
+// GetSceneSetExecutionStatus 获取场景集所在集群中场景集执行的并发情况
+func (svc *Service) GetSceneSetExecutionStatus(setID uint64) (*apistructs.SceneSetExecutionStatus, error) {
+	sceneSet, err := svc.db.GetSceneSet(setID)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneSet.NotFound()
+	}
+	clusterName, err := svc.GetTestClusterNameBySpaceID(sceneSet.SpaceID)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneSet.InternalError(err)
+	}
+	status := apistructs.SceneSetExecutionStatus{
+		ClusterName: clusterName,
+		MaxParallel: conf.AutotestSceneSetMaxParallel(),
+	}
+	if status.MaxParallel <= 0 {
+		status.MaxParallel = 0
+		return &status, nil
+	}
+	queue, err := svc.getSceneSetQueue(clusterName, status.MaxParallel, false)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneSet.InternalError(err)
+	}
+	if queue == nil {
+		return &status, nil
+	}
+	// 分页查询不返回队列的使用情况
+	queue, err = svc.bdl.GetPipelineQueue(queue.ID)
+	if err != nil {
+		return nil, apierrors.ErrGetAutoTestSceneSet.InternalError(err)
+	}
+	if queue.Usage != nil {
+		status.Running = queue.Usage.ProcessingCount
+		status.Queued = queue.Usage.PendingCount
+	}
+	return &status, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+)
+
+func TestBindSceneSetQueue(t *testing.T) {
+	bdl := bundle.New()
+	svc := New(WithBundle(bdl))
+
+	var (
+		queues  []*apistructs.PipelineQueue
+		created int
+		updated int
+	)
+	m1 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "PagingPipelineQueues",
+		func(_ *bundle.Bundle, req apistructs.PipelineQueuePagingRequest) (*apistructs.PipelineQueuePagingData, error) {
+			assert.Equal(t, sceneSetQueueName, req.Name)
+			assert.Equal(t, "test", req.ClusterName)
+			return &apistructs.PipelineQueuePagingData{Queues: queues, Total: int64(len(queues))}, nil
+		})
+	defer m1.Unpatch()
+	m2 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "CreatePipelineQueue",
+		func(_ *bundle.Bundle, req apistructs.PipelineQueueCreateRequest) (*apistructs.PipelineQueue, error) {
+			created++
+			queue := &apistructs.PipelineQueue{ID: 10, Name: req.Name, ClusterName: req.ClusterName, Concurrency: req.Concurrency}
+			queues = append(queues, queue)
+			return queue, nil
+		})
+	defer m2.Unpatch()
+	m3 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "UpdatePipelineQueue",
+		func(_ *bundle.Bundle, req apistructs.PipelineQueueUpdateRequest) (*apistructs.PipelineQueue, error) {
+			updated++
+			queues[0].Concurrency = req.Concurrency
+			return queues[0], nil
+		})
+	defer m3.Unpatch()
+
+	// 不限制时不绑定队列
+	req := apistructs.PipelineCreateRequestV2{ClusterName: "test", Labels: map[string]string{"a": "b"}}
+	assert.NoError(t, svc.bindSceneSetQueue(&req, 0))
+	assert.Equal(t, map[string]string{"a": "b"}, req.Labels)
+	assert.Equal(t, 0, created)
+
+	assert.NoError(t, svc.bindSceneSetQueue(&req, 2))
+	assert.Equal(t, "10", req.Labels[apistructs.LabelBindPipelineQueueID])
+	assert.Equal(t, "b", req.Labels["a"])
+	assert.Equal(t, 1, created)
+
+	// 并发数变化时更新队列
+	req = apistructs.PipelineCreateRequestV2{ClusterName: "test"}
+	assert.NoError(t, svc.bindSceneSetQueue(&req, 3))
+	assert.Equal(t, "10", req.Labels[apistructs.LabelBindPipelineQueueID])
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, int64(3), queues[0].Concurrency)
+}
+
+func TestGetSceneSetQueueConcurrentCreate(t *testing.T) {
+	bdl := bundle.New()
+	svc := New(WithBundle(bdl))
+
+	var queues []*apistructs.PipelineQueue
+	m1 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "PagingPipelineQueues",
+		func(_ *bundle.Bundle, req apistructs.PipelineQueuePagingRequest) (*apistructs.PipelineQueuePagingData, error) {
+			return &apistructs.PipelineQueuePagingData{Queues: queues, Total: int64(len(queues))}, nil
+		})
+	defer m1.Unpatch()
+	m2 := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "CreatePipelineQueue",
+		func(_ *bundle.Bundle, req apistructs.PipelineQueueCreateRequest) (*apistructs.PipelineQueue, error) {
+			// 其他实例同时创建了队列
+			queues = append(queues,
+				&apistructs.PipelineQueue{ID: 12, Name: req.Name, Concurrency: req.Concurrency},
+				&apistructs.PipelineQueue{ID: 11, Name: req.Name, Concurrency: req.Concurrency})
+			return queues[0], nil
+		})
+	defer m2.Unpatch()
+
+	queue, err := svc.getSceneSetQueue("test", 2, false)
+	assert.NoError(t, err)
+	assert.Nil(t, queue)
+
+	queue, err = svc.getSceneSetQueue("test", 2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(11), queue.ID)
+}
@@ -0,0 +1,414 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/cron"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+// CreateAutoTestSchedule 为场景、场景集或测试计划创建定时执行
+func (svc *Service) CreateAutoTestSchedule(req apistructs.AutoTestScheduleCreateRequest) (*apistructs.AutoTestSchedule, error) {
+	spaceID, err := svc.GetScheduleTargetSpaceID(req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	sceneTags, err := normalizeScheduleSceneTags(req.TargetType, req.SceneTags)
+	if err != nil {
+		return nil, apierrors.ErrCreateAutoTestSchedule.InvalidParameter(err)
+	}
+	schedule := dao.AutoTestSchedule{
+		TargetType:      req.TargetType,
+		TargetID:        req.TargetID,
+		SpaceID:         spaceID,
+		CronExpr:        strings.TrimSpace(req.CronExpr),
+		Enabled:         req.Enabled,
+		ConfigNamespace: req.ConfigManageNamespaces,
+		ClusterName:     req.ClusterName,
+		SceneTags:       sceneTags,
+		CreatorID:       req.UserID,
+		UpdaterID:       req.UserID,
+	}
+	if err := resetScheduleNextFireAt(&schedule, time.Now()); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSchedule.InvalidParameter(err)
+	}
+	if err := svc.db.CreateAutoTestSchedule(&schedule); err != nil {
+		return nil, apierrors.ErrCreateAutoTestSchedule.InternalError(err)
+	}
+	result := schedule.Convert()
+	return &result, nil
+}
+
+// UpdateAutoTestSchedule 更新定时执行, 修改 cron 表达式或重新启用时从当前时间重新计算下次触发时间
+func (svc *Service) UpdateAutoTestSchedule(req apistructs.AutoTestScheduleUpdateRequest) (*apistructs.AutoTestSchedule, error) {
+	schedule, err := svc.GetAutoTestSchedule(req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if req.CronExpr != nil {
+		schedule.CronExpr = strings.TrimSpace(*req.CronExpr)
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if req.ConfigManageNamespaces != nil {
+		schedule.ConfigNamespace = *req.ConfigManageNamespaces
+	}
+	if req.ClusterName != nil {
+		schedule.ClusterName = *req.ClusterName
+	}
+	if req.SceneTags != nil {
+		sceneTags, err := normalizeScheduleSceneTags(schedule.TargetType, req.SceneTags)
+		if err != nil {
+			return nil, apierrors.ErrUpdateAutoTestSchedule.InvalidParameter(err)
+		}
+		schedule.SceneTags = sceneTags
+	}
+	schedule.UpdaterID = req.UserID
+	if err := resetScheduleNextFireAt(schedule, time.Now()); err != nil {
+		return nil, apierrors.ErrUpdateAutoTestSchedule.InvalidParameter(err)
+	}
+	if err := svc.db.UpdateAutoTestSchedule(schedule); err != nil {
+		return nil, apierrors.ErrUpdateAutoTestSchedule.InternalError(err)
+	}
+	result := schedule.Convert()
+	return &result, nil
+}
+
+// DeleteAutoTestSchedule 删除定时执行及其触发记录
+func (svc *Service) DeleteAutoTestSchedule(id uint64) error {
+	if err := svc.db.DeleteAutoTestSchedule(id); err != nil {
+		return apierrors.ErrDeleteAutoTestSchedule.InternalError(err)
+	}
+	return nil
+}
+
+// GetAutoTestSchedule 获取定时执行
+func (svc *Service) GetAutoTestSchedule(id uint64) (*dao.AutoTestSchedule, error) {
+	schedule, err := svc.db.GetAutoTestSchedule(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetAutoTestSchedule.NotFound()
+		}
+		return nil, apierrors.ErrGetAutoTestSchedule.InternalError(err)
+	}
+	return schedule, nil
+}
+
+// ListAutoTestSchedules 查询定时执行列表
+func (svc *Service) ListAutoTestSchedules(req apistructs.AutoTestScheduleListRequest) ([]apistructs.AutoTestSchedule, error) {
+	schedules, err := svc.db.ListAutoTestSchedules(req)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestSchedule.InternalError(err)
+	}
+	results := make([]apistructs.AutoTestSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		results = append(results, schedule.Convert())
+	}
+	return results, nil
+}
+
+// ListAutoTestScheduleRecords 分页查询定时执行的触发记录
+func (svc *Service) ListAutoTestScheduleRecords(req apistructs.AutoTestScheduleRecordListRequest) (*apistructs.AutoTestScheduleRecordPagingData, error) {
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	total, records, err := svc.db.PagingAutoTestScheduleRecords(req)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestScheduleRecord.InternalError(err)
+	}
+	result := &apistructs.AutoTestScheduleRecordPagingData{
+		Total: total,
+		List:  make([]apistructs.AutoTestScheduleRecord, 0, len(records)),
+	}
+	for _, record := range records {
+		result.List = append(result.List, record.Convert())
+	}
+	return result, nil
+}
+
+// ListAutoTestScheduleUpcoming 查询定时执行接下来的触发时间, 已停用的定时执行返回空列表
+func (svc *Service) ListAutoTestScheduleUpcoming(req apistructs.AutoTestScheduleUpcomingRequest) ([]time.Time, error) {
+	schedule, err := svc.GetAutoTestSchedule(req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Count <= 0 {
+		req.Count = 5
+	}
+	if req.Count > 50 {
+		req.Count = 50
+	}
+	if !schedule.Enabled || schedule.NextFireAt == nil {
+		return []time.Time{}, nil
+	}
+	sched, err := parseScheduleCron(schedule.CronExpr)
+	if err != nil {
+		return nil, apierrors.ErrListAutoTestScheduleUpcoming.InternalError(err)
+	}
+	return upcomingFireTimes(sched, *schedule.NextFireAt, req.Count), nil
+}
+
+// FireDueAutoTestSchedules 触发所有到期的定时执行.
+// 每次触发都从当前时间计算下次触发时间, 因此停机期间错过的多次触发只会补偿一次;
+// 错过时间超过阈值的触发不再执行, 仅记录跳过, 避免恢复后大量定时执行同时触发.
+func (svc *Service) FireDueAutoTestSchedules() {
+	now := time.Now()
+	schedules, err := svc.db.ListDueAutoTestSchedules(now)
+	if err != nil {
+		logrus.Errorf("failed to list due autotest schedules, err: %v", err)
+		return
+	}
+	for i := range schedules {
+		svc.fireAutoTestSchedule(&schedules[i], now)
+	}
+}
+
+func (svc *Service) fireAutoTestSchedule(schedule *dao.AutoTestSchedule, now time.Time) {
+	fireAt := *schedule.NextFireAt
+	sched, err := parseScheduleCron(schedule.CronExpr)
+	if err != nil {
+		logrus.Errorf("invalid cron expr of autotest schedule %d, err: %v", schedule.ID, err)
+		return
+	}
+	claimed, err := svc.db.ClaimAutoTestSchedule(schedule.ID, fireAt, now, sched.Next(now))
+	if err != nil {
+		logrus.Errorf("failed to claim autotest schedule %d, err: %v", schedule.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	record := dao.AutoTestScheduleRecord{
+		ScheduleID: schedule.ID,
+		FiredAt:    now,
+	}
+	if misfire := conf.AutotestScheduleMisfireThreshold(); misfire > 0 && now.Sub(fireAt) > misfire {
+		record.Status = apistructs.AutoTestScheduleRecordSkipped
+		record.Message = fmt.Sprintf("missed schedule at %s", fireAt.Format("2006-01-02 15:04:05"))
+	} else if running, err := svc.getRunningSchedulePipeline(schedule); err != nil {
+		record.Status = apistructs.AutoTestScheduleRecordFailed
+		record.Message = err.Error()
+	} else if running != nil {
+		record.Status = apistructs.AutoTestScheduleRecordSkipped
+		record.Message = fmt.Sprintf("previous pipeline %d is still running", running.ID)
+	} else if pipelineDTO, err := svc.executeAutoTestSchedule(schedule); err != nil {
+		record.Status = apistructs.AutoTestScheduleRecordFailed
+		record.Message = err.Error()
+	} else {
+		record.Status = apistructs.AutoTestScheduleRecordSuccess
+		record.PipelineID = pipelineDTO.ID
+	}
+	if err := svc.db.CreateAutoTestScheduleRecord(&record); err != nil {
+		logrus.Errorf("failed to create record of autotest schedule %d, err: %v", schedule.ID, err)
+	}
+}
+
+func (svc *Service) executeAutoTestSchedule(schedule *dao.AutoTestSchedule) (*apistructs.PipelineDTO, error) {
+	identityInfo := apistructs.IdentityInfo{UserID: schedule.UpdaterID}
+	labels := map[string]string{
+		apistructs.LabelAutotestScheduleID: strconv.FormatUint(schedule.ID, 10),
+	}
+	switch schedule.TargetType {
+	case apistructs.AutoTestScheduleTargetScene:
+		var req apistructs.AutotestExecuteSceneRequest
+		req.AutoTestScene.ID = schedule.TargetID
+		req.ClusterName = schedule.ClusterName
+		req.ConfigManageNamespaces = schedule.ConfigNamespace
+		req.Labels = labels
+		req.UserID = schedule.UpdaterID
+		req.IdentityInfo = identityInfo
+		return svc.ExecuteDiceAutotestScene(req)
+	case apistructs.AutoTestScheduleTargetSceneSet:
+		return svc.executeDiceAutotestSceneSet(schedule.TargetID, schedule.ClusterName, schedule.ConfigNamespace, schedule.SceneTags, labels, identityInfo)
+	case apistructs.AutoTestScheduleTargetTestPlan:
+		var req apistructs.AutotestExecuteTestPlansRequest
+		req.TestPlan.ID = schedule.TargetID
+		req.ClusterName = schedule.ClusterName
+		req.ConfigManageNamespaces = schedule.ConfigNamespace
+		req.Labels = labels
+		req.UserID = schedule.UpdaterID
+		req.IdentityInfo = identityInfo
+		return svc.ExecuteDiceAutotestTestPlan(req)
+	default:
+		return nil, fmt.Errorf("invalid target type: %s", schedule.TargetType)
+	}
+}
+
+// getRunningSchedulePipeline 获取测试计划最近一次仍在执行的流水线, 避免同一测试计划的多次执行互相重叠
+func (svc *Service) getRunningSchedulePipeline(schedule *dao.AutoTestSchedule) (*apistructs.PagePipeline, error) {
+	if schedule.TargetType != apistructs.AutoTestScheduleTargetTestPlan {
+		return nil, nil
+	}
+	pages, err := svc.bdl.PageListPipeline(apistructs.PipelinePageListRequest{
+		PageNum:  1,
+		PageSize: 1,
+		Sources:  []apistructs.PipelineSource{apistructs.PipelineSourceAutoTest},
+		YmlNames: []string{apistructs.PipelineSourceAutoTestPlan.String() + "-" + strconv.FormatUint(schedule.TargetID, 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pages.Pipelines {
+		if pages.Pipelines[i].Status.IsReconcilerRunningStatus() {
+			return &pages.Pipelines[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// executeDiceAutotestSceneSet 执行场景集, sceneTags 不为空时只执行带有其中任一标签的场景
+func (svc *Service) executeDiceAutotestSceneSet(setID uint64, clusterName, configNs string, sceneTags []string, labels map[string]string,
+	identityInfo apistructs.IdentityInfo) (*apistructs.PipelineDTO, error) {
+	sceneSet, err := svc.db.GetSceneSet(setID)
+	if err != nil {
+		return nil, err
+	}
+	if configNs, err = svc.effectiveConfigNs(configNs); err != nil {
+		return nil, err
+	}
+	specStage, err := sceneSetSnippetStage(apistructs.TestPlanV2Step{
+		ID:           sceneSet.ID,
+		SceneSetID:   sceneSet.ID,
+		SceneSetName: sceneSet.Name,
+	}, sceneSet.SpaceID, configNs, sceneTags)
+	if err != nil {
+		return nil, err
+	}
+	var spec pipelineyml.Spec
+	spec.Version = "1.1"
+	spec.Stages = []*pipelineyml.Stage{specStage}
+	yml, err := pipelineyml.GenerateYml(&spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var reqPipeline = apistructs.PipelineCreateRequestV2{
+		PipelineYmlName: apistructs.AutotestSceneSet + "-" + strconv.FormatUint(sceneSet.ID, 10),
+		PipelineSource:  apistructs.PipelineSourceAutoTest,
+		AutoRun:         true,
+		ForceRun:        true,
+		ClusterName:     clusterName,
+		PipelineYml:     string(yml),
+		Labels:          labels,
+		IdentityInfo:    identityInfo,
+	}
+	if configNs != "" {
+		reqPipeline.ConfigManageNamespaces = append(reqPipeline.ConfigManageNamespaces, configNs)
+	}
+	if reqPipeline.ClusterName == "" {
+		testClusterName, err := svc.GetTestClusterNameBySpaceID(sceneSet.SpaceID)
+		if err != nil {
+			return nil, err
+		}
+		reqPipeline.ClusterName = testClusterName
+	}
+	if err := svc.bindSceneSetQueue(&reqPipeline, conf.AutotestSceneSetMaxParallel()); err != nil {
+		return nil, err
+	}
+	return svc.bdl.CreatePipeline(&reqPipeline)
+}
+
+// GetScheduleTargetSpaceID 获取定时执行对象所属的测试空间
+func (svc *Service) GetScheduleTargetSpaceID(targetType apistructs.AutoTestScheduleTargetType, targetID uint64) (uint64, error) {
+	switch targetType {
+	case apistructs.AutoTestScheduleTargetScene:
+		scene, err := svc.db.GetAutotestScene(targetID)
+		if err != nil {
+			return 0, apierrors.ErrCreateAutoTestSchedule.InvalidParameter(fmt.Sprintf("scene %d not found", targetID))
+		}
+		return scene.SpaceID, nil
+	case apistructs.AutoTestScheduleTargetSceneSet:
+		sceneSet, err := svc.db.GetSceneSet(targetID)
+		if err != nil {
+			return 0, apierrors.ErrCreateAutoTestSchedule.InvalidParameter(fmt.Sprintf("scene set %d not found", targetID))
+		}
+		return sceneSet.SpaceID, nil
+	case apistructs.AutoTestScheduleTargetTestPlan:
+		testPlan, err := svc.db.GetTestPlanV2ByID(targetID)
+		if err != nil {
+			return 0, apierrors.ErrCreateAutoTestSchedule.InvalidParameter(fmt.Sprintf("test plan %d not found", targetID))
+		}
+		return testPlan.SpaceID, nil
+	default:
+		return 0, apierrors.ErrCreateAutoTestSchedule.InvalidParameter(fmt.Sprintf("invalid target type: %s", targetType))
+	}
+}
+
+// normalizeScheduleSceneTags 校验定时执行的场景标签, 只有场景集可以按标签筛选场景
+func normalizeScheduleSceneTags(targetType apistructs.AutoTestScheduleTargetType, tags []string) ([]string, error) {
+	tags, err := normalizeSceneTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 && targetType != apistructs.AutoTestScheduleTargetSceneSet {
+		return nil, fmt.Errorf("sceneTags is only supported for scene set")
+	}
+	return tags, nil
+}
+
+// resetScheduleNextFireAt 校验 cron 表达式并从 now 计算下次触发时间, 未启用时清空下次触发时间
+func resetScheduleNextFireAt(schedule *dao.AutoTestSchedule, now time.Time) error {
+	sched, err := parseScheduleCron(schedule.CronExpr)
+	if err != nil {
+		return err
+	}
+	schedule.NextFireAt = nil
+	if schedule.Enabled {
+		next := sched.Next(now)
+		schedule.NextFireAt = &next
+	}
+	return nil
+}
+
+// upcomingFireTimes 从 first 开始依次计算 count 个触发时间
+func upcomingFireTimes(sched cron.Schedule, first time.Time, count int) []time.Time {
+	times := make([]time.Time, 0, count)
+	for next := first; len(times) < count && !next.IsZero(); next = sched.Next(next) {
+		times = append(times, next)
+	}
+	return times
+}
+
+// parseScheduleCron 解析 cron 表达式, 与流水线 cron 一致, 支持 5 位标准表达式和带秒、年的 6/7 位表达式
+func parseScheduleCron(expr string) (cron.Schedule, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("missing cron expr")
+	}
+	switch fields := strings.Fields(expr); len(fields) {
+	case 7:
+		return cron.Parse(strings.Join(fields[:len(fields)-1], " "))
+	case 6:
+		return cron.Parse(expr)
+	default:
+		return cron.ParseStandard(expr)
+	}
+}
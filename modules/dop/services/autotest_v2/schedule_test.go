@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestParseScheduleCron(t *testing.T) {
+	now := time.Date(2021, 9, 12, 10, 30, 15, 0, time.Local)
+
+	sched, err := parseScheduleCron("0 2 * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 9, 13, 2, 0, 0, 0, time.Local), sched.Next(now))
+
+	sched, err = parseScheduleCron("0 0 2 * * ?")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 9, 13, 2, 0, 0, 0, time.Local), sched.Next(now))
+
+	sched, err = parseScheduleCron("0 0 2 * * ? *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 9, 13, 2, 0, 0, 0, time.Local), sched.Next(now))
+
+	_, err = parseScheduleCron("")
+	assert.Error(t, err)
+	_, err = parseScheduleCron("61 * * * *")
+	assert.Error(t, err)
+}
+
+func TestResetScheduleNextFireAt(t *testing.T) {
+	now := time.Date(2021, 9, 12, 10, 30, 15, 0, time.Local)
+
+	schedule := dao.AutoTestSchedule{CronExpr: "*/10 * * * *", Enabled: true}
+	assert.NoError(t, resetScheduleNextFireAt(&schedule, now))
+	assert.Equal(t, time.Date(2021, 9, 12, 10, 40, 0, 0, time.Local), *schedule.NextFireAt)
+
+	schedule.Enabled = false
+	assert.NoError(t, resetScheduleNextFireAt(&schedule, now))
+	assert.Nil(t, schedule.NextFireAt)
+
+	schedule.CronExpr = "invalid"
+	assert.Error(t, resetScheduleNextFireAt(&schedule, now))
+}
+
+func TestUpcomingFireTimes(t *testing.T) {
+	sched, err := parseScheduleCron("0 2 * * *")
+	assert.NoError(t, err)
+	first := time.Date(2021, 9, 13, 2, 0, 0, 0, time.Local)
+
+	times := upcomingFireTimes(sched, first, 3)
+	assert.Equal(t, []time.Time{
+		first,
+		time.Date(2021, 9, 14, 2, 0, 0, 0, time.Local),
+		time.Date(2021, 9, 15, 2, 0, 0, 0, time.Local),
+	}, times)
+
+	assert.Empty(t, upcomingFireTimes(sched, first, 0))
+}
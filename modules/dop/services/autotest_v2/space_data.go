@@ -42,6 +42,8 @@ type AutoTestSpaceData struct {
 	sceneSetIDAssociationMap map[uint64]uint64
 	sceneIDAssociationMap    map[uint64]uint64
 	stepIDAssociationMap     map[uint64]uint64
+	// skippedSceneIDs 导入到已有空间时被跳过的场景，不再复制其步骤、入参和出参
+	skippedSceneIDs map[uint64]bool
 
 	Space     *apistructs.AutoTestSpace
 	NewSpace  *apistructs.AutoTestSpace
@@ -553,6 +555,9 @@ func (a *AutoTestSpaceData) CopyInputs() error {
 	var err error
 	for _, scenes := range a.Scenes {
 		for _, scene := range scenes {
+			if a.skippedSceneIDs[scene.ID] {
+				continue
+			}
 			for _, oldInput := range scene.Inputs {
 				oldInput.Value = replaceInputValue(oldInput.Value, a.sceneIDAssociationMap)
 				newInput := &dao.AutoTestSceneInput{
@@ -577,6 +582,9 @@ func (a *AutoTestSpaceData) CopyOutputs() error {
 	var err error
 	for _, scenes := range a.Scenes {
 		for _, scene := range scenes {
+			if a.skippedSceneIDs[scene.ID] {
+				continue
+			}
 			for _, oldOutput := range scene.Output {
 				oldOutput.Value = replacePreStepValue(oldOutput.Value, a.stepIDAssociationMap)
 				newOutput := &dao.AutoTestSceneOutput{
@@ -601,9 +609,13 @@ func (a *AutoTestSpaceData) CopySceneSteps() error {
 	a.stepIDAssociationMap = map[uint64]uint64{}
 
 	for oldSceneID, steps := range a.Steps {
+		if a.skippedSceneIDs[oldSceneID] {
+			continue
+		}
 		var head uint64
 		for _, each := range steps {
 			each.Value = replacePreStepValue(each.Value, a.stepIDAssociationMap)
+			each.Condition = replacePreStepValue(each.Condition, a.stepIDAssociationMap)
 
 			newStep := &dao.AutoTestSceneStep{
 				Type:      each.Type,
@@ -614,6 +626,7 @@ func (a *AutoTestSpaceData) CopySceneSteps() error {
 				SceneID:   a.sceneIDAssociationMap[oldSceneID],
 				SpaceID:   a.NewSpace.ID,
 				APISpecID: each.APISpecID,
+				Condition: each.Condition,
 				CreatorID: a.UserID,
 			}
 			if err = a.svc.db.CreateAutoTestSceneStep(newStep); err != nil {
@@ -625,6 +638,7 @@ func (a *AutoTestSpaceData) CopySceneSteps() error {
 
 			for _, pv := range each.Children {
 				pv.Value = replacePreStepValue(pv.Value, a.stepIDAssociationMap)
+				pv.Condition = replacePreStepValue(pv.Condition, a.stepIDAssociationMap)
 
 				newPStep := &dao.AutoTestSceneStep{
 					Type:      pv.Type,
@@ -635,6 +649,7 @@ func (a *AutoTestSpaceData) CopySceneSteps() error {
 					SceneID:   a.sceneIDAssociationMap[oldSceneID],
 					SpaceID:   a.NewSpace.ID,
 					APISpecID: pv.APISpecID,
+					Condition: pv.Condition,
 					CreatorID: a.UserID,
 				}
 
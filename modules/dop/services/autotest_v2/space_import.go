@@ -0,0 +1,228 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// ImportToSpace 将导入的数据合并到已有的测试空间
+// 同名场景集会被复用，同名场景按 mode 跳过、覆盖或重命名；场景间的引用会重新映射到目标空间中的 ID
+func (a *AutoTestSpaceData) ImportToSpace(spaceID uint64, mode apistructs.AutoTestSpaceImportMode) (*apistructs.AutoTestSpaceImportSummary, error) {
+	var err error
+	if err = a.copyPreCheck(); err != nil {
+		return nil, err
+	}
+
+	target, err := a.svc.GetSpace(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(target.ProjectID) != a.ProjectID {
+		return nil, fmt.Errorf("space %d does not belong to project %d", spaceID, a.ProjectID)
+	}
+	if !target.IsOpen() {
+		return nil, fmt.Errorf("目标测试空间已锁定")
+	}
+
+	// lock target space while importing
+	target.Status = apistructs.TestSpaceLocked
+	a.NewSpace, err = a.svc.UpdateAutoTestSpace(*target, a.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		a.NewSpace.Status = apistructs.TestSpaceOpen
+		if _, err := a.svc.UpdateAutoTestSpace(*a.NewSpace, a.UserID); err != nil {
+			logrus.Error(apierrors.ErrImportAutoTestSpace.InternalError(err))
+		}
+	}()
+
+	summary := newAutoTestSpaceImportSummary(spaceID, mode)
+	if err = a.mergeSceneSets(summary); err != nil {
+		return nil, err
+	}
+	if err = a.mergeScenes(mode, summary); err != nil {
+		return nil, err
+	}
+	if err = a.CopySceneSteps(); err != nil {
+		return nil, err
+	}
+	if err = a.CopyInputs(); err != nil {
+		return nil, err
+	}
+	if err = a.CopyOutputs(); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// NewSpaceImportSummary 导入到新建测试空间时，全部场景集和场景都是新建的
+func (a *AutoTestSpaceData) NewSpaceImportSummary(spaceID uint64) *apistructs.AutoTestSpaceImportSummary {
+	summary := newAutoTestSpaceImportSummary(spaceID, "")
+	for _, sceneSet := range a.SceneSets[a.Space.ID] {
+		summary.SceneSets.Created = append(summary.SceneSets.Created, sceneSet.Name)
+		for _, scene := range a.Scenes[sceneSet.ID] {
+			summary.Scenes.Created = append(summary.Scenes.Created, importSceneDisplayName(sceneSet.Name, scene.Name))
+		}
+	}
+	return summary
+}
+
+// mergeSceneSets 复用目标空间中的同名场景集，其余场景集追加到末尾
+func (a *AutoTestSpaceData) mergeSceneSets(summary *apistructs.AutoTestSpaceImportSummary) error {
+	a.sceneSetIDAssociationMap = map[uint64]uint64{}
+
+	existSets, err := a.svc.db.SceneSetsBySpaceID(a.NewSpace.ID)
+	if err != nil {
+		return err
+	}
+	existSetIDs := make(map[string]uint64, len(existSets))
+	preIDs := make(map[uint64]uint64, len(existSets))
+	for _, set := range existSets {
+		existSetIDs[set.Name] = set.ID
+		preIDs[set.ID] = set.PreID
+	}
+
+	preID := linkedListTail(preIDs)
+	for _, each := range a.SceneSets[a.Space.ID] {
+		if id, ok := existSetIDs[each.Name]; ok {
+			a.sceneSetIDAssociationMap[each.ID] = id
+			summary.SceneSets.Updated = append(summary.SceneSets.Updated, each.Name)
+			continue
+		}
+		newSet := &dao.SceneSet{
+			Name:        each.Name,
+			Description: each.Description,
+			SpaceID:     a.NewSpace.ID,
+			PreID:       preID,
+			CreatorID:   a.UserID,
+		}
+		if err := a.svc.db.CreateSceneSet(newSet); err != nil {
+			return err
+		}
+		a.sceneSetIDAssociationMap[each.ID] = newSet.ID
+		preID = newSet.ID
+		summary.SceneSets.Created = append(summary.SceneSets.Created, each.Name)
+	}
+	return nil
+}
+
+// mergeScenes 按 mode 处理与目标场景集中同名的场景，新场景追加到场景集末尾
+func (a *AutoTestSpaceData) mergeScenes(mode apistructs.AutoTestSpaceImportMode, summary *apistructs.AutoTestSpaceImportSummary) error {
+	a.sceneIDAssociationMap = map[uint64]uint64{}
+	a.skippedSceneIDs = map[uint64]bool{}
+
+	for _, sceneSet := range a.SceneSets[a.Space.ID] {
+		setID := a.sceneSetIDAssociationMap[sceneSet.ID]
+		existScenes, err := a.svc.db.ListAutotestScenes([]uint64{setID})
+		if err != nil {
+			return err
+		}
+		existSceneMap := make(map[string]dao.AutoTestScene, len(existScenes))
+		preIDs := make(map[uint64]uint64, len(existScenes))
+		for _, scene := range existScenes {
+			existSceneMap[scene.Name] = scene
+			preIDs[scene.ID] = scene.PreID
+		}
+
+		preID := linkedListTail(preIDs)
+		for _, each := range a.Scenes[sceneSet.ID] {
+			sceneName := each.Name
+			if exist, ok := existSceneMap[each.Name]; ok {
+				switch mode {
+				case apistructs.AutoTestSpaceImportModeOverwrite:
+					if err = a.svc.db.ClearAutoTestSceneContents(exist.ID); err != nil {
+						return err
+					}
+					exist.Description = each.Description
+					exist.RefSetID = a.sceneSetIDAssociationMap[each.RefSetID]
+					exist.UpdaterID = a.UserID
+					if err = a.svc.db.UpdateAutotestScene(&exist); err != nil {
+						return err
+					}
+					a.sceneIDAssociationMap[each.ID] = exist.ID
+					summary.Scenes.Updated = append(summary.Scenes.Updated, importSceneDisplayName(sceneSet.Name, each.Name))
+					continue
+				case apistructs.AutoTestSpaceImportModeRename:
+					sceneName, err = a.svc.GenerateSceneName(each.Name, setID)
+					if err != nil {
+						return err
+					}
+				default:
+					// 跳过的场景仍然记录映射，其他场景对它的引用指向已有场景
+					a.sceneIDAssociationMap[each.ID] = exist.ID
+					a.skippedSceneIDs[each.ID] = true
+					summary.Scenes.Skipped = append(summary.Scenes.Skipped, importSceneDisplayName(sceneSet.Name, each.Name))
+					continue
+				}
+			}
+
+			newScene := &dao.AutoTestScene{
+				Name:        sceneName,
+				Description: each.Description,
+				SpaceID:     a.NewSpace.ID,
+				SetID:       setID,
+				PreID:       preID,
+				CreatorID:   a.UserID,
+				Status:      apistructs.DefaultSceneStatus,
+				RefSetID:    a.sceneSetIDAssociationMap[each.RefSetID],
+			}
+			if err = a.svc.db.Insert(newScene, preID); err != nil {
+				return err
+			}
+			a.sceneIDAssociationMap[each.ID] = newScene.ID
+			preID = newScene.ID
+			summary.Scenes.Created = append(summary.Scenes.Created, importSceneDisplayName(sceneSet.Name, sceneName))
+		}
+	}
+	return nil
+}
+
+func newAutoTestSpaceImportSummary(spaceID uint64, mode apistructs.AutoTestSpaceImportMode) *apistructs.AutoTestSpaceImportSummary {
+	newResult := func() apistructs.AutoTestSpaceImportResult {
+		return apistructs.AutoTestSpaceImportResult{Created: []string{}, Updated: []string{}, Skipped: []string{}}
+	}
+	return &apistructs.AutoTestSpaceImportSummary{
+		SpaceID:   spaceID,
+		Mode:      mode,
+		SceneSets: newResult(),
+		Scenes:    newResult(),
+	}
+}
+
+func importSceneDisplayName(setName, sceneName string) string {
+	return setName + "/" + sceneName
+}
+
+// linkedListTail 根据 id -> preID 找到链表的最后一个节点，空链表返回 0
+func linkedListTail(preIDs map[uint64]uint64) uint64 {
+	hasNext := make(map[uint64]bool, len(preIDs))
+	for _, preID := range preIDs {
+		hasNext[preID] = true
+	}
+	for id := range preIDs {
+		if !hasNext[id] {
+			return id
+		}
+	}
+	return 0
+}
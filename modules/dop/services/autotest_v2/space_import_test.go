@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotestv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestLinkedListTail(t *testing.T) {
+	assert.Equal(t, uint64(0), linkedListTail(map[uint64]uint64{}))
+	assert.Equal(t, uint64(3), linkedListTail(map[uint64]uint64{1: 0, 2: 1, 3: 2}))
+	assert.Equal(t, uint64(5), linkedListTail(map[uint64]uint64{5: 0}))
+}
+
+func TestNewSpaceImportSummary(t *testing.T) {
+	data := &AutoTestSpaceData{
+		Space:     &apistructs.AutoTestSpace{ID: 1},
+		SceneSets: map[uint64][]apistructs.SceneSet{1: {{ID: 3, Name: "smoke"}, {ID: 4, Name: "regression"}}},
+		Scenes: map[uint64][]apistructs.AutoTestScene{
+			3: {{Name: "login"}, {Name: "order"}},
+			4: {{Name: "login"}},
+		},
+	}
+	summary := data.NewSpaceImportSummary(2)
+	assert.Equal(t, uint64(2), summary.SpaceID)
+	assert.Equal(t, []string{"smoke", "regression"}, summary.SceneSets.Created)
+	assert.Equal(t, []string{"smoke/login", "smoke/order", "regression/login"}, summary.Scenes.Created)
+	assert.Empty(t, summary.Scenes.Skipped)
+	assert.NotNil(t, summary.Scenes.Skipped)
+}
+
+func TestAutoTestSpaceImportModeValid(t *testing.T) {
+	assert.True(t, apistructs.AutoTestSpaceImportModeSkip.Valid())
+	assert.True(t, apistructs.AutoTestSpaceImportModeOverwrite.Valid())
+	assert.True(t, apistructs.AutoTestSpaceImportModeRename.Valid())
+	assert.False(t, apistructs.AutoTestSpaceImportMode("merge").Valid())
+}
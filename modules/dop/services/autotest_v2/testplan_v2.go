@@ -19,8 +19,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
 	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/pkg/expression"
@@ -95,7 +97,12 @@ func (svc *Service) DeleteTestPlanV2(testPlanID uint64, identityInfo apistructs.
 	}
 
 	// Delete test plan member
-	return svc.db.DeleteAutoTestPlanMemberByPlanID(testPlanID)
+	if err := svc.db.DeleteAutoTestPlanMemberByPlanID(testPlanID); err != nil {
+		return err
+	}
+
+	// Delete test plan schedules
+	return svc.db.DeleteAutoTestSchedulesByTargets(apistructs.AutoTestScheduleTargetTestPlan, []uint64{testPlanID})
 }
 
 // UpdateTestPlanV2 update testplan
@@ -416,6 +423,10 @@ func (svc *Service) ExecuteDiceAutotestTestPlan(req apistructs.AutotestExecuteTe
 	if err != nil {
 		return nil, err
 	}
+	configNs, err := svc.effectiveConfigNs(req.ConfigManageNamespaces)
+	if err != nil {
+		return nil, err
+	}
 
 	var spec pipelineyml.Spec
 	spec.Version = "1.1"
@@ -424,32 +435,11 @@ func (svc *Service) ExecuteDiceAutotestTestPlan(req apistructs.AutotestExecuteTe
 		if v.SceneSetID <= 0 {
 			continue
 		}
-		var specStage pipelineyml.Stage
-		sceneSetJson, err := json.Marshal(v)
+		specStage, err := sceneSetSnippetStage(*v, testPlan.SpaceID, configNs, nil)
 		if err != nil {
 			return nil, err
 		}
-		specStage.Actions = append(specStage.Actions, map[pipelineyml.ActionType]*pipelineyml.Action{
-			pipelineyml.Snippet: {
-				Alias: pipelineyml.ActionAlias(strconv.Itoa(int(v.ID))),
-				Type:  pipelineyml.Snippet,
-				Labels: map[string]string{
-					apistructs.AutotestSceneSet: base64.StdEncoding.EncodeToString(sceneSetJson),
-					apistructs.AutotestType:     apistructs.AutotestSceneSet,
-				},
-				If: expression.LeftPlaceholder + " 1 == 1 " + expression.RightPlaceholder,
-				SnippetConfig: &pipelineyml.SnippetConfig{
-					Name:   strconv.Itoa(int(v.SceneSetID)),
-					Source: apistructs.PipelineSourceAutoTest.String(),
-					Labels: map[string]string{
-						apistructs.LabelAutotestExecType: apistructs.SceneSetsAutotestExecType,
-						apistructs.LabelSceneSetID:       strconv.Itoa(int(v.SceneSetID)),
-						apistructs.LabelSpaceID:          strconv.Itoa(int(testPlan.SpaceID)),
-					},
-				},
-			},
-		})
-		stagesValue = append(stagesValue, &specStage)
+		stagesValue = append(stagesValue, specStage)
 	}
 	spec.Stages = stagesValue
 	yml, err := pipelineyml.GenerateYml(&spec)
@@ -467,8 +457,8 @@ func (svc *Service) ExecuteDiceAutotestTestPlan(req apistructs.AutotestExecuteTe
 		Labels:          req.Labels,
 		IdentityInfo:    req.IdentityInfo,
 	}
-	if req.ConfigManageNamespaces != "" {
-		reqPipeline.ConfigManageNamespaces = append(reqPipeline.ConfigManageNamespaces, req.ConfigManageNamespaces)
+	if configNs != "" {
+		reqPipeline.ConfigManageNamespaces = append(reqPipeline.ConfigManageNamespaces, configNs)
 	}
 
 	if reqPipeline.ClusterName == "" {
@@ -478,6 +468,10 @@ func (svc *Service) ExecuteDiceAutotestTestPlan(req apistructs.AutotestExecuteTe
 		}
 		reqPipeline.ClusterName = testClusterName
 	}
+	// 测试计划依次执行其中的场景集, 与单独执行的场景集共用并发限制
+	if err := svc.bindSceneSetQueue(&reqPipeline, conf.AutotestSceneSetMaxParallel()); err != nil {
+		return nil, err
+	}
 
 	pipelineDTO, err := svc.bdl.CreatePipeline(&reqPipeline)
 	if err != nil {
@@ -487,6 +481,42 @@ func (svc *Service) ExecuteDiceAutotestTestPlan(req apistructs.AutotestExecuteTe
 	return pipelineDTO, nil
 }
 
+// sceneSetSnippetStage 生成执行场景集的 snippet stage
+func sceneSetSnippetStage(step apistructs.TestPlanV2Step, spaceID uint64, configNs string, sceneTags []string) (*pipelineyml.Stage, error) {
+	var specStage pipelineyml.Stage
+	sceneSetJson, err := json.Marshal(step)
+	if err != nil {
+		return nil, err
+	}
+	snippetLabels := map[string]string{
+		apistructs.LabelAutotestExecType: apistructs.SceneSetsAutotestExecType,
+		apistructs.LabelSceneSetID:       strconv.Itoa(int(step.SceneSetID)),
+		apistructs.LabelSpaceID:          strconv.Itoa(int(spaceID)),
+		apistructs.LabelConfigNamespace:  configNs,
+	}
+	// 只执行带有指定标签的场景
+	if len(sceneTags) > 0 {
+		snippetLabels[apistructs.LabelSceneSetTags] = strings.Join(sceneTags, ",")
+	}
+	specStage.Actions = append(specStage.Actions, map[pipelineyml.ActionType]*pipelineyml.Action{
+		pipelineyml.Snippet: {
+			Alias: pipelineyml.ActionAlias(strconv.Itoa(int(step.ID))),
+			Type:  pipelineyml.Snippet,
+			Labels: map[string]string{
+				apistructs.AutotestSceneSet: base64.StdEncoding.EncodeToString(sceneSetJson),
+				apistructs.AutotestType:     apistructs.AutotestSceneSet,
+			},
+			If: expression.LeftPlaceholder + " 1 == 1 " + expression.RightPlaceholder,
+			SnippetConfig: &pipelineyml.SnippetConfig{
+				Name:   strconv.Itoa(int(step.SceneSetID)),
+				Source: apistructs.PipelineSourceAutoTest.String(),
+				Labels: snippetLabels,
+			},
+		},
+	})
+	return &specStage, nil
+}
+
 func (svc *Service) GetTestClusterNameBySpaceID(spaceID uint64) (string, error) {
 	space, err := svc.db.GetAutoTestSpace(spaceID)
 	if err != nil {
@@ -562,6 +592,7 @@ func (svc *Service) BatchQuerySceneSetPipelineSnippetYaml(configs []apistructs.S
 		spec.Version = "1.1"
 
 		scenes := sortAutoTestSceneList(resultsScenes, 1, 10000)
+		scenes = filterScenesByTags(scenes, sceneSetTagsFromLabels(configs[index].Labels))
 		spec.Stages = make([]*pipelineyml.Stage, len(scenes))
 		for index, v := range scenes {
 			var specStage pipelineyml.Stage
@@ -599,6 +630,7 @@ func (svc *Service) BatchQuerySceneSetPipelineSnippetYaml(configs []apistructs.S
 								apistructs.LabelSceneSetID:       strconv.Itoa(int(v.RefSetID)),
 								apistructs.LabelSpaceID:          strconv.Itoa(int(v.SpaceID)),
 								apistructs.LabelSceneID:          strconv.Itoa(int(v.ID)),
+								apistructs.LabelConfigNamespace:  configs[index].Labels[apistructs.LabelConfigNamespace],
 							},
 						},
 					},
@@ -621,6 +653,7 @@ func (svc *Service) BatchQuerySceneSetPipelineSnippetYaml(configs []apistructs.S
 								apistructs.LabelAutotestExecType: apistructs.SceneAutotestExecType,
 								apistructs.LabelSceneID:          strconv.Itoa(int(v.ID)),
 								apistructs.LabelSpaceID:          strconv.Itoa(int(v.SpaceID)),
+								apistructs.LabelConfigNamespace:  configs[index].Labels[apistructs.LabelConfigNamespace],
 							},
 						},
 					},
@@ -662,8 +695,11 @@ func (svc *Service) QuerySceneSetPipelineSnippetYaml(req apistructs.SnippetConfi
 		return "", err
 	}
 
+	configNs := req.Labels[apistructs.LabelConfigNamespace]
+
 	var sceneListReq apistructs.AutotestSceneRequest
 	sceneListReq.SetID = uint64(sceneSetIDInt)
+	sceneListReq.Tags = sceneSetTagsFromLabels(req.Labels)
 	_, scenes, err := svc.ListAutotestScene(sceneListReq)
 	if err != nil {
 		return "", err
@@ -709,6 +745,7 @@ func (svc *Service) QuerySceneSetPipelineSnippetYaml(req apistructs.SnippetConfi
 						apistructs.LabelAutotestExecType: apistructs.SceneAutotestExecType,
 						apistructs.LabelSceneID:          strconv.Itoa(int(v.ID)),
 						apistructs.LabelSpaceID:          strconv.Itoa(int(v.SpaceID)),
+						apistructs.LabelConfigNamespace:  configNs,
 					},
 				},
 			},
@@ -755,7 +792,7 @@ func (svc *Service) BatchQueryScenePipelineSnippetYaml(configs []apistructs.Snip
 
 	var resultConfigs []apistructs.BatchSnippetConfigYml
 	for key, v := range results {
-		yml, err := svc.DoSceneToYml(v.Steps, v.Inputs, v.Output)
+		yml, err := svc.DoSceneToYml(applySceneStepTimeout(v.Steps, v.StepTimeoutMs), v.Inputs, v.Output, configsMap[key].Labels[apistructs.LabelConfigNamespace])
 		if err != nil {
 			return nil, err
 		}
@@ -778,7 +815,7 @@ func (svc *Service) QueryScenePipelineSnippetYaml(req apistructs.SnippetConfig)
 		return "", err
 	}
 
-	yml, err := svc.SceneToYml(uint64(sceneSetIDInt))
+	yml, err := svc.SceneToYml(uint64(sceneSetIDInt), req.Labels[apistructs.LabelConfigNamespace])
 	if err != nil {
 		return "", err
 	}
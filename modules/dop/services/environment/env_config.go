@@ -16,6 +16,7 @@ package environment
 
 import (
 	"regexp"
+	"strconv"
 
 	"github.com/pkg/errors"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/model"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/dop/services/permission"
 )
 
@@ -85,7 +87,11 @@ func (e *EnvConfig) Add(createReq *apistructs.EnvConfigAddOrUpdateRequest, names
 
 	configItems := encryptAndParse2Entity(createReq.Configs, ns, encrypt)
 
+	var keyID string
 	for _, config := range configItems {
+		if err := e.encryptConfigItem(&config, nil, false, &keyID); err != nil {
+			return err
+		}
 		err = e.db.UpdateOrAddEnvConfig(&config)
 		if err != nil {
 			return err
@@ -132,7 +138,8 @@ func (e *EnvConfig) Update(permission *permission.Permission, createReq *apistru
 
 	configItems := encryptAndParse2Entity(createReq.Configs, ns, encrypt)
 
-	for _, config := range configItems {
+	var keyID string
+	for i, config := range configItems {
 		configItem, err := e.db.GetEnvConfigByKey(ns.ID, config.ItemKey)
 		if err != nil {
 			return errors.Errorf("failed to get config by namespace id and key, namespaceID: %s, key: %s",
@@ -143,6 +150,9 @@ func (e *EnvConfig) Update(permission *permission.Permission, createReq *apistru
 			config.ID = configItem.ID
 			config.CreatedAt = configItem.CreatedAt
 		}
+		if err := e.encryptConfigItem(&config, configItem, createReq.Configs[i].DisableEncrypt, &keyID); err != nil {
+			return err
+		}
 
 		err = e.db.UpdateOrAddEnvConfig(&config)
 		if err != nil {
@@ -338,10 +348,42 @@ func (e *EnvConfig) GetDeployConfigs(permission *permission.Permission, userID,
 	if err != nil {
 		return nil, err
 	}
+	if err := e.decryptConfigItems(newConfigsItem); err != nil {
+		return nil, err
+	}
 
 	return decryptAndEntitys2Res(newConfigsItem, true), nil
 }
 
+// checkNamespacePermission 校验用户对 namespace 配置的操作权限, 应用级校验应用配置权限, 项目级校验项目权限, 其余默认拒绝
+func checkNamespacePermission(permission *permission.Permission, identityInfo apistructs.IdentityInfo,
+	ns *model.ConfigNamespace, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	switch {
+	case ns.ApplicationID != "":
+		appID, err := strconv.ParseUint(ns.ApplicationID, 10, 64)
+		if err != nil {
+			return errors.Errorf("invalid application id of namespace %s", ns.Name)
+		}
+		return permission.CheckAppConfig(identityInfo, appID, action)
+	case ns.ProjectID != "":
+		projectID, err := strconv.ParseUint(ns.ProjectID, 10, 64)
+		if err != nil {
+			return errors.Errorf("invalid project id of namespace %s", ns.Name)
+		}
+		// 项目级配置读取需要项目查看权限, 修改需要项目编辑权限
+		if action != apistructs.GetAction {
+			action = apistructs.UpdateAction
+		}
+		return permission.CheckProjectAction(identityInfo, projectID, action)
+	default:
+		// 不属于任何项目和应用的 namespace 只允许内部调用
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+}
+
 func filterDeployEnvFormat(configs []model.ConfigItem) ([]model.ConfigItem, error) {
 	var newConfigsItem []model.ConfigItem
 	configMap := make(map[string]struct{}, 0)
@@ -369,10 +411,11 @@ func verifyEnvConfigs(configs []apistructs.EnvConfig) error {
 
 	for i, env := range configs {
 		if env.Key == "" {
-			return errors.Errorf("environment config key error, env: %+v", env)
+			return errors.Errorf("environment config key error, index: %d", i)
 		}
+		// 不打印配置值, 避免敏感配置泄露
 		if env.Value == "" {
-			return errors.Errorf("environment config key error, env: %+v", env)
+			return errors.Errorf("environment config value error, key: %s", env.Key)
 		}
 		if env.ConfigType != "FILE" && env.ConfigType != "ENV" {
 			configs[i].ConfigType = "ENV"
@@ -392,7 +435,7 @@ func encryptAndParse2Entity(configs []apistructs.EnvConfig, namespace *model.Con
 		configItem.ItemKey = config.Key
 		configItem.ItemValue = config.Value
 		configItem.ItemType = config.ConfigType
-		if encrypt {
+		if encrypt || config.Encrypt {
 			configItem.Encrypt = true
 		}
 		configItem.Source = Web
@@ -421,8 +464,11 @@ func decryptAndEntitys2Res(configItems []model.ConfigItem, decrypt bool) []apist
 			ConfigType: config.ItemType,
 		}
 
-		if decrypt || !config.Encrypt {
+		// kms 加密的配置值只在部署时解密, 读取时始终返回掩码
+		if !config.Encrypt || (decrypt && config.KMSKeyID == "") {
 			envConfig.Value = config.ItemValue
+		} else {
+			envConfig.Value = EncryptedValueMask
 		}
 
 		envConfigs = append(envConfigs, envConfig)
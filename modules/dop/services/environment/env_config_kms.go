@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/model"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/dop/services/permission"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// EncryptedValueMask 加密配置在读取时返回的掩码, 更新时传入掩码表示沿用原值
+const EncryptedValueMask = "******"
+
+// EncryptConfigs 将 namespace 下已存在的明文配置标记为敏感配置并使用 kms 加密, 返回本次加密的配置 key
+func (e *EnvConfig) EncryptConfigs(permission *permission.Permission, identityInfo apistructs.IdentityInfo,
+	namespace string, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, apierrors.ErrEncryptEnvConfig.MissingParameter("keys")
+	}
+
+	ns, err := e.db.GetNamespaceByName(namespace)
+	if err != nil {
+		return nil, apierrors.ErrEncryptEnvConfig.InternalError(err)
+	}
+	if ns == nil {
+		return nil, apierrors.ErrEncryptEnvConfig.NotFound()
+	}
+	if err := checkNamespacePermission(permission, identityInfo, ns, apistructs.UpdateAction); err != nil {
+		return nil, err
+	}
+
+	var keyID string
+	encryptedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		configItem, err := e.db.GetEnvConfigByKey(ns.ID, key)
+		if err != nil || configItem == nil {
+			return nil, apierrors.ErrEncryptEnvConfig.InvalidParameter(errors.Errorf("not exist config, key: %s", key))
+		}
+		// 已加密的配置跳过
+		if configItem.KMSKeyID != "" {
+			continue
+		}
+		configItem.Encrypt = true
+		if err := e.encryptConfigItem(configItem, nil, false, &keyID); err != nil {
+			return nil, apierrors.ErrEncryptEnvConfig.InternalError(err)
+		}
+		if err := e.db.UpdateOrAddEnvConfig(configItem); err != nil {
+			return nil, apierrors.ErrEncryptEnvConfig.InternalError(err)
+		}
+		encryptedKeys = append(encryptedKeys, key)
+	}
+
+	return encryptedKeys, nil
+}
+
+// encryptConfigItem 使用 kms 加密敏感配置的值, keyID 为空时获取 namespace 使用的 key 并回填.
+// 已加密的配置只有 disableEncrypt 时才取消加密; 值为掩码时表示未修改, 沿用已加密的原值
+func (e *EnvConfig) encryptConfigItem(config, existing *model.ConfigItem, disableEncrypt bool, keyID *string) error {
+	if existing != nil && existing.Encrypt && !disableEncrypt {
+		config.Encrypt = true
+	}
+	if config.ItemValue == EncryptedValueMask {
+		if existing == nil || !existing.Encrypt {
+			return errors.Errorf("value of encrypted config %s must be re-entered", config.ItemKey)
+		}
+		if !config.Encrypt {
+			return errors.Errorf("value of encrypted config %s must be re-entered when disabling encryption", config.ItemKey)
+		}
+		config.ItemValue = existing.ItemValue
+		config.KMSKeyID = existing.KMSKeyID
+		return nil
+	}
+	if !config.Encrypt {
+		config.KMSKeyID = ""
+		return nil
+	}
+
+	if *keyID == "" {
+		id, err := e.getOrCreateKMSKey(config.NamespaceID)
+		if err != nil {
+			return err
+		}
+		*keyID = id
+	}
+	encrypted, err := e.bdl.KMSEncrypt(apistructs.KMSEncryptRequest{
+		EncryptRequest: kmstypes.EncryptRequest{
+			KeyID:           *keyID,
+			PlaintextBase64: base64.StdEncoding.EncodeToString([]byte(config.ItemValue)),
+		},
+	})
+	if err != nil {
+		return errors.Errorf("failed to encrypt config %s, err: %v", config.ItemKey, err)
+	}
+	config.ItemValue = encrypted.CiphertextBase64
+	config.KMSKeyID = *keyID
+	return nil
+}
+
+// getOrCreateKMSKey 同一 namespace 下的加密配置共用一个 kms key
+func (e *EnvConfig) getOrCreateKMSKey(namespaceID uint64) (string, error) {
+	keyID, err := e.db.GetEnvConfigKMSKeyID(int64(namespaceID))
+	if err != nil {
+		return "", err
+	}
+	if keyID != "" {
+		return keyID, nil
+	}
+	key, err := e.bdl.KMSCreateKey(apistructs.KMSCreateKeyRequest{
+		CreateKeyRequest: kmstypes.CreateKeyRequest{
+			PluginKind: kmstypes.PluginKind_DICE_KMS,
+		},
+	})
+	if err != nil {
+		return "", errors.Errorf("failed to create kms key, err: %v", err)
+	}
+	return key.KeyMetadata.KeyID, nil
+}
+
+// decryptConfigItems 解密 kms 加密的配置值, 仅用于部署时注入, 解密后的值不可打印到日志
+func (e *EnvConfig) decryptConfigItems(configItems []model.ConfigItem) error {
+	for i := range configItems {
+		if configItems[i].KMSKeyID == "" {
+			continue
+		}
+		decrypted, err := e.bdl.KMSDecrypt(apistructs.KMSDecryptRequest{
+			DecryptRequest: kmstypes.DecryptRequest{
+				KeyID:            configItems[i].KMSKeyID,
+				CiphertextBase64: configItems[i].ItemValue,
+			},
+		})
+		if err != nil {
+			return errors.Errorf("failed to decrypt config %s, err: %v", configItems[i].ItemKey, err)
+		}
+		value, err := base64.StdEncoding.DecodeString(decrypted.PlaintextBase64)
+		if err != nil {
+			return errors.Errorf("failed to decode config %s, err: %v", configItems[i].ItemKey, err)
+		}
+		configItems[i].ItemValue = string(value)
+		configItems[i].KMSKeyID = ""
+	}
+	return nil
+}
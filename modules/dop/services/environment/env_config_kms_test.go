@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/model"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+func newKMSTestEnvConfig(t *testing.T) (*EnvConfig, func()) {
+	bdl := bundle.New()
+	encrypt := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "KMSEncrypt",
+		func(_ *bundle.Bundle, req apistructs.KMSEncryptRequest) (*kmstypes.EncryptResponse, error) {
+			assert.Equal(t, "key-1", req.KeyID)
+			return &kmstypes.EncryptResponse{KeyID: req.KeyID, CiphertextBase64: "enc:" + req.PlaintextBase64}, nil
+		})
+	decrypt := monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "KMSDecrypt",
+		func(_ *bundle.Bundle, req apistructs.KMSDecryptRequest) (*kmstypes.DecryptResponse, error) {
+			assert.Equal(t, "key-1", req.KeyID)
+			return &kmstypes.DecryptResponse{PlaintextBase64: req.CiphertextBase64[len("enc:"):]}, nil
+		})
+	return New(WithBundle(bdl)), func() {
+		encrypt.Unpatch()
+		decrypt.Unpatch()
+	}
+}
+
+func TestEncryptConfigItem(t *testing.T) {
+	e, unpatch := newKMSTestEnvConfig(t)
+	defer unpatch()
+	keyID := "key-1"
+	ciphertext := "enc:" + base64.StdEncoding.EncodeToString([]byte("secret"))
+	existing := &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: ciphertext, Encrypt: true, KMSKeyID: keyID}
+
+	// 新增敏感配置
+	config := &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: "secret", Encrypt: true}
+	assert.NoError(t, e.encryptConfigItem(config, nil, false, &keyID))
+	assert.Equal(t, ciphertext, config.ItemValue)
+	assert.Equal(t, keyID, config.KMSKeyID)
+
+	// 未传 encrypt 时已加密的配置保持加密
+	config = &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: "secret"}
+	assert.NoError(t, e.encryptConfigItem(config, existing, false, &keyID))
+	assert.True(t, config.Encrypt)
+	assert.Equal(t, ciphertext, config.ItemValue)
+
+	// 显式取消加密
+	config = &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: "plain"}
+	assert.NoError(t, e.encryptConfigItem(config, existing, true, &keyID))
+	assert.False(t, config.Encrypt)
+	assert.Equal(t, "plain", config.ItemValue)
+	assert.Equal(t, "", config.KMSKeyID)
+
+	// 普通配置不加密
+	config = &model.ConfigItem{ItemKey: "HOST", ItemValue: "localhost"}
+	assert.NoError(t, e.encryptConfigItem(config, &model.ConfigItem{ItemKey: "HOST", ItemValue: "127.0.0.1"}, false, &keyID))
+	assert.Equal(t, "localhost", config.ItemValue)
+}
+
+func TestEncryptConfigItemMask(t *testing.T) {
+	e, unpatch := newKMSTestEnvConfig(t)
+	defer unpatch()
+	keyID := "key-1"
+	existing := &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: "enc:c2VjcmV0", Encrypt: true, KMSKeyID: keyID}
+
+	// 旧版导出再导入时敏感配置的值为掩码, 且不携带 encrypt, 沿用原值
+	config := &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: EncryptedValueMask}
+	assert.NoError(t, e.encryptConfigItem(config, existing, false, &keyID))
+	assert.True(t, config.Encrypt)
+	assert.Equal(t, existing.ItemValue, config.ItemValue)
+	assert.Equal(t, existing.KMSKeyID, config.KMSKeyID)
+
+	// 取消加密时需要重新填写
+	config = &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: EncryptedValueMask}
+	assert.Error(t, e.encryptConfigItem(config, existing, true, &keyID))
+
+	// 目标 namespace 中没有对应的敏感配置时需要重新填写
+	config = &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: EncryptedValueMask, Encrypt: true}
+	assert.Error(t, e.encryptConfigItem(config, nil, false, &keyID))
+	config = &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: EncryptedValueMask}
+	assert.Error(t, e.encryptConfigItem(config, &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: "plain"}, false, &keyID))
+}
+
+func TestDecryptConfigItems(t *testing.T) {
+	e, unpatch := newKMSTestEnvConfig(t)
+	defer unpatch()
+	items := []model.ConfigItem{
+		{ItemKey: "PASSWORD", ItemValue: "enc:" + base64.StdEncoding.EncodeToString([]byte("secret")), Encrypt: true, KMSKeyID: "key-1"},
+		{ItemKey: "HOST", ItemValue: "localhost"},
+	}
+	// 读取时敏感配置返回掩码
+	configs := decryptAndEntitys2Res(items, true)
+	assert.Equal(t, EncryptedValueMask, configs[0].Value)
+	assert.Equal(t, "localhost", configs[1].Value)
+
+	assert.NoError(t, e.decryptConfigItems(items))
+	assert.Equal(t, "secret", items[0].ItemValue)
+	assert.Equal(t, "", items[0].KMSKeyID)
+	assert.Equal(t, "localhost", items[1].ItemValue)
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/model"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/dop/services/permission"
+)
+
+const (
+	// ImportKeyFormat 导入配置 key 的格式
+	ImportKeyFormat = `^[A-Za-z_][A-Za-z0-9_.\-]*$`
+	// ImportKeyMaxLength 配置 key 最大长度, 与 dice_config_item.item_key 一致
+	ImportKeyMaxLength = 128
+)
+
+var importKeyRegexp = regexp.MustCompile(ImportKeyFormat)
+
+// ExportConfigs 导出 namespace 的所有配置, 敏感配置的值以掩码导出
+func (e *EnvConfig) ExportConfigs(permission *permission.Permission, identityInfo apistructs.IdentityInfo,
+	namespace string) (*apistructs.EnvConfigDocument, error) {
+	ns, err := e.db.GetNamespaceByName(namespace)
+	if err != nil {
+		return nil, apierrors.ErrExportEnvConfig.InternalError(err)
+	}
+	if ns == nil {
+		return nil, apierrors.ErrExportEnvConfig.NotFound()
+	}
+	if err := checkNamespacePermission(permission, identityInfo, ns, apistructs.GetAction); err != nil {
+		return nil, err
+	}
+
+	configItems, err := e.db.GetEnvConfigsByNamespaceID(ns.ID)
+	if err != nil {
+		return nil, apierrors.ErrExportEnvConfig.InternalError(err)
+	}
+
+	doc := &apistructs.EnvConfigDocument{
+		Namespace: namespace,
+		Configs:   make([]apistructs.EnvConfigDocumentItem, 0, len(configItems)),
+	}
+	for _, config := range configItems {
+		item := apistructs.EnvConfigDocumentItem{
+			Key:        config.ItemKey,
+			Value:      config.ItemValue,
+			ConfigType: config.ItemType,
+			Comment:    config.ItemComment,
+			Encrypt:    config.Encrypt,
+		}
+		if config.Encrypt {
+			item.Value = EncryptedValueMask
+		}
+		doc.Configs = append(doc.Configs, item)
+	}
+	sort.Slice(doc.Configs, func(i, j int) bool { return doc.Configs[i].Key < doc.Configs[j].Key })
+
+	return doc, nil
+}
+
+// ImportConfigs 将配置文档导入到 namespace, 校验不通过时不做任何修改
+func (e *EnvConfig) ImportConfigs(permission *permission.Permission, identityInfo apistructs.IdentityInfo,
+	namespace string, req *apistructs.EnvConfigImportRequest) (*apistructs.EnvConfigImportResult, error) {
+	if req.Mode == "" {
+		req.Mode = apistructs.EnvConfigImportModeMerge
+	}
+	if req.Mode != apistructs.EnvConfigImportModeMerge && req.Mode != apistructs.EnvConfigImportModeReplace {
+		return nil, apierrors.ErrImportEnvConfig.InvalidParameter(fmt.Sprintf("invalid mode: %s", req.Mode))
+	}
+
+	ns, err := e.db.GetNamespaceByName(namespace)
+	if err != nil {
+		return nil, apierrors.ErrImportEnvConfig.InternalError(err)
+	}
+	if ns == nil {
+		return nil, apierrors.ErrImportEnvConfig.NotFound()
+	}
+	if err := checkNamespacePermission(permission, identityInfo, ns, apistructs.UpdateAction); err != nil {
+		return nil, err
+	}
+
+	existItems, err := e.db.GetEnvConfigsByNamespaceID(ns.ID)
+	if err != nil {
+		return nil, apierrors.ErrImportEnvConfig.InternalError(err)
+	}
+	existing := make(map[string]*model.ConfigItem, len(existItems))
+	for i := range existItems {
+		existing[existItems[i].ItemKey] = &existItems[i]
+	}
+
+	if problems := validateImportConfigs(req.Configs, existing); len(problems) > 0 {
+		apiErr := apierrors.ErrImportEnvConfig.InvalidParameter("invalid configs in document")
+		for _, problem := range problems {
+			apiErr = apiErr.AppendDetail(problem.key, problem.reason)
+		}
+		return nil, apiErr
+	}
+
+	plan := planImportConfigs(req.Mode, req.Configs, existing)
+	// 先完成加密, 再在同一事务中写入和删除, 避免导入中途失败留下部分配置
+	var keyID string
+	saves := make([]*model.ConfigItem, 0, len(plan.saves))
+	for _, config := range plan.saves {
+		configItem := &model.ConfigItem{
+			NamespaceID: uint64(ns.ID),
+			ItemKey:     config.Key,
+			ItemValue:   config.Value,
+			ItemType:    config.ConfigType,
+			ItemComment: config.Comment,
+			Encrypt:     config.Encrypt,
+			Source:      Web,
+			Dynamic:     ns.Dynamic,
+			IsDeleted:   NotDeleteValue,
+		}
+		exist := existing[config.Key]
+		if exist != nil {
+			configItem.ID = exist.ID
+			configItem.CreatedAt = exist.CreatedAt
+		}
+		// 导入文档中显式给出了 encrypt, 以文档为准
+		if err := e.encryptConfigItem(configItem, exist, !config.Encrypt, &keyID); err != nil {
+			return nil, apierrors.ErrImportEnvConfig.InternalError(err)
+		}
+		saves = append(saves, configItem)
+	}
+	deletes := make([]*model.ConfigItem, 0, len(plan.result.Deleted))
+	for _, key := range plan.result.Deleted {
+		deletes = append(deletes, existing[key])
+	}
+	if err := e.db.ImportEnvConfigs(saves, deletes); err != nil {
+		return nil, apierrors.ErrImportEnvConfig.InternalError(err)
+	}
+
+	return plan.result, nil
+}
+
+type importProblem struct {
+	key    string
+	reason string
+}
+
+// validateImportConfigs 校验 key 格式, 重复 key 以及需要重新填写的敏感配置值, 错误信息中不包含配置值
+func validateImportConfigs(configs []apistructs.EnvConfigDocumentItem, existing map[string]*model.ConfigItem) []importProblem {
+	var problems []importProblem
+	seen := make(map[string]struct{}, len(configs))
+	for i := range configs {
+		config := &configs[i]
+		if config.ConfigType == "" {
+			config.ConfigType = "ENV"
+		}
+		switch {
+		case config.Key == "":
+			problems = append(problems, importProblem{fmt.Sprintf("configs[%d]", i), "key is empty"})
+			continue
+		case len(config.Key) > ImportKeyMaxLength:
+			problems = append(problems, importProblem{config.Key, fmt.Sprintf("key is longer than %d", ImportKeyMaxLength)})
+		case !importKeyRegexp.MatchString(config.Key):
+			problems = append(problems, importProblem{config.Key, fmt.Sprintf("key does not match %s", ImportKeyFormat)})
+		}
+		if _, ok := seen[config.Key]; ok {
+			problems = append(problems, importProblem{config.Key, "duplicate key"})
+		}
+		seen[config.Key] = struct{}{}
+		if config.ConfigType != "ENV" && config.ConfigType != "FILE" {
+			problems = append(problems, importProblem{config.Key, fmt.Sprintf("invalid config type: %s", config.ConfigType)})
+		}
+		if config.Value == "" {
+			problems = append(problems, importProblem{config.Key, "value is empty"})
+		}
+		// 导出时被掩码的敏感配置, 只有目标 namespace 已有同名敏感配置时才能沿用, 否则需要重新填写
+		if config.Value == EncryptedValueMask {
+			if exist := existing[config.Key]; exist == nil || !exist.Encrypt || !config.Encrypt {
+				problems = append(problems, importProblem{config.Key, "value of encrypted config must be re-entered"})
+			}
+		}
+	}
+	return problems
+}
+
+type importPlan struct {
+	saves  []apistructs.EnvConfigDocumentItem
+	result *apistructs.EnvConfigImportResult
+}
+
+// planImportConfigs 计算导入需要新增, 更新和删除的配置
+// merge 模式下已存在且不一致的配置不会被覆盖, 作为冲突返回; replace 模式下以文档为准
+func planImportConfigs(mode apistructs.EnvConfigImportMode, configs []apistructs.EnvConfigDocumentItem,
+	existing map[string]*model.ConfigItem) *importPlan {
+	plan := &importPlan{
+		result: &apistructs.EnvConfigImportResult{
+			Mode:      mode,
+			Created:   []string{},
+			Updated:   []string{},
+			Deleted:   []string{},
+			Unchanged: []string{},
+			Conflicts: []apistructs.EnvConfigImportConflict{},
+		},
+	}
+	imported := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		imported[config.Key] = struct{}{}
+		exist, ok := existing[config.Key]
+		if !ok {
+			plan.saves = append(plan.saves, config)
+			plan.result.Created = append(plan.result.Created, config.Key)
+			continue
+		}
+		if reason := importConflictReason(config, exist); reason == "" {
+			plan.result.Unchanged = append(plan.result.Unchanged, config.Key)
+		} else if mode == apistructs.EnvConfigImportModeReplace {
+			plan.saves = append(plan.saves, config)
+			plan.result.Updated = append(plan.result.Updated, config.Key)
+		} else {
+			plan.result.Conflicts = append(plan.result.Conflicts,
+				apistructs.EnvConfigImportConflict{Key: config.Key, Reason: reason})
+		}
+	}
+	if mode == apistructs.EnvConfigImportModeReplace {
+		for key := range existing {
+			if _, ok := imported[key]; !ok {
+				plan.result.Deleted = append(plan.result.Deleted, key)
+			}
+		}
+		sort.Strings(plan.result.Deleted)
+	}
+	return plan
+}
+
+// importConflictReason 返回导入配置与已有配置的差异, 一致时返回空; 不解密比较敏感配置的值
+func importConflictReason(config apistructs.EnvConfigDocumentItem, exist *model.ConfigItem) string {
+	switch {
+	case config.ConfigType != exist.ItemType:
+		return fmt.Sprintf("config type differs: %s -> %s", exist.ItemType, config.ConfigType)
+	case config.Encrypt != exist.Encrypt:
+		return "encrypt flag differs"
+	case config.Encrypt && config.Value != EncryptedValueMask:
+		return "encrypted value is re-entered"
+	case !config.Encrypt && config.Value != exist.ItemValue:
+		return "value differs"
+	case config.Comment != exist.ItemComment:
+		return "comment differs"
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package environment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/model"
+)
+
+func TestValidateImportConfigs(t *testing.T) {
+	existing := map[string]*model.ConfigItem{
+		"PASSWORD": {ItemKey: "PASSWORD", ItemValue: "ciphertext", Encrypt: true, KMSKeyID: "key"},
+		"HOST":     {ItemKey: "HOST", ItemValue: "localhost", ItemType: "ENV"},
+	}
+	tests := []struct {
+		name    string
+		configs []apistructs.EnvConfigDocumentItem
+		keys    []string
+	}{
+		{
+			name: "valid",
+			configs: []apistructs.EnvConfigDocumentItem{
+				{Key: "HOST", Value: "127.0.0.1"},
+				{Key: "app.name", Value: "demo", ConfigType: "FILE"},
+				{Key: "PASSWORD", Value: EncryptedValueMask, Encrypt: true},
+			},
+		},
+		{
+			name: "invalid key",
+			configs: []apistructs.EnvConfigDocumentItem{
+				{Key: "", Value: "v"},
+				{Key: "1HOST", Value: "v"},
+				{Key: strings.Repeat("A", ImportKeyMaxLength+1), Value: "v"},
+			},
+			keys: []string{"configs[0]", "1HOST", strings.Repeat("A", ImportKeyMaxLength+1)},
+		},
+		{
+			name: "duplicate key and invalid type",
+			configs: []apistructs.EnvConfigDocumentItem{
+				{Key: "HOST", Value: "a"},
+				{Key: "HOST", Value: "b", ConfigType: "YAML"},
+			},
+			keys: []string{"HOST", "HOST"},
+		},
+		{
+			name: "masked value must be re-entered",
+			configs: []apistructs.EnvConfigDocumentItem{
+				{Key: "TOKEN", Value: EncryptedValueMask, Encrypt: true},
+				{Key: "HOST", Value: EncryptedValueMask, Encrypt: true},
+				{Key: "PASSWORD", Value: EncryptedValueMask},
+				{Key: "EMPTY", Value: ""},
+			},
+			keys: []string{"TOKEN", "HOST", "PASSWORD", "EMPTY"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, problem := range validateImportConfigs(tt.configs, existing) {
+				keys = append(keys, problem.key)
+			}
+			assert.Equal(t, tt.keys, keys)
+		})
+	}
+}
+
+func TestPlanImportConfigs(t *testing.T) {
+	existing := map[string]*model.ConfigItem{
+		"PASSWORD": {ItemKey: "PASSWORD", ItemValue: "ciphertext", ItemType: "ENV", Encrypt: true, KMSKeyID: "key"},
+		"HOST":     {ItemKey: "HOST", ItemValue: "localhost", ItemType: "ENV"},
+		"PORT":     {ItemKey: "PORT", ItemValue: "8080", ItemType: "ENV"},
+		"OBSOLETE": {ItemKey: "OBSOLETE", ItemValue: "1", ItemType: "ENV"},
+	}
+	configs := []apistructs.EnvConfigDocumentItem{
+		{Key: "PASSWORD", Value: EncryptedValueMask, ConfigType: "ENV", Encrypt: true},
+		{Key: "HOST", Value: "localhost", ConfigType: "ENV"},
+		{Key: "PORT", Value: "9090", ConfigType: "ENV"},
+		{Key: "NEW", Value: "v", ConfigType: "ENV"},
+	}
+
+	plan := planImportConfigs(apistructs.EnvConfigImportModeMerge, configs, existing)
+	assert.Equal(t, []string{"NEW"}, plan.result.Created)
+	assert.Equal(t, []string{}, plan.result.Updated)
+	assert.Equal(t, []string{}, plan.result.Deleted)
+	assert.Equal(t, []string{"PASSWORD", "HOST"}, plan.result.Unchanged)
+	assert.Equal(t, []apistructs.EnvConfigImportConflict{{Key: "PORT", Reason: "value differs"}}, plan.result.Conflicts)
+	assert.Len(t, plan.saves, 1)
+
+	plan = planImportConfigs(apistructs.EnvConfigImportModeReplace, configs, existing)
+	assert.Equal(t, []string{"NEW"}, plan.result.Created)
+	assert.Equal(t, []string{"PORT"}, plan.result.Updated)
+	assert.Equal(t, []string{"OBSOLETE"}, plan.result.Deleted)
+	assert.Equal(t, []apistructs.EnvConfigImportConflict{}, plan.result.Conflicts)
+	var saved []string
+	for _, config := range plan.saves {
+		saved = append(saved, config.Key)
+	}
+	assert.Equal(t, []string{"PORT", "NEW"}, saved)
+}
+
+func TestImportConflictReason(t *testing.T) {
+	exist := &model.ConfigItem{ItemKey: "PASSWORD", ItemValue: "ciphertext", ItemType: "ENV", Encrypt: true}
+	assert.Equal(t, "", importConflictReason(apistructs.EnvConfigDocumentItem{Key: "PASSWORD", Value: EncryptedValueMask, ConfigType: "ENV", Encrypt: true}, exist))
+	assert.Equal(t, "encrypted value is re-entered", importConflictReason(apistructs.EnvConfigDocumentItem{Key: "PASSWORD", Value: "new", ConfigType: "ENV", Encrypt: true}, exist))
+	assert.Equal(t, "encrypt flag differs", importConflictReason(apistructs.EnvConfigDocumentItem{Key: "PASSWORD", Value: "new", ConfigType: "ENV"}, exist))
+	assert.Equal(t, "config type differs: ENV -> FILE", importConflictReason(apistructs.EnvConfigDocumentItem{Key: "PASSWORD", ConfigType: "FILE"}, exist))
+}
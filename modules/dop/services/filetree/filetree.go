@@ -41,6 +41,7 @@ const gittarEntryBlobType = "blob"
 type GittarFileTree struct {
 	bdl           *bundle.Bundle
 	branchRuleSve *branchrule.BranchRule
+	pipelineSvc   *pipeline.Pipeline
 }
 
 // Option Pipeline 配置选项
@@ -68,6 +69,13 @@ func WithBranchRule(svc *branchrule.BranchRule) Option {
 	}
 }
 
+// WithPipelineSvc 配置 pipeline service, 移动流水线文件时用于在新路径上重建定时任务
+func WithPipelineSvc(svc *pipeline.Pipeline) Option {
+	return func(f *GittarFileTree) {
+		f.pipelineSvc = svc
+	}
+}
+
 func (svc *GittarFileTree) ListFileTreeNodes(req apistructs.UnifiedFileTreeNodeListRequest, orgID uint64) ([]*apistructs.UnifiedFileTreeNode, error) {
 	// 参数校验
 	if err := req.BasicValidate(); err != nil {
@@ -402,6 +410,8 @@ func (svc *GittarFileTree) FuzzySearchFileTreeNodes(req apistructs.UnifiedFileTr
 	projectName := apps.List[0].ProjectName
 
 	var results []apistructs.UnifiedFileTreeNode
+	// 搜索范围，为空则不过滤
+	var scope *gittarNode
 	// 异步设置值
 	if req.FromPinode == "" {
 		results, err = svc.searchGittarYmlList(apps.List, orgID, projectName, "", "", projectID)
@@ -416,40 +426,59 @@ func (svc *GittarFileTree) FuzzySearchFileTreeNodes(req apistructs.UnifiedFileTr
 
 		pathSplit := strings.Split(string(inodeBytes), "/")
 		length := len(pathSplit)
+		if length < 2 {
+			return nil, apierrors.ErrFuzzySearchGittarFileTreeNodes.InvalidParameter("fromPinode")
+		}
 
-		var filterAppName string
+		// 第二个为appId的字符串, 获取其名称
+		appID, err := strconv.ParseUint(pathSplit[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid appID: %s, err: %v", pathSplit[1], err)
+		}
+		app, err := svc.bdl.GetApp(appID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get app, appID: %d, err: %v", appID, err)
+		}
+
+		// 长度大于 3 时 fromPinode 为分支或分支下的目录，只搜索该子树
 		var filterBranch string
-		if length == 2 {
-			// 第二个为appId的字符串, 获取其名称
-			appID, err := strconv.ParseUint(pathSplit[1], 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid appID: %s, err: %v", pathSplit[1], err)
-			}
-			app, err := svc.bdl.GetApp(appID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get app, appID: %d, err: %v", appID, err)
+		if length > 3 {
+			if scope, err = parseGittarNode(req.FromPinode); err != nil {
+				return nil, apierrors.ErrFuzzySearchGittarFileTreeNodes.InvalidParameter(err)
 			}
-			filterAppName = app.Name
-		} else if length >= 3 {
-			filterBranch = getBranchStr(string(inodeBytes))
+			filterBranch = scope.branch
 		}
 
-		results, err = svc.searchGittarYmlList(apps.List, orgID, projectName, filterAppName, filterBranch, projectID)
+		results, err = svc.searchGittarYmlList(apps.List, orgID, projectName, app.Name, filterBranch, projectID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// 过滤查询
-	var filterResult []apistructs.UnifiedFileTreeNode
+	// 过滤查询，并按匹配程度排序
+	var filterResult []fuzzyResult
 	for _, v := range results {
-		if !strings.Contains(v.Name, req.Fuzzy) {
+		node, err := parseGittarNode(v.Inode)
+		if err != nil {
+			continue
+		}
+		if scope != nil && scope.path != "" && !strings.HasPrefix(node.path, scope.path+"/") {
+			continue
+		}
+		level, pos, matches, ok := matchFuzzy(node.path, req.Fuzzy)
+		if !ok {
 			continue
 		}
-		filterResult = append(filterResult, v)
+		filterResult = append(filterResult, fuzzyResult{
+			node:    v,
+			path:    node.path,
+			level:   level,
+			pos:     pos,
+			matches: matches,
+		})
 	}
 
-	return filterResult, nil
+	return rankFuzzyResults(filterResult, getFuzzySearchLimit(req.Limit)), nil
 }
 
 func (svc *GittarFileTree) CreateFileTreeNode(req apistructs.UnifiedFileTreeNodeCreateRequest, orgID uint64) (*apistructs.UnifiedFileTreeNode, error) {
@@ -0,0 +1,142 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetree
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// maxFuzzySearchLimit 指定 limit 时允许的最大返回数量
+const maxFuzzySearchLimit = 500
+
+// 匹配等级，越小越靠前
+const (
+	fuzzyLevelNamePrefix    = iota // 文件名前缀匹配
+	fuzzyLevelNameSubstring        // 文件名包含
+	fuzzyLevelPathPrefix           // 路径中某一级目录前缀匹配
+	fuzzyLevelPathSubstring        // 路径包含
+)
+
+type fuzzyResult struct {
+	node    apistructs.UnifiedFileTreeNode
+	path    string
+	level   int
+	pos     int
+	matches []apistructs.UnifiedFileTreeNodeFuzzyMatch
+}
+
+// matchFuzzy 对分支下的相对路径做忽略大小写的模糊匹配，返回匹配等级、命中位置及命中的路径片段
+func matchFuzzy(nodePath, keyword string) (level, pos int, matches []apistructs.UnifiedFileTreeNodeFuzzyMatch, ok bool) {
+	lowerPath, lowerKeyword := strings.ToLower(nodePath), strings.ToLower(keyword)
+	if lowerKeyword == "" {
+		return 0, 0, nil, false
+	}
+
+	// 优先匹配文件名
+	nameStart := strings.LastIndex(lowerPath, "/") + 1
+	if idx := strings.Index(lowerPath[nameStart:], lowerKeyword); idx >= 0 {
+		level = fuzzyLevelNameSubstring
+		if idx == 0 {
+			level = fuzzyLevelNamePrefix
+		}
+		pos = nameStart + idx
+		return level, pos, splitFuzzyMatch(nodePath, pos, pos+len(keyword)), true
+	}
+
+	// 其次匹配路径，优先取命中某一级目录开头的位置
+	pos = strings.Index(lowerPath, lowerKeyword)
+	if pos < 0 {
+		return 0, 0, nil, false
+	}
+	level = fuzzyLevelPathSubstring
+	for idx := pos; idx >= 0; {
+		if idx == 0 || lowerPath[idx-1] == '/' {
+			level, pos = fuzzyLevelPathPrefix, idx
+			break
+		}
+		next := strings.Index(lowerPath[idx+1:], lowerKeyword)
+		if next < 0 {
+			break
+		}
+		idx += next + 1
+	}
+	return level, pos, splitFuzzyMatch(nodePath, pos, pos+len(keyword)), true
+}
+
+// splitFuzzyMatch 将路径上的命中区间 [start, end) 拆分到各级路径片段上
+func splitFuzzyMatch(nodePath string, start, end int) []apistructs.UnifiedFileTreeNodeFuzzyMatch {
+	var matches []apistructs.UnifiedFileTreeNodeFuzzyMatch
+	offset := 0
+	for i, segment := range strings.Split(nodePath, "/") {
+		segStart, segEnd := offset, offset+len(segment)
+		offset = segEnd + 1
+		if start >= segEnd || end <= segStart {
+			continue
+		}
+		match := apistructs.UnifiedFileTreeNodeFuzzyMatch{Index: i, Segment: segment, Start: 0, End: len(segment)}
+		if start > segStart {
+			match.Start = start - segStart
+		}
+		if end < segEnd {
+			match.End = end - segStart
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// rankFuzzyResults 按匹配等级、命中位置、路径长度排序，limit 大于 0 时截取前 limit 个
+func rankFuzzyResults(results []fuzzyResult, limit int) []apistructs.UnifiedFileTreeNode {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].level != results[j].level {
+			return results[i].level < results[j].level
+		}
+		if results[i].pos != results[j].pos {
+			return results[i].pos < results[j].pos
+		}
+		if len(results[i].path) != len(results[j].path) {
+			return len(results[i].path) < len(results[j].path)
+		}
+		return results[i].node.Inode < results[j].node.Inode
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	nodes := make([]apistructs.UnifiedFileTreeNode, 0, len(results))
+	for _, r := range results {
+		node := r.node
+		if node.Meta == nil {
+			node.Meta = map[string]interface{}{}
+		}
+		node.Meta[apistructs.UnifiedFileTreeNodeMetaKeyFuzzyMatches] = r.matches
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// getFuzzySearchLimit 未指定 limit 时保持原有行为，返回全部结果
+func getFuzzySearchLimit(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	if limit > maxFuzzySearchLimit {
+		return maxFuzzySearchLimit
+	}
+	return limit
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestMatchFuzzy(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		keyword   string
+		wantOK    bool
+		wantLevel int
+		wantPos   int
+		wantMatch []apistructs.UnifiedFileTreeNodeFuzzyMatch
+	}{
+		{
+			name:      "name prefix ignore case",
+			path:      ".dice/pipelines/Deploy.yml",
+			keyword:   "dep",
+			wantOK:    true,
+			wantLevel: fuzzyLevelNamePrefix,
+			wantPos:   16,
+			wantMatch: []apistructs.UnifiedFileTreeNodeFuzzyMatch{{Index: 2, Segment: "Deploy.yml", Start: 0, End: 3}},
+		},
+		{
+			name:      "name substring",
+			path:      ".dice/pipelines/deploy.yml",
+			keyword:   "ploy",
+			wantOK:    true,
+			wantLevel: fuzzyLevelNameSubstring,
+			wantPos:   18,
+			wantMatch: []apistructs.UnifiedFileTreeNodeFuzzyMatch{{Index: 2, Segment: "deploy.yml", Start: 2, End: 6}},
+		},
+		{
+			name:      "path prefix prefers segment start",
+			path:      ".dice/pipelines/ci/pipe.yml",
+			keyword:   "ci/",
+			wantOK:    true,
+			wantLevel: fuzzyLevelPathPrefix,
+			wantPos:   16,
+			wantMatch: []apistructs.UnifiedFileTreeNodeFuzzyMatch{{Index: 2, Segment: "ci", Start: 0, End: 2}},
+		},
+		{
+			name:      "path substring across segments",
+			path:      ".dice/pipelines/a.yml",
+			keyword:   "ce/pi",
+			wantOK:    true,
+			wantLevel: fuzzyLevelPathSubstring,
+			wantPos:   3,
+			wantMatch: []apistructs.UnifiedFileTreeNodeFuzzyMatch{
+				{Index: 0, Segment: ".dice", Start: 3, End: 5},
+				{Index: 1, Segment: "pipelines", Start: 0, End: 2},
+			},
+		},
+		{
+			name:    "not matched",
+			path:    ".dice/pipelines/a.yml",
+			keyword: "deploy",
+		},
+		{
+			name:    "empty keyword",
+			path:    ".dice/pipelines/a.yml",
+			keyword: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, pos, matches, ok := matchFuzzy(tt.path, tt.keyword)
+			assert.Equal(t, tt.wantOK, ok)
+			if !tt.wantOK {
+				return
+			}
+			assert.Equal(t, tt.wantLevel, level)
+			assert.Equal(t, tt.wantPos, pos)
+			assert.Equal(t, tt.wantMatch, matches)
+		})
+	}
+}
+
+func TestSplitFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		start, end int
+		want       []apistructs.UnifiedFileTreeNodeFuzzyMatch
+	}{
+		{
+			name:  "inside one segment",
+			path:  "a/bcd/e.yml",
+			start: 3, end: 5,
+			want: []apistructs.UnifiedFileTreeNodeFuzzyMatch{{Index: 1, Segment: "bcd", Start: 1, End: 3}},
+		},
+		{
+			name:  "across three segments",
+			path:  "ab/cd/ef",
+			start: 1, end: 7,
+			want: []apistructs.UnifiedFileTreeNodeFuzzyMatch{
+				{Index: 0, Segment: "ab", Start: 1, End: 2},
+				{Index: 1, Segment: "cd", Start: 0, End: 2},
+				{Index: 2, Segment: "ef", Start: 0, End: 1},
+			},
+		},
+		{
+			name:  "only separator",
+			path:  "ab/cd",
+			start: 2, end: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitFuzzyMatch(tt.path, tt.start, tt.end))
+		})
+	}
+}
+
+func TestRankFuzzyResults(t *testing.T) {
+	result := func(inode, path string, level, pos int) fuzzyResult {
+		return fuzzyResult{node: apistructs.UnifiedFileTreeNode{Inode: inode}, path: path, level: level, pos: pos}
+	}
+	results := []fuzzyResult{
+		result("5", ".dice/pipelines/xx/a.yml", fuzzyLevelPathSubstring, 1),
+		result("4", ".dice/pipelines/deploy-b.yml", fuzzyLevelNamePrefix, 16),
+		result("3", ".dice/pipelines/deploy.yml", fuzzyLevelNamePrefix, 16),
+		result("2", ".dice/pipelines/ci/deploy.yml", fuzzyLevelNamePrefix, 19),
+		result("1", ".dice/pipelines/my-deploy.yml", fuzzyLevelNameSubstring, 19),
+		result("0", "pipeline.yml", fuzzyLevelNamePrefix, 16),
+	}
+	inodes := func(nodes []apistructs.UnifiedFileTreeNode) []string {
+		var ret []string
+		for _, n := range nodes {
+			ret = append(ret, n.Inode)
+			assert.Contains(t, n.Meta, apistructs.UnifiedFileTreeNodeMetaKeyFuzzyMatches)
+		}
+		return ret
+	}
+
+	assert.Equal(t, []string{"0", "3", "4", "2", "1", "5"}, inodes(rankFuzzyResults(append([]fuzzyResult{}, results...), 0)))
+	assert.Equal(t, []string{"0", "3"}, inodes(rankFuzzyResults(append([]fuzzyResult{}, results...), 2)))
+}
+
+func TestGetFuzzySearchLimit(t *testing.T) {
+	assert.Equal(t, 0, getFuzzySearchLimit(0))
+	assert.Equal(t, 0, getFuzzySearchLimit(-1))
+	assert.Equal(t, 10, getFuzzySearchLimit(10))
+	assert.Equal(t, maxFuzzySearchLimit, getFuzzySearchLimit(maxFuzzySearchLimit+1))
+}
@@ -0,0 +1,440 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetree
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/pkg/diceworkspace"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+)
+
+const gittarPipelinesDir = ".dice/pipelines"
+
+// gittarNode 解析后的应用目录树节点
+type gittarNode struct {
+	projectID string
+	appID     string
+	branch    string
+	// path 为分支下的相对路径，分支节点为空
+	path string
+}
+
+// parseGittarNode 解析 base64 编码的 inode: projectID/appID/tree/branch/path
+func parseGittarNode(inode string) (*gittarNode, error) {
+	inodeBytes, err := base64.URLEncoding.DecodeString(inode)
+	if err != nil {
+		return nil, err
+	}
+	decoded := string(inodeBytes)
+	pathSplit := strings.Split(decoded, "/")
+
+	// 因为分支是 feature/sss/sss 这种模式根据 / 分割就会有问题
+	branchExcessLength := getBranchExcessLength(decoded)
+	if len(pathSplit) < 4+branchExcessLength {
+		return nil, fmt.Errorf("wrong format inode error: %s is not under any branch", decoded)
+	}
+	if pathSplit[2] != gittarEntryTreeType && pathSplit[2] != gittarEntryBlobType {
+		return nil, fmt.Errorf("wrong format inode error: %s", decoded)
+	}
+	return &gittarNode{
+		projectID: pathSplit[0],
+		appID:     pathSplit[1],
+		branch:    strings.Join(pathSplit[3:4+branchExcessLength], "/"),
+		path:      strings.Join(pathSplit[4+branchExcessLength:], "/"),
+	}, nil
+}
+
+func (n *gittarNode) isFile() bool {
+	return strings.HasSuffix(n.path, ".yml")
+}
+
+func (n *gittarNode) sameTree(o *gittarNode) bool {
+	return n.appID == o.appID && n.branch == o.branch
+}
+
+func (n *gittarNode) inode(nodePath string) string {
+	return base64.URLEncoding.EncodeToString([]byte(fmt.Sprintf("%s/%s/%s/%s/%s", n.projectID, n.appID, gittarEntryTreeType, n.branch, nodePath)))
+}
+
+// isUnderPipelinesDir .dice/pipelines 及其子目录
+func isUnderPipelinesDir(p string) bool {
+	return p == gittarPipelinesDir || strings.HasPrefix(p, gittarPipelinesDir+"/")
+}
+
+// validateMoveTarget 校验移动的源节点和目标父节点，返回移动后的路径
+func validateMoveTarget(src, dst *gittarNode) (string, error) {
+	if src.path == "" || src.path == ".dice" || src.path == gittarPipelinesDir {
+		return "", fmt.Errorf("node %q can not be moved", src.path)
+	}
+	if src.projectID != dst.projectID {
+		return "", fmt.Errorf("can not move node across projects")
+	}
+	if dst.isFile() {
+		return "", fmt.Errorf("target node must be a directory")
+	}
+	// 同一棵目录树下，不能移动到自身或自己的子孙节点下
+	if src.sameTree(dst) {
+		if dst.path == src.path || strings.HasPrefix(dst.path, src.path+"/") {
+			return "", fmt.Errorf("can not move node under itself or its descendant")
+		}
+		if dst.path == path.Dir(src.path) || (dst.path == "" && !strings.Contains(src.path, "/")) {
+			return "", fmt.Errorf("node is already under the target directory")
+		}
+	}
+
+	name := path.Base(src.path)
+	if src.isFile() {
+		// 分支根目录下只能有 pipeline.yml，其余 .yml 只能在 .dice/pipelines 下
+		if dst.path == "" {
+			if name != "pipeline.yml" {
+				return "", fmt.Errorf("only 'pipeline.yml' can be moved under the branch")
+			}
+			return name, nil
+		}
+		if !isUnderPipelinesDir(dst.path) {
+			return "", fmt.Errorf(".yml files can only be moved into the .dice/pipelines folder")
+		}
+		return dst.path + "/" + name, nil
+	}
+	if !isUnderPipelinesDir(dst.path) {
+		return "", fmt.Errorf("folders can only be moved into the .dice/pipelines folder")
+	}
+	return dst.path + "/" + name, nil
+}
+
+// MoveFileTreeNode 移动应用目录树节点，支持跨应用、跨分支移动
+func (svc *GittarFileTree) MoveFileTreeNode(req apistructs.UnifiedFileTreeNodeMoveRequest, orgID uint64) (*apistructs.UnifiedFileTreeNode, error) {
+	// 参数校验
+	if err := req.BasicValidate(); err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InvalidParameter(err)
+	}
+	src, err := parseGittarNode(req.Inode)
+	if err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InvalidParameter(err)
+	}
+	dst, err := parseGittarNode(req.Pinode)
+	if err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InvalidParameter(err)
+	}
+	newPath, err := validateMoveTarget(src, dst)
+	if err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InvalidParameter(err)
+	}
+
+	srcApp, err := svc.getGittarApp(src.appID)
+	if err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+	}
+	dstApp := srcApp
+	if dst.appID != src.appID {
+		if dstApp, err = svc.getGittarApp(dst.appID); err != nil {
+			return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+		}
+	}
+
+	// 源目录树和目标目录树都需要有写权限
+	if err := svc.checkPushPermission(apierrors.ErrMoveGittarFileTreeNode, req.IdentityInfo, srcApp, src.branch); err != nil {
+		return nil, err
+	}
+	if !src.sameTree(dst) {
+		if err := svc.checkPushPermission(apierrors.ErrMoveGittarFileTreeNode, req.IdentityInfo, dstApp, dst.branch); err != nil {
+			return nil, err
+		}
+	}
+
+	// 目标位置已存在同名节点时拒绝移动, 避免覆盖已有文件
+	orgIDStr := strconv.FormatUint(orgID, 10)
+	exist, err := svc.gittarNodeExists(dstApp, dst.branch, newPath, orgIDStr)
+	if err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+	}
+	if exist {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.AlreadyExists()
+	}
+
+	// 收集待移动的文件及内容
+	files, err := svc.listGittarFiles(srcApp, src.branch, src.path, src.isFile(), orgIDStr)
+	if err != nil {
+		return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+	}
+	var addActions []apistructs.EditActionItem
+	for _, f := range files {
+		addActions = append(addActions, apistructs.EditActionItem{
+			Action:   "add",
+			PathType: gittarEntryBlobType,
+			Path:     newPath + strings.TrimPrefix(f.path, src.path),
+			Content:  f.content,
+		})
+	}
+	// 空目录直接在目标位置创建目录
+	if len(addActions) == 0 {
+		addActions = append(addActions, apistructs.EditActionItem{
+			Action:   "add",
+			PathType: gittarEntryTreeType,
+			Path:     newPath,
+		})
+	}
+	deleteAction := apistructs.EditActionItem{
+		Action:   "delete",
+		PathType: gittarEntryTreeType,
+		Path:     src.path,
+	}
+	if src.isFile() {
+		deleteAction.PathType = gittarEntryBlobType
+	}
+
+	srcRepo := fmt.Sprintf("wb/%s/%s", srcApp.ProjectName, srcApp.Name)
+	dstRepo := fmt.Sprintf("wb/%s/%s", dstApp.ProjectName, dstApp.Name)
+	srcDesc := fmt.Sprintf("%s/%s/%s", srcApp.Name, src.branch, src.path)
+	dstDesc := fmt.Sprintf("%s/%s/%s", dstApp.Name, dst.branch, newPath)
+	if src.sameTree(dst) {
+		// 同一分支内的移动放在一次提交中，git 会识别为重命名，保留文件历史
+		if err := svc.commitGittar(srcRepo, apistructs.GittarCreateCommitRequest{
+			Branch:  src.branch,
+			Message: fmt.Sprintf("move %s to %s", src.path, newPath),
+			Actions: append(addActions, deleteAction),
+		}, orgID); err != nil {
+			return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+		}
+	} else {
+		// 跨目录树移动，在两边的提交信息中记录来源和去向，便于追溯历史
+		if err := svc.commitGittar(dstRepo, apistructs.GittarCreateCommitRequest{
+			Branch:  dst.branch,
+			Message: fmt.Sprintf("move %s from %s", newPath, srcDesc),
+			Actions: addActions,
+		}, orgID); err != nil {
+			return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+		}
+		if err := svc.commitGittar(srcRepo, apistructs.GittarCreateCommitRequest{
+			Branch:  src.branch,
+			Message: fmt.Sprintf("move %s to %s", src.path, dstDesc),
+			Actions: []apistructs.EditActionItem{deleteAction},
+		}, orgID); err != nil {
+			// 回滚目标目录树中本次添加的节点，避免出现两份; 目标位置移动前不存在，不会删除已有文件
+			var rollbackActions []apistructs.EditActionItem
+			for _, action := range addActions {
+				rollbackActions = append(rollbackActions, apistructs.EditActionItem{Action: "delete", PathType: action.PathType, Path: action.Path})
+			}
+			if rbErr := svc.commitGittar(dstRepo, apistructs.GittarCreateCommitRequest{
+				Branch:  dst.branch,
+				Message: fmt.Sprintf("revert move %s from %s", newPath, srcDesc),
+				Actions: rollbackActions,
+			}, orgID); rbErr != nil {
+				logrus.Errorf("failed to rollback moved filetree node %s, err: %v", dstDesc, rbErr)
+			}
+			return nil, apierrors.ErrMoveGittarFileTreeNode.InternalError(err)
+		}
+	}
+
+	// 原路径对应的流水线定时任务已失效，在新路径上重建后停止掉
+	moved := make(map[string]gittarFile, len(files))
+	for _, f := range files {
+		moved[f.path] = gittarFile{path: newPath + strings.TrimPrefix(f.path, src.path), content: f.content}
+	}
+	svc.movePipelineCrons(src, dst, dstApp, moved, req.UserID)
+
+	if src.isFile() {
+		return svc.GetFileTreeNode(apistructs.UnifiedFileTreeNodeGetRequest{
+			Inode:        dst.inode(newPath),
+			ScopeID:      dst.projectID,
+			IdentityInfo: req.IdentityInfo,
+		}, orgID)
+	}
+	return &apistructs.UnifiedFileTreeNode{
+		Type:      apistructs.UnifiedFileTreeNodeTypeDir,
+		Inode:     dst.inode(newPath),
+		Pinode:    req.Pinode,
+		Name:      path.Base(newPath),
+		ScopeID:   dst.projectID,
+		UpdatedAt: time.Now(),
+		UpdaterID: req.UserID,
+	}, nil
+}
+
+func (svc *GittarFileTree) getGittarApp(appIDStr string) (*apistructs.ApplicationDTO, error) {
+	appID, err := strconv.ParseUint(appIDStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appID: %s, err: %v", appIDStr, err)
+	}
+	app, err := svc.bdl.GetApp(appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app, appID: %d, err: %v", appID, err)
+	}
+	return app, nil
+}
+
+// checkPushPermission 校验用户是否有推送代码到应用对应分支的权限，与 gittar 推送时的校验保持一致
+func (svc *GittarFileTree) checkPushPermission(apiErr *errorresp.APIError, identityInfo apistructs.IdentityInfo, app *apistructs.ApplicationDTO, branch string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	rules, err := svc.branchRuleSve.Query(apistructs.ProjectScope, int64(app.ProjectID))
+	if err != nil {
+		return apiErr.InternalError(err)
+	}
+	action := "PUSH"
+	if validBranch := diceworkspace.GetValidBranchByGitReference(branch, rules); validBranch.IsProtect {
+		action = "PUSH_PROTECT_BRANCH"
+	}
+	checkResp, err := svc.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   identityInfo.UserID,
+		Scope:    apistructs.AppScope,
+		ScopeID:  app.ID,
+		Resource: "repo",
+		Action:   action,
+	})
+	if err != nil {
+		return apiErr.InternalError(err)
+	}
+	if !checkResp.Access {
+		return apiErr.AccessDenied()
+	}
+	return nil
+}
+
+type gittarFile struct {
+	path    string
+	content string
+}
+
+// gittarNodeExists 分支下 nodePath 是否已存在
+func (svc *GittarFileTree) gittarNodeExists(app *apistructs.ApplicationDTO, branch, nodePath, orgID string) (bool, error) {
+	repo := gittarPrefixOpenApi + app.ProjectName + "/" + app.Name
+	dir := path.Dir(nodePath)
+	if dir == "." {
+		dir = ""
+	}
+	entries, err := svc.bdl.GetGittarTreeNode(strings.TrimSuffix(repo+"/"+gittarEntryTreeType+"/"+branch+"/"+dir, "/"), orgID, false)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Name == path.Base(nodePath) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listGittarFiles 递归获取节点下所有文件及内容
+func (svc *GittarFileTree) listGittarFiles(app *apistructs.ApplicationDTO, branch, nodePath string, isFile bool, orgID string) ([]gittarFile, error) {
+	repo := gittarPrefixOpenApi + app.ProjectName + "/" + app.Name
+	if isFile {
+		content, err := svc.bdl.GetGittarBlobNode(repo+"/"+gittarEntryBlobType+"/"+branch+"/"+nodePath, orgID)
+		if err != nil {
+			return nil, err
+		}
+		return []gittarFile{{path: nodePath, content: content}}, nil
+	}
+
+	entries, err := svc.bdl.GetGittarTreeNode(repo+"/"+gittarEntryTreeType+"/"+branch+"/"+nodePath, orgID, false)
+	if err != nil {
+		return nil, err
+	}
+	var files []gittarFile
+	for _, entry := range entries {
+		childPath := nodePath + "/" + entry.Name
+		children, err := svc.listGittarFiles(app, branch, childPath, entry.Type != gittarEntryTreeType, orgID)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, children...)
+	}
+	return files, nil
+}
+
+func (svc *GittarFileTree) commitGittar(repo string, req apistructs.GittarCreateCommitRequest, orgID uint64) error {
+	resp, err := svc.bdl.CreateGittarCommitV2(repo, req, int(orgID))
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("commit to %s error: %v", repo, resp.Error)
+	}
+	return nil
+}
+
+// movePipelineCrons 将原路径上开启的流水线定时任务迁移到移动后的路径: 在新路径上创建并启动定时任务, 再停止原定时任务.
+// moved 为原路径到移动后文件的映射, 新路径上无法创建时仍停止原定时任务, 避免继续执行已不存在的流水线
+func (svc *GittarFileTree) movePipelineCrons(src, dst *gittarNode, dstApp *apistructs.ApplicationDTO, moved map[string]gittarFile, userID string) {
+	if len(moved) == 0 {
+		return
+	}
+	workspace, err := svc.GetWorkspaceByBranch(src.projectID, src.branch)
+	if err != nil {
+		logrus.Errorf("failed to get workspace of branch %s, err: %v", src.branch, err)
+		return
+	}
+	ymlNames := make([]string, 0, len(moved))
+	ymlPaths := make(map[string]string, len(moved))
+	for p := range moved {
+		ymlName := getGittarYmlNamesLabels(src.appID, workspace, src.branch, p)
+		ymlNames = append(ymlNames, ymlName)
+		ymlPaths[ymlName] = p
+	}
+	crons, err := svc.bdl.PageListPipelineCrons(apistructs.PipelineCronPagingRequest{
+		Sources:  []apistructs.PipelineSource{apistructs.PipelineSourceDice},
+		YmlNames: ymlNames,
+		PageSize: len(ymlNames),
+		PageNo:   1,
+	})
+	if err != nil {
+		logrus.Errorf("failed to list pipeline crons of %v, err: %v", ymlNames, err)
+		return
+	}
+	for _, cron := range crons.Data {
+		if cron.Enable == nil || !*cron.Enable {
+			continue
+		}
+		if target, ok := moved[ymlPaths[cron.PipelineYmlName]]; ok {
+			if err := svc.createMovedPipelineCron(dst, dstApp, target, userID); err != nil {
+				logrus.Errorf("failed to create pipeline cron of moved yml %s, err: %v", target.path, err)
+			}
+		}
+		if _, err := svc.bdl.StopPipelineCron(cron.ID); err != nil {
+			logrus.Errorf("failed to stop pipeline cron %d of moved yml %s, err: %v", cron.ID, cron.PipelineYmlName, err)
+		}
+	}
+}
+
+// createMovedPipelineCron 在移动后的路径上创建并启动定时任务
+func (svc *GittarFileTree) createMovedPipelineCron(dst *gittarNode, dstApp *apistructs.ApplicationDTO, target gittarFile, userID string) error {
+	if svc.pipelineSvc == nil {
+		return fmt.Errorf("pipeline service is not configured")
+	}
+	createReq, err := svc.pipelineSvc.ConvertPipelineToV2(&apistructs.PipelineCreateRequest{
+		AppID:              dstApp.ID,
+		Branch:             dst.branch,
+		PipelineYmlName:    target.path,
+		PipelineYmlContent: target.content,
+		UserID:             userID,
+	})
+	if err != nil {
+		return err
+	}
+	createReq.AutoStartCron = true
+	_, err = svc.bdl.CreatePipelineCron(apistructs.PipelineCronCreateRequest{PipelineCreateRequest: *createReq})
+	return err
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMoveTarget(t *testing.T) {
+	node := func(appID, branch, p string) *gittarNode {
+		return &gittarNode{projectID: "1", appID: appID, branch: branch, path: p}
+	}
+	tests := []struct {
+		name    string
+		src     *gittarNode
+		dst     *gittarNode
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "move yml into sub folder",
+			src:  node("1", "master", ".dice/pipelines/a.yml"),
+			dst:  node("1", "master", ".dice/pipelines/ci"),
+			want: ".dice/pipelines/ci/a.yml",
+		},
+		{
+			name: "move folder across branches",
+			src:  node("1", "master", ".dice/pipelines/ci"),
+			dst:  node("1", "develop", ".dice/pipelines"),
+			want: ".dice/pipelines/ci",
+		},
+		{
+			name: "move pipeline.yml to branch root across apps",
+			src:  node("1", "master", ".dice/pipelines/pipeline.yml"),
+			dst:  node("2", "master", ""),
+			want: "pipeline.yml",
+		},
+		{
+			name:    "other yml can not be moved to branch root",
+			src:     node("1", "master", ".dice/pipelines/a.yml"),
+			dst:     node("1", "develop", ""),
+			wantErr: true,
+		},
+		{
+			name:    "protected nodes can not be moved",
+			src:     node("1", "master", gittarPipelinesDir),
+			dst:     node("1", "develop", ".dice"),
+			wantErr: true,
+		},
+		{
+			name:    "target must be a directory",
+			src:     node("1", "master", ".dice/pipelines/a.yml"),
+			dst:     node("1", "master", ".dice/pipelines/b.yml"),
+			wantErr: true,
+		},
+		{
+			name:    "can not move under itself",
+			src:     node("1", "master", ".dice/pipelines/ci"),
+			dst:     node("1", "master", ".dice/pipelines/ci/sub"),
+			wantErr: true,
+		},
+		{
+			name:    "already under target",
+			src:     node("1", "master", ".dice/pipelines/a.yml"),
+			dst:     node("1", "master", ".dice/pipelines"),
+			wantErr: true,
+		},
+		{
+			name:    "folders only under pipelines dir",
+			src:     node("1", "master", ".dice/pipelines/ci"),
+			dst:     node("1", "master", ".dice"),
+			wantErr: true,
+		},
+		{
+			name:    "across projects",
+			src:     node("1", "master", ".dice/pipelines/a.yml"),
+			dst:     &gittarNode{projectID: "2", appID: "3", branch: "master", path: gittarPipelinesDir},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateMoveTarget(tt.src, tt.dst)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetree
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const snippetActionType = "snippet"
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// SaveFileTreeNodePipeline 保存应用流水线文件，保存前校验 yml 结构及引用的 snippet 是否可用
+func (svc *GittarFileTree) SaveFileTreeNodePipeline(req apistructs.UnifiedFileTreeNodeSavePipelineRequest, orgID uint64) (*apistructs.UnifiedFileTreeNode, error) {
+	// 参数校验
+	if err := req.BasicValidate(); err != nil {
+		return nil, apierrors.ErrSaveGittarFileTreeNodePipeline.InvalidParameter(err)
+	}
+	node, err := parseGittarNode(req.Inode)
+	if err != nil {
+		return nil, apierrors.ErrSaveGittarFileTreeNodePipeline.InvalidParameter(err)
+	}
+	if !node.isFile() {
+		return nil, apierrors.ErrSaveGittarFileTreeNodePipeline.InvalidParameter("only '.yml' file can be saved")
+	}
+	app, err := svc.getGittarApp(node.appID)
+	if err != nil {
+		return nil, apierrors.ErrSaveGittarFileTreeNodePipeline.InternalError(err)
+	}
+	if err := svc.checkPushPermission(apierrors.ErrSaveGittarFileTreeNodePipeline, req.IdentityInfo, app, node.branch); err != nil {
+		return nil, err
+	}
+
+	// 校验不通过时拒绝保存，草稿可通过 force 强制保存
+	validationErrors := svc.validatePipelineYml(req.PipelineYml, app, orgID, req.UserID)
+	if len(validationErrors) > 0 && !req.Force {
+		msg := validationErrors[0].Message
+		if line := validationErrors[0].Line; line > 0 {
+			msg = fmt.Sprintf("line %d: %s", line, msg)
+		}
+		return nil, apierrors.ErrSaveGittarFileTreeNodePipeline.
+			InvalidParameter(fmt.Errorf("invalid pipelineYml: %s", msg)).
+			SetCtx(validationErrors)
+	}
+
+	if err := svc.commitGittar(fmt.Sprintf("wb/%s/%s", app.ProjectName, app.Name), apistructs.GittarCreateCommitRequest{
+		Branch:  node.branch,
+		Message: "update " + node.path,
+		Actions: []apistructs.EditActionItem{
+			{
+				Action:   "update",
+				PathType: gittarEntryBlobType,
+				Path:     node.path,
+				Content:  req.PipelineYml,
+			},
+		},
+	}, orgID); err != nil {
+		return nil, apierrors.ErrSaveGittarFileTreeNodePipeline.InternalError(err)
+	}
+
+	result, err := svc.GetFileTreeNode(apistructs.UnifiedFileTreeNodeGetRequest{
+		Inode:        req.Inode,
+		ScopeID:      node.projectID,
+		IdentityInfo: req.IdentityInfo,
+	}, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(validationErrors) > 0 {
+		if result.Meta == nil {
+			result.Meta = map[string]interface{}{}
+		}
+		result.Meta[apistructs.UnifiedFileTreeNodeMetaKeyValidationErrors] = validationErrors
+	}
+	return result, nil
+}
+
+// validatePipelineYml 校验 stage/task 结构和 snippet 引用，结构正确时再做完整解析
+func (svc *GittarFileTree) validatePipelineYml(content string, app *apistructs.ApplicationDTO, orgID uint64, userID string) []apistructs.PipelineYmlValidationError {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return []apistructs.PipelineYmlValidationError{newYmlErrorFromMessage(err.Error())}
+	}
+	if len(root.Content) == 0 {
+		return []apistructs.PipelineYmlValidationError{{Line: 1, Message: "pipeline yml is empty"}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []apistructs.PipelineYmlValidationError{newYmlError(doc, "", "pipeline yml must be a mapping")}
+	}
+
+	var errs []apistructs.PipelineYmlValidationError
+	if _, stages := lookupYamlKey(doc, "stages"); stages != nil && !isYamlNull(stages) {
+		errs = append(errs, svc.validateStages(stages, app, orgID, userID)...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if _, err := pipelineyml.New([]byte(content)); err != nil {
+		return []apistructs.PipelineYmlValidationError{newYmlErrorFromMessage(err.Error())}
+	}
+	return nil
+}
+
+func (svc *GittarFileTree) validateStages(stages *yaml.Node, app *apistructs.ApplicationDTO, orgID uint64, userID string) []apistructs.PipelineYmlValidationError {
+	if stages.Kind != yaml.SequenceNode {
+		return []apistructs.PipelineYmlValidationError{newYmlError(stages, "stages", "stages must be a list")}
+	}
+
+	var errs []apistructs.PipelineYmlValidationError
+	for i, stage := range stages.Content {
+		stagePath := fmt.Sprintf("stages[%d]", i)
+		if stage.Kind != yaml.MappingNode {
+			errs = append(errs, newYmlError(stage, stagePath, "stage must be a mapping with key 'stage'"))
+			continue
+		}
+		_, actions := lookupYamlKey(stage, "stage")
+		if actions == nil {
+			errs = append(errs, newYmlError(stage, stagePath, "missing key 'stage'"))
+			continue
+		}
+		if actions.Kind != yaml.SequenceNode {
+			errs = append(errs, newYmlError(actions, stagePath+".stage", "stage must be a list of tasks"))
+			continue
+		}
+		for j, action := range actions.Content {
+			actionPath := fmt.Sprintf("%s.stage[%d]", stagePath, j)
+			if action.Kind != yaml.MappingNode || len(action.Content) != 2 {
+				errs = append(errs, newYmlError(action, actionPath, "task must be a mapping with exactly one action type"))
+				continue
+			}
+			actionType, spec := action.Content[0], action.Content[1]
+			if actionType.Value == "" {
+				errs = append(errs, newYmlError(actionType, actionPath, "action type is empty"))
+				continue
+			}
+			actionPath += "." + actionType.Value
+			if !isYamlNull(spec) && spec.Kind != yaml.MappingNode {
+				errs = append(errs, newYmlError(spec, actionPath, "task spec must be a mapping"))
+				continue
+			}
+			if actionType.Value == snippetActionType {
+				if err := svc.validateSnippetAction(spec, actionPath, app, orgID, userID); err != nil {
+					errs = append(errs, *err)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateSnippetAction 校验 snippet 引用的流水线是否存在，目前只校验应用下的 local snippet
+func (svc *GittarFileTree) validateSnippetAction(spec *yaml.Node, actionPath string, app *apistructs.ApplicationDTO, orgID uint64, userID string) *apistructs.PipelineYmlValidationError {
+	if isYamlNull(spec) {
+		e := newYmlError(spec, actionPath, "missing snippet_config")
+		return &e
+	}
+	configKey, configNode := lookupYamlKey(spec, "snippet_config")
+	if configNode == nil {
+		e := newYmlError(spec, actionPath, "missing snippet_config")
+		return &e
+	}
+	configPath := actionPath + ".snippet_config"
+	var config apistructs.SnippetConfig
+	if err := configNode.Decode(&config); err != nil {
+		e := newYmlError(configNode, configPath, fmt.Sprintf("invalid snippet_config: %v", err))
+		return &e
+	}
+	if config.Source != apistructs.SnippetSourceLocal {
+		return nil
+	}
+	if err := svc.resolveLocalSnippet(config, app, orgID, userID); err != nil {
+		e := newYmlError(configKey, configPath, fmt.Sprintf("snippet %s can not be resolved: %v", config.Name, err))
+		return &e
+	}
+	return nil
+}
+
+// resolveLocalSnippet 根据 snippet 标签定位引用的流水线文件
+// gittarYmlPath 标签格式为 appName/workspace/branch/ymlPath
+func (svc *GittarFileTree) resolveLocalSnippet(config apistructs.SnippetConfig, app *apistructs.ApplicationDTO, orgID uint64, userID string) error {
+	ymlPath := strings.TrimPrefix(config.Labels[apistructs.LabelGittarYmlPath], "/")
+	if ymlPath == "" {
+		return apierrors.ErrGetSnippetYaml.InvalidParameter(fmt.Errorf("labels key %v value is empty", apistructs.LabelGittarYmlPath))
+	}
+	name := strings.TrimPrefix(config.Name, "/")
+	parts := strings.SplitN(ymlPath, "/", 3)
+	if name == "" || len(parts) < 3 || !strings.HasSuffix(parts[2], "/"+name) {
+		return apierrors.ErrGetSnippetYaml.InvalidParameter(fmt.Errorf("labels key %v value %s does not match snippet name", apistructs.LabelGittarYmlPath, ymlPath))
+	}
+	appName, branch := parts[0], strings.TrimSuffix(parts[2], "/"+name)
+
+	snippetApp := app
+	if appName != app.Name {
+		apps, err := svc.bdl.GetAppsByProjectAndAppName(app.ProjectID, orgID, userID, appName)
+		if err != nil {
+			return apierrors.ErrGetSnippetYaml.InternalError(err)
+		}
+		snippetApp = nil
+		for i := range apps.List {
+			if apps.List[i].Name == appName {
+				snippetApp = &apps.List[i]
+				break
+			}
+		}
+		if snippetApp == nil {
+			return apierrors.ErrGetSnippetYaml.InvalidParameter(fmt.Errorf("not find app: %s", appName))
+		}
+	}
+
+	repo := gittarPrefixOpenApi + snippetApp.ProjectName + "/" + snippetApp.Name + "/" + gittarEntryBlobType + "/" + branch + "/" + name
+	if _, err := svc.bdl.GetGittarBlobNode(repo, strconv.FormatUint(orgID, 10)); err != nil {
+		return apierrors.ErrGetSnippetYaml.InvalidParameter(err)
+	}
+	return nil
+}
+
+func lookupYamlKey(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func isYamlNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+func newYmlError(n *yaml.Node, path, msg string) apistructs.PipelineYmlValidationError {
+	return apistructs.PipelineYmlValidationError{Line: n.Line, Column: n.Column, Path: path, Message: msg}
+}
+
+// newYmlErrorFromMessage 从解析错误信息中提取行号，无法提取时行号为 0
+func newYmlErrorFromMessage(msg string) apistructs.PipelineYmlValidationError {
+	e := apistructs.PipelineYmlValidationError{Message: msg}
+	if matches := yamlErrorLineRegexp.FindStringSubmatch(msg); len(matches) == 2 {
+		e.Line, _ = strconv.Atoi(matches[1])
+	}
+	return e
+}
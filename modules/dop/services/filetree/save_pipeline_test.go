@@ -0,0 +1,163 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestValidatePipelineYml(t *testing.T) {
+	svc := &GittarFileTree{}
+	app := &apistructs.ApplicationDTO{Name: "app", ProjectName: "project"}
+	tests := []struct {
+		name    string
+		content string
+		// 期望的第一个错误，为空表示校验通过
+		want *apistructs.PipelineYmlValidationError
+	}{
+		{
+			name: "valid pipeline",
+			content: `version: "1.1"
+stages:
+  - stage:
+      - git-checkout:
+          alias: repo
+`,
+		},
+		{
+			name:    "invalid yaml",
+			content: "version: \"1.1\"\nstages:\n  - stage: [\n",
+			want:    &apistructs.PipelineYmlValidationError{Line: 3},
+		},
+		{
+			name:    "empty yaml",
+			content: "",
+			want:    &apistructs.PipelineYmlValidationError{Line: 1, Message: "pipeline yml is empty"},
+		},
+		{
+			name:    "not a mapping",
+			content: "- a\n- b\n",
+			want:    &apistructs.PipelineYmlValidationError{Line: 1, Column: 1, Message: "pipeline yml must be a mapping"},
+		},
+		{
+			name: "invalid stage",
+			content: `version: "1.1"
+stages:
+  - git-checkout:
+      alias: repo
+`,
+			want: &apistructs.PipelineYmlValidationError{Line: 3, Column: 5, Path: "stages[0]", Message: "missing key 'stage'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := svc.validatePipelineYml(tt.content, app, 1, "1")
+			if tt.want == nil {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.NotEmpty(t, errs) {
+				assert.Equal(t, tt.want.Line, errs[0].Line)
+				assert.Equal(t, tt.want.Column, errs[0].Column)
+				assert.Equal(t, tt.want.Path, errs[0].Path)
+				if tt.want.Message != "" {
+					assert.Equal(t, tt.want.Message, errs[0].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStages(t *testing.T) {
+	svc := &GittarFileTree{}
+	app := &apistructs.ApplicationDTO{Name: "app", ProjectName: "project"}
+	tests := []struct {
+		name      string
+		stages    string
+		wantPaths []string
+	}{
+		{
+			name: "valid stages",
+			stages: `
+- stage:
+    - git-checkout:
+    - snippet:
+        snippet_config:
+          source: autotest
+          name: "1"
+`,
+		},
+		{
+			name:      "stages is not a list",
+			stages:    "stage: a",
+			wantPaths: []string{"stages"},
+		},
+		{
+			name: "invalid stage and tasks",
+			stages: `
+- a
+- stage: a
+- stage:
+    - a
+    - git-checkout:
+      dice:
+    - "":
+    - git-checkout: a
+`,
+			wantPaths: []string{
+				"stages[0]",
+				"stages[1].stage",
+				"stages[2].stage[0]",
+				"stages[2].stage[1]",
+				"stages[2].stage[2]",
+				"stages[2].stage[3].git-checkout",
+			},
+		},
+		{
+			name: "invalid snippet",
+			stages: `
+- stage:
+    - snippet:
+    - snippet:
+        alias: a
+    - snippet:
+        snippet_config:
+          source: local
+          name: a.yml
+`,
+			wantPaths: []string{
+				"stages[0].stage[0].snippet",
+				"stages[0].stage[1].snippet",
+				"stages[0].stage[2].snippet.snippet_config",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var root yaml.Node
+			assert.NoError(t, yaml.Unmarshal([]byte(tt.stages), &root))
+			var paths []string
+			for _, e := range svc.validateStages(root.Content[0], app, 1, "1") {
+				paths = append(paths, e.Path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
@@ -38,6 +38,7 @@ const (
 	I18nKeyCaseAPITestBody      = "tp.export.case.apitest.body"
 	I18nKeyCaseAPITestOutParams = "tp.export.case.apitest.outparams"
 	I18nKeyCaseAPITestAsserts   = "tp.export.case.apitest.asserts"
+	I18nKeyCaseAttachments      = "tp.export.case.attachments"
 
 	I18nKeySpaceSheetName   = "tp.export.space.sheet.name"
 	I18nKeySpaceNum         = "tp.export.space.num"
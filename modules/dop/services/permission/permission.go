@@ -84,6 +84,16 @@ func (p *Permission) CheckAppAction(identityInfo apistructs.IdentityInfo, appID
 	})
 }
 
+// CheckProjectAction 校验用户在项目下是否有 ${action} 权限
+func (p *Permission) CheckProjectAction(identityInfo apistructs.IdentityInfo, projectID uint64, action string) error {
+	return p.check(identityInfo, &apistructs.PermissionCheckRequest{
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  projectID,
+		Resource: apistructs.ProjectResource,
+		Action:   action,
+	})
+}
+
 // CheckAppConfig 校验用户在应用配置管理下是否有 ${action} 权限
 func (p *Permission) CheckAppConfig(identityInfo apistructs.IdentityInfo, appID uint64, action string) error {
 	return p.check(identityInfo, &apistructs.PermissionCheckRequest{
@@ -0,0 +1,173 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const batchCancelPageSize = 100
+
+// BatchCancelPipelines 按应用、分支、状态批量取消流水线，已结束的流水线跳过
+// checkBranch 用于校验用户是否有对应分支的操作权限
+func (p *Pipeline) BatchCancelPipelines(req apistructs.PipelineBatchCancelRequest,
+	checkBranch func(branch string) error) (*apistructs.PipelineBatchCancelResult, error) {
+
+	pipelines, err := p.listBatchCancelPipelines(req)
+	if err != nil {
+		return nil, err
+	}
+
+	canceler := batchCanceler{
+		checkBranch: checkBranch,
+		cancel: func(pipelineID uint64) error {
+			return p.bdl.CancelPipeline(apistructs.PipelineCancelRequest{
+				PipelineID:   pipelineID,
+				IdentityInfo: req.IdentityInfo,
+			})
+		},
+		getStatus: func(pipelineID uint64) (apistructs.PipelineStatus, error) {
+			detail, err := p.bdl.GetPipeline(pipelineID)
+			if err != nil {
+				return "", err
+			}
+			return detail.Status, nil
+		},
+	}
+	result := canceler.run(pipelines)
+	result.AppID = req.AppID
+	result.Branch = req.Branch
+
+	return result, nil
+}
+
+// listBatchCancelPipelines 先查出全部匹配的流水线再取消，避免取消过程中状态变化导致分页错位
+func (p *Pipeline) listBatchCancelPipelines(req apistructs.PipelineBatchCancelRequest) ([]apistructs.PagePipeline, error) {
+	labels := []string{fmt.Sprintf("%s=%d", apistructs.LabelAppID, req.AppID)}
+	if req.Branch != "" {
+		labels = append(labels, fmt.Sprintf("%s=%s", apistructs.LabelBranch, req.Branch))
+	}
+	statuses := req.Statuses
+	if len(statuses) == 0 {
+		statuses = []apistructs.PipelineStatus{
+			apistructs.PipelineStatusCreated,
+			apistructs.PipelineStatusQueue,
+			apistructs.PipelineStatusRunning,
+		}
+	}
+	var statusStrs []string
+	for _, status := range statuses {
+		statusStrs = append(statusStrs, status.String())
+	}
+
+	var pipelines []apistructs.PagePipeline
+	for pageNum := 1; ; pageNum++ {
+		data, err := p.bdl.PageListPipeline(apistructs.PipelinePageListRequest{
+			PageNum:                    pageNum,
+			PageSize:                   batchCancelPageSize,
+			Sources:                    []apistructs.PipelineSource{apistructs.PipelineSourceDice},
+			Statuses:                   statusStrs,
+			MustMatchLabelsQueryParams: labels,
+		})
+		if err != nil {
+			return nil, apierrors.ErrListPipeline.InternalError(err)
+		}
+		pipelines = append(pipelines, data.Pipelines...)
+		if len(data.Pipelines) < batchCancelPageSize || int64(len(pipelines)) >= data.Total {
+			break
+		}
+	}
+
+	return pipelines, nil
+}
+
+type batchCanceler struct {
+	checkBranch func(branch string) error
+	cancel      func(pipelineID uint64) error
+	getStatus   func(pipelineID uint64) (apistructs.PipelineStatus, error)
+}
+
+func (c batchCanceler) run(pipelines []apistructs.PagePipeline) *apistructs.PipelineBatchCancelResult {
+	result := &apistructs.PipelineBatchCancelResult{List: []apistructs.PipelineBatchCancelItem{}}
+	// 同一分支只校验一次权限
+	branchErrs := make(map[string]error)
+	for _, pipeline := range pipelines {
+		item := c.cancelOne(pipeline, branchErrs)
+		switch item.Result {
+		case apistructs.PipelineBatchCancelItemCanceled:
+			result.Canceled++
+		case apistructs.PipelineBatchCancelItemSkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+		result.List = append(result.List, item)
+	}
+	result.Total = len(result.List)
+
+	return result
+}
+
+func (c batchCanceler) cancelOne(pipeline apistructs.PagePipeline, branchErrs map[string]error) apistructs.PipelineBatchCancelItem {
+	branch := pipeline.FilterLabels[apistructs.LabelBranch]
+	item := apistructs.PipelineBatchCancelItem{
+		PipelineID: pipeline.ID,
+		Branch:     branch,
+		Status:     pipeline.Status,
+	}
+	if !pipeline.Status.CanCancel() {
+		item.Result = apistructs.PipelineBatchCancelItemSkipped
+		item.Reason = fmt.Sprintf("pipeline status is %s", pipeline.Status)
+		return item
+	}
+
+	permErr, ok := branchErrs[branch]
+	if !ok {
+		permErr = c.checkBranch(branch)
+		branchErrs[branch] = permErr
+	}
+	if permErr != nil {
+		item.Result = apistructs.PipelineBatchCancelItemFailed
+		item.Reason = permErr.Error()
+		return item
+	}
+
+	cancelErr := c.cancel(pipeline.ID)
+	if cancelErr == nil {
+		item.Result = apistructs.PipelineBatchCancelItemCanceled
+		item.Status = apistructs.PipelineStatusStopByUser
+		return item
+	}
+
+	// 取消失败时流水线可能已经自行结束，此时视为跳过
+	status, err := c.getStatus(pipeline.ID)
+	if err != nil {
+		logrus.Errorf("failed to get pipeline status after cancel failed, pipelineID: %d, err: %v", pipeline.ID, err)
+	} else if status.IsEndStatus() {
+		item.Result = apistructs.PipelineBatchCancelItemSkipped
+		item.Status = status
+		item.Reason = fmt.Sprintf("pipeline already finished with status %s", status)
+		return item
+	}
+	item.Result = apistructs.PipelineBatchCancelItemFailed
+	item.Reason = cancelErr.Error()
+
+	return item
+}
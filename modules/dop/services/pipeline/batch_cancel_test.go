@@ -0,0 +1,77 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestBatchCancelerRun(t *testing.T) {
+	var checkedBranches []string
+	canceler := batchCanceler{
+		checkBranch: func(branch string) error {
+			checkedBranches = append(checkedBranches, branch)
+			if branch == "master" {
+				return fmt.Errorf("permission denied")
+			}
+			return nil
+		},
+		cancel: func(pipelineID uint64) error {
+			if pipelineID == 4 || pipelineID == 5 {
+				return fmt.Errorf("cancel failed")
+			}
+			return nil
+		},
+		getStatus: func(pipelineID uint64) (apistructs.PipelineStatus, error) {
+			if pipelineID == 4 {
+				return apistructs.PipelineStatusSuccess, nil
+			}
+			return apistructs.PipelineStatusRunning, nil
+		},
+	}
+	pipelines := []apistructs.PagePipeline{
+		{ID: 1, Status: apistructs.PipelineStatusRunning, FilterLabels: map[string]string{apistructs.LabelBranch: "develop"}},
+		{ID: 2, Status: apistructs.PipelineStatusSuccess, FilterLabels: map[string]string{apistructs.LabelBranch: "develop"}},
+		{ID: 3, Status: apistructs.PipelineStatusQueue, FilterLabels: map[string]string{apistructs.LabelBranch: "master"}},
+		{ID: 4, Status: apistructs.PipelineStatusRunning, FilterLabels: map[string]string{apistructs.LabelBranch: "develop"}},
+		{ID: 5, Status: apistructs.PipelineStatusCreated, FilterLabels: map[string]string{apistructs.LabelBranch: "develop"}},
+		{ID: 6, Status: apistructs.PipelineStatusRunning, FilterLabels: map[string]string{apistructs.LabelBranch: "master"}},
+	}
+
+	result := canceler.run(pipelines)
+	assert.Equal(t, 6, result.Total)
+	assert.Equal(t, 1, result.Canceled)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Equal(t, 3, result.Failed)
+	assert.Equal(t, []string{"develop", "master"}, checkedBranches)
+
+	want := []apistructs.PipelineBatchCancelItemResult{
+		apistructs.PipelineBatchCancelItemCanceled,
+		apistructs.PipelineBatchCancelItemSkipped,
+		apistructs.PipelineBatchCancelItemFailed,
+		apistructs.PipelineBatchCancelItemSkipped,
+		apistructs.PipelineBatchCancelItemFailed,
+		apistructs.PipelineBatchCancelItemFailed,
+	}
+	for i, item := range result.List {
+		assert.Equal(t, want[i], item.Result, "pipeline %d", item.PipelineID)
+	}
+	assert.Equal(t, apistructs.PipelineStatusSuccess, result.List[3].Status)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/cron"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	defaultCronFireTimeCount = 1
+	maxCronFireTimeCount     = 20
+
+	defaultCronHistoryPageSize = 20
+	maxCronHistoryPageSize     = 100
+)
+
+// GetCronNextFireTime 根据定时配置的 cron 表达式和时区计算后续 count 次触发时间
+// 未配置时区时与流水线定时调度器一致，使用服务端时区
+func (p *Pipeline) GetCronNextFireTime(cronID uint64, count int) (*apistructs.PipelineCronNextFireTime, error) {
+	cronInfo, err := p.bdl.GetPipelineCron(cronID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := pipelineyml.LoadCronLocation(cronInfo.TimeZone)
+	if err != nil {
+		return nil, apierrors.ErrGetCronFireTime.InvalidParameter(err)
+	}
+
+	now := time.Now().In(location)
+	result := &apistructs.PipelineCronNextFireTime{
+		CronID:        cronInfo.ID,
+		CronExpr:      cronInfo.CronExpr,
+		Enable:        cronInfo.Enable != nil && *cronInfo.Enable,
+		TimeZone:      location.String(),
+		NextFireTimes: []time.Time{},
+	}
+	if !result.Enable {
+		return result, nil
+	}
+
+	fireTimes, err := nextCronFireTimes(cronInfo.CronExpr, cronInfo.CronStartTime, now, getCronFireTimeCount(count))
+	if err != nil {
+		return nil, apierrors.ErrGetCronFireTime.InvalidParameter(err)
+	}
+	result.NextFireTimes = fireTimes
+
+	return result, nil
+}
+
+// ListCronHistory 分页查询定时触发的流水线及其执行结果，按创建时间倒序
+func (p *Pipeline) ListCronHistory(req apistructs.PipelineCronHistoryRequest) (*apistructs.PipelineCronHistoryData, error) {
+	cronInfo, err := p.bdl.GetPipelineCron(req.CronID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultCronHistoryPageSize
+	}
+	if req.PageSize > maxCronHistoryPageSize {
+		req.PageSize = maxCronHistoryPageSize
+	}
+
+	source := cronInfo.PipelineSource
+	if source == "" {
+		source = apistructs.PipelineSourceDice
+	}
+	pageData, err := p.bdl.PageListPipeline(apistructs.PipelinePageListRequest{
+		PageNum:      req.PageNo,
+		PageSize:     req.PageSize,
+		Sources:      []apistructs.PipelineSource{source},
+		YmlNames:     []string{cronInfo.PipelineYmlName},
+		TriggerModes: []apistructs.PipelineTriggerMode{apistructs.PipelineTriggerModeCron},
+	})
+	if err != nil {
+		return nil, apierrors.ErrListCronHistory.InternalError(err)
+	}
+
+	result := &apistructs.PipelineCronHistoryData{
+		Total: pageData.Total,
+		List:  make([]apistructs.PipelineCronHistoryItem, 0, len(pageData.Pipelines)),
+	}
+	for _, pipeline := range pageData.Pipelines {
+		result.List = append(result.List, apistructs.PipelineCronHistoryItem{
+			PipelineID:  pipeline.ID,
+			Status:      pipeline.Status,
+			TriggerTime: pipeline.Extra.CronTriggerTime,
+			TimeBegin:   pipeline.TimeBegin,
+			TimeEnd:     pipeline.TimeEnd,
+			CostTimeSec: pipeline.CostTimeSec,
+		})
+	}
+
+	return result, nil
+}
+
+// nextCronFireTimes 计算 from 之后的 count 次触发时间，按 from 所在时区解析表达式
+// 早于 startFrom 的触发会被调度器忽略，因此从 startFrom 开始计算
+func nextCronFireTimes(cronExpr string, startFrom *time.Time, from time.Time, count int) ([]time.Time, error) {
+	schedule, err := cron.ParseSpec(cronExpr)
+	if err != nil {
+		return nil, err
+	}
+	if startFrom != nil && startFrom.After(from) {
+		// Next 返回严格晚于入参的时间，回退 1s 使恰好等于 startFrom 的触发也被计入
+		from = startFrom.Add(-time.Second).In(from.Location())
+	}
+
+	fireTimes := make([]time.Time, 0, count)
+	for len(fireTimes) < count {
+		next := schedule.Next(from)
+		// 表达式永远不会触发，例如 2 月 30 日
+		if next.IsZero() {
+			break
+		}
+		fireTimes = append(fireTimes, next)
+		from = next
+	}
+
+	return fireTimes, nil
+}
+
+func getCronFireTimeCount(count int) int {
+	if count <= 0 {
+		return defaultCronFireTimeCount
+	}
+	if count > maxCronFireTimeCount {
+		return maxCronFireTimeCount
+	}
+	return count
+}
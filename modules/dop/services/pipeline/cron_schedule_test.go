@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextCronFireTimes(t *testing.T) {
+	from := time.Date(2021, 10, 1, 10, 30, 0, 0, time.Local)
+
+	fireTimes, err := nextCronFireTimes("0 0 2 * * ?", nil, from, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 10, 2, 2, 0, 0, 0, time.Local),
+		time.Date(2021, 10, 3, 2, 0, 0, 0, time.Local),
+	}, fireTimes)
+
+	// 5 段表达式按标准 crontab 解析
+	fireTimes, err = nextCronFireTimes("*/15 * * * *", nil, from, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{time.Date(2021, 10, 1, 10, 45, 0, 0, time.Local)}, fireTimes)
+
+	// 早于 cronStartFrom 的触发会被忽略
+	startFrom := time.Date(2021, 10, 5, 2, 0, 0, 0, time.Local)
+	fireTimes, err = nextCronFireTimes("0 0 2 * * ?", &startFrom, from, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{startFrom}, fireTimes)
+
+	_, err = nextCronFireTimes("invalid", nil, from, 1)
+	assert.Error(t, err)
+}
+
+func TestGetCronFireTimeCount(t *testing.T) {
+	assert.Equal(t, defaultCronFireTimeCount, getCronFireTimeCount(0))
+	assert.Equal(t, 5, getCronFireTimeCount(5))
+	assert.Equal(t, maxCronFireTimeCount, getCronFireTimeCount(100))
+}
+
+func TestNextCronFireTimesInLocation(t *testing.T) {
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	from := time.Date(2021, 10, 1, 0, 30, 0, 0, time.UTC).In(tokyo)
+
+	fireTimes, err := nextCronFireTimes("0 9 * * *", nil, from, 1)
+	assert.NoError(t, err)
+	assert.Len(t, fireTimes, 1)
+	assert.True(t, fireTimes[0].Equal(time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)))
+}
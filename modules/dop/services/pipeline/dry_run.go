@@ -0,0 +1,221 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/modules/dop/utils"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+)
+
+const (
+	// maxDryRunSnippetDepth snippet 最大展开层数
+	maxDryRunSnippetDepth = 5
+	// dryRunEncryptedValue 加密配置在预览中不展示真实值
+	dryRunEncryptedValue = "******"
+)
+
+var dryRunPlaceholderRegexp = regexp.MustCompile(`\(\([^()\s]+\)\)`)
+
+// dryRunContext 预览过程中的上下文，收集配置和告警
+type dryRunContext struct {
+	projectID  uint64
+	orgID      uint64
+	userID     string
+	configs    map[string]string
+	unresolved map[string]struct{}
+	warnings   []string
+}
+
+func (ctx *dryRunContext) warn(format string, args ...interface{}) {
+	ctx.warnings = append(ctx.warnings, fmt.Sprintf(format, args...))
+}
+
+// render 用配置渲染 yml 中的占位符，并记录未能渲染的占位符
+func (ctx *dryRunContext) render(yml string) string {
+	rendered, err := pipelineyml.RenderSecrets([]byte(yml), ctx.configs)
+	if err != nil {
+		ctx.warn("failed to render configs: %v", err)
+		return yml
+	}
+	for _, placeholder := range dryRunPlaceholderRegexp.FindAllString(string(rendered), -1) {
+		ctx.unresolved[placeholder] = struct{}{}
+	}
+	return string(rendered)
+}
+
+// DryRunPipelineV2 预览流水线：渲染配置、展开 snippet 并返回校验告警，不创建也不执行流水线
+func (p *Pipeline) DryRunPipelineV2(req *apistructs.PipelineCreateRequestV2) (*apistructs.PipelineDryRunResult, error) {
+	ctx := &dryRunContext{
+		userID:     req.UserID,
+		unresolved: map[string]struct{}{},
+	}
+	ctx.projectID, _ = strconv.ParseUint(req.Labels[apistructs.LabelProjectID], 10, 64)
+	ctx.orgID, _ = strconv.ParseUint(req.Labels[apistructs.LabelOrgID], 10, 64)
+	ctx.configs = p.getDryRunConfigs(ctx, req.ConfigManageNamespaces, req.PipelineSource)
+
+	pipelineYml := ctx.render(req.PipelineYml)
+	y, err := pipelineyml.New([]byte(pipelineYml))
+	if err != nil {
+		ctx.warn("invalid pipeline yml: %v", err)
+	} else {
+		ctx.warnings = append(ctx.warnings, y.Warns()...)
+		if y.NeedUpgrade() {
+			ctx.warn("pipeline yml version is outdated and will be upgraded at runtime")
+		}
+	}
+
+	graph, err := p.parseDryRunGraph(ctx, pipelineYml, map[string]bool{}, 0)
+	if err != nil {
+		return nil, apierrors.ErrCreatePipeline.InvalidParameter(fmt.Errorf("invalid pipelineYml: %v", err))
+	}
+
+	if len(ctx.unresolved) > 0 {
+		var placeholders []string
+		for placeholder := range ctx.unresolved {
+			placeholders = append(placeholders, placeholder)
+		}
+		sort.Strings(placeholders)
+		ctx.warn("placeholders not found in configs, they must be provided at runtime: %s", strings.Join(placeholders, ", "))
+	}
+
+	return &apistructs.PipelineDryRunResult{
+		PipelineYmlName:        req.PipelineYmlName,
+		Branch:                 req.Labels[apistructs.LabelBranch],
+		Workspace:              req.Labels[apistructs.LabelDiceWorkspace],
+		ClusterName:            req.ClusterName,
+		PipelineYml:            pipelineYml,
+		Stages:                 graph.Stages,
+		ConfigManageNamespaces: req.ConfigManageNamespaces,
+		Warnings:               ctx.warnings,
+	}, nil
+}
+
+// getDryRunConfigs 按命名空间顺序获取配置，后面的命名空间覆盖前面的
+func (p *Pipeline) getDryRunConfigs(ctx *dryRunContext, namespaces []string, source apistructs.PipelineSource) map[string]string {
+	configs := make(map[string]string)
+	if p.cms == nil {
+		return configs
+	}
+	var encrypted []string
+	for _, ns := range namespaces {
+		resp, err := p.cms.GetCmsNsConfigs(utils.WithInternalClientContext(context.Background()),
+			&cmspb.CmsNsConfigsGetRequest{
+				Ns:             ns,
+				PipelineSource: source.String(),
+			})
+		if err != nil {
+			ctx.warn("failed to get configs of namespace %s: %v", ns, err)
+			continue
+		}
+		for _, cfg := range resp.Data {
+			if cfg.EncryptInDB {
+				configs[cfg.Key] = dryRunEncryptedValue
+				encrypted = append(encrypted, cfg.Key)
+				continue
+			}
+			configs[cfg.Key] = cfg.Value
+		}
+	}
+	if len(encrypted) > 0 {
+		ctx.warn("encrypted configs are masked in dry run: %s", strings.Join(encrypted, ", "))
+	}
+	return configs
+}
+
+// parseDryRunGraph 解析流水线结构，并递归展开其中的 snippet
+func (p *Pipeline) parseDryRunGraph(ctx *dryRunContext, yml string, visiting map[string]bool, depth int) (*apistructs.PipelineYml, error) {
+	graph, err := p.bdl.ParsePipelineYmlGraph(apistructs.PipelineYmlParseGraphRequest{PipelineYmlContent: yml})
+	if err != nil {
+		return nil, err
+	}
+	for _, stage := range graph.Stages {
+		for _, action := range stage {
+			if action.Type == apistructs.ActionTypeSnippet && action.SnippetConfig != nil {
+				p.expandDryRunSnippet(ctx, action, visiting, depth)
+			}
+		}
+	}
+	return graph, nil
+}
+
+// expandDryRunSnippet 展开应用下的 snippet，其他来源的 snippet 只给出告警
+func (p *Pipeline) expandDryRunSnippet(ctx *dryRunContext, action *apistructs.PipelineYmlAction, visiting map[string]bool, depth int) {
+	config := action.SnippetConfig
+	if config.Source != apistructs.SnippetSourceLocal {
+		ctx.warn("snippet %s from source %s is not expanded in dry run", config.Name, config.Source)
+		return
+	}
+	key := config.Labels[apistructs.LabelGittarYmlPath]
+	if visiting[key] {
+		ctx.warn("snippet %s is referenced circularly", config.Name)
+		return
+	}
+	if depth >= maxDryRunSnippetDepth {
+		ctx.warn("snippet %s exceeds max depth %d and is not expanded", config.Name, maxDryRunSnippetDepth)
+		return
+	}
+
+	yml, err := p.fetchLocalSnippetYml(ctx, config)
+	if err != nil {
+		ctx.warn("%v", apierrors.ErrGetSnippetYaml.InvalidParameter(fmt.Errorf("snippet %s: %v", config.Name, err)))
+		return
+	}
+
+	visiting[key] = true
+	defer delete(visiting, key)
+	graph, err := p.parseDryRunGraph(ctx, ctx.render(yml), visiting, depth+1)
+	if err != nil {
+		ctx.warn("invalid snippet %s: %v", config.Name, err)
+		return
+	}
+	action.SnippetStages = &apistructs.SnippetStages{
+		Params:  graph.Params,
+		Outputs: graph.Outputs,
+		Stages:  graph.Stages,
+	}
+}
+
+// fetchLocalSnippetYml 获取应用下 snippet 引用的流水线文件
+// gittarYmlPath 标签格式为 appName/workspace/branch/ymlPath
+func (p *Pipeline) fetchLocalSnippetYml(ctx *dryRunContext, config *apistructs.SnippetConfig) (string, error) {
+	ymlPath := strings.TrimPrefix(config.Labels[apistructs.LabelGittarYmlPath], "/")
+	name := strings.TrimPrefix(config.Name, "/")
+	parts := strings.SplitN(ymlPath, "/", 3)
+	if name == "" || len(parts) < 3 || !strings.HasSuffix(parts[2], "/"+name) {
+		return "", fmt.Errorf("invalid label %s: %q", apistructs.LabelGittarYmlPath, ymlPath)
+	}
+	appName, branch := parts[0], strings.TrimSuffix(parts[2], "/"+name)
+
+	apps, err := p.bdl.GetAppsByProjectAndAppName(ctx.projectID, ctx.orgID, ctx.userID, appName)
+	if err != nil {
+		return "", err
+	}
+	for _, app := range apps.List {
+		if app.Name == appName {
+			return p.FetchPipelineYml(app.GitRepo, branch, name)
+		}
+	}
+	return "", fmt.Errorf("not find app: %s", appName)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
+	"github.com/erda-project/erda/apistructs"
+)
+
+type fakeDryRunCms struct {
+	cmspb.CmsServiceServer
+	configs map[string][]*cmspb.PipelineCmsConfig
+}
+
+func (f *fakeDryRunCms) GetCmsNsConfigs(ctx context.Context, req *cmspb.CmsNsConfigsGetRequest) (*cmspb.CmsNsConfigsGetResponse, error) {
+	return &cmspb.CmsNsConfigsGetResponse{Data: f.configs[req.Ns]}, nil
+}
+
+func TestGetDryRunConfigs(t *testing.T) {
+	p := New(WithPipelineCms(&fakeDryRunCms{configs: map[string][]*cmspb.PipelineCmsConfig{
+		"default": {
+			{Key: "host", Value: "default.example.com"},
+			{Key: "port", Value: "80"},
+		},
+		"develop": {
+			{Key: "host", Value: "dev.example.com"},
+			{Key: "password", Value: "secret", EncryptInDB: true},
+		},
+	}}))
+	ctx := &dryRunContext{unresolved: map[string]struct{}{}}
+
+	configs := p.getDryRunConfigs(ctx, []string{"default", "develop"}, apistructs.PipelineSourceDice)
+	assert.Equal(t, map[string]string{
+		"host":     "dev.example.com",
+		"port":     "80",
+		"password": dryRunEncryptedValue,
+	}, configs)
+	assert.Equal(t, []string{"encrypted configs are masked in dry run: password"}, ctx.warnings)
+}
+
+func TestDryRunContextRender(t *testing.T) {
+	ctx := &dryRunContext{
+		configs:    map[string]string{"host": "dev.example.com"},
+		unresolved: map[string]struct{}{},
+	}
+	rendered := ctx.render("envs:\n  HOST: ((host))\n  REPO: ((gittar.repo))\n")
+	assert.Equal(t, "envs:\n  HOST: dev.example.com\n  REPO: ((gittar.repo))\n", rendered)
+	assert.Equal(t, map[string]struct{}{"((gittar.repo))": {}}, ctx.unresolved)
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+	"gopkg.in/yaml.v3"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// CreateParamPreset 创建运行参数预设，参数需与流水线声明的 params 匹配
+func (p *Pipeline) CreateParamPreset(req apistructs.PipelineParamPresetCreateRequest) (*apistructs.PipelineParamPresetDTO, error) {
+	if req.Name == "" {
+		return nil, apierrors.ErrCreateParamPreset.MissingParameter("name")
+	}
+	if req.Branch == "" {
+		return nil, apierrors.ErrCreateParamPreset.MissingParameter("branch")
+	}
+	if req.PipelineYmlName == "" {
+		req.PipelineYmlName = apistructs.DefaultPipelineYmlName
+	}
+
+	exist, err := p.db.GetPipelineParamPresetByName(req.AppID, req.Branch, req.PipelineYmlName, req.Name)
+	if err != nil {
+		return nil, apierrors.ErrCreateParamPreset.InternalError(err)
+	}
+	if exist != nil {
+		return nil, apierrors.ErrCreateParamPreset.AlreadyExists()
+	}
+
+	app, err := p.bdl.GetApp(req.AppID)
+	if err != nil {
+		return nil, apierrors.ErrGetApp.InternalError(err)
+	}
+	if err := p.checkParamPreset(apierrors.ErrCreateParamPreset, app, req.Branch, req.PipelineYmlName, req.Params); err != nil {
+		return nil, err
+	}
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, apierrors.ErrCreateParamPreset.InvalidParameter(err)
+	}
+
+	preset := dao.PipelineParamPreset{
+		ProjectID:       app.ProjectID,
+		AppID:           req.AppID,
+		Branch:          req.Branch,
+		PipelineYmlName: req.PipelineYmlName,
+		Name:            req.Name,
+		Params:          string(params),
+		IsDefault:       req.IsDefault,
+		CreatorID:       req.UserID,
+		UpdaterID:       req.UserID,
+	}
+	if err := p.db.CreatePipelineParamPreset(&preset); err != nil {
+		return nil, apierrors.ErrCreateParamPreset.InternalError(err)
+	}
+
+	return convertParamPreset(preset)
+}
+
+// UpdateParamPreset 更新运行参数预设，只更新请求中传入的字段
+func (p *Pipeline) UpdateParamPreset(req apistructs.PipelineParamPresetUpdateRequest) (*apistructs.PipelineParamPresetDTO, error) {
+	preset, err := p.getParamPreset(apierrors.ErrUpdateParamPreset, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" && req.Name != preset.Name {
+		exist, err := p.db.GetPipelineParamPresetByName(preset.AppID, preset.Branch, preset.PipelineYmlName, req.Name)
+		if err != nil {
+			return nil, apierrors.ErrUpdateParamPreset.InternalError(err)
+		}
+		if exist != nil {
+			return nil, apierrors.ErrUpdateParamPreset.AlreadyExists()
+		}
+		preset.Name = req.Name
+	}
+
+	// 参数变更时才需重新校验
+	if req.Params != nil {
+		app, err := p.bdl.GetApp(preset.AppID)
+		if err != nil {
+			return nil, apierrors.ErrGetApp.InternalError(err)
+		}
+		if err := p.checkParamPreset(apierrors.ErrUpdateParamPreset, app, preset.Branch, preset.PipelineYmlName, *req.Params); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyParamPresetUpdate(preset, req); err != nil {
+		return nil, apierrors.ErrUpdateParamPreset.InvalidParameter(err)
+	}
+	if err := p.db.UpdatePipelineParamPreset(preset); err != nil {
+		return nil, apierrors.ErrUpdateParamPreset.InternalError(err)
+	}
+
+	return convertParamPreset(*preset)
+}
+
+// applyParamPresetUpdate 将请求中传入的 params、isDefault 更新至预设，未传入的字段保持不变
+func applyParamPresetUpdate(preset *dao.PipelineParamPreset, req apistructs.PipelineParamPresetUpdateRequest) error {
+	if req.Params != nil {
+		params, err := json.Marshal(*req.Params)
+		if err != nil {
+			return err
+		}
+		preset.Params = string(params)
+	}
+	if req.IsDefault != nil {
+		preset.IsDefault = *req.IsDefault
+	}
+	preset.UpdaterID = req.UserID
+	return nil
+}
+
+// GetParamPreset 获取运行参数预设
+func (p *Pipeline) GetParamPreset(id uint64) (*apistructs.PipelineParamPresetDTO, error) {
+	preset, err := p.getParamPreset(apierrors.ErrGetParamPreset, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertParamPreset(*preset)
+}
+
+func (p *Pipeline) getParamPreset(apiErr *errorresp.APIError, id uint64) (*dao.PipelineParamPreset, error) {
+	preset, err := p.db.GetPipelineParamPreset(id)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apiErr.NotFound()
+		}
+		return nil, apiErr.InternalError(err)
+	}
+	return preset, nil
+}
+
+// DeleteParamPreset 删除运行参数预设
+func (p *Pipeline) DeleteParamPreset(id uint64) error {
+	if err := p.db.DeletePipelineParamPreset(id); err != nil {
+		return apierrors.ErrDeleteParamPreset.InternalError(err)
+	}
+	return nil
+}
+
+// ListParamPresets 查询流水线定义下的运行参数预设，默认预设排在最前，用于运行弹窗预填参数
+func (p *Pipeline) ListParamPresets(req apistructs.PipelineParamPresetListRequest) ([]apistructs.PipelineParamPresetDTO, error) {
+	if req.PipelineYmlName == "" {
+		req.PipelineYmlName = apistructs.DefaultPipelineYmlName
+	}
+	presets, err := p.db.ListPipelineParamPresets(req.AppID, req.Branch, req.PipelineYmlName)
+	if err != nil {
+		return nil, apierrors.ErrListParamPreset.InternalError(err)
+	}
+	result := make([]apistructs.PipelineParamPresetDTO, 0, len(presets))
+	for _, preset := range presets {
+		dto, err := convertParamPreset(preset)
+		if err != nil {
+			return nil, apierrors.ErrListParamPreset.InternalError(err)
+		}
+		result = append(result, *dto)
+	}
+	return result, nil
+}
+
+// ApplyParamPreset 计算流水线运行参数
+// 指定 presetID 时使用该预设；未指定且未传入任何运行参数时使用默认预设；runParams 中的同名参数优先
+func (p *Pipeline) ApplyParamPreset(pipeline *apistructs.PipelineDTO, presetID uint64,
+	runParams apistructs.PipelineRunParams) (apistructs.PipelineRunParams, error) {
+
+	ymlName := GetPipelineDefinitionYmlName(pipeline)
+	var preset *dao.PipelineParamPreset
+	switch {
+	case presetID > 0:
+		var err error
+		preset, err = p.getParamPreset(apierrors.ErrRunPipeline, presetID)
+		if err != nil {
+			return nil, err
+		}
+		if preset.AppID != pipeline.ApplicationID || preset.Branch != pipeline.Branch || preset.PipelineYmlName != ymlName {
+			return nil, apierrors.ErrRunPipeline.InvalidParameter(
+				fmt.Sprintf("param preset %s does not belong to pipeline %s", preset.Name, ymlName))
+		}
+	case len(runParams) == 0 && pipeline.ApplicationID > 0:
+		var err error
+		preset, err = p.db.GetDefaultPipelineParamPreset(pipeline.ApplicationID, pipeline.Branch, ymlName)
+		if err != nil {
+			return nil, apierrors.ErrGetParamPreset.InternalError(err)
+		}
+	}
+	if preset == nil {
+		return runParams, nil
+	}
+
+	var presetParams apistructs.PipelineRunParams
+	if err := json.Unmarshal([]byte(preset.Params), &presetParams); err != nil {
+		return nil, apierrors.ErrGetParamPreset.InternalError(err)
+	}
+	return mergeRunParams(presetParams, runParams), nil
+}
+
+// GetPipelineDefinitionYmlName 返回 dice 流水线在仓库中的 yml 路径，如 pipeline.yml、.dice/pipelines/a.yml
+func GetPipelineDefinitionYmlName(pipeline *apistructs.PipelineDTO) string {
+	prefix := fmt.Sprintf("%d/%s/%s/", pipeline.ApplicationID, pipeline.Extra.DiceWorkspace, pipeline.Branch)
+	return strutil.TrimPrefixes(pipeline.YmlName, prefix)
+}
+
+func mergeRunParams(base, override apistructs.PipelineRunParams) apistructs.PipelineRunParams {
+	overridden := make(map[string]bool, len(override))
+	for _, param := range override {
+		overridden[param.Name] = true
+	}
+	var result apistructs.PipelineRunParams
+	for _, param := range base {
+		if !overridden[param.Name] {
+			result = append(result, param)
+		}
+	}
+	return append(result, override...)
+}
+
+func convertParamPreset(preset dao.PipelineParamPreset) (*apistructs.PipelineParamPresetDTO, error) {
+	var params apistructs.PipelineRunParams
+	if preset.Params != "" {
+		if err := json.Unmarshal([]byte(preset.Params), &params); err != nil {
+			return nil, err
+		}
+	}
+	return &apistructs.PipelineParamPresetDTO{
+		ID:              preset.ID,
+		ProjectID:       preset.ProjectID,
+		AppID:           preset.AppID,
+		Branch:          preset.Branch,
+		PipelineYmlName: preset.PipelineYmlName,
+		Name:            preset.Name,
+		Params:          params,
+		IsDefault:       preset.IsDefault,
+		CreatorID:       preset.CreatorID,
+		UpdaterID:       preset.UpdaterID,
+		CreatedAt:       preset.CreatedAt,
+		UpdatedAt:       preset.UpdatedAt,
+	}, nil
+}
+
+// checkParamPreset 读取分支上的流水线定义，校验预设参数与声明的 params 是否匹配
+func (p *Pipeline) checkParamPreset(apiErr *errorresp.APIError, app *apistructs.ApplicationDTO,
+	branch, pipelineYmlName string, params apistructs.PipelineRunParams) error {
+
+	yml, err := p.FetchPipelineYml(app.GitRepo, branch, pipelineYmlName)
+	if err != nil {
+		return apierrors.ErrGetGittarRepoFile.InternalError(err)
+	}
+	declared, err := parseDeclaredParams(yml)
+	if err != nil {
+		return apiErr.InvalidParameter(fmt.Errorf("failed to parse %s: %v", pipelineYmlName, err))
+	}
+	if problems := validateParamPreset(declared, params); len(problems) > 0 {
+		e := apiErr.InvalidParameter(fmt.Sprintf("params do not match inputs declared in %s", pipelineYmlName))
+		for _, problem := range problems {
+			e = e.AppendDetail(problem.name, problem.reason)
+		}
+		return e
+	}
+	return nil
+}
+
+func parseDeclaredParams(yml string) ([]*pipelineyml.PipelineParam, error) {
+	var spec struct {
+		Params []*pipelineyml.PipelineParam `yaml:"params"`
+	}
+	if err := yaml.Unmarshal([]byte(yml), &spec); err != nil {
+		return nil, err
+	}
+	return spec.Params, nil
+}
+
+type paramPresetProblem struct {
+	name   string
+	reason string
+}
+
+// validateParamPreset 校验预设参数：参数需已声明、不能重复、类型需匹配，且需覆盖没有默认值的必填参数
+func validateParamPreset(declared []*pipelineyml.PipelineParam, params apistructs.PipelineRunParams) []paramPresetProblem {
+	declaredMap := make(map[string]*pipelineyml.PipelineParam, len(declared))
+	for _, param := range declared {
+		if param != nil {
+			declaredMap[param.Name] = param
+		}
+	}
+
+	var problems []paramPresetProblem
+	provided := make(map[string]bool, len(params))
+	for _, param := range params {
+		if param.Name == "" {
+			problems = append(problems, paramPresetProblem{"params", "param name is empty"})
+			continue
+		}
+		if provided[param.Name] {
+			problems = append(problems, paramPresetProblem{param.Name, "duplicated param"})
+			continue
+		}
+		provided[param.Name] = true
+		define, ok := declaredMap[param.Name]
+		if !ok {
+			problems = append(problems, paramPresetProblem{param.Name, "param is not declared in pipeline params"})
+			continue
+		}
+		if reason := checkParamValueType(define.Type, param.Value); reason != "" {
+			problems = append(problems, paramPresetProblem{param.Name, reason})
+		}
+	}
+	for _, define := range declared {
+		if define == nil || !define.Required || define.Default != nil || provided[define.Name] {
+			continue
+		}
+		problems = append(problems, paramPresetProblem{define.Name, "required param has no default value"})
+	}
+
+	return problems
+}
+
+func checkParamValueType(paramType string, value interface{}) string {
+	switch paramType {
+	case apistructs.PipelineParamIntType:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return ""
+			}
+		case int, int64:
+			return ""
+		case string:
+			if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value %v is not a valid %s", value, paramType)
+	case apistructs.PipelineParamBoolType:
+		switch v := value.(type) {
+		case bool:
+			return ""
+		case string:
+			if _, err := strconv.ParseBool(v); err == nil {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value %v is not a valid %s", value, paramType)
+	default:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return fmt.Sprintf("value of %s param must be a scalar", apistructs.PipelineParamStringType)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestValidateParamPreset(t *testing.T) {
+	declared, err := parseDeclaredParams(`
+version: "1.1"
+params:
+  - name: env
+    required: true
+  - name: replicas
+    type: int
+    default: 1
+  - name: debug
+    type: boolean
+stages: []
+`)
+	assert.NoError(t, err)
+	assert.Len(t, declared, 3)
+
+	problems := validateParamPreset(declared, apistructs.PipelineRunParams{
+		{Name: "env", Value: "test"},
+		{Name: "replicas", Value: float64(2)},
+		{Name: "debug", Value: "true"},
+	})
+	assert.Empty(t, problems)
+
+	problems = validateParamPreset(declared, apistructs.PipelineRunParams{
+		{Name: "replicas", Value: 1.5},
+		{Name: "debug", Value: "yes"},
+		{Name: "debug", Value: true},
+		{Name: "unknown", Value: "x"},
+	})
+	var names []string
+	for _, problem := range problems {
+		names = append(names, problem.name)
+	}
+	assert.Equal(t, []string{"replicas", "debug", "debug", "unknown", "env"}, names)
+}
+
+func TestMergeRunParams(t *testing.T) {
+	merged := mergeRunParams(
+		apistructs.PipelineRunParams{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+		apistructs.PipelineRunParams{{Name: "b", Value: "3"}, {Name: "c", Value: "4"}},
+	)
+	assert.Equal(t, apistructs.PipelineRunParams{
+		{Name: "a", Value: "1"}, {Name: "b", Value: "3"}, {Name: "c", Value: "4"},
+	}, merged)
+}
+
+func TestGetPipelineDefinitionYmlName(t *testing.T) {
+	p := &apistructs.PipelineDTO{
+		ApplicationID: 100,
+		Branch:        "feature/a",
+		YmlName:       "100/DEV/feature/a/.dice/pipelines/a.yml",
+	}
+	p.Extra.DiceWorkspace = "DEV"
+	assert.Equal(t, ".dice/pipelines/a.yml", GetPipelineDefinitionYmlName(p))
+
+	p.YmlName = "pipeline.yml"
+	assert.Equal(t, "pipeline.yml", GetPipelineDefinitionYmlName(p))
+}
+
+func TestApplyParamPresetUpdate(t *testing.T) {
+	newPreset := func() *dao.PipelineParamPreset {
+		return &dao.PipelineParamPreset{Name: "daily", Params: `[{"name":"env","value":"dev"}]`, IsDefault: true}
+	}
+
+	// 未传入 params 及 isDefault 时保持不变
+	preset := newPreset()
+	assert.NoError(t, applyParamPresetUpdate(preset, apistructs.PipelineParamPresetUpdateRequest{IdentityInfo: apistructs.IdentityInfo{UserID: "2"}}))
+	assert.Equal(t, `[{"name":"env","value":"dev"}]`, preset.Params)
+	assert.True(t, preset.IsDefault)
+	assert.Equal(t, "2", preset.UpdaterID)
+
+	isDefault := false
+	preset = newPreset()
+	assert.NoError(t, applyParamPresetUpdate(preset, apistructs.PipelineParamPresetUpdateRequest{IsDefault: &isDefault}))
+	assert.Equal(t, `[{"name":"env","value":"dev"}]`, preset.Params)
+	assert.False(t, preset.IsDefault)
+
+	// 传入空 params 时清空
+	preset = newPreset()
+	assert.NoError(t, applyParamPresetUpdate(preset, apistructs.PipelineParamPresetUpdateRequest{Params: &apistructs.PipelineRunParams{}}))
+	assert.Equal(t, `[]`, preset.Params)
+	assert.True(t, preset.IsDefault)
+}
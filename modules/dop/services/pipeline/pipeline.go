@@ -30,6 +30,7 @@ import (
 	cmspb "github.com/erda-project/erda-proto-go/core/pipeline/cms/pb"
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/dop/services/branchrule"
 	"github.com/erda-project/erda/modules/dop/services/publisher"
@@ -48,6 +49,7 @@ const (
 
 // Pipeline pipeline 结构体
 type Pipeline struct {
+	db            *dao.DBClient
 	bdl           *bundle.Bundle
 	branchRuleSvc *branchrule.BranchRule
 	publisherSvc  *publisher.Publisher
@@ -66,6 +68,13 @@ func New(options ...Option) *Pipeline {
 	return r
 }
 
+// WithDBClient 配置 db client
+func WithDBClient(db *dao.DBClient) Option {
+	return func(f *Pipeline) {
+		f.db = db
+	}
+}
+
 // WithBundle 配置 bundle
 func WithBundle(bdl *bundle.Bundle) Option {
 	return func(f *Pipeline) {
@@ -539,10 +548,10 @@ func (p *Pipeline) PipelineCronUpdate(req apistructs.GittarPushPayloadEvent) err
 					logrus.Errorf("fail to GetGittarBlobNode,err: %s,path: %s,oldPath: %s", err.Error(), v.Name, v.OldName)
 					continue
 				}
-				// get cronExpr from pipelineYml
-				cronExpr, err := getCronExpr(pipelineYml)
+				// get cronExpr and timeZone from pipelineYml
+				cronExpr, timeZone, err := getCronConfig(pipelineYml)
 				if err != nil {
-					logrus.Errorf("fail to getCronExpr,err: %s,path: %s,oldPath: %s", err.Error(), v.Name, v.OldName)
+					logrus.Errorf("fail to getCronConfig,err: %s,path: %s,oldPath: %s", err.Error(), v.Name, v.OldName)
 					continue
 				}
 
@@ -550,6 +559,7 @@ func (p *Pipeline) PipelineCronUpdate(req apistructs.GittarPushPayloadEvent) err
 					ID:          cron.ID,
 					PipelineYml: pipelineYml,
 					CronExpr:    cronExpr,
+					TimeZone:    timeZone,
 				}); err != nil {
 					logrus.Errorf("fail to UpdatePipelineCron,err: %s,path: %s,oldPath: %s", err.Error(), v.Name, v.OldName)
 					continue
@@ -566,15 +576,16 @@ func (p *Pipeline) PipelineCronUpdate(req apistructs.GittarPushPayloadEvent) err
 	return nil
 }
 
-func getCronExpr(pipelineYmlStr string) (string, error) {
+// getCronConfig 从 pipeline.yml 中获取定时表达式及时区
+func getCronConfig(pipelineYmlStr string) (cronExpr, timeZone string, err error) {
 	if pipelineYmlStr == "" {
-		return "", nil
+		return "", "", nil
 	}
 	pipelineYml, err := pipelineyml.New([]byte(pipelineYmlStr))
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	return pipelineYml.Spec().Cron, nil
+	return pipelineYml.Spec().Cron, pipelineYml.Spec().CronTimeZone, nil
 }
 
 func getBranch(ref string) string {
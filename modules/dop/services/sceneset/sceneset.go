@@ -173,7 +173,13 @@ func (svc *Service) DeleteSceneSet(req apistructs.SceneSetRequest) error {
 	for i, s := range scenes {
 		ids[i] = s.ID
 	}
-	return svc.db.DeleteSceneSet(s, ids)
+	if err := svc.db.DeleteSceneSet(s, ids); err != nil {
+		return err
+	}
+	if err := svc.db.DeleteAutoTestSchedulesByTargets(apistructs.AutoTestScheduleTargetSceneSet, []uint64{s.ID}); err != nil {
+		return err
+	}
+	return svc.db.DeleteAutoTestSchedulesByTargets(apistructs.AutoTestScheduleTargetScene, ids)
 }
 
 func (svc *Service) DragSceneSet(req apistructs.SceneSetRequest) error {
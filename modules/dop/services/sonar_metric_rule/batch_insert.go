@@ -36,6 +36,7 @@ func (svc *Service) BatchInsert(req *apistructs.SonarMetricRulesBatchInsertReque
 			MetricKeyID: metric.MetricKeyID,
 			MetricValue: metric.MetricValue,
 			Description: metric.Description,
+			IsBlocking:  metric.IsBlocking,
 		}
 		if err := checkAndTruncatedMetricValue(insertRule); err != nil {
 			return nil, err
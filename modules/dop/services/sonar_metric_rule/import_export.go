@@ -0,0 +1,148 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sonar_metric_rule
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver"
+)
+
+// Export 导出项目自定义的指标规则，不包含平台默认规则
+func (svc *Service) Export(req *apistructs.SonarMetricRulesExportRequest) (httpserver.Responser, error) {
+	dbRules, err := svc.db.ListSonarMetricRules(&dao.QASonarMetricRules{
+		ScopeType: req.ScopeType,
+		ScopeID:   req.ScopeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return httpserver.OkResp(exportDocument(dbRules))
+}
+
+// Import 导入指标规则文件，未知的指标会被拒绝
+func (svc *Service) Import(req *apistructs.SonarMetricRulesImportRequest) (httpserver.Responser, error) {
+	if req.Mode == "" {
+		req.Mode = apistructs.SonarMetricRulesImportModeMerge
+	}
+	if req.Mode != apistructs.SonarMetricRulesImportModeMerge && req.Mode != apistructs.SonarMetricRulesImportModeReplace {
+		return nil, apierrors.ErrImportSonarMetricRules.InvalidParameter(fmt.Sprintf("mode: %s", req.Mode))
+	}
+	if req.Document.Version > apistructs.SonarMetricRulesDocumentVersion {
+		return nil, apierrors.ErrImportSonarMetricRules.InvalidParameter(fmt.Sprintf("unsupported document version: %d", req.Document.Version))
+	}
+
+	dbRules, err := svc.db.ListSonarMetricRules(&dao.QASonarMetricRules{
+		ScopeType: req.ScopeType,
+		ScopeID:   req.ScopeID,
+	})
+	if err != nil {
+		return nil, apierrors.ErrImportSonarMetricRules.InternalError(err)
+	}
+
+	saves, deletes, result, err := planImport(dbRules, req)
+	if err != nil {
+		return nil, apierrors.ErrImportSonarMetricRules.InvalidParameter(err)
+	}
+	if err := svc.db.ImportSonarMetricRules(saves, deletes); err != nil {
+		return nil, apierrors.ErrImportSonarMetricRules.InternalError(err)
+	}
+
+	return httpserver.OkResp(result)
+}
+
+func exportDocument(dbRules []dao.QASonarMetricRules) apistructs.SonarMetricRulesDocument {
+	doc := apistructs.SonarMetricRulesDocument{
+		Version: apistructs.SonarMetricRulesDocumentVersion,
+		Rules:   []apistructs.SonarMetricRulesDocumentRule{},
+	}
+	for _, rule := range dbRules {
+		key := apistructs.SonarMetricKeys[rule.MetricKeyID]
+		if key == nil {
+			continue
+		}
+		doc.Rules = append(doc.Rules, apistructs.SonarMetricRulesDocumentRule{
+			MetricKey:   key.MetricKey,
+			MetricValue: rule.MetricValue,
+			Description: rule.Description,
+			IsBlocking:  rule.IsBlocking,
+		})
+	}
+	// 保证导出结果稳定，便于版本管理
+	sort.Slice(doc.Rules, func(i, j int) bool { return doc.Rules[i].MetricKey < doc.Rules[j].MetricKey })
+	return doc
+}
+
+// planImport 计算导入需要保存和删除的规则
+func planImport(dbRules []dao.QASonarMetricRules, req *apistructs.SonarMetricRulesImportRequest) ([]*dao.QASonarMetricRules, []dao.QASonarMetricRules, apistructs.SonarMetricRulesImportResult, error) {
+	var result apistructs.SonarMetricRulesImportResult
+
+	metricKeyIDs := make(map[string]int64, len(apistructs.SonarMetricKeys))
+	for id, key := range apistructs.SonarMetricKeys {
+		metricKeyIDs[key.MetricKey] = id
+	}
+	existing := make(map[int64]dao.QASonarMetricRules, len(dbRules))
+	for _, rule := range dbRules {
+		existing[rule.MetricKeyID] = rule
+	}
+
+	var saves []*dao.QASonarMetricRules
+	imported := make(map[int64]bool, len(req.Document.Rules))
+	for _, docRule := range req.Document.Rules {
+		metricKeyID, ok := metricKeyIDs[docRule.MetricKey]
+		if !ok {
+			return nil, nil, result, fmt.Errorf("unknown metric: %s", docRule.MetricKey)
+		}
+		if imported[metricKeyID] {
+			return nil, nil, result, fmt.Errorf("duplicate metric: %s", docRule.MetricKey)
+		}
+		imported[metricKeyID] = true
+
+		rule := &dao.QASonarMetricRules{
+			ScopeType:   req.ScopeType,
+			ScopeID:     req.ScopeID,
+			MetricKeyID: metricKeyID,
+		}
+		if old, ok := existing[metricKeyID]; ok {
+			*rule = old
+			result.Updated++
+		} else {
+			result.Created++
+		}
+		rule.MetricValue = docRule.MetricValue
+		rule.Description = docRule.Description
+		rule.IsBlocking = docRule.IsBlocking
+		if err := checkAndTruncatedMetricValue(rule); err != nil {
+			return nil, nil, result, err
+		}
+		saves = append(saves, rule)
+	}
+
+	var deletes []dao.QASonarMetricRules
+	if req.Mode == apistructs.SonarMetricRulesImportModeReplace {
+		for _, rule := range dbRules {
+			if !imported[rule.MetricKeyID] {
+				deletes = append(deletes, rule)
+			}
+		}
+		result.Deleted = len(deletes)
+	}
+
+	return saves, deletes, result, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sonar_metric_rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestImportExportDocument(t *testing.T) {
+	apistructs.SonarMetricKeys[2] = &apistructs.SonarMetricKey{ID: 2, MetricKey: "bugs", ValueType: "INT"}
+	apistructs.SonarMetricKeys[13] = &apistructs.SonarMetricKey{ID: 13, MetricKey: "coverage", ValueType: "PERCENT", DecimalScale: 1}
+	defer func() {
+		delete(apistructs.SonarMetricKeys, 2)
+		delete(apistructs.SonarMetricKeys, 13)
+	}()
+
+	dbRules := []dao.QASonarMetricRules{
+		{ID: 1, ScopeType: "project", ScopeID: "1", MetricKeyID: 13, MetricValue: "80.0", IsBlocking: true},
+		{ID: 2, ScopeType: "project", ScopeID: "1", MetricKeyID: 2, MetricValue: "0"},
+	}
+	doc := exportDocument(dbRules)
+	assert.Equal(t, apistructs.SonarMetricRulesDocumentVersion, doc.Version)
+	assert.Equal(t, []apistructs.SonarMetricRulesDocumentRule{
+		{MetricKey: "bugs", MetricValue: "0"},
+		{MetricKey: "coverage", MetricValue: "80.0", IsBlocking: true},
+	}, doc.Rules)
+
+	// 导入到另一个项目：只有 bugs 已存在
+	target := []dao.QASonarMetricRules{
+		{ID: 10, ScopeType: "project", ScopeID: "2", MetricKeyID: 2, MetricValue: "5"},
+	}
+	req := &apistructs.SonarMetricRulesImportRequest{
+		ScopeType: "project",
+		ScopeID:   "2",
+		Mode:      apistructs.SonarMetricRulesImportModeMerge,
+		Document: apistructs.SonarMetricRulesDocument{Rules: []apistructs.SonarMetricRulesDocumentRule{
+			{MetricKey: "coverage", MetricValue: "75"},
+		}},
+	}
+	saves, deletes, result, err := planImport(target, req)
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.SonarMetricRulesImportResult{Created: 1}, result)
+	assert.Equal(t, 1, len(saves))
+	assert.Equal(t, "75.0", saves[0].MetricValue)
+	assert.Equal(t, "2", saves[0].ScopeID)
+	assert.Empty(t, deletes)
+
+	req.Mode = apistructs.SonarMetricRulesImportModeReplace
+	req.Document = doc
+	saves, deletes, result, err = planImport(target, req)
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.SonarMetricRulesImportResult{Created: 1, Updated: 1}, result)
+	assert.Equal(t, int64(10), saves[0].ID)
+	assert.Empty(t, deletes)
+
+	req.Document.Rules = []apistructs.SonarMetricRulesDocumentRule{{MetricKey: "coverage", MetricValue: "75"}}
+	_, deletes, result, err = planImport(target, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, int64(10), deletes[0].ID)
+
+	req.Document.Rules = []apistructs.SonarMetricRulesDocumentRule{{MetricKey: "unknown", MetricValue: "1"}}
+	_, _, _, err = planImport(target, req)
+	assert.Error(t, err)
+
+	req.Document.Rules = []apistructs.SonarMetricRulesDocumentRule{{MetricKey: "bugs", MetricValue: "1"}, {MetricKey: "bugs", MetricValue: "2"}}
+	_, _, _, err = planImport(target, req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sonar_metric_rule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+// severityMetricKeyPrefix 严重级别规则的指标前缀
+const severityMetricKeyPrefix = "severity:"
+
+// EvaluateQualityGate 根据项目生效的指标规则及请求中的严重级别阈值评估本次扫描结果
+func (svc *Service) EvaluateQualityGate(req *apistructs.SonarStoreRequest) (*apistructs.SonarQualityGateResult, error) {
+	rules, err := svc.listEffectiveRules(apistructs.ProjectScopeType, strconv.FormatInt(req.ProjectID, 10))
+	if err != nil {
+		return nil, err
+	}
+	return evaluateQualityGate(rules, req), nil
+}
+
+// PushQualityGateLog 将质量门禁结果输出到流水线任务日志
+func (svc *Service) PushQualityGateLog(logID string, result *apistructs.SonarQualityGateResult) error {
+	if logID == "" {
+		return nil
+	}
+	now := time.Now().UnixNano()
+	var lines []apistructs.LogPushLine
+	for i, content := range formatQualityGateLog(result) {
+		lines = append(lines, apistructs.LogPushLine{
+			ID:        logID,
+			Source:    "job",
+			Timestamp: now + int64(i),
+			Content:   content,
+			Stream:    &apistructs.CollectorLogPushStreamStdout,
+		})
+	}
+	return svc.bdl.PushLog(&apistructs.LogPushRequest{Lines: lines})
+}
+
+func evaluateQualityGate(rules []dao.QASonarMetricRules, req *apistructs.SonarStoreRequest) *apistructs.SonarQualityGateResult {
+	result := &apistructs.SonarQualityGateResult{Passed: true}
+
+	for _, rule := range rules {
+		key := apistructs.SonarMetricKeys[rule.MetricKeyID]
+		if key == nil {
+			continue
+		}
+		actual, ok := sonarMetricValue(key.MetricKey, req.IssuesStatistics)
+		if !ok {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(rule.MetricValue, 64)
+		if err != nil {
+			continue
+		}
+		operational := apistructs.GetOperationalValue(key.Operational)
+		if !violates(operational, actual, threshold) {
+			continue
+		}
+		result.Violations = append(result.Violations, apistructs.SonarQualityGateViolation{
+			MetricKey:   key.MetricKey,
+			Operational: operational,
+			Threshold:   rule.MetricValue,
+			Actual:      strconv.FormatFloat(actual, 'f', -1, 64),
+			Blocking:    rule.IsBlocking,
+		})
+		if rule.IsBlocking {
+			result.Passed = false
+		}
+	}
+
+	counts := countIssuesBySeverity(req)
+	severities := make([]string, 0, len(req.SeverityThresholds))
+	for severity := range req.SeverityThresholds {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	for _, severity := range severities {
+		max := req.SeverityThresholds[severity]
+		if counts[strings.ToUpper(severity)] <= max {
+			continue
+		}
+		result.Violations = append(result.Violations, apistructs.SonarQualityGateViolation{
+			MetricKey:   severityMetricKeyPrefix + strings.ToUpper(severity),
+			Operational: ">",
+			Threshold:   strconv.Itoa(max),
+			Actual:      strconv.Itoa(counts[strings.ToUpper(severity)]),
+			Blocking:    true,
+		})
+		result.Passed = false
+	}
+
+	return result
+}
+
+// sonarMetricValue 从扫描统计中获取指标值，不支持的指标返回 false
+func sonarMetricValue(metricKey string, statistics apistructs.TestIssuesStatistics) (float64, bool) {
+	var raw string
+	switch metricKey {
+	case "bugs":
+		raw = statistics.Bugs
+	case "vulnerabilities":
+		raw = statistics.Vulnerabilities
+	case "code_smells":
+		raw = statistics.CodeSmells
+	case "coverage":
+		raw = statistics.Coverage
+	case "duplicated_lines_density":
+		raw = statistics.Duplications
+	default:
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(raw), "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// violates > 表示指标值大于阈值时违反规则，< 表示小于阈值时违反规则
+func violates(operational string, actual, threshold float64) bool {
+	switch operational {
+	case ">":
+		return actual > threshold
+	case "<":
+		return actual < threshold
+	default:
+		return false
+	}
+}
+
+func countIssuesBySeverity(req *apistructs.SonarStoreRequest) map[string]int {
+	counts := make(map[string]int)
+	for _, issues := range [][]*apistructs.TestIssues{req.Bugs, req.CodeSmells, req.Vulnerabilities} {
+		for _, issue := range issues {
+			if issue == nil {
+				continue
+			}
+			counts[strings.ToUpper(issue.Severity)]++
+		}
+	}
+	return counts
+}
+
+func formatQualityGateLog(result *apistructs.SonarQualityGateResult) []string {
+	var lines []string
+	for _, v := range result.Violations {
+		level := "WARN"
+		if v.Blocking {
+			level = "ERROR"
+		}
+		lines = append(lines, fmt.Sprintf("[quality gate] %s: %s is %s, expected not %s %s", level, v.MetricKey, v.Actual, v.Operational, v.Threshold))
+	}
+	if result.Passed {
+		lines = append(lines, "[quality gate] passed")
+	} else {
+		lines = append(lines, "[quality gate] failed, blocking rules violated")
+	}
+	return lines
+}
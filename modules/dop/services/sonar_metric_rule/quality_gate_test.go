@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sonar_metric_rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestEvaluateQualityGate(t *testing.T) {
+	apistructs.SonarMetricKeys[2] = &apistructs.SonarMetricKey{ID: 2, MetricKey: "bugs", Operational: "-1"}
+	apistructs.SonarMetricKeys[13] = &apistructs.SonarMetricKey{ID: 13, MetricKey: "coverage", Operational: "1"}
+	defer func() {
+		delete(apistructs.SonarMetricKeys, 2)
+		delete(apistructs.SonarMetricKeys, 13)
+	}()
+
+	rules := []dao.QASonarMetricRules{
+		{MetricKeyID: 2, MetricValue: "0", IsBlocking: false},
+		{MetricKeyID: 13, MetricValue: "80.0", IsBlocking: true},
+	}
+	req := &apistructs.SonarStoreRequest{
+		IssuesStatistics: apistructs.TestIssuesStatistics{Bugs: "3", Coverage: "85.5"},
+		Bugs:             []*apistructs.TestIssues{{Severity: "BLOCKER"}, {Severity: "MAJOR"}, {Severity: "MAJOR"}},
+	}
+
+	// bugs 规则非阻断，仅记录
+	result := evaluateQualityGate(rules, req)
+	assert.True(t, result.Passed)
+	assert.Equal(t, []apistructs.SonarQualityGateViolation{
+		{MetricKey: "bugs", Operational: ">", Threshold: "0", Actual: "3", Blocking: false},
+	}, result.Violations)
+
+	// 覆盖率低于阈值
+	req.IssuesStatistics.Coverage = "65%"
+	result = evaluateQualityGate(rules, req)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "coverage < 80.0 (actual 65)", result.BlockingViolationsMessage())
+
+	// 严重级别阈值
+	req.IssuesStatistics.Coverage = "90"
+	req.SeverityThresholds = map[string]int{"blocker": 0, "MAJOR": 2}
+	result = evaluateQualityGate(rules, req)
+	assert.False(t, result.Passed)
+	assert.Equal(t, "severity:BLOCKER > 0 (actual 1)", result.BlockingViolationsMessage())
+}
+
+func TestFormatQualityGateLog(t *testing.T) {
+	lines := formatQualityGateLog(&apistructs.SonarQualityGateResult{
+		Passed: false,
+		Violations: []apistructs.SonarQualityGateViolation{
+			{MetricKey: "coverage", Operational: "<", Threshold: "80", Actual: "65", Blocking: true},
+		},
+	})
+	assert.Equal(t, []string{
+		"[quality gate] ERROR: coverage is 65, expected not < 80",
+		"[quality gate] failed, blocking rules violated",
+	}, lines)
+}
@@ -21,10 +21,29 @@ import (
 )
 
 func (svc *Service) QueryMetricKeys(req *apistructs.SonarMetricRulesListRequest) (httpserver.Responser, error) {
+	dbRules, err := svc.listEffectiveRules(req.ScopeType, req.ScopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*apistructs.SonarMetricKey
+	for _, rule := range dbRules {
+		key := apistructs.SonarMetricKeys[rule.MetricKeyID]
+		results = append(results, &apistructs.SonarMetricKey{
+			MetricKey:   key.MetricKey,
+			Operational: getOperational(key.Operational),
+			MetricValue: rule.MetricValue,
+		})
+	}
+
+	return httpserver.OkResp(results)
+}
 
+// listEffectiveRules 获取生效的规则，未配置的指标使用平台默认规则
+func (svc *Service) listEffectiveRules(scopeType, scopeID string) ([]dao.QASonarMetricRules, error) {
 	rule := dao.QASonarMetricRules{
-		ScopeID:   req.ScopeID,
-		ScopeType: req.ScopeType,
+		ScopeID:   scopeID,
+		ScopeType: scopeType,
 	}
 
 	dbRules, err := svc.db.ListSonarMetricRules(&rule)
@@ -59,18 +78,7 @@ func (svc *Service) QueryMetricKeys(req *apistructs.SonarMetricRulesListRequest)
 			}
 		}
 	}
-
-	var results []*apistructs.SonarMetricKey
-	for _, rule := range dbRules {
-		key := apistructs.SonarMetricKeys[rule.MetricKeyID]
-		results = append(results, &apistructs.SonarMetricKey{
-			MetricKey:   key.MetricKey,
-			Operational: getOperational(key.Operational),
-			MetricValue: rule.MetricValue,
-		})
-	}
-
-	return httpserver.OkResp(results)
+	return dbRules, nil
 }
 
 // 查询 list 的时候根据实际 operational 转换成 gt 和 lt，前端看到的是 > < 但是给 sonar 服务器看的是 gt lt，数据库存储的是 -1 1
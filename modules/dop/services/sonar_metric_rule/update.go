@@ -35,6 +35,9 @@ func (svc *Service) Update(req apistructs.SonarMetricRulesUpdateRequest) (httpse
 	}
 	dbRule.MetricValue = req.MetricValue
 	dbRule.Description = req.Description
+	if req.IsBlocking != nil {
+		dbRule.IsBlocking = *req.IsBlocking
+	}
 
 	if err := checkAndTruncatedMetricValue(dbRule); err != nil {
 		return nil, err
@@ -105,6 +105,9 @@ func (svc *Service) ExecuteAPIs(req apistructs.ApiTestsActionRequest) (uint64, e
 
 	// insert labels
 	labels := make(map[string]string)
+	for k, v := range req.Labels {
+		labels[k] = v
+	}
 	labels[apistructs.LabelProjectID] = strconv.FormatInt(req.ProjectID, 10)
 	labels[apistructs.LabelOrgName] = orgName
 	labels[apistructs.LabelDiceWorkspace] = string(apistructs.TestWorkspace)
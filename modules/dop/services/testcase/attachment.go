@@ -0,0 +1,279 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	attachmentFileFrom            = "testcase-attachment"
+	attachmentNameMaxLength       = 255
+	defaultAttachmentMaxSize      = 10 << 20
+	defaultAttachmentMaxTotalSize = 50 << 20
+	attachmentSniffLength         = 512
+)
+
+// attachmentForbiddenContentTypes 浏览器会作为页面渲染并执行脚本的类型, 无论配置如何都不允许上传
+var attachmentForbiddenContentTypes = map[string]bool{
+	"text/html":              true,
+	"application/xhtml+xml":  true,
+	"image/svg+xml":          true,
+	"text/xml":               true,
+	"application/xml":        true,
+	"text/javascript":        true,
+	"application/javascript": true,
+}
+
+// CreateAttachment 上传用例或执行结果的附件, 校验文件类型与大小后保存至文件服务
+func (svc *Service) CreateAttachment(req apistructs.TestCaseAttachmentCreateRequest, r io.Reader) (*apistructs.TestCaseAttachment, error) {
+	if req.Name == "" {
+		return nil, apierrors.ErrCreateTestCaseAttachment.MissingParameter("name")
+	}
+	if err := strutil.Validate(req.Name, strutil.MaxRuneCountValidator(attachmentNameMaxLength)); err != nil {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter(err)
+	}
+
+	maxSize := attachmentMaxSize()
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InternalError(err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter(fmt.Sprintf("附件不能超过 %d 字节", maxSize))
+	}
+	if len(content) == 0 {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter("附件内容为空")
+	}
+	contentType, err := resolveAttachmentContentType(req.Name, req.ContentType, content)
+	if err != nil {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter(err)
+	}
+	if !attachmentContentTypeAllowed(contentType, conf.TestCaseAttachmentContentTypes()) {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter(fmt.Sprintf("不支持的附件类型: %s", contentType))
+	}
+
+	totalSize, err := svc.db.SumTestCaseAttachmentSize(req.TestCaseID)
+	if err != nil {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InternalError(err)
+	}
+	maxTotalSize := attachmentMaxTotalSize()
+	if totalSize+int64(len(content)) > maxTotalSize {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter(fmt.Sprintf("用例附件总大小不能超过 %d 字节", maxTotalSize))
+	}
+
+	file, err := svc.bdl.UploadFile(apistructs.FileUploadRequest{
+		FileNameWithExt: req.Name,
+		ByteSize:        int64(len(content)),
+		FileReader:      ioutil.NopCloser(bytes.NewReader(content)),
+		From:            attachmentFileFrom,
+		Creator:         req.UserID,
+	})
+	if err != nil {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InternalError(err)
+	}
+
+	attachment := dao.TestCaseAttachment{
+		ProjectID:         req.ProjectID,
+		TestCaseID:        req.TestCaseID,
+		TestPlanCaseRelID: req.TestPlanCaseRelID,
+		Name:              req.Name,
+		ContentType:       contentType,
+		Size:              int64(len(content)),
+		FileUUID:          file.UUID,
+		URL:               file.DownloadURL,
+		CreatorID:         req.UserID,
+	}
+	// 上传期间可能有并发上传, 写入时在锁内重新校验总大小
+	created, err := svc.db.CreateTestCaseAttachmentWithinQuota(&attachment, maxTotalSize)
+	if err != nil || !created {
+		svc.deleteAttachmentFile(file.UUID)
+	}
+	if err != nil {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InternalError(err)
+	}
+	if !created {
+		return nil, apierrors.ErrCreateTestCaseAttachment.InvalidParameter(fmt.Sprintf("用例附件总大小不能超过 %d 字节", maxTotalSize))
+	}
+	result := attachment.Convert()
+	return &result, nil
+}
+
+// ListAttachments 查询用例本身或指定执行结果的附件
+func (svc *Service) ListAttachments(target apistructs.TestCaseAttachmentTarget) ([]apistructs.TestCaseAttachment, error) {
+	attachments, err := svc.db.ListTestCaseAttachments(target.TestCaseID, target.TestPlanCaseRelID)
+	if err != nil {
+		return nil, apierrors.ErrListTestCaseAttachments.InternalError(err)
+	}
+	results := make([]apistructs.TestCaseAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		results = append(results, attachment.Convert())
+	}
+	return results, nil
+}
+
+// DeleteAttachment 删除附件, 附件需属于 target
+func (svc *Service) DeleteAttachment(target apistructs.TestCaseAttachmentTarget, attachmentID uint64) error {
+	attachment, err := svc.db.GetTestCaseAttachment(attachmentID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return apierrors.ErrDeleteTestCaseAttachment.NotFound()
+		}
+		return apierrors.ErrDeleteTestCaseAttachment.InternalError(err)
+	}
+	if attachment.TestCaseID != target.TestCaseID || attachment.TestPlanCaseRelID != target.TestPlanCaseRelID {
+		return apierrors.ErrDeleteTestCaseAttachment.NotFound()
+	}
+	if err := svc.db.DeleteTestCaseAttachment(attachmentID); err != nil {
+		return apierrors.ErrDeleteTestCaseAttachment.InternalError(err)
+	}
+	svc.deleteAttachmentFile(attachment.FileUUID)
+	return nil
+}
+
+// listAttachmentsByTestCaseIDs 批量查询用例本身的附件, 按用例 ID 分组
+func (svc *Service) listAttachmentsByTestCaseIDs(testCaseIDs []uint64) (map[uint64][]apistructs.TestCaseAttachment, error) {
+	attachments, err := svc.db.ListTestCaseAttachmentsByTestCaseIDs(testCaseIDs, false)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[uint64][]apistructs.TestCaseAttachment)
+	for _, attachment := range attachments {
+		results[attachment.TestCaseID] = append(results[attachment.TestCaseID], attachment.Convert())
+	}
+	return results, nil
+}
+
+// cleanAttachments 删除用例及其执行结果的全部附件
+func (svc *Service) cleanAttachments(testCaseIDs []uint64) error {
+	attachments, err := svc.db.ListTestCaseAttachmentsByTestCaseIDs(testCaseIDs, true)
+	if err != nil {
+		return err
+	}
+	if err := svc.db.DeleteTestCaseAttachmentsByTestCaseIDs(testCaseIDs); err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		svc.deleteAttachmentFile(attachment.FileUUID)
+	}
+	return nil
+}
+
+// deleteAttachmentFile 删除文件服务中的附件文件, 失败时仅记录日志
+func (svc *Service) deleteAttachmentFile(uuid string) {
+	if uuid == "" {
+		return
+	}
+	if err := svc.bdl.DeleteDiceFile(uuid); err != nil {
+		logrus.Errorf("failed to delete test case attachment file, uuid: %s, err: %v", uuid, err)
+	}
+}
+
+// resolveAttachmentContentType 根据内容识别附件类型, 声明的类型需与识别结果一致;
+// 文件名后缀及声明、识别的类型均不能是浏览器会渲染执行的类型
+func resolveAttachmentContentType(name, declared string, content []byte) (string, error) {
+	if extType, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(name))); err == nil && attachmentForbiddenContentTypes[extType] {
+		return "", fmt.Errorf("不支持的附件后缀: %s", filepath.Ext(name))
+	}
+	if len(content) > attachmentSniffLength {
+		content = content[:attachmentSniffLength]
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if attachmentForbiddenContentTypes[sniffed] {
+		return "", fmt.Errorf("不支持的附件类型: %s", sniffed)
+	}
+	declaredType, _, err := mime.ParseMediaType(declared)
+	if err != nil || declaredType == "application/octet-stream" {
+		return sniffed, nil
+	}
+	declaredType = strings.ToLower(declaredType)
+	if attachmentForbiddenContentTypes[declaredType] {
+		return "", fmt.Errorf("不支持的附件类型: %s", declaredType)
+	}
+	if !attachmentContentTypeMatched(declaredType, sniffed) {
+		return "", fmt.Errorf("附件内容与声明的类型 %s 不一致", declaredType)
+	}
+	return declaredType, nil
+}
+
+// attachmentContentTypeMatched 判断声明的类型与内容识别的类型是否一致;
+// 识别只能区分常见格式, 文本内容可声明为具体的文本类型, zip 内容可声明为基于 zip 的文档类型
+func attachmentContentTypeMatched(declared, sniffed string) bool {
+	if declared == sniffed {
+		return true
+	}
+	declaredMajor := strings.SplitN(declared, "/", 2)[0]
+	switch sniffed {
+	case "text/plain":
+		return declaredMajor == "text" || declared == "application/json"
+	case "application/zip":
+		return declaredMajor == "application"
+	case "application/octet-stream":
+		// 无法识别的二进制内容不能声明为文本或多媒体类型
+		return declaredMajor == "application"
+	}
+	// 多媒体类型允许具体格式有差异, 例如 image/jpg 与 image/jpeg
+	switch declaredMajor {
+	case "image", "audio", "video":
+		return strings.HasPrefix(sniffed, declaredMajor+"/")
+	}
+	return false
+}
+
+// attachmentContentTypeAllowed 判断附件类型是否在允许列表中, 未配置时不限制
+func attachmentContentTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, pattern := range allowed {
+		if pattern == contentType {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func attachmentMaxSize() int64 {
+	if max := conf.TestCaseAttachmentMaxSize(); max > 0 {
+		return max
+	}
+	return defaultAttachmentMaxSize
+}
+
+func attachmentMaxTotalSize() int64 {
+	if max := conf.TestCaseAttachmentMaxTotalSize(); max > 0 {
+		return max
+	}
+	return defaultAttachmentMaxTotalSize
+}
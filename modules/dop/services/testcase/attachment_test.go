@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestResolveAttachmentContentType(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A" + "rest of image")
+	resolve := func(name, declared string, content []byte) string {
+		contentType, err := resolveAttachmentContentType(name, declared, content)
+		assert.NoError(t, err)
+		return contentType
+	}
+	assert.Equal(t, "image/png", resolve("a.png", "IMAGE/PNG", png))
+	assert.Equal(t, "image/png", resolve("a.png", "application/octet-stream", png))
+	// 未声明类型的日志文件根据内容识别为文本
+	assert.Equal(t, "text/plain", resolve("a.log", "", []byte("2021-09-30 ERROR something failed\n")))
+	assert.Equal(t, "text/csv", resolve("a.csv", "text/csv", []byte("a,b\n1,2\n")))
+	assert.Equal(t, "application/octet-stream", resolve("a.bin", "", []byte{0x00, 0x01, 0x02}))
+	assert.Equal(t, "image/jpg", resolve("a.jpg", "image/jpg", []byte("\xFF\xD8\xFFrest of image")))
+}
+
+func TestResolveAttachmentContentTypeRejected(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A" + "rest of image")
+	html := []byte("<html><script>alert(1)</script></html>")
+	svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+	for _, c := range []struct {
+		name     string
+		declared string
+		content  []byte
+	}{
+		// 声明的类型与内容不一致
+		{"a.png", "image/png", html},
+		{"a.png", "image/png", []byte("plain text")},
+		{"a.txt", "text/plain", png},
+		// 可在浏览器中执行脚本的类型
+		{"a.txt", "", html},
+		{"a.txt", "text/plain", svg},
+		{"a.svg", "image/svg+xml", []byte("<svg></svg>")},
+		{"a.html", "", []byte("plain text")},
+		{"a.svg", "text/plain", []byte("<svg></svg>")},
+	} {
+		_, err := resolveAttachmentContentType(c.name, c.declared, c.content)
+		assert.Error(t, err, c.name, c.declared)
+	}
+}
+
+func TestAttachmentContentTypeAllowed(t *testing.T) {
+	allowed := []string{"image/*", "application/json"}
+	assert.True(t, attachmentContentTypeAllowed("image/png", allowed))
+	assert.True(t, attachmentContentTypeAllowed("application/json", allowed))
+	assert.False(t, attachmentContentTypeAllowed("application/json5", allowed))
+	assert.False(t, attachmentContentTypeAllowed("imagex/png", allowed))
+	assert.False(t, attachmentContentTypeAllowed("application/octet-stream", allowed))
+	assert.True(t, attachmentContentTypeAllowed("application/octet-stream", nil))
+}
+
+func TestFormatExcelAttachments(t *testing.T) {
+	assert.Equal(t, "", formatExcelAttachments(nil))
+	assert.Equal(t, "a.png: http://a\nb.log: http://b", formatExcelAttachments([]apistructs.TestCaseAttachment{
+		{Name: "a.png", URL: "http://a"},
+		{Name: "b.log", URL: "http://b"},
+	}))
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/dlock"
+)
+
+const (
+	// auditLogPurgeBatchSize 每批清理的审计日志数量
+	auditLogPurgeBatchSize = 500
+	// auditLogPurgeInterval 审计日志清理周期
+	auditLogPurgeInterval = time.Hour
+	// auditLogPurgeDLockKey 保证多副本部署时只有一个实例执行清理
+	auditLogPurgeDLockKey = "/devops/dop/testcase/audit-log/purge/lock"
+	// auditLogPurgeWaitIfLostDLock 获取或丢失分布式锁后重试前的等待时间
+	auditLogPurgeWaitIfLostDLock = time.Minute
+)
+
+// ListTestCaseAuditLogs 按时间倒序分页查询测试用例审计日志
+func (svc *Service) ListTestCaseAuditLogs(req apistructs.TestCaseAuditLogListRequest) (*apistructs.TestCaseAuditLogListResponseData, error) {
+	if req.ProjectID == 0 {
+		return nil, apierrors.ErrListTestCaseAuditLogs.MissingParameter("projectID")
+	}
+	if req.TestCaseID == 0 {
+		return nil, apierrors.ErrListTestCaseAuditLogs.MissingParameter("testCaseID")
+	}
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	logs, total, err := svc.db.PagingTestCaseAuditLogs(req.ProjectID, req.TestCaseID, req.PageNo, req.PageSize)
+	if err != nil {
+		return nil, apierrors.ErrListTestCaseAuditLogs.InternalError(err)
+	}
+	result := apistructs.TestCaseAuditLogListResponseData{
+		Total: total,
+		List:  make([]apistructs.TestCaseAuditLog, 0, len(logs)),
+	}
+	for _, l := range logs {
+		result.List = append(result.List, l.Convert())
+	}
+	return &result, nil
+}
+
+// RunTestCaseAuditLogPurger 获取分布式锁后定时清理过期审计日志，锁丢失时停止并重新竞争
+func (svc *Service) RunTestCaseAuditLogPurger() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lock, err := dlock.New(
+		auditLogPurgeDLockKey,
+		func() {
+			logrus.Errorf("[alert] dlock lost, stop current testcase audit log purger")
+			cancel()
+			time.Sleep(auditLogPurgeWaitIfLostDLock)
+			logrus.Warn("try to continue testcase audit log purger again")
+			go svc.RunTestCaseAuditLogPurger()
+		},
+		dlock.WithTTL(30),
+	)
+	if err != nil {
+		logrus.Errorf("[alert] failed to get dlock, err: %v", err)
+		time.Sleep(auditLogPurgeWaitIfLostDLock)
+		go svc.RunTestCaseAuditLogPurger()
+		return
+	}
+	if err := lock.Lock(context.Background()); err != nil {
+		logrus.Errorf("[alert] failed to lock dlock, err: %v", err)
+		time.Sleep(auditLogPurgeWaitIfLostDLock)
+		go svc.RunTestCaseAuditLogPurger()
+		return
+	}
+	defer func() {
+		_ = lock.UnlockAndClose()
+	}()
+
+	logrus.Info("testcase audit log purger: start")
+	ticker := time.NewTicker(auditLogPurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("stop testcase audit log purger, received cancel signal from channel")
+			return
+		case <-ticker.C:
+			svc.PurgeTestCaseAuditLogs()
+		}
+	}
+}
+
+// PurgeTestCaseAuditLogs 删除超过保留天数的审计日志
+func (svc *Service) PurgeTestCaseAuditLogs() {
+	retentionDays := conf.TestCaseAuditLogRetentionDays()
+	if retentionDays <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -retentionDays)
+	for {
+		deleted, err := svc.db.DeleteTestCaseAuditLogsBefore(before, auditLogPurgeBatchSize)
+		if err != nil {
+			logrus.Errorf("failed to purge testcase audit logs, err: %v", err)
+			return
+		}
+		if deleted < auditLogPurgeBatchSize {
+			return
+		}
+	}
+}
+
+// recordAuditLogs 记录审计日志，需与用例变更在同一事务中调用，保证变更与审计日志同时成功或回滚
+func (svc *Service) recordAuditLogs(logs []dao.TestCaseAuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	if err := svc.db.BatchCreateTestCaseAuditLogs(logs); err != nil {
+		return fmt.Errorf("failed to record testcase audit logs, err: %v", err)
+	}
+	return nil
+}
+
+func newAuditLog(tc dao.TestCase, action apistructs.TestCaseAuditAction, identity apistructs.IdentityInfo,
+	changes []apistructs.TestCaseAuditFieldChange) dao.TestCaseAuditLog {
+	operatorID := identity.UserID
+	if operatorID == "" {
+		operatorID = identity.InternalClient
+	}
+	return dao.TestCaseAuditLog{
+		ProjectID:  tc.ProjectID,
+		TestCaseID: tc.ID,
+		Action:     action,
+		OperatorID: operatorID,
+		Changes:    changes,
+	}
+}
+
+// batchUpdateAuditAction 批量更新对应的审计操作类型，回收/恢复优先于移动
+func batchUpdateAuditAction(req apistructs.TestCaseBatchUpdateRequest) apistructs.TestCaseAuditAction {
+	switch {
+	case req.Recycled != nil && *req.Recycled:
+		return apistructs.TestCaseAuditActionRecycle
+	case req.Recycled != nil:
+		return apistructs.TestCaseAuditActionRecover
+	case req.MoveToTestSetID != nil:
+		return apistructs.TestCaseAuditActionMove
+	default:
+		return apistructs.TestCaseAuditActionUpdate
+	}
+}
+
+// diffTestCase 计算用例字段级变更，复杂字段以 json 文本比较
+func diffTestCase(before, after dao.TestCase) []apistructs.TestCaseAuditFieldChange {
+	var changes []apistructs.TestCaseAuditFieldChange
+	add := func(field, b, a string) {
+		if b != a {
+			changes = append(changes, apistructs.TestCaseAuditFieldChange{Field: field, Before: b, After: a})
+		}
+	}
+	add("name", before.Name, after.Name)
+	add("priority", string(before.Priority), string(after.Priority))
+	add("preCondition", before.PreCondition, after.PreCondition)
+	add("stepAndResults", formatAuditStepAndResults(before.StepAndResults), formatAuditStepAndResults(after.StepAndResults))
+	add("desc", before.Desc, after.Desc)
+	add("testSetID", strconv.FormatUint(before.TestSetID, 10), strconv.FormatUint(after.TestSetID, 10))
+	add("recycled", formatAuditRecycled(before.Recycled), formatAuditRecycled(after.Recycled))
+	return changes
+}
+
+func formatAuditStepAndResults(sr dao.TestCaseStepAndResults) string {
+	if len(sr) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(sr)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func formatAuditRecycled(recycled *bool) string {
+	return strconv.FormatBool(recycled != nil && *recycled)
+}
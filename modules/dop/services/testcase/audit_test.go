@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestDiffTestCase(t *testing.T) {
+	before := dao.TestCase{
+		Name:           "login",
+		Priority:       apistructs.TestCasePriorityP1,
+		StepAndResults: dao.TestCaseStepAndResults{{Step: "open", Result: "ok"}},
+		TestSetID:      1,
+	}
+	after := before
+	after.Priority = apistructs.TestCasePriorityP0
+	after.StepAndResults = dao.TestCaseStepAndResults{{Step: "open", Result: "shown"}}
+	after.TestSetID = 2
+
+	changes := diffTestCase(before, after)
+	assert.Equal(t, []apistructs.TestCaseAuditFieldChange{
+		{Field: "priority", Before: "P1", After: "P0"},
+		{Field: "stepAndResults", Before: `[{"step":"open","result":"ok"}]`, After: `[{"step":"open","result":"shown"}]`},
+		{Field: "testSetID", Before: "1", After: "2"},
+	}, changes)
+
+	assert.Empty(t, diffTestCase(before, before))
+}
+
+func TestBatchUpdateAuditAction(t *testing.T) {
+	yes, no := true, false
+	var testSetID uint64 = 1
+	assert.Equal(t, apistructs.TestCaseAuditActionRecycle, batchUpdateAuditAction(apistructs.TestCaseBatchUpdateRequest{Recycled: &yes}))
+	assert.Equal(t, apistructs.TestCaseAuditActionRecover, batchUpdateAuditAction(apistructs.TestCaseBatchUpdateRequest{Recycled: &no, MoveToTestSetID: &testSetID}))
+	assert.Equal(t, apistructs.TestCaseAuditActionMove, batchUpdateAuditAction(apistructs.TestCaseBatchUpdateRequest{MoveToTestSetID: &testSetID}))
+	assert.Equal(t, apistructs.TestCaseAuditActionUpdate, batchUpdateAuditAction(apistructs.TestCaseBatchUpdateRequest{Priority: apistructs.TestCasePriorityP2}))
+}
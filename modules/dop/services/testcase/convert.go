@@ -68,10 +68,13 @@ func (svc *Service) batchConvertTestCases(projectID uint64, models []dao.TestCas
 			BugIDs:         nil,
 			LabelIDs:       nil,
 			Attachments:    nil,
-			StepAndResults: model.StepAndResults,
+			StepAndResults: normalizeStepIDs(model.StepAndResults),
 			Labels:         nil,
 			APIs:           apis[model.ID],
 			APICount:       apiCount,
+			CopiedFromID:   model.CopiedFromID,
+			ReviewState:    model.ReviewState,
+			LockVersion:    model.LockVersion,
 			CreatedAt:      model.CreatedAt,
 			UpdatedAt:      model.UpdatedAt,
 		}
@@ -16,22 +16,30 @@ package testcase
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/jinzhu/gorm"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 )
 
+// BatchCopyTestCases 批量复制测试用例到目标测试集，目标测试集可以位于其他项目，返回新建的用例 ID 列表
 func (svc *Service) BatchCopyTestCases(req apistructs.TestCaseBatchCopyRequest) ([]uint64, error) {
-	// 校验目标测试集是否存在
+	// 校验目标测试集是否存在，目标项目以目标测试集所属项目为准
 	if req.CopyToTestSetID > 0 {
-		if _, err := svc.db.GetTestSetByID(req.CopyToTestSetID); err != nil {
+		ts, err := svc.db.GetTestSetByID(req.CopyToTestSetID)
+		if err != nil {
 			if gorm.IsRecordNotFoundError(err) {
 				return nil, apierrors.ErrBatchCopyTestCases.InvalidParameter(fmt.Sprintf("testset not found, id: %d", req.CopyToTestSetID))
 			}
 			return nil, apierrors.ErrBatchCopyTestCases.InvalidParameter(fmt.Sprintf("failed to find testset, id: %d, err: %v", req.CopyToTestSetID, err))
 		}
+		if ts.Recycled {
+			return nil, apierrors.ErrBatchCopyTestCases.InvalidParameter(fmt.Sprintf("testset is recycled, id: %d", req.CopyToTestSetID))
+		}
+		req.ProjectID = ts.ProjectID
 	}
 
 	// 校验项目 ID
@@ -48,6 +56,23 @@ func (svc *Service) BatchCopyTestCases(req apistructs.TestCaseBatchCopyRequest)
 		return nil, apierrors.ErrBatchCopyTestCases.InvalidParameter(err)
 	}
 
+	// 鉴权：目标项目需要写权限，跨项目复制时源项目需要读权限
+	if !req.IsInternalClient() {
+		if err := svc.checkCopyPermission(req.UserID, req.ProjectID, apistructs.CreateAction); err != nil {
+			return nil, err
+		}
+		checkedProjects := map[uint64]bool{req.ProjectID: true}
+		for _, dbTc := range fromTestCases {
+			if checkedProjects[dbTc.ProjectID] {
+				continue
+			}
+			if err := svc.checkCopyPermission(req.UserID, dbTc.ProjectID, apistructs.GetAction); err != nil {
+				return nil, err
+			}
+			checkedProjects[dbTc.ProjectID] = true
+		}
+	}
+
 	// 批量复制 -> 批量创建
 	batchCreateReq := apistructs.TestCaseBatchCreateRequest{
 		ProjectID:    req.ProjectID,
@@ -55,14 +80,15 @@ func (svc *Service) BatchCopyTestCases(req apistructs.TestCaseBatchCopyRequest)
 		IdentityInfo: req.IdentityInfo,
 	}
 	for _, dbTc := range fromTestCases {
-		//// 若目标测试集和当前测试集相同，则不复制
-		//if dbTc.TestSetID == req.CopyToTestSetID {
-		//	continue
-		//}
 		tc, err := svc.convertTestCase(dbTc)
 		if err != nil {
 			return nil, err
 		}
+		// 标签属于项目，跨项目复制时不保留
+		labelIDs := tc.LabelIDs
+		if dbTc.ProjectID != req.ProjectID {
+			labelIDs = nil
+		}
 		batchCreateReq.TestCases = append(batchCreateReq.TestCases, apistructs.TestCaseCreateRequest{
 			ProjectID:      req.ProjectID,
 			TestSetID:      req.CopyToTestSetID,
@@ -72,9 +98,55 @@ func (svc *Service) BatchCopyTestCases(req apistructs.TestCaseBatchCopyRequest)
 			APIs:           tc.APIs,
 			Desc:           tc.Desc,
 			Priority:       tc.Priority,
-			LabelIDs:       tc.LabelIDs,
+			LabelIDs:       labelIDs,
+			CopiedFromID:   tc.ID,
 			IdentityInfo:   req.IdentityInfo,
 		})
 	}
-	return svc.BatchCreateTestCases(batchCreateReq)
+	var newIDs []uint64
+	if err := svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		var err error
+		if newIDs, err = txSvc.BatchCreateTestCases(batchCreateReq); err != nil {
+			return err
+		}
+
+		// 审计日志记录在新用例上，BatchCreateTestCases 按请求顺序返回新用例 ID
+		var auditLogs []dao.TestCaseAuditLog
+		for i, newID := range newIDs {
+			newTc := dao.TestCase{ProjectID: req.ProjectID, TestSetID: req.CopyToTestSetID}
+			newTc.ID = newID
+			auditLogs = append(auditLogs, newAuditLog(newTc, apistructs.TestCaseAuditActionCopy, req.IdentityInfo,
+				[]apistructs.TestCaseAuditFieldChange{{
+					Field:  "copiedFromID",
+					Before: "0",
+					After:  strconv.FormatUint(fromTestCases[i].ID, 10),
+				}}))
+		}
+		if err := txSvc.recordAuditLogs(auditLogs); err != nil {
+			return apierrors.ErrBatchCopyTestCases.InternalError(err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return newIDs, nil
+}
+
+func (svc *Service) checkCopyPermission(userID string, projectID uint64, action string) error {
+	access, err := svc.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   userID,
+		Scope:    apistructs.ProjectScope,
+		ScopeID:  projectID,
+		Resource: apistructs.TestPlanResource,
+		Action:   action,
+	})
+	if err != nil {
+		return apierrors.ErrBatchCopyTestCases.InternalError(err)
+	}
+	if !access.Access {
+		return apierrors.ErrBatchCopyTestCases.AccessDenied()
+	}
+	return nil
 }
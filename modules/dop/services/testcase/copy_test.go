@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestBatchCopyTestCases(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open("mysql", sqlDB)
+	assert.NoError(t, err)
+	defer gormDB.Close()
+
+	var (
+		db        = &dao.DBClient{DBEngine: &dbengine.DBEngine{DB: gormDB}}
+		bdl       = bundle.New()
+		targetSet dao.TestSet
+		checks    []apistructs.PermissionCheckRequest
+		denied    = map[uint64]bool{}
+		createReq apistructs.TestCaseBatchCreateRequest
+	)
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetTestSetByID", func(_ *dao.DBClient, id uint64) (*dao.TestSet, error) {
+		ts := targetSet
+		ts.ID = id
+		return &ts, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ListTestCasesByIDs", func(_ *dao.DBClient, ids []uint64) ([]dao.TestCase, error) {
+		var tcs []dao.TestCase
+		for _, id := range ids {
+			// 奇数 ID 的用例属于项目 1, 偶数 ID 的用例属于项目 2
+			tc := dao.TestCase{Name: "case", ProjectID: 2 - id%2, Priority: apistructs.TestCasePriorityP1}
+			tc.ID = id
+			tcs = append(tcs, tc)
+		}
+		return tcs, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "CheckPermission", func(_ *bundle.Bundle, req *apistructs.PermissionCheckRequest) (*apistructs.PermissionCheckResponseData, error) {
+		checks = append(checks, *req)
+		return &apistructs.PermissionCheckResponseData{Access: !denied[req.ScopeID]}, nil
+	})
+	svc := New(WithDBClient(db), WithBundle(bdl))
+	monkey.PatchInstanceMethod(reflect.TypeOf(svc), "BatchListAPIs", func(_ *Service, _ uint64, _ []uint64) (map[uint64][]*apistructs.ApiTestInfo, error) {
+		return nil, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(svc), "BatchCreateTestCases", func(_ *Service, req apistructs.TestCaseBatchCreateRequest) ([]uint64, error) {
+		createReq = req
+		var ids []uint64
+		for i := range req.TestCases {
+			ids = append(ids, uint64(100+i))
+		}
+		return ids, nil
+	})
+	defer monkey.UnpatchAll()
+
+	reset := func(ts dao.TestSet) {
+		targetSet = ts
+		checks = nil
+		denied = map[uint64]bool{}
+		createReq = apistructs.TestCaseBatchCreateRequest{}
+	}
+	req := apistructs.TestCaseBatchCopyRequest{
+		CopyToTestSetID: 10,
+		ProjectID:       1,
+		TestCaseIDs:     []uint64{1, 2},
+		IdentityInfo:    apistructs.IdentityInfo{UserID: "1"},
+	}
+
+	t.Run("copy into test set of another project", func(t *testing.T) {
+		reset(dao.TestSet{ProjectID: 3})
+		// 新用例的审计日志与用例在同一事务中写入, 记录复制来源
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO `dice_test_case_audit_logs`").WithArgs(
+			"COPY", `[{"field":"copiedFromID","before":"0","after":"1"}]`, sqlmock.AnyArg(), "1", 3, 100, sqlmock.AnyArg(),
+			"COPY", `[{"field":"copiedFromID","before":"0","after":"2"}]`, sqlmock.AnyArg(), "1", 3, 101, sqlmock.AnyArg(),
+		).WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+		newIDs, err := svc.BatchCopyTestCases(req)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{100, 101}, newIDs)
+
+		// 目标项目以目标测试集为准, 目标项目需要创建权限, 每个源项目需要查看权限
+		assert.Equal(t, []apistructs.PermissionCheckRequest{
+			{UserID: "1", Scope: apistructs.ProjectScope, ScopeID: 3, Resource: apistructs.TestPlanResource, Action: apistructs.CreateAction},
+			{UserID: "1", Scope: apistructs.ProjectScope, ScopeID: 1, Resource: apistructs.TestPlanResource, Action: apistructs.GetAction},
+			{UserID: "1", Scope: apistructs.ProjectScope, ScopeID: 2, Resource: apistructs.TestPlanResource, Action: apistructs.GetAction},
+		}, checks)
+
+		assert.Equal(t, uint64(3), createReq.ProjectID)
+		assert.Len(t, createReq.TestCases, 2)
+		for i, tc := range createReq.TestCases {
+			assert.Equal(t, uint64(3), tc.ProjectID)
+			assert.Equal(t, uint64(10), tc.TestSetID)
+			assert.Equal(t, req.TestCaseIDs[i], tc.CopiedFromID)
+			// 跨项目复制不保留标签
+			assert.Empty(t, tc.LabelIDs)
+		}
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("target test set recycled", func(t *testing.T) {
+		reset(dao.TestSet{ProjectID: 3, Recycled: true})
+		_, err := svc.BatchCopyTestCases(req)
+		assert.Error(t, err)
+		assert.Empty(t, checks)
+		assert.Empty(t, createReq.TestCases)
+	})
+
+	t.Run("no create permission on target project", func(t *testing.T) {
+		reset(dao.TestSet{ProjectID: 3})
+		denied[3] = true
+		_, err := svc.BatchCopyTestCases(req)
+		assert.Error(t, err)
+		assert.Empty(t, createReq.TestCases)
+	})
+
+	t.Run("no get permission on source project", func(t *testing.T) {
+		reset(dao.TestSet{ProjectID: 3})
+		denied[2] = true
+		_, err := svc.BatchCopyTestCases(req)
+		assert.Error(t, err)
+		assert.Len(t, checks, 3)
+		assert.Empty(t, createReq.TestCases)
+	})
+}
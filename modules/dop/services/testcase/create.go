@@ -17,6 +17,8 @@ package testcase
 import (
 	"fmt"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
@@ -26,18 +28,19 @@ import (
 func (svc *Service) CreateTestCase(req apistructs.TestCaseCreateRequest) (uint64, error) {
 	// 参数检查
 	if req.Name == "" {
-		return 0, apierrors.ErrCreateTestCase.MissingParameter("name")
+		return 0, apierrors.ErrCreateTestCase.MissingParameter("name").AppendDetail("name", "required")
 	}
 	if req.Priority == "" {
-		return 0, apierrors.ErrCreateTestCase.MissingParameter("priority")
+		return 0, apierrors.ErrCreateTestCase.MissingParameter("priority").AppendDetail("priority", "required")
 	}
 	if !req.Priority.IsValid() {
-		return 0, apierrors.ErrCreateTestCase.InvalidParameter(fmt.Sprintf("priority: %s", req.Priority))
+		return 0, apierrors.ErrCreateTestCase.InvalidParameter(fmt.Sprintf("priority: %s", req.Priority)).
+			AppendDetail("priority", fmt.Sprintf("invalid value %s", req.Priority))
 	}
 
 	tc := dao.TestCase{
 		Name:           req.Name,
-		StepAndResults: dao.TestCaseStepAndResults(req.StepAndResults),
+		StepAndResults: normalizeStepIDs(req.StepAndResults),
 		From:           apistructs.TestCaseFromManual,
 		ProjectID:      req.ProjectID,
 		CreatorID:      req.IdentityInfo.UserID,
@@ -47,6 +50,8 @@ func (svc *Service) CreateTestCase(req apistructs.TestCaseCreateRequest) (uint64
 		Desc:           req.Desc,
 		TestSetID:      req.TestSetID,
 		Priority:       req.Priority,
+		CopiedFromID:   req.CopiedFromID,
+		ReviewState:    apistructs.TestCaseReviewStateDraft,
 	}
 	if err := svc.db.CreateTestCase(&tc); err != nil {
 		return 0, apierrors.ErrCreateTestCase.InternalError(fmt.Errorf("failed to insert testcase into database, err: %v", err))
@@ -59,6 +64,11 @@ func (svc *Service) CreateTestCase(req apistructs.TestCaseCreateRequest) (uint64
 		}
 	}
 
+	// 记录初始版本
+	if err := svc.recordTestCaseVersion(&tc, req.UserID, 0); err != nil {
+		logrus.Errorf("failed to record testcase version after create, err: %v", err)
+	}
+
 	return uint64(tc.ID), nil
 }
 
@@ -0,0 +1,287 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const (
+	// duplicateCandidatesLimit 参与重复检测的用例上限，检测需两两比较；超过上限时只检测最新的用例并在结果中标记 truncated
+	duplicateCandidatesLimit  = 2000
+	defaultDuplicateThreshold = 0.85
+	// 标题与步骤在相似度中的权重，两个用例都没有步骤时只比较标题
+	duplicateNameWeight  = 0.6
+	duplicateStepsWeight = 0.4
+)
+
+// DetectDuplicateTestCases 检测测试集 (包含子测试集) 内标题、步骤高度相似的用例，按重复用例组分页返回
+func (svc *Service) DetectDuplicateTestCases(req apistructs.TestCaseDuplicateDetectRequest) (*apistructs.TestCaseDuplicateDetectResponseData, error) {
+	if req.ProjectID == 0 {
+		return nil, apierrors.ErrDetectDuplicateTestCases.MissingParameter("projectID")
+	}
+	if req.Threshold < 0 || req.Threshold > 1 {
+		return nil, apierrors.ErrDetectDuplicateTestCases.InvalidParameter("threshold should be in (0, 1]")
+	}
+	if req.Threshold == 0 {
+		req.Threshold = duplicateThreshold()
+	}
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	if !req.IsInternalClient() {
+		access, err := svc.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   req.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  req.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return nil, apierrors.ErrDetectDuplicateTestCases.InternalError(err)
+		}
+		if !access.Access {
+			return nil, apierrors.ErrDetectDuplicateTestCases.AccessDenied()
+		}
+	}
+
+	_, testSets, err := svc.db.ListTestSetsRecursive(apistructs.TestSetListRequest{
+		ParentID:  &req.TestSetID,
+		ProjectID: &req.ProjectID,
+	})
+	if err != nil {
+		return nil, apierrors.ErrDetectDuplicateTestCases.InternalError(err)
+	}
+	dirs := make(map[uint64]string, len(testSets))
+	for _, ts := range testSets {
+		dirs[ts.ID] = ts.Directory
+	}
+	var testSetIDs []uint64
+	if req.TestSetID != 0 {
+		for _, ts := range testSets {
+			testSetIDs = append(testSetIDs, ts.ID)
+		}
+		if len(testSetIDs) == 0 {
+			return &apistructs.TestCaseDuplicateDetectResponseData{Threshold: req.Threshold}, nil
+		}
+	}
+
+	// 多查询一条用于判断是否超过检测上限
+	tcs, err := svc.db.SearchTestCases(req.ProjectID, testSetIDs, req.ExcludeTestSetIDs, nil, nil, duplicateCandidatesLimit+1)
+	if err != nil {
+		return nil, apierrors.ErrDetectDuplicateTestCases.InternalError(err)
+	}
+	truncated := len(tcs) > duplicateCandidatesLimit
+	if truncated {
+		tcs = tcs[:duplicateCandidatesLimit]
+	}
+
+	clusters := clusterDuplicateTestCases(tcs, req.Threshold)
+	for i := range clusters {
+		for j := range clusters[i].TestCases {
+			clusters[i].TestCases[j].Directory = dirs[clusters[i].TestCases[j].TestSetID]
+		}
+	}
+
+	result := apistructs.TestCaseDuplicateDetectResponseData{Total: uint64(len(clusters)), Threshold: req.Threshold, Truncated: truncated}
+	offset := (req.PageNo - 1) * req.PageSize
+	if offset < int64(len(clusters)) {
+		end := offset + req.PageSize
+		if end > int64(len(clusters)) {
+			end = int64(len(clusters))
+		}
+		result.List = clusters[offset:end]
+	}
+	return &result, nil
+}
+
+// caseFingerprint 用例标题及步骤归一化后的字符 bigram 集合
+type caseFingerprint struct {
+	name  map[string]struct{}
+	steps map[string]struct{}
+}
+
+func newCaseFingerprint(tc dao.TestCase) caseFingerprint {
+	var steps strings.Builder
+	for _, sr := range tc.StepAndResults {
+		steps.WriteString(sr.Step)
+		steps.WriteString(sr.Result)
+	}
+	return caseFingerprint{
+		name:  bigrams(normalizeForSimilarity(tc.Name)),
+		steps: bigrams(normalizeForSimilarity(steps.String())),
+	}
+}
+
+// clusterDuplicateTestCases 两两计算相似度，将相似度不低于阈值的用例合并为一组
+func clusterDuplicateTestCases(tcs []dao.TestCase, threshold float64) []apistructs.TestCaseDuplicateCluster {
+	fingerprints := make([]caseFingerprint, len(tcs))
+	for i, tc := range tcs {
+		fingerprints[i] = newCaseFingerprint(tc)
+	}
+
+	parents := make([]int, len(tcs))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	pairScores := make(map[int]float64)
+	for i := 0; i < len(tcs); i++ {
+		for j := i + 1; j < len(tcs); j++ {
+			if similarityUpperBound(fingerprints[i], fingerprints[j]) < threshold {
+				continue
+			}
+			score := similarity(fingerprints[i], fingerprints[j])
+			if score < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parents[rj] = ri
+			}
+			pairScores[i] = math.Max(pairScores[i], score)
+			pairScores[j] = math.Max(pairScores[j], score)
+		}
+	}
+
+	groups := make(map[int]*apistructs.TestCaseDuplicateCluster)
+	var roots []int
+	for i, tc := range tcs {
+		if _, ok := pairScores[i]; !ok {
+			continue
+		}
+		root := find(i)
+		cluster, ok := groups[root]
+		if !ok {
+			cluster = &apistructs.TestCaseDuplicateCluster{}
+			groups[root] = cluster
+			roots = append(roots, root)
+		}
+		cluster.Score = math.Max(cluster.Score, pairScores[i])
+		cluster.TestCases = append(cluster.TestCases, apistructs.TestCaseDuplicateItem{
+			ID:        uint64(tc.ID),
+			Name:      tc.Name,
+			Priority:  tc.Priority,
+			TestSetID: tc.TestSetID,
+		})
+	}
+
+	clusters := make([]apistructs.TestCaseDuplicateCluster, 0, len(roots))
+	for _, root := range roots {
+		cluster := groups[root]
+		cluster.Score = math.Round(cluster.Score*1000) / 1000
+		sort.Slice(cluster.TestCases, func(i, j int) bool { return cluster.TestCases[i].ID < cluster.TestCases[j].ID })
+		clusters = append(clusters, *cluster)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		return clusters[i].TestCases[0].ID < clusters[j].TestCases[0].ID
+	})
+	return clusters
+}
+
+// similarity 标题与步骤 Dice 系数的加权平均
+func similarity(a, b caseFingerprint) float64 {
+	nameScore := dice(a.name, b.name)
+	if len(a.steps) == 0 && len(b.steps) == 0 {
+		return nameScore
+	}
+	return duplicateNameWeight*nameScore + duplicateStepsWeight*dice(a.steps, b.steps)
+}
+
+// similarityUpperBound 仅根据集合大小估算相似度上限，用于跳过明显不相似的用例
+func similarityUpperBound(a, b caseFingerprint) float64 {
+	nameBound := diceUpperBound(len(a.name), len(b.name))
+	if len(a.steps) == 0 && len(b.steps) == 0 {
+		return nameBound
+	}
+	return duplicateNameWeight*nameBound + duplicateStepsWeight*diceUpperBound(len(a.steps), len(b.steps))
+}
+
+func dice(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var intersection int
+	for gram := range a {
+		if _, ok := b[gram]; ok {
+			intersection++
+		}
+	}
+	return 2 * float64(intersection) / float64(len(a)+len(b))
+}
+
+func diceUpperBound(m, n int) float64 {
+	if m == 0 && n == 0 {
+		return 1
+	}
+	if m > n {
+		m, n = n, m
+	}
+	return 2 * float64(m) / float64(m+n)
+}
+
+// normalizeForSimilarity 转为小写并去除标点、空白等字符
+func normalizeForSimilarity(s string) []rune {
+	var runes []rune
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+// bigrams 相邻字符组成的集合，只有一个字符时使用该字符本身
+func bigrams(runes []rune) map[string]struct{} {
+	grams := make(map[string]struct{})
+	if len(runes) == 1 {
+		grams[string(runes)] = struct{}{}
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+func duplicateThreshold() float64 {
+	if threshold := conf.TestCaseDuplicateThreshold(); threshold > 0 && threshold <= 1 {
+		return threshold
+	}
+	return defaultDuplicateThreshold
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func newDuplicateTestCase(id uint64, name string, steps ...string) dao.TestCase {
+	tc := dao.TestCase{BaseModel: dbengine.BaseModel{ID: id}, Name: name, TestSetID: 1}
+	for _, step := range steps {
+		tc.StepAndResults = append(tc.StepAndResults, apistructs.TestCaseStepAndResult{Step: step})
+	}
+	return tc
+}
+
+func TestSimilarity(t *testing.T) {
+	a := newCaseFingerprint(newDuplicateTestCase(1, "用户登录-密码错误", "输入错误密码", "点击登录"))
+	b := newCaseFingerprint(newDuplicateTestCase(2, "用户登录 密码错误！", "输入错误密码", "点击登录"))
+	c := newCaseFingerprint(newDuplicateTestCase(3, "导出订单列表", "点击导出"))
+
+	// 标点、空白不影响相似度
+	assert.Equal(t, 1.0, similarity(a, b))
+	assert.True(t, similarity(a, c) < 0.2)
+	assert.True(t, similarityUpperBound(a, c) >= similarity(a, c))
+
+	// 都没有步骤时只比较标题
+	d := newCaseFingerprint(newDuplicateTestCase(4, "Login Failed"))
+	e := newCaseFingerprint(newDuplicateTestCase(5, "login failed"))
+	assert.Equal(t, 1.0, similarity(d, e))
+}
+
+func TestClusterDuplicateTestCases(t *testing.T) {
+	tcs := []dao.TestCase{
+		newDuplicateTestCase(1, "用户登录-密码错误", "输入错误密码", "点击登录"),
+		newDuplicateTestCase(2, "导出订单列表", "点击导出"),
+		newDuplicateTestCase(3, "用户登录 密码错误", "输入错误密码", "点击登录"),
+		newDuplicateTestCase(4, "导出订单列表。", "点击导出"),
+		newDuplicateTestCase(5, "修改个人头像", "上传图片"),
+		newDuplicateTestCase(6, "用户登录，密码错误", "输入错误的密码", "点击登录"),
+	}
+
+	clusters := clusterDuplicateTestCases(tcs, 0.8)
+	assert.Len(t, clusters, 2)
+	var ids [][]uint64
+	for _, cluster := range clusters {
+		var clusterIDs []uint64
+		for _, tc := range cluster.TestCases {
+			clusterIDs = append(clusterIDs, tc.ID)
+		}
+		ids = append(ids, clusterIDs)
+		assert.Equal(t, 1.0, cluster.Score)
+	}
+	assert.Equal(t, [][]uint64{{1, 3, 6}, {2, 4}}, ids)
+
+	assert.Empty(t, clusterDuplicateTestCases(tcs[:2], 0.8))
+}
+
+func TestDetectDuplicateTestCasesTruncated(t *testing.T) {
+	var total int
+	db := &dao.DBClient{}
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ListTestSetsRecursive", func(_ *dao.DBClient, _ apistructs.TestSetListRequest) ([]uint64, []dao.TestSet, error) {
+		return nil, nil, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "SearchTestCases", func(_ *dao.DBClient, _ uint64, _, _ []uint64, _ []apistructs.TestCasePriority,
+		_ []string, limit int) ([]dao.TestCase, error) {
+		var tcs []dao.TestCase
+		for i := 0; i < total && i < limit; i++ {
+			tcs = append(tcs, newDuplicateTestCase(uint64(i+1), fmt.Sprintf("case %d", i)))
+		}
+		return tcs, nil
+	})
+	defer monkey.UnpatchAll()
+
+	svc := New(WithDBClient(db))
+	req := apistructs.TestCaseDuplicateDetectRequest{ProjectID: 1, Threshold: 1, IdentityInfo: apistructs.IdentityInfo{InternalClient: "test"}}
+
+	total = duplicateCandidatesLimit
+	result, err := svc.DetectDuplicateTestCases(req)
+	assert.NoError(t, err)
+	assert.False(t, result.Truncated)
+
+	total = duplicateCandidatesLimit + 1
+	result, err = svc.DetectDuplicateTestCases(req)
+	assert.NoError(t, err)
+	assert.True(t, result.Truncated)
+}
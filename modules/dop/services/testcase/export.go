@@ -27,7 +27,6 @@ import (
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 	"github.com/erda-project/erda/modules/dop/services/i18n"
 	"github.com/erda-project/erda/pkg/excel"
-	"github.com/erda-project/erda/pkg/xmind"
 )
 
 const (
@@ -107,20 +106,6 @@ func (svc *Service) ExportFile(record *dao.TestFileRecord) {
 }
 
 func (svc *Service) ExportTestCases(req *apistructs.TestCaseExportRequest, sheetName string) (string, error) {
-	req.PageNo = -1
-	req.PageSize = -1
-	totalResult, err := svc.PagingTestCases(req.TestCasePagingRequest)
-	if err != nil {
-		return "", err
-	}
-
-	var testCases []apistructs.TestCaseWithSimpleSetInfo
-	for _, ts := range totalResult.TestSets {
-		for _, tc := range ts.TestCases {
-			testCases = append(testCases, apistructs.TestCaseWithSimpleSetInfo{TestCase: tc, Directory: ts.Directory})
-		}
-	}
-
 	f, err := ioutil.TempFile("", "export.*")
 	if err != nil {
 		return "", apierrors.ErrExportTestCases.InternalError(err)
@@ -135,21 +120,40 @@ func (svc *Service) ExportTestCases(req *apistructs.TestCaseExportRequest, sheet
 	defer f.Close()
 
 	if req.FileType == apistructs.TestCaseFileTypeExcel {
-		excelLines, err := svc.convert2Excel(testCases, req.Locale)
+		req.PageNo = -1
+		req.PageSize = -1
+		totalResult, err := svc.PagingTestCases(req.TestCasePagingRequest)
 		if err != nil {
-			return "", apierrors.ErrExportTestCases.InternalError(err)
+			return "", err
 		}
 
-		if err := excel.Export(f, excelLines, sheetName); err != nil {
+		var testCases []apistructs.TestCaseWithSimpleSetInfo
+		for _, ts := range totalResult.TestSets {
+			for _, tc := range ts.TestCases {
+				testCases = append(testCases, apistructs.TestCaseWithSimpleSetInfo{TestCase: tc, Directory: ts.Directory})
+			}
+		}
+
+		testCaseIDs := make([]uint64, 0, len(testCases))
+		for _, tc := range testCases {
+			testCaseIDs = append(testCaseIDs, tc.ID)
+		}
+		attachments, err := svc.listAttachmentsByTestCaseIDs(testCaseIDs)
+		if err != nil {
 			return "", apierrors.ErrExportTestCases.InternalError(err)
 		}
-	} else {
-		xmindContent, err := svc.convert2XMind(testCases, req.Locale)
+
+		excelLines, err := svc.convert2Excel(testCases, attachments, req.Locale)
 		if err != nil {
 			return "", apierrors.ErrExportTestCases.InternalError(err)
 		}
 
-		if err := xmind.Export(f, xmindContent, sheetName); err != nil {
+		if err := excel.Export(f, excelLines, sheetName); err != nil {
+			return "", apierrors.ErrExportTestCases.InternalError(err)
+		}
+	} else {
+		// xmind 流式写入临时文件，用例量大时不会占用过多内存
+		if err := svc.exportXMind(f, *req); err != nil {
 			return "", apierrors.ErrExportTestCases.InternalError(err)
 		}
 	}
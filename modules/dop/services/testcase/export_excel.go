@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/erda-project/erda/apistructs"
@@ -29,7 +30,7 @@ const (
 	i18nKeyCaseAPITest = ""
 )
 
-func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, locale string) ([][]excel.Cell, error) {
+func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, attachments map[uint64][]apistructs.TestCaseAttachment, locale string) ([][]excel.Cell, error) {
 	begin := time.Now()
 	defer func() {
 		end := time.Now()
@@ -52,6 +53,7 @@ func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, lo
 		excel.EmptyCell(),
 		excel.EmptyCell(),
 		excel.EmptyCell(),
+		excel.NewVMergeCell(l.Get(i18n.I18nKeyCaseAttachments), 1),
 	}
 	title2 := []excel.Cell{
 		excel.EmptyCell(),
@@ -69,6 +71,7 @@ func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, lo
 		excel.NewCell(l.Get(i18n.I18nKeyCaseAPITestBody)),
 		excel.NewCell(l.Get(i18n.I18nKeyCaseAPITestOutParams)),
 		excel.NewCell(l.Get(i18n.I18nKeyCaseAPITestAsserts)),
+		excel.EmptyCell(),
 	}
 
 	var allTcLines [][]excel.Cell
@@ -124,6 +127,9 @@ func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, lo
 				line = append(line, excel.EmptyCells(8)...)
 			}
 
+			// 附件, 每行一个 "名称: 下载地址"
+			line = append(line, excel.NewCell(formatExcelAttachments(attachments[tc.ID])))
+
 			oneTcLines = append(oneTcLines, line)
 		}
 
@@ -131,8 +137,9 @@ func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, lo
 		if len(oneTcLines) > 0 {
 			firstLine := oneTcLines[0]
 			vMergeNum := len(oneTcLines) - 1
-			// 只合并前5列基础信息
-			for i := 0; i < 5; i++ {
+			// 只合并前5列基础信息及附件列
+			mergeColumns := []int{0, 1, 2, 3, 4, len(firstLine) - 1}
+			for _, i := range mergeColumns {
 				firstLine[i] = excel.NewVMergeCell(firstLine[i].Value, vMergeNum)
 				// 被合并的单元格数据置为空，优化文件大小
 				// e.g., 6673 bytes -> 3888 bytes
@@ -152,3 +159,11 @@ func (svc *Service) convert2Excel(tcs []apistructs.TestCaseWithSimpleSetInfo, lo
 
 	return allLines, nil
 }
+
+func formatExcelAttachments(attachments []apistructs.TestCaseAttachment) string {
+	lines := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		lines = append(lines, fmt.Sprintf("%s: %s", attachment.Name, attachment.URL))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -15,6 +15,7 @@
 package testcase
 
 import (
+	"io"
 	"sort"
 
 	"github.com/erda-project/erda/apistructs"
@@ -23,51 +24,93 @@ import (
 	"github.com/erda-project/erda/pkg/xmind"
 )
 
-func (svc *Service) convert2XMind(tcs []apistructs.TestCaseWithSimpleSetInfo, locale string) (xmind.XMLContent, error) {
-	l := svc.bdl.GetLocale(locale)
-	// 定义临时结构体
-	type TmpTestCases struct {
-		TestSetID  uint64
-		TestSetDir string
-		TestCases  []apistructs.TestCase
+// xmindExportPageSize 流式导出 xmind 时每次查询的用例数
+const xmindExportPageSize = 200
+
+// exportXMind 流式导出 xmind
+// 按目录深度优先的顺序遍历测试集，逐个测试集分页查询用例并写入，不会一次性加载全部用例
+func (svc *Service) exportXMind(w io.Writer, req apistructs.TestCaseExportRequest) error {
+	_, testSets, err := svc.db.ListTestSetsRecursive(apistructs.TestSetListRequest{
+		Recycled:      req.Recycled,
+		ParentID:      &req.TestSetID,
+		ProjectID:     &req.ProjectID,
+		NoSubTestSets: req.NoSubTestSet,
+	})
+	if err != nil {
+		return err
 	}
-	// 按照父测试集 ID 进行分类
-	tcGroupMapByTsID := make(map[uint64]*TmpTestCases)
-	for _, tc := range tcs {
-		tmp := tcGroupMapByTsID[tc.TestSetID]
-		if tmp == nil {
-			tmp = &TmpTestCases{}
-		}
-		tmp.TestSetID = tc.TestSetID
-		tmp.TestCases = append(tmp.TestCases, tc.TestCase)
-		tmp.TestSetDir = tc.Directory
-		tcGroupMapByTsID[tc.TestSetID] = tmp
+	sort.SliceStable(testSets, func(i, j int) bool {
+		return lessDirectory(strutil.Split(testSets[i].Directory, "/", true), strutil.Split(testSets[j].Directory, "/", true))
+	})
+
+	l := svc.bdl.GetLocale(req.Locale)
+	sw, err := xmind.NewStreamWriter(w, l.Get(i18n.I18nKeyTestCaseSheetName))
+	if err != nil {
+		return err
 	}
-	// 将 map 转换为 list，并用 testSetID 倒序排序
-	tmpTcsList := make([]*TmpTestCases, 0, len(tcGroupMapByTsID))
-	for _, tmp := range tcGroupMapByTsID {
-		tmpTcsList = append(tmpTcsList, tmp)
+
+	var openedDirs []string
+	for _, ts := range testSets {
+		pagingReq := req.TestCasePagingRequest
+		pagingReq.TestSetID = ts.ID
+		pagingReq.NoSubTestSet = true
+		pagingReq.PageSize = xmindExportPageSize
+		for pagingReq.PageNo = 1; ; pagingReq.PageNo++ {
+			result, err := svc.PagingTestCases(pagingReq)
+			if err != nil {
+				return err
+			}
+			for _, tsWithCases := range result.TestSets {
+				if len(tsWithCases.TestCases) == 0 {
+					continue
+				}
+				// 插入目录节点
+				if openedDirs, err = moveToXMindDirectory(sw, openedDirs, strutil.Split(ts.Directory, "/", true)); err != nil {
+					return err
+				}
+				// 插入测试用例节点
+				for _, tc := range tsWithCases.TestCases {
+					var parent xmind.XMLTopic
+					insertTestCaseTopic(&parent, tc)
+					if err := sw.WriteTopic(parent.Children.TypedTopics.Topics[0]); err != nil {
+						return err
+					}
+				}
+			}
+			if uint64(pagingReq.PageNo*pagingReq.PageSize) >= result.Total {
+				break
+			}
+		}
 	}
-	sort.Slice(tmpTcsList, func(i, j int) bool {
-		// 倒序排序
-		return tmpTcsList[i].TestSetID < tmpTcsList[j].TestSetID
-	})
 
-	var content xmind.XMLContent
-	rootTopic := &xmind.XMLTopic{Title: l.Get(i18n.I18nKeyTestCaseSheetName)}
-	content.Sheet.Topic = rootTopic
+	return sw.Close()
+}
 
-	for _, tmpTcs := range tmpTcsList {
-		// 插入目录节点
-		currentTopic := rootTopic
-		for _, dir := range strutil.Split(tmpTcs.TestSetDir, "/", true) {
-			currentTopic = currentTopic.AddAttachedChildTopic(dir, true)
+// moveToXMindDirectory 关闭与目标目录不同的已打开目录节点，再依次打开目标目录剩余的节点
+func moveToXMindDirectory(sw *xmind.StreamWriter, opened, target []string) ([]string, error) {
+	common := 0
+	for common < len(opened) && common < len(target) && opened[common] == target[common] {
+		common++
+	}
+	for i := len(opened); i > common; i-- {
+		if err := sw.EndTopic(); err != nil {
+			return nil, err
 		}
-		// 插入测试用例节点
-		for _, tc := range tmpTcs.TestCases {
-			insertTestCaseTopic(currentTopic, tc)
+	}
+	for _, dir := range target[common:] {
+		if err := sw.StartTopic(dir); err != nil {
+			return nil, err
 		}
 	}
+	return target, nil
+}
 
-	return content, nil
+// lessDirectory 按目录逐级比较，保证同一目录下的测试集连续出现
+func lessDirectory(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
 }
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/strutil"
+	"github.com/erda-project/erda/pkg/xmind"
+)
+
+func TestLessDirectory(t *testing.T) {
+	dirs := []string{"/b", "/a/c", "/", "/a", "/a b", "/a/b"}
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return lessDirectory(strutil.Split(dirs[i], "/", true), strutil.Split(dirs[j], "/", true))
+	})
+	assert.Equal(t, []string{"/", "/a", "/a/b", "/a/c", "/a b", "/b"}, dirs)
+}
+
+func TestMoveToXMindDirectory(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := xmind.NewStreamWriter(&buf, "root")
+	assert.NoError(t, err)
+
+	opened, err := moveToXMindDirectory(sw, nil, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, opened)
+	opened, err = moveToXMindDirectory(sw, opened, []string{"a", "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, opened)
+	opened, err = moveToXMindDirectory(sw, opened, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, opened)
+	// 已回到根节点，无法再关闭
+	assert.Error(t, sw.EndTopic())
+	assert.NoError(t, sw.Close())
+}
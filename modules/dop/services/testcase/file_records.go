@@ -72,6 +72,18 @@ func (svc *Service) UpdateFileRecord(req apistructs.TestFileRecordRequest) error
 	if req.State != "" {
 		r.State = req.State
 	}
+	if info := req.Extra.AutotestSpaceFileExtraInfo; info != nil && info.ImportSummary != nil {
+		if r.Extra.AutotestSpaceFileExtraInfo == nil {
+			r.Extra.AutotestSpaceFileExtraInfo = &apistructs.AutoTestSpaceFileExtraInfo{}
+		}
+		r.Extra.AutotestSpaceFileExtraInfo.ImportSummary = info.ImportSummary
+	}
+	if info := req.Extra.ManualTestFileExtraInfo; info != nil && info.ImportResult != nil {
+		if r.Extra.ManualTestFileExtraInfo == nil {
+			r.Extra.ManualTestFileExtraInfo = &apistructs.ManualTestFileExtraInfo{}
+		}
+		r.Extra.ManualTestFileExtraInfo.ImportResult = info.ImportResult
+	}
 	return svc.db.UpdateRecord(r)
 }
 
@@ -146,6 +158,12 @@ func mapping(s *dao.TestFileRecord, project, testSet string) *apistructs.TestFil
 		UpdatedAt:   s.UpdatedAt,
 		OperatorID:  s.OperatorID,
 	}
+	if info := s.Extra.AutotestSpaceFileExtraInfo; info != nil {
+		record.ImportSummary = info.ImportSummary
+	}
+	if info := s.Extra.ManualTestFileExtraInfo; info != nil {
+		record.ImportResult = info.ImportResult
+	}
 
 	if record.Type == apistructs.FileActionTypeImport || record.Type == apistructs.FileActionTypeExport {
 		record.Description = fmt.Sprintf("%v ID: %v, %v ID: %v", project, record.ProjectID, testSet, record.TestSetID)
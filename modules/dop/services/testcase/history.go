@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// ListTestCaseHistory 按版本号倒序返回测试用例的历史版本
+func (svc *Service) ListTestCaseHistory(req apistructs.TestCaseHistoryListRequest) ([]apistructs.TestCaseVersion, error) {
+	if req.TestCaseID == 0 {
+		return nil, apierrors.ErrGetTestCaseHistory.MissingParameter("testCaseID")
+	}
+	if _, err := svc.db.GetTestCaseByID(req.TestCaseID); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetTestCaseHistory.NotFound()
+		}
+		return nil, apierrors.ErrGetTestCaseHistory.InternalError(err)
+	}
+	versions, err := svc.db.ListTestCaseVersions(req.TestCaseID)
+	if err != nil {
+		return nil, apierrors.ErrGetTestCaseHistory.InternalError(err)
+	}
+	results := make([]apistructs.TestCaseVersion, 0, len(versions))
+	for _, v := range versions {
+		results = append(results, v.Convert())
+	}
+	return results, nil
+}
+
+// GetTestCaseHistoryVersion 获取测试用例指定历史版本
+func (svc *Service) GetTestCaseHistoryVersion(testCaseID, version uint64) (*apistructs.TestCaseVersion, error) {
+	v, err := svc.db.GetTestCaseVersion(testCaseID, version)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrGetTestCaseHistory.NotFound()
+		}
+		return nil, apierrors.ErrGetTestCaseHistory.InternalError(err)
+	}
+	result := v.Convert()
+	return &result, nil
+}
+
+// RestoreTestCaseHistory 将测试用例恢复至指定历史版本，恢复结果作为新版本记录，不会删除任何历史
+func (svc *Service) RestoreTestCaseHistory(req apistructs.TestCaseHistoryRestoreRequest) (*apistructs.TestCaseVersion, error) {
+	v, err := svc.db.GetTestCaseVersion(req.TestCaseID, req.Version)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrRestoreTestCaseHistory.NotFound()
+		}
+		return nil, apierrors.ErrRestoreTestCaseHistory.InternalError(err)
+	}
+	existAPIs, err := svc.ListAPIs(int64(req.TestCaseID))
+	if err != nil {
+		return nil, apierrors.ErrRestoreTestCaseHistory.InternalError(err)
+	}
+
+	if err := svc.UpdateTestCase(apistructs.TestCaseUpdateRequest{
+		ID:                  req.TestCaseID,
+		Name:                v.Name,
+		Priority:            v.Priority,
+		PreCondition:        v.PreCondition,
+		StepAndResults:      v.StepAndResults,
+		APIs:                restoreVersionAPIs(v.APIs, existAPIs),
+		Desc:                v.Desc,
+		RestoredFromVersion: v.Version,
+		IdentityInfo:        req.IdentityInfo,
+	}); err != nil {
+		return nil, err
+	}
+
+	latest, err := svc.db.GetLatestTestCaseVersion(req.TestCaseID)
+	if err != nil {
+		return nil, apierrors.ErrRestoreTestCaseHistory.InternalError(err)
+	}
+	return svc.GetTestCaseHistoryVersion(req.TestCaseID, latest)
+}
+
+// restoreVersionAPIs 将历史版本中的接口转换为更新请求，已被删除的接口重新创建
+func restoreVersionAPIs(versionAPIs []apistructs.TestCaseVersionAPI, existAPIs []*apistructs.ApiTestInfo) []*apistructs.ApiTestInfo {
+	exists := make(map[int64]bool, len(existAPIs))
+	for _, api := range existAPIs {
+		exists[api.ApiID] = true
+	}
+	apis := make([]*apistructs.ApiTestInfo, 0, len(versionAPIs))
+	for _, api := range versionAPIs {
+		apiID := api.ApiID
+		if !exists[apiID] {
+			apiID = 0
+		}
+		apis = append(apis, &apistructs.ApiTestInfo{ApiID: apiID, ApiInfo: api.ApiInfo})
+	}
+	return apis
+}
+
+// recordTestCaseVersion 记录用例当前定义为新版本
+func (svc *Service) recordTestCaseVersion(tc *dao.TestCase, operatorID string, restoredFromVersion uint64) error {
+	apis, err := svc.ListAPIs(int64(tc.ID))
+	if err != nil {
+		return err
+	}
+	v := dao.TestCaseVersion{
+		TestCaseID:          tc.ID,
+		Name:                tc.Name,
+		Priority:            tc.Priority,
+		PreCondition:        tc.PreCondition,
+		StepAndResults:      tc.StepAndResults,
+		APIs:                make(dao.TestCaseVersionAPIs, 0, len(apis)),
+		Desc:                tc.Desc,
+		OperatorID:          operatorID,
+		RestoredFromVersion: restoredFromVersion,
+	}
+	for _, api := range apis {
+		v.APIs = append(v.APIs, apistructs.TestCaseVersionAPI{ApiID: api.ApiID, ApiInfo: api.ApiInfo})
+	}
+	if err := svc.db.CreateTestCaseVersion(&v); err != nil {
+		return fmt.Errorf("failed to create testcase version, testCaseID: %d, err: %v", tc.ID, err)
+	}
+	return nil
+}
+
+// ensureBaselineVersion 功能上线前创建的用例没有历史版本，更新前先将当前定义记录为基线版本
+func (svc *Service) ensureBaselineVersion(tc *dao.TestCase) {
+	latest, err := svc.db.GetLatestTestCaseVersion(tc.ID)
+	if err != nil {
+		logrus.Errorf("failed to get latest testcase version, testCaseID: %d, err: %v", tc.ID, err)
+		return
+	}
+	if latest > 0 {
+		return
+	}
+	if err := svc.recordTestCaseVersion(tc, tc.UpdaterID, 0); err != nil {
+		logrus.Errorf("failed to record baseline testcase version, err: %v", err)
+	}
+}
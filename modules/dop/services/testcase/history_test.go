@@ -0,0 +1,38 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestRestoreVersionAPIs(t *testing.T) {
+	versionAPIs := []apistructs.TestCaseVersionAPI{
+		{ApiID: 1, ApiInfo: "a"},
+		{ApiID: 2, ApiInfo: "b"},
+	}
+	existAPIs := []*apistructs.ApiTestInfo{{ApiID: 1}, {ApiID: 3}}
+
+	apis := restoreVersionAPIs(versionAPIs, existAPIs)
+	assert.Equal(t, []*apistructs.ApiTestInfo{
+		{ApiID: 1, ApiInfo: "a"},
+		// 已被删除的接口需要重新创建
+		{ApiID: 0, ApiInfo: "b"},
+	}, apis)
+}
@@ -35,6 +35,14 @@ func (svc *Service) Import(req apistructs.TestCaseImportRequest, r *http.Request
 	if req.ProjectID == 0 {
 		return nil, apierrors.ErrImportTestCases.MissingParameter("projectID")
 	}
+	if req.ColumnMapping != nil {
+		if req.FileType != apistructs.TestCaseFileTypeExcel {
+			return nil, apierrors.ErrImportTestCases.InvalidParameter("columnMapping only supports excel")
+		}
+		if err := req.ColumnMapping.Validate(); err != nil {
+			return nil, apierrors.ErrImportTestCases.InvalidParameter(err)
+		}
+	}
 
 	// fake ts
 	ts := dao.FakeRootTestSet(req.ProjectID, false)
@@ -101,56 +109,82 @@ func (svc *Service) ImportFile(record *dao.TestFileRecord) {
 		logrus.Error(apierrors.ErrImportTestCases.InternalError(err))
 		return
 	}
-	if err := svc.ImportTestCases(req, record.ApiFileUUID); err != nil {
+	result, err := svc.ImportTestCases(req, record.ApiFileUUID)
+	if err != nil {
 		logrus.Error(apierrors.ErrImportTestCases.InternalError(err))
 		if err := svc.UpdateFileRecord(apistructs.TestFileRecordRequest{ID: id, State: apistructs.FileRecordStateFail}); err != nil {
 			logrus.Error(apierrors.ErrImportTestCases.InternalError(err))
 		}
 		return
 	}
-	if err := svc.UpdateFileRecord(apistructs.TestFileRecordRequest{ID: id, State: apistructs.FileRecordStateSuccess}); err != nil {
+	// 全部用例都导入失败时记为失败，行级错误保存在导入结果中
+	state := apistructs.FileRecordStateSuccess
+	if result.SuccessCount == 0 && result.FailedCount > 0 {
+		state = apistructs.FileRecordStateFail
+	}
+	if err := svc.UpdateFileRecord(apistructs.TestFileRecordRequest{
+		ID:    id,
+		State: state,
+		Extra: apistructs.TestFileExtra{
+			ManualTestFileExtraInfo: &apistructs.ManualTestFileExtraInfo{
+				ImportResult: result,
+			},
+		},
+	}); err != nil {
 		logrus.Error(apierrors.ErrImportTestCases.InternalError(err))
 	}
 }
 
-func (svc *Service) ImportTestCases(req *apistructs.TestCaseImportRequest, testFileUUID string) error {
+func (svc *Service) ImportTestCases(req *apistructs.TestCaseImportRequest, testFileUUID string) (*apistructs.TestCaseImportResult, error) {
 	ts := dao.FakeRootTestSet(req.ProjectID, false)
 	if req.TestSetID != 0 {
 		_ts, err := svc.db.GetTestSetByID(req.TestSetID)
 		if err != nil {
 			if gorm.IsRecordNotFoundError(err) {
-				return apierrors.ErrImportTestCases.InvalidParameter(fmt.Errorf("testSet not found, id: %d", req.TestSetID))
+				return nil, apierrors.ErrImportTestCases.InvalidParameter(fmt.Errorf("testSet not found, id: %d", req.TestSetID))
 			}
-			return apierrors.ErrImportTestCases.InternalError(err)
+			return nil, apierrors.ErrImportTestCases.InternalError(err)
 		}
 		ts = *_ts
 	}
 	if ts.ProjectID != req.ProjectID {
-		return apierrors.ErrImportTestCases.InvalidParameter("projectID")
+		return nil, apierrors.ErrImportTestCases.InvalidParameter("projectID")
 	}
 
 	f, err := svc.bdl.DownloadDiceFile(testFileUUID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if req.FileType == apistructs.TestCaseFileTypeExcel {
-		excelTcs, err := svc.decodeFromExcelFile(f)
-		if err != nil {
-			return apierrors.ErrImportTestCases.InternalError(err)
+		var (
+			excelTcs []apistructs.TestCaseExcel
+			rowErrs  []apistructs.TestCaseImportRowError
+		)
+		if req.ColumnMapping != nil {
+			excelTcs, rowErrs, err = svc.decodeFromMappedExcelFile(f, *req.ColumnMapping)
+		} else {
+			excelTcs, rowErrs, err = svc.decodeFromExcelFile(f)
 		}
-		if _, err := svc.storeExcel2DB(*req, ts, excelTcs); err != nil {
-			return apierrors.ErrImportTestCases.InternalError(err)
-		}
-	} else {
-		xmindTcs, err := svc.decodeFromXMindFile(f)
 		if err != nil {
-			return apierrors.ErrImportTestCases.InternalError(err)
+			return nil, apierrors.ErrImportTestCases.InternalError(err)
 		}
-		if _, err := svc.storeXmind2DB(*req, ts, xmindTcs); err != nil {
-			return apierrors.ErrImportTestCases.InternalError(err)
+		result, err := svc.storeExcel2DB(*req, ts, excelTcs)
+		if err != nil {
+			return nil, apierrors.ErrImportTestCases.InternalError(err)
 		}
+		result.FailedCount += uint64(len(rowErrs))
+		result.Errors = append(rowErrs, result.Errors...)
+		return result, nil
 	}
 
-	return nil
+	xmindTcs, err := svc.decodeFromXMindFile(f)
+	if err != nil {
+		return nil, apierrors.ErrImportTestCases.InternalError(err)
+	}
+	result, err := svc.storeXmind2DB(*req, ts, xmindTcs)
+	if err != nil {
+		return nil, apierrors.ErrImportTestCases.InternalError(err)
+	}
+	return result, nil
 }
@@ -26,112 +26,129 @@ import (
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
-func (svc *Service) decodeFromExcelFile(r io.Reader) (allTestCases []apistructs.TestCaseExcel, err error) {
-	var lineTestCaseID string
-	defer func() {
-		if r := recover(); r != nil {
-			err = apierrors.ErrInvalidTestCaseExcelFormat.InternalError(fmt.Errorf("testCaseID: %s", lineTestCaseID))
-		}
-	}()
+// decodeFromExcelFile 按固定模板解析 Excel，解析失败的用例以行级错误返回，不影响其他用例
+func (svc *Service) decodeFromExcelFile(r io.Reader) ([]apistructs.TestCaseExcel, []apistructs.TestCaseImportRowError, error) {
 	sheets, err := excel.Decode(r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(sheets) == 0 {
-		return nil, fmt.Errorf("not found sheet")
+		return nil, nil, fmt.Errorf("not found sheet")
 	}
 	rows := sheets[0]
 	// 校验：至少有两行 title
 	if len(rows) < 2 {
-		return nil, fmt.Errorf("invalid title format")
+		return nil, nil, fmt.Errorf("invalid title format")
 	}
 	// 根据用例编号进行分组
 	groupedRows := make(map[string][][]string) // key: TestCaseID, value: TestCaseInfos
+	lineNums := make(map[string]int)           // key: TestCaseID, value: 用例起始行号
 	var orderedRowIDs []string
 	var currentTcID string
 	for i := 2; i < len(rows); i++ {
 		row := rows[i]
-		if row[0] != "" {
+		if len(row) > 0 && row[0] != "" {
 			currentTcID = row[0]
 		}
+		if _, ok := lineNums[currentTcID]; !ok {
+			lineNums[currentTcID] = i + 1
+		}
 		groupedRows[currentTcID] = append(groupedRows[currentTcID], row)
 		orderedRowIDs = append(orderedRowIDs, currentTcID)
 	}
 	orderedRowIDs = strutil.DedupSlice(orderedRowIDs, true)
 
 	// 操作每个分组
+	var allTestCases []apistructs.TestCaseExcel
+	var rowErrs []apistructs.TestCaseImportRowError
 	for _, testCaseID := range orderedRowIDs {
-		lineTestCaseID = testCaseID
 		rows, ok := groupedRows[testCaseID]
 		if !ok {
 			continue
 		}
-		firstLine := rows[0]
-		tcExcel := apistructs.TestCaseExcel{
-			Title:         firstLine[1],
-			DirectoryName: firstLine[2],
-			PriorityName:  firstLine[3],
-			PreCondition:  firstLine[4],
+		tcExcel, err := parseExcelTestCaseRows(testCaseID, rows)
+		if err != nil {
+			rowErrs = append(rowErrs, apistructs.TestCaseImportRowError{Row: lineNums[testCaseID], Reason: err.Error()})
+			continue
 		}
-		// 步骤与结果列表，接口测试
-		for _, row := range rows {
-			// 步骤与结果
-			if row[5] != "" {
-				tcExcel.StepAndResults = append(tcExcel.StepAndResults, apistructs.TestCaseStepAndResult{Step: row[5], Result: row[6]})
-			}
+		tcExcel.LineNum = lineNums[testCaseID]
+		allTestCases = append(allTestCases, *tcExcel)
+	}
 
-			// 接口测试
-			if len(row) > 7 && row[7] != "" {
-
-				// 接口名称
-				name := row[7]
-				// 请求头信息
-				var headers []apistructs.APIHeader
-				if err := json.Unmarshal([]byte(row[8]), &headers); err != nil {
-					return nil, fmt.Errorf("failed to parse api headers, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
-				}
-				// 方法
-				method := row[9]
-				// 接口地址
-				url := row[10]
-				// 接口参数
-				var params []apistructs.APIParam
-				if err := json.Unmarshal([]byte(row[11]), &params); err != nil {
-					return nil, fmt.Errorf("failed to parse api params, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
-				}
-				// 请求体
-				var reqBody apistructs.APIBody
-				if err := json.Unmarshal([]byte(row[12]), &reqBody); err != nil {
-					return nil, fmt.Errorf("failed to parse api request body, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
-				}
-				// out 参数
-				var outParams []apistructs.APIOutParam
-				if err := json.Unmarshal([]byte(row[13]), &outParams); err != nil {
-					return nil, fmt.Errorf("failed to parse api out params, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
-				}
-				// 断言
-				var asserts [][]apistructs.APIAssert
-				if err := json.Unmarshal([]byte(row[14]), &asserts); err != nil {
-					return nil, fmt.Errorf("failed ot parse api asserts, testCaesID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
-				}
-
-				tcExcel.ApiInfos = append(tcExcel.ApiInfos, apistructs.APIInfo{
-					Name:      name,
-					Headers:   headers,
-					Method:    method,
-					URL:       url,
-					Params:    params,
-					Body:      reqBody,
-					OutParams: outParams,
-					Asserts:   asserts,
-				})
-			}
+	return allTestCases, rowErrs, nil
+}
+
+// parseExcelTestCaseRows 解析固定模板中同一用例编号下的所有行
+func parseExcelTestCaseRows(testCaseID string, rows [][]string) (tc *apistructs.TestCaseExcel, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tc = nil
+			err = apierrors.ErrInvalidTestCaseExcelFormat.InvalidParameter(fmt.Errorf("testCaseID: %s", testCaseID))
 		}
+	}()
 
-		allTestCases = append(allTestCases, tcExcel)
+	firstLine := rows[0]
+	tcExcel := apistructs.TestCaseExcel{
+		Title:         firstLine[1],
+		DirectoryName: firstLine[2],
+		PriorityName:  firstLine[3],
+		PreCondition:  firstLine[4],
 	}
+	// 步骤与结果列表，接口测试
+	for _, row := range rows {
+		// 步骤与结果
+		if row[5] != "" {
+			tcExcel.StepAndResults = append(tcExcel.StepAndResults, apistructs.TestCaseStepAndResult{Step: row[5], Result: row[6]})
+		}
+
+		// 接口测试
+		if len(row) > 7 && row[7] != "" {
+
+			// 接口名称
+			name := row[7]
+			// 请求头信息
+			var headers []apistructs.APIHeader
+			if err := json.Unmarshal([]byte(row[8]), &headers); err != nil {
+				return nil, fmt.Errorf("failed to parse api headers, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
+			}
+			// 方法
+			method := row[9]
+			// 接口地址
+			url := row[10]
+			// 接口参数
+			var params []apistructs.APIParam
+			if err := json.Unmarshal([]byte(row[11]), &params); err != nil {
+				return nil, fmt.Errorf("failed to parse api params, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
+			}
+			// 请求体
+			var reqBody apistructs.APIBody
+			if err := json.Unmarshal([]byte(row[12]), &reqBody); err != nil {
+				return nil, fmt.Errorf("failed to parse api request body, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
+			}
+			// out 参数
+			var outParams []apistructs.APIOutParam
+			if err := json.Unmarshal([]byte(row[13]), &outParams); err != nil {
+				return nil, fmt.Errorf("failed to parse api out params, testCaseID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
+			}
+			// 断言
+			var asserts [][]apistructs.APIAssert
+			if err := json.Unmarshal([]byte(row[14]), &asserts); err != nil {
+				return nil, fmt.Errorf("failed ot parse api asserts, testCaesID: %s, name: %s, err: %v", testCaseID, tcExcel.Title, err)
+			}
 
-	return allTestCases, nil
+			tcExcel.ApiInfos = append(tcExcel.ApiInfos, apistructs.APIInfo{
+				Name:      name,
+				Headers:   headers,
+				Method:    method,
+				URL:       url,
+				Params:    params,
+				Body:      reqBody,
+				OutParams: outParams,
+				Asserts:   asserts,
+			})
+		}
+	}
+	return &tcExcel, nil
 }
 
 func (svc *Service) storeExcel2DB(req apistructs.TestCaseImportRequest, rootTestSet dao.TestSet, tcs []apistructs.TestCaseExcel) (*apistructs.TestCaseImportResult, error) {
@@ -144,66 +161,79 @@ func (svc *Service) storeExcel2DB(req apistructs.TestCaseImportRequest, rootTest
 		}
 	}
 
+	var result apistructs.TestCaseImportResult
 	for _, tc := range tcs {
-		// create testset
-		// split excel directory，在 targetTestSetDir/ 下创建对应的测试集
-		parentID := rootTestSet.ID
-		tc.DirectoryName = strutil.Trim(tc.DirectoryName)
-		dirList := strutil.Split(strutil.TrimPrefixes(tc.DirectoryName, "/"), "/")
-		for _, dir := range dirList {
-			if dir == "" {
-				continue
-			}
-			existTs, err := svc.db.GetTestSetByNameAndParentIDAndProjectID(rootTestSet.ProjectID, parentID, false, dir)
-			if err != nil {
-				return nil, err
-			}
-			if existTs != nil {
-				parentID = existTs.ID
-			} else {
-				// create testSet
-				testSet, err := svc.CreateTestSetFn(apistructs.TestSetCreateRequest{
-					Name:         dir,
-					ProjectID:    &rootTestSet.ProjectID,
-					ParentID:     &parentID,
-					IdentityInfo: req.IdentityInfo,
-				})
-				if err != nil {
-					return nil, err
-				}
-				parentID = testSet.ID
-			}
+		if err := svc.storeExcelTestCase(req, rootTestSet, tc); err != nil {
+			result.FailedCount++
+			result.Errors = append(result.Errors, apistructs.TestCaseImportRowError{
+				Row:    tc.LineNum,
+				Reason: fmt.Sprintf("failed to import test case %s, err: %v", tc.Title, err),
+			})
+			continue
 		}
+		if tc.Title != fakeTcName {
+			result.SuccessCount++
+		}
+	}
 
-		// 过滤只用来创建空子测试集的 fake tc
-		if tc.Title == fakeTcName {
+	return &result, nil
+}
+
+// storeExcelTestCase 创建用例及其所在的测试集
+func (svc *Service) storeExcelTestCase(req apistructs.TestCaseImportRequest, rootTestSet dao.TestSet, tc apistructs.TestCaseExcel) error {
+	// create testset
+	// split excel directory，在 targetTestSetDir/ 下创建对应的测试集
+	parentID := rootTestSet.ID
+	tc.DirectoryName = strutil.Trim(tc.DirectoryName)
+	dirList := strutil.Split(strutil.TrimPrefixes(tc.DirectoryName, "/"), "/")
+	for _, dir := range dirList {
+		if dir == "" {
 			continue
 		}
-
-		var apiTests []*apistructs.ApiTestInfo
-		for _, apiInfo := range tc.ApiInfos {
-			apiInfoBytes, _ := json.Marshal(apiInfo)
-			apiTests = append(apiTests, &apistructs.ApiTestInfo{
-				ApiInfo: string(apiInfoBytes),
+		existTs, err := svc.db.GetTestSetByNameAndParentIDAndProjectID(rootTestSet.ProjectID, parentID, false, dir)
+		if err != nil {
+			return err
+		}
+		if existTs != nil {
+			parentID = existTs.ID
+		} else {
+			// create testSet
+			testSet, err := svc.CreateTestSetFn(apistructs.TestSetCreateRequest{
+				Name:         dir,
+				ProjectID:    &rootTestSet.ProjectID,
+				ParentID:     &parentID,
+				IdentityInfo: req.IdentityInfo,
 			})
+			if err != nil {
+				return err
+			}
+			parentID = testSet.ID
 		}
+	}
 
-		tcCreateReq := apistructs.TestCaseCreateRequest{
-			ProjectID:      rootTestSet.ProjectID,
-			TestSetID:      parentID,
-			Name:           tc.Title,
-			PreCondition:   tc.PreCondition,
-			StepAndResults: tc.StepAndResults,
-			APIs:           apiTests,
-			Priority:       apistructs.TestCasePriority(tc.PriorityName),
-			IdentityInfo:   req.IdentityInfo,
-		}
-		if _, err := svc.CreateTestCase(tcCreateReq); err != nil {
-			return nil, err
-		}
+	// 过滤只用来创建空子测试集的 fake tc
+	if tc.Title == fakeTcName {
+		return nil
 	}
 
-	importResult := apistructs.TestCaseImportResult{SuccessCount: uint64(len(tcs))}
+	var apiTests []*apistructs.ApiTestInfo
+	for _, apiInfo := range tc.ApiInfos {
+		apiInfoBytes, _ := json.Marshal(apiInfo)
+		apiTests = append(apiTests, &apistructs.ApiTestInfo{
+			ApiInfo: string(apiInfoBytes),
+		})
+	}
 
-	return &importResult, nil
+	tcCreateReq := apistructs.TestCaseCreateRequest{
+		ProjectID:      rootTestSet.ProjectID,
+		TestSetID:      parentID,
+		Name:           tc.Title,
+		PreCondition:   tc.PreCondition,
+		StepAndResults: tc.StepAndResults,
+		APIs:           apiTests,
+		Priority:       apistructs.TestCasePriority(tc.PriorityName),
+		IdentityInfo:   req.IdentityInfo,
+	}
+	_, err := svc.CreateTestCase(tcCreateReq)
+	return err
 }
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/pkg/excel"
+)
+
+var excelColumnLetterRegexp = regexp.MustCompile(`^[A-Z]{1,3}$`)
+
+// excelColumnIndexes 映射后各字段所在的列下标，未映射的字段为 -1
+type excelColumnIndexes struct {
+	title        int
+	directory    int
+	preCondition int
+	step         int
+	result       int
+	priority     int
+}
+
+// decodeFromMappedExcelFile 按自定义列映射解析 Excel
+// 标题不为空的行开始一个新用例，标题为空的行作为上一个用例的后续步骤
+func (svc *Service) decodeFromMappedExcelFile(r io.Reader, mapping apistructs.TestCaseExcelColumnMapping) ([]apistructs.TestCaseExcel, []apistructs.TestCaseImportRowError, error) {
+	if err := mapping.Validate(); err != nil {
+		return nil, nil, err
+	}
+	sheets, err := excel.Decode(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sheets) == 0 {
+		return nil, nil, fmt.Errorf("not found sheet")
+	}
+	return parseMappedExcelRows(sheets[0], mapping)
+}
+
+func parseMappedExcelRows(rows [][]string, mapping apistructs.TestCaseExcelColumnMapping) ([]apistructs.TestCaseExcel, []apistructs.TestCaseImportRowError, error) {
+	headerRows := mapping.HeaderRows
+	if headerRows == 0 {
+		headerRows = 1
+	}
+	if len(rows) < headerRows {
+		return nil, nil, fmt.Errorf("invalid title format")
+	}
+	indexes, err := resolveExcelColumnIndexes(rows[:headerRows], mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		tcs     []apistructs.TestCaseExcel
+		rowErrs []apistructs.TestCaseImportRowError
+		current *apistructs.TestCaseExcel
+	)
+	for i := headerRows; i < len(rows); i++ {
+		row := rows[i]
+		title := excelCell(row, indexes.title)
+		step := excelCell(row, indexes.step)
+		result := excelCell(row, indexes.result)
+
+		if title != "" {
+			if current != nil {
+				tcs = append(tcs, *current)
+			}
+			current = &apistructs.TestCaseExcel{
+				Title:         title,
+				DirectoryName: excelCell(row, indexes.directory),
+				PriorityName:  excelCell(row, indexes.priority),
+				PreCondition:  excelCell(row, indexes.preCondition),
+				LineNum:       i + 1,
+			}
+		} else if step == "" && result == "" {
+			// 空行
+			continue
+		} else if current == nil {
+			rowErrs = append(rowErrs, apistructs.TestCaseImportRowError{Row: i + 1, Reason: "missing test case title"})
+			continue
+		}
+
+		if step != "" || result != "" {
+			current.StepAndResults = append(current.StepAndResults, apistructs.TestCaseStepAndResult{Step: step, Result: result})
+		}
+	}
+	if current != nil {
+		tcs = append(tcs, *current)
+	}
+	return tcs, rowErrs, nil
+}
+
+func resolveExcelColumnIndexes(headers [][]string, mapping apistructs.TestCaseExcelColumnMapping) (*excelColumnIndexes, error) {
+	var (
+		indexes excelColumnIndexes
+		err     error
+	)
+	resolve := func(column string, index *int) {
+		if err != nil {
+			return
+		}
+		*index, err = resolveExcelColumn(headers, column)
+	}
+	resolve(mapping.Title, &indexes.title)
+	resolve(mapping.Directory, &indexes.directory)
+	resolve(mapping.PreCondition, &indexes.preCondition)
+	resolve(mapping.Step, &indexes.step)
+	resolve(mapping.Result, &indexes.result)
+	resolve(mapping.Priority, &indexes.priority)
+	if err != nil {
+		return nil, err
+	}
+	return &indexes, nil
+}
+
+// resolveExcelColumn 优先按表头名称匹配，其次按列号解析，未映射时返回 -1
+func resolveExcelColumn(headers [][]string, column string) (int, error) {
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return -1, nil
+	}
+	for _, header := range headers {
+		for i, name := range header {
+			if strings.TrimSpace(name) == column {
+				return i, nil
+			}
+		}
+	}
+	if excelColumnLetterRegexp.MatchString(column) {
+		var index int
+		for _, c := range column {
+			index = index*26 + int(c-'A'+1)
+		}
+		return index - 1, nil
+	}
+	return -1, fmt.Errorf("column %s not found in header", column)
+}
+
+func excelCell(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
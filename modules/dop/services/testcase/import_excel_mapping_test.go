@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/schema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestParseMappedExcelRows(t *testing.T) {
+	rows := [][]string{
+		{"Case", "Given", "Do", "Expect", "Level"},
+		{"login", "user exists", "open page", "page shown", "P1"},
+		{"", "", "submit", "logged in", ""},
+		{"", "", "", "", ""},
+		{"logout", "", "click logout", "", "P2"},
+	}
+	mapping := apistructs.TestCaseExcelColumnMapping{
+		Title:        "Case",
+		PreCondition: "B",
+		Step:         "Do",
+		Result:       "Expect",
+		Priority:     "Level",
+	}
+	tcs, rowErrs, err := parseMappedExcelRows(rows, mapping)
+	assert.NoError(t, err)
+	assert.Empty(t, rowErrs)
+	assert.Equal(t, 2, len(tcs))
+	assert.Equal(t, "login", tcs[0].Title)
+	assert.Equal(t, "user exists", tcs[0].PreCondition)
+	assert.Equal(t, "P1", tcs[0].PriorityName)
+	assert.Equal(t, 2, tcs[0].LineNum)
+	assert.Equal(t, []apistructs.TestCaseStepAndResult{
+		{Step: "open page", Result: "page shown"},
+		{Step: "submit", Result: "logged in"},
+	}, tcs[0].StepAndResults)
+	assert.Equal(t, "logout", tcs[1].Title)
+	assert.Equal(t, 5, tcs[1].LineNum)
+}
+
+func TestParseMappedExcelRows_RowErrors(t *testing.T) {
+	rows := [][]string{
+		{"Title", "Step"},
+		{"", "orphan step"},
+		{"case", "step"},
+	}
+	tcs, rowErrs, err := parseMappedExcelRows(rows, apistructs.TestCaseExcelColumnMapping{Title: "A", Step: "B"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tcs))
+	assert.Equal(t, []apistructs.TestCaseImportRowError{{Row: 2, Reason: "missing test case title"}}, rowErrs)
+
+	_, _, err = parseMappedExcelRows(rows, apistructs.TestCaseExcelColumnMapping{Title: "Name"})
+	assert.Error(t, err)
+}
+
+func TestResolveExcelColumn(t *testing.T) {
+	headers := [][]string{{"Title", "Step"}}
+	idx, err := resolveExcelColumn(headers, "Step")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idx)
+	idx, err = resolveExcelColumn(headers, "AA")
+	assert.NoError(t, err)
+	assert.Equal(t, 26, idx)
+	idx, err = resolveExcelColumn(headers, "")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, idx)
+	_, err = resolveExcelColumn(headers, "unknown")
+	assert.Error(t, err)
+}
+
+func TestTestCaseExcelColumnMappingValidate(t *testing.T) {
+	assert.Error(t, apistructs.TestCaseExcelColumnMapping{Step: "A"}.Validate())
+	assert.Error(t, apistructs.TestCaseExcelColumnMapping{Title: "A", Result: "B"}.Validate())
+	assert.NoError(t, apistructs.TestCaseExcelColumnMapping{Title: "A", Step: "B", Result: "C"}.Validate())
+}
+
+func TestDecodeColumnMappingFromQuery(t *testing.T) {
+	values := url.Values{}
+	values.Set("projectID", "1")
+	values.Set("fileType", "excel")
+	values.Set("columnMapping.title", "Case")
+	values.Set("columnMapping.step", "C")
+	values.Set("columnMapping.headerRows", "2")
+
+	var req apistructs.TestCaseImportRequest
+	decoder := schema.NewDecoder()
+	decoder.IgnoreUnknownKeys(true)
+	assert.NoError(t, decoder.Decode(&req, values))
+	assert.NotNil(t, req.ColumnMapping)
+	assert.Equal(t, "Case", req.ColumnMapping.Title)
+	assert.Equal(t, "C", req.ColumnMapping.Step)
+	assert.Equal(t, 2, req.ColumnMapping.HeaderRows)
+}
@@ -122,6 +122,11 @@ func (svc *Service) PagingTestCases(req apistructs.TestCasePagingRequest) (*apis
 			return nil, apierrors.ErrPagingTestCases.InvalidParameter(fmt.Sprintf("priority: %s", priority))
 		}
 	}
+	for _, state := range req.ReviewStates {
+		if !state.IsValid() {
+			return nil, apierrors.ErrPagingTestCases.InvalidParameter(fmt.Sprintf("reviewState: %s", state))
+		}
+	}
 	if req.OrderByPriorityAsc != nil && req.OrderByPriorityDesc != nil {
 		return nil, apierrors.ErrPagingTestCases.InvalidParameter("order by priority ASC or DESC?")
 	}
@@ -176,6 +181,20 @@ func (svc *Service) PagingTestCases(req apistructs.TestCasePagingRequest) (*apis
 		return nil, apierrors.ErrPagingTestCases.InternalError(
 			fmt.Errorf("failed to get all children testSet, testSetID: %d, projectID: %d, err: %v", req.TestSetID, req.ProjectID, err))
 	}
+	// 排除用户无权查看的测试集
+	if len(req.ExcludeTestSetIDs) > 0 {
+		excluded := make(map[uint64]struct{}, len(req.ExcludeTestSetIDs))
+		for _, id := range req.ExcludeTestSetIDs {
+			excluded[id] = struct{}{}
+		}
+		readableTestSetIDs := make([]uint64, 0, len(allTestSetIDs))
+		for _, id := range allTestSetIDs {
+			if _, ok := excluded[id]; !ok {
+				readableTestSetIDs = append(readableTestSetIDs, id)
+			}
+		}
+		allTestSetIDs = readableTestSetIDs
+	}
 	// 测试集列表为空，直接返回
 	if len(allTestSetIDs) == 0 {
 		return &apistructs.TestCasePagingResponseData{Total: 0, TestSets: nil, UserIDs: req.UpdaterIDs}, nil
@@ -244,6 +263,10 @@ func (svc *Service) PagingTestCases(req apistructs.TestCasePagingRequest) (*apis
 	if len(req.UpdaterIDs) > 0 {
 		sql = sql.Where("`updater_id` IN (?)", req.UpdaterIDs)
 	}
+	// 评审状态 列表过滤
+	if len(req.ReviewStates) > 0 {
+		sql = sql.Where("`review_state` IN (?)", req.ReviewStates)
+	}
 	// 更新时间起始时间 左闭区间过滤
 	if req.TimestampSecUpdatedAtBegin != nil {
 		t := time.Unix(int64(*req.TimestampSecUpdatedAtBegin), 0)
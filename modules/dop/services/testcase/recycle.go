@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 )
 
@@ -45,8 +46,33 @@ func (svc *Service) BatchCleanFromRecycleBin(req apistructs.TestCaseBatchCleanFr
 		return apierrors.ErrBatchCleanTestCasesFromRecycleBin.InternalError(err)
 	}
 
-	// 批量删除测试用例
-	if err := svc.db.BatchDeleteTestCases(req.TestCaseIDs); err != nil {
+	// 批量删除历史版本
+	if err := svc.db.DeleteTestCaseVersionsByTestCaseIDs(req.TestCaseIDs); err != nil {
+		return apierrors.ErrBatchCleanTestCasesFromRecycleBin.InternalError(err)
+	}
+
+	// 批量删除评审记录
+	if err := svc.db.DeleteTestCaseReviewsByTestCaseIDs(req.TestCaseIDs); err != nil {
+		return apierrors.ErrBatchCleanTestCasesFromRecycleBin.InternalError(err)
+	}
+
+	// 批量删除附件
+	if err := svc.cleanAttachments(req.TestCaseIDs); err != nil {
+		return apierrors.ErrBatchCleanTestCasesFromRecycleBin.InternalError(err)
+	}
+
+	// 批量删除测试用例，审计日志不随用例删除，按保留策略清理
+	auditLogs := make([]dao.TestCaseAuditLog, 0, len(tcs))
+	for _, tc := range tcs {
+		auditLogs = append(auditLogs, newAuditLog(tc, apistructs.TestCaseAuditActionDelete, req.IdentityInfo, nil))
+	}
+	if err := svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		if err := txSvc.db.BatchDeleteTestCases(req.TestCaseIDs); err != nil {
+			return err
+		}
+		return txSvc.recordAuditLogs(auditLogs)
+	}); err != nil {
 		return apierrors.ErrBatchCleanTestCasesFromRecycleBin.InternalError(err)
 	}
 
@@ -0,0 +1,142 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+type reviewTransition struct {
+	from apistructs.TestCaseReviewState
+	to   apistructs.TestCaseReviewState
+}
+
+// reviewTransitions 评审操作允许的状态流转
+var reviewTransitions = map[apistructs.TestCaseReviewAction]reviewTransition{
+	apistructs.TestCaseReviewActionSubmit:  {from: apistructs.TestCaseReviewStateDraft, to: apistructs.TestCaseReviewStateInReview},
+	apistructs.TestCaseReviewActionApprove: {from: apistructs.TestCaseReviewStateInReview, to: apistructs.TestCaseReviewStateApproved},
+	apistructs.TestCaseReviewActionReject:  {from: apistructs.TestCaseReviewStateInReview, to: apistructs.TestCaseReviewStateDraft},
+}
+
+// ReviewTestCase 流转测试用例评审状态并记录操作人, 驳回时必须填写评审意见
+func (svc *Service) ReviewTestCase(req apistructs.TestCaseReviewRequest) (*apistructs.TestCaseReview, error) {
+	if req.TestCaseID == 0 {
+		return nil, apierrors.ErrReviewTestCase.MissingParameter("testCaseID")
+	}
+	transition, ok := reviewTransitions[req.Action]
+	if !ok {
+		return nil, apierrors.ErrReviewTestCase.InvalidParameter(fmt.Sprintf("action: %s", req.Action))
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if req.Action == apistructs.TestCaseReviewActionReject && req.Comment == "" {
+		return nil, apierrors.ErrReviewTestCase.MissingParameter("comment")
+	}
+
+	tc, err := svc.db.GetTestCaseByID(req.TestCaseID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrReviewTestCase.NotFound()
+		}
+		return nil, apierrors.ErrReviewTestCase.InternalError(err)
+	}
+	if tc.Recycled != nil && *tc.Recycled {
+		return nil, apierrors.ErrReviewTestCase.InvalidState("test case is in recycle bin")
+	}
+	if tc.ReviewState != transition.from {
+		return nil, apierrors.ErrReviewTestCase.InvalidState(
+			fmt.Sprintf("cannot %s test case in review state %s", strings.ToLower(string(req.Action)), tc.ReviewState))
+	}
+	if req.Action == apistructs.TestCaseReviewActionApprove {
+		reviews, err := svc.db.ListTestCaseReviews(req.TestCaseID)
+		if err != nil {
+			return nil, apierrors.ErrReviewTestCase.InternalError(err)
+		}
+		if isSelfApproval(req.UserID, *tc, reviews) {
+			return nil, apierrors.ErrReviewTestCase.AccessDenied()
+		}
+	}
+
+	review := dao.TestCaseReview{
+		TestCaseID: req.TestCaseID,
+		Action:     req.Action,
+		FromState:  transition.from,
+		ToState:    transition.to,
+		OperatorID: req.UserID,
+		Comment:    req.Comment,
+	}
+	transited, err := svc.db.TransitTestCaseReviewState(&review)
+	if err != nil {
+		return nil, apierrors.ErrReviewTestCase.InternalError(err)
+	}
+	// 查询后状态被并发修改
+	if !transited {
+		return nil, apierrors.ErrReviewTestCase.InvalidState("review state has been changed, please refresh and retry")
+	}
+	result := review.Convert()
+	return &result, nil
+}
+
+// isSelfApproval 提交评审的人及最后修改用例的人不能评审通过自己的用例, reviews 按时间倒序
+func isSelfApproval(userID string, tc dao.TestCase, reviews []dao.TestCaseReview) bool {
+	if userID == tc.UpdaterID {
+		return true
+	}
+	for _, review := range reviews {
+		if review.Action == apistructs.TestCaseReviewActionSubmit {
+			return userID == review.OperatorID
+		}
+	}
+	return false
+}
+
+// resetApprovedReviewState 评审通过的用例内容被修改后回到草稿状态, 需要重新提交评审
+func resetApprovedReviewState(before dao.TestCase, after *dao.TestCase) {
+	if before.ReviewState != apistructs.TestCaseReviewStateApproved {
+		return
+	}
+	if before.Name != after.Name || before.PreCondition != after.PreCondition || before.Desc != after.Desc ||
+		formatAuditStepAndResults(before.StepAndResults) != formatAuditStepAndResults(after.StepAndResults) {
+		after.ReviewState = apistructs.TestCaseReviewStateDraft
+	}
+}
+
+// ListTestCaseReviews 按时间倒序返回测试用例的评审记录
+func (svc *Service) ListTestCaseReviews(testCaseID uint64) ([]apistructs.TestCaseReview, error) {
+	if testCaseID == 0 {
+		return nil, apierrors.ErrListTestCaseReviews.MissingParameter("testCaseID")
+	}
+	if _, err := svc.db.GetTestCaseByID(testCaseID); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrListTestCaseReviews.NotFound()
+		}
+		return nil, apierrors.ErrListTestCaseReviews.InternalError(err)
+	}
+	reviews, err := svc.db.ListTestCaseReviews(testCaseID)
+	if err != nil {
+		return nil, apierrors.ErrListTestCaseReviews.InternalError(err)
+	}
+	results := make([]apistructs.TestCaseReview, 0, len(reviews))
+	for _, r := range reviews {
+		results = append(results, r.Convert())
+	}
+	return results, nil
+}
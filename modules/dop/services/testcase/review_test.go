@@ -0,0 +1,90 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestReviewTransitions(t *testing.T) {
+	assert.Equal(t, reviewTransition{from: apistructs.TestCaseReviewStateDraft, to: apistructs.TestCaseReviewStateInReview},
+		reviewTransitions[apistructs.TestCaseReviewActionSubmit])
+	assert.Equal(t, reviewTransition{from: apistructs.TestCaseReviewStateInReview, to: apistructs.TestCaseReviewStateApproved},
+		reviewTransitions[apistructs.TestCaseReviewActionApprove])
+	// 驳回后回到草稿状态, 修改后可重新提交评审
+	assert.Equal(t, reviewTransition{from: apistructs.TestCaseReviewStateInReview, to: apistructs.TestCaseReviewStateDraft},
+		reviewTransitions[apistructs.TestCaseReviewActionReject])
+}
+
+func TestReviewTestCaseValidate(t *testing.T) {
+	svc := New()
+
+	_, err := svc.ReviewTestCase(apistructs.TestCaseReviewRequest{TestCaseID: 1, Action: "UNKNOWN"})
+	assert.Error(t, err)
+
+	// 驳回必须填写评审意见
+	_, err = svc.ReviewTestCase(apistructs.TestCaseReviewRequest{
+		TestCaseID: 1,
+		Action:     apistructs.TestCaseReviewActionReject,
+		Comment:    "  ",
+	})
+	assert.Error(t, err)
+}
+
+func TestIsSelfApproval(t *testing.T) {
+	tc := dao.TestCase{UpdaterID: "1"}
+	reviews := []dao.TestCaseReview{
+		{Action: apistructs.TestCaseReviewActionSubmit, OperatorID: "2"},
+		{Action: apistructs.TestCaseReviewActionReject, OperatorID: "3"},
+		{Action: apistructs.TestCaseReviewActionSubmit, OperatorID: "3"},
+	}
+	// 最后修改人及最近一次提交评审的人不能评审通过
+	assert.True(t, isSelfApproval("1", tc, reviews))
+	assert.True(t, isSelfApproval("2", tc, reviews))
+	assert.False(t, isSelfApproval("3", tc, reviews))
+	assert.False(t, isSelfApproval("4", tc, nil))
+}
+
+func TestResetApprovedReviewState(t *testing.T) {
+	approved := dao.TestCase{
+		Name:           "case",
+		StepAndResults: dao.TestCaseStepAndResults{{ID: 1, Step: "step", Result: "result"}},
+		ReviewState:    apistructs.TestCaseReviewStateApproved,
+	}
+
+	// 仅修改优先级不影响评审结果
+	after := approved
+	after.Priority = apistructs.TestCasePriorityP0
+	resetApprovedReviewState(approved, &after)
+	assert.Equal(t, apistructs.TestCaseReviewStateApproved, after.ReviewState)
+
+	after = approved
+	after.StepAndResults = dao.TestCaseStepAndResults{{ID: 1, Step: "step", Result: "changed"}}
+	resetApprovedReviewState(approved, &after)
+	assert.Equal(t, apistructs.TestCaseReviewStateDraft, after.ReviewState)
+
+	// 评审中的用例保持原状态
+	inReview := approved
+	inReview.ReviewState = apistructs.TestCaseReviewStateInReview
+	after = inReview
+	after.Name = "renamed"
+	resetApprovedReviewState(inReview, &after)
+	assert.Equal(t, apistructs.TestCaseReviewStateInReview, after.ReviewState)
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	// searchCandidatesLimit 参与相关度排序的候选用例上限
+	searchCandidatesLimit = 1000
+	// searchSnippetContext 命中片段中关键字前后保留的字符数
+	searchSnippetContext = 30
+)
+
+// 各字段命中一次的权重
+var searchFieldWeights = map[apistructs.TestCaseSearchField]int{
+	apistructs.TestCaseSearchFieldName:         10,
+	apistructs.TestCaseSearchFieldPreCondition: 3,
+	apistructs.TestCaseSearchFieldStep:         2,
+	apistructs.TestCaseSearchFieldResult:       2,
+}
+
+// SearchTestCases 测试用例全文搜索，结果按相关度降序分页返回
+func (svc *Service) SearchTestCases(req apistructs.TestCaseSearchRequest) (*apistructs.TestCaseSearchResponseData, error) {
+	if req.ProjectID == 0 {
+		return nil, apierrors.ErrSearchTestCases.MissingParameter("projectID")
+	}
+	keywords := splitSearchKeywords(req.Query)
+	if len(keywords) == 0 {
+		return nil, apierrors.ErrSearchTestCases.MissingParameter("query")
+	}
+	for _, priority := range req.Priorities {
+		if !priority.IsValid() {
+			return nil, apierrors.ErrSearchTestCases.InvalidParameter(fmt.Sprintf("priority: %s", priority))
+		}
+	}
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	if !req.IsInternalClient() {
+		access, err := svc.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+			UserID:   req.UserID,
+			Scope:    apistructs.ProjectScope,
+			ScopeID:  req.ProjectID,
+			Resource: apistructs.TestPlanResource,
+			Action:   apistructs.GetAction,
+		})
+		if err != nil {
+			return nil, apierrors.ErrSearchTestCases.InternalError(err)
+		}
+		if !access.Access {
+			return nil, apierrors.ErrSearchTestCases.AccessDenied()
+		}
+	}
+
+	// 限定测试集时包含其所有子测试集
+	_, testSets, err := svc.db.ListTestSetsRecursive(apistructs.TestSetListRequest{
+		ParentID:  &req.TestSetID,
+		ProjectID: &req.ProjectID,
+	})
+	if err != nil {
+		return nil, apierrors.ErrSearchTestCases.InternalError(err)
+	}
+	dirs := make(map[uint64]string, len(testSets))
+	for _, ts := range testSets {
+		dirs[ts.ID] = ts.Directory
+	}
+	var testSetIDs []uint64
+	if req.TestSetID != 0 {
+		for _, ts := range testSets {
+			testSetIDs = append(testSetIDs, ts.ID)
+		}
+		if len(testSetIDs) == 0 {
+			return &apistructs.TestCaseSearchResponseData{}, nil
+		}
+	}
+
+	tcs, err := svc.db.SearchTestCases(req.ProjectID, testSetIDs, req.ExcludeTestSetIDs, req.Priorities, keywords, searchCandidatesLimit)
+	if err != nil {
+		return nil, apierrors.ErrSearchTestCases.InternalError(err)
+	}
+
+	hits := make([]apistructs.TestCaseSearchHit, 0, len(tcs))
+	for _, tc := range tcs {
+		hit := rankTestCase(tc, keywords)
+		if hit.Score == 0 {
+			continue
+		}
+		hit.Directory = dirs[tc.TestSetID]
+		hits = append(hits, hit)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	result := apistructs.TestCaseSearchResponseData{Total: uint64(len(hits))}
+	offset := (req.PageNo - 1) * req.PageSize
+	if offset < int64(len(hits)) {
+		end := offset + req.PageSize
+		if end > int64(len(hits)) {
+			end = int64(len(hits))
+		}
+		result.List = hits[offset:end]
+	}
+	return &result, nil
+}
+
+// splitSearchKeywords 按空白切分关键字并去重
+func splitSearchKeywords(query string) []string {
+	return strutil.DedupSlice(strings.Fields(query))
+}
+
+// rankTestCase 计算用例相关度并生成命中片段，任一关键字未命中时相关度为 0
+func rankTestCase(tc dao.TestCase, keywords []string) apistructs.TestCaseSearchHit {
+	hit := apistructs.TestCaseSearchHit{
+		ID:        uint64(tc.ID),
+		Name:      tc.Name,
+		Priority:  tc.Priority,
+		TestSetID: tc.TestSetID,
+	}
+	type fieldText struct {
+		field apistructs.TestCaseSearchField
+		text  string
+	}
+	texts := []fieldText{
+		{apistructs.TestCaseSearchFieldName, tc.Name},
+		{apistructs.TestCaseSearchFieldPreCondition, tc.PreCondition},
+	}
+	for _, sr := range tc.StepAndResults {
+		texts = append(texts,
+			fieldText{apistructs.TestCaseSearchFieldStep, sr.Step},
+			fieldText{apistructs.TestCaseSearchFieldResult, sr.Result})
+	}
+
+	matched := make(map[string]bool, len(keywords))
+	var score int
+	for _, ft := range texts {
+		var fieldScore int
+		for _, keyword := range keywords {
+			count := strings.Count(strings.ToLower(ft.text), strings.ToLower(keyword))
+			if count == 0 {
+				continue
+			}
+			matched[keyword] = true
+			fieldScore += count * searchFieldWeights[ft.field]
+		}
+		if fieldScore == 0 {
+			continue
+		}
+		score += fieldScore
+		hit.Highlights = append(hit.Highlights, apistructs.TestCaseSearchHighlight{
+			Field:   ft.field,
+			Snippet: highlightSnippet(ft.text, keywords),
+		})
+	}
+	if len(matched) < len(keywords) {
+		return hit
+	}
+	hit.Score = score
+	return hit
+}
+
+// highlightSnippet 截取首个命中位置附近的文本，转义 HTML 后用 <em></em> 包裹所有命中的关键字
+func highlightSnippet(text string, keywords []string) string {
+	runes := []rune(text)
+	// 大小写转换后长度变化时无法按位置对齐，退化为区分大小写
+	fold := len([]rune(strings.ToLower(text))) == len(runes)
+	lowerText := text
+	if fold {
+		lowerText = strings.ToLower(text)
+	}
+
+	// 标记命中的字符
+	marks := make([]bool, len(runes))
+	first := -1
+	for _, keyword := range keywords {
+		kw := keyword
+		if fold {
+			kw = strings.ToLower(keyword)
+		}
+		kwLen := utf8.RuneCountInString(kw)
+		for byteStart := 0; ; {
+			idx := strings.Index(lowerText[byteStart:], kw)
+			if idx < 0 {
+				break
+			}
+			start := utf8.RuneCountInString(lowerText[:byteStart+idx])
+			for i := start; i < start+kwLen && i < len(marks); i++ {
+				marks[i] = true
+			}
+			if first < 0 || start < first {
+				first = start
+			}
+			byteStart += idx + len(kw)
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	begin, end := first-searchSnippetContext, first+searchSnippetContext*2
+	if begin < 0 {
+		begin = 0
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if begin > 0 {
+		b.WriteString("...")
+	}
+	for i := begin; i < end; i++ {
+		if marks[i] && (i == begin || !marks[i-1]) {
+			b.WriteString("<em>")
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		if marks[i] && (i == end-1 || !marks[i+1]) {
+			b.WriteString("</em>")
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
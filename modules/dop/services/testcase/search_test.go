@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestSplitSearchKeywords(t *testing.T) {
+	assert.Equal(t, []string{"登录", "token"}, splitSearchKeywords("  登录 token 登录 "))
+	assert.Empty(t, splitSearchKeywords("   "))
+}
+
+func TestRankTestCase(t *testing.T) {
+	tc := dao.TestCase{
+		Name:         "登录失败提示",
+		PreCondition: "用户已注册",
+		StepAndResults: dao.TestCaseStepAndResults{
+			{Step: "输入错误的密码并点击登录", Result: "提示密码错误"},
+		},
+	}
+	tc.ID = 1
+
+	hit := rankTestCase(tc, []string{"登录"})
+	assert.Equal(t, 10+2, hit.Score)
+	assert.Equal(t, []apistructs.TestCaseSearchHighlight{
+		{Field: apistructs.TestCaseSearchFieldName, Snippet: "<em>登录</em>失败提示"},
+		{Field: apistructs.TestCaseSearchFieldStep, Snippet: "输入错误的密码并点击<em>登录</em>"},
+	}, hit.Highlights)
+
+	// 所有关键字都需命中
+	assert.Equal(t, 0, rankTestCase(tc, []string{"登录", "验证码"}).Score)
+	assert.Equal(t, 10+2+2+2, rankTestCase(tc, []string{"登录", "密码"}).Score)
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	assert.Equal(t, "Check <em>Token</em> and <em>token</em> &lt;b&gt;", highlightSnippet("Check Token and token <b>", []string{"token"}))
+	assert.Equal(t, "", highlightSnippet("nothing", []string{"token"}))
+
+	long := strings.Repeat("a", 50) + "key" + strings.Repeat("b", 100)
+	snippet := highlightSnippet(long, []string{"key"})
+	assert.True(t, strings.HasPrefix(snippet, "..."+strings.Repeat("a", searchSnippetContext)+"<em>key</em>"))
+	assert.True(t, strings.HasSuffix(snippet, "..."))
+}
@@ -55,3 +55,10 @@ func WithBundle(bdl *bundle.Bundle) Option {
 		svc.bdl = bdl
 	}
 }
+
+// withDB 返回通过 db 访问数据库的服务副本, 用于在 dao.DBClient.WithTransaction 中复用服务方法
+func (svc *Service) withDB(db *dao.DBClient) *Service {
+	txSvc := *svc
+	txSvc.db = db
+	return &txSvc
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// ReorderTestCaseSteps 按给定的步骤 ID 顺序调整测试用例步骤;
+// 步骤 ID 集合与当前不一致或期间用例被其他人修改时返回错误，不会覆盖他人的修改
+func (svc *Service) ReorderTestCaseSteps(req apistructs.TestCaseStepReorderRequest) ([]apistructs.TestCaseStepAndResult, error) {
+	if req.TestCaseID == 0 {
+		return nil, apierrors.ErrUpdateTestCase.MissingParameter("testCaseID")
+	}
+	if len(req.StepIDs) == 0 {
+		return nil, apierrors.ErrUpdateTestCase.MissingParameter("stepIDs")
+	}
+
+	tc, err := svc.db.GetTestCaseByID(req.TestCaseID)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, apierrors.ErrUpdateTestCase.NotFound()
+		}
+		return nil, apierrors.ErrUpdateTestCase.InternalError(err)
+	}
+	current := normalizeStepIDs(tc.StepAndResults)
+	reordered, err := reorderSteps(current, req.StepIDs)
+	if err != nil {
+		return nil, apierrors.ErrUpdateTestCase.InvalidState(err.Error())
+	}
+	if sameStepOrder(tc.StepAndResults, reordered) {
+		return reordered, nil
+	}
+
+	svc.ensureBaselineVersion(tc)
+	before := *tc
+	tc.StepAndResults = reordered
+	tc.UpdaterID = req.UserID
+	resetApprovedReviewState(before, tc)
+	if err := svc.db.UpdateTestCaseSteps(tc.ID, before.LockVersion, reordered, tc.ReviewState, req.UserID); err != nil {
+		if err == dao.ErrTestCaseModified {
+			return nil, apierrors.ErrUpdateTestCase.InvalidState("test case has been modified, please refresh and retry")
+		}
+		return nil, apierrors.ErrUpdateTestCase.InternalError(err)
+	}
+	tc.LockVersion++
+
+	svc.recordAuditLogs([]dao.TestCaseAuditLog{
+		newAuditLog(*tc, apistructs.TestCaseAuditActionUpdate, req.IdentityInfo, diffTestCase(before, *tc)),
+	})
+	if err := svc.recordTestCaseVersion(tc, req.UserID, 0); err != nil {
+		logrus.Errorf("failed to record testcase version after reorder steps, err: %v", err)
+	}
+
+	return reordered, nil
+}
+
+// normalizeStepIDs 为没有 ID 或 ID 重复的步骤分配新 ID；历史数据没有步骤 ID，按位置依次分配
+func normalizeStepIDs(steps []apistructs.TestCaseStepAndResult) []apistructs.TestCaseStepAndResult {
+	if len(steps) == 0 {
+		return steps
+	}
+	var maxID uint64
+	for _, step := range steps {
+		if step.ID > maxID {
+			maxID = step.ID
+		}
+	}
+	seen := make(map[uint64]bool, len(steps))
+	results := make([]apistructs.TestCaseStepAndResult, 0, len(steps))
+	for _, step := range steps {
+		if step.ID == 0 || seen[step.ID] {
+			maxID++
+			step.ID = maxID
+		}
+		seen[step.ID] = true
+		results = append(results, step)
+	}
+	return results
+}
+
+// reorderSteps 按 stepIDs 的顺序重排步骤，stepIDs 须与当前步骤 ID 集合完全一致
+func reorderSteps(steps []apistructs.TestCaseStepAndResult, stepIDs []uint64) (dao.TestCaseStepAndResults, error) {
+	if len(stepIDs) != len(steps) {
+		return nil, fmt.Errorf("steps have been changed, expected %d steps but got %d, please refresh and retry", len(steps), len(stepIDs))
+	}
+	stepMap := make(map[uint64]apistructs.TestCaseStepAndResult, len(steps))
+	for _, step := range steps {
+		stepMap[step.ID] = step
+	}
+	results := make(dao.TestCaseStepAndResults, 0, len(steps))
+	for _, id := range stepIDs {
+		step, ok := stepMap[id]
+		if !ok {
+			return nil, fmt.Errorf("step %d not found or duplicated, please refresh and retry", id)
+		}
+		delete(stepMap, id)
+		results = append(results, step)
+	}
+	return results, nil
+}
+
+// sameStepOrder 判断重排结果与库中步骤是否完全一致，一致时无需更新
+func sameStepOrder(origin dao.TestCaseStepAndResults, reordered dao.TestCaseStepAndResults) bool {
+	if len(origin) != len(reordered) {
+		return false
+	}
+	for i := range origin {
+		if origin[i].ID != reordered[i].ID || origin[i].Step != reordered[i].Step || origin[i].Result != reordered[i].Result {
+			return false
+		}
+	}
+	return true
+}
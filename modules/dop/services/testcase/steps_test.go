@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testcase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestNormalizeStepIDs(t *testing.T) {
+	// 历史数据没有步骤 ID，按位置分配
+	assert.Equal(t, []apistructs.TestCaseStepAndResult{
+		{ID: 1, Step: "a"}, {ID: 2, Step: "b"},
+	}, normalizeStepIDs([]apistructs.TestCaseStepAndResult{{Step: "a"}, {Step: "b"}}))
+
+	// 新增及重复 ID 的步骤在最大 ID 之后分配
+	assert.Equal(t, []apistructs.TestCaseStepAndResult{
+		{ID: 3, Step: "a"}, {ID: 4, Step: "b"}, {ID: 1, Step: "c"}, {ID: 5, Step: "d"},
+	}, normalizeStepIDs([]apistructs.TestCaseStepAndResult{{ID: 3, Step: "a"}, {Step: "b"}, {ID: 1, Step: "c"}, {ID: 3, Step: "d"}}))
+
+	assert.Empty(t, normalizeStepIDs(nil))
+}
+
+func TestReorderSteps(t *testing.T) {
+	steps := []apistructs.TestCaseStepAndResult{{ID: 1, Step: "a"}, {ID: 2, Step: "b"}, {ID: 3, Step: "c"}}
+
+	reordered, err := reorderSteps(steps, []uint64{3, 1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, dao.TestCaseStepAndResults{{ID: 3, Step: "c"}, {ID: 1, Step: "a"}, {ID: 2, Step: "b"}}, reordered)
+	assert.False(t, sameStepOrder(steps, reordered))
+	assert.True(t, sameStepOrder(steps, dao.TestCaseStepAndResults(steps)))
+
+	// 步骤数量不一致
+	_, err = reorderSteps(steps, []uint64{1, 2})
+	assert.Error(t, err)
+	// 重复的步骤 ID
+	_, err = reorderSteps(steps, []uint64{1, 1, 2})
+	assert.Error(t, err)
+	// 不存在的步骤 ID
+	_, err = reorderSteps(steps, []uint64{1, 2, 4})
+	assert.Error(t, err)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
 	"github.com/erda-project/erda/modules/dop/services/apierrors"
 )
 
@@ -55,6 +56,11 @@ func (svc *Service) UpdateTestCase(req apistructs.TestCaseUpdateRequest) error {
 		logrus.Errorf("failed to query testcase, id: %d, err: %v", req.ID, err)
 		return apierrors.ErrUpdateTestCase.InternalError(fmt.Errorf("query testcase failed"))
 	}
+	if req.LockVersion != nil && *req.LockVersion != tc.LockVersion {
+		return apierrors.ErrUpdateTestCase.InvalidState("test case has been modified, please refresh and retry")
+	}
+	svc.ensureBaselineVersion(tc)
+	before := *tc
 
 	// 更新至数据库
 	if req.Name != "" {
@@ -67,14 +73,28 @@ func (svc *Service) UpdateTestCase(req apistructs.TestCaseUpdateRequest) error {
 		tc.PreCondition = req.PreCondition
 	}
 	if len(req.StepAndResults) > 0 {
-		tc.StepAndResults = req.StepAndResults
+		tc.StepAndResults = normalizeStepIDs(req.StepAndResults)
 	}
 	if req.Desc != "" {
 		tc.Desc = req.Desc
 	}
 	tc.UpdaterID = req.IdentityInfo.UserID
+	resetApprovedReviewState(before, tc)
 
-	if err := svc.db.UpdateTestCase(tc); err != nil {
+	if err := svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		if err := txSvc.db.UpdateTestCase(tc); err != nil {
+			return err
+		}
+		var auditLogs []dao.TestCaseAuditLog
+		if changes := diffTestCase(before, *tc); len(changes) > 0 {
+			auditLogs = append(auditLogs, newAuditLog(*tc, apistructs.TestCaseAuditActionUpdate, req.IdentityInfo, changes))
+		}
+		return txSvc.recordAuditLogs(auditLogs)
+	}); err != nil {
+		if err == dao.ErrTestCaseModified {
+			return apierrors.ErrUpdateTestCase.InvalidState("test case has been modified, please refresh and retry")
+		}
 		return apierrors.ErrUpdateTestCase.InternalError(err)
 	}
 
@@ -106,6 +126,11 @@ func (svc *Service) UpdateTestCase(req apistructs.TestCaseUpdateRequest) error {
 		}
 	}
 
+	// 记录历史版本
+	if err := svc.recordTestCaseVersion(tc, req.UserID, req.RestoredFromVersion); err != nil {
+		logrus.Errorf("failed to record testcase version after update, err: %v", err)
+	}
+
 	return nil
 }
 
@@ -129,13 +154,36 @@ func (svc *Service) BatchUpdateTestCases(req apistructs.TestCaseBatchUpdateReque
 	}
 
 	// 校验 ids 是否都存在
-	_, err := svc.db.ListTestCasesByIDs(req.TestCaseIDs)
+	tcs, err := svc.db.ListTestCasesByIDs(req.TestCaseIDs)
 	if err != nil {
 		return apierrors.ErrBatchUpdateTestCases.InvalidParameter(err)
 	}
 
-	// 批量更新字段
-	if err := svc.db.BatchUpdateTestCases(req); err != nil {
+	// 批量更新字段，审计日志与更新在同一事务中写入
+	action := batchUpdateAuditAction(req)
+	var auditLogs []dao.TestCaseAuditLog
+	for _, tc := range tcs {
+		after := tc
+		if req.Priority != "" {
+			after.Priority = req.Priority
+		}
+		if req.Recycled != nil {
+			after.Recycled = req.Recycled
+		}
+		if req.MoveToTestSetID != nil {
+			after.TestSetID = *req.MoveToTestSetID
+		}
+		if changes := diffTestCase(tc, after); len(changes) > 0 {
+			auditLogs = append(auditLogs, newAuditLog(after, action, req.IdentityInfo, changes))
+		}
+	}
+	if err := svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		txSvc := svc.withDB(tx)
+		if err := txSvc.db.BatchUpdateTestCases(req); err != nil {
+			return err
+		}
+		return txSvc.recordAuditLogs(auditLogs)
+	}); err != nil {
 		return apierrors.ErrBatchUpdateTestCases.InternalError(err)
 	}
 
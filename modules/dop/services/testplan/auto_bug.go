@@ -0,0 +1,206 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+)
+
+// AutoCreateBugs 测试计划开启自动创建缺陷时, 为执行未通过的用例创建缺陷并关联;
+// 用例已关联未关闭的自动创建缺陷时, 不再重复创建, 而是在该缺陷下追加本次执行结果
+func (t *TestPlan) AutoCreateBugs(testPlanID uint64, relIDs []uint64, operatorID string) error {
+	if len(relIDs) == 0 {
+		return nil
+	}
+	tp, err := t.Get(testPlanID)
+	if err != nil {
+		return err
+	}
+	if !tp.AutoCreateBug {
+		return nil
+	}
+	if operatorID == "" {
+		operatorID = tp.OwnerID
+	}
+
+	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{
+		IDs:          relIDs,
+		TestPlanIDs:  []uint64{testPlanID},
+		ExecStatuses: []apistructs.TestCaseExecStatus{apistructs.CaseExecStatusFail},
+	})
+	if err != nil {
+		return err
+	}
+	if len(rels) == 0 {
+		return nil
+	}
+	tcIDs := make([]uint64, 0, len(rels))
+	for _, rel := range rels {
+		tcIDs = append(tcIDs, rel.TestCaseID)
+	}
+	tcs, _, err := t.testCaseSvc.ListTestCases(apistructs.TestCaseListRequest{
+		IDs:                   tcIDs,
+		AllowMissingProjectID: true,
+		AllowEmptyTestSetIDs:  true,
+	})
+	if err != nil {
+		return err
+	}
+	tcMap := make(map[uint64]*apistructs.TestCase, len(tcs))
+	for i := range tcs {
+		tcMap[tcs[i].ID] = &tcs[i]
+	}
+	apis, err := dbclient.ListAPIsByTestCaseIDs(tp.ProjectID, tcIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range rels {
+		tc, ok := tcMap[rel.TestCaseID]
+		if !ok {
+			continue
+		}
+		if err := t.autoCreateBug(tp, rel, tc, apis[rel.TestCaseID], operatorID); err != nil {
+			return fmt.Errorf("failed to auto create bug of test plan case %d, err: %v", rel.ID, err)
+		}
+	}
+	return nil
+}
+
+func (t *TestPlan) autoCreateBug(tp *apistructs.TestPlan, rel dao.TestPlanCaseRel, tc *apistructs.TestCase,
+	apis []*dbclient.ApiTest, operatorID string) error {
+	now := time.Now()
+	actual := genAutoBugActualResult(tp.Name, apis, now)
+
+	bug, err := t.getOpenAutoBug(rel.ID)
+	if err != nil {
+		return err
+	}
+	if bug != nil {
+		user, err := t.bdl.GetCurrentUser(operatorID)
+		if err != nil {
+			return err
+		}
+		_, err = t.issueStream.Create(&apistructs.IssueStreamCreateRequest{
+			IssueID:    int64(bug.ID),
+			Operator:   operatorID,
+			StreamType: apistructs.ISTComment,
+			StreamParams: apistructs.ISTParam{
+				Comment:     actual,
+				CommentTime: now.Format("2006-01-02 15:04:05"),
+				UserName:    user.Nick,
+			},
+		})
+		return err
+	}
+
+	assignee := rel.ExecutorID
+	if assignee == "" {
+		assignee = tp.OwnerID
+	}
+	issue, err := t.issueSvc.Create(&apistructs.IssueCreateRequest{
+		ProjectID:    tp.ProjectID,
+		IterationID:  -1,
+		Type:         apistructs.IssueTypeBug,
+		Title:        fmt.Sprintf("[%s] %s", tp.Name, tc.Name),
+		Content:      genAutoBugContent(tc, actual),
+		Assignee:     assignee,
+		Source:       apistructs.TestPlanAutoBugIssueSource,
+		IdentityInfo: apistructs.IdentityInfo{UserID: operatorID},
+	})
+	if err != nil {
+		return err
+	}
+	return t.AddTestPlanCaseRelIssueRelations(apistructs.TestPlanCaseRelIssueRelationAddRequest{
+		IssueIDs:          []uint64{issue.ID},
+		TestPlanID:        rel.TestPlanID,
+		TestPlanCaseRelID: rel.ID,
+		IdentityInfo:      apistructs.IdentityInfo{UserID: operatorID},
+	})
+}
+
+// getOpenAutoBug 获取测试计划用例已关联的、未关闭的自动创建缺陷
+func (t *TestPlan) getOpenAutoBug(relID uint64) (*dao.Issue, error) {
+	issueRels, err := t.db.ListIssueTestCaseRelations(apistructs.IssueTestCaseRelationsListRequest{TestPlanCaseRelID: relID})
+	if err != nil {
+		return nil, err
+	}
+	if len(issueRels) == 0 {
+		return nil, nil
+	}
+	issueIDs := make([]uint64, 0, len(issueRels))
+	for _, issueRel := range issueRels {
+		issueIDs = append(issueIDs, issueRel.IssueID)
+	}
+	issues, err := t.db.GetIssueByIssueIDs(issueIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		if issues[i].Type != apistructs.IssueTypeBug || issues[i].Source != apistructs.TestPlanAutoBugIssueSource {
+			continue
+		}
+		state, err := t.db.GetIssueStateByID(issues[i].State)
+		if err != nil {
+			return nil, err
+		}
+		if state.Belong != apistructs.IssueStateBelongClosed && state.Belong != apistructs.IssueStateBelongWontfix {
+			return &issues[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// genAutoBugContent 生成自动创建缺陷的描述, 包括前置条件、操作步骤及预期结果和实际结果
+func genAutoBugContent(tc *apistructs.TestCase, actual string) string {
+	var b strings.Builder
+	if tc.PreCondition != "" {
+		b.WriteString("### 前置条件\n")
+		b.WriteString(tc.PreCondition)
+		b.WriteString("\n\n")
+	}
+	if len(tc.StepAndResults) > 0 {
+		b.WriteString("### 操作步骤及预期结果\n")
+		for i, sr := range tc.StepAndResults {
+			b.WriteString(fmt.Sprintf("%d. %s\n", i+1, sr.Step))
+			if sr.Result != "" {
+				b.WriteString(fmt.Sprintf("   - 预期结果: %s\n", sr.Result))
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("### 实际结果\n")
+	b.WriteString(actual)
+	return b.String()
+}
+
+// genAutoBugActualResult 生成用例执行未通过的实际结果, 接口测试用例附带未通过接口的断言结果
+func genAutoBugActualResult(testPlanName string, apis []*dbclient.ApiTest, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("测试计划 %s 中执行未通过 (%s)\n", testPlanName, now.Format("2006-01-02 15:04:05")))
+	for _, api := range apis {
+		if api.Status != string(apistructs.ApiTestFailed) {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("- 接口 %d 未通过: %s\n", api.ID, api.AssertResult))
+	}
+	return b.String()
+}
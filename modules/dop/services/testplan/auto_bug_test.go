@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+)
+
+func TestGenAutoBugActualResult(t *testing.T) {
+	now := time.Date(2021, 9, 17, 10, 0, 0, 0, time.Local)
+	apis := []*dbclient.ApiTest{
+		{ID: 1, Status: string(apistructs.ApiTestPassed)},
+		{ID: 2, Status: string(apistructs.ApiTestFailed), AssertResult: "status 500 != 200"},
+	}
+	assert.Equal(t, "测试计划 regression 中执行未通过 (2021-09-17 10:00:00)\n- 接口 2 未通过: status 500 != 200\n",
+		genAutoBugActualResult("regression", apis, now))
+}
+
+func TestGenAutoBugContent(t *testing.T) {
+	tc := &apistructs.TestCase{
+		PreCondition: "logged in",
+		StepAndResults: []apistructs.TestCaseStepAndResult{
+			{Step: "open page", Result: "page shown"},
+			{Step: "click"},
+		},
+	}
+	assert.Equal(t, "### 前置条件\nlogged in\n\n"+
+		"### 操作步骤及预期结果\n1. open page\n   - 预期结果: page shown\n2. click\n\n"+
+		"### 实际结果\nfailed", genAutoBugContent(tc, "failed"))
+
+	assert.Equal(t, "### 实际结果\nfailed", genAutoBugContent(&apistructs.TestCase{}, "failed"))
+}
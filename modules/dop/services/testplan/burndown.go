@@ -0,0 +1,153 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+const burndownDateFormat = "2006-01-02"
+
+// maxBurndownDays 燃尽图最多统计的天数, 超出时只保留最近的部分
+const maxBurndownDays = 366
+
+// GetBurndown 根据用例执行状态的变更记录, 统计测试计划从开始到结束每天的执行进度
+func (t *TestPlan) GetBurndown(testPlanID uint64) (*apistructs.TestPlanBurndown, error) {
+	tp, err := t.Get(testPlanID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := burndownDateRange(tp, time.Now())
+
+	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{
+		TestPlanIDs: []uint64{testPlanID},
+	})
+	if err != nil {
+		return nil, apierrors.ErrGetTestPlanBurndown.InternalError(err)
+	}
+	histories, err := t.db.ListTestPlanCaseExecHistories(testPlanID, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, apierrors.ErrGetTestPlanBurndown.InternalError(err)
+	}
+
+	return &apistructs.TestPlanBurndown{
+		TestPlanID: testPlanID,
+		StartDate:  start.Format(burndownDateFormat),
+		EndDate:    end.Format(burndownDateFormat),
+		Total:      len(rels),
+		Days:       computeBurndown(rels, histories, start, end),
+	}, nil
+}
+
+// recordExecHistories 记录测试计划用例的执行状态变更, 失败不影响状态更新, 只打印日志
+func (t *TestPlan) recordExecHistories(testPlanID uint64, relIDs []uint64, status apistructs.TestCaseExecStatus, operatorID string) {
+	if status == "" || len(relIDs) == 0 {
+		return
+	}
+	histories := make([]dao.TestPlanCaseExecHistory, 0, len(relIDs))
+	for _, relID := range relIDs {
+		histories = append(histories, dao.TestPlanCaseExecHistory{
+			TestPlanID:        testPlanID,
+			TestPlanCaseRelID: relID,
+			ExecStatus:        status,
+			OperatorID:        operatorID,
+		})
+	}
+	if err := t.db.BatchCreateTestPlanCaseExecHistories(histories); err != nil {
+		logrus.Errorf("failed to record exec histories of test plan %d, err: %v", testPlanID, err)
+	}
+}
+
+// burndownDateRange 统计的日期范围: 从测试计划开始时间(未设置时为创建时间)到结束时间与当天中较早的一天
+func burndownDateRange(tp *apistructs.TestPlan, now time.Time) (time.Time, time.Time) {
+	start, end := now, now
+	if tp.StartedAt != nil {
+		start = *tp.StartedAt
+	} else if tp.CreatedAt != nil {
+		start = *tp.CreatedAt
+	}
+	if tp.EndedAt != nil && tp.EndedAt.Before(now) {
+		end = *tp.EndedAt
+	}
+	start, end = truncateToDay(start), truncateToDay(end)
+	if end.Before(start) {
+		end = start
+	}
+	if earliest := end.AddDate(0, 0, 1-maxBurndownDays); start.Before(earliest) {
+		start = earliest
+	}
+	return start, end
+}
+
+// computeBurndown 统计每天结束时计划内用例的执行情况, histories 需按时间排序
+func computeBurndown(rels []dao.TestPlanCaseRel, histories []dao.TestPlanCaseExecHistory, start, end time.Time) []apistructs.TestPlanBurndownDay {
+	relHistories := make(map[uint64][]dao.TestPlanCaseExecHistory)
+	for _, h := range histories {
+		relHistories[h.TestPlanCaseRelID] = append(relHistories[h.TestPlanCaseRelID], h)
+	}
+
+	var days []apistructs.TestPlanBurndownDay
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		item := apistructs.TestPlanBurndownDay{Date: day.Format(burndownDateFormat)}
+		for _, rel := range rels {
+			if !rel.CreatedAt.Before(dayEnd) {
+				continue
+			}
+			item.Total++
+			switch execStatusAt(rel, relHistories[rel.ID], dayEnd) {
+			case apistructs.CaseExecStatusSucc:
+				item.Passed++
+			case apistructs.CaseExecStatusFail:
+				item.Failed++
+			case apistructs.CaseExecStatusBlocked:
+				item.Blocked++
+			}
+		}
+		item.Executed = item.Passed + item.Failed + item.Blocked
+		item.Remaining = item.Total - item.Executed
+		days = append(days, item)
+	}
+	return days
+}
+
+// execStatusAt 用例在指定时间点之前的最后一次执行状态;
+// 没有变更记录的用例按当前状态和更新时间推算, 兼容开始记录之前已执行的用例
+func execStatusAt(rel dao.TestPlanCaseRel, histories []dao.TestPlanCaseExecHistory, at time.Time) apistructs.TestCaseExecStatus {
+	if len(histories) == 0 {
+		if rel.UpdatedAt.Before(at) {
+			return rel.ExecStatus
+		}
+		return apistructs.CaseExecStatusInit
+	}
+	status := apistructs.CaseExecStatusInit
+	for _, h := range histories {
+		if !h.CreatedAt.Before(at) {
+			break
+		}
+		status = h.ExecStatus
+	}
+	return status
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
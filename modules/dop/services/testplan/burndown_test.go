@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestBurndownDateRange(t *testing.T) {
+	now := time.Date(2021, 9, 20, 15, 0, 0, 0, time.Local)
+	created := time.Date(2021, 9, 1, 10, 0, 0, 0, time.Local)
+	started := time.Date(2021, 9, 10, 10, 0, 0, 0, time.Local)
+	ended := time.Date(2021, 9, 30, 10, 0, 0, 0, time.Local)
+
+	start, end := burndownDateRange(&apistructs.TestPlan{CreatedAt: &created, StartedAt: &started, EndedAt: &ended}, now)
+	assert.Equal(t, "2021-09-10", start.Format(burndownDateFormat))
+	assert.Equal(t, "2021-09-20", end.Format(burndownDateFormat))
+
+	start, end = burndownDateRange(&apistructs.TestPlan{CreatedAt: &created}, now)
+	assert.Equal(t, "2021-09-01", start.Format(burndownDateFormat))
+	assert.Equal(t, "2021-09-20", end.Format(burndownDateFormat))
+
+	longAgo := now.AddDate(-2, 0, 0)
+	start, _ = burndownDateRange(&apistructs.TestPlan{StartedAt: &longAgo}, now)
+	assert.Equal(t, now.AddDate(0, 0, 1-maxBurndownDays).Format(burndownDateFormat), start.Format(burndownDateFormat))
+}
+
+func TestComputeBurndown(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2021, 9, d, h, 0, 0, 0, time.Local) }
+	rels := []dao.TestPlanCaseRel{
+		{BaseModel: dbengine.BaseModel{ID: 1, CreatedAt: day(1, 9), UpdatedAt: day(3, 9)}, ExecStatus: apistructs.CaseExecStatusFail},
+		{BaseModel: dbengine.BaseModel{ID: 2, CreatedAt: day(1, 9), UpdatedAt: day(2, 9)}, ExecStatus: apistructs.CaseExecStatusBlocked},
+		// 开始记录变更前已执行的用例
+		{BaseModel: dbengine.BaseModel{ID: 3, CreatedAt: day(2, 9), UpdatedAt: day(2, 10)}, ExecStatus: apistructs.CaseExecStatusSucc},
+	}
+	histories := []dao.TestPlanCaseExecHistory{
+		{BaseModel: dbengine.BaseModel{CreatedAt: day(2, 9)}, TestPlanCaseRelID: 1, ExecStatus: apistructs.CaseExecStatusSucc},
+		{BaseModel: dbengine.BaseModel{CreatedAt: day(2, 9)}, TestPlanCaseRelID: 2, ExecStatus: apistructs.CaseExecStatusBlocked},
+		{BaseModel: dbengine.BaseModel{CreatedAt: day(3, 9)}, TestPlanCaseRelID: 1, ExecStatus: apistructs.CaseExecStatusFail},
+	}
+
+	days := computeBurndown(rels, histories, day(1, 0), day(3, 0))
+	assert.Equal(t, []apistructs.TestPlanBurndownDay{
+		{Date: "2021-09-01", Total: 2, Remaining: 2},
+		{Date: "2021-09-02", Total: 3, Executed: 3, Passed: 2, Blocked: 1},
+		{Date: "2021-09-03", Total: 3, Executed: 3, Passed: 1, Failed: 1, Blocked: 1},
+	}, days)
+}
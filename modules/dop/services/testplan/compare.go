@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// maxAPITestRerunDepth 对比时最多向前追溯的重跑次数
+const maxAPITestRerunDepth = 20
+
+// CompareAPITests 对比测试计划两次接口测试中各用例的结果, 找出新增未通过、新通过及持续未通过的用例
+func (t *TestPlan) CompareAPITests(req apistructs.TestPlanAPITestCompareRequest) (*apistructs.TestPlanAPITestCompareResult, error) {
+	if req.BasePipelineID == 0 {
+		return nil, apierrors.ErrTestPlanCompareAPITest.MissingParameter("basePipelineID")
+	}
+	if req.PipelineID == 0 {
+		return nil, apierrors.ErrTestPlanCompareAPITest.MissingParameter("pipelineID")
+	}
+	if req.BasePipelineID == req.PipelineID {
+		return nil, apierrors.ErrTestPlanCompareAPITest.InvalidParameter("cannot compare a pipeline with itself")
+	}
+	if _, err := t.Get(req.TestPlanID); err != nil {
+		return nil, err
+	}
+
+	var statuses []map[uint64]apistructs.PipelineStatus
+	for _, pipelineID := range []uint64{req.BasePipelineID, req.PipelineID} {
+		pipelineStatuses, err := t.loadAPITestCaseStatuses(pipelineID, req.TestPlanID)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, pipelineStatuses)
+	}
+
+	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{TestPlanIDs: []uint64{req.TestPlanID}})
+	if err != nil {
+		return nil, apierrors.ErrTestPlanCompareAPITest.InternalError(err)
+	}
+	result := compareAPITestCaseStatuses(rels, statuses[0], statuses[1])
+	result.BasePipelineID = req.BasePipelineID
+	result.PipelineID = req.PipelineID
+
+	if err := t.fillAPITestCompareCaseNames(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// loadAPITestCaseStatuses 获取流水线中各用例的结果.
+// 用例结果取自流水线中以用例 id 命名的任务状态, 而不是重跑使用的 dice_api_test 记录,
+// 因为 dice_api_test 只保留用例最近一次执行的结果, 无法还原较早的流水线.
+// 与 MergeRerunAPITestResult 一致, 重跑流水线只包含重跑的用例, 未重跑的用例沿用被重跑流水线中的结果
+func (t *TestPlan) loadAPITestCaseStatuses(pipelineID, testPlanID uint64) (map[uint64]apistructs.PipelineStatus, error) {
+	statuses := make(map[uint64]apistructs.PipelineStatus)
+	originPipelineID := pipelineID
+	for depth := 0; depth < maxAPITestRerunDepth; depth++ {
+		pipeline, err := t.bdl.GetPipeline(pipelineID)
+		if err != nil {
+			return nil, apierrors.ErrTestPlanCompareAPITest.InternalError(err)
+		}
+		if err := checkEndedAPITestPipeline(&pipeline.PipelineDTO, testPlanID); err != nil {
+			return nil, apierrors.ErrTestPlanCompareAPITest.InvalidParameter(err)
+		}
+		for tcID, status := range apiTestCaseStatuses(pipeline) {
+			if _, ok := statuses[tcID]; !ok {
+				statuses[tcID] = status
+			}
+		}
+		rerunFrom := pipeline.Labels[apistructs.LabelTestPlanRerunFromPipelineID]
+		if rerunFrom == "" {
+			return statuses, nil
+		}
+		if pipelineID, err = strconv.ParseUint(rerunFrom, 10, 64); err != nil {
+			return nil, apierrors.ErrTestPlanCompareAPITest.InternalError(
+				fmt.Errorf("invalid rerun from pipeline id label of pipeline %d, err: %v", pipeline.ID, err))
+		}
+	}
+	return nil, apierrors.ErrTestPlanCompareAPITest.InvalidParameter(
+		fmt.Sprintf("pipeline %d is rerun more than %d times", originPipelineID, maxAPITestRerunDepth))
+}
+
+// fillAPITestCompareCaseNames 填充对比结果中的用例名称
+func (t *TestPlan) fillAPITestCompareCaseNames(result *apistructs.TestPlanAPITestCompareResult) error {
+	groups := [][]apistructs.TestPlanAPITestCompareCase{result.NewlyFailed, result.NewlyPassed, result.StillFailing}
+	var tcIDs []uint64
+	for _, cases := range groups {
+		for _, c := range cases {
+			tcIDs = append(tcIDs, c.TestCaseID)
+		}
+	}
+	if len(tcIDs) == 0 {
+		return nil
+	}
+	tcs, _, err := t.testCaseSvc.ListTestCases(apistructs.TestCaseListRequest{
+		IDs:                   tcIDs,
+		AllowMissingProjectID: true,
+		AllowEmptyTestSetIDs:  true,
+	})
+	if err != nil {
+		return err
+	}
+	tcNames := make(map[uint64]string, len(tcs))
+	for _, tc := range tcs {
+		tcNames[tc.ID] = tc.Name
+	}
+	for _, cases := range groups {
+		for i := range cases {
+			cases[i].Name = tcNames[cases[i].TestCaseID]
+		}
+	}
+	return nil
+}
+
+// apiTestCaseStatuses 按用例 id 汇总流水线中各任务的状态, 未实际执行的任务不计入
+func apiTestCaseStatuses(pipeline *apistructs.PipelineDetailDTO) map[uint64]apistructs.PipelineStatus {
+	statuses := make(map[uint64]apistructs.PipelineStatus)
+	for _, stage := range pipeline.PipelineStages {
+		for _, task := range stage.PipelineTasks {
+			if !task.Status.IsEndStatus() ||
+				task.Status == apistructs.PipelineStatusNoNeedBySystem ||
+				task.Status == apistructs.PipelineStatusStopByUser {
+				continue
+			}
+			tcID, err := strconv.ParseUint(task.Name, 10, 64)
+			if err != nil {
+				continue
+			}
+			statuses[tcID] = task.Status
+		}
+	}
+	return statuses
+}
+
+// compareAPITestCaseStatuses 对比两次执行均包含的测试计划用例
+func compareAPITestCaseStatuses(rels []dao.TestPlanCaseRel, base, current map[uint64]apistructs.PipelineStatus) *apistructs.TestPlanAPITestCompareResult {
+	result := apistructs.TestPlanAPITestCompareResult{
+		NewlyFailed:  []apistructs.TestPlanAPITestCompareCase{},
+		NewlyPassed:  []apistructs.TestPlanAPITestCompareCase{},
+		StillFailing: []apistructs.TestPlanAPITestCompareCase{},
+	}
+	for _, rel := range rels {
+		baseStatus, ok := base[rel.TestCaseID]
+		if !ok {
+			continue
+		}
+		status, ok := current[rel.TestCaseID]
+		if !ok {
+			continue
+		}
+		c := apistructs.TestPlanAPITestCompareCase{
+			RelationID: rel.ID,
+			TestCaseID: rel.TestCaseID,
+			BaseStatus: baseStatus,
+			Status:     status,
+		}
+		switch {
+		case baseStatus.IsSuccessStatus() && status.IsFailedStatus():
+			result.NewlyFailed = append(result.NewlyFailed, c)
+		case baseStatus.IsFailedStatus() && status.IsSuccessStatus():
+			result.NewlyPassed = append(result.NewlyPassed, c)
+		case baseStatus.IsFailedStatus() && status.IsFailedStatus():
+			result.StillFailing = append(result.StillFailing, c)
+		}
+	}
+	return &result
+}
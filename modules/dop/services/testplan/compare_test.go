@@ -0,0 +1,138 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestAPITestCaseStatuses(t *testing.T) {
+	pipeline := &apistructs.PipelineDetailDTO{
+		PipelineStages: []apistructs.PipelineStageDetailDTO{
+			{PipelineTasks: []apistructs.PipelineTaskDTO{
+				{Name: "11", Status: apistructs.PipelineStatusSuccess},
+				{Name: "12", Status: apistructs.PipelineStatusFailed},
+			}},
+			{PipelineTasks: []apistructs.PipelineTaskDTO{
+				{Name: "13", Status: apistructs.PipelineStatusNoNeedBySystem},
+				{Name: "invalid", Status: apistructs.PipelineStatusSuccess},
+			}},
+		},
+	}
+	assert.Equal(t, map[uint64]apistructs.PipelineStatus{
+		11: apistructs.PipelineStatusSuccess,
+		12: apistructs.PipelineStatusFailed,
+	}, apiTestCaseStatuses(pipeline))
+}
+
+func TestCompareAPITestCaseStatuses(t *testing.T) {
+	rels := []dao.TestPlanCaseRel{
+		{BaseModel: dbengine.BaseModel{ID: 1}, TestCaseID: 11},
+		{BaseModel: dbengine.BaseModel{ID: 2}, TestCaseID: 12},
+		{BaseModel: dbengine.BaseModel{ID: 3}, TestCaseID: 13},
+		{BaseModel: dbengine.BaseModel{ID: 4}, TestCaseID: 14},
+		{BaseModel: dbengine.BaseModel{ID: 5}, TestCaseID: 15},
+	}
+	base := map[uint64]apistructs.PipelineStatus{
+		11: apistructs.PipelineStatusSuccess,
+		12: apistructs.PipelineStatusFailed,
+		13: apistructs.PipelineStatusFailed,
+		14: apistructs.PipelineStatusSuccess,
+	}
+	current := map[uint64]apistructs.PipelineStatus{
+		11: apistructs.PipelineStatusFailed,
+		12: apistructs.PipelineStatusSuccess,
+		13: apistructs.PipelineStatusTimeout,
+		14: apistructs.PipelineStatusSuccess,
+		15: apistructs.PipelineStatusFailed,
+	}
+	result := compareAPITestCaseStatuses(rels, base, current)
+	assert.Equal(t, []apistructs.TestPlanAPITestCompareCase{
+		{RelationID: 1, TestCaseID: 11, BaseStatus: apistructs.PipelineStatusSuccess, Status: apistructs.PipelineStatusFailed},
+	}, result.NewlyFailed)
+	assert.Equal(t, []apistructs.TestPlanAPITestCompareCase{
+		{RelationID: 2, TestCaseID: 12, BaseStatus: apistructs.PipelineStatusFailed, Status: apistructs.PipelineStatusSuccess},
+	}, result.NewlyPassed)
+	assert.Equal(t, []apistructs.TestPlanAPITestCompareCase{
+		{RelationID: 3, TestCaseID: 13, BaseStatus: apistructs.PipelineStatusFailed, Status: apistructs.PipelineStatusTimeout},
+	}, result.StillFailing)
+}
+
+func TestLoadAPITestCaseStatuses(t *testing.T) {
+	// 1 为完整执行, 2 重跑了 1 中的用例 12, 3 重跑了 2 中的用例 12, 4 属于其他测试计划
+	newPipeline := func(id uint64, testPlanID, rerunFrom string, tasks map[string]apistructs.PipelineStatus) *apistructs.PipelineDetailDTO {
+		p := &apistructs.PipelineDetailDTO{}
+		p.ID = id
+		p.Source = apistructs.PipelineSourceAPITest
+		p.Status = apistructs.PipelineStatusSuccess
+		p.Labels = map[string]string{apistructs.LabelTestPlanID: testPlanID}
+		if rerunFrom != "" {
+			p.Labels[apistructs.LabelTestPlanRerunFromPipelineID] = rerunFrom
+		}
+		var stage apistructs.PipelineStageDetailDTO
+		for name, status := range tasks {
+			stage.PipelineTasks = append(stage.PipelineTasks, apistructs.PipelineTaskDTO{Name: name, Status: status})
+		}
+		p.PipelineStages = []apistructs.PipelineStageDetailDTO{stage}
+		return p
+	}
+	pipelines := map[uint64]*apistructs.PipelineDetailDTO{
+		1: newPipeline(1, "1", "", map[string]apistructs.PipelineStatus{"11": apistructs.PipelineStatusSuccess, "12": apistructs.PipelineStatusFailed, "13": apistructs.PipelineStatusFailed}),
+		2: newPipeline(2, "1", "1", map[string]apistructs.PipelineStatus{"12": apistructs.PipelineStatusFailed, "13": apistructs.PipelineStatusSuccess}),
+		3: newPipeline(3, "1", "2", map[string]apistructs.PipelineStatus{"12": apistructs.PipelineStatusSuccess}),
+		4: newPipeline(4, "2", "", map[string]apistructs.PipelineStatus{"11": apistructs.PipelineStatusSuccess}),
+		5: newPipeline(5, "1", "4", map[string]apistructs.PipelineStatus{"11": apistructs.PipelineStatusFailed}),
+	}
+	tp := &TestPlan{bdl: &bundle.Bundle{}}
+	monkey.PatchInstanceMethod(reflect.TypeOf(tp.bdl), "GetPipeline", func(_ *bundle.Bundle, pipelineID uint64) (*apistructs.PipelineDetailDTO, error) {
+		p, ok := pipelines[pipelineID]
+		if !ok {
+			return nil, fmt.Errorf("pipeline %d not found", pipelineID)
+		}
+		return p, nil
+	})
+	defer monkey.UnpatchAll()
+
+	statuses, err := tp.loadAPITestCaseStatuses(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]apistructs.PipelineStatus{
+		11: apistructs.PipelineStatusSuccess,
+		12: apistructs.PipelineStatusFailed,
+		13: apistructs.PipelineStatusFailed,
+	}, statuses)
+
+	statuses, err = tp.loadAPITestCaseStatuses(3, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint64]apistructs.PipelineStatus{
+		11: apistructs.PipelineStatusSuccess,
+		12: apistructs.PipelineStatusSuccess,
+		13: apistructs.PipelineStatusSuccess,
+	}, statuses)
+
+	_, err = tp.loadAPITestCaseStatuses(4, 1)
+	assert.Error(t, err)
+	_, err = tp.loadAPITestCaseStatuses(5, 1)
+	assert.Error(t, err)
+}
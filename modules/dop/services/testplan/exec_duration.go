@@ -0,0 +1,232 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// maxExecDurationSamples 每个用例每种来源参与统计的最近执行次数, 避免早期数据影响估算
+const maxExecDurationSamples = 20
+
+// GetExecDurations 统计测试计划内各用例的历史执行耗时, 手动执行与接口测试分开统计
+func (t *TestPlan) GetExecDurations(testPlanID uint64) (*apistructs.TestPlanExecDurations, error) {
+	if _, err := t.Get(testPlanID); err != nil {
+		return nil, err
+	}
+	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{
+		TestPlanIDs: []uint64{testPlanID},
+	})
+	if err != nil {
+		return nil, apierrors.ErrGetTestPlanExecDurations.InternalError(err)
+	}
+	tcIDs := make([]uint64, 0, len(rels))
+	for _, rel := range rels {
+		tcIDs = append(tcIDs, rel.TestCaseID)
+	}
+	durations, err := t.db.ListTestPlanCaseExecDurationsByTestCaseIDs(tcIDs)
+	if err != nil {
+		return nil, apierrors.ErrGetTestPlanExecDurations.InternalError(err)
+	}
+
+	result := computeExecDurations(rels, durations)
+	result.TestPlanID = testPlanID
+	if len(tcIDs) > 0 {
+		tcs, _, err := t.testCaseSvc.ListTestCases(apistructs.TestCaseListRequest{
+			IDs:                   tcIDs,
+			AllowMissingProjectID: true,
+			AllowEmptyTestSetIDs:  true,
+		})
+		if err != nil {
+			return nil, apierrors.ErrGetTestPlanExecDurations.InternalError(err)
+		}
+		tcNames := make(map[uint64]string, len(tcs))
+		for _, tc := range tcs {
+			tcNames[tc.ID] = tc.Name
+		}
+		for i := range result.List {
+			result.List[i].Name = tcNames[result.List[i].TestCaseID]
+		}
+	}
+	return result, nil
+}
+
+// RecordAPITestDurations 接口测试流水线结束后, 按流水线中各用例任务的实际耗时记录执行耗时
+func (t *TestPlan) RecordAPITestDurations(testPlanID, pipelineID uint64, userID string) error {
+	pipeline, err := t.bdl.GetPipeline(pipelineID)
+	if err != nil {
+		return err
+	}
+	costs := apiTestCaseDurations(pipeline)
+	if len(costs) == 0 {
+		return nil
+	}
+	tcIDs := make([]uint64, 0, len(costs))
+	for tcID := range costs {
+		tcIDs = append(tcIDs, tcID)
+	}
+	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{
+		TestPlanIDs: []uint64{testPlanID},
+		TestCaseIDs: tcIDs,
+	})
+	if err != nil {
+		return err
+	}
+	durations := make([]dao.TestPlanCaseExecDuration, 0, len(rels))
+	for _, rel := range rels {
+		durations = append(durations, dao.TestPlanCaseExecDuration{
+			TestPlanID:        testPlanID,
+			TestPlanCaseRelID: rel.ID,
+			TestCaseID:        rel.TestCaseID,
+			Source:            apistructs.TestCaseExecDurationSourceAutomated,
+			DurationSec:       costs[rel.TestCaseID],
+			PipelineID:        pipelineID,
+			OperatorID:        userID,
+		})
+	}
+	if len(durations) == 0 {
+		return nil
+	}
+	return t.db.BatchCreateTestPlanCaseExecDurations(durations)
+}
+
+// checkManualExecDuration 手动执行耗时只能在更新单个用例的执行结果时填写
+func checkManualExecDuration(req apistructs.TestPlanCaseRelBatchUpdateRequest) error {
+	if req.ExecDurationSec == nil {
+		return nil
+	}
+	if req.Delete || req.TestSetID != nil || len(req.RelationIDs) != 1 ||
+		req.ExecStatus == "" || req.ExecStatus == apistructs.CaseExecStatusInit {
+		return fmt.Errorf("execDurationSec is only allowed when updating exec result of a single case")
+	}
+	return nil
+}
+
+// recordManualExecDuration 记录手动执行的耗时, 失败不影响执行结果更新, 只打印日志
+func (t *TestPlan) recordManualExecDuration(req apistructs.TestPlanCaseRelBatchUpdateRequest) {
+	if req.ExecDurationSec == nil || len(req.RelationIDs) != 1 {
+		return
+	}
+	rel, err := t.db.GetTestPlanCaseRel(req.RelationIDs[0])
+	if err != nil {
+		logrus.Errorf("failed to get test plan case rel %d, err: %v", req.RelationIDs[0], err)
+		return
+	}
+	if rel.TestPlanID != req.TestPlanID {
+		return
+	}
+	if err := t.db.BatchCreateTestPlanCaseExecDurations([]dao.TestPlanCaseExecDuration{{
+		TestPlanID:        rel.TestPlanID,
+		TestPlanCaseRelID: rel.ID,
+		TestCaseID:        rel.TestCaseID,
+		Source:            apistructs.TestCaseExecDurationSourceManual,
+		DurationSec:       *req.ExecDurationSec,
+		OperatorID:        req.UserID,
+	}}); err != nil {
+		logrus.Errorf("failed to record exec duration of test plan case rel %d, err: %v", rel.ID, err)
+	}
+}
+
+// apiTestCaseDurations 按用例 id 汇总流水线中各任务的耗时, 未实际执行或无耗时信息的任务不计入
+func apiTestCaseDurations(pipeline *apistructs.PipelineDetailDTO) map[uint64]uint64 {
+	costs := make(map[uint64]uint64)
+	for _, stage := range pipeline.PipelineStages {
+		for _, task := range stage.PipelineTasks {
+			if !task.Status.IsEndStatus() ||
+				task.Status == apistructs.PipelineStatusNoNeedBySystem ||
+				task.Status == apistructs.PipelineStatusStopByUser ||
+				task.CostTimeSec < 0 {
+				continue
+			}
+			tcID, err := strconv.ParseUint(task.Name, 10, 64)
+			if err != nil {
+				continue
+			}
+			costs[tcID] += uint64(task.CostTimeSec)
+		}
+	}
+	return costs
+}
+
+// computeExecDurations 统计各用例的执行耗时并估算测试计划总耗时, durations 需按时间排序
+func computeExecDurations(rels []dao.TestPlanCaseRel, durations []dao.TestPlanCaseExecDuration) *apistructs.TestPlanExecDurations {
+	samples := make(map[apistructs.TestCaseExecDurationSource]map[uint64][]dao.TestPlanCaseExecDuration)
+	for _, d := range durations {
+		if samples[d.Source] == nil {
+			samples[d.Source] = make(map[uint64][]dao.TestPlanCaseExecDuration)
+		}
+		samples[d.Source][d.TestCaseID] = append(samples[d.Source][d.TestCaseID], d)
+	}
+
+	result := apistructs.TestPlanExecDurations{
+		Total: len(rels),
+		List:  make([]apistructs.TestPlanCaseExecDuration, 0, len(rels)),
+	}
+	for _, rel := range rels {
+		item := apistructs.TestPlanCaseExecDuration{
+			RelationID: rel.ID,
+			TestCaseID: rel.TestCaseID,
+			Manual:     execDurationStats(samples[apistructs.TestCaseExecDurationSourceManual][rel.TestCaseID]),
+			Automated:  execDurationStats(samples[apistructs.TestCaseExecDurationSourceAutomated][rel.TestCaseID]),
+		}
+		if item.Manual != nil {
+			result.EstimatedManualSec += item.Manual.AvgSec
+		}
+		if item.Automated != nil {
+			result.EstimatedAutomatedSec += item.Automated.AvgSec
+		}
+		result.List = append(result.List, item)
+	}
+	return &result
+}
+
+// execDurationStats 统计最近 maxExecDurationSamples 次执行的耗时, 无记录时返回 nil
+func execDurationStats(durations []dao.TestPlanCaseExecDuration) *apistructs.TestCaseExecDurationStats {
+	if len(durations) == 0 {
+		return nil
+	}
+	if len(durations) > maxExecDurationSamples {
+		durations = durations[len(durations)-maxExecDurationSamples:]
+	}
+	secs := make([]uint64, 0, len(durations))
+	var sum uint64
+	for _, d := range durations {
+		secs = append(secs, d.DurationSec)
+		sum += d.DurationSec
+	}
+	sort.Slice(secs, func(i, j int) bool { return secs[i] < secs[j] })
+
+	n := uint64(len(secs))
+	median := secs[n/2]
+	if n%2 == 0 {
+		median = (secs[n/2-1] + secs[n/2] + 1) / 2
+	}
+	last := durations[len(durations)-1]
+	return &apistructs.TestCaseExecDurationStats{
+		Count:          len(durations),
+		AvgSec:         (sum + n/2) / n,
+		MedianSec:      median,
+		LastSec:        last.DurationSec,
+		LastExecutedAt: last.CreatedAt,
+	}
+}
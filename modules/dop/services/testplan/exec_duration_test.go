@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestApiTestCaseDurations(t *testing.T) {
+	pipeline := &apistructs.PipelineDetailDTO{
+		PipelineStages: []apistructs.PipelineStageDetailDTO{
+			{PipelineTasks: []apistructs.PipelineTaskDTO{
+				{Name: "1", Status: apistructs.PipelineStatusSuccess, CostTimeSec: 10},
+				{Name: "2", Status: apistructs.PipelineStatusFailed, CostTimeSec: 5},
+				{Name: "3", Status: apistructs.PipelineStatusRunning, CostTimeSec: 3},
+				{Name: "4", Status: apistructs.PipelineStatusNoNeedBySystem, CostTimeSec: 0},
+				{Name: "5", Status: apistructs.PipelineStatusSuccess, CostTimeSec: -1},
+				{Name: "not-a-case", Status: apistructs.PipelineStatusSuccess, CostTimeSec: 1},
+			}},
+		},
+	}
+	assert.Equal(t, map[uint64]uint64{1: 10, 2: 5}, apiTestCaseDurations(pipeline))
+}
+
+func TestCheckManualExecDuration(t *testing.T) {
+	sec := uint64(60)
+	testSetID := uint64(1)
+	assert.NoError(t, checkManualExecDuration(apistructs.TestPlanCaseRelBatchUpdateRequest{RelationIDs: []uint64{1, 2}}))
+	assert.NoError(t, checkManualExecDuration(apistructs.TestPlanCaseRelBatchUpdateRequest{
+		RelationIDs: []uint64{1}, ExecStatus: apistructs.CaseExecStatusSucc, ExecDurationSec: &sec,
+	}))
+	assert.Error(t, checkManualExecDuration(apistructs.TestPlanCaseRelBatchUpdateRequest{
+		RelationIDs: []uint64{1, 2}, ExecStatus: apistructs.CaseExecStatusSucc, ExecDurationSec: &sec,
+	}))
+	assert.Error(t, checkManualExecDuration(apistructs.TestPlanCaseRelBatchUpdateRequest{
+		RelationIDs: []uint64{1}, TestSetID: &testSetID, ExecStatus: apistructs.CaseExecStatusSucc, ExecDurationSec: &sec,
+	}))
+	assert.Error(t, checkManualExecDuration(apistructs.TestPlanCaseRelBatchUpdateRequest{
+		RelationIDs: []uint64{1}, ExecStatus: apistructs.CaseExecStatusInit, ExecDurationSec: &sec,
+	}))
+}
+
+func TestComputeExecDurations(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2021, 10, d, 10, 0, 0, 0, time.Local) }
+	manual := func(tcID, sec uint64, d int) dao.TestPlanCaseExecDuration {
+		return dao.TestPlanCaseExecDuration{BaseModel: dbengine.BaseModel{CreatedAt: at(d)}, TestCaseID: tcID,
+			Source: apistructs.TestCaseExecDurationSourceManual, DurationSec: sec}
+	}
+	automated := func(tcID, sec uint64, d int) dao.TestPlanCaseExecDuration {
+		return dao.TestPlanCaseExecDuration{BaseModel: dbengine.BaseModel{CreatedAt: at(d)}, TestCaseID: tcID,
+			Source: apistructs.TestCaseExecDurationSourceAutomated, DurationSec: sec}
+	}
+	rels := []dao.TestPlanCaseRel{
+		{BaseModel: dbengine.BaseModel{ID: 11}, TestCaseID: 1},
+		{BaseModel: dbengine.BaseModel{ID: 12}, TestCaseID: 2},
+		{BaseModel: dbengine.BaseModel{ID: 13}, TestCaseID: 3},
+	}
+	durations := []dao.TestPlanCaseExecDuration{
+		manual(1, 60, 1), manual(1, 120, 2), automated(1, 4, 2), manual(1, 90, 3), manual(1, 300, 4),
+		automated(2, 7, 3),
+	}
+
+	result := computeExecDurations(rels, durations)
+	assert.Equal(t, 3, result.Total)
+	assert.Len(t, result.List, 3)
+	assert.Equal(t, &apistructs.TestCaseExecDurationStats{
+		Count: 4, AvgSec: 143, MedianSec: 105, LastSec: 300, LastExecutedAt: at(4),
+	}, result.List[0].Manual)
+	assert.Equal(t, &apistructs.TestCaseExecDurationStats{
+		Count: 1, AvgSec: 4, MedianSec: 4, LastSec: 4, LastExecutedAt: at(2),
+	}, result.List[0].Automated)
+	assert.Nil(t, result.List[1].Manual)
+	assert.Equal(t, uint64(7), result.List[1].Automated.AvgSec)
+	assert.Nil(t, result.List[2].Manual)
+	assert.Nil(t, result.List[2].Automated)
+	assert.Equal(t, uint64(143), result.EstimatedManualSec)
+	assert.Equal(t, uint64(11), result.EstimatedAutomatedSec)
+}
+
+func TestExecDurationStatsRecentSamples(t *testing.T) {
+	var durations []dao.TestPlanCaseExecDuration
+	for i := 0; i < maxExecDurationSamples+5; i++ {
+		sec := uint64(10)
+		if i < 5 {
+			sec = 1000
+		}
+		durations = append(durations, dao.TestPlanCaseExecDuration{DurationSec: sec})
+	}
+	stats := execDurationStats(durations)
+	assert.Equal(t, maxExecDurationSamples, stats.Count)
+	assert.Equal(t, uint64(10), stats.AvgSec)
+	assert.Nil(t, execDurationStats(nil))
+}
@@ -34,6 +34,12 @@ func (t *TestPlan) GenerateReport(testPlanID uint64) (*apistructs.TestPlanReport
 	report.TestPlan = *tp
 	report.RelsCount = tp.RelsCount
 
+	// 自定义字段, 必填字段未填写时不允许生成报告
+	if err := checkRequiredReportFields(tp.ReportFields); err != nil {
+		return nil, apierrors.ErrGenerateTestPlanReport.InvalidParameter(err)
+	}
+	report.CustomFields = tp.ReportFields
+
 	// 接口总数
 	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{
 		TestPlanIDs: []uint64{testPlanID},
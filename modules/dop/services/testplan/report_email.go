@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/pkg/retry"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	reportEmailTemplateName  = "notify.testplan_report.email"
+	reportEmailRetryTimes    = 3
+	reportEmailRetryInterval = 10 * time.Second
+)
+
+// SendReportEmail 测试计划执行完成后生成测试报告并按配置发送邮件;
+// 发送失败只记录日志并重试, 不影响测试报告本身
+func (t *TestPlan) SendReportEmail(testPlanID, pipelineID uint64) error {
+	report, err := t.GenerateReport(testPlanID)
+	if err != nil {
+		return err
+	}
+	cfg := report.TestPlan.ReportEmail
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	project, err := t.bdl.GetProject(report.TestPlan.ProjectID)
+	if err != nil {
+		return err
+	}
+	org, err := t.bdl.GetOrg(project.OrgID)
+	if err != nil {
+		return err
+	}
+	if org.Locale == "" {
+		org.Locale = "zh-CN"
+	}
+
+	var ccAddrs []string
+	if cfg.CcMembers {
+		memberIDs := strutil.DedupSlice(append([]string{report.TestPlan.OwnerID}, report.TestPlan.PartnerIDs...), true)
+		users, err := t.bdl.ListUsers(apistructs.UserListRequest{Plaintext: true, UserIDs: memberIDs})
+		if err != nil {
+			return err
+		}
+		for _, user := range users.Users {
+			if user.Email != "" {
+				ccAddrs = append(ccAddrs, user.Email)
+			}
+		}
+	}
+	recipients, ccAddrs := splitReportEmailAddrs(cfg.Recipients, ccAddrs)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	params := genReportEmailParams(report, org.Name, project.Name, pipelineID, conf.UIPublicURL(), org.Locale)
+	if err := retry.DoWithInterval(func() error {
+		return t.bdl.CreateEmailNotifyWithCc(reportEmailTemplateName, params, org.Locale, org.ID, recipients, ccAddrs)
+	}, reportEmailRetryTimes, reportEmailRetryInterval); err != nil {
+		logrus.Errorf("failed to send report email of test plan %d, err: %v", testPlanID, err)
+	}
+	return nil
+}
+
+// splitReportEmailAddrs 去除抄送中与收件人重复的地址; 未配置收件人时将抄送作为收件人
+func splitReportEmailAddrs(recipients, ccAddrs []string) ([]string, []string) {
+	recipients = strutil.DedupSlice(recipients, true)
+	if len(recipients) == 0 {
+		return strutil.DedupSlice(ccAddrs, true), nil
+	}
+	to := make(map[string]struct{}, len(recipients))
+	for _, addr := range recipients {
+		to[strings.ToLower(addr)] = struct{}{}
+	}
+	var cc []string
+	for _, addr := range strutil.DedupSlice(ccAddrs, true) {
+		if _, ok := to[strings.ToLower(addr)]; !ok {
+			cc = append(cc, addr)
+		}
+	}
+	return recipients, cc
+}
+
+// genReportEmailParams 生成测试报告邮件的模板参数
+func genReportEmailParams(report *apistructs.TestPlanReport, orgName, projectName string, pipelineID uint64, uiPublicURL, locale string) map[string]string {
+	tp := report.TestPlan
+	params := map[string]string{
+		"orgName":      orgName,
+		"projectName":  projectName,
+		"testPlanName": tp.Name,
+		"caseTotal":    strconv.FormatUint(report.RelsCount.Total, 10),
+		"caseSucc":     strconv.FormatUint(report.RelsCount.Succ, 10),
+		"caseFail":     strconv.FormatUint(report.RelsCount.Fail, 10),
+		"caseBlock":    strconv.FormatUint(report.RelsCount.Block, 10),
+		"caseInit":     strconv.FormatUint(report.RelsCount.Init, 10),
+		"casePassRate": passRate(report.RelsCount.Succ, report.RelsCount.Total),
+		"apiTotal":     strconv.FormatUint(report.APICount.Total, 10),
+		"apiPassed":    strconv.FormatUint(report.APICount.Passed, 10),
+		"apiFailed":    strconv.FormatUint(report.APICount.Failed, 10),
+		"apiPassRate":  passRate(report.APICount.Passed, report.APICount.Total),
+		"customFields": renderReportFieldsHTML(report.CustomFields),
+	}
+	params["testPlanEmailLink"] = fmt.Sprintf("%s/%s/dop/projects/%d/testing/testplan/%d",
+		uiPublicURL, orgName, tp.ProjectID, tp.ID)
+	params["pipelineEmailLink"] = fmt.Sprintf("%s?pipelineID=%d", params["testPlanEmailLink"], pipelineID)
+	if locale == "zh-CN" {
+		params["title"] = fmt.Sprintf("测试计划 %s 执行报告 (%s/%s 项目)", tp.Name, orgName, projectName)
+	} else {
+		params["title"] = fmt.Sprintf("Report of test plan %s (%s/%s project)", tp.Name, orgName, projectName)
+	}
+	return params
+}
+
+// passRate 计算通过率, 保留两位小数
+func passRate(passed, total uint64) string {
+	if total == 0 {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", float64(passed*100)/float64(total))
+}
+
+// normalizeReportEmailConfig 校验并去重收件人邮箱
+func normalizeReportEmailConfig(cfg apistructs.TestPlanReportEmailConfig) (*apistructs.TestPlanReportEmailConfig, error) {
+	var recipients []string
+	for _, addr := range cfg.Recipients {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("invalid email: %s", addr)
+		}
+		recipients = append(recipients, addr)
+	}
+	cfg.Recipients = strutil.DedupSlice(recipients, true)
+	if cfg.Enabled && len(cfg.Recipients) == 0 && !cfg.CcMembers {
+		return nil, fmt.Errorf("missing report email recipients")
+	}
+	return &cfg, nil
+}
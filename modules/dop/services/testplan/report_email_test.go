@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestSplitReportEmailAddrs(t *testing.T) {
+	to, cc := splitReportEmailAddrs([]string{"a@erda.cloud", "b@erda.cloud", "a@erda.cloud"}, []string{"B@erda.cloud", "c@erda.cloud"})
+	assert.Equal(t, []string{"a@erda.cloud", "b@erda.cloud"}, to)
+	assert.Equal(t, []string{"c@erda.cloud"}, cc)
+
+	to, cc = splitReportEmailAddrs(nil, []string{"c@erda.cloud"})
+	assert.Equal(t, []string{"c@erda.cloud"}, to)
+	assert.Empty(t, cc)
+}
+
+func TestGenReportEmailParams(t *testing.T) {
+	report := &apistructs.TestPlanReport{
+		TestPlan:  apistructs.TestPlan{ID: 3, Name: "regression", ProjectID: 2},
+		RelsCount: apistructs.TestPlanRelsCount{Total: 3, Succ: 2, Fail: 1},
+		APICount:  apistructs.TestCaseAPICount{Total: 0},
+		CustomFields: []apistructs.TestPlanReportField{
+			{Name: "Version", Type: apistructs.TestPlanReportFieldTypeText, Value: "v1.3 <rc>"},
+			{Name: "Sign-off", Type: apistructs.TestPlanReportFieldTypeText},
+		},
+	}
+	params := genReportEmailParams(report, "erda", "demo", 10, "https://erda.cloud", "en-US")
+	assert.Equal(t, "66.67", params["casePassRate"])
+	assert.Equal(t, "0.00", params["apiPassRate"])
+	assert.Equal(t, "https://erda.cloud/erda/dop/projects/2/testing/testplan/3", params["testPlanEmailLink"])
+	assert.Equal(t, "https://erda.cloud/erda/dop/projects/2/testing/testplan/3?pipelineID=10", params["pipelineEmailLink"])
+	assert.Equal(t, "Report of test plan regression (erda/demo project)", params["title"])
+	assert.Equal(t, "<p>Version: v1.3 &lt;rc&gt;</p>", params["customFields"])
+}
+
+func TestNormalizeReportEmailConfig(t *testing.T) {
+	cfg, err := normalizeReportEmailConfig(apistructs.TestPlanReportEmailConfig{
+		Enabled:    true,
+		Recipients: []string{" a@erda.cloud ", "", "a@erda.cloud"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a@erda.cloud"}, cfg.Recipients)
+
+	_, err = normalizeReportEmailConfig(apistructs.TestPlanReportEmailConfig{Enabled: true, Recipients: []string{"invalid"}})
+	assert.Error(t, err)
+
+	_, err = normalizeReportEmailConfig(apistructs.TestPlanReportEmailConfig{Enabled: true})
+	assert.Error(t, err)
+
+	_, err = normalizeReportEmailConfig(apistructs.TestPlanReportEmailConfig{Enabled: true, CcMembers: true})
+	assert.NoError(t, err)
+}
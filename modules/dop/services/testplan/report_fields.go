@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	reportFieldDateFormat = "2006-01-02"
+	// maxReportFields 测试计划最多可配置的自定义字段数
+	maxReportFields = 20
+)
+
+// normalizeReportFields 校验测试报告自定义字段的定义及已填写的值, 字段名不可重复
+func normalizeReportFields(fields []apistructs.TestPlanReportField) (dao.TestPlanReportFields, error) {
+	if len(fields) > maxReportFields {
+		return nil, fmt.Errorf("too many report fields, max: %d", maxReportFields)
+	}
+	names := make(map[string]struct{}, len(fields))
+	results := make(dao.TestPlanReportFields, 0, len(fields))
+	for _, field := range fields {
+		field.Name = strings.TrimSpace(field.Name)
+		field.Value = strings.TrimSpace(field.Value)
+		if field.Name == "" {
+			return nil, fmt.Errorf("missing report field name")
+		}
+		if _, ok := names[field.Name]; ok {
+			return nil, fmt.Errorf("duplicate report field: %s", field.Name)
+		}
+		names[field.Name] = struct{}{}
+		if !field.Type.IsValid() {
+			return nil, fmt.Errorf("invalid type of report field %s: %s", field.Name, field.Type)
+		}
+		if field.Type == apistructs.TestPlanReportFieldTypeEnum {
+			var options []string
+			for _, option := range field.Options {
+				if option = strings.TrimSpace(option); option != "" {
+					options = append(options, option)
+				}
+			}
+			field.Options = strutil.DedupSlice(options)
+			if len(field.Options) == 0 {
+				return nil, fmt.Errorf("missing options of enum report field %s", field.Name)
+			}
+		} else {
+			field.Options = nil
+		}
+		if err := checkReportFieldValue(field); err != nil {
+			return nil, err
+		}
+		results = append(results, field)
+	}
+	return results, nil
+}
+
+// checkReportFieldValue 校验字段的值与类型是否匹配, 未填写时不校验
+func checkReportFieldValue(field apistructs.TestPlanReportField) error {
+	if field.Value == "" {
+		return nil
+	}
+	switch field.Type {
+	case apistructs.TestPlanReportFieldTypeDate:
+		if _, err := time.Parse(reportFieldDateFormat, field.Value); err != nil {
+			return fmt.Errorf("invalid date of report field %s: %s", field.Name, field.Value)
+		}
+	case apistructs.TestPlanReportFieldTypeEnum:
+		if !strutil.Exist(field.Options, field.Value) {
+			return fmt.Errorf("invalid value of report field %s: %s", field.Name, field.Value)
+		}
+	}
+	return nil
+}
+
+// checkRequiredReportFields 生成报告前校验必填的自定义字段均已填写
+func checkRequiredReportFields(fields []apistructs.TestPlanReportField) error {
+	var missing []string
+	for _, field := range fields {
+		if field.Required && strings.TrimSpace(field.Value) == "" {
+			missing = append(missing, field.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required report fields are not filled: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// renderReportFieldsHTML 将已填写的自定义字段渲染为邮件中的段落, 未填写的字段不展示
+func renderReportFieldsHTML(fields []apistructs.TestPlanReportField) string {
+	var b strings.Builder
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("<p>%s: %s</p>", html.EscapeString(field.Name), html.EscapeString(field.Value)))
+	}
+	return b.String()
+}
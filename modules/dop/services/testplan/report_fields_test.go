@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestNormalizeReportFields(t *testing.T) {
+	fields, err := normalizeReportFields([]apistructs.TestPlanReportField{
+		{Name: " Version ", Type: apistructs.TestPlanReportFieldTypeText, Required: true, Options: []string{"ignored"}, Value: " v1.3 "},
+		{Name: "Release Date", Type: apistructs.TestPlanReportFieldTypeDate, Value: "2021-10-05"},
+		{Name: "Env", Type: apistructs.TestPlanReportFieldTypeEnum, Options: []string{"staging", " prod ", "staging", ""}, Value: "prod"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Version", fields[0].Name)
+	assert.Equal(t, "v1.3", fields[0].Value)
+	assert.Nil(t, fields[0].Options)
+	assert.Equal(t, []string{"staging", "prod"}, fields[2].Options)
+
+	invalids := [][]apistructs.TestPlanReportField{
+		{{Name: "", Type: apistructs.TestPlanReportFieldTypeText}},
+		{{Name: "A", Type: "NUMBER"}},
+		{{Name: "A", Type: apistructs.TestPlanReportFieldTypeText}, {Name: "A ", Type: apistructs.TestPlanReportFieldTypeDate}},
+		{{Name: "A", Type: apistructs.TestPlanReportFieldTypeEnum}},
+		{{Name: "A", Type: apistructs.TestPlanReportFieldTypeEnum, Options: []string{"x"}, Value: "y"}},
+		{{Name: "A", Type: apistructs.TestPlanReportFieldTypeDate, Value: "2021/10/05"}},
+		make([]apistructs.TestPlanReportField, maxReportFields+1),
+	}
+	for _, invalid := range invalids {
+		_, err := normalizeReportFields(invalid)
+		assert.Error(t, err)
+	}
+
+	fields, err = normalizeReportFields([]apistructs.TestPlanReportField{})
+	assert.NoError(t, err)
+	assert.NotNil(t, fields)
+	assert.Len(t, fields, 0)
+}
+
+func TestCheckRequiredReportFields(t *testing.T) {
+	assert.NoError(t, checkRequiredReportFields(nil))
+	assert.NoError(t, checkRequiredReportFields([]apistructs.TestPlanReportField{
+		{Name: "Version", Required: true, Value: "v1.3"},
+		{Name: "Build"},
+	}))
+	err := checkRequiredReportFields([]apistructs.TestPlanReportField{
+		{Name: "Version", Required: true, Value: " "},
+		{Name: "Sign-off", Required: true},
+	})
+	assert.EqualError(t, err, "required report fields are not filled: Version, Sign-off")
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+// RequirementCoverage 统计需求的测试覆盖情况, 需求关联了测试计划中的用例即视为已覆盖;
+// 用例的执行结果取其在项目所有测试计划中最近一次执行的结果
+func (t *TestPlan) RequirementCoverage(req apistructs.RequirementCoverageRequest) (*apistructs.RequirementCoverageResponseData, error) {
+	if req.ProjectID == 0 {
+		return nil, apierrors.ErrGetRequirementCoverage.MissingParameter("projectID")
+	}
+
+	// 查询需求
+	issues, err := t.listCoverageRequirements(req)
+	if err != nil {
+		return nil, apierrors.ErrGetRequirementCoverage.InternalError(err)
+	}
+	result := apistructs.RequirementCoverageResponseData{List: make([]apistructs.RequirementCoverage, 0, len(issues))}
+	if len(issues) == 0 {
+		return &result, nil
+	}
+
+	// 查询需求关联的用例
+	var issueIDs []uint64
+	for _, issue := range issues {
+		issueIDs = append(issueIDs, issue.ID)
+	}
+	issueCaseRels, err := t.db.ListIssueTestCaseRelationsByIssueIDs(issueIDs)
+	if err != nil {
+		return nil, apierrors.ErrGetRequirementCoverage.InternalError(err)
+	}
+	issueTestCaseIDs := make(map[uint64][]uint64)
+	var testCaseIDs []uint64
+	for _, rel := range issueCaseRels {
+		issueTestCaseIDs[rel.IssueID] = append(issueTestCaseIDs[rel.IssueID], rel.TestCaseID)
+		testCaseIDs = append(testCaseIDs, rel.TestCaseID)
+	}
+	testCaseIDs = strutil.DedupUint64Slice(testCaseIDs, true)
+
+	// 查询用例名称及最近一次执行结果
+	testCaseNames := make(map[uint64]string)
+	latestRels := make(map[uint64]dao.TestPlanCaseRel)
+	if len(testCaseIDs) > 0 {
+		tcs, err := t.db.ListTestCasesByIDs(testCaseIDs)
+		if err != nil {
+			return nil, apierrors.ErrGetRequirementCoverage.InternalError(err)
+		}
+		for _, tc := range tcs {
+			testCaseNames[tc.ID] = tc.Name
+		}
+		rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{TestCaseIDs: testCaseIDs})
+		if err != nil {
+			return nil, apierrors.ErrGetRequirementCoverage.InternalError(err)
+		}
+		latestRels = latestExecutedRels(rels)
+	}
+
+	for _, issue := range issues {
+		coverage := apistructs.RequirementCoverage{
+			IssueID:     issue.ID,
+			Title:       issue.Title,
+			IterationID: issue.IterationID,
+			TestCases:   make([]apistructs.RequirementCoverageCase, 0),
+		}
+		for _, tcID := range strutil.DedupUint64Slice(issueTestCaseIDs[issue.ID], true) {
+			tc := apistructs.RequirementCoverageCase{
+				TestCaseID: tcID,
+				Name:       testCaseNames[tcID],
+				ExecStatus: apistructs.CaseExecStatusInit,
+			}
+			if rel, ok := latestRels[tcID]; ok {
+				updatedAt := rel.UpdatedAt
+				tc.TestPlanID = rel.TestPlanID
+				tc.TestPlanCaseRelID = rel.ID
+				tc.ExecStatus = rel.ExecStatus
+				tc.ExecutorID = rel.ExecutorID
+				tc.UpdatedAt = &updatedAt
+			}
+			coverage.TestCases = append(coverage.TestCases, tc)
+			countExecStatus(&coverage.ExecStatusCount, tc.ExecStatus)
+		}
+		coverage.Covered = len(coverage.TestCases) > 0
+		if coverage.Covered {
+			result.Covered++
+		} else {
+			result.Uncovered++
+		}
+		result.List = append(result.List, coverage)
+	}
+	result.Total = uint64(len(result.List))
+
+	return &result, nil
+}
+
+// listCoverageRequirements 查询项目 (迭代) 下未删除的需求, 指定 IssueIDs 时只返回其中的需求
+func (t *TestPlan) listCoverageRequirements(req apistructs.RequirementCoverageRequest) ([]dao.Issue, error) {
+	var (
+		issues []dao.Issue
+		err    error
+	)
+	if len(req.IssueIDs) > 0 {
+		ids := make([]int64, 0, len(req.IssueIDs))
+		for _, id := range strutil.DedupUint64Slice(req.IssueIDs, true) {
+			ids = append(ids, int64(id))
+		}
+		issues, err = t.db.ListIssueByIDs(ids)
+	} else {
+		issues, err = t.db.ListIssue(apistructs.IssueListRequest{
+			ProjectID:   req.ProjectID,
+			IterationID: req.IterationID,
+			Type:        []apistructs.IssueType{apistructs.IssueTypeRequirement},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	requirements := make([]dao.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Deleted || issue.ProjectID != req.ProjectID || issue.Type != apistructs.IssueTypeRequirement {
+			continue
+		}
+		if req.IterationID != 0 && issue.IterationID != req.IterationID {
+			continue
+		}
+		requirements = append(requirements, issue)
+	}
+	return requirements, nil
+}
+
+// latestExecutedRels 返回每个用例最近一次执行的测试计划用例关系, 从未执行过的用例取最近更新的关系
+func latestExecutedRels(rels []dao.TestPlanCaseRel) map[uint64]dao.TestPlanCaseRel {
+	latest := make(map[uint64]dao.TestPlanCaseRel)
+	for _, rel := range rels {
+		exist, ok := latest[rel.TestCaseID]
+		if !ok {
+			latest[rel.TestCaseID] = rel
+			continue
+		}
+		relExecuted := rel.ExecStatus != apistructs.CaseExecStatusInit
+		existExecuted := exist.ExecStatus != apistructs.CaseExecStatusInit
+		if relExecuted != existExecuted {
+			if relExecuted {
+				latest[rel.TestCaseID] = rel
+			}
+			continue
+		}
+		if rel.UpdatedAt.After(exist.UpdatedAt) {
+			latest[rel.TestCaseID] = rel
+		}
+	}
+	return latest
+}
+
+func countExecStatus(count *apistructs.TestPlanRelsCount, status apistructs.TestCaseExecStatus) {
+	count.Total++
+	switch status {
+	case apistructs.CaseExecStatusSucc:
+		count.Succ++
+	case apistructs.CaseExecStatusFail:
+		count.Fail++
+	case apistructs.CaseExecStatusBlocked:
+		count.Block++
+	default:
+		count.Init++
+	}
+}
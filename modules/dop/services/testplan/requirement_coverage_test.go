@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestLatestExecutedRels(t *testing.T) {
+	now := time.Now()
+	newRel := func(id, testCaseID uint64, status apistructs.TestCaseExecStatus, updatedAt time.Time) dao.TestPlanCaseRel {
+		rel := dao.TestPlanCaseRel{TestCaseID: testCaseID, ExecStatus: status}
+		rel.ID = id
+		rel.UpdatedAt = updatedAt
+		return rel
+	}
+	rels := []dao.TestPlanCaseRel{
+		newRel(1, 1, apistructs.CaseExecStatusFail, now.Add(-2*time.Hour)),
+		newRel(2, 1, apistructs.CaseExecStatusSucc, now.Add(-time.Hour)),
+		// 最近加入新计划但尚未执行，不覆盖已有的执行结果
+		newRel(3, 1, apistructs.CaseExecStatusInit, now),
+		newRel(4, 2, apistructs.CaseExecStatusInit, now.Add(-time.Hour)),
+		newRel(5, 2, apistructs.CaseExecStatusInit, now),
+	}
+
+	latest := latestExecutedRels(rels)
+	assert.Equal(t, uint64(2), latest[1].ID)
+	assert.Equal(t, uint64(5), latest[2].ID)
+}
+
+func TestCountExecStatus(t *testing.T) {
+	var count apistructs.TestPlanRelsCount
+	for _, status := range []apistructs.TestCaseExecStatus{
+		apistructs.CaseExecStatusSucc, apistructs.CaseExecStatusSucc, apistructs.CaseExecStatusFail, apistructs.CaseExecStatusInit,
+	} {
+		countExecStatus(&count, status)
+	}
+	assert.Equal(t, apistructs.TestPlanRelsCount{Total: 4, Init: 1, Succ: 2, Fail: 1}, count)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// RerunFailedAPITest 重跑测试计划某次接口测试中未通过的用例, 沿用原流水线的测试环境;
+// 新流水线结束后由 MergeRerunAPITestResult 将结果合并回测试计划用例的执行状态
+func (t *TestPlan) RerunFailedAPITest(req apistructs.TestPlanAPITestRerunFailedRequest) (*apistructs.TestPlanAPITestRerunFailedResult, error) {
+	if req.PipelineID == 0 {
+		return nil, apierrors.ErrTestPlanRerunFailedAPITest.MissingParameter("pipelineID")
+	}
+	tp, err := t.Get(req.TestPlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := t.bdl.GetPipeline(req.PipelineID)
+	if err != nil {
+		return nil, apierrors.ErrTestPlanRerunFailedAPITest.InternalError(err)
+	}
+	if err := checkEndedAPITestPipeline(&pipeline.PipelineDTO, req.TestPlanID); err != nil {
+		return nil, apierrors.ErrTestPlanRerunFailedAPITest.InvalidParameter(err)
+	}
+	envID, err := parseAPITestEnvID(pipeline.YmlContent)
+	if err != nil {
+		return nil, apierrors.ErrTestPlanRerunFailedAPITest.InternalError(err)
+	}
+
+	rels, apis, err := t.listTestPlanCaseRelAPIs(tp)
+	if err != nil {
+		return nil, apierrors.ErrTestPlanRerunFailedAPITest.InternalError(err)
+	}
+	cases := filterFailedRerunCases(rels, apis, req.PipelineID)
+	if len(cases) == 0 {
+		return nil, apierrors.ErrTestPlanRerunFailedAPITest.InvalidParameter(
+			fmt.Sprintf("no failed cases in pipeline %d", req.PipelineID))
+	}
+	tcIDs := make([]uint64, 0, len(cases))
+	for i := range cases {
+		tcIDs = append(tcIDs, cases[i].TestCaseID)
+	}
+	tcs, _, err := t.testCaseSvc.ListTestCases(apistructs.TestCaseListRequest{
+		IDs:                   tcIDs,
+		AllowMissingProjectID: true,
+		AllowEmptyTestSetIDs:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tcNames := make(map[uint64]string, len(tcs))
+	for _, tc := range tcs {
+		tcNames[tc.ID] = tc.Name
+	}
+	for i := range cases {
+		cases[i].Name = tcNames[cases[i].TestCaseID]
+	}
+
+	pipelineID, err := t.testCaseSvc.ExecuteAPIs(apistructs.ApiTestsActionRequest{
+		ProjectID:        int64(tp.ProjectID),
+		TestPlanID:       int64(req.TestPlanID),
+		ProjectTestEnvID: int64(envID),
+		UsecaseIDs:       tcIDs,
+		Labels: map[string]string{
+			apistructs.LabelTestPlanRerunFromPipelineID: strconv.FormatUint(req.PipelineID, 10),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apistructs.TestPlanAPITestRerunFailedResult{
+		PipelineID:          pipelineID,
+		RerunFromPipelineID: req.PipelineID,
+		EnvID:               envID,
+		Cases:               cases,
+	}, nil
+}
+
+// MergeRerunAPITestResult 重跑流水线结束后, 根据各用例在该流水线中的接口执行结果更新测试计划用例的执行状态
+func (t *TestPlan) MergeRerunAPITestResult(pipelineID uint64, userID string, labels map[string]string) error {
+	if labels[apistructs.LabelTestPlanRerunFromPipelineID] == "" {
+		return nil
+	}
+	testPlanID, err := strconv.ParseUint(labels[apistructs.LabelTestPlanID], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid test plan id label, err: %v", err)
+	}
+	tp, err := t.Get(testPlanID)
+	if err != nil {
+		return err
+	}
+	rels, apis, err := t.listTestPlanCaseRelAPIs(tp)
+	if err != nil {
+		return err
+	}
+	statuses := mergeRerunExecStatuses(rels, apis, pipelineID)
+	for _, status := range []apistructs.TestCaseExecStatus{apistructs.CaseExecStatusSucc, apistructs.CaseExecStatusFail} {
+		if len(statuses[status]) == 0 {
+			continue
+		}
+		if err := t.db.BatchUpdateTestPlanCaseRels(apistructs.TestPlanCaseRelBatchUpdateRequest{
+			TestPlanID:  testPlanID,
+			RelationIDs: statuses[status],
+			ExecStatus:  status,
+		}); err != nil {
+			return err
+		}
+		t.recordExecHistories(testPlanID, statuses[status], status, userID)
+	}
+	if err := t.AutoCreateBugs(testPlanID, statuses[apistructs.CaseExecStatusFail], userID); err != nil {
+		logrus.Errorf("failed to auto create bugs of test plan %d, err: %v", testPlanID, err)
+	}
+	logrus.Infof("merged rerun api test result of test plan %d, pipelineID: %d, passed: %d, failed: %d",
+		testPlanID, pipelineID, len(statuses[apistructs.CaseExecStatusSucc]), len(statuses[apistructs.CaseExecStatusFail]))
+	return nil
+}
+
+// checkEndedAPITestPipeline 校验流水线是测试计划已结束的接口测试
+func checkEndedAPITestPipeline(pipeline *apistructs.PipelineDTO, testPlanID uint64) error {
+	if pipeline.Source != apistructs.PipelineSourceAPITest ||
+		pipeline.Labels[apistructs.LabelTestPlanID] != strconv.FormatUint(testPlanID, 10) {
+		return fmt.Errorf("pipeline %d is not an api test of test plan %d", pipeline.ID, testPlanID)
+	}
+	if !pipeline.Status.IsEndStatus() {
+		return fmt.Errorf("pipeline %d is still running", pipeline.ID)
+	}
+	return nil
+}
+
+// listTestPlanCaseRelAPIs 获取测试计划下的用例及各用例的接口
+func (t *TestPlan) listTestPlanCaseRelAPIs(tp *apistructs.TestPlan) ([]dao.TestPlanCaseRel, map[uint64][]*dbclient.ApiTest, error) {
+	rels, err := t.db.ListTestPlanCaseRels(apistructs.TestPlanCaseRelListRequest{TestPlanIDs: []uint64{tp.ID}})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rels) == 0 {
+		return rels, nil, nil
+	}
+	tcIDs := make([]uint64, 0, len(rels))
+	for _, rel := range rels {
+		tcIDs = append(tcIDs, rel.TestCaseID)
+	}
+	apis, err := dbclient.ListAPIsByTestCaseIDs(tp.ProjectID, tcIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rels, apis, nil
+}
+
+// filterFailedRerunCases 筛选在指定流水线中存在未通过接口的用例
+func filterFailedRerunCases(rels []dao.TestPlanCaseRel, apis map[uint64][]*dbclient.ApiTest, pipelineID uint64) []apistructs.TestPlanRerunCase {
+	var cases []apistructs.TestPlanRerunCase
+	for _, rel := range rels {
+		var failed int
+		for _, api := range apis[rel.TestCaseID] {
+			if uint64(api.PipelineID) == pipelineID && api.Status != string(apistructs.ApiTestPassed) {
+				failed++
+			}
+		}
+		if failed > 0 {
+			cases = append(cases, apistructs.TestPlanRerunCase{
+				RelationID:     rel.ID,
+				TestCaseID:     rel.TestCaseID,
+				FailedAPICount: failed,
+			})
+		}
+	}
+	return cases
+}
+
+// mergeRerunExecStatuses 按在指定流水线中的接口执行结果将用例分为通过和未通过, 未在该流水线中执行的用例保持原状态
+func mergeRerunExecStatuses(rels []dao.TestPlanCaseRel, apis map[uint64][]*dbclient.ApiTest, pipelineID uint64) map[apistructs.TestCaseExecStatus][]uint64 {
+	statuses := make(map[apistructs.TestCaseExecStatus][]uint64)
+	for _, rel := range rels {
+		executed, passed := false, true
+		for _, api := range apis[rel.TestCaseID] {
+			if uint64(api.PipelineID) != pipelineID {
+				continue
+			}
+			executed = true
+			if api.Status != string(apistructs.ApiTestPassed) {
+				passed = false
+			}
+		}
+		if !executed {
+			continue
+		}
+		status := apistructs.CaseExecStatusFail
+		if passed {
+			status = apistructs.CaseExecStatusSucc
+		}
+		statuses[status] = append(statuses[status], rel.ID)
+	}
+	return statuses
+}
+
+// parseAPITestEnvID 从接口测试流水线的 yml 中解析测试环境 id
+func parseAPITestEnvID(ymlContent string) (uint64, error) {
+	var yml apistructs.PipelineYml
+	if err := yaml.Unmarshal([]byte(ymlContent), &yml); err != nil {
+		return 0, fmt.Errorf("failed to parse pipeline yml, err: %v", err)
+	}
+	envID := yml.Envs["PROJECT_TEST_ENV_ID"]
+	if envID == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(envID, 10, 64)
+}
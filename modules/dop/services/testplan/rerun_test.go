@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/dbclient"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func newRerunTestData() ([]dao.TestPlanCaseRel, map[uint64][]*dbclient.ApiTest) {
+	rels := []dao.TestPlanCaseRel{
+		{BaseModel: dbengine.BaseModel{ID: 1}, TestCaseID: 11},
+		{BaseModel: dbengine.BaseModel{ID: 2}, TestCaseID: 12},
+		{BaseModel: dbengine.BaseModel{ID: 3}, TestCaseID: 13},
+	}
+	apis := map[uint64][]*dbclient.ApiTest{
+		11: {
+			{PipelineID: 100, Status: string(apistructs.ApiTestPassed)},
+			{PipelineID: 100, Status: string(apistructs.ApiTestFailed)},
+		},
+		12: {
+			{PipelineID: 100, Status: string(apistructs.ApiTestPassed)},
+		},
+		13: {
+			{PipelineID: 99, Status: string(apistructs.ApiTestFailed)},
+		},
+	}
+	return rels, apis
+}
+
+func TestFilterFailedRerunCases(t *testing.T) {
+	rels, apis := newRerunTestData()
+	cases := filterFailedRerunCases(rels, apis, 100)
+	assert.Equal(t, []apistructs.TestPlanRerunCase{
+		{RelationID: 1, TestCaseID: 11, FailedAPICount: 1},
+	}, cases)
+	assert.Empty(t, filterFailedRerunCases(rels, apis, 101))
+}
+
+func TestMergeRerunExecStatuses(t *testing.T) {
+	rels, apis := newRerunTestData()
+	// 重跑时用例下所有接口都会重新执行
+	for _, api := range append(apis[11], apis[13]...) {
+		api.PipelineID = 101
+	}
+	apis[11][1].Status = string(apistructs.ApiTestPassed)
+
+	statuses := mergeRerunExecStatuses(rels, apis, 101)
+	assert.Equal(t, []uint64{3}, statuses[apistructs.CaseExecStatusFail])
+	assert.Equal(t, []uint64{1}, statuses[apistructs.CaseExecStatusSucc])
+
+	apis[13][0].Status = string(apistructs.ApiTestPassed)
+	statuses = mergeRerunExecStatuses(rels, apis, 101)
+	assert.Equal(t, []uint64{1, 3}, statuses[apistructs.CaseExecStatusSucc])
+	assert.Empty(t, statuses[apistructs.CaseExecStatusFail])
+}
+
+func TestParseAPITestEnvID(t *testing.T) {
+	envID, err := parseAPITestEnvID("version: \"1.1\"\nenvs:\n  PROJECT_TEST_ENV_ID: \"8\"\nstages: []\n")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), envID)
+
+	envID, err = parseAPITestEnvID("version: \"1.1\"\nstages: []\n")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), envID)
+}
@@ -79,11 +79,19 @@ func (t *TestPlan) CreateCaseRelations(req apistructs.TestPlanCaseRelCreateReque
 	}
 
 	// 批量插入
-	var rels []dao.TestPlanCaseRel
+	var (
+		rels            []dao.TestPlanCaseRel
+		unapprovedCount uint64
+	)
 	for _, tc := range tcs {
 		if _, ok := existTcIDMap[uint64(tc.ID)]; ok {
 			continue
 		}
+		// 测试计划只允许关联评审通过的用例
+		if tp.ApprovedCasesOnly && tc.ReviewState != apistructs.TestCaseReviewStateApproved {
+			unapprovedCount++
+			continue
+		}
 		rel := dao.TestPlanCaseRel{
 			TestPlanID: tp.ID,
 			TestSetID:  tc.TestSetID,
@@ -98,7 +106,7 @@ func (t *TestPlan) CreateCaseRelations(req apistructs.TestPlanCaseRelCreateReque
 	}
 
 	// result
-	result := apistructs.TestPlanCaseRelCreateResult{TotalCount: uint64(len(rels))}
+	result := apistructs.TestPlanCaseRelCreateResult{TotalCount: uint64(len(rels)), UnapprovedCount: unapprovedCount}
 
 	return &result, nil
 }
@@ -235,6 +243,9 @@ func (t *TestPlan) BatchUpdateTestPlanCaseRels(req apistructs.TestPlanCaseRelBat
 	if len(req.RelationIDs) == 0 && req.TestSetID == nil {
 		return nil
 	}
+	if err := checkManualExecDuration(req); err != nil {
+		return apierrors.ErrBatchUpdateTestPlanCaseRels.InvalidParameter(err)
+	}
 
 	// 处理 relationIDs
 	if req.TestSetID != nil {
@@ -281,6 +292,17 @@ func (t *TestPlan) BatchUpdateTestPlanCaseRels(req apistructs.TestPlanCaseRelBat
 	if err := t.db.BatchUpdateTestPlanCaseRels(req); err != nil {
 		return apierrors.ErrBatchUpdateTestPlanCaseRels.InternalError(err)
 	}
+	t.recordExecHistories(req.TestPlanID, req.RelationIDs, req.ExecStatus, req.UserID)
+	t.recordManualExecDuration(req)
+
+	// 执行未通过时按测试计划配置自动创建缺陷, 失败不影响用例状态更新
+	if req.ExecStatus == apistructs.CaseExecStatusFail {
+		go func() {
+			if err := t.AutoCreateBugs(req.TestPlanID, req.RelationIDs, req.UserID); err != nil {
+				logrus.Errorf("failed to auto create bugs of test plan %d, err: %v", req.TestPlanID, err)
+			}
+		}()
+	}
 
 	return nil
 }
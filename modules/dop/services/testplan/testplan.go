@@ -30,6 +30,7 @@ import (
 	"github.com/erda-project/erda/modules/dop/services/autotest"
 	"github.com/erda-project/erda/modules/dop/services/issue"
 	"github.com/erda-project/erda/modules/dop/services/issuestate"
+	"github.com/erda-project/erda/modules/dop/services/issuestream"
 	"github.com/erda-project/erda/modules/dop/services/testcase"
 	"github.com/erda-project/erda/modules/dop/services/testset"
 	"github.com/erda-project/erda/pkg/strutil"
@@ -44,6 +45,7 @@ type TestPlan struct {
 	autotest      *autotest.Service
 	issueSvc      *issue.Issue
 	issueStateSvc *issuestate.IssueState
+	issueStream   *issuestream.IssueStream
 }
 
 // Option
@@ -104,6 +106,12 @@ func WithIssueState(issueStateSvc *issuestate.IssueState) Option {
 	}
 }
 
+func WithIssueStream(issueStream *issuestream.IssueStream) Option {
+	return func(svc *TestPlan) {
+		svc.issueStream = issueStream
+	}
+}
+
 // Create 创建测试计划
 func (t *TestPlan) Create(req apistructs.TestPlanCreateRequest) (uint64, error) {
 	// req params check
@@ -146,6 +154,8 @@ func (t *TestPlan) Create(req apistructs.TestPlanCreateRequest) (uint64, error)
 		CreatorID: req.UserID,
 		UpdaterID: req.UserID,
 		Type:      apistructs.TestPlanTypeManual,
+
+		ApprovedCasesOnly: req.ApprovedCasesOnly,
 	}
 	// 自动化测试计划
 	if req.IsAutoTest {
@@ -220,6 +230,27 @@ func (t *TestPlan) Update(req apistructs.TestPlanUpdateRequest) error {
 		testPlan.EndedAt = &t
 	}
 
+	if req.ReportEmail != nil {
+		reportEmail, err := normalizeReportEmailConfig(*req.ReportEmail)
+		if err != nil {
+			return apierrors.ErrUpdateTestPlan.InvalidParameter(err)
+		}
+		testPlan.ReportEmail = (*dao.TestPlanReportEmail)(reportEmail)
+	}
+	if req.AutoCreateBug != nil {
+		testPlan.AutoCreateBug = *req.AutoCreateBug
+	}
+	if req.ApprovedCasesOnly != nil {
+		testPlan.ApprovedCasesOnly = *req.ApprovedCasesOnly
+	}
+	if req.ReportFields != nil {
+		reportFields, err := normalizeReportFields(req.ReportFields)
+		if err != nil {
+			return apierrors.ErrUpdateTestPlan.InvalidParameter(err)
+		}
+		testPlan.ReportFields = reportFields
+	}
+
 	var isUpdateArchive bool
 	if req.IsArchived != nil {
 		if &testPlan.IsArchived != req.IsArchived {
@@ -636,9 +667,16 @@ func (t *TestPlan) Convert(testPlan *dao.TestPlan, relsCount apistructs.TestPlan
 		Type:       testPlan.Type,
 		Inode:      testPlan.Inode,
 		IsArchived: testPlan.IsArchived,
+
+		AutoCreateBug:     testPlan.AutoCreateBug,
+		ApprovedCasesOnly: testPlan.ApprovedCasesOnly,
+		ReportFields:      []apistructs.TestPlanReportField(testPlan.ReportFields),
+	}
+	if testPlan.ReportEmail != nil {
+		result.ReportEmail = (*apistructs.TestPlanReportEmailConfig)(testPlan.ReportEmail)
 	}
 	for _, mem := range members {
-		if mem.Role.IsOwner() {
+		if mem.Role.IsOwner() && result.OwnerID == "" {
 			result.OwnerID = mem.UserID
 		}
 		if mem.Role.IsPartner() {
@@ -15,8 +15,14 @@
 package testplan
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/bdl"
 	dao2 "github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 )
 
 func (t *TestPlan) ConvertMember(dbMem dao2.TestPlanMember) apistructs.TestPlanMember {
@@ -37,3 +43,175 @@ func (t *TestPlan) BatchConvertMembers(dbMems []dao2.TestPlanMember) []apistruct
 	}
 	return results
 }
+
+// ListMembers 查询测试计划成员及其角色
+func (t *TestPlan) ListMembers(testPlanID uint64) ([]apistructs.TestPlanMember, error) {
+	members, err := t.db.ListTestPlanMembersByPlanID(testPlanID)
+	if err != nil {
+		return nil, apierrors.ErrListTestPlanMembers.InternalError(err)
+	}
+	results := t.BatchConvertMembers(members)
+	if results == nil {
+		results = []apistructs.TestPlanMember{}
+	}
+	return results, nil
+}
+
+// CreateMember 添加测试计划成员
+func (t *TestPlan) CreateMember(req apistructs.TestPlanMemberCreateRequest) (*apistructs.TestPlanMember, error) {
+	if req.UserID == "" {
+		return nil, apierrors.ErrCreateTestPlanMember.MissingParameter("userID")
+	}
+	if req.Role.Invalid() {
+		return nil, apierrors.ErrCreateTestPlanMember.InvalidParameter(fmt.Sprintf("role: %s", req.Role))
+	}
+	members, err := t.db.ListTestPlanMembersByPlanID(req.TestPlanID)
+	if err != nil {
+		return nil, apierrors.ErrCreateTestPlanMember.InternalError(err)
+	}
+	for _, mem := range members {
+		if mem.UserID == req.UserID {
+			return nil, apierrors.ErrCreateTestPlanMember.AlreadyExists()
+		}
+	}
+	member := dao2.TestPlanMember{TestPlanID: req.TestPlanID, Role: req.Role, UserID: req.UserID}
+	if err := t.db.CreateTestPlanMember(&member); err != nil {
+		return nil, apierrors.ErrCreateTestPlanMember.InternalError(err)
+	}
+	result := t.ConvertMember(member)
+	return &result, nil
+}
+
+// UpdateMember 修改测试计划成员的角色, 不允许修改最后一个所有者的角色
+func (t *TestPlan) UpdateMember(req apistructs.TestPlanMemberUpdateRequest) (*apistructs.TestPlanMember, error) {
+	if req.Role.Invalid() {
+		return nil, apierrors.ErrUpdateTestPlanMember.InvalidParameter(fmt.Sprintf("role: %s", req.Role))
+	}
+	member, err := t.db.OverwriteTestPlanMemberRole(req.TestPlanID, req.UserID, req.Role, func(members []dao2.TestPlanMember) error {
+		if len(memberRoles(members, req.UserID)) == 0 {
+			return apierrors.ErrUpdateTestPlanMember.NotFound()
+		}
+		if !req.Role.IsOwner() && isLastOwner(members, req.UserID) {
+			return apierrors.ErrUpdateTestPlanMember.InvalidState("测试计划至少需要保留一个所有者")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, memberWriteErr(apierrors.ErrUpdateTestPlanMember, err)
+	}
+	result := t.ConvertMember(*member)
+	return &result, nil
+}
+
+// DeleteMember 移除测试计划成员, 不允许移除最后一个所有者
+func (t *TestPlan) DeleteMember(testPlanID uint64, userID string) error {
+	err := t.db.DeleteTestPlanMember(testPlanID, userID, func(members []dao2.TestPlanMember) error {
+		if len(memberRoles(members, userID)) == 0 {
+			return apierrors.ErrDeleteTestPlanMember.NotFound()
+		}
+		if isLastOwner(members, userID) {
+			return apierrors.ErrDeleteTestPlanMember.InvalidState("测试计划至少需要保留一个所有者")
+		}
+		return nil
+	})
+	if err != nil {
+		return memberWriteErr(apierrors.ErrDeleteTestPlanMember, err)
+	}
+	return nil
+}
+
+// CheckMemberPermission 校验用户在测试计划中的角色是否允许该操作
+// 未加入测试计划的用户仅保留项目权限内的执行权限, 管理测试计划需为计划所有者或项目管理员
+func (t *TestPlan) CheckMemberPermission(identityInfo apistructs.IdentityInfo, testPlanID uint64, permission apistructs.TestPlanMemberPermission) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	members, err := t.db.ListTestPlanMembersByPlanID(testPlanID)
+	if err != nil {
+		return apierrors.ErrListTestPlanMembers.InternalError(err)
+	}
+	if memberAllowed(memberRoles(members, identityInfo.UserID), permission) {
+		return nil
+	}
+	isManager, err := t.isProjectManager(identityInfo.UserID, testPlanID)
+	if err != nil {
+		return apierrors.ErrCheckPermission.InternalError(err)
+	}
+	if !isManager {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	return nil
+}
+
+// isProjectManager 用户是否为测试计划所属项目的管理员
+func (t *TestPlan) isProjectManager(userID string, testPlanID uint64) (bool, error) {
+	plan, err := t.db.GetTestPlan(testPlanID)
+	if err != nil {
+		return false, err
+	}
+	if plan == nil {
+		return false, nil
+	}
+	access, err := t.bdl.ScopeRoleAccess(userID, &apistructs.ScopeRoleAccessRequest{
+		Scope: apistructs.Scope{Type: apistructs.ProjectScope, ID: strconv.FormatUint(plan.ProjectID, 10)},
+	})
+	if err != nil {
+		return false, err
+	}
+	if !access.Access {
+		return false, nil
+	}
+	for _, role := range access.Roles {
+		if bdl.CheckIfRoleIsManager(role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// memberWriteErr 校验失败时直接返回校验错误, 否则包装为内部错误
+func memberWriteErr(apiErr *errorresp.APIError, err error) error {
+	if e, ok := err.(*errorresp.APIError); ok {
+		return e
+	}
+	return apiErr.InternalError(err)
+}
+
+// memberRoles 用户在测试计划中的角色, 历史数据中同一用户可能同时为所有者和执行者
+func memberRoles(members []dao2.TestPlanMember, userID string) []apistructs.TestPlanMemberRole {
+	var roles []apistructs.TestPlanMemberRole
+	for _, mem := range members {
+		if mem.UserID == userID {
+			roles = append(roles, mem.Role)
+		}
+	}
+	return roles
+}
+
+// memberAllowed 任一角色允许即可, 非成员 (无角色) 仅允许执行, 不允许管理
+func memberAllowed(roles []apistructs.TestPlanMemberRole, permission apistructs.TestPlanMemberPermission) bool {
+	if len(roles) == 0 {
+		return permission != apistructs.TestPlanMemberPermissionManage
+	}
+	for _, role := range roles {
+		if role.Allow(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLastOwner 用户是否为测试计划唯一的所有者
+func isLastOwner(members []dao2.TestPlanMember, userID string) bool {
+	var isOwner bool
+	for _, mem := range members {
+		if !mem.Role.IsOwner() {
+			continue
+		}
+		if mem.UserID != userID {
+			return false
+		}
+		isOwner = true
+	}
+	return isOwner
+}
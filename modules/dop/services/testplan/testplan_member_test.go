@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+)
+
+func TestMemberAllowed(t *testing.T) {
+	owner := []apistructs.TestPlanMemberRole{apistructs.TestPlanMemberRoleOwner}
+	partner := []apistructs.TestPlanMemberRole{apistructs.TestPlanMemberRolePartner}
+	viewer := []apistructs.TestPlanMemberRole{apistructs.TestPlanMemberRoleViewer}
+
+	assert.True(t, memberAllowed(owner, apistructs.TestPlanMemberPermissionManage))
+	assert.True(t, memberAllowed(owner, apistructs.TestPlanMemberPermissionExecute))
+	assert.False(t, memberAllowed(partner, apistructs.TestPlanMemberPermissionManage))
+	assert.True(t, memberAllowed(partner, apistructs.TestPlanMemberPermissionExecute))
+	assert.False(t, memberAllowed(viewer, apistructs.TestPlanMemberPermissionManage))
+	assert.False(t, memberAllowed(viewer, apistructs.TestPlanMemberPermissionExecute))
+	// 同时拥有多个角色时取权限最大的
+	assert.True(t, memberAllowed(append(viewer, owner...), apistructs.TestPlanMemberPermissionManage))
+	// 非成员仅保留执行权限
+	assert.False(t, memberAllowed(nil, apistructs.TestPlanMemberPermissionManage))
+	assert.True(t, memberAllowed(nil, apistructs.TestPlanMemberPermissionExecute))
+}
+
+func TestIsLastOwner(t *testing.T) {
+	members := []dao.TestPlanMember{
+		{UserID: "1", Role: apistructs.TestPlanMemberRoleOwner},
+		{UserID: "2", Role: apistructs.TestPlanMemberRolePartner},
+		{UserID: "1", Role: apistructs.TestPlanMemberRolePartner},
+	}
+	assert.True(t, isLastOwner(members, "1"))
+	assert.False(t, isLastOwner(members, "2"))
+	assert.False(t, isLastOwner(members, "3"))
+
+	members = append(members, dao.TestPlanMember{UserID: "3", Role: apistructs.TestPlanMemberRoleOwner})
+	assert.False(t, isLastOwner(members, "1"))
+	assert.Equal(t, []apistructs.TestPlanMemberRole{apistructs.TestPlanMemberRoleOwner, apistructs.TestPlanMemberRolePartner}, memberRoles(members, "1"))
+}
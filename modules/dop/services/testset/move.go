@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// Move 移动测试集至新的父测试集下，子测试集和测试用例随之移动
+func (svc *Service) Move(req apistructs.TestSetMoveRequest) (*apistructs.TestSet, error) {
+	// 参数校验
+	if req.TestSetID == 0 {
+		return nil, apierrors.ErrMoveTestSet.InvalidParameter("cannot move root testset")
+	}
+	if req.MoveToParentID == req.TestSetID {
+		return nil, apierrors.ErrMoveTestSet.InvalidParameter("cannot move to itself")
+	}
+
+	srcTs, err := svc.Get(req.TestSetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkTestSetDestination(srcTs, req.MoveToParentID); err != nil {
+		return nil, apierrors.ErrMoveTestSet.InvalidParameter(err)
+	}
+
+	// 复用更新逻辑，处理重名、排序及子测试集目录
+	if err := svc.Update(apistructs.TestSetUpdateRequest{
+		TestSetID:      srcTs.ID,
+		MoveToParentID: &req.MoveToParentID,
+		IdentityInfo:   req.IdentityInfo,
+	}); err != nil {
+		return nil, err
+	}
+
+	return svc.Get(srcTs.ID)
+}
+
+// Merge 将测试集合并至目标测试集：子测试集移至目标测试集下，测试用例及测试计划中的关联归入目标测试集，最后删除原测试集
+func (svc *Service) Merge(req apistructs.TestSetMergeRequest) (*apistructs.TestSet, error) {
+	// 参数校验
+	if req.TestSetID == 0 || req.MergeToTestSetID == 0 {
+		return nil, apierrors.ErrMergeTestSet.InvalidParameter("cannot merge root testset")
+	}
+	if req.MergeToTestSetID == req.TestSetID {
+		return nil, apierrors.ErrMergeTestSet.InvalidParameter("cannot merge to itself")
+	}
+
+	srcTs, err := svc.Get(req.TestSetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.checkTestSetDestination(srcTs, req.MergeToTestSetID); err != nil {
+		return nil, apierrors.ErrMergeTestSet.InvalidParameter(err)
+	}
+
+	// 子测试集、测试用例的移动及原测试集的删除在同一事务中完成，避免合并一半失败
+	if err := svc.db.WithTransaction(func(tx *dao.DBClient) error {
+		return svc.withDB(tx).mergeTestSet(srcTs, req.MergeToTestSetID, req.IdentityInfo)
+	}); err != nil {
+		return nil, err
+	}
+
+	return svc.Get(req.MergeToTestSetID)
+}
+
+// mergeTestSet 合并测试集：未回收的子测试集移至目标测试集下，回收站中的子测试集同样挂至目标测试集，
+// 以免原测试集删除后无法恢复；测试用例及测试计划关联归入目标测试集
+func (svc *Service) mergeTestSet(srcTs *apistructs.TestSet, dstTestSetID uint64, identityInfo apistructs.IdentityInfo) error {
+	subTestSets, err := svc.List(apistructs.TestSetListRequest{
+		Recycled:  false,
+		ParentID:  &srcTs.ID,
+		ProjectID: &srcTs.ProjectID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, subTs := range subTestSets {
+		if err := svc.Update(apistructs.TestSetUpdateRequest{
+			TestSetID:      subTs.ID,
+			MoveToParentID: &dstTestSetID,
+			IdentityInfo:   identityInfo,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 回收站中的子测试集仅修改父测试集及目录，恢复时再处理重名
+	recycledSubTestSets, err := svc.db.ListTestSets(apistructs.TestSetListRequest{
+		Recycled:  apistructs.RecycledYes,
+		ParentID:  &srcTs.ID,
+		ProjectID: &srcTs.ProjectID,
+	})
+	if err != nil {
+		return apierrors.ErrMergeTestSet.InternalError(err)
+	}
+	if len(recycledSubTestSets) > 0 {
+		dstTs, err := svc.ensureGetTestSet(srcTs.ProjectID, dstTestSetID)
+		if err != nil {
+			return apierrors.ErrMergeTestSet.InvalidParameter(err)
+		}
+		for i := range recycledSubTestSets {
+			subTs := &recycledSubTestSets[i]
+			subTs.ParentID = dstTestSetID
+			subTs.Directory = generateTestSetDirectory(dstTs, subTs.Name)
+			subTs.UpdaterID = identityInfo.UserID
+			if err := svc.db.UpdateTestSet(subTs); err != nil {
+				return apierrors.ErrMergeTestSet.InternalError(err)
+			}
+		}
+	}
+
+	if err := svc.db.MergeTestSet(srcTs.ProjectID, srcTs.ID, dstTestSetID); err != nil {
+		return apierrors.ErrMergeTestSet.InternalError(err)
+	}
+	if err := svc.db.DeleteTestSetPermissionsByTestSetIDs([]uint64{srcTs.ID}); err != nil {
+		return apierrors.ErrMergeTestSet.InternalError(err)
+	}
+	return nil
+}
+
+// checkTestSetDestination 校验目标测试集：需存在、未回收、属于同一项目，且不能是源测试集的子测试集；0 表示根目录
+func (svc *Service) checkTestSetDestination(srcTs *apistructs.TestSet, dstTestSetID uint64) error {
+	if srcTs.Recycled {
+		return fmt.Errorf("testset is recycled, id: %d", srcTs.ID)
+	}
+	if dstTestSetID == 0 {
+		return nil
+	}
+	dstTs, err := svc.ensureGetTestSet(srcTs.ProjectID, dstTestSetID)
+	if err != nil {
+		return err
+	}
+	if dstTs.ProjectID != srcTs.ProjectID {
+		return fmt.Errorf("target testset belongs to another project, id: %d", dstTestSetID)
+	}
+	if dstTs.Recycled {
+		return fmt.Errorf("target testset is recycled, id: %d", dstTestSetID)
+	}
+	findInSub, err := svc.findTargetTestSetIDInSubTestSets([]uint64{srcTs.ID}, srcTs.ProjectID, dstTestSetID)
+	if err != nil {
+		return err
+	}
+	if findInSub {
+		return fmt.Errorf("cannot move to sub testset")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestCheckTestSetDestination(t *testing.T) {
+	// 1 -> 2 -> 3, 4 已回收, 5 属于其他项目
+	testSets := map[uint64]dao.TestSet{
+		1: {BaseModel: dbengine.BaseModel{ID: 1}, ProjectID: 1},
+		2: {BaseModel: dbengine.BaseModel{ID: 2}, ProjectID: 1, ParentID: 1},
+		3: {BaseModel: dbengine.BaseModel{ID: 3}, ProjectID: 1, ParentID: 2},
+		4: {BaseModel: dbengine.BaseModel{ID: 4}, ProjectID: 1, Recycled: true},
+		5: {BaseModel: dbengine.BaseModel{ID: 5}, ProjectID: 2},
+		6: {BaseModel: dbengine.BaseModel{ID: 6}, ProjectID: 1},
+	}
+	db := &dao.DBClient{}
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetTestSetByID", func(_ *dao.DBClient, id uint64) (*dao.TestSet, error) {
+		ts, ok := testSets[id]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &ts, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetTestSetByParentIDsAndProjectID",
+		func(_ *dao.DBClient, parentIDs []uint64, projectID uint64, recycled bool) ([]dao.TestSet, error) {
+			var children []dao.TestSet
+			for _, ts := range testSets {
+				for _, parentID := range parentIDs {
+					if ts.ParentID == parentID && ts.ProjectID == projectID && ts.Recycled == recycled {
+						children = append(children, ts)
+					}
+				}
+			}
+			return children, nil
+		})
+	defer monkey.UnpatchAll()
+
+	svc := New(WithDBClient(db))
+	src := &apistructs.TestSet{ID: 1, ProjectID: 1}
+
+	tests := []struct {
+		name    string
+		src     *apistructs.TestSet
+		dstID   uint64
+		wantErr string
+	}{
+		{name: "root", src: src, dstID: 0},
+		{name: "sibling", src: src, dstID: 6},
+		{name: "src recycled", src: &apistructs.TestSet{ID: 4, ProjectID: 1, Recycled: true}, dstID: 6, wantErr: "testset is recycled, id: 4"},
+		{name: "not exist", src: src, dstID: 100, wantErr: "testset not exist, id: 100"},
+		{name: "other project", src: src, dstID: 5, wantErr: "target testset belongs to another project, id: 5"},
+		{name: "dst recycled", src: src, dstID: 4, wantErr: "target testset is recycled, id: 4"},
+		{name: "direct child", src: src, dstID: 2, wantErr: "cannot move to sub testset"},
+		{name: "grandchild", src: src, dstID: 3, wantErr: "cannot move to sub testset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.checkTestSetDestination(tt.src, tt.dstID)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	// 查询失败时返回错误
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetTestSetByID", func(_ *dao.DBClient, id uint64) (*dao.TestSet, error) {
+		return nil, fmt.Errorf("db error")
+	})
+	assert.Error(t, svc.checkTestSetDestination(src, 2))
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+)
+
+// ListPermissions 查询测试集上的显式授权, 不包含继承自上级测试集的授权
+func (svc *Service) ListPermissions(testSetID uint64) ([]apistructs.TestSetPermission, error) {
+	perms, err := svc.db.ListTestSetPermissions(testSetID)
+	if err != nil {
+		return nil, apierrors.ErrListTestSetPermissions.InternalError(err)
+	}
+	results := make([]apistructs.TestSetPermission, 0, len(perms))
+	for _, perm := range perms {
+		results = append(results, convertPermission(perm))
+	}
+	return results, nil
+}
+
+// GrantPermission 授予用户在测试集上的角色, 子测试集未单独授权时继承该角色
+func (svc *Service) GrantPermission(req apistructs.TestSetPermissionGrantRequest) (*apistructs.TestSetPermission, error) {
+	if req.TestSetID == 0 {
+		return nil, apierrors.ErrGrantTestSetPermission.InvalidParameter("cannot grant permission on root testset")
+	}
+	if req.UserID == "" {
+		return nil, apierrors.ErrGrantTestSetPermission.MissingParameter("userID")
+	}
+	if req.Role.Invalid() {
+		return nil, apierrors.ErrGrantTestSetPermission.InvalidParameter(fmt.Sprintf("role: %s", req.Role))
+	}
+	ts, err := svc.Get(req.TestSetID)
+	if err != nil {
+		return nil, err
+	}
+	perm := dao.TestSetPermission{
+		ProjectID: ts.ProjectID,
+		TestSetID: ts.ID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+		CreatorID: req.IdentityInfo.UserID,
+	}
+	if err := svc.db.OverwriteTestSetPermission(&perm); err != nil {
+		return nil, apierrors.ErrGrantTestSetPermission.InternalError(err)
+	}
+	result := convertPermission(perm)
+	return &result, nil
+}
+
+// RevokePermission 撤销用户在测试集上的授权, 撤销后重新继承上级测试集的授权
+func (svc *Service) RevokePermission(testSetID uint64, userID string) error {
+	deleted, err := svc.db.DeleteTestSetPermission(testSetID, userID)
+	if err != nil {
+		return apierrors.ErrRevokeTestSetPermission.InternalError(err)
+	}
+	if !deleted {
+		return apierrors.ErrRevokeTestSetPermission.NotFound()
+	}
+	return nil
+}
+
+// GetEffectivePermission 查询用户在测试集上的有效授权: 测试集自身的授权优先, 否则沿父测试集向上查找最近的授权
+// 有效授权在查询时根据当前的父子关系计算, 移动测试集后立即按新的上级重新计算
+func (svc *Service) GetEffectivePermission(testSetID uint64, userID string) (*apistructs.TestSetEffectivePermission, error) {
+	ts, err := svc.Get(testSetID)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := svc.newPermissionResolver(ts.ProjectID, userID)
+	if err != nil {
+		return nil, apierrors.ErrGetTestSetPermission.InternalError(err)
+	}
+	effective, err := resolver.resolve(ts.ID)
+	if err != nil {
+		return nil, apierrors.ErrGetTestSetPermission.InternalError(err)
+	}
+	return &apistructs.TestSetEffectivePermission{
+		TestSetID:       ts.ID,
+		UserID:          userID,
+		Role:            effective.role,
+		SourceTestSetID: effective.sourceID,
+		Inherited:       effective.role != "" && effective.sourceID != ts.ID,
+		CanRead:         effective.role.Allow(apistructs.TestSetPermissionActionRead),
+		CanEdit:         effective.role.Allow(apistructs.TestSetPermissionActionEdit),
+	}, nil
+}
+
+// CheckPermission 校验用户在测试集上的有效授权是否允许该操作, 0 表示根目录, 不需要校验
+// 测试集及其上级均未授权的用户不受限制, 仍由项目权限控制
+func (svc *Service) CheckPermission(identityInfo apistructs.IdentityInfo, action apistructs.TestSetPermissionAction, testSetIDs ...uint64) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	resolvers := make(map[uint64]*permissionResolver)
+	for _, testSetID := range testSetIDs {
+		if testSetID == 0 {
+			continue
+		}
+		ts, err := svc.Get(testSetID)
+		if err != nil {
+			return err
+		}
+		resolver, ok := resolvers[ts.ProjectID]
+		if !ok {
+			if resolver, err = svc.newPermissionResolver(ts.ProjectID, identityInfo.UserID); err != nil {
+				return apierrors.ErrCheckPermission.InternalError(err)
+			}
+			resolvers[ts.ProjectID] = resolver
+		}
+		effective, err := resolver.resolve(ts.ID)
+		if err != nil {
+			return apierrors.ErrCheckPermission.InternalError(err)
+		}
+		if !effective.role.Allow(action) {
+			return apierrors.ErrCheckPermission.AccessDenied()
+		}
+	}
+	return nil
+}
+
+// FilterReadable 过滤掉用户无权查看的测试集
+func (svc *Service) FilterReadable(identityInfo apistructs.IdentityInfo, projectID uint64, testSets []apistructs.TestSet) ([]apistructs.TestSet, error) {
+	if identityInfo.IsInternalClient() || len(testSets) == 0 {
+		return testSets, nil
+	}
+	resolver, err := svc.newPermissionResolver(projectID, identityInfo.UserID)
+	if err != nil {
+		return nil, apierrors.ErrListTestSets.InternalError(err)
+	}
+	if len(resolver.roles) == 0 {
+		return testSets, nil
+	}
+	for _, ts := range testSets {
+		resolver.parents[ts.ID] = ts.ParentID
+	}
+	results := make([]apistructs.TestSet, 0, len(testSets))
+	for _, ts := range testSets {
+		effective, err := resolver.resolve(ts.ID)
+		if err != nil {
+			return nil, apierrors.ErrListTestSets.InternalError(err)
+		}
+		if effective.role.Allow(apistructs.TestSetPermissionActionRead) {
+			results = append(results, ts)
+		}
+	}
+	return results, nil
+}
+
+// ListUnreadableTestSetIDs 查询项目内用户无权查看的测试集, 用于分页查询测试用例时排除
+func (svc *Service) ListUnreadableTestSetIDs(identityInfo apistructs.IdentityInfo, projectID uint64, recycled bool) ([]uint64, error) {
+	if identityInfo.IsInternalClient() {
+		return nil, nil
+	}
+	resolver, err := svc.newPermissionResolver(projectID, identityInfo.UserID)
+	if err != nil {
+		return nil, apierrors.ErrCheckPermission.InternalError(err)
+	}
+	if len(resolver.roles) == 0 {
+		return nil, nil
+	}
+	testSets, err := svc.db.ListTestSets(apistructs.TestSetListRequest{ProjectID: &projectID, Recycled: recycled})
+	if err != nil {
+		return nil, apierrors.ErrCheckPermission.InternalError(err)
+	}
+	for _, ts := range testSets {
+		resolver.parents[uint64(ts.ID)] = ts.ParentID
+	}
+	var results []uint64
+	for _, ts := range testSets {
+		effective, err := resolver.resolve(uint64(ts.ID))
+		if err != nil {
+			return nil, apierrors.ErrCheckPermission.InternalError(err)
+		}
+		if !effective.role.Allow(apistructs.TestSetPermissionActionRead) {
+			results = append(results, uint64(ts.ID))
+		}
+	}
+	return results, nil
+}
+
+// effectivePermission 生效的授权及其所在的测试集
+type effectivePermission struct {
+	role     apistructs.TestSetPermissionRole
+	sourceID uint64
+}
+
+// permissionResolver 沿父测试集链计算用户在测试集上的有效授权, 缓存已查询的父子关系及计算结果
+type permissionResolver struct {
+	svc      *Service
+	roles    map[uint64]apistructs.TestSetPermissionRole
+	parents  map[uint64]uint64
+	resolved map[uint64]effectivePermission
+}
+
+func (svc *Service) newPermissionResolver(projectID uint64, userID string) (*permissionResolver, error) {
+	perms, err := svc.db.ListUserTestSetPermissions(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[uint64]apistructs.TestSetPermissionRole, len(perms))
+	for _, perm := range perms {
+		roles[perm.TestSetID] = perm.Role
+	}
+	return &permissionResolver{
+		svc:      svc,
+		roles:    roles,
+		parents:  make(map[uint64]uint64),
+		resolved: make(map[uint64]effectivePermission),
+	}, nil
+}
+
+func (r *permissionResolver) resolve(testSetID uint64) (effectivePermission, error) {
+	var (
+		result effectivePermission
+		path   []uint64
+	)
+	visited := make(map[uint64]bool)
+	for id := testSetID; id != 0 && !visited[id] && len(r.roles) > 0; {
+		if cached, ok := r.resolved[id]; ok {
+			result = cached
+			break
+		}
+		visited[id] = true
+		path = append(path, id)
+		if role, ok := r.roles[id]; ok {
+			result = effectivePermission{role: role, sourceID: id}
+			break
+		}
+		parentID, ok := r.parents[id]
+		if !ok {
+			ts, err := r.svc.db.GetTestSetByID(id)
+			if err != nil {
+				if gorm.IsRecordNotFoundError(err) {
+					break
+				}
+				return result, err
+			}
+			parentID = ts.ParentID
+			r.parents[id] = parentID
+		}
+		id = parentID
+	}
+	for _, id := range path {
+		r.resolved[id] = result
+	}
+	return result, nil
+}
+
+func convertPermission(perm dao.TestSetPermission) apistructs.TestSetPermission {
+	return apistructs.TestSetPermission{
+		ID:        perm.ID,
+		ProjectID: perm.ProjectID,
+		TestSetID: perm.TestSetID,
+		UserID:    perm.UserID,
+		Role:      perm.Role,
+		CreatorID: perm.CreatorID,
+		CreatedAt: perm.CreatedAt,
+		UpdatedAt: perm.UpdatedAt,
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+// 1 -> 2 -> 3, 1 -> 4 -> 5, 1 -> 6, 10 未授权, 7 <-> 8 成环, 9 的上级不存在
+func newPermissionTestSets() map[uint64]dao.TestSet {
+	testSets := make(map[uint64]dao.TestSet)
+	for id, parentID := range map[uint64]uint64{1: 0, 2: 1, 3: 2, 4: 1, 5: 4, 6: 1, 7: 8, 8: 7, 9: 99, 10: 0} {
+		testSets[id] = dao.TestSet{BaseModel: dbengine.BaseModel{ID: id}, ProjectID: 1, ParentID: parentID}
+	}
+	return testSets
+}
+
+// 用户在 1 上为 Editor, 4 覆盖为 None, 6 覆盖为 Viewer
+var permissionTestRoles = map[uint64]apistructs.TestSetPermissionRole{
+	1: apistructs.TestSetPermissionRoleEditor,
+	4: apistructs.TestSetPermissionRoleNone,
+	6: apistructs.TestSetPermissionRoleViewer,
+}
+
+func patchPermissionDB(db *dao.DBClient, testSets map[uint64]dao.TestSet, roles map[uint64]apistructs.TestSetPermissionRole) {
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetTestSetByID", func(_ *dao.DBClient, id uint64) (*dao.TestSet, error) {
+		ts, ok := testSets[id]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &ts, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ListTestSets", func(_ *dao.DBClient, _ apistructs.TestSetListRequest) ([]dao.TestSet, error) {
+		var results []dao.TestSet
+		for _, ts := range testSets {
+			results = append(results, ts)
+		}
+		return results, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ListUserTestSetPermissions", func(_ *dao.DBClient, projectID uint64, userID string) ([]dao.TestSetPermission, error) {
+		var perms []dao.TestSetPermission
+		for testSetID, role := range roles {
+			perms = append(perms, dao.TestSetPermission{ProjectID: projectID, TestSetID: testSetID, UserID: userID, Role: role})
+		}
+		return perms, nil
+	})
+}
+
+func TestPermissionResolver_Resolve(t *testing.T) {
+	db := &dao.DBClient{}
+	patchPermissionDB(db, newPermissionTestSets(), nil)
+	defer monkey.UnpatchAll()
+	svc := New(WithDBClient(db))
+
+	tests := []struct {
+		name       string
+		roles      map[uint64]apistructs.TestSetPermissionRole
+		testSetID  uint64
+		wantRole   apistructs.TestSetPermissionRole
+		wantSource uint64
+	}{
+		{name: "explicit grant", roles: permissionTestRoles, testSetID: 1, wantRole: apistructs.TestSetPermissionRoleEditor, wantSource: 1},
+		{name: "inherited from parent", roles: permissionTestRoles, testSetID: 2, wantRole: apistructs.TestSetPermissionRoleEditor, wantSource: 1},
+		{name: "inherited from ancestor", roles: permissionTestRoles, testSetID: 3, wantRole: apistructs.TestSetPermissionRoleEditor, wantSource: 1},
+		{name: "child overridden with none", roles: permissionTestRoles, testSetID: 4, wantRole: apistructs.TestSetPermissionRoleNone, wantSource: 4},
+		{name: "inherited override", roles: permissionTestRoles, testSetID: 5, wantRole: apistructs.TestSetPermissionRoleNone, wantSource: 4},
+		{name: "child overridden with viewer", roles: permissionTestRoles, testSetID: 6, wantRole: apistructs.TestSetPermissionRoleViewer, wantSource: 6},
+		{name: "not granted", roles: permissionTestRoles, testSetID: 10},
+		{name: "root", roles: permissionTestRoles, testSetID: 0},
+		{name: "cycle", roles: permissionTestRoles, testSetID: 7},
+		{name: "missing parent", roles: permissionTestRoles, testSetID: 9},
+		{name: "no grants", testSetID: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &permissionResolver{
+				svc:      svc,
+				roles:    tt.roles,
+				parents:  make(map[uint64]uint64),
+				resolved: make(map[uint64]effectivePermission),
+			}
+			got, err := r.resolve(tt.testSetID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRole, got.role)
+			assert.Equal(t, tt.wantSource, got.sourceID)
+		})
+	}
+}
+
+func TestPermissionResolver_ResolveError(t *testing.T) {
+	db := &dao.DBClient{}
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "GetTestSetByID", func(_ *dao.DBClient, _ uint64) (*dao.TestSet, error) {
+		return nil, gorm.ErrInvalidSQL
+	})
+	defer monkey.UnpatchAll()
+
+	r := &permissionResolver{
+		svc:      New(WithDBClient(db)),
+		roles:    permissionTestRoles,
+		parents:  make(map[uint64]uint64),
+		resolved: make(map[uint64]effectivePermission),
+	}
+	_, err := r.resolve(3)
+	assert.Error(t, err)
+}
+
+func TestCheckPermission(t *testing.T) {
+	db := &dao.DBClient{}
+	defer monkey.UnpatchAll()
+	svc := New(WithDBClient(db))
+	user := apistructs.IdentityInfo{UserID: "1"}
+
+	tests := []struct {
+		name       string
+		identity   apistructs.IdentityInfo
+		noGrants   bool
+		action     apistructs.TestSetPermissionAction
+		testSetIDs []uint64
+		wantErr    bool
+	}{
+		{name: "editor edit", identity: user, action: apistructs.TestSetPermissionActionEdit, testSetIDs: []uint64{1}},
+		{name: "inherited editor edit", identity: user, action: apistructs.TestSetPermissionActionEdit, testSetIDs: []uint64{3}},
+		{name: "none read", identity: user, action: apistructs.TestSetPermissionActionRead, testSetIDs: []uint64{5}, wantErr: true},
+		{name: "viewer read", identity: user, action: apistructs.TestSetPermissionActionRead, testSetIDs: []uint64{6}},
+		{name: "viewer edit", identity: user, action: apistructs.TestSetPermissionActionEdit, testSetIDs: []uint64{6}, wantErr: true},
+		{name: "any denied", identity: user, action: apistructs.TestSetPermissionActionRead, testSetIDs: []uint64{1, 4}, wantErr: true},
+		{name: "not granted", identity: user, action: apistructs.TestSetPermissionActionEdit, testSetIDs: []uint64{10}},
+		{name: "root", identity: user, action: apistructs.TestSetPermissionActionEdit, testSetIDs: []uint64{0}},
+		{name: "internal client", identity: apistructs.IdentityInfo{InternalClient: "bundle"}, action: apistructs.TestSetPermissionActionRead, testSetIDs: []uint64{4}},
+		{name: "no grants", identity: user, noGrants: true, action: apistructs.TestSetPermissionActionEdit, testSetIDs: []uint64{4, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := permissionTestRoles
+			if tt.noGrants {
+				roles = nil
+			}
+			patchPermissionDB(db, newPermissionTestSets(), roles)
+			err := svc.CheckPermission(tt.identity, tt.action, tt.testSetIDs...)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestFilterReadable(t *testing.T) {
+	db := &dao.DBClient{}
+	defer monkey.UnpatchAll()
+	svc := New(WithDBClient(db))
+	user := apistructs.IdentityInfo{UserID: "1"}
+
+	var testSets []apistructs.TestSet
+	for _, ts := range newPermissionTestSets() {
+		testSets = append(testSets, svc.convert(ts))
+	}
+
+	tests := []struct {
+		name     string
+		identity apistructs.IdentityInfo
+		roles    map[uint64]apistructs.TestSetPermissionRole
+		want     []uint64
+	}{
+		{name: "granted", identity: user, roles: permissionTestRoles, want: []uint64{1, 2, 3, 6, 7, 8, 9, 10}},
+		{name: "no grants", identity: user, want: []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "internal client", identity: apistructs.IdentityInfo{InternalClient: "bundle"}, roles: permissionTestRoles, want: []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patchPermissionDB(db, newPermissionTestSets(), tt.roles)
+			results, err := svc.FilterReadable(tt.identity, 1, testSets)
+			assert.NoError(t, err)
+			var got []uint64
+			for _, ts := range results {
+				got = append(got, ts.ID)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestListUnreadableTestSetIDs(t *testing.T) {
+	db := &dao.DBClient{}
+	defer monkey.UnpatchAll()
+	svc := New(WithDBClient(db))
+	user := apistructs.IdentityInfo{UserID: "1"}
+
+	tests := []struct {
+		name     string
+		identity apistructs.IdentityInfo
+		roles    map[uint64]apistructs.TestSetPermissionRole
+		want     []uint64
+	}{
+		{name: "granted", identity: user, roles: permissionTestRoles, want: []uint64{4, 5}},
+		{name: "no grants", identity: user},
+		{name: "internal client", identity: apistructs.IdentityInfo{InternalClient: "bundle"}, roles: permissionTestRoles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patchPermissionDB(db, newPermissionTestSets(), tt.roles)
+			got, err := svc.ListUnreadableTestSetIDs(tt.identity, 1, false)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetEffectivePermission(t *testing.T) {
+	db := &dao.DBClient{}
+	testSets := newPermissionTestSets()
+	patchPermissionDB(db, testSets, permissionTestRoles)
+	defer monkey.UnpatchAll()
+	svc := New(WithDBClient(db))
+
+	tests := []struct {
+		name          string
+		testSetID     uint64
+		wantRole      apistructs.TestSetPermissionRole
+		wantSource    uint64
+		wantInherited bool
+		wantCanRead   bool
+		wantCanEdit   bool
+	}{
+		{name: "explicit", testSetID: 1, wantRole: apistructs.TestSetPermissionRoleEditor, wantSource: 1, wantCanRead: true, wantCanEdit: true},
+		{name: "inherited", testSetID: 3, wantRole: apistructs.TestSetPermissionRoleEditor, wantSource: 1, wantInherited: true, wantCanRead: true, wantCanEdit: true},
+		{name: "override none", testSetID: 4, wantRole: apistructs.TestSetPermissionRoleNone, wantSource: 4},
+		{name: "inherited none", testSetID: 5, wantRole: apistructs.TestSetPermissionRoleNone, wantSource: 4, wantInherited: true},
+		{name: "override viewer", testSetID: 6, wantRole: apistructs.TestSetPermissionRoleViewer, wantSource: 6, wantCanRead: true},
+		{name: "not granted", testSetID: 10, wantCanRead: true, wantCanEdit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.GetEffectivePermission(tt.testSetID, "1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRole, got.Role)
+			assert.Equal(t, tt.wantSource, got.SourceTestSetID)
+			assert.Equal(t, tt.wantInherited, got.Inherited)
+			assert.Equal(t, tt.wantCanRead, got.CanRead)
+			assert.Equal(t, tt.wantCanEdit, got.CanEdit)
+		})
+	}
+
+	// 3 移动到 6 下后按新的上级重新计算
+	ts := testSets[3]
+	ts.ParentID = 6
+	testSets[3] = ts
+	got, err := svc.GetEffectivePermission(3, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, apistructs.TestSetPermissionRoleViewer, got.Role)
+	assert.Equal(t, uint64(6), got.SourceTestSetID)
+	assert.True(t, got.Inherited)
+	assert.False(t, got.CanEdit)
+}
@@ -100,30 +100,9 @@ func (svc *Service) CleanFromRecycleBin(req apistructs.TestSetCleanFromRecycleBi
 		return apierrors.ErrCleanTestSetFromRecycleBin.InvalidState("not in recycle bin")
 	}
 
-	// 获取回收站中测试集下测试用例列表
-	_, tcIDs, err := svc.tcSvc.ListTestCases(apistructs.TestCaseListRequest{
-		ProjectID:  ts.ProjectID,
-		TestSetIDs: []uint64{ts.ID},
-		Recycled:   true,
-		IDOnly:     true,
-	})
-	if err != nil {
+	if _, err := svc.cleanTestSetFromRecycleBin(ts.ProjectID, ts.ID, req.IdentityInfo); err != nil {
 		return err
 	}
-	// 从回收站中彻底删除测试用例
-	if len(tcIDs) > 0 {
-		if err := svc.tcSvc.BatchCleanFromRecycleBin(apistructs.TestCaseBatchCleanFromRecycleBinRequest{
-			TestCaseIDs:  tcIDs,
-			IdentityInfo: req.IdentityInfo,
-		}); err != nil {
-			return err
-		}
-	}
-
-	// 彻底删除测试集
-	if err := svc.db.CleanTestSetFromRecycleBin(ts.ID); err != nil {
-		return apierrors.ErrRecycleTestSet.InternalError(fmt.Errorf("failed to clean current testset from recycle bin, id: %d, err: %v", ts.ID, err))
-	}
 
 	// 递归回收子测试集
 	subTestSets, err := svc.List(apistructs.TestSetListRequest{
@@ -146,6 +125,39 @@ func (svc *Service) CleanFromRecycleBin(req apistructs.TestSetCleanFromRecycleBi
 	return nil
 }
 
+// cleanTestSetFromRecycleBin 彻底删除回收站中的测试集及其下的测试用例，不处理子测试集，返回删除的测试用例数量
+func (svc *Service) cleanTestSetFromRecycleBin(projectID, testSetID uint64, identityInfo apistructs.IdentityInfo) (int, error) {
+	// 获取回收站中测试集下测试用例列表
+	_, tcIDs, err := svc.tcSvc.ListTestCases(apistructs.TestCaseListRequest{
+		ProjectID:  projectID,
+		TestSetIDs: []uint64{testSetID},
+		Recycled:   true,
+		IDOnly:     true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// 从回收站中彻底删除测试用例
+	if len(tcIDs) > 0 {
+		if err := svc.tcSvc.BatchCleanFromRecycleBin(apistructs.TestCaseBatchCleanFromRecycleBinRequest{
+			TestCaseIDs:  tcIDs,
+			IdentityInfo: identityInfo,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	// 彻底删除测试集
+	if err := svc.db.CleanTestSetFromRecycleBin(testSetID); err != nil {
+		return 0, apierrors.ErrRecycleTestSet.InternalError(fmt.Errorf("failed to clean current testset from recycle bin, id: %d, err: %v", testSetID, err))
+	}
+	if err := svc.db.DeleteTestSetPermissionsByTestSetIDs([]uint64{testSetID}); err != nil {
+		return 0, apierrors.ErrCleanTestSetFromRecycleBin.InternalError(err)
+	}
+
+	return len(tcIDs), nil
+}
+
 func (svc *Service) RecoverFromRecycleBin(req apistructs.TestSetRecoverFromRecycleBinRequest) error {
 	// 参数校验
 	if req.TestSetID == 0 {
@@ -0,0 +1,168 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/conf"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/apierrors"
+	"github.com/erda-project/erda/pkg/strutil"
+)
+
+const (
+	// recycleBinPurgeBatchSize 每批清理的测试集或测试用例数量
+	recycleBinPurgeBatchSize = 100
+	// recycleBinPurgeMetricName 回收站清理数量指标
+	recycleBinPurgeMetricName = "test_recycle_bin_purge"
+	// recycleBinPurgeOperator 自动清理时使用的内部调用方
+	recycleBinPurgeOperator = "dop-recycle-bin-sweeper"
+)
+
+// GetRecycleBinPolicy 获取项目回收站自动清理策略，未单独配置时返回平台默认策略
+func (svc *Service) GetRecycleBinPolicy(projectID uint64) (*apistructs.TestRecycleBinPolicy, error) {
+	if projectID == 0 {
+		return nil, apierrors.ErrGetTestRecycleBinPolicy.MissingParameter("projectID")
+	}
+	policy, err := svc.db.GetTestRecycleBinPolicy(projectID)
+	if err != nil {
+		return nil, apierrors.ErrGetTestRecycleBinPolicy.InternalError(err)
+	}
+	return convertRecycleBinPolicy(projectID, policy), nil
+}
+
+// UpdateRecycleBinPolicy 更新项目回收站自动清理策略
+func (svc *Service) UpdateRecycleBinPolicy(req apistructs.TestRecycleBinPolicyUpdateRequest) (*apistructs.TestRecycleBinPolicy, error) {
+	if req.ProjectID == 0 {
+		return nil, apierrors.ErrUpdateTestRecycleBinPolicy.MissingParameter("projectID")
+	}
+	if req.RetentionDays < 0 {
+		return nil, apierrors.ErrUpdateTestRecycleBinPolicy.InvalidParameter("retentionDays")
+	}
+	policy, err := svc.db.SaveTestRecycleBinPolicy(req.ProjectID, req.RetentionDays, req.UserID)
+	if err != nil {
+		return nil, apierrors.ErrUpdateTestRecycleBinPolicy.InternalError(err)
+	}
+	return convertRecycleBinPolicy(req.ProjectID, policy), nil
+}
+
+func convertRecycleBinPolicy(projectID uint64, policy *dao.TestRecycleBinPolicy) *apistructs.TestRecycleBinPolicy {
+	if policy == nil {
+		return &apistructs.TestRecycleBinPolicy{
+			ProjectID:     projectID,
+			RetentionDays: conf.TestRecycleBinRetentionDays(),
+			IsDefault:     true,
+		}
+	}
+	updatedAt := policy.UpdatedAt
+	return &apistructs.TestRecycleBinPolicy{
+		ProjectID:     policy.ProjectID,
+		RetentionDays: policy.RetentionDays,
+		UpdaterID:     policy.UpdaterID,
+		UpdatedAt:     &updatedAt,
+	}
+}
+
+// PurgeExpiredRecycleBin 按各项目的保留天数彻底删除回收站中过期的测试集及测试用例
+func (svc *Service) PurgeExpiredRecycleBin() {
+	projectIDs, err := svc.db.ListProjectIDsWithRecycledTestItems()
+	if err != nil {
+		logrus.Errorf("failed to list projects with recycled test items, err: %v", err)
+		return
+	}
+	for _, projectID := range strutil.DedupUint64Slice(projectIDs, true) {
+		policy, err := svc.GetRecycleBinPolicy(projectID)
+		if err != nil {
+			logrus.Errorf("failed to get recycle bin policy, projectID: %d, err: %v", projectID, err)
+			continue
+		}
+		if policy.RetentionDays <= 0 {
+			continue
+		}
+		before := time.Now().AddDate(0, 0, -policy.RetentionDays)
+		testSetCount, testCaseCount := svc.purgeProjectRecycleBin(projectID, before)
+		if testSetCount > 0 || testCaseCount > 0 {
+			logrus.Infof("purged recycle bin, projectID: %d, testSets: %d, testCases: %d", projectID, testSetCount, testCaseCount)
+			svc.emitRecycleBinPurgeMetric(projectID, testSetCount, testCaseCount)
+		}
+	}
+}
+
+// purgeProjectRecycleBin 清理项目回收站中回收时间早于 before 的测试集及测试用例，返回清理的数量
+func (svc *Service) purgeProjectRecycleBin(projectID uint64, before time.Time) (testSetCount, testCaseCount int) {
+	identity := apistructs.IdentityInfo{InternalClient: recycleBinPurgeOperator}
+
+	// 测试集的子测试集在回收时同样被标记为回收，因此逐个清理即可，无需递归
+	for {
+		testSets, err := svc.db.ListRecycledTestSetsBefore(projectID, before, recycleBinPurgeBatchSize)
+		if err != nil {
+			logrus.Errorf("failed to list expired recycled testsets, projectID: %d, err: %v", projectID, err)
+			return
+		}
+		for _, ts := range testSets {
+			tcCount, err := svc.cleanTestSetFromRecycleBin(projectID, ts.ID, identity)
+			if err != nil {
+				logrus.Errorf("failed to purge recycled testset, id: %d, err: %v", ts.ID, err)
+				return
+			}
+			testCaseCount += tcCount
+			testSetCount++
+		}
+		if len(testSets) < recycleBinPurgeBatchSize {
+			break
+		}
+	}
+
+	// 单独回收的测试用例
+	for {
+		tcIDs, err := svc.db.ListRecycledTestCaseIDsBefore(projectID, before, recycleBinPurgeBatchSize)
+		if err != nil {
+			logrus.Errorf("failed to list expired recycled testcases, projectID: %d, err: %v", projectID, err)
+			return
+		}
+		if len(tcIDs) > 0 {
+			if err := svc.tcSvc.BatchCleanFromRecycleBin(apistructs.TestCaseBatchCleanFromRecycleBinRequest{
+				TestCaseIDs:  tcIDs,
+				IdentityInfo: identity,
+			}); err != nil {
+				logrus.Errorf("failed to purge recycled testcases, projectID: %d, err: %v", projectID, err)
+				return
+			}
+			testCaseCount += len(tcIDs)
+		}
+		if len(tcIDs) < recycleBinPurgeBatchSize {
+			return
+		}
+	}
+}
+
+func (svc *Service) emitRecycleBinPurgeMetric(projectID uint64, testSetCount, testCaseCount int) {
+	if err := svc.bdl.CollectMetrics(&apistructs.Metrics{Metric: []apistructs.Metric{{
+		Name:      recycleBinPurgeMetricName,
+		Timestamp: time.Now().UnixNano(),
+		Tags:      map[string]string{"project_id": strconv.FormatUint(projectID, 10)},
+		Fields: map[string]interface{}{
+			"test_sets":  testSetCount,
+			"test_cases": testCaseCount,
+		},
+	}}}); err != nil {
+		logrus.Errorf("failed to collect recycle bin purge metric, projectID: %d, err: %v", projectID, err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/dop/dao"
+	"github.com/erda-project/erda/modules/dop/services/testcase"
+	"github.com/erda-project/erda/pkg/database/dbengine"
+)
+
+func TestConvertRecycleBinPolicy(t *testing.T) {
+	policy := convertRecycleBinPolicy(1, nil)
+	assert.Equal(t, uint64(1), policy.ProjectID)
+	assert.True(t, policy.IsDefault)
+	assert.Nil(t, policy.UpdatedAt)
+
+	now := time.Now()
+	policy = convertRecycleBinPolicy(1, &dao.TestRecycleBinPolicy{
+		BaseModel:     dbengine.BaseModel{UpdatedAt: now},
+		ProjectID:     1,
+		RetentionDays: 7,
+		UpdaterID:     "2",
+	})
+	assert.False(t, policy.IsDefault)
+	assert.Equal(t, 7, policy.RetentionDays)
+	assert.Equal(t, "2", policy.UpdaterID)
+	assert.Equal(t, now, *policy.UpdatedAt)
+}
+
+func TestPurgeProjectRecycleBin(t *testing.T) {
+	// 测试集 1 下有用例 11、12，测试集 2 下没有用例，另有单独回收的用例 21、22
+	recycledTestSets := []dao.TestSet{{BaseModel: dbengine.BaseModel{ID: 1}}, {BaseModel: dbengine.BaseModel{ID: 2}}}
+	testSetCases := map[uint64][]uint64{1: {11, 12}}
+	recycledTestCases := []uint64{21, 22}
+
+	db := &dao.DBClient{}
+	tcSvc := &testcase.Service{}
+	svc := New(WithDBClient(db), WithTestCaseService(tcSvc))
+
+	var cleanedTestSets, cleanedPermissions, cleanedTestCases []uint64
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ListRecycledTestSetsBefore", func(_ *dao.DBClient, projectID uint64, before time.Time, limit int) ([]dao.TestSet, error) {
+		ret := recycledTestSets
+		recycledTestSets = nil
+		return ret, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "ListRecycledTestCaseIDsBefore", func(_ *dao.DBClient, projectID uint64, before time.Time, limit int) ([]uint64, error) {
+		ret := recycledTestCases
+		recycledTestCases = nil
+		return ret, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "CleanTestSetFromRecycleBin", func(_ *dao.DBClient, testSetID uint64) error {
+		cleanedTestSets = append(cleanedTestSets, testSetID)
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(db), "DeleteTestSetPermissionsByTestSetIDs", func(_ *dao.DBClient, testSetIDs []uint64) error {
+		cleanedPermissions = append(cleanedPermissions, testSetIDs...)
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(tcSvc), "ListTestCases", func(_ *testcase.Service, req apistructs.TestCaseListRequest) ([]apistructs.TestCase, []uint64, error) {
+		assert.True(t, req.Recycled)
+		return nil, testSetCases[req.TestSetIDs[0]], nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(tcSvc), "BatchCleanFromRecycleBin", func(_ *testcase.Service, req apistructs.TestCaseBatchCleanFromRecycleBinRequest) error {
+		assert.Equal(t, recycleBinPurgeOperator, req.InternalClient)
+		cleanedTestCases = append(cleanedTestCases, req.TestCaseIDs...)
+		return nil
+	})
+	defer monkey.UnpatchAll()
+
+	testSetCount, testCaseCount := svc.purgeProjectRecycleBin(1, time.Now())
+	assert.Equal(t, 2, testSetCount)
+	assert.Equal(t, 4, testCaseCount)
+	assert.Equal(t, []uint64{1, 2}, cleanedTestSets)
+	assert.Equal(t, []uint64{1, 2}, cleanedPermissions)
+	sort.Slice(cleanedTestCases, func(i, j int) bool { return cleanedTestCases[i] < cleanedTestCases[j] })
+	assert.Equal(t, []uint64{11, 12, 21, 22}, cleanedTestCases)
+}
@@ -51,6 +51,7 @@ type MailData struct {
 	Type        string            `json:"type"` // 默认不做二次渲染当做html, 值为markdown时:使用模式渲染html
 	Attachments []*Attachment     `json:"attachments"`
 	OrgID       int64             `json:"orgID"`
+	Cc          []string          `json:"cc"`
 }
 
 type Option func(*MailSubscriber)
@@ -158,19 +159,28 @@ func (d *MailSubscriber) sendToMail(mails []string, mailData *MailData) error {
 	if displayUser == "" {
 		displayUser = smtpUser
 	}
+	ccAddrs := []string{}
+	for _, m := range mailData.Cc {
+		if _, err := mail.ParseAddress(m); err == nil {
+			ccAddrs = append(ccAddrs, m)
+		}
+	}
 	msg := NewMessage(subject, body, "text/html;charset=UTF-8")
 	msg.To = mailsAddrs
+	msg.Cc = ccAddrs
 	msg.FromDisplayName = displayUser
 	msg.From = smtpUser
 	for _, attachment := range mailData.Attachments {
 		msg.Attach(attachment)
 	}
 
+	// 抄送地址同样需要作为 smtp 收件人投递
+	rcpts := append(append([]string{}, mailsAddrs...), ccAddrs...)
 	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
 	if isSSL {
-		err = SendMailUsingTLS(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, smtpUser, mailsAddrs, d.insecureSkipVerify, msg.Bytes())
+		err = SendMailUsingTLS(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, smtpUser, rcpts, d.insecureSkipVerify, msg.Bytes())
 	} else {
-		err = smtp.SendMail(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, smtpUser, mailsAddrs, msg.Bytes())
+		err = smtp.SendMail(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, smtpUser, rcpts, msg.Bytes())
 	}
 	return err
 }
@@ -25,6 +25,8 @@ import (
 	"github.com/recallsong/go-utils/encoding/jsonx"
 	"github.com/recallsong/go-utils/reflectx"
 
+	"github.com/erda-project/erda-infra/base/logs"
+	querydb "github.com/erda-project/erda/modules/extensions/loghub/index/query/db"
 	"github.com/erda-project/erda/modules/msp/instance/db"
 	"github.com/erda-project/erda/pkg/http/httpclient"
 )
@@ -38,23 +40,58 @@ const (
 // ESClient .
 type ESClient struct {
 	*elastic.Client
-	URLs       string
-	LogVersion string
-	Indices    []string
+	URLs        string
+	LogVersion  string
+	Indices     []string
+	ClusterName string
+
+	// 超过 slowQueryThreshold 的查询会被记录到 logger，为 0 时不记录
+	slowQueryThreshold time.Duration
+	logger             logs.Logger
 }
 
 func (c *ESClient) printSearchSource(searchSource *elastic.SearchSource) (string, error) {
+	body, err := c.formatSearchSource(searchSource)
+	if err != nil {
+		return "", err
+	}
+	fmt.Println(body)
+	return body, nil
+}
+
+func (c *ESClient) formatSearchSource(searchSource *elastic.SearchSource) (string, error) {
 	source, err := searchSource.Source()
 	if err != nil {
 		return "", fmt.Errorf("invalid search source: %s", err)
 	}
 	body := jsonx.MarshalAndIndent(source)
 	body = c.URLs + "\n" + strings.Join(c.Indices, ",") + "\n" + body
-	fmt.Println(body)
 	return body, nil
 }
 
+func (c *ESClient) logSlowQuery(searchSource *elastic.SearchSource, elapsed time.Duration) {
+	if c.slowQueryThreshold <= 0 || elapsed <= c.slowQueryThreshold || c.logger == nil {
+		return
+	}
+	body, err := c.formatSearchSource(searchSource)
+	if err != nil {
+		c.logger.Warnf("slow log query, elapsed: %s, %s", elapsed, err)
+		return
+	}
+	c.logger.Warnf("slow log query, elapsed: %s\n%s", elapsed, body)
+}
+
+func (p *provider) setSlowQueryLog(clients []*ESClient) {
+	for _, c := range clients {
+		c.slowQueryThreshold = p.C.SlowQueryThreshold
+		c.logger = p.L
+	}
+}
+
 func (p *provider) getESClients(orgID int64, req *LogRequest) []*ESClient {
+	if req.AllClusters {
+		return p.getESClientsFromOrgLogDeployments(orgID)
+	}
 	if len(req.ClusterName) > 0 || len(req.Addon) > 0 {
 		if len(req.ClusterName) <= 0 || len(req.Addon) <= 0 {
 			return nil
@@ -64,7 +101,9 @@ func (p *provider) getESClients(orgID int64, req *LogRequest) []*ESClient {
 	}
 	filters := make(map[string]string)
 	for _, item := range req.Filters {
-		filters[item.Key] = item.Value
+		if !item.Regexp {
+			filters[item.Key] = item.Value
+		}
 	}
 	if filters["origin"] == "sls" {
 		return p.getCenterESClients("sls-*")
@@ -105,11 +144,25 @@ func (p *provider) getESClientsFromLogAnalytics(orgID int64) []*ESClient {
 	return p.getESClientsFromLogAnalyticsByCluster(orgID, "", clusterNames...)
 }
 
+// getESClientsFromOrgLogDeployments 返回企业下所有集群的日志存储，不受当前用户可访问集群的限制，仅供管理员查询使用
+func (p *provider) getESClientsFromOrgLogDeployments(orgID int64) []*ESClient {
+	list, err := p.db.LogDeployment.QueryByOrgID(orgID)
+	if err != nil {
+		p.L.Errorf("failed to query log deployments of org %d: %s", orgID, err)
+		return nil
+	}
+	return p.newESClientsFromLogDeployments(list, "")
+}
+
 func (p *provider) getESClientsFromLogAnalyticsByCluster(orgID int64, addon string, clusterNames ...string) []*ESClient {
 	list, err := p.db.LogDeployment.QueryByOrgIDAndClusters(orgID, clusterNames...)
 	if err != nil {
 		return nil
 	}
+	return p.newESClientsFromLogDeployments(list, addon)
+}
+
+func (p *provider) newESClientsFromLogDeployments(list []*querydb.LogDeployment, addon string) []*ESClient {
 	type ESConfig struct {
 		Security bool   `json:"securityEnable"`
 		Username string `json:"securityUsername"`
@@ -165,37 +218,41 @@ func (p *provider) getESClientsFromLogAnalyticsByCluster(orgID int64, addon stri
 			options = append(options, elastic.SetHttpClient(newHTTPClient(d.ClusterName)))
 		}
 
-		orgId := d.OrgID
-		if d.LogType == string(db.LogTypeLogAnalytics) {
-			// omit the orgId alias, if deployed by log-analytics addon，specially for old versions, there's no orgId alias
-			orgId = ""
-		}
-
 		client, err := elastic.NewClient(options...)
 		if err != nil {
 			p.L.Errorf("failed to create elasticsearch client: %s", err)
 			continue
 		}
-		d.CollectorURL = strings.TrimSpace(d.CollectorURL)
-		if len(d.CollectorURL) > 0 || d.LogType == string(db.LogTypeLogService) {
-			clients = append(clients, &ESClient{
-				Client:     client,
-				LogVersion: LogVersion2,
-				URLs:       d.ESURL,
-				Indices:    getLogIndices("rlogs-", orgId, addons...),
-			})
-		} else {
-			clients = append(clients, &ESClient{
-				Client:     client,
-				LogVersion: LogVersion1,
-				URLs:       d.ESURL,
-				Indices:    getLogIndices("spotlogs-", orgId, addons...),
-			})
-		}
+		version, indices := getLogDeploymentIndices(d, addons...)
+		clients = append(clients, &ESClient{
+			Client:      client,
+			LogVersion:  version,
+			URLs:        d.ESURL,
+			Indices:     indices,
+			ClusterName: d.ClusterName,
+		})
 	}
 	return clients
 }
 
+// getLogDeploymentIndices 根据日志部署记录返回日志的版本及需要查询的索引，部署记录中配置了索引前缀时使用该前缀
+func getLogDeploymentIndices(d *querydb.LogDeployment, addons ...string) (string, []string) {
+	orgId := d.OrgID
+	if d.LogType == string(db.LogTypeLogAnalytics) {
+		// omit the orgId alias, if deployed by log-analytics addon，specially for old versions, there's no orgId alias
+		orgId = ""
+	}
+	version, prefix := LogVersion1, "spotlogs-"
+	d.CollectorURL = strings.TrimSpace(d.CollectorURL)
+	if len(d.CollectorURL) > 0 || d.LogType == string(db.LogTypeLogService) {
+		version, prefix = LogVersion2, "rlogs-"
+	}
+	if p := strings.TrimSpace(d.IndexPrefix); len(p) > 0 {
+		prefix = p
+	}
+	return version, getLogIndices(prefix, orgId, addons...)
+}
+
 func getLogIndices(prefix, orgId string, addons ...string) []string {
 	if len(addons) > 0 {
 		var indices []string
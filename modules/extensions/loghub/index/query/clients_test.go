@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic"
+
+	"github.com/erda-project/erda-infra/base/logs"
+	querydb "github.com/erda-project/erda/modules/extensions/loghub/index/query/db"
+	"github.com/erda-project/erda/modules/msp/instance/db"
+)
+
+func TestGetLogDeploymentIndices(t *testing.T) {
+	tests := []struct {
+		name        string
+		deployment  *querydb.LogDeployment
+		addons      []string
+		wantVersion string
+		wantIndices []string
+	}{
+		{
+			name:        "log-analytics default prefix",
+			deployment:  &querydb.LogDeployment{OrgID: "1", LogType: string(db.LogTypeLogAnalytics)},
+			wantVersion: LogVersion1,
+			wantIndices: []string{"spotlogs-*"},
+		},
+		{
+			name:        "log-analytics with collector default prefix",
+			deployment:  &querydb.LogDeployment{OrgID: "1", LogType: string(db.LogTypeLogAnalytics), CollectorURL: "http://collector"},
+			addons:      []string{"a"},
+			wantVersion: LogVersion2,
+			wantIndices: []string{"rlogs-a", "rlogs-a-*"},
+		},
+		{
+			name:        "log-analytics overridden prefix",
+			deployment:  &querydb.LogDeployment{OrgID: "1", LogType: string(db.LogTypeLogAnalytics), IndexPrefix: "applogs-"},
+			addons:      []string{"a", "b"},
+			wantVersion: LogVersion1,
+			wantIndices: []string{"applogs-a", "applogs-a-*", "applogs-b", "applogs-b-*"},
+		},
+		{
+			name:        "log-service default prefix",
+			deployment:  &querydb.LogDeployment{OrgID: "1", LogType: string(db.LogTypeLogService)},
+			wantVersion: LogVersion2,
+			wantIndices: []string{"rlogs-1"},
+		},
+		{
+			name:        "log-service overridden prefix",
+			deployment:  &querydb.LogDeployment{OrgID: "1", LogType: string(db.LogTypeLogService), IndexPrefix: " applogs- "},
+			wantVersion: LogVersion2,
+			wantIndices: []string{"applogs-1"},
+		},
+		{
+			name:        "log-service overridden prefix with addon",
+			deployment:  &querydb.LogDeployment{OrgID: "1", LogType: string(db.LogTypeLogService), IndexPrefix: "applogs-"},
+			addons:      []string{"a"},
+			wantVersion: LogVersion2,
+			wantIndices: []string{"applogs-a", "applogs-a-*"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, indices := getLogDeploymentIndices(tt.deployment, tt.addons...)
+			if version != tt.wantVersion {
+				t.Errorf("getLogDeploymentIndices() version = %v, want %v", version, tt.wantVersion)
+			}
+			if !reflect.DeepEqual(indices, tt.wantIndices) {
+				t.Errorf("getLogDeploymentIndices() indices = %v, want %v", indices, tt.wantIndices)
+			}
+		})
+	}
+}
+
+type slowQueryLogger struct {
+	logs.Logger
+	warnings []string
+}
+
+func (l *slowQueryLogger) Warnf(template string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(template, args...))
+}
+
+func TestESClientLogSlowQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		elapsed   time.Duration
+		wantLog   bool
+	}{
+		{name: "disabled", threshold: 0, elapsed: time.Hour},
+		{name: "below threshold", threshold: 100 * time.Millisecond, elapsed: 50 * time.Millisecond},
+		{name: "equal to threshold", threshold: 100 * time.Millisecond, elapsed: 100 * time.Millisecond},
+		{name: "above threshold", threshold: 100 * time.Millisecond, elapsed: 200 * time.Millisecond, wantLog: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &slowQueryLogger{}
+			c := &ESClient{URLs: "http://es", Indices: []string{"rlogs-1"}, slowQueryThreshold: tt.threshold, logger: logger}
+			c.logSlowQuery(elastic.NewSearchSource().Query(elastic.NewTermQuery("tags.level", "error")), tt.elapsed)
+			if !tt.wantLog {
+				if len(logger.warnings) != 0 {
+					t.Errorf("logSlowQuery() logged %v, want nothing", logger.warnings)
+				}
+				return
+			}
+			if len(logger.warnings) != 1 {
+				t.Fatalf("logSlowQuery() logged %d times, want 1", len(logger.warnings))
+			}
+			if !strings.Contains(logger.warnings[0], tt.elapsed.String()) || !strings.Contains(logger.warnings[0], "tags.level") {
+				t.Errorf("logSlowQuery() logged %q, want elapsed and query", logger.warnings[0])
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ type LogDeployment struct {
 	CollectorURL string `gorm:"column:collector_url" json:"collector_url"`
 	Domain       string `gorm:"column:domain" json:"domain"`
 	LogType      string `gorm:"column:log_type" json:"log_type"`
+	IndexPrefix  string `gorm:"column:index_prefix" json:"index_prefix"`
 }
 
 // TableName .
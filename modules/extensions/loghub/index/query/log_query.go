@@ -32,6 +32,10 @@ import (
 type Tag struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// Regexp 为 true 时 Value 作为正则匹配 tags.Key，对应 es 的 regexp 查询。
+	// 请求中的正则使用 Go (RE2) 语法，查询前转换为 Lucene 语法，不支持 \b 及非贪婪匹配。
+	// 正则需要匹配整个字段值，且会遍历字段的所有词项，在大索引上很慢，应尽量使用前缀明确的表达式，如 /api/v2.*
+	Regexp bool `json:"regexp,omitempty"`
 }
 
 // LogRequest .
@@ -45,6 +49,7 @@ type LogRequest struct {
 	Query       string
 	Debug       bool
 	Lang        i18n.LanguageCodes
+	AllClusters bool // 查询企业下所有集群的日志，仅限企业管理员
 }
 
 // LogSearchRequest .
@@ -140,7 +145,9 @@ func (c *ESClient) statisticLogs(req *LogStatisticRequest, timeout time.Duration
 func (c *ESClient) getTagsBoolQuery(req *LogRequest) *elastic.BoolQuery {
 	boolQuery := elastic.NewBoolQuery()
 	for _, item := range req.Filters {
-		if item.Key != "origin" {
+		if item.Regexp {
+			boolQuery = boolQuery.Filter(elastic.NewRegexpQuery("tags."+item.Key, item.Value))
+		} else if item.Key != "origin" {
 			boolQuery = boolQuery.Filter(elastic.NewTermQuery("tags."+item.Key, item.Value))
 		}
 	}
@@ -174,10 +181,12 @@ func (c *ESClient) getSearchSource(req *LogSearchRequest, boolQuery *elastic.Boo
 func (c *ESClient) doRequest(searchSource *elastic.SearchSource, timeout time.Duration) (*elastic.SearchResult, error) {
 	context, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
+	start := time.Now()
 	resp, err := c.Client.Search(c.Indices...).
 		IgnoreUnavailable(true).
 		AllowNoIndices(true).
 		SearchSource(searchSource).Do(context)
+	c.logSlowQuery(searchSource, time.Since(start))
 	if err != nil || (resp != nil && resp.Error != nil) {
 		if resp != nil && resp.Error != nil {
 			return nil, fmt.Errorf("fail to request es: %s", jsonx.MarshalAndIndent(resp.Error))
@@ -218,15 +227,32 @@ func (c *ESClient) setModule(log *logs.Log) {
 	}
 }
 
+// setClusterName 标记日志来源集群，跨集群查询时用于区分结果
+func (c *ESClient) setClusterName(result *LogQueryResponse) {
+	if len(c.ClusterName) <= 0 {
+		return
+	}
+	for _, log := range result.Data {
+		if log.Tags == nil {
+			log.Tags = make(map[string]string)
+		}
+		log.Tags["cluster_name"] = c.ClusterName
+	}
+}
+
 // SearchLogs .
 func (p *provider) SearchLogs(req *LogSearchRequest) (interface{}, error) {
 	clients := p.getESClients(req.OrgID, &req.LogRequest)
+	p.setSlowQueryLog(clients)
 	var results []*LogQueryResponse
 	for _, client := range clients {
 		result, err := client.searchLogs(req, p.C.Timeout)
 		if err != nil {
 			continue
 		}
+		if req.AllClusters {
+			client.setClusterName(result)
+		}
 		results = append(results, result)
 	}
 	return mergeLogSearch(int(req.Size), results), nil
@@ -275,6 +301,7 @@ func mergeLogSearch(limit int, results []*LogQueryResponse) *LogQueryResponse {
 // StatisticLogs .
 func (p *provider) StatisticLogs(req *LogStatisticRequest) (interface{}, error) {
 	clients := p.getESClients(req.OrgID, &req.LogRequest)
+	p.setSlowQueryLog(clients)
 	var results []*LogStatisticResponse
 	name := p.t.Text(req.Lang, "Count")
 	for _, client := range clients {
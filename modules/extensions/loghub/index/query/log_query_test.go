@@ -16,6 +16,7 @@ package query
 
 import (
 	"fmt"
+	"testing"
 
 	"github.com/recallsong/go-utils/encoding/jsonx"
 
@@ -137,3 +138,27 @@ func Example_mergeLogSearch() {
 	fmt.Println(jsonx.MarshalAndIndent(result), len(result.Data))
 
 }
+
+func TestESClientSetClusterName(t *testing.T) {
+	result := &LogQueryResponse{Data: []*logs.Log{
+		{Content: "1"},
+		{Content: "2", Tags: map[string]string{"level": "INFO"}},
+	}}
+
+	(&ESClient{}).setClusterName(result)
+	for _, log := range result.Data {
+		if _, ok := log.Tags["cluster_name"]; ok {
+			t.Errorf("setClusterName() should not tag logs when cluster name is empty")
+		}
+	}
+
+	(&ESClient{ClusterName: "dev"}).setClusterName(result)
+	for _, log := range result.Data {
+		if log.Tags["cluster_name"] != "dev" {
+			t.Errorf("setClusterName() got cluster_name %q, want %q", log.Tags["cluster_name"], "dev")
+		}
+	}
+	if result.Data[1].Tags["level"] != "INFO" {
+		t.Errorf("setClusterName() should keep existing tags")
+	}
+}
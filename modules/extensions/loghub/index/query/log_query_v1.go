@@ -74,37 +74,45 @@ func (c *ESClient) searchLogsV1(req *LogSearchRequest, timeout time.Duration) (*
 		Total: total,
 	}
 	for _, hit := range hits {
-		if hit.Source == nil {
+		log, ok := c.convertHitV1(hit)
+		if !ok {
 			continue
 		}
-		// 	"tags": {
-		// 	    "dice_runtime_id": "7587",
-		// 	    "dice_service_name": "marketing-batch-server",
-		// 	    "dice_project_id": "181",
-		// 	    "dice_workspace": "staging",
-		// 	    "dice_application_id": "3027",
-		// 	    "dice_application_name": "longfor-marketing",
-		// 	    "dice_runtime_name": "release/1.0",
-		// 	    "stream": "stdout",
-		// 	    "container_id": "5a4fa92ecb687d02ed9063155760d37c3a90c06a15e0df0e8b6d5b037aba4b9a",
-		// 	    "terminus_log_key": "zcdff177492c8441ebb84ab63f4e297ae",
-		// 	    "dice_project_name": "longfor-middle-marketing"
-		// 	},
-		// 	"offset": 2206380,
-		// 	"message": "Hibernate: select activity0_.id as id1_26_, activity0_.approval_id as approval2_26_, activity0_.approval_status as approval3_26_, activity0_.batch_id as batch_id4_26_, activity0_.created_at as created_5_26_, activity0_.description as descript6_26_, activity0_.ext1 as ext7_26_, activity0_.ext2 as ext8_26_, activity0_.ext3 as ext9_26_, activity0_.group_id as group_i10_26_, activity0_.link as link11_26_, activity0_.marketing_mode as marketi12_26_, activity0_.name as name13_26_, activity0_.operator_id as operato14_26_, activity0_.operator_name as operato15_26_, activity0_.status as status16_26_, activity0_.target_code as target_17_26_, activity0_.updated_at as updated18_26_, activity0_.work_flow as work_fl19_26_ from sb_activity activity0_ where activity0_.approval_status=3 and (activity0_.status in (1 , 2))",
-		// 	"@timestamp": "2020-07-20T22:21:01.631Z"
-		var logv1 LogV1
-		err := json.Unmarshal([]byte(*hit.Source), &logv1)
-		if err != nil {
-			continue
-		}
-		log := logv1.ToLog()
-		c.setModule(log)
 		resp.Data = append(resp.Data, log)
 	}
 	return resp, nil
 }
 
+func (c *ESClient) convertHitV1(hit *elastic.SearchHit) (*logs.Log, bool) {
+	if hit.Source == nil {
+		return nil, false
+	}
+	// 	"tags": {
+	// 	    "dice_runtime_id": "7587",
+	// 	    "dice_service_name": "marketing-batch-server",
+	// 	    "dice_project_id": "181",
+	// 	    "dice_workspace": "staging",
+	// 	    "dice_application_id": "3027",
+	// 	    "dice_application_name": "longfor-marketing",
+	// 	    "dice_runtime_name": "release/1.0",
+	// 	    "stream": "stdout",
+	// 	    "container_id": "5a4fa92ecb687d02ed9063155760d37c3a90c06a15e0df0e8b6d5b037aba4b9a",
+	// 	    "terminus_log_key": "zcdff177492c8441ebb84ab63f4e297ae",
+	// 	    "dice_project_name": "longfor-middle-marketing"
+	// 	},
+	// 	"offset": 2206380,
+	// 	"message": "Hibernate: select activity0_.id as id1_26_, activity0_.approval_id as approval2_26_, activity0_.approval_status as approval3_26_, activity0_.batch_id as batch_id4_26_, activity0_.created_at as created_5_26_, activity0_.description as descript6_26_, activity0_.ext1 as ext7_26_, activity0_.ext2 as ext8_26_, activity0_.ext3 as ext9_26_, activity0_.group_id as group_i10_26_, activity0_.link as link11_26_, activity0_.marketing_mode as marketi12_26_, activity0_.name as name13_26_, activity0_.operator_id as operato14_26_, activity0_.operator_name as operato15_26_, activity0_.status as status16_26_, activity0_.target_code as target_17_26_, activity0_.updated_at as updated18_26_, activity0_.work_flow as work_fl19_26_ from sb_activity activity0_ where activity0_.approval_status=3 and (activity0_.status in (1 , 2))",
+	// 	"@timestamp": "2020-07-20T22:21:01.631Z"
+	var logv1 LogV1
+	err := json.Unmarshal([]byte(*hit.Source), &logv1)
+	if err != nil {
+		return nil, false
+	}
+	log := logv1.ToLog()
+	c.setModule(log)
+	return log, true
+}
+
 func (c *ESClient) statisticLogsV1(req *LogStatisticRequest, timeout time.Duration, name string) (*LogStatisticResponse, error) {
 	boolQuery := c.getBoolQueryV1(&req.LogRequest)
 	searchSource := elastic.NewSearchSource().Query(boolQuery)
@@ -52,21 +52,29 @@ func (c *ESClient) searchLogsV2(req *LogSearchRequest, timeout time.Duration) (*
 		Total: total,
 	}
 	for _, hit := range hits {
-		if hit.Source == nil {
+		log, ok := c.convertHitV2(hit)
+		if !ok {
 			continue
 		}
-		var log logs.Log
-		err := json.Unmarshal([]byte(*hit.Source), &log)
-		if err != nil {
-			continue
-		}
-		c.setModule(&log)
-		log.Timestamp = log.Timestamp / int64(time.Millisecond)
-		resp.Data = append(resp.Data, &log)
+		resp.Data = append(resp.Data, log)
 	}
 	return resp, nil
 }
 
+func (c *ESClient) convertHitV2(hit *elastic.SearchHit) (*logs.Log, bool) {
+	if hit.Source == nil {
+		return nil, false
+	}
+	var log logs.Log
+	err := json.Unmarshal([]byte(*hit.Source), &log)
+	if err != nil {
+		return nil, false
+	}
+	c.setModule(&log)
+	log.Timestamp = log.Timestamp / int64(time.Millisecond)
+	return &log, true
+}
+
 func (c *ESClient) statisticLogsV2(req *LogStatisticRequest, timeout time.Duration, name string) (*LogStatisticResponse, error) {
 	boolQuery := c.getBoolQueryV2(&req.LogRequest)
 	searchSource := elastic.NewSearchSource().Query(boolQuery)
@@ -0,0 +1,212 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// toLuceneRegexp 将 Go (RE2) 语法的正则转换为 es regexp 查询使用的 Lucene 语法。
+// Lucene 正则总是匹配整个字段值，不支持锚点，因此只允许 ^ 和 $ 出现在开头和结尾并将其去掉；
+// \b 等 Lucene 不支持的语法返回错误。
+func toLuceneRegexp(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	re = trimAnchors(re)
+	var sb strings.Builder
+	if err := writeLuceneRegexp(&sb, re); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// trimAnchors 去掉开头和结尾的锚点
+func trimAnchors(re *syntax.Regexp) *syntax.Regexp {
+	if isAnchor(re) {
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	}
+	if re.Op != syntax.OpConcat || len(re.Sub) == 0 {
+		return re
+	}
+	subs := re.Sub
+	for len(subs) > 0 && isAnchor(subs[0]) {
+		subs = subs[1:]
+	}
+	for len(subs) > 0 && isAnchor(subs[len(subs)-1]) {
+		subs = subs[:len(subs)-1]
+	}
+	trimmed := *re
+	trimmed.Sub = subs
+	return &trimmed
+}
+
+func isAnchor(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return true
+	}
+	return false
+}
+
+func writeLuceneRegexp(sb *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		sb.WriteString("()")
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			writeLuceneLiteral(sb, r, re.Flags&syntax.FoldCase != 0)
+		}
+	case syntax.OpCharClass:
+		writeLuceneCharClass(sb, re.Rune)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte('.')
+	case syntax.OpCapture:
+		sb.WriteByte('(')
+		if err := writeLuceneRegexp(sb, re.Sub[0]); err != nil {
+			return err
+		}
+		sb.WriteByte(')')
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if re.Flags&syntax.NonGreedy != 0 {
+			return fmt.Errorf("non-greedy repetition is not supported")
+		}
+		if err := writeLuceneAtom(sb, re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			sb.WriteByte('*')
+		case syntax.OpPlus:
+			sb.WriteByte('+')
+		case syntax.OpQuest:
+			sb.WriteByte('?')
+		default:
+			sb.WriteString("{" + strconv.Itoa(re.Min))
+			if re.Max < 0 {
+				sb.WriteByte(',')
+			} else if re.Max != re.Min {
+				sb.WriteString("," + strconv.Itoa(re.Max))
+			}
+			sb.WriteByte('}')
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpAlternate {
+				if err := writeLuceneAtom(sb, sub); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := writeLuceneRegexp(sb, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				sb.WriteByte('|')
+			}
+			if err := writeLuceneRegexp(sb, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return fmt.Errorf("anchors are only allowed at the beginning or end of the pattern")
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return fmt.Errorf("word boundary is not supported")
+	default:
+		return fmt.Errorf("unsupported regexp: %s", re)
+	}
+	return nil
+}
+
+// writeLuceneAtom 写入作为重复或连接对象的子表达式，非单个字符时用括号包裹
+func writeLuceneAtom(sb *strings.Builder, re *syntax.Regexp) error {
+	switch {
+	case re.Op == syntax.OpCharClass, re.Op == syntax.OpAnyChar, re.Op == syntax.OpAnyCharNotNL, re.Op == syntax.OpCapture,
+		re.Op == syntax.OpLiteral && len(re.Rune) == 1 && re.Flags&syntax.FoldCase == 0:
+		return writeLuceneRegexp(sb, re)
+	}
+	sb.WriteByte('(')
+	if err := writeLuceneRegexp(sb, re); err != nil {
+		return err
+	}
+	sb.WriteByte(')')
+	return nil
+}
+
+// writeLuceneLiteral 写入字面字符，Lucene 中的特殊字符均需转义；忽略大小写时转换为字符集合
+func writeLuceneLiteral(sb *strings.Builder, r rune, foldCase bool) {
+	if foldCase {
+		if folds := caseFolds(r); len(folds) > 1 {
+			sb.WriteByte('[')
+			for _, f := range folds {
+				writeLuceneRune(sb, f)
+			}
+			sb.WriteByte(']')
+			return
+		}
+	}
+	writeLuceneRune(sb, r)
+}
+
+func caseFolds(r rune) []rune {
+	folds := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		folds = append(folds, f)
+	}
+	return folds
+}
+
+// writeLuceneCharClass 写入字符集合，ranges 为成对的起止字符
+func writeLuceneCharClass(sb *strings.Builder, ranges []rune) {
+	sb.WriteByte('[')
+	// 取反的集合如 [^/] 解析后为覆盖其余所有字符的区间，转换回 [^...] 的形式
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		sb.WriteByte('^')
+		var negated []rune
+		for i := 1; i+1 < len(ranges); i += 2 {
+			negated = append(negated, ranges[i]+1, ranges[i+1]-1)
+		}
+		ranges = negated
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		writeLuceneRune(sb, ranges[i])
+		if ranges[i+1] != ranges[i] {
+			sb.WriteByte('-')
+			writeLuceneRune(sb, ranges[i+1])
+		}
+	}
+	sb.WriteByte(']')
+}
+
+func writeLuceneRune(sb *strings.Builder, r rune) {
+	if r < utf8RuneSelf && !isAlphaNumeric(r) {
+		sb.WriteByte('\\')
+	}
+	sb.WriteRune(r)
+}
+
+const utf8RuneSelf = 0x80
+
+func isAlphaNumeric(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
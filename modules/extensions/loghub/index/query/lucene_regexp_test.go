@@ -0,0 +1,52 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestToLuceneRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{pattern: "/api/v2.*", want: `\/api\/v2.*`},
+		{pattern: "^/api/v[12]/users$", want: `\/api\/v[1-2]\/users`},
+		{pattern: `\d{3,}`, want: `[0-9]{3,}`},
+		{pattern: `id-\d{2,4}`, want: `id\-[0-9]{2,4}`},
+		{pattern: `[^/]+/health`, want: `[^\/]+\/health`},
+		{pattern: "(get|post) /users", want: `(get|post)\ \/users`},
+		{pattern: "a(bc)*d?", want: `a(bc)*d?`},
+		{pattern: "err(or)?|warn", want: `err(or)?|warn`},
+		{pattern: "(?i)error", want: `[Ee][Rr][Rr][Oo][Rr]`},
+		{pattern: `a\.b"c`, want: `a\.b\"c`},
+		{pattern: `\berror`, wantErr: true},
+		{pattern: "a.*?b", wantErr: true},
+		{pattern: "a^b", wantErr: true},
+		{pattern: "(a", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := toLuceneRegexp(tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("toLuceneRegexp(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("toLuceneRegexp(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
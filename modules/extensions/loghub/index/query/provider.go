@@ -35,6 +35,14 @@ import (
 type config struct {
 	Timeout     time.Duration `file:"timeout" default:"60s"`
 	QueryBackES bool          `file:"query_back_es" default:"false"`
+	// 正则过滤的限制，正则查询需要遍历字段的所有词项，在大索引上开销很高
+	RegexpMaxLength       int  `file:"regexp_max_length" default:"256"`
+	RejectUnboundedRegexp bool `file:"reject_unbounded_regexp" default:"true"`
+	// 慢查询日志，记录耗时超过阈值的查询语句，为 0 时关闭
+	SlowQueryThreshold time.Duration `file:"slow_query_threshold" default:"0s"`
+	// scroll 遍历大量日志时每批的条数及 scroll 上下文的保持时间
+	ScrollBatchSize int           `file:"scroll_batch_size" default:"1000"`
+	ScrollKeepAlive time.Duration `file:"scroll_keep_alive" default:"1m"`
 }
 
 type provider struct {
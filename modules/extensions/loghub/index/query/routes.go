@@ -17,10 +17,12 @@ package query
 import (
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/erda-project/erda-infra/providers/httpserver"
+	"github.com/erda-project/erda/apistructs"
 	api "github.com/erda-project/erda/pkg/common/httpapi"
 )
 
@@ -54,11 +56,67 @@ func (p *provider) buildLogFilters(r *http.Request) []*Tag {
 					Value: v,
 				})
 			}
+		} else if strings.HasPrefix(k, "regexp.") {
+			// 正则匹配，使用 Go (RE2) 语法，如 regexp.request_path=/api/v2/.*
+			k = k[len("regexp."):]
+			if len(k) <= 0 {
+				continue
+			}
+			for _, v := range vs {
+				filters = append(filters, &Tag{
+					Key:    k,
+					Value:  v,
+					Regexp: true,
+				})
+			}
 		}
 	}
 	return filters
 }
 
+// checkFilters 校验正则过滤条件，并将其转换为 es 使用的 Lucene 正则语法
+func (p *provider) checkFilters(filters []*Tag) error {
+	for _, item := range filters {
+		if !item.Regexp {
+			continue
+		}
+		if err := checkRegexp(item.Value, p.C.RegexpMaxLength, p.C.RejectUnboundedRegexp); err != nil {
+			return fmt.Errorf("invalid regexp of %s: %s", item.Key, err)
+		}
+		value, err := toLuceneRegexp(item.Value)
+		if err != nil {
+			return fmt.Errorf("invalid regexp of %s: %s", item.Key, err)
+		}
+		item.Value = value
+	}
+	return nil
+}
+
+// checkRegexp 限制正则的长度和形式，避免开销过大的查询
+func checkRegexp(pattern string, maxLength int, rejectUnbounded bool) error {
+	if len(pattern) <= 0 {
+		return fmt.Errorf("empty pattern")
+	}
+	if maxLength > 0 && len(pattern) > maxLength {
+		return fmt.Errorf("pattern is longer than %d", maxLength)
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return err
+	}
+	if rejectUnbounded && isUnboundedPrefix(pattern) && isUnboundedSuffix(pattern) {
+		return fmt.Errorf("pattern can not start and end with .* or .+")
+	}
+	return nil
+}
+
+func isUnboundedPrefix(pattern string) bool {
+	return strings.HasPrefix(pattern, ".*") || strings.HasPrefix(pattern, ".+")
+}
+
+func isUnboundedSuffix(pattern string) bool {
+	return (strings.HasSuffix(pattern, ".*") || strings.HasSuffix(pattern, ".+")) && !strings.HasSuffix(pattern, `\.*`) && !strings.HasSuffix(pattern, `\.+`)
+}
+
 func (p *provider) checkTime(start, end int64) error {
 	if end <= start {
 		return fmt.Errorf("end must after start")
@@ -69,6 +127,31 @@ func (p *provider) checkTime(start, end int64) error {
 	return nil
 }
 
+// checkAllClustersQuery 跨集群查询会绕过用户的集群权限，只允许企业管理员使用
+func (p *provider) checkAllClustersQuery(r *http.Request, orgID int64, addon, clusterName string) interface{} {
+	if len(addon) > 0 || len(clusterName) > 0 {
+		return api.Errors.InvalidParameter("allClusters can not be used with addon or clusterName")
+	}
+	userID := api.UserID(r)
+	if len(userID) <= 0 {
+		return api.Errors.AccessDenied("missing User-ID")
+	}
+	resp, err := p.bdl.CheckPermission(&apistructs.PermissionCheckRequest{
+		UserID:   userID,
+		Scope:    apistructs.OrgScope,
+		ScopeID:  uint64(orgID),
+		Resource: apistructs.OrgResource,
+		Action:   apistructs.UpdateAction,
+	})
+	if err != nil {
+		return api.Errors.Internal(err)
+	}
+	if !resp.Access {
+		return api.Errors.AccessDenied("only org administrators can query logs of all clusters")
+	}
+	return nil
+}
+
 func (p *provider) logStatistic(r *http.Request, params struct {
 	Start       int64  `query:"start" validate:"gte=1"`
 	End         int64  `query:"end" validate:"gte=1"`
@@ -78,16 +161,25 @@ func (p *provider) logStatistic(r *http.Request, params struct {
 	Debug       bool   `query:"debug"`
 	Addon       string `param:"addon"`
 	ClusterName string `query:"clusterName"`
+	AllClusters bool   `query:"allClusters"`
 }) interface{} {
 	orgID := api.OrgID(r)
 	orgid, err := strconv.ParseInt(orgID, 10, 64)
 	if err != nil {
 		return api.Errors.InvalidParameter("invalid Org-ID")
 	}
+	if params.AllClusters {
+		if resp := p.checkAllClustersQuery(r, orgid, params.Addon, params.ClusterName); resp != nil {
+			return resp
+		}
+	}
 	if params.Points <= 0 {
 		params.Points = 60
 	}
 	filters := p.buildLogFilters(r)
+	if err := p.checkFilters(filters); err != nil {
+		return api.Errors.InvalidParameter(err)
+	}
 	data, err := p.StatisticLogs(&LogStatisticRequest{
 		LogRequest: LogRequest{
 			OrgID:       orgid,
@@ -99,6 +191,7 @@ func (p *provider) logStatistic(r *http.Request, params struct {
 			Query:       params.Query,
 			Debug:       params.Debug,
 			Lang:        api.Language(r),
+			AllClusters: params.AllClusters,
 		},
 		Points:   params.Points,
 		Interval: params.Interval,
@@ -118,12 +211,18 @@ func (p *provider) logSearch(r *http.Request, params struct {
 	Debug       bool   `query:"debug"`
 	Addon       string `param:"addon"`
 	ClusterName string `query:"clusterName"`
+	AllClusters bool   `query:"allClusters"`
 }) interface{} {
 	orgID := api.OrgID(r)
 	orgid, err := strconv.ParseInt(orgID, 10, 64)
 	if err != nil {
 		return api.Errors.InvalidParameter("invalid Org-ID")
 	}
+	if params.AllClusters {
+		if resp := p.checkAllClustersQuery(r, orgid, params.Addon, params.ClusterName); resp != nil {
+			return resp
+		}
+	}
 	if params.Size <= 0 {
 		params.Size = 50
 	}
@@ -132,6 +231,9 @@ func (p *provider) logSearch(r *http.Request, params struct {
 		return api.Errors.InvalidParameter(err)
 	}
 	filters := p.buildLogFilters(r)
+	if err := p.checkFilters(filters); err != nil {
+		return api.Errors.InvalidParameter(err)
+	}
 	logs, err := p.SearchLogs(&LogSearchRequest{
 		LogRequest: LogRequest{
 			OrgID:       orgid,
@@ -143,6 +245,7 @@ func (p *provider) logSearch(r *http.Request, params struct {
 			Query:       params.Query,
 			Debug:       params.Debug,
 			Lang:        api.Language(r),
+			AllClusters: params.AllClusters,
 		},
 		Size: params.Size,
 		Sort: params.Sort,
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+)
+
+func TestCheckRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: "/api/v2.*"},
+		{pattern: ".*/health"},
+		{pattern: `/api/v1\.*`},
+		{pattern: "", wantErr: true},
+		{pattern: ".*error.*", wantErr: true},
+		{pattern: ".+error.+", wantErr: true},
+		{pattern: "/api/(v1", wantErr: true},
+		{pattern: "/api/v1/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := checkRegexp(tt.pattern, 64, true); (err != nil) != tt.wantErr {
+			t.Errorf("checkRegexp(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+	if err := checkRegexp(".*error.*", 64, false); err != nil {
+		t.Errorf("checkRegexp() should allow unbounded pattern when not rejected, got %v", err)
+	}
+}
+
+func TestCheckAllClustersQuery(t *testing.T) {
+	p := &provider{bdl: &bundle.Bundle{}}
+	var checked *apistructs.PermissionCheckRequest
+	access, checkErr := true, error(nil)
+	monkey.PatchInstanceMethod(reflect.TypeOf(p.bdl), "CheckPermission", func(_ *bundle.Bundle, req *apistructs.PermissionCheckRequest) (*apistructs.PermissionCheckResponseData, error) {
+		checked = req
+		if checkErr != nil {
+			return nil, checkErr
+		}
+		return &apistructs.PermissionCheckResponseData{Access: access}, nil
+	})
+	defer monkey.UnpatchAll()
+
+	newRequest := func(userID string) *http.Request {
+		r, _ := http.NewRequest(http.MethodGet, "/api/logs/search", nil)
+		if len(userID) > 0 {
+			r.Header.Set("User-ID", userID)
+		}
+		return r
+	}
+
+	if resp := p.checkAllClustersQuery(newRequest("1"), 1, "addon", ""); resp == nil {
+		t.Errorf("checkAllClustersQuery() should reject addon")
+	}
+	if resp := p.checkAllClustersQuery(newRequest("1"), 1, "", "dev"); resp == nil {
+		t.Errorf("checkAllClustersQuery() should reject clusterName")
+	}
+	if resp := p.checkAllClustersQuery(newRequest(""), 1, "", ""); resp == nil {
+		t.Errorf("checkAllClustersQuery() should reject request without User-ID")
+	}
+	if checked != nil {
+		t.Errorf("checkAllClustersQuery() should not check permission for invalid request")
+	}
+
+	if resp := p.checkAllClustersQuery(newRequest("1"), 2, "", ""); resp != nil {
+		t.Errorf("checkAllClustersQuery() should allow org administrator, got %v", resp)
+	}
+	if checked == nil || checked.UserID != "1" || checked.Scope != apistructs.OrgScope || checked.ScopeID != 2 ||
+		checked.Resource != apistructs.OrgResource || checked.Action != apistructs.UpdateAction {
+		t.Errorf("checkAllClustersQuery() checked unexpected permission %+v", checked)
+	}
+
+	access = false
+	if resp := p.checkAllClustersQuery(newRequest("1"), 2, "", ""); resp == nil {
+		t.Errorf("checkAllClustersQuery() should reject user who is not org administrator")
+	}
+	checkErr = fmt.Errorf("connection refused")
+	if resp := p.checkAllClustersQuery(newRequest("1"), 2, "", ""); resp == nil {
+		t.Errorf("checkAllClustersQuery() should return error when permission check failed")
+	}
+}
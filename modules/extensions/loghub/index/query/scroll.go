@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olivere/elastic"
+
+	logs "github.com/erda-project/erda/modules/core/monitor/log"
+)
+
+// ScrollHandler 处理 scroll 查询返回的一批日志，返回错误时终止遍历
+type ScrollHandler func(list []*logs.Log) error
+
+const scrollClearTimeout = 10 * time.Second
+
+// ScrollLogs 通过 scroll 逐批遍历所有匹配的日志，用于导出等需要读取大量结果的场景
+func (p *provider) ScrollLogs(ctx context.Context, req *LogRequest, handler ScrollHandler) error {
+	clients := p.getESClients(req.OrgID, req)
+	p.setSlowQueryLog(clients)
+	for _, client := range clients {
+		client := client
+		err := client.scrollLogs(ctx, req, p.C.ScrollBatchSize, p.C.ScrollKeepAlive, func(list []*logs.Log) error {
+			if req.AllClusters {
+				client.setClusterName(&LogQueryResponse{Data: list})
+			}
+			return handler(list)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ESClient) scrollLogs(ctx context.Context, req *LogRequest, batchSize int, keepAlive time.Duration, handler ScrollHandler) error {
+	var (
+		query     *elastic.BoolQuery
+		timestamp string
+		convert   func(hit *elastic.SearchHit) (*logs.Log, bool)
+	)
+	switch c.LogVersion {
+	case LogVersion1:
+		query, timestamp, convert = c.getBoolQueryV1(req), "@timestamp", c.convertHitV1
+	default:
+		query, timestamp, convert = c.getBoolQueryV2(req), "timestamp", c.convertHitV2
+	}
+	searchSource := elastic.NewSearchSource().Query(query).
+		Sort(timestamp, true).Sort("offset", true)
+	scroll := c.Client.Scroll(c.Indices...).
+		IgnoreUnavailable(true).AllowNoIndices(true).
+		KeepAlive(formatKeepAlive(keepAlive)).
+		SearchSource(searchSource).Size(batchSize)
+	defer func() {
+		// ctx 可能已经被取消，使用独立的 context 清理 scroll 上下文
+		clearCtx, cancel := context.WithTimeout(context.Background(), scrollClearTimeout)
+		defer cancel()
+		if err := scroll.Clear(clearCtx); err != nil && c.logger != nil {
+			c.logger.Warnf("failed to clear scroll of indices %v: %s", c.Indices, err)
+		}
+	}()
+	for {
+		start := time.Now()
+		result, err := scroll.Do(ctx)
+		c.logSlowQuery(searchSource, time.Since(start))
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if result.Hits == nil || len(result.Hits.Hits) <= 0 {
+			return nil
+		}
+		list := make([]*logs.Log, 0, len(result.Hits.Hits))
+		for _, hit := range result.Hits.Hits {
+			item, ok := convert(hit)
+			if !ok {
+				continue
+			}
+			list = append(list, item)
+		}
+		if len(list) <= 0 {
+			continue
+		}
+		if err := handler(list); err != nil {
+			return err
+		}
+	}
+}
+
+// formatKeepAlive 将时长转换为 es 的时间单位格式，例如 1m、30s
+func formatKeepAlive(d time.Duration) string {
+	if d <= 0 {
+		d = time.Minute
+	}
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic"
+
+	logs "github.com/erda-project/erda/modules/core/monitor/log"
+)
+
+func TestFormatKeepAlive(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "1m"},
+		{d: -time.Second, want: "1m"},
+		{d: 5 * time.Minute, want: "5m"},
+		{d: 90 * time.Second, want: "90s"},
+		{d: 1500 * time.Millisecond, want: "1500ms"},
+	}
+	for _, tt := range tests {
+		if got := formatKeepAlive(tt.d); got != tt.want {
+			t.Errorf("formatKeepAlive(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// newScrollTestServer 模拟 es 的 scroll 接口，按 pages 逐页返回日志，返回的 cleared 记录清理过的 scroll id
+func newScrollTestServer(t *testing.T, pages [][]string) (server *httptest.Server, requests func() []string, cleared func() []string) {
+	var (
+		mu          sync.Mutex
+		reqs, clear []string
+		page        int
+	)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/_search/scroll":
+			var body struct {
+				ScrollID []string `json:"scroll_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			clear = append(clear, body.ScrollID...)
+			fmt.Fprint(w, `{"succeeded":true,"num_freed":1}`)
+			return
+		case strings.HasSuffix(r.URL.Path, "/_search/scroll"):
+			reqs = append(reqs, "scroll")
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			reqs = append(reqs, "search:"+r.URL.Query().Get("scroll"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var hits []string
+		if page < len(pages) {
+			for _, content := range pages[page] {
+				hits = append(hits, fmt.Sprintf(`{"_index":"rlogs-1","_id":%q,"_source":{"content":%q,"timestamp":1000000}}`, content, content))
+			}
+		}
+		page++
+		fmt.Fprintf(w, `{"_scroll_id":"scroll-1","hits":{"total":%d,"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+	}))
+	requests = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, reqs...)
+	}
+	cleared = func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, clear...)
+	}
+	return server, requests, cleared
+}
+
+func newScrollTestClient(t *testing.T, url string) *ESClient {
+	client, err := elastic.NewClient(elastic.SetURL(url), elastic.SetSniff(false), elastic.SetHealthcheck(false))
+	if err != nil {
+		t.Fatalf("failed to create es client: %s", err)
+	}
+	return &ESClient{Client: client, LogVersion: LogVersion2, Indices: []string{"rlogs-1"}}
+}
+
+func TestESClientScrollLogs(t *testing.T) {
+	server, requests, cleared := newScrollTestServer(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}})
+	defer server.Close()
+	client := newScrollTestClient(t, server.URL)
+
+	var batches [][]string
+	err := client.scrollLogs(context.Background(), &LogRequest{Start: 1, End: 2}, 2, 90*time.Second, func(list []*logs.Log) error {
+		var batch []string
+		for _, log := range list {
+			batch = append(batch, log.Content)
+		}
+		batches = append(batches, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scrollLogs() error = %v", err)
+	}
+	if got, want := fmt.Sprint(batches), "[[1 2] [3 4] [5]]"; got != want {
+		t.Errorf("scrollLogs() got batches %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprint(requests()), "[search:90s scroll scroll scroll]"; got != want {
+		t.Errorf("scrollLogs() sent requests %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprint(cleared()), "[scroll-1]"; got != want {
+		t.Errorf("scrollLogs() cleared scrolls %s, want %s", got, want)
+	}
+}
+
+func TestESClientScrollLogsHandlerError(t *testing.T) {
+	server, requests, cleared := newScrollTestServer(t, [][]string{{"1", "2"}, {"3", "4"}})
+	defer server.Close()
+	client := newScrollTestClient(t, server.URL)
+
+	handlerErr := fmt.Errorf("client disconnected")
+	var calls int
+	err := client.scrollLogs(context.Background(), &LogRequest{Start: 1, End: 2}, 2, time.Minute, func(list []*logs.Log) error {
+		calls++
+		return handlerErr
+	})
+	if err != handlerErr {
+		t.Errorf("scrollLogs() error = %v, want %v", err, handlerErr)
+	}
+	if calls != 1 || len(requests()) != 1 {
+		t.Errorf("scrollLogs() should stop after handler error, handler calls %d, requests %v", calls, requests())
+	}
+	if got, want := fmt.Sprint(cleared()), "[scroll-1]"; got != want {
+		t.Errorf("scrollLogs() cleared scrolls %s, want %s", got, want)
+	}
+}
+
+func TestESClientScrollLogsSlowQuery(t *testing.T) {
+	server, requests, _ := newScrollTestServer(t, [][]string{{"1", "2"}, {"3"}})
+	defer server.Close()
+	client := newScrollTestClient(t, server.URL)
+	logger := &slowQueryLogger{}
+	client.slowQueryThreshold, client.logger = time.Nanosecond, logger
+
+	err := client.scrollLogs(context.Background(), &LogRequest{Start: 1, End: 2}, 2, time.Minute, func(list []*logs.Log) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scrollLogs() error = %v", err)
+	}
+	// 每次 scroll 请求单独计时
+	if got, want := fmt.Sprint(requests()), "[search:1m scroll scroll]"; got != want {
+		t.Errorf("scrollLogs() sent requests %s, want %s", got, want)
+	}
+	if len(logger.warnings) != len(requests()) {
+		t.Errorf("scrollLogs() logged %d slow queries, want %d", len(logger.warnings), len(requests()))
+	}
+	for _, warning := range logger.warnings {
+		if !strings.Contains(warning, `"offset"`) {
+			t.Errorf("scrollLogs() logged %q, want the scroll query", warning)
+		}
+	}
+}
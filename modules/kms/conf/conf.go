@@ -16,8 +16,12 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/erda-project/erda/pkg/envconf"
+	"github.com/erda-project/erda/pkg/kms"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
+	"github.com/erda-project/erda/pkg/strutil"
 )
 
 // Conf define config from envs.
@@ -26,6 +30,17 @@ type Conf struct {
 	Debug         bool               `env:"DEBUG" default:"false"`
 	KmsStoreKind  kmstypes.StoreKind `env:"KMS_STORE_KIND" default:"ETCD"`
 	EtcdEndpoints string             `env:"ETCD_ENDPOINTS" required:"false"`
+
+	// 密钥默认的调用频率限制，为 0 时不限制，密钥可单独设置
+	RateLimitQPS            float64 `env:"KMS_RATE_LIMIT_QPS" default:"0"`
+	RateLimitBurst          int     `env:"KMS_RATE_LIMIT_BURST" default:"0"`
+	RateLimitPerCallerQPS   float64 `env:"KMS_RATE_LIMIT_PER_CALLER_QPS" default:"0"`
+	RateLimitPerCallerBurst int     `env:"KMS_RATE_LIMIT_PER_CALLER_BURST" default:"0"`
+	// 不受频率限制的内部调用方的来源地址或网段，多个以逗号分隔，如 10.0.0.8,172.16.0.0/12
+	RateLimitExemptCallers string `env:"KMS_RATE_LIMIT_EXEMPT_CALLERS" default:""`
+
+	// 密钥使用情况先在内存中累计，按周期批量写入存储
+	KeyUsageFlushInterval time.Duration `env:"KMS_KEY_USAGE_FLUSH_INTERVAL" default:"1m"`
 }
 
 var cfg Conf
@@ -58,3 +73,19 @@ func KmsStoreKind() kmstypes.StoreKind {
 func EtcdEndpoints() string {
 	return cfg.EtcdEndpoints
 }
+
+// RateLimitConfig 返回密钥默认的调用频率限制
+func RateLimitConfig() kms.RateLimitConfig {
+	return kms.RateLimitConfig{
+		QPS:            cfg.RateLimitQPS,
+		Burst:          cfg.RateLimitBurst,
+		PerCallerQPS:   cfg.RateLimitPerCallerQPS,
+		PerCallerBurst: cfg.RateLimitPerCallerBurst,
+		ExemptCallers:  strutil.Split(cfg.RateLimitExemptCallers, ",", true),
+	}
+}
+
+// KeyUsageFlushInterval 返回密钥使用情况的写入周期
+func KeyUsageFlushInterval() time.Duration {
+	return cfg.KeyUsageFlushInterval
+}
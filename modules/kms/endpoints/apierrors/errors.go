@@ -20,14 +20,20 @@ import (
 )
 
 var (
-	ErrCheckIdentity    = err("ErrCheckIdentity", "身份校验失败")
-	ErrParseRequest     = err("ErrParseRequest", "解析请求失败")
-	ErrCreateKey        = err("ErrCreateKey", "创建 KMS 用户主密钥失败")
-	ErrEncrypt          = err("ErrEncrypt", "对称加密失败")
-	ErrDecrypt          = err("ErrDecrypt", "对称解密失败")
-	ErrGenerateDataKey  = err("ErrGenerateDataKey", "生成数据加密密钥失败")
-	ErrRotateKeyVersion = err("ErrRotateKeyVersion", "轮转密钥版本失败")
-	ErrDescribeKey      = err("ErrDescribeKey", "查询用户主密钥失败")
+	ErrCheckIdentity      = err("ErrCheckIdentity", "身份校验失败")
+	ErrParseRequest       = err("ErrParseRequest", "解析请求失败")
+	ErrCreateKey          = err("ErrCreateKey", "创建 KMS 用户主密钥失败")
+	ErrEncrypt            = err("ErrEncrypt", "对称加密失败")
+	ErrDecrypt            = err("ErrDecrypt", "对称解密失败")
+	ErrGenerateDataKey    = err("ErrGenerateDataKey", "生成数据加密密钥失败")
+	ErrRotateKeyVersion   = err("ErrRotateKeyVersion", "轮转密钥版本失败")
+	ErrDescribeKey        = err("ErrDescribeKey", "查询用户主密钥失败")
+	ErrRotateAllKeys      = err("ErrRotateAllKeys", "批量轮转密钥失败")
+	ErrUpdateKeyRateLimit = err("ErrUpdateKeyRateLimit", "更新密钥调用频率限制失败")
+	ErrGetKeyUsage        = err("ErrGetKeyUsage", "查询密钥使用情况失败")
+	ErrListKeys           = err("ErrListKeys", "查询用户主密钥列表失败")
+	ErrUpdateKeyTags      = err("ErrUpdateKeyTags", "更新密钥标签失败")
+	ErrDeleteKey          = err("ErrDeleteKey", "删除用户主密钥失败")
 )
 
 func err(template, defaultValue string) *errorresp.APIError {
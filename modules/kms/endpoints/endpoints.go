@@ -57,6 +57,12 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 		{Path: "/api/kms/decrypt", Method: http.MethodPost, Handler: e.KmsDecrypt},
 		{Path: "/api/kms/generate-data-key", Method: http.MethodPost, Handler: e.KmsGenerateDataKey},
 		{Path: "/api/kms/rotate-key-version", Method: http.MethodPost, Handler: e.KmsRotateKeyVersion},
-		{Path: "/api/kms/describe-key", Method: http.MethodGet, Handler: e.KmsRotateKeyVersion},
+		{Path: "/api/kms/rotate-all", Method: http.MethodPost, Handler: e.KmsRotateAll},
+		{Path: "/api/kms/update-key-rate-limit", Method: http.MethodPost, Handler: e.KmsUpdateKeyRateLimit},
+		{Path: "/api/kms/describe-key", Method: http.MethodGet, Handler: e.KmsDescribeKey},
+		{Path: "/api/kms/list-keys", Method: http.MethodGet, Handler: e.KmsListKeys},
+		{Path: "/api/kms/update-key-tags", Method: http.MethodPost, Handler: e.KmsUpdateKeyTags},
+		{Path: "/api/kms/delete-key", Method: http.MethodPost, Handler: e.KmsDeleteKey},
+		{Path: "/api/kms/key-usage", Method: http.MethodGet, Handler: e.KmsKeyUsage},
 	}
 }
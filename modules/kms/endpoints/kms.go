@@ -17,6 +17,7 @@ package endpoints
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/erda-project/erda/modules/kms/conf"
 	"github.com/erda-project/erda/modules/kms/endpoints/apierrors"
@@ -43,7 +44,7 @@ func (e *Endpoints) KmsCreateKey(ctx context.Context, r *http.Request, vars map[
 	return httpserver.OkResp(createKeyResp)
 }
 
-func (e *Endpoints) DescribeKey(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+func (e *Endpoints) KmsDescribeKey(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
 	var req kmstypes.DescribeKeyRequest
 	if err := e.parseRequestBody(r, &req); err != nil {
 		return err.ToResp(), nil
@@ -61,3 +62,110 @@ func (e *Endpoints) DescribeKey(ctx context.Context, r *http.Request, vars map[s
 
 	return httpserver.OkResp(descResp)
 }
+
+// KmsListKeys 列出插件下的密钥，可按标签过滤
+func (e *Endpoints) KmsListKeys(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.ListKeysRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.KmsMgr.GetPlugin(req.PluginKind, conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrListKeys.InvalidParameter(err).ToResp(), nil
+	}
+	listResp, err := plugin.ListKeys(ctx, &req)
+	if err != nil {
+		return apierrors.ErrListKeys.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(listResp)
+}
+
+// KmsUpdateKeyTags 替换密钥的标签
+func (e *Endpoints) KmsUpdateKeyTags(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.UpdateKeyTagsRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	store, err := e.KmsMgr.GetStore(conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrUpdateKeyTags.InternalError(err).ToResp(), nil
+	}
+	keyInfo, err := store.UpdateKeyTags(req.KeyID, req.Tags)
+	if err != nil {
+		return apierrors.ErrUpdateKeyTags.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(kmstypes.UpdateKeyTagsResponse{KeyMetadata: kmstypes.GetKeyMetadata(keyInfo)})
+}
+
+// KmsDeleteKey 删除密钥及其所有版本, 删除后使用该密钥加密的数据无法再解密
+func (e *Endpoints) KmsDeleteKey(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.DeleteKeyRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	store, err := e.KmsMgr.GetStore(conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrDeleteKey.InternalError(err).ToResp(), nil
+	}
+	if err := store.DeleteByKeyID(req.KeyID); err != nil {
+		return apierrors.ErrDeleteKey.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(kmstypes.DeleteKeyResponse{})
+}
+
+// KmsUpdateKeyRateLimit 更新密钥的调用频率限制，立即生效
+func (e *Endpoints) KmsUpdateKeyRateLimit(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.UpdateKeyRateLimitRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	store, err := e.KmsMgr.GetStore(conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrUpdateKeyRateLimit.InternalError(err).ToResp(), nil
+	}
+	keyInfo, err := store.UpdateKeyRateLimit(req.KeyID, req.RateLimit)
+	if err != nil {
+		return apierrors.ErrUpdateKeyRateLimit.InternalError(err).ToResp(), nil
+	}
+
+	return httpserver.OkResp(kmstypes.UpdateKeyRateLimitResponse{KeyMetadata: kmstypes.GetKeyMetadata(keyInfo)})
+}
+
+// KmsKeyUsage 查询密钥在时间段内的使用情况
+func (e *Endpoints) KmsKeyUsage(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.KeyUsageRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	store, err := e.KmsMgr.GetStore(conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrGetKeyUsage.InternalError(err).ToResp(), nil
+	}
+	if _, err := store.GetKey(req.KeyID); err != nil {
+		return apierrors.ErrGetKeyUsage.InvalidParameter(err).ToResp(), nil
+	}
+	usage, err := e.KmsMgr.GetKeyUsage(conf.KmsStoreKind(), req.KeyID)
+	if err != nil {
+		return apierrors.ErrGetKeyUsage.InternalError(err).ToResp(), nil
+	}
+
+	resp := kmstypes.KeyUsageResponse{
+		KeyID:      req.KeyID,
+		LastUsedAt: usage.LastUsedAt,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Operations: usage.Count(time.Unix(req.StartTime, 0), time.Unix(req.EndTime, 0)),
+	}
+	for _, count := range resp.Operations {
+		resp.Total += count
+	}
+	return httpserver.OkResp(resp)
+}
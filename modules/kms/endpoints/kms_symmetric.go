@@ -17,9 +17,15 @@ package endpoints
 import (
 	"context"
 	"net/http"
+	"time"
 
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/kms/conf"
 	"github.com/erda-project/erda/modules/kms/endpoints/apierrors"
+	"github.com/erda-project/erda/pkg/crypto/uuid"
 	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/kms"
 	"github.com/erda-project/erda/pkg/kms/kmstypes"
 )
 
@@ -29,14 +35,18 @@ func (e *Endpoints) KmsEncrypt(ctx context.Context, r *http.Request, vars map[st
 		return err.ToResp(), nil
 	}
 
-	plugin, err := e.getPluginByKeyID(req.KeyID)
+	plugin, err := e.getPluginByKeyIDWithRateLimit(r, req.KeyID)
 	if err != nil {
+		if kms.IsRateLimited(err) {
+			return apierrors.ErrEncrypt.TooManyRequests(err.Error()).ToResp(), nil
+		}
 		return apierrors.ErrEncrypt.InternalError(err).ToResp(), nil
 	}
 	encryptResp, err := plugin.Encrypt(ctx, &req)
 	if err != nil {
 		return apierrors.ErrEncrypt.InternalError(err).ToResp(), nil
 	}
+	e.KmsMgr.RecordKeyUsage(req.KeyID, kmstypes.KeyOperationEncrypt)
 
 	return httpserver.OkResp(encryptResp)
 }
@@ -47,14 +57,18 @@ func (e *Endpoints) KmsDecrypt(ctx context.Context, r *http.Request, vars map[st
 		return err.ToResp(), nil
 	}
 
-	plugin, err := e.getPluginByKeyID(req.KeyID)
+	plugin, err := e.getPluginByKeyIDWithRateLimit(r, req.KeyID)
 	if err != nil {
+		if kms.IsRateLimited(err) {
+			return apierrors.ErrDecrypt.TooManyRequests(err.Error()).ToResp(), nil
+		}
 		return apierrors.ErrDecrypt.InternalError(err).ToResp(), nil
 	}
 	decryptResp, err := plugin.Decrypt(ctx, &req)
 	if err != nil {
 		return apierrors.ErrDecrypt.InternalError(err).ToResp(), nil
 	}
+	e.KmsMgr.RecordKeyUsage(req.KeyID, kmstypes.KeyOperationDecrypt)
 
 	return httpserver.OkResp(decryptResp)
 }
@@ -65,14 +79,18 @@ func (e *Endpoints) KmsGenerateDataKey(ctx context.Context, r *http.Request, var
 		return err.ToResp(), nil
 	}
 
-	plugin, err := e.getPluginByKeyID(req.KeyID)
+	plugin, err := e.getPluginByKeyIDWithRateLimit(r, req.KeyID)
 	if err != nil {
+		if kms.IsRateLimited(err) {
+			return apierrors.ErrGenerateDataKey.TooManyRequests(err.Error()).ToResp(), nil
+		}
 		return apierrors.ErrGenerateDataKey.InvalidParameter(err).ToResp(), nil
 	}
 	generateResp, err := plugin.GenerateDataKey(ctx, &req)
 	if err != nil {
 		return apierrors.ErrGenerateDataKey.InternalError(err).ToResp(), nil
 	}
+	e.KmsMgr.RecordKeyUsage(req.KeyID, kmstypes.KeyOperationGenerateDataKey)
 
 	return httpserver.OkResp(generateResp)
 }
@@ -94,3 +112,89 @@ func (e *Endpoints) KmsRotateKeyVersion(ctx context.Context, r *http.Request, va
 
 	return httpserver.OkResp(rotateResp)
 }
+
+// KmsRotateAll 轮转指定插件下所有启用状态的密钥，单个密钥失败不影响其他密钥
+func (e *Endpoints) KmsRotateAll(ctx context.Context, r *http.Request, vars map[string]string) (httpserver.Responser, error) {
+	var req kmstypes.RotateAllKeysRequest
+	if err := e.parseRequestBody(r, &req); err != nil {
+		return err.ToResp(), nil
+	}
+
+	plugin, err := e.KmsMgr.GetPlugin(req.PluginKind, conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrRotateAllKeys.InvalidParameter(err).ToResp(), nil
+	}
+	store, err := e.KmsMgr.GetStore(conf.KmsStoreKind())
+	if err != nil {
+		return apierrors.ErrRotateAllKeys.InternalError(err).ToResp(), nil
+	}
+	keyIDs, err := store.ListKeysByKind(req.PluginKind)
+	if err != nil {
+		return apierrors.ErrRotateAllKeys.InternalError(err).ToResp(), nil
+	}
+
+	if req.CorrelationID == "" {
+		req.CorrelationID = uuid.UUID()
+	}
+	resp := kmstypes.RotateAllKeysResponse{
+		CorrelationID: req.CorrelationID,
+		StartedAt:     time.Now(),
+		Results:       []kmstypes.RotateKeyResult{},
+	}
+	audit := logrus.WithFields(logrus.Fields{
+		"audit":         "kms-rotate-all",
+		"correlationID": req.CorrelationID,
+		"pluginKind":    req.PluginKind,
+	})
+	audit.Infof("start rotating all keys, candidates: %d", len(keyIDs))
+
+	for _, keyID := range keyIDs {
+		keyInfo, err := store.GetKey(keyID)
+		if err != nil {
+			resp.Results = append(resp.Results, kmstypes.RotateKeyResult{KeyID: keyID, Status: kmstypes.RotateKeyStatusFailed, Error: err.Error()})
+			continue
+		}
+		if keyInfo.GetKeyState() != kmstypes.KeyStateEnabled {
+			continue
+		}
+		// 重复执行时跳过已经轮转过的密钥
+		if req.NotRotatedSince != nil {
+			if createdAt := keyInfo.GetPrimaryKeyVersion().GetCreatedAt(); createdAt != nil && !createdAt.Before(*req.NotRotatedSince) {
+				resp.Results = append(resp.Results, kmstypes.RotateKeyResult{
+					KeyID:               keyID,
+					Status:              kmstypes.RotateKeyStatusSkipped,
+					PrimaryKeyVersionID: keyInfo.GetPrimaryKeyVersion().GetVersionID(),
+				})
+				continue
+			}
+		}
+		rotateResp, err := plugin.RotateKeyVersion(ctx, &kmstypes.RotateKeyVersionRequest{KeyID: keyID})
+		if err != nil {
+			resp.Results = append(resp.Results, kmstypes.RotateKeyResult{KeyID: keyID, Status: kmstypes.RotateKeyStatusFailed, Error: err.Error()})
+			continue
+		}
+		resp.Results = append(resp.Results, kmstypes.RotateKeyResult{
+			KeyID:               keyID,
+			Status:              kmstypes.RotateKeyStatusSuccess,
+			PrimaryKeyVersionID: rotateResp.KeyMetadata.PrimaryKeyVersionID,
+		})
+	}
+
+	for _, result := range resp.Results {
+		switch result.Status {
+		case kmstypes.RotateKeyStatusSuccess:
+			resp.Succeeded++
+			audit.WithField("keyID", result.KeyID).Infof("key rotated, new primary key version: %s", result.PrimaryKeyVersionID)
+		case kmstypes.RotateKeyStatusFailed:
+			resp.Failed++
+			audit.WithField("keyID", result.KeyID).Errorf("failed to rotate key, err: %s", result.Error)
+		case kmstypes.RotateKeyStatusSkipped:
+			resp.Skipped++
+		}
+	}
+	resp.Total = len(resp.Results)
+	audit.Infof("finish rotating all keys, total: %d, succeeded: %d, failed: %d, skipped: %d",
+		resp.Total, resp.Succeeded, resp.Failed, resp.Skipped)
+
+	return httpserver.OkResp(resp)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/http/httpserver"
+	"github.com/erda-project/erda/pkg/http/httputil"
+	"github.com/erda-project/erda/pkg/kms"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+type fakeRotateStore struct {
+	kmstypes.Store
+	keyIDs []string
+	keys   map[string]*kmstypes.Key
+}
+
+func (s *fakeRotateStore) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
+	return s.keyIDs, nil
+}
+
+func (s *fakeRotateStore) GetKey(keyID string) (kmstypes.KeyInfo, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key not exist")
+	}
+	return key, nil
+}
+
+type fakeRotatePlugin struct {
+	kmstypes.Plugin
+	rotated []string
+	fail    map[string]bool
+}
+
+func (p *fakeRotatePlugin) RotateKeyVersion(ctx context.Context, req *kmstypes.RotateKeyVersionRequest) (*kmstypes.RotateKeyVersionResponse, error) {
+	if p.fail[req.KeyID] {
+		return nil, fmt.Errorf("rotate failed")
+	}
+	p.rotated = append(p.rotated, req.KeyID)
+	return &kmstypes.RotateKeyVersionResponse{KeyMetadata: kmstypes.KeyMetadata{KeyID: req.KeyID, PrimaryKeyVersionID: req.KeyID + "-v2"}}, nil
+}
+
+func TestEndpoints_KmsRotateAll(t *testing.T) {
+	var (
+		lastRotated = time.Now().Add(-time.Hour)
+		since       = lastRotated.Add(time.Minute)
+		rerun       = since.Add(time.Minute)
+	)
+	newKey := func(keyID string, state kmstypes.KeyState, rotatedAt time.Time) *kmstypes.Key {
+		return &kmstypes.Key{
+			PluginKind:        kmstypes.PluginKind_DICE_KMS,
+			KeyID:             keyID,
+			KeyState:          state,
+			PrimaryKeyVersion: kmstypes.KeyVersion{VersionID: keyID + "-v1", CreatedAt: &rotatedAt},
+		}
+	}
+	store := &fakeRotateStore{
+		keyIDs: []string{"k1", "k2", "k3", "k4", "k5", "k6"},
+		keys: map[string]*kmstypes.Key{
+			"k1": newKey("k1", kmstypes.KeyStateEnabled, lastRotated),
+			"k2": newKey("k2", kmstypes.KeyStateDisabled, lastRotated),
+			"k3": newKey("k3", kmstypes.KeyStateEnabled, rerun),
+			"k4": newKey("k4", kmstypes.KeyStateEnabled, lastRotated),
+			"k5": newKey("k5", kmstypes.KeyStatePendingDeletion, lastRotated),
+			"k6": newKey("k6", kmstypes.KeyStateEnabled, lastRotated),
+		},
+	}
+	plugin := &fakeRotatePlugin{fail: map[string]bool{"k4": true}}
+
+	mgr := &kms.Manager{}
+	monkey.PatchInstanceMethod(reflect.TypeOf(mgr), "GetPlugin", func(_ *kms.Manager, _ kmstypes.PluginKind, _ kmstypes.StoreKind) (kmstypes.Plugin, error) {
+		return plugin, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(mgr), "GetStore", func(_ *kms.Manager, _ kmstypes.StoreKind) (kmstypes.Store, error) {
+		return store, nil
+	})
+	defer monkey.UnpatchAll()
+	e := New(WithKmsManager(mgr))
+
+	rotateAll := func(req kmstypes.RotateAllKeysRequest) kmstypes.RotateAllKeysResponse {
+		body, err := json.Marshal(req)
+		assert.NoError(t, err)
+		r := httptest.NewRequest(http.MethodPost, "/api/kms/rotate-all", bytes.NewReader(body))
+		r.Header.Set(httputil.InternalHeader, "test")
+		resp, err := e.KmsRotateAll(context.Background(), r, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.GetStatus())
+		return resp.GetContent().(httpserver.Resp).Data.(kmstypes.RotateAllKeysResponse)
+	}
+
+	// k2, k5 未启用, 不参与轮转; k4 轮转失败不影响后续的 k6
+	resp := rotateAll(kmstypes.RotateAllKeysRequest{CorrelationID: "rotate-1", NotRotatedSince: &since})
+	assert.Equal(t, "rotate-1", resp.CorrelationID)
+	assert.Equal(t, []string{"k1", "k6"}, plugin.rotated)
+	assert.Equal(t, []kmstypes.RotateKeyResult{
+		{KeyID: "k1", Status: kmstypes.RotateKeyStatusSuccess, PrimaryKeyVersionID: "k1-v2"},
+		{KeyID: "k3", Status: kmstypes.RotateKeyStatusSkipped, PrimaryKeyVersionID: "k3-v1"},
+		{KeyID: "k4", Status: kmstypes.RotateKeyStatusFailed, Error: "rotate failed"},
+		{KeyID: "k6", Status: kmstypes.RotateKeyStatusSuccess, PrimaryKeyVersionID: "k6-v2"},
+	}, resp.Results)
+	assert.Equal(t, 4, resp.Total)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Equal(t, 1, resp.Skipped)
+
+	// 重新执行时跳过上次已轮转的密钥, 只轮转遗留的 k4
+	store.keys["k1"].PrimaryKeyVersion.CreatedAt = &rerun
+	store.keys["k6"].PrimaryKeyVersion.CreatedAt = &rerun
+	store.keyIDs = append(store.keyIDs, "k7")
+	plugin.rotated = nil
+	plugin.fail = nil
+	resp = rotateAll(kmstypes.RotateAllKeysRequest{CorrelationID: "rotate-1", NotRotatedSince: &since})
+	assert.Equal(t, []string{"k4"}, plugin.rotated)
+	assert.Equal(t, 5, resp.Total)
+	assert.Equal(t, 1, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Equal(t, 3, resp.Skipped)
+	assert.Equal(t, kmstypes.RotateKeyResult{KeyID: "k7", Status: kmstypes.RotateKeyStatusFailed, Error: "key not exist"}, resp.Results[4])
+}
@@ -17,6 +17,7 @@ package endpoints
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
@@ -41,6 +42,32 @@ func (e *Endpoints) getPluginByKeyID(keyID string) (kmstypes.Plugin, error) {
 	return e.KmsMgr.GetPlugin(keyInfo.GetPluginKind(), conf.KmsStoreKind())
 }
 
+// getPluginByKeyIDWithRateLimit 根据 keyID 获取对应的 plugin，并校验调用方对密钥的调用频率
+func (e *Endpoints) getPluginByKeyIDWithRateLimit(r *http.Request, keyID string) (kmstypes.Plugin, error) {
+	store, err := e.KmsMgr.GetStore(conf.KmsStoreKind())
+	if err != nil {
+		return nil, err
+	}
+	keyInfo, err := store.GetKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.KmsMgr.CheckRateLimit(keyInfo, requestSourceIP(r)); err != nil {
+		return nil, err
+	}
+	return e.KmsMgr.GetPlugin(keyInfo.GetPluginKind(), conf.KmsStoreKind())
+}
+
+// requestSourceIP 返回请求连接的来源地址, 作为频率限制的调用方;
+// Internal-Client 等请求头可由调用方任意设置, 不能用于区分调用方
+func requestSourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // parseRequestBody return *errorresp.APIError
 func (e *Endpoints) parseRequestBody(r *http.Request, req kmstypes.RequestValidator) *errorresp.APIError {
 	if err := e.checkIdentity(r); err != nil {
@@ -16,6 +16,10 @@
 package kms
 
 import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/modules/kms/conf"
@@ -25,8 +29,8 @@ import (
 	"github.com/erda-project/erda/pkg/kms/stores/etcd"
 )
 
-// Initialize initialize and bootstrap the module.
-func Initialize() error {
+// Initialize initialize and bootstrap the module, pending key usage is flushed when ctx is done.
+func Initialize(ctx context.Context) error {
 	conf.Load()
 
 	// config logrus
@@ -34,23 +38,39 @@ func Initialize() error {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 
-	kmsMgr, err := kms.GetManager(kms.WithStoreConfigs(map[string]string{
-		etcd.EnvKeyEtcdEndpoints: conf.EtcdEndpoints(),
-	}))
+	kmsMgr, err := kms.GetManager(
+		kms.WithStoreConfigs(map[string]string{
+			etcd.EnvKeyEtcdEndpoints: conf.EtcdEndpoints(),
+		}),
+		kms.WithRateLimitConfig(conf.RateLimitConfig()),
+	)
 	if err != nil {
 		return err
 	}
 
+	go kmsMgr.FlushKeyUsage(ctx, conf.KmsStoreKind(), conf.KeyUsageFlushInterval())
+
 	ep := endpoints.New(endpoints.WithKmsManager(kmsMgr))
 
 	server := httpserver.New(conf.ListenAddr())
 	server.RegisterEndpoint(ep.Routes())
+	server.Router().Path("/metrics").Methods(http.MethodGet).Handler(promhttp.Handler())
 
 	if err := do(); err != nil {
 		return err
 	}
 
-	return server.ListenAndServe()
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.ListenAndServe() }()
+	select {
+	case err := <-errCh:
+		if closeErr := kmsMgr.Close(); closeErr != nil {
+			logrus.Errorf("failed to close kms manager, err: %v", closeErr)
+		}
+		return err
+	case <-ctx.Done():
+		return kmsMgr.Close()
+	}
 }
 
 func do() error {
@@ -22,7 +22,7 @@ import (
 
 type provider struct{}
 
-func (p *provider) Run(ctx context.Context) error { return Initialize() }
+func (p *provider) Run(ctx context.Context) error { return Initialize(ctx) }
 
 func init() {
 	servicehub.Register("kms", &servicehub.Spec{
@@ -165,6 +165,7 @@ type LogDeployment struct {
 	Created      time.Time `gorm:"column:created"`
 	Updated      time.Time `gorm:"column:updated"`
 	LogType      string    `gorm:"column:log_type;default:'log-analytics'"`
+	IndexPrefix  string    `gorm:"column:index_prefix"`
 }
 
 func (LogDeployment) TableName() string {
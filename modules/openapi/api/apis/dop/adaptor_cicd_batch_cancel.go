@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dop
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/openapi/api/apis"
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+var ADAPTOR_CICD_BATCH_CANCEL = apis.ApiSpec{
+	Path:         "/api/cicds/actions/batch-cancel",
+	BackendPath:  "/api/cicds/actions/batch-cancel",
+	Host:         "dop.marathon.l4lb.thisdcos.directory:9527",
+	Scheme:       "http",
+	Method:       http.MethodPost,
+	IsOpenAPI:    true,
+	CheckLogin:   true,
+	CheckToken:   true,
+	RequestType:  apistructs.PipelineBatchCancelRequest{},
+	ResponseType: apistructs.PipelineBatchCancelResponse{},
+	Doc:          "summary: 按条件批量取消 pipeline",
+	Audit: func(ctx *spec.AuditContext) error {
+		var res apistructs.PipelineBatchCancelResponse
+		if err := ctx.BindResponseData(&res); err != nil {
+			return err
+		}
+		if !res.Success || res.Data == nil {
+			return nil
+		}
+		result := res.Data
+		app, err := ctx.GetApp(result.AppID)
+		if err != nil {
+			return err
+		}
+		return ctx.CreateAudit(&apistructs.Audit{
+			Context: map[string]interface{}{
+				"projectId":   strconv.FormatUint(app.ProjectID, 10),
+				"appId":       strconv.FormatUint(app.ID, 10),
+				"projectName": app.ProjectName,
+				"appName":     app.Name,
+				"branch":      result.Branch,
+				"total":       strconv.Itoa(result.Total),
+				"canceled":    strconv.Itoa(result.Canceled),
+				"skipped":     strconv.Itoa(result.Skipped),
+				"failed":      strconv.Itoa(result.Failed),
+			},
+			ProjectID:    app.ProjectID,
+			AppID:        app.ID,
+			ScopeType:    "app",
+			TemplateName: apistructs.CancelPipelinesTemplate,
+			ScopeID:      app.ID,
+		})
+	},
+}
@@ -16,9 +16,11 @@ package dop
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/openapi/api/apis"
+	"github.com/erda-project/erda/modules/openapi/api/spec"
 )
 
 var CMDB_ISSUE_ManHour_SUM = apis.ApiSpec{
@@ -31,5 +33,11 @@ var CMDB_ISSUE_ManHour_SUM = apis.ApiSpec{
 	CheckToken:   true,
 	ResponseType: apistructs.IssueManHourSumResponse{},
 	IsOpenAPI:    true,
-	Doc:          "summary: 查询 ISSUE下所有的任务总和",
+	Timeout:      10 * time.Second,
+	CircuitBreaker: &spec.CircuitBreaker{
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	},
+	CacheTTL: 10 * time.Second,
+	Doc:      "summary: 查询 ISSUE下所有的任务总和",
 }
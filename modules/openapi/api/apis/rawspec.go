@@ -62,7 +62,22 @@ type ApiSpec struct {
 	CheckToken      bool
 	CheckBasicAuth  bool
 	ChunkAPI        bool
-	Doc             string
+	// 转发到后端的超时时间，为 0 时使用全局默认值，超时返回 504
+	Timeout time.Duration
+	// 熔断配置，为 nil 时不熔断，熔断期间返回 503
+	CircuitBreaker *spec.CircuitBreaker
+	// 限流配置，为 nil 时不限流，超出限额返回 429
+	RateLimit *spec.RateLimit
+	// GET 请求应答的缓存时间，为 0 时不缓存；缓存按 path、query、用户及企业区分，
+	// 请求头 Cache-Control: no-cache 可跳过缓存
+	CacheTTL time.Duration
+	// 请求体的 JSON Schema，不为 nil 时网关在转发前校验请求体，校验失败返回 400 及字段级的错误
+	RequestSchema *openapi3.Schema
+	// 是否已废弃，废弃的 API 应答中会带上 Deprecation header，调用会记录日志及计数
+	Deprecated bool
+	// 计划下线的日期，格式为 2006-01-02，设置后应答中会带上 Sunset header
+	SunsetDate string
+	Doc        string
 	// API 请求 & 应答 类型, 定义在 apistructs
 	RequestType  interface{}
 	ResponseType interface{}
@@ -339,6 +354,7 @@ func (api *ApiSpec) tagToOperation() {
 
 func (api *ApiSpec) newOperation() {
 	api.operation = openapi3.NewOperation()
+	api.operation.Deprecated = api.Deprecated
 	api.operation.RequestBody = newRequestBodyRef()
 	api.operation.Responses = openapi3.Responses{"200": newResponseRef()}
 }
@@ -0,0 +1,34 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testset
+
+import (
+	"net/http"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/openapi/api/apis"
+)
+
+var PERMISSION_GRANT = apis.ApiSpec{
+	Path:         "/api/testsets/<testSetID>/permissions",
+	BackendPath:  "/api/testsets/<testSetID>/permissions",
+	Host:         "dop.marathon.l4lb.thisdcos.directory:9527",
+	Scheme:       "http",
+	Method:       http.MethodPut,
+	CheckLogin:   true,
+	RequestType:  apistructs.TestSetPermissionGrantRequest{},
+	ResponseType: apistructs.TestSetPermissionGrantResponse{},
+	Doc:          "summary: 授予用户在测试集上的角色，子测试集未单独授权时继承该角色",
+}
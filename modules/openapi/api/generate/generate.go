@@ -70,6 +70,13 @@ func main() {
 			"MarathonHost":    quote(marathon),
 			"K8SHost":         quote(k8s),
 			"Port":            port,
+			"Timeout":         APINames[idx] + ".Timeout",
+			"CircuitBreaker":  APINames[idx] + ".CircuitBreaker",
+			"RateLimit":       APINames[idx] + ".RateLimit",
+			"CacheTTL":        APINames[idx] + ".CacheTTL",
+			"RequestSchema":   APINames[idx] + ".RequestSchema",
+			"Deprecated":      api.Deprecated,
+			"SunsetDate":      quote(api.SunsetDate),
 		})
 	}
 	trivialEnd(&buf)
@@ -86,7 +93,7 @@ func main() {
 	os.Remove("../../../../apistructs/generated_desc.go")
 }
 
-var SpecTemplate = template.Must(template.New("spec").Parse(`	{NewPath({{.Path}}), NewPath({{.BackendPath}}), {{.Host}}, {{.Scheme}}, {{.Method}}, {{.Custom}}, {{.CustomResponse}}, {{.Audit}}, {{.NeedDesensitize}}, {{.CheckLogin}}, {{.TryCheckLogin}}, {{.CheckToken}}, {{.CheckBasicAuth}}, {{.ChunkAPI}}, {{.MarathonHost}}, {{.K8SHost}}, {{.Port}}},
+var SpecTemplate = template.Must(template.New("spec").Parse(`	{NewPath({{.Path}}), NewPath({{.BackendPath}}), {{.Host}}, {{.Scheme}}, {{.Method}}, {{.Custom}}, {{.CustomResponse}}, {{.Audit}}, {{.NeedDesensitize}}, {{.CheckLogin}}, {{.TryCheckLogin}}, {{.CheckToken}}, {{.CheckBasicAuth}}, {{.ChunkAPI}}, {{.MarathonHost}}, {{.K8SHost}}, {{.Port}}, {{.Timeout}}, {{.CircuitBreaker}}, {{.RateLimit}}, {{.CacheTTL}}, {{.RequestSchema}}, {{.Deprecated}}, {{.SunsetDate}}},
 `))
 
 func convertHost(api *apis.ApiSpec) (marathon, k8s, port string, err error) {
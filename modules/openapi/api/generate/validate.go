@@ -15,8 +15,10 @@
 package main
 
 import (
+	"net/http"
 	"net/url"
 	"regexp"
+	"time"
 
 	"github.com/erda-project/erda/modules/openapi/api/apis"
 	"github.com/erda-project/erda/modules/openapi/api/spec"
@@ -48,6 +50,20 @@ func validate(r *apis.ApiSpec) error {
 	if err := validatePath(r.Path); err != nil {
 		return err
 	}
+	if r.CacheTTL > 0 && strutil.ToUpper(r.Method) != http.MethodGet {
+		return errors.New("CacheTTL is only supported by GET method")
+	}
+	if r.RequestSchema != nil && r.ChunkAPI {
+		return errors.New("RequestSchema is not supported by ChunkAPI")
+	}
+	if r.SunsetDate != "" {
+		if !r.Deprecated {
+			return errors.New("SunsetDate is only allowed on Deprecated api")
+		}
+		if _, err := time.Parse(spec.SunsetDateLayout, r.SunsetDate); err != nil {
+			return errors.Wrap(err, "invalid SunsetDate")
+		}
+	}
 	s := &spec.Spec{
 		Path:           spec.NewPath(r.Path),
 		BackendPath:    spec.NewPath(r.BackendPath),
@@ -24,6 +24,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
@@ -122,6 +123,61 @@ type Spec struct {
 	MarathonHost string
 	K8SHost      string
 	Port         int
+	// 转发到后端的超时时间，为 0 时使用全局默认值
+	Timeout time.Duration
+	// 熔断配置，为 nil 时不熔断
+	CircuitBreaker *CircuitBreaker
+	// 限流配置，为 nil 时不限流
+	RateLimit *RateLimit
+	// GET 请求应答的缓存时间，为 0 时不缓存
+	CacheTTL time.Duration
+	// 请求体的 JSON Schema，不为 nil 时转发前校验请求体
+	RequestSchema *openapi3.Schema
+	// 是否已废弃，废弃的 API 应答中会带上 Deprecation 及 Sunset header
+	Deprecated bool
+	// 计划下线的日期，格式为 2006-01-02
+	SunsetDate string
+}
+
+// SunsetDateLayout SunsetDate 的日期格式
+const SunsetDateLayout = "2006-01-02"
+
+// SunsetHeader 返回 Sunset header 的值，格式为 HTTP-date，未设置 SunsetDate 时返回空
+func (s *Spec) SunsetHeader() (string, error) {
+	if s.SunsetDate == "" {
+		return "", nil
+	}
+	t, err := time.Parse(SunsetDateLayout, s.SunsetDate)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(http.TimeFormat), nil
+}
+
+// CircuitBreaker 熔断配置，后端连续失败 FailureThreshold 次后熔断 OpenDuration，
+// 熔断期间请求直接返回 503，熔断结束后放行一个探测请求，成功则恢复
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+}
+
+// RateLimitDimension 限流的维度
+type RateLimitDimension string
+
+const (
+	// RateLimitByAPI 整个 API 共用一个限额
+	RateLimitByAPI RateLimitDimension = ""
+	// RateLimitByUser 每个用户单独计数
+	RateLimitByUser RateLimitDimension = "user"
+	// RateLimitByOrg 每个企业单独计数
+	RateLimitByOrg RateLimitDimension = "org"
+)
+
+// RateLimit 限流配置，每个 Interval 内最多允许 Requests 次请求，超出返回 429
+type RateLimit struct {
+	Requests  int
+	Interval  time.Duration
+	Dimension RateLimitDimension
 }
 
 func (s *Spec) Validate() error {
@@ -108,3 +108,19 @@ func TestPathMatch2(t *testing.T) {
 	assert.True(t, path.Match("/a/"))
 
 }
+
+func TestSunsetHeader(t *testing.T) {
+	s := &Spec{}
+	sunset, err := s.SunsetHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, "", sunset)
+
+	s.SunsetDate = "2021-12-31"
+	sunset, err = s.SunsetHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, "Fri, 31 Dec 2021 00:00:00 GMT", sunset)
+
+	s.SunsetDate = "2021/12/31"
+	_, err = s.SunsetHeader()
+	assert.Error(t, err)
+}
@@ -106,8 +106,9 @@ type RowData struct {
 }
 
 type AutoTestRunStep struct {
-	ApiSpec  map[string]interface{} `json:"apiSpec"`
-	WaitTime int64                  `json:"waitTime"`
+	ApiSpec  map[string]interface{}       `json:"apiSpec"`
+	WaitTime int64                        `json:"waitTime"`
+	Poll     *apistructs.AutoTestWaitPoll `json:"poll"`
 }
 
 func (a *ExecuteTaskTable) Import(c *apistructs.Component) error {
@@ -246,6 +247,8 @@ func transformStepType(str apistructs.StepAPIType) string {
 		return "配置单"
 	case apistructs.StepTypeCustomScript:
 		return "自定义"
+	case apistructs.StepTypeJSScript:
+		return "脚本"
 	case apistructs.AutotestSceneStep:
 		return "步骤"
 	case apistructs.AutotestSceneSet:
@@ -352,7 +355,13 @@ func (a *ExecuteTaskTable) setData(pipeline *apistructs.PipelineDetailDTO) error
 						}
 					}
 					if res.Type == apistructs.StepTypeWait {
-						res.Name = transformStepType(res.Type) + strconv.FormatInt(value.WaitTime, 10) + "s"
+						if value.Poll != nil {
+							if res.Name == "" {
+								res.Name = "等待条件"
+							}
+						} else {
+							res.Name = transformStepType(res.Type) + strconv.FormatInt(value.WaitTime, 10) + "s"
+						}
 					}
 				} else {
 					res.Name = task.Name
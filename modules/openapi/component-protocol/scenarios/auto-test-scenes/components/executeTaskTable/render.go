@@ -105,8 +105,9 @@ type RowData struct {
 }
 
 type AutoTestRunStep struct {
-	ApiSpec  map[string]interface{} `json:"apiSpec"`
-	WaitTime int64                  `json:"waitTime"`
+	ApiSpec  map[string]interface{}       `json:"apiSpec"`
+	WaitTime int64                        `json:"waitTime"`
+	Poll     *apistructs.AutoTestWaitPoll `json:"poll"`
 }
 
 func (a *ExecuteTaskTable) Import(c *apistructs.Component) error {
@@ -251,6 +252,8 @@ func transformStepType(str apistructs.StepAPIType) string {
 		return "配置单"
 	case apistructs.StepTypeCustomScript:
 		return "自定义"
+	case apistructs.StepTypeJSScript:
+		return "脚本"
 	}
 	return string(str)
 }
@@ -366,7 +369,13 @@ func (a *ExecuteTaskTable) setData(pipeline *apistructs.PipelineDetailDTO) error
 						}
 					}
 					if res.Type == apistructs.StepTypeWait {
-						res.Name = transformStepType(res.Type) + strconv.FormatInt(value.WaitTime, 10) + "s"
+						if value.Poll != nil {
+							if res.Name == "" {
+								res.Name = "等待条件"
+							}
+						} else {
+							res.Name = transformStepType(res.Type) + strconv.FormatInt(value.WaitTime, 10) + "s"
+						}
 					}
 				} else {
 					res.Name = task.Name
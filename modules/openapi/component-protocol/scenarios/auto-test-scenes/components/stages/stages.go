@@ -40,7 +40,11 @@ func RenderStage(groupID uint64, step apistructs.AutoTestSceneStep) (StageData,
 			if err := json.Unmarshal([]byte(step.Value), &value); err != nil {
 				return StageData{}, err
 			}
-			title = title + "等待 " + strconv.Itoa(value.WaitTime) + " 秒"
+			if value.Poll != nil {
+				title = title + "等待条件 " + value.Poll.Condition + " 超时 " + strconv.Itoa(value.Poll.TimeoutSec) + " 秒"
+			} else {
+				title = title + "等待 " + strconv.Itoa(value.WaitTime) + " 秒"
+			}
 		}
 	} else if step.Type == apistructs.StepTypeAPI {
 		if step.Value == "" {
@@ -67,6 +71,8 @@ func RenderStage(groupID uint64, step apistructs.AutoTestSceneStep) (StageData,
 		title = title + "嵌套场景: " + step.Name
 	} else if step.Type == apistructs.StepTypeCustomScript {
 		title = title + "自定义任务: " + step.Name
+	} else if step.Type == apistructs.StepTypeJSScript {
+		title = title + "脚本: " + step.Name
 	}
 	pd := StageData{
 		Title:      title,
@@ -17,6 +17,7 @@ package conf
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -63,6 +64,9 @@ type Conf struct {
 
 	// Allow people who are not admin to create org
 	CreateOrgEnabled bool `default:"false" env:"CREATE_ORG_ENABLED"`
+
+	// 转发到后端的默认超时时间，ApiSpec 未指定 Timeout 时使用
+	ProxyTimeout time.Duration `default:"60s" env:"PROXY_TIMEOUT"`
 }
 
 var cfg Conf
@@ -199,6 +203,10 @@ func ErdaSystemFQDN() string {
 	return cfg.ErdaSystemFQDN
 }
 
+func ProxyTimeout() time.Duration {
+	return cfg.ProxyTimeout
+}
+
 func CreateOrgEnabled() bool {
 	return cfg.CreateOrgEnabled
 }
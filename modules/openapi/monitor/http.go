@@ -182,6 +182,16 @@ func mkTables(headers []string, last5min, last20min, last1hour, last6hour, last1
 					strconv.FormatInt(last1day[k], 10)})
 			continue
 		}
+		if strings.HasPrefix(k, APIDeprecatedCount.String()) {
+			data[APIDeprecatedCount.String()] = append(data[APIDeprecatedCount.String()],
+				[]string{api,
+					strconv.FormatInt(last5min[k], 10),
+					strconv.FormatInt(last20min[k], 10),
+					strconv.FormatInt(last1hour[k], 10),
+					strconv.FormatInt(last6hour[k], 10),
+					strconv.FormatInt(last1day[k], 10)})
+			continue
+		}
 		data[k] = append(data[k],
 			[]string{api,
 				strconv.FormatInt(last5min[k], 10),
@@ -18,9 +18,9 @@ package monitor
 
 import "strconv"
 
-const _InfoType_name = "AuthFailAuthSuccAPIInvokeCountAPIInvokeDurationAPI50xCountAPI40xCountLastType"
+const _InfoType_name = "AuthFailAuthSuccAPIInvokeCountAPIInvokeDurationAPI50xCountAPI40xCountAPIDeprecatedCountLastType"
 
-var _InfoType_index = [...]uint8{0, 8, 16, 30, 47, 58, 69, 77}
+var _InfoType_index = [...]uint8{0, 8, 16, 30, 47, 58, 69, 87, 95}
 
 func (i InfoType) String() string {
 	if i < 0 || i >= InfoType(len(_InfoType_index)-1) {
@@ -46,6 +46,8 @@ const (
 	API50xCount
 	// API40xCount api 4xx 次数
 	API40xCount
+	// APIDeprecatedCount 已废弃 api 调用次数
+	APIDeprecatedCount
 	// LastType InfoType 个数
 	LastType
 )
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+// circuitBreaker 单个 API 的熔断状态
+type circuitBreaker struct {
+	sync.Mutex
+	cfg       spec.CircuitBreaker
+	failures  int
+	openUntil time.Time
+	probing   bool // 熔断结束后已放行探测请求，等待其结果
+}
+
+func newCircuitBreaker(cfg spec.CircuitBreaker) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	return &circuitBreaker{cfg: cfg}
+}
+
+// allow 返回当前是否允许请求转发到后端
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+	if b.failures < b.cfg.FailureThreshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record 记录一次请求的结果
+func (b *circuitBreaker) record(now time.Time, success bool) {
+	b.Lock()
+	defer b.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.openUntil = now.Add(b.cfg.OpenDuration)
+	}
+}
+
+// circuitBreakers 按 API 维护熔断状态
+type circuitBreakers struct {
+	m sync.Map
+}
+
+func (c *circuitBreakers) get(s *spec.Spec) *circuitBreaker {
+	key := s.Method + " " + s.Path.String()
+	if b, ok := c.m.Load(key); ok {
+		return b.(*circuitBreaker)
+	}
+	b, _ := c.m.LoadOrStore(key, newCircuitBreaker(*s.CircuitBreaker))
+	return b.(*circuitBreaker)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(spec.CircuitBreaker{FailureThreshold: 2, OpenDuration: time.Minute})
+
+	assert.True(t, b.allow(now))
+	b.record(now, false)
+	assert.True(t, b.allow(now))
+	b.record(now, false)
+
+	// open
+	assert.False(t, b.allow(now.Add(30*time.Second)))
+
+	// half open, only one probe is allowed
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow(now))
+	assert.False(t, b.allow(now))
+	b.record(now, false)
+	assert.False(t, b.allow(now.Add(30*time.Second)))
+
+	// probe succeeded, closed again
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow(now))
+	b.record(now, true)
+	assert.True(t, b.allow(now))
+	assert.True(t, b.allow(now))
+}
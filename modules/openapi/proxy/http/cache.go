@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+const (
+	// 超过该大小的应答不缓存
+	maxCachedBodySize = 1 << 20
+	// 清理过期缓存的间隔
+	cacheSweepInterval = time.Minute
+)
+
+type cachedResponse struct {
+	status   int
+	header   http.Header
+	body     []byte
+	expireAt time.Time
+}
+
+// responseCache 缓存 GET 请求的后端应答
+type responseCache struct {
+	sync.Mutex
+	items     map[string]*cachedResponse
+	lastSweep time.Time
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{items: make(map[string]*cachedResponse)}
+}
+
+func (c *responseCache) get(key string, now time.Time) (*cachedResponse, bool) {
+	c.Lock()
+	defer c.Unlock()
+	item, ok := c.items[key]
+	if !ok || !now.Before(item.expireAt) {
+		return nil, false
+	}
+	return item, true
+}
+
+func (c *responseCache) set(key string, item *cachedResponse, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	if now.Sub(c.lastSweep) >= cacheSweepInterval {
+		for k, v := range c.items {
+			if !now.Before(v.expireAt) {
+				delete(c.items, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.items[key] = item
+}
+
+// cacheable 判断请求是否可以使用缓存，请求头 Cache-Control: no-cache 时跳过缓存
+func cacheable(s *spec.Spec, req *http.Request) bool {
+	if s.CacheTTL <= 0 || s.ChunkAPI || req.Method != http.MethodGet {
+		return false
+	}
+	return !strings.Contains(strings.ToLower(req.Header.Get("Cache-Control")), "no-cache")
+}
+
+// cacheKey 由 path、query 及鉴权后的调用方身份组成，避免不同调用方之间共享缓存；
+// token 调用时 token 携带的 metadata 会注入请求头，因此同一客户端的不同 token 也不共享缓存
+func cacheKey(req *http.Request) string {
+	return req.URL.Path + "?" + req.URL.Query().Encode() +
+		" user:" + req.Header.Get("User-ID") + " org:" + req.Header.Get("Org-ID") +
+		" client:" + req.Header.Get("Client-ID") + " internal:" + req.Header.Get("Internal-Client") +
+		" token:" + hashCacheKeyPart(req.Header.Get("Authorization"))
+}
+
+// hashCacheKeyPart 避免在缓存 key 中保存 token 明文
+func hashCacheKeyPart(v string) string {
+	if v == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeCachedResponse(rw http.ResponseWriter, item *cachedResponse) {
+	for k, v := range item.header {
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(item.status)
+	rw.Write(item.body)
+}
+
+// cachingResponseWriter 在写应答的同时记录应答内容
+type cachingResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *cachingResponseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cachingResponseWriter) Write(b []byte) (int, error) {
+	if !w.overflow {
+		if w.body.Len()+len(b) > maxCachedBodySize {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cachingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// response 返回可缓存的应答，只缓存 200 且大小未超限的应答
+func (w *cachingResponseWriter) response(ttl time.Duration, now time.Time) (*cachedResponse, bool) {
+	if w.status != http.StatusOK || w.overflow {
+		return nil, false
+	}
+	return &cachedResponse{
+		status:   w.status,
+		header:   w.Header().Clone(),
+		body:     append([]byte(nil), w.body.Bytes()...),
+		expireAt: now.Add(ttl),
+	}, true
+}
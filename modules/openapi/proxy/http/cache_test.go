@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+func TestCacheable(t *testing.T) {
+	s := &spec.Spec{Method: http.MethodGet, CacheTTL: time.Minute}
+	req := httptest.NewRequest(http.MethodGet, "/api/issues/actions/man-hour?b=2&a=1", nil)
+	assert.True(t, cacheable(s, req))
+
+	req.Header.Set("Cache-Control", "no-cache")
+	assert.False(t, cacheable(s, req))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/issues/actions/man-hour", nil)
+	assert.False(t, cacheable(s, req))
+
+	s.CacheTTL = 0
+	req = httptest.NewRequest(http.MethodGet, "/api/issues/actions/man-hour", nil)
+	assert.False(t, cacheable(s, req))
+}
+
+func TestCacheKey(t *testing.T) {
+	req1 := httptest.NewRequest(http.MethodGet, "/api/a?b=2&a=1", nil)
+	req1.Header.Set("User-ID", "1")
+	req1.Header.Set("Org-ID", "2")
+	req2 := httptest.NewRequest(http.MethodGet, "/api/a?a=1&b=2", nil)
+	req2.Header.Set("User-ID", "1")
+	req2.Header.Set("Org-ID", "2")
+	assert.Equal(t, cacheKey(req1), cacheKey(req2))
+
+	req2.Header.Set("User-ID", "3")
+	assert.NotEqual(t, cacheKey(req1), cacheKey(req2))
+
+	// token 调用没有用户信息, 按客户端及 token 区分
+	req1 = httptest.NewRequest(http.MethodGet, "/api/a", nil)
+	req1.Header.Set("Client-ID", "c1")
+	req1.Header.Set("Authorization", "Bearer t1")
+	req2 = httptest.NewRequest(http.MethodGet, "/api/a", nil)
+	req2.Header.Set("Client-ID", "c2")
+	req2.Header.Set("Authorization", "Bearer t1")
+	assert.NotEqual(t, cacheKey(req1), cacheKey(req2))
+	req2.Header.Set("Client-ID", "c1")
+	assert.Equal(t, cacheKey(req1), cacheKey(req2))
+	req2.Header.Set("Authorization", "Bearer t2")
+	assert.NotEqual(t, cacheKey(req1), cacheKey(req2))
+	assert.NotContains(t, cacheKey(req2), "t2")
+}
+
+func TestResponseCache(t *testing.T) {
+	c := newResponseCache()
+	now := time.Now()
+
+	rec := httptest.NewRecorder()
+	w := &cachingResponseWriter{ResponseWriter: rec, status: http.StatusOK}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"success":true}`))
+	item, ok := w.response(time.Minute, now)
+	assert.True(t, ok)
+	c.set("a", item, now)
+
+	item, ok = c.get("a", now.Add(30*time.Second))
+	assert.True(t, ok)
+	rec = httptest.NewRecorder()
+	writeCachedResponse(rec, item)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"success":true}`, rec.Body.String())
+
+	_, ok = c.get("a", now.Add(time.Minute))
+	assert.False(t, ok)
+
+	// error responses are not cached
+	w = &cachingResponseWriter{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	w.WriteHeader(http.StatusInternalServerError)
+	_, ok = w.response(time.Minute, now)
+	assert.False(t, ok)
+}
@@ -15,13 +15,18 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httputil"
 	"runtime/debug"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/modules/openapi/api"
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+	"github.com/erda-project/erda/modules/openapi/conf"
 )
 
 func NewReverseProxy(director func(*http.Request),
@@ -30,17 +35,30 @@ func NewReverseProxy(director func(*http.Request),
 		FlushInterval:  -1,
 		Director:       director,
 		ModifyResponse: modifyResponse,
+		ErrorHandler:   errorHandler,
 	}
 }
 
+// errorHandler 后端超时返回 504，其他错误返回 502
+func errorHandler(rw http.ResponseWriter, req *http.Request, err error) {
+	logrus.Errorf("openapi http proxy error, url: %v, err: %v", req.URL, err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		rw.WriteHeader(http.StatusGatewayTimeout)
+		return
+	}
+	rw.WriteHeader(http.StatusBadGateway)
+}
+
 type ReverseProxyWithCustom struct {
 	reverseProxy http.Handler
+	breakers     circuitBreakers
+	cache        *responseCache
 }
 
 func NewReverseProxyWithCustom(director func(*http.Request),
 	modifyResponse func(*http.Response) error) http.Handler {
 	r := NewReverseProxy(director, modifyResponse)
-	return &ReverseProxyWithCustom{reverseProxy: r}
+	return &ReverseProxyWithCustom{reverseProxy: r, cache: newResponseCache()}
 }
 
 func (r *ReverseProxyWithCustom) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -55,5 +73,83 @@ func (r *ReverseProxyWithCustom) ServeHTTP(rw http.ResponseWriter, req *http.Req
 		spec.Custom(rw, req)
 		return
 	}
-	r.reverseProxy.ServeHTTP(rw, req)
+	if spec == nil {
+		r.reverseProxy.ServeHTTP(rw, req)
+		return
+	}
+	details, err := validateRequestBody(spec, req)
+	if err != nil {
+		logrus.Errorf("failed to read request body: %v", err)
+		http.Error(rw, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if len(details) > 0 {
+		writeValidationError(rw, details)
+		return
+	}
+	if !cacheable(spec, req) {
+		r.forward(rw, req, spec)
+		return
+	}
+	key := cacheKey(req)
+	if item, ok := r.cache.get(key, time.Now()); ok {
+		writeCachedResponse(rw, item)
+		return
+	}
+	crw := &cachingResponseWriter{ResponseWriter: rw, status: http.StatusOK}
+	r.forward(crw, req, spec)
+	now := time.Now()
+	if item, ok := crw.response(spec.CacheTTL, now); ok {
+		r.cache.set(key, item, now)
+	}
+}
+
+// forward 转发请求到后端，按 API 配置处理超时和熔断
+func (r *ReverseProxyWithCustom) forward(rw http.ResponseWriter, req *http.Request, spec *spec.Spec) {
+	if timeout := proxyTimeout(spec); timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	if spec.CircuitBreaker == nil {
+		r.reverseProxy.ServeHTTP(rw, req)
+		return
+	}
+	breaker := r.breakers.get(spec)
+	if !breaker.allow(time.Now()) {
+		logrus.Warnf("circuit breaker is open, reject request: %v", req.URL)
+		http.Error(rw, "circuit breaker is open: "+spec.Path.String(), http.StatusServiceUnavailable)
+		return
+	}
+	srw := &statusResponseWriter{ResponseWriter: rw, status: http.StatusOK}
+	r.reverseProxy.ServeHTTP(srw, req)
+	breaker.record(time.Now(), srw.status < http.StatusInternalServerError)
+}
+
+// proxyTimeout 返回转发的超时时间，未指定时使用全局默认值，ChunkAPI 一般为长时间的流式传输，不使用全局默认值
+func proxyTimeout(s *spec.Spec) time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	if s.ChunkAPI {
+		return 0
+	}
+	return conf.ProxyTimeout()
+}
+
+// statusResponseWriter 记录后端应答的状态码，用于熔断统计
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+// validateRequestBody 使用 API 上配置的 schema 校验请求体，校验通过时返回 nil
+func validateRequestBody(s *spec.Spec, req *http.Request) ([]apistructs.ErrorDetail, error) {
+	if s.RequestSchema == nil || req.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []apistructs.ErrorDetail{{Reason: "invalid json body: " + err.Error()}}, nil
+	}
+	err = s.RequestSchema.VisitJSON(value, openapi3.MultiErrors(), openapi3.VisitAsRequest())
+	if err == nil {
+		return nil, nil
+	}
+	return schemaErrorDetails(err), nil
+}
+
+// schemaErrorDetails 将 schema 校验错误展开为字段级的错误详情
+func schemaErrorDetails(err error) []apistructs.ErrorDetail {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var details []apistructs.ErrorDetail
+		for _, e := range multi {
+			details = append(details, schemaErrorDetails(e)...)
+		}
+		return details
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []apistructs.ErrorDetail{{
+			Field:  strings.Join(schemaErr.JSONPointer(), "."),
+			Reason: schemaErr.Reason,
+		}}
+	}
+	return []apistructs.ErrorDetail{{Reason: err.Error()}}
+}
+
+func writeValidationError(rw http.ResponseWriter, details []apistructs.ErrorDetail) {
+	resp := apistructs.Header{
+		Success: false,
+		Error: apistructs.ErrorResponse{
+			Code:    "InvalidParameter",
+			Msg:     "invalid request body",
+			Details: details,
+		},
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+func TestValidateRequestBody(t *testing.T) {
+	schema := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema().WithMinLength(1)).
+		WithProperty("replicas", openapi3.NewIntegerSchema().WithMin(1))
+	schema.Required = []string{"name", "replicas"}
+	s := &spec.Spec{RequestSchema: schema}
+
+	body := `{"name":"pod","replicas":1}`
+	req := httptest.NewRequest(http.MethodPost, "/api/a", strings.NewReader(body))
+	details, err := validateRequestBody(s, req)
+	assert.NoError(t, err)
+	assert.Empty(t, details)
+	// body can still be read by the proxy
+	b, _ := ioutil.ReadAll(req.Body)
+	assert.Equal(t, body, string(b))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/a", strings.NewReader(`{"name":"","replicas":0}`))
+	details, err = validateRequestBody(s, req)
+	assert.NoError(t, err)
+	var fields []string
+	for _, d := range details {
+		fields = append(fields, d.Field)
+	}
+	assert.ElementsMatch(t, []string{"name", "replicas"}, fields)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/a", strings.NewReader(`{"name":`))
+	details, err = validateRequestBody(s, req)
+	assert.NoError(t, err)
+	assert.Len(t, details, 1)
+
+	// no schema, no validation
+	req = httptest.NewRequest(http.MethodPost, "/api/a", strings.NewReader(`{"name":`))
+	details, err = validateRequestBody(&spec.Spec{}, req)
+	assert.NoError(t, err)
+	assert.Empty(t, details)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit 按 API 限制 openapi 转发的请求频率
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+// 清理过期计数窗口的间隔
+const sweepInterval = time.Minute
+
+type window struct {
+	start time.Time
+	end   time.Time
+	count int
+}
+
+// Limiter 固定窗口限流器，计数按 API 及限流维度区分
+type Limiter struct {
+	sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+// New 创建 Limiter
+func New() *Limiter {
+	return &Limiter{windows: make(map[string]*window)}
+}
+
+// Allow 判断请求是否在限额内，超出限额时返回需要等待的时间
+func (l *Limiter) Allow(s *spec.Spec, req *http.Request) (bool, time.Duration) {
+	if s.RateLimit == nil || s.RateLimit.Requests <= 0 || s.RateLimit.Interval <= 0 {
+		return true, 0
+	}
+	return l.allow(key(s, req), s.RateLimit, time.Now())
+}
+
+func (l *Limiter) allow(key string, limit *spec.RateLimit, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, w := range l.windows {
+			if !now.Before(w.end) {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.end) {
+		w = &window{start: now, end: now.Add(limit.Interval)}
+		l.windows[key] = w
+	}
+	if w.count >= limit.Requests {
+		return false, w.end.Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// key 返回计数的 key，按用户或企业限流时需要在鉴权之后调用，以便从 header 中取到 User-ID 或 Org-ID
+func key(s *spec.Spec, req *http.Request) string {
+	k := s.Method + " " + s.Path.String()
+	switch s.RateLimit.Dimension {
+	case spec.RateLimitByUser:
+		k += " user:" + req.Header.Get("User-ID")
+	case spec.RateLimitByOrg:
+		k += " org:" + req.Header.Get("Org-ID")
+	}
+	return k
+}
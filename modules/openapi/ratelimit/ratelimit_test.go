@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/openapi/api/spec"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	l := New()
+	limit := &spec.RateLimit{Requests: 2, Interval: time.Minute}
+	now := time.Now()
+
+	ok, _ := l.allow("a", limit, now)
+	assert.True(t, ok)
+	ok, _ = l.allow("a", limit, now.Add(time.Second))
+	assert.True(t, ok)
+	ok, wait := l.allow("a", limit, now.Add(20*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, wait)
+
+	// other keys are counted separately
+	ok, _ = l.allow("b", limit, now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	// next window
+	ok, _ = l.allow("a", limit, now.Add(time.Minute))
+	assert.True(t, ok)
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	l := New()
+	s := &spec.Spec{Path: spec.NewPath("/api/a"), Method: "GET"}
+	req, _ := http.NewRequest("GET", "http://127.0.0.1/api/a", nil)
+	for i := 0; i < 10; i++ {
+		ok, _ := l.Allow(s, req)
+		assert.True(t, ok)
+	}
+}
+
+func TestKey(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://127.0.0.1/api/a", nil)
+	req.Header.Set("User-ID", "1")
+	req.Header.Set("Org-ID", "2")
+	s := &spec.Spec{Path: spec.NewPath("/api/a"), Method: "POST", RateLimit: &spec.RateLimit{}}
+	assert.Equal(t, "POST /api/a", key(s, req))
+	s.RateLimit.Dimension = spec.RateLimitByUser
+	assert.Equal(t, "POST /api/a user:1", key(s, req))
+	s.RateLimit.Dimension = spec.RateLimitByOrg
+	assert.Equal(t, "POST /api/a org:2", key(s, req))
+}
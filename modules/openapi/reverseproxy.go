@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net"
 	"net/http"
 	"strconv"
@@ -40,6 +41,7 @@ import (
 	"github.com/erda-project/erda/modules/openapi/proxy"
 	phttp "github.com/erda-project/erda/modules/openapi/proxy/http"
 	"github.com/erda-project/erda/modules/openapi/proxy/ws"
+	"github.com/erda-project/erda/modules/openapi/ratelimit"
 )
 
 type ReverseProxyWithAuth struct {
@@ -48,13 +50,14 @@ type ReverseProxyWithAuth struct {
 	auth      *auth.Auth
 	bundle    *bundle.Bundle
 	cache     *sync.Map
+	limiter   *ratelimit.Limiter
 }
 
 func NewReverseProxyWithAuth(auth *auth.Auth, bundle *bundle.Bundle) (http.Handler, error) {
 	director := proxy.NewDirector()
 	httpProxy := phttp.NewReverseProxyWithCustom(director, modifyResponse)
 	wsProxy := ws.NewReverseProxyWithCustom(director)
-	return &ReverseProxyWithAuth{httpProxy: httpProxy, wsProxy: wsProxy, auth: auth, bundle: bundle, cache: &sync.Map{}}, nil
+	return &ReverseProxyWithAuth{httpProxy: httpProxy, wsProxy: wsProxy, auth: auth, bundle: bundle, cache: &sync.Map{}, limiter: ratelimit.New()}, nil
 }
 
 func (r *ReverseProxyWithAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -73,6 +76,16 @@ func (r *ReverseProxyWithAuth) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 		http.Error(rw, errStr, authr.Code)
 		return
 	}
+	if ok, wait := r.limiter.Allow(spec, req); !ok {
+		errStr := fmt.Sprintf("rate limit exceeded: %v", spec.Path)
+		logrus.Warn(errStr)
+		rw.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+		http.Error(rw, errStr, http.StatusTooManyRequests)
+		return
+	}
+	if spec.Deprecated {
+		markDeprecated(rw, req, spec)
+	}
 	switch spec.Scheme {
 	case apispec.HTTP:
 		monitor.Notify(monitor.Info{
@@ -115,6 +128,23 @@ func (r *ReverseProxyWithAuth) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 	}
 }
 
+// markDeprecated 为已废弃的 API 设置 Deprecation 及 Sunset header，并记录调用方便统计剩余的调用方
+func markDeprecated(rw http.ResponseWriter, req *http.Request, spec *apispec.Spec) {
+	rw.Header().Set("Deprecation", "true")
+	sunset, err := spec.SunsetHeader()
+	if err != nil {
+		logrus.Errorf("invalid sunset date of %s: %v", spec.Path, err)
+	} else if sunset != "" {
+		rw.Header().Set("Sunset", sunset)
+	}
+	logrus.Warnf("deprecated api invoked: %s %s, userID: %s, orgID: %s, clientIP: %s, userAgent: %s",
+		req.Method, spec.Path, req.Header.Get("User-ID"), req.Header.Get("Org-ID"), GetRealIP(req), req.Header.Get("User-Agent"))
+	monitor.Notify(monitor.Info{
+		Tp:     monitor.APIDeprecatedCount,
+		Detail: spec.Path.String(),
+	})
+}
+
 func modifyResponse(res *http.Response) error {
 	spec := api.API.FindOriginPath(res.Request)
 	if spec == nil {
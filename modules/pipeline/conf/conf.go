@@ -43,6 +43,14 @@ type Conf struct {
 	// build cache
 	BuildCacheCleanJobCron string        `env:"BUILD_CACHE_CLEAN_JOB_CRON" default:"0 0 0 * * ?"`
 	BuildCacheExpireIn     time.Duration `env:"BUILD_CACHE_EXPIRE_IN" default:"168h"`
+	// BuildCacheExpireInPerClusterStr overrides expire duration for some clusters, e.g. terminus-dev:72h,terminus-test:24h
+	BuildCacheExpireInPerClusterStr string `env:"BUILD_CACHE_EXPIRE_IN_PER_CLUSTER"`
+	BuildCacheExpireInPerCluster    map[string]time.Duration
+	// BuildCacheMaxSizePerClusterStr limits total cache size of clusters, e.g. terminus-dev:100Gi, no limit if not set
+	BuildCacheMaxSizePerClusterStr string `env:"BUILD_CACHE_MAX_SIZE_PER_CLUSTER"`
+	BuildCacheMaxSizePerCluster    map[string]int64
+	// BuildCacheEvictionWebhook is notified when caches are evicted because of the size limit
+	BuildCacheEvictionWebhook string `env:"BUILD_CACHE_EVICTION_WEBHOOK"`
 
 	// bundle
 	GittarAddr         string `env:"GITTAR_ADDR" required:"false"`
@@ -121,6 +129,12 @@ func Load() {
 
 	// actionTypeMapping
 	checkActionTypeMapping(&cfg)
+
+	// build cache expire duration of clusters
+	checkBuildCacheExpireInPerCluster(&cfg)
+
+	// build cache max size of clusters
+	checkBuildCacheMaxSizePerCluster(&cfg)
 }
 
 // ListenAddr 返回 pipeline 服务监听地址.
@@ -191,6 +205,36 @@ func BuildCacheExpireIn() time.Duration {
 	return cfg.BuildCacheExpireIn
 }
 
+// BuildCacheExpireInOfCluster 返回指定集群 构建缓存镜像 的失效时间，未单独配置时使用 BuildCacheExpireIn.
+func BuildCacheExpireInOfCluster(clusterName string) time.Duration {
+	if d, ok := cfg.BuildCacheExpireInPerCluster[clusterName]; ok {
+		return d
+	}
+	return cfg.BuildCacheExpireIn
+}
+
+// BuildCacheMaxSizeOfCluster 返回指定集群 构建缓存镜像 的总大小限制(字节)，未配置时返回 false.
+func BuildCacheMaxSizeOfCluster(clusterName string) (int64, bool) {
+	size, ok := cfg.BuildCacheMaxSizePerCluster[clusterName]
+	return size, ok
+}
+
+// BuildCacheEvictionWebhook 返回 构建缓存镜像 因超出大小限制被淘汰时的通知地址.
+func BuildCacheEvictionWebhook() string {
+	return cfg.BuildCacheEvictionWebhook
+}
+
+// BuildCacheMinExpireIn 返回所有集群中最短的 构建缓存镜像 失效时间.
+func BuildCacheMinExpireIn() time.Duration {
+	min := cfg.BuildCacheExpireIn
+	for _, d := range cfg.BuildCacheExpireInPerCluster {
+		if d < min {
+			min = d
+		}
+	}
+	return min
+}
+
 // GittarAddr 返回 gittar 的集群内部地址.
 func GittarAddr() string {
 	return cfg.GittarAddr
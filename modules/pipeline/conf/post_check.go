@@ -17,7 +17,10 @@
 package conf
 
 import (
+	"time"
+
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/erda-project/erda/pkg/strutil"
 )
@@ -34,3 +37,37 @@ func checkActionTypeMapping(cfg *Conf) {
 		cfg.ActionTypeMapping[vv[0]] = vv[1]
 	}
 }
+
+func checkBuildCacheExpireInPerCluster(cfg *Conf) {
+	cfg.BuildCacheExpireInPerCluster = make(map[string]time.Duration)
+	for _, v := range strutil.Split(cfg.BuildCacheExpireInPerClusterStr, ",", true) {
+		vv := strutil.Split(v, ":", true)
+		if len(vv) != 2 {
+			logrus.Errorf("[alert] invalid build cache expire in of cluster: %q", v)
+			continue
+		}
+		d, err := time.ParseDuration(vv[1])
+		if err != nil || d <= 0 {
+			logrus.Errorf("[alert] invalid build cache expire in of cluster: %q, err: %v", v, err)
+			continue
+		}
+		cfg.BuildCacheExpireInPerCluster[vv[0]] = d
+	}
+}
+
+func checkBuildCacheMaxSizePerCluster(cfg *Conf) {
+	cfg.BuildCacheMaxSizePerCluster = make(map[string]int64)
+	for _, v := range strutil.Split(cfg.BuildCacheMaxSizePerClusterStr, ",", true) {
+		vv := strutil.Split(v, ":", true)
+		if len(vv) != 2 {
+			logrus.Errorf("[alert] invalid build cache max size of cluster: %q", v)
+			continue
+		}
+		q, err := resource.ParseQuantity(vv[1])
+		if err != nil || q.Value() <= 0 {
+			logrus.Errorf("[alert] invalid build cache max size of cluster: %q, err: %v", v, err)
+			continue
+		}
+		cfg.BuildCacheMaxSizePerCluster[vv[0]] = q.Value()
+	}
+}
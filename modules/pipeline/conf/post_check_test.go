@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBuildCacheExpireInPerCluster(t *testing.T) {
+	c := Conf{
+		BuildCacheExpireIn:              168 * time.Hour,
+		BuildCacheExpireInPerClusterStr: "terminus-dev:72h, terminus-test:1d,terminus-prod",
+	}
+	checkBuildCacheExpireInPerCluster(&c)
+	assert.Equal(t, map[string]time.Duration{"terminus-dev": 72 * time.Hour}, c.BuildCacheExpireInPerCluster)
+
+	cfg = c
+	assert.Equal(t, 72*time.Hour, BuildCacheExpireInOfCluster("terminus-dev"))
+	assert.Equal(t, 168*time.Hour, BuildCacheExpireInOfCluster("terminus-test"))
+	assert.Equal(t, 72*time.Hour, BuildCacheMinExpireIn())
+}
+
+func TestCheckBuildCacheMaxSizePerCluster(t *testing.T) {
+	c := Conf{BuildCacheMaxSizePerClusterStr: "terminus-dev:1Gi,terminus-test:-1,terminus-prod:100M,invalid"}
+	checkBuildCacheMaxSizePerCluster(&c)
+	assert.Equal(t, map[string]int64{"terminus-dev": 1 << 30, "terminus-prod": 100 * 1000 * 1000}, c.BuildCacheMaxSizePerCluster)
+
+	cfg = c
+	size, ok := BuildCacheMaxSizeOfCluster("terminus-dev")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1<<30), size)
+	_, ok = BuildCacheMaxSizeOfCluster("terminus-test")
+	assert.False(t, ok)
+}
@@ -15,8 +15,11 @@
 package dbclient
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/spec"
 )
 
@@ -43,3 +46,154 @@ func (client *Client) DeleteBuildCache(id interface{}) (err error) {
 	_, err = client.ID(id).Delete(&spec.CIV3BuildCache{})
 	return err
 }
+
+// ExistBuildCache returns whether the cache exists.
+func (client *Client) ExistBuildCache(clusterName, imageName string) (bool, error) {
+	exist, err := client.Where("cluster_name = ? AND name = ?", clusterName, imageName).Exist(&spec.CIV3BuildCache{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check build cache exist, clusterName [%s], imageName [%s]", clusterName, imageName)
+	}
+	return exist, nil
+}
+
+// UpsertBuildCache inserts the cache, or updates lastPushAt and size if it already exists.
+// Deleted cache with the same key will be restored, the unique key (cluster_name, name) guarantees no duplicates.
+func (client *Client) UpsertBuildCache(cache *spec.CIV3BuildCache) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to upsert build cache, clusterName [%s], imageName [%s]", cache.ClusterName, cache.Name)
+	}()
+
+	now := time.Now()
+	_, err = client.Exec("INSERT INTO `ci_v3_build_caches` "+
+		"(`name`, `cluster_name`, `size`, `last_push_at`, `created_at`, `updated_at`) VALUES (?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `last_push_at` = VALUES(`last_push_at`), "+
+		"`size` = IF(VALUES(`size`) > 0, VALUES(`size`), `size`), "+
+		"`updated_at` = VALUES(`updated_at`), `deleted_at` = NULL",
+		cache.Name, cache.ClusterName, cache.Size, cache.LastPushAt, now, now)
+	return err
+}
+
+// UpdateBuildCacheLastPullAt updates last pull time of the cache, nothing happens if it doesn't exist.
+func (client *Client) UpdateBuildCacheLastPullAt(clusterName, imageName string, lastPullAt time.Time) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to update build cache last pull time, clusterName [%s], imageName [%s]", clusterName, imageName)
+	}()
+
+	_, err = client.Where("cluster_name = ? AND name = ?", clusterName, imageName).
+		Cols("last_pull_at").
+		Update(&spec.CIV3BuildCache{LastPullAt: lastPullAt})
+	return err
+}
+
+// DeleteExpiredBuildCache only deletes the cache when it is still expired, both pull and push time are considered,
+// so caches reported concurrently after being selected by gc won't be deleted.
+func (client *Client) DeleteExpiredBuildCache(id int64, expiredBefore time.Time) (deleted bool, err error) {
+	defer func() { err = errors.Wrapf(err, "failed to delete expired build cache, id [%d]", id) }()
+
+	affected, err := client.Where("id = ?", id).
+		And("COALESCE(last_pull_at, created_at) < ?", expiredBefore).
+		And("(last_push_at IS NULL OR last_push_at < ?)", expiredBefore).
+		And("updated_at < ?", expiredBefore).
+		Delete(&spec.CIV3BuildCache{})
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// DeleteBuildCacheByName return affected rows, 0 means the cache doesn't exist.
+func (client *Client) DeleteBuildCacheByName(clusterName, imageName string) (affected int64, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to delete build cache, clusterName [%s], imageName [%s]", clusterName, imageName)
+	}()
+
+	return client.Where("cluster_name = ? AND name = ?", clusterName, imageName).Delete(&spec.CIV3BuildCache{})
+}
+
+func (client *Client) PagingBuildCaches(req apistructs.BuildCachePagingRequest) ([]spec.CIV3BuildCache, int64, error) {
+	if req.PageNo <= 0 {
+		req.PageNo = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	sql := client.Desc("id")
+	if req.ClusterName != "" {
+		sql.Where("cluster_name = ?", req.ClusterName)
+	}
+	if req.NamePrefix != "" {
+		sql.Where("name LIKE ?", req.NamePrefix+"%")
+	}
+
+	var caches []spec.CIV3BuildCache
+	total, err := sql.Limit(req.PageSize, (req.PageNo-1)*req.PageSize).FindAndCount(&caches)
+	if err != nil {
+		return nil, -1, errors.Wrapf(err, "failed to paging build caches, clusterName [%s], namePrefix [%s]", req.ClusterName, req.NamePrefix)
+	}
+	return caches, total, nil
+}
+
+// RecordBuildCacheResult atomically increases hit or miss counters of the cache and its daily statistics.
+func (client *Client) RecordBuildCacheResult(clusterName, imageName string, hit bool, at time.Time) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to record build cache result, clusterName [%s], imageName [%s]", clusterName, imageName)
+	}()
+
+	column, hitCount, missCount := "miss_count", 0, 1
+	if hit {
+		column, hitCount, missCount = "hit_count", 1, 0
+	}
+
+	if _, err = client.Exec("UPDATE `ci_v3_build_caches` SET `"+column+"` = `"+column+"` + 1 "+
+		"WHERE `cluster_name` = ? AND `name` = ? AND `deleted_at` IS NULL", clusterName, imageName); err != nil {
+		return err
+	}
+	_, err = client.Exec("INSERT INTO `ci_v3_build_cache_stats` "+
+		"(`name`, `cluster_name`, `stat_date`, `hit_count`, `miss_count`, `created_at`, `updated_at`) VALUES (?,?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `hit_count` = `hit_count` + VALUES(`hit_count`), "+
+		"`miss_count` = `miss_count` + VALUES(`miss_count`), `updated_at` = VALUES(`updated_at`)",
+		imageName, clusterName, at.Format(buildCacheStatDateLayout), hitCount, missCount, at, at)
+	return err
+}
+
+const buildCacheStatDateLayout = "2006-01-02"
+
+// ListBuildCacheStats returns hit/miss counters summed by cluster and cache name, date range is closed.
+func (client *Client) ListBuildCacheStats(clusterName, imageName, startDate, endDate string) ([]spec.CIV3BuildCacheStat, error) {
+	sql := client.Table(&spec.CIV3BuildCacheStat{}).
+		Select("`cluster_name`, `name`, SUM(`hit_count`) AS `hit_count`, SUM(`miss_count`) AS `miss_count`").
+		Where("`stat_date` >= ? AND `stat_date` <= ?", startDate, endDate)
+	if clusterName != "" {
+		sql.And("`cluster_name` = ?", clusterName)
+	}
+	if imageName != "" {
+		sql.And("`name` = ?", imageName)
+	}
+
+	var stats []spec.CIV3BuildCacheStat
+	if err := sql.GroupBy("`cluster_name`, `name`").Find(&stats); err != nil {
+		return nil, errors.Wrapf(err, "failed to list build cache stats, clusterName [%s], imageName [%s]", clusterName, imageName)
+	}
+	return stats, nil
+}
+
+// SumBuildCacheSize returns total size of caches in the cluster.
+func (client *Client) SumBuildCacheSize(clusterName string) (int64, error) {
+	total, err := client.Where("cluster_name = ?", clusterName).SumInt(&spec.CIV3BuildCache{}, "size")
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to sum build cache size, clusterName [%s]", clusterName)
+	}
+	return total, nil
+}
+
+// ListBuildCachesOrderByLastUsed returns caches of the cluster, least recently used first.
+func (client *Client) ListBuildCachesOrderByLastUsed(clusterName string) ([]spec.CIV3BuildCache, error) {
+	var caches []spec.CIV3BuildCache
+	if err := client.Where("cluster_name = ?", clusterName).
+		OrderBy("COALESCE(last_pull_at, created_at) ASC, id ASC").
+		Find(&caches); err != nil {
+		return nil, errors.Wrapf(err, "failed to list build caches, clusterName [%s]", clusterName)
+	}
+	return caches, nil
+}
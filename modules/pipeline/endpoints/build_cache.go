@@ -19,9 +19,12 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
 	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/modules/pkg/user"
 	"github.com/erda-project/erda/pkg/http/httpserver"
 	"github.com/erda-project/erda/pkg/http/httpserver/errorresp"
 )
@@ -37,6 +40,7 @@ func (e *Endpoints) reportBuildCache(ctx context.Context, r *http.Request, vars
 	cacheImage := spec.CIV3BuildCache{
 		Name:        req.Name,
 		ClusterName: req.ClusterName,
+		Size:        req.Size,
 	}
 
 	if err := e.buildCacheSvc.Report(&req, &cacheImage); err != nil {
@@ -45,3 +49,78 @@ func (e *Endpoints) reportBuildCache(ctx context.Context, r *http.Request, vars
 
 	return httpserver.OkResp(nil)
 }
+
+func (e *Endpoints) listBuildCache(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	var req apistructs.BuildCachePagingRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrListBuildCache.InvalidParameter(err).ToResp(), nil
+	}
+
+	caches, total, err := e.buildCacheSvc.List(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	list := make([]*apistructs.BuildCacheImageDTO, 0, len(caches))
+	for i := range caches {
+		list = append(list, caches[i].Convert2DTO())
+	}
+
+	return httpserver.OkResp(apistructs.BuildCachePagingData{
+		Total: total,
+		List:  list,
+	})
+}
+
+func (e *Endpoints) deleteBuildCache(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	identityInfo, err := user.GetIdentityInfo(r)
+	if err != nil {
+		return apierrors.ErrDeleteBuildCache.NotLogin().ToResp(), nil
+	}
+
+	var req apistructs.BuildCacheImageDeleteRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrDeleteBuildCache.InvalidParameter(err).ToResp(), nil
+	}
+
+	// 内部调用或集群所在企业的管理员才能删除缓存
+	if !identityInfo.IsInternalClient() {
+		orgID, err := user.GetOrgID(r)
+		if err != nil {
+			return apierrors.ErrDeleteBuildCache.InvalidParameter(err).ToResp(), nil
+		}
+		if err := e.permissionSvc.CheckCluster(identityInfo, orgID, req.ClusterName, apistructs.DeleteAction); err != nil {
+			return errorresp.ErrResp(err)
+		}
+	}
+
+	deleted, err := e.buildCacheSvc.Delete(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	logrus.Infof("[audit] build cache deleted, userID: %s, internalClient: %s, clusterName: %s, name: %s, existed: %t",
+		identityInfo.UserID, identityInfo.InternalClient, req.ClusterName, req.Name, deleted)
+
+	return httpserver.OkResp(nil)
+}
+
+func (e *Endpoints) buildCacheStats(ctx context.Context, r *http.Request, vars map[string]string) (
+	httpserver.Responser, error) {
+
+	var req apistructs.BuildCacheStatsRequest
+	if err := e.queryStringDecoder.Decode(&req, r.URL.Query()); err != nil {
+		return apierrors.ErrBuildCacheStats.InvalidParameter(err).ToResp(), nil
+	}
+
+	data, err := e.buildCacheSvc.Stats(req)
+	if err != nil {
+		return errorresp.ErrResp(err)
+	}
+
+	return httpserver.OkResp(data)
+}
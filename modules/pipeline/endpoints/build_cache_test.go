@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/gorilla/schema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/pipeline/dbclient"
+	"github.com/erda-project/erda/modules/pipeline/services/buildcachesvc"
+	"github.com/erda-project/erda/modules/pipeline/services/permissionsvc"
+)
+
+func TestEndpoints_DeleteBuildCacheDenied(t *testing.T) {
+	bdl := bundle.New()
+	client := &dbclient.Client{}
+	defer monkey.UnpatchAll()
+
+	var deleted bool
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "DeleteBuildCacheByName",
+		func(_ *dbclient.Client, clusterName, imageName string) (int64, error) {
+			deleted = true
+			return 1, nil
+		})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "GetOrgClusterRelationsByOrg",
+		func(_ *bundle.Bundle, orgID uint64) ([]apistructs.OrgClusterRelationDTO, error) {
+			return []apistructs.OrgClusterRelationDTO{{ClusterName: "org-cluster"}}, nil
+		})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "CheckPermission",
+		func(_ *bundle.Bundle, req *apistructs.PermissionCheckRequest) (*apistructs.PermissionCheckResponseData, error) {
+			return &apistructs.PermissionCheckResponseData{Access: req.UserID == "admin"}, nil
+		})
+
+	e := New(
+		WithPermissionSvc(permissionsvc.New(bdl)),
+		WithBuildCacheSvc(buildcachesvc.New(client, bdl)),
+		WithQueryStringDecoder(schema.NewDecoder()),
+	)
+
+	tests := []struct {
+		name        string
+		userID      string
+		clusterName string
+		wantStatus  int
+	}{
+		{"not cluster admin", "member", "org-cluster", http.StatusForbidden},
+		{"cluster of other org", "admin", "other-cluster", http.StatusForbidden},
+		{"cluster admin", "admin", "org-cluster", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted = false
+			r := httptest.NewRequest(http.MethodDelete, "/api/build-caches?name=cache&clusterName="+tt.clusterName, nil)
+			r.Header.Set("USER-ID", tt.userID)
+			r.Header.Set("ORG-ID", "1")
+
+			resp, err := e.deleteBuildCache(context.Background(), r, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.GetStatus())
+			assert.Equal(t, tt.wantStatus == http.StatusOK, deleted)
+		})
+	}
+}
@@ -211,6 +211,9 @@ func (e *Endpoints) Routes() []httpserver.Endpoint {
 
 		// build cache
 		{Path: "/api/build-caches", Method: http.MethodPost, Handler: e.reportBuildCache},
+		{Path: "/api/build-caches", Method: http.MethodGet, Handler: e.listBuildCache},
+		{Path: "/api/build-caches", Method: http.MethodDelete, Handler: e.deleteBuildCache},
+		{Path: "/api/build-caches/actions/stats", Method: http.MethodGet, Handler: e.buildCacheStats},
 
 		// platform callback
 		{Path: "/api/pipelines/actions/callback", Method: http.MethodPost, Handler: e.pipelineCallback},
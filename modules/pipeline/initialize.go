@@ -17,10 +17,12 @@ package pipeline
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/gorilla/schema"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
@@ -113,7 +115,7 @@ func (p *provider) do() (*httpserver.Server, error) {
 	// init services
 	appSvc := appsvc.New(bdl)
 	buildArtifactSvc := buildartifactsvc.New(dbClient)
-	buildCacheSvc := buildcachesvc.New(dbClient)
+	buildCacheSvc := buildcachesvc.New(dbClient, bdl)
 	permissionSvc := permissionsvc.New(bdl)
 	crondSvc := crondsvc.New(dbClient, bdl, js)
 	actionAgentSvc := actionagentsvc.New(dbClient, bdl, js, etcdctl)
@@ -174,7 +176,7 @@ func (p *provider) do() (*httpserver.Server, error) {
 	)
 
 	server := httpserver.New(conf.ListenAddr())
-	//server.Router().Path("/metrics").Methods(http.MethodGet).Handler(promxp.Handler("pipeline"))
+	server.Router().Path("/metrics").Methods(http.MethodGet).Handler(promhttp.Handler())
 	server.RegisterEndpoint(ep.Routes())
 
 	// 加载 event manager
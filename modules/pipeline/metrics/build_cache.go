@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	labelClusterName = "cluster_name"
+)
+
+var buildCacheReclaimedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pipeline_build_cache_reclaimed",
+	Help: "the number of expired build cache records reclaimed by gc",
+}, []string{labelClusterName})
+
+var buildCacheEvictedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pipeline_build_cache_evicted",
+	Help: "the number of build caches evicted because of the cluster size limit",
+}, []string{labelClusterName})
+
+func init() {
+	prometheus.MustRegister(buildCacheReclaimedCounter, buildCacheEvictedCounter)
+}
+
+// BuildCacheReclaimedAdd 累加 gc 回收的构建缓存记录数
+func BuildCacheReclaimedAdd(clusterName string, value float64) {
+	buildCacheReclaimedCounter.WithLabelValues(clusterName).Add(value)
+}
+
+// BuildCacheEvictedAdd 累加因超出集群大小限制被淘汰的构建缓存数
+func BuildCacheEvictedAdd(clusterName string, value float64) {
+	buildCacheEvictedCounter.WithLabelValues(clusterName).Add(value)
+}
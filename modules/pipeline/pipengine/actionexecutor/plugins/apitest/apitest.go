@@ -17,6 +17,10 @@ package apitest
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/modules/pipeline/dbclient"
@@ -27,10 +31,16 @@ import (
 
 var Kind = types.Kind(spec.PipelineTaskExecutorKindAPITest)
 
+// cancelCheckInterval 执行中的任务检查是否已被取消的间隔，用于取消请求落在其他实例上的情况
+var cancelCheckInterval = 3 * time.Second
+
 type define struct {
 	name     types.Name
 	options  map[string]string
 	dbClient *dbclient.Client
+
+	// runningTasks 执行中的任务，key: taskID, value: context.CancelFunc
+	runningTasks sync.Map
 }
 
 func (d *define) Kind() types.Kind { return Kind }
@@ -57,10 +67,40 @@ func (d *define) Create(ctx context.Context, task *spec.PipelineTask) (interface
 }
 
 func (d *define) Start(ctx context.Context, task *spec.PipelineTask) (interface{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	d.runningTasks.Store(task.ID, cancel)
+	defer d.runningTasks.Delete(task.ID)
+
+	go d.watchCancel(ctx, task.ID, cancel)
+
 	logic.Do(ctx, task)
 	return nil, nil
 }
 
+// watchCancel 定时检查任务状态，任务被取消后中断正在执行的请求
+func (d *define) watchCancel(ctx context.Context, taskID uint64, cancel context.CancelFunc) {
+	ticker := time.NewTicker(cancelCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			latestTask, err := d.dbClient.GetPipelineTask(taskID)
+			if err != nil {
+				logrus.Warnf("failed to query task when watching cancel, taskID: %d, err: %v", taskID, err)
+				continue
+			}
+			if latestTask.Status == apistructs.PipelineStatusStopByUser {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
 func (d *define) Update(ctx context.Context, task *spec.PipelineTask) (interface{}, error) {
 	return nil, nil
 }
@@ -92,7 +132,14 @@ func (d *define) Status(ctx context.Context, task *spec.PipelineTask) (apistruct
 			if metaField.Value == logic.ResultSuccess {
 				return apistructs.PipelineStatusDesc{Status: apistructs.PipelineStatusSuccess}, nil
 			}
-			return apistructs.PipelineStatusDesc{Status: apistructs.PipelineStatusFailed}, nil
+			if metaField.Value == logic.ResultCancelled {
+				return apistructs.PipelineStatusDesc{Status: apistructs.PipelineStatusStopByUser}, nil
+			}
+			status := apistructs.PipelineStatusFailed
+			if metaField.Value == logic.ResultTimeout {
+				status = apistructs.PipelineStatusTimeout
+			}
+			return apistructs.PipelineStatusDesc{Status: status}, nil
 		}
 	}
 
@@ -105,6 +152,9 @@ func (d *define) Inspect(ctx context.Context, task *spec.PipelineTask) (apistruc
 }
 
 func (d *define) Cancel(ctx context.Context, task *spec.PipelineTask) (interface{}, error) {
+	if cancel, ok := d.runningTasks.Load(task.ID); ok {
+		cancel.(context.CancelFunc)()
+	}
 	return nil, nil
 }
 
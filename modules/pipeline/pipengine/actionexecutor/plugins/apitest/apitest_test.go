@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func TestDefine_Cancel(t *testing.T) {
+	d := &define{}
+	task := &spec.PipelineTask{ID: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.runningTasks.Store(task.ID, cancel)
+
+	_, err := d.Cancel(context.Background(), task)
+	assert.NoError(t, err)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("running task is not cancelled")
+	}
+
+	// cancel a task not running on this instance
+	_, err = d.Cancel(context.Background(), &spec.PipelineTask{ID: 2})
+	assert.NoError(t, err)
+}
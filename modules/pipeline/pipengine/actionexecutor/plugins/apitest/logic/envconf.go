@@ -28,6 +28,7 @@ type EnvConfig struct {
 	Body         apistructs.APIBody            `env:"ACTION_BODY"`
 	OutParams    []apistructs.APIOutParam      `env:"ACTION_OUT_PARAMS"`
 	Asserts      []APIAssert                   `env:"ACTION_ASSERTS"`
+	Proxy        *apistructs.APIProxyConfig    `env:"ACTION_PROXY"`
 	GlobalConfig *apistructs.AutoTestAPIConfig `env:"AUTOTEST_API_GLOBAL_CONFIG"`
 
 	MetaFile string `env:"METAFILE"`
@@ -55,6 +56,7 @@ func generateAPIInfoFromEnv(cfg EnvConfig) *apistructs.APIInfo {
 		Body:      cfg.Body,
 		OutParams: cfg.OutParams,
 		Asserts:   [][]apistructs.APIAssert{asserts}, // 目前有且只有一组断言
+		Proxy:     cfg.Proxy,
 	}
 }
 
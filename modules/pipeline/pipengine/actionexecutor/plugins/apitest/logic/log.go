@@ -17,6 +17,7 @@ package logic
 import (
 	"context"
 	"reflect"
+	"strings"
 
 	"github.com/erda-project/erda/apistructs"
 )
@@ -60,6 +61,8 @@ func printOriginalAPIInfo(ctx context.Context, api *apistructs.APIInfo) {
 	}
 	// url
 	log.Printf("url: %s", api.URL)
+	// proxy
+	printProxyConfig(ctx, api.Proxy)
 	// method
 	log.Printf("method: %s", api.Method)
 	// headers
@@ -133,6 +136,8 @@ func printGlobalAPIConfig(ctx context.Context, cfg *apistructs.APITestEnvData) {
 	}
 	// domain
 	log.Printf("domain: %s", cfg.Domain)
+	// proxy
+	printProxyConfig(ctx, cfg.Proxy)
 	// headers
 	if len(cfg.Header) > 0 {
 		log.Printf("headers:")
@@ -146,8 +151,12 @@ func printGlobalAPIConfig(ctx context.Context, cfg *apistructs.APITestEnvData) {
 	if len(cfg.Global) > 0 {
 		log.Printf("global configs:")
 		for key, item := range cfg.Global {
+			value := item.Value
+			if item.Secret {
+				value = apistructs.AutoTestConfigSecretMask
+			}
 			log.Printf("  key: %s", key)
-			log.Printf("  value: %s", item.Value)
+			log.Printf("  value: %s", value)
 			log.Printf("  type: %s", item.Type)
 			if item.Desc != "" {
 				log.Printf("  desc: %s", item.Desc)
@@ -157,6 +166,23 @@ func printGlobalAPIConfig(ctx context.Context, cfg *apistructs.APITestEnvData) {
 	}
 }
 
+func printProxyConfig(ctx context.Context, proxy *apistructs.APIProxyConfig) {
+	log := clog(ctx)
+
+	if proxy == nil || proxy.URL == "" {
+		return
+	}
+	log.Printf("proxy:")
+	log.Printf("  url: %s", proxy.URL)
+	if proxy.Username != "" {
+		log.Printf("  username: %s", proxy.Username)
+		log.Printf("  password: %s", apistructs.AutoTestConfigSecretMask)
+	}
+	if len(proxy.NoProxy) > 0 {
+		log.Printf("  noProxy: %s", strings.Join(proxy.NoProxy, ","))
+	}
+}
+
 func printRenderedHTTPReq(ctx context.Context, req *apistructs.APIRequestInfo) {
 	log := clog(ctx)
 
@@ -170,6 +196,10 @@ func printRenderedHTTPReq(ctx context.Context, req *apistructs.APIRequestInfo) {
 	log.Printf("url: %s", req.URL)
 	// method
 	log.Printf("method: %s", req.Method)
+	// proxy
+	if req.Proxy != "" {
+		log.Printf("proxy: %s", req.Proxy)
+	}
 	// headers
 	if len(req.Headers) > 0 {
 		log.Printf("headers:")
@@ -17,8 +17,11 @@ package logic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"golang.org/x/net/publicsuffix"
 
@@ -90,6 +93,13 @@ func Do(ctx context.Context, task *spec.PipelineTask) {
 	meta.OutParamsDefine = apiInfo.OutParams
 	defer writeMetaFile(ctx, task, meta)
 
+	if err := decryptProxyPassword(apiInfo.Proxy); err != nil {
+		meta.Result = ResultFailed
+		clog(ctx).Errorf("%v", err)
+		success = false
+		return
+	}
+
 	// global config
 	var apiTestEnvData *apistructs.APITestEnvData
 	caseParams := make(map[string]*apistructs.CaseParams)
@@ -97,11 +107,13 @@ func Do(ctx context.Context, task *spec.PipelineTask) {
 		apiTestEnvData = &apistructs.APITestEnvData{}
 		apiTestEnvData.Domain = cfg.GlobalConfig.Domain
 		apiTestEnvData.Header = cfg.GlobalConfig.Header
+		apiTestEnvData.Proxy = cfg.GlobalConfig.Proxy
 		apiTestEnvData.Global = make(map[string]*apistructs.APITestEnvVariable)
 		for name, item := range cfg.GlobalConfig.Global {
 			apiTestEnvData.Global[name] = &apistructs.APITestEnvVariable{
-				Value: item.Value,
-				Type:  item.Type,
+				Value:  item.Value,
+				Type:   item.Type,
+				Secret: item.Secret,
 			}
 			caseParams[name] = &apistructs.CaseParams{
 				Key:   name,
@@ -126,9 +138,22 @@ func Do(ctx context.Context, task *spec.PipelineTask) {
 	hc := http.Client{Jar: cookieJar}
 	printGlobalAPIConfig(ctx, apiTestEnvData)
 
+	// 步骤配置了超时时间时, 超时后取消请求
+	if timeoutMs := stepTimeoutMs(task); timeoutMs > 0 {
+		meta.TimeoutMs = timeoutMs
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
 	// do apiTest
-	apiTest := apitestsv2.New(apiInfo, apitestsv2.WithNetportalConfigs(getNetportalURL(ctx), conf.APITestNetportalAccessK8sNamespaceBlacklist()))
+	apiTest := apitestsv2.New(apiInfo,
+		apitestsv2.WithNetportalConfigs(getNetportalURL(ctx), conf.APITestNetportalAccessK8sNamespaceBlacklist()),
+		apitestsv2.WithContext(ctx),
+	)
+	begin := time.Now()
 	apiReq, apiResp, err := apiTest.Invoke(&hc, apiTestEnvData, caseParams)
+	meta.DurationMs = time.Since(begin).Milliseconds()
 	printRenderedHTTPReq(ctx, apiReq)
 	meta.Req = apiReq
 	meta.Resp = apiResp
@@ -136,6 +161,19 @@ func Do(ctx context.Context, task *spec.PipelineTask) {
 	if apiResp != nil {
 		printHTTPResp(ctx, apiResp)
 	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		meta.Result = ResultTimeout
+		clog(ctx).Errorf("api test timeout after %dms, err: %v", meta.TimeoutMs, err)
+		success = false
+		return
+	}
+	if err != nil && (errors.Is(err, context.Canceled) || ctx.Err() != nil) {
+		// 被取消的请求不算失败
+		meta.Result = ResultCancelled
+		clog(ctx).Warnf("api test cancelled, err: %v", err)
+		success = false
+		return
+	}
 	if err != nil {
 		meta.Result = ResultFailed
 		clog(ctx).Errorf("failed to do api test, err: %v", err)
@@ -167,3 +205,16 @@ func Do(ctx context.Context, task *spec.PipelineTask) {
 	addNewLine(ctx, 2)
 	clog(ctx).Println("API Test Success")
 }
+
+// stepTimeoutMs 从 action label 中获取步骤的超时时间, 未配置时返回 0
+func stepTimeoutMs(task *spec.PipelineTask) int64 {
+	v, ok := task.Extra.Action.Labels[apistructs.AutotestStepTimeoutMs]
+	if !ok {
+		return 0
+	}
+	timeoutMs, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || timeoutMs < 0 {
+		return 0
+	}
+	return timeoutMs
+}
@@ -30,17 +30,22 @@ import (
 )
 
 const (
-	ResultSuccess = "success"
-	ResultFailed  = "failed"
+	ResultSuccess   = "success"
+	ResultFailed    = "failed"
+	ResultCancelled = "cancelled"
+	ResultTimeout   = "timeout"
 )
 
 const (
-	MetaKeyResult           = "result"
+	MetaKeyResult           = apistructs.AutotestStepOutputResult
+	metaKeyAPIStatus        = apistructs.AutotestStepOutputStatus
 	metaKeyAPIRequest       = "api_request"
 	metaKeyAPIResponse      = "api_response"
 	metaKeyAPISetCookie     = "api_set_cookie"
 	metaKeyAPIAssertSuccess = "api_assert_success" // true; false
 	metaKeyAPIAssertDetail  = "api_assert_detail"
+	metaKeyAPITimeoutMs     = "api_timeout_ms"
+	metaKeyAPIDurationMs    = "api_duration_ms"
 )
 
 type Meta struct {
@@ -52,6 +57,8 @@ type Meta struct {
 	OutParamsDefine []apistructs.APIOutParam
 	CookieJar       cookiejar.Cookies
 	OutParamsResult map[string]interface{}
+	TimeoutMs       int64
+	DurationMs      int64
 }
 
 func NewMeta() *Meta {
@@ -77,10 +84,20 @@ func writeMetaFile(ctx context.Context, task *spec.PipelineTask, meta *Meta) {
 	kvs := &KVs{}
 
 	kvs.add(MetaKeyResult, meta.Result)
+	// 请求失败时状态码为 0, 流水线根据状态码判断是否重试
+	status := 0
+	if meta.Resp != nil {
+		status = meta.Resp.Status
+	}
+	kvs.add(metaKeyAPIStatus, strconv.Itoa(status))
 	if meta.AssertDetail != "" {
 		kvs.add(metaKeyAPIAssertSuccess, strconv.FormatBool(meta.AssertResult))
 		kvs.add(metaKeyAPIAssertDetail, meta.AssertDetail)
 	}
+	if meta.TimeoutMs > 0 {
+		kvs.add(metaKeyAPITimeoutMs, strconv.FormatInt(meta.TimeoutMs, 10))
+	}
+	kvs.add(metaKeyAPIDurationMs, strconv.FormatInt(meta.DurationMs, 10))
 	if meta.Req != nil {
 		kvs.add(metaKeyAPIRequest, jsonOneLine(ctx, meta.Req))
 	}
@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// decryptProxyPassword 解密步骤代理配置中由 kms 加密保存的密码, 密码只在执行时出现在内存中
+func decryptProxyPassword(proxy *apistructs.APIProxyConfig) error {
+	if proxy == nil || proxy.PasswordCiphertext == "" {
+		return nil
+	}
+	decrypted, err := bundle.New(bundle.WithKMS()).KMSDecrypt(apistructs.KMSDecryptRequest{
+		DecryptRequest: kmstypes.DecryptRequest{
+			KeyID:            proxy.KMSKeyID,
+			CiphertextBase64: proxy.PasswordCiphertext,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decrypt proxy password, err: %v", err)
+	}
+	password, err := base64.StdEncoding.DecodeString(decrypted.PlaintextBase64)
+	if err != nil {
+		return fmt.Errorf("failed to decode proxy password, err: %v", err)
+	}
+	proxy.Password = string(password)
+	proxy.PasswordCiphertext = ""
+	proxy.KMSKeyID = ""
+	return nil
+}
@@ -88,6 +88,9 @@ var (
 
 	ErrQueryDicehub     = err("ErrQueryDicehub", "查询 Dicehub 失败")
 	ErrReportBuildCache = err("ErrReportBuildCache", "上报构建缓存失败")
+	ErrListBuildCache   = err("ErrListBuildCache", "查询构建缓存列表失败")
+	ErrDeleteBuildCache = err("ErrDeleteBuildCache", "删除构建缓存失败")
+	ErrBuildCacheStats  = err("ErrBuildCacheStats", "查询构建缓存命中率失败")
 
 	ErrCallback = err("ErrCallback", "回调平台失败")
 
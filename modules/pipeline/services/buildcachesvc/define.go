@@ -15,9 +15,11 @@
 package buildcachesvc
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
 	"github.com/erda-project/erda/modules/pipeline/dbclient"
 	"github.com/erda-project/erda/modules/pipeline/services/apierrors"
 	"github.com/erda-project/erda/modules/pipeline/spec"
@@ -25,36 +27,143 @@ import (
 
 type BuildCacheSvc struct {
 	dbClient *dbclient.Client
+	bdl      *bundle.Bundle
 }
 
-func New(dbClient *dbclient.Client) *BuildCacheSvc {
+func New(dbClient *dbclient.Client, bdl *bundle.Bundle) *BuildCacheSvc {
 	s := BuildCacheSvc{}
 	s.dbClient = dbClient
+	s.bdl = bdl
 	return &s
 }
 
+// Report is idempotent, caches are keyed on (name, clusterName) and reported concurrently without duplicates.
 func (s *BuildCacheSvc) Report(req *apistructs.BuildCacheImageReportRequest, cache *spec.CIV3BuildCache) error {
-	success, err := s.dbClient.Get(cache)
-	if err != nil {
-		return apierrors.ErrReportBuildCache.InternalError(err)
-	}
-	if req.Action == "push" {
-		// 不存在添加,存在不处理
-		if !success {
-			if _, err = s.dbClient.Insert(cache); err != nil {
-				return apierrors.ErrReportBuildCache.InternalError(err)
-			}
-		}
+	if req.Name == "" {
+		return apierrors.ErrReportBuildCache.InvalidParameter("missing name")
+	}
+	if req.ClusterName == "" {
+		return apierrors.ErrReportBuildCache.InvalidParameter("missing clusterName")
+	}
+	if req.Result != "" && !req.Result.Valid() {
+		return apierrors.ErrReportBuildCache.InvalidParameter(fmt.Errorf("invalid result: %s", req.Result))
+	}
 
-	} else if req.Action == "pull" {
+	now := time.Now()
+	switch req.Action {
+	case "push":
+		exist, err := s.dbClient.ExistBuildCache(cache.ClusterName, cache.Name)
+		if err != nil {
+			return apierrors.ErrReportBuildCache.InternalError(err)
+		}
+		// 不存在时，超出集群缓存大小限制则先淘汰最久未使用的缓存再添加；存在则更新推送时间和大小
+		cache.LastPushAt = now
+		upsert := s.dbClient.UpsertBuildCache
+		if !exist {
+			upsert = s.upsertWithinQuota
+		}
+		if err := upsert(cache); err != nil {
+			return apierrors.ErrReportBuildCache.InternalError(err)
+		}
+	case "pull":
 		// 存在更新时间,不存在不处理
-		if success {
-			cache.LastPullAt = time.Now()
-			if _, err = s.dbClient.ID(cache.ID).Update(cache); err != nil {
-				return apierrors.ErrReportBuildCache.InternalError(err)
-			}
+		if err := s.dbClient.UpdateBuildCacheLastPullAt(cache.ClusterName, cache.Name, now); err != nil {
+			return apierrors.ErrReportBuildCache.InternalError(err)
+		}
+	}
+
+	if req.Result != "" {
+		hit := req.Result == apistructs.BuildCacheResultHit
+		if err := s.dbClient.RecordBuildCacheResult(cache.ClusterName, cache.Name, hit, now); err != nil {
+			return apierrors.ErrReportBuildCache.InternalError(err)
 		}
 	}
 
 	return nil
 }
+
+func (s *BuildCacheSvc) List(req apistructs.BuildCachePagingRequest) ([]spec.CIV3BuildCache, int64, error) {
+	caches, total, err := s.dbClient.PagingBuildCaches(req)
+	if err != nil {
+		return nil, -1, apierrors.ErrListBuildCache.InternalError(err)
+	}
+	return caches, total, nil
+}
+
+// Delete is idempotent, deleting a cache which doesn't exist also returns success.
+func (s *BuildCacheSvc) Delete(req apistructs.BuildCacheImageDeleteRequest) (bool, error) {
+	if req.Name == "" {
+		return false, apierrors.ErrDeleteBuildCache.MissingParameter("name")
+	}
+	if req.ClusterName == "" {
+		return false, apierrors.ErrDeleteBuildCache.MissingParameter("clusterName")
+	}
+	affected, err := s.dbClient.DeleteBuildCacheByName(req.ClusterName, req.Name)
+	if err != nil {
+		return false, apierrors.ErrDeleteBuildCache.InternalError(err)
+	}
+	return affected > 0, nil
+}
+
+func (s *BuildCacheSvc) Stats(req apistructs.BuildCacheStatsRequest) (*apistructs.BuildCacheStatsData, error) {
+	const layout = "2006-01-02"
+	now := time.Now()
+	if req.EndTime == "" {
+		req.EndTime = now.Format(layout)
+	}
+	if req.StartTime == "" {
+		req.StartTime = now.AddDate(0, 0, -6).Format(layout)
+	}
+	start, err := time.Parse(layout, req.StartTime)
+	if err != nil {
+		return nil, apierrors.ErrBuildCacheStats.InvalidParameter(fmt.Errorf("invalid startTime: %v", err))
+	}
+	end, err := time.Parse(layout, req.EndTime)
+	if err != nil {
+		return nil, apierrors.ErrBuildCacheStats.InvalidParameter(fmt.Errorf("invalid endTime: %v", err))
+	}
+	if end.Before(start) {
+		return nil, apierrors.ErrBuildCacheStats.InvalidParameter("endTime is before startTime")
+	}
+
+	stats, err := s.dbClient.ListBuildCacheStats(req.ClusterName, req.Name, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, apierrors.ErrBuildCacheStats.InternalError(err)
+	}
+	return aggregateBuildCacheStats(req, stats), nil
+}
+
+func aggregateBuildCacheStats(req apistructs.BuildCacheStatsRequest, stats []spec.CIV3BuildCacheStat) *apistructs.BuildCacheStatsData {
+	data := apistructs.BuildCacheStatsData{
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Clusters:  []apistructs.BuildCacheStatsItem{},
+		Caches:    []apistructs.BuildCacheStatsItem{},
+	}
+	clusterIndex := make(map[string]int)
+	for _, stat := range stats {
+		data.Caches = append(data.Caches, newBuildCacheStatsItem(stat.ClusterName, stat.Name, stat.HitCount, stat.MissCount))
+		idx, ok := clusterIndex[stat.ClusterName]
+		if !ok {
+			idx = len(data.Clusters)
+			clusterIndex[stat.ClusterName] = idx
+			data.Clusters = append(data.Clusters, apistructs.BuildCacheStatsItem{ClusterName: stat.ClusterName})
+		}
+		cluster := data.Clusters[idx]
+		data.Clusters[idx] = newBuildCacheStatsItem(cluster.ClusterName, "", cluster.HitCount+stat.HitCount, cluster.MissCount+stat.MissCount)
+	}
+	return &data
+}
+
+func newBuildCacheStatsItem(clusterName, name string, hit, miss int64) apistructs.BuildCacheStatsItem {
+	item := apistructs.BuildCacheStatsItem{
+		ClusterName: clusterName,
+		Name:        name,
+		HitCount:    hit,
+		MissCount:   miss,
+	}
+	if hit+miss > 0 {
+		item.HitRate = float64(hit) / float64(hit+miss)
+	}
+	return item
+}
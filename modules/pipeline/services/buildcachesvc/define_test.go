@@ -0,0 +1,233 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildcachesvc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+	"github.com/erda-project/erda/bundle"
+	"github.com/erda-project/erda/modules/pipeline/dbclient"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func TestAggregateBuildCacheStats(t *testing.T) {
+	stats := []spec.CIV3BuildCacheStat{
+		{ClusterName: "dev", Name: "a", HitCount: 3, MissCount: 1},
+		{ClusterName: "dev", Name: "b", HitCount: 0, MissCount: 4},
+		{ClusterName: "test", Name: "a", HitCount: 0, MissCount: 0},
+	}
+	data := aggregateBuildCacheStats(apistructs.BuildCacheStatsRequest{StartTime: "2021-09-01", EndTime: "2021-09-07"}, stats)
+
+	assert.Equal(t, "2021-09-01", data.StartTime)
+	assert.Len(t, data.Caches, 3)
+	assert.Equal(t, 0.75, data.Caches[0].HitRate)
+	assert.Equal(t, float64(0), data.Caches[2].HitRate)
+
+	assert.Equal(t, []apistructs.BuildCacheStatsItem{
+		{ClusterName: "dev", HitCount: 3, MissCount: 5, HitRate: 0.375},
+		{ClusterName: "test"},
+	}, data.Clusters)
+}
+
+func TestPickLRUBuildCachesToEvict(t *testing.T) {
+	caches := []spec.CIV3BuildCache{
+		{ID: 1, Size: 10},
+		{ID: 2, Size: 0},
+		{ID: 3, Size: 20},
+		{ID: 4, Size: 30},
+	}
+	picked := pickLRUBuildCachesToEvict(caches, 25)
+	assert.Len(t, picked, 2)
+	assert.Equal(t, int64(1), picked[0].ID)
+	assert.Equal(t, int64(3), picked[1].ID)
+
+	assert.Len(t, pickLRUBuildCachesToEvict(caches, 0), 0)
+	assert.Len(t, pickLRUBuildCachesToEvict(caches, 100), 3)
+}
+
+func TestBuildCacheSvc_EvictIfExceedQuota(t *testing.T) {
+	client := &dbclient.Client{}
+	bdl := bundle.New()
+	s := New(client, bdl)
+
+	var deleteImagesErr error
+	var deletedImages []string
+	var deletedIDs []interface{}
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "SumBuildCacheSize", func(_ *dbclient.Client, _ string) (int64, error) {
+		return 90, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "ListBuildCachesOrderByLastUsed", func(_ *dbclient.Client, _ string) ([]spec.CIV3BuildCache, error) {
+		return []spec.CIV3BuildCache{
+			{ID: 1, Name: "a", ClusterName: "dev", Size: 10},
+			{ID: 2, Name: "b", ClusterName: "dev", Size: 10},
+			{ID: 3, Name: "c", ClusterName: "dev", Size: 10},
+		}, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(bdl), "DeleteImageManifests", func(_ *bundle.Bundle, _ string, images []string) (*apistructs.RegistryManifestsRemoveResponseData, error) {
+		if deleteImagesErr != nil {
+			return nil, deleteImagesErr
+		}
+		deletedImages = append(deletedImages, images...)
+		// b failed to be deleted from registry
+		return &apistructs.RegistryManifestsRemoveResponseData{Succeed: []string{"a"}, Failed: map[string]string{"b": "manifest unknown"}}, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "DeleteBuildCache", func(_ *dbclient.Client, id interface{}) error {
+		deletedIDs = append(deletedIDs, id)
+		return nil
+	})
+	defer monkey.UnpatchAll()
+
+	// a and b are picked to free 15, only the record of a whose image is deleted is dropped
+	assert.NoError(t, s.evictIfExceedQuota("dev", 100, 25))
+	assert.Equal(t, []string{"a", "b"}, deletedImages)
+	assert.Equal(t, []interface{}{int64(1)}, deletedIDs)
+
+	// records are kept if registry is unavailable
+	deletedImages, deletedIDs = nil, nil
+	deleteImagesErr = fmt.Errorf("connection refused")
+	assert.NoError(t, s.evictIfExceedQuota("dev", 100, 25))
+	assert.Empty(t, deletedIDs)
+
+	// nothing is evicted within quota
+	assert.NoError(t, s.evictIfExceedQuota("dev", 100, 10))
+	assert.Empty(t, deletedImages)
+	assert.Empty(t, deletedIDs)
+}
+
+func TestBuildCacheSvc_Report(t *testing.T) {
+	client := &dbclient.Client{}
+	s := New(client, nil)
+
+	var exist bool
+	var upserted []spec.CIV3BuildCache
+	var pulled, results []string
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "ExistBuildCache", func(_ *dbclient.Client, clusterName, imageName string) (bool, error) {
+		return exist, nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "UpsertBuildCache", func(_ *dbclient.Client, cache *spec.CIV3BuildCache) error {
+		upserted = append(upserted, *cache)
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "UpdateBuildCacheLastPullAt", func(_ *dbclient.Client, clusterName, imageName string, _ time.Time) error {
+		pulled = append(pulled, clusterName+"/"+imageName)
+		return nil
+	})
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "RecordBuildCacheResult", func(_ *dbclient.Client, clusterName, imageName string, hit bool, _ time.Time) error {
+		results = append(results, fmt.Sprintf("%s/%s:%v", clusterName, imageName, hit))
+		return nil
+	})
+	defer monkey.UnpatchAll()
+
+	// push always upserts with the push time, both for new and existing caches
+	for _, exist = range []bool{false, true} {
+		req := apistructs.BuildCacheImageReportRequest{Action: "push", Name: "cache", ClusterName: "dev", Size: 10}
+		assert.NoError(t, s.Report(&req, &spec.CIV3BuildCache{Name: req.Name, ClusterName: req.ClusterName, Size: req.Size}))
+	}
+	assert.Len(t, upserted, 2)
+	for _, cache := range upserted {
+		assert.Equal(t, "dev", cache.ClusterName)
+		assert.Equal(t, "cache", cache.Name)
+		assert.Equal(t, int64(10), cache.Size)
+		assert.False(t, cache.LastPushAt.IsZero())
+	}
+
+	// pull only updates the pull time and records the result
+	req := apistructs.BuildCacheImageReportRequest{Action: "pull", Name: "cache", ClusterName: "dev", Result: apistructs.BuildCacheResultHit}
+	assert.NoError(t, s.Report(&req, &spec.CIV3BuildCache{Name: req.Name, ClusterName: req.ClusterName}))
+	assert.Len(t, upserted, 2)
+	assert.Equal(t, []string{"dev/cache"}, pulled)
+	assert.Equal(t, []string{"dev/cache:true"}, results)
+
+	// db errors are returned
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "UpsertBuildCache", func(_ *dbclient.Client, cache *spec.CIV3BuildCache) error {
+		return fmt.Errorf("duplicate entry")
+	})
+	req = apistructs.BuildCacheImageReportRequest{Action: "push", Name: "cache", ClusterName: "dev"}
+	assert.Error(t, s.Report(&req, &spec.CIV3BuildCache{Name: req.Name, ClusterName: req.ClusterName}))
+}
+
+func TestBuildCacheSvc_ReportInvalidParameter(t *testing.T) {
+	s := New(&dbclient.Client{}, nil)
+	assert.Error(t, s.Report(&apistructs.BuildCacheImageReportRequest{Action: "push", ClusterName: "dev"}, &spec.CIV3BuildCache{}))
+	assert.Error(t, s.Report(&apistructs.BuildCacheImageReportRequest{Action: "push", Name: "cache"}, &spec.CIV3BuildCache{}))
+	assert.Error(t, s.Report(&apistructs.BuildCacheImageReportRequest{Action: "push", Name: "cache", ClusterName: "dev", Result: "unknown"}, &spec.CIV3BuildCache{}))
+}
+
+func TestBuildCacheSvc_List(t *testing.T) {
+	client := &dbclient.Client{}
+	s := New(client, nil)
+
+	var got apistructs.BuildCachePagingRequest
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "PagingBuildCaches", func(_ *dbclient.Client, req apistructs.BuildCachePagingRequest) ([]spec.CIV3BuildCache, int64, error) {
+		got = req
+		return []spec.CIV3BuildCache{{ID: 2, Name: "cache-b"}, {ID: 1, Name: "cache-a"}}, 12, nil
+	})
+	defer monkey.UnpatchAll()
+
+	req := apistructs.BuildCachePagingRequest{ClusterName: "dev", NamePrefix: "cache", PageNo: 2, PageSize: 10}
+	caches, total, err := s.List(req)
+	assert.NoError(t, err)
+	assert.Equal(t, req, got)
+	assert.Equal(t, int64(12), total)
+	assert.Len(t, caches, 2)
+
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "PagingBuildCaches", func(_ *dbclient.Client, req apistructs.BuildCachePagingRequest) ([]spec.CIV3BuildCache, int64, error) {
+		return nil, -1, fmt.Errorf("connection refused")
+	})
+	_, _, err = s.List(req)
+	assert.Error(t, err)
+}
+
+func TestBuildCacheSvc_Delete(t *testing.T) {
+	client := &dbclient.Client{}
+	s := New(client, nil)
+
+	var affected int64
+	var deleted []string
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "DeleteBuildCacheByName", func(_ *dbclient.Client, clusterName, imageName string) (int64, error) {
+		deleted = append(deleted, clusterName+"/"+imageName)
+		return affected, nil
+	})
+	defer monkey.UnpatchAll()
+
+	// missing parameters are rejected before touching db
+	_, err := s.Delete(apistructs.BuildCacheImageDeleteRequest{ClusterName: "dev"})
+	assert.Error(t, err)
+	_, err = s.Delete(apistructs.BuildCacheImageDeleteRequest{Name: "cache"})
+	assert.Error(t, err)
+	assert.Empty(t, deleted)
+
+	// deleting is idempotent
+	req := apistructs.BuildCacheImageDeleteRequest{Name: "cache", ClusterName: "dev"}
+	for _, affected = range []int64{1, 0} {
+		existed, err := s.Delete(req)
+		assert.NoError(t, err)
+		assert.Equal(t, affected > 0, existed)
+	}
+	assert.Equal(t, []string{"dev/cache", "dev/cache"}, deleted)
+
+	monkey.PatchInstanceMethod(reflect.TypeOf(client), "DeleteBuildCacheByName", func(_ *dbclient.Client, clusterName, imageName string) (int64, error) {
+		return 0, fmt.Errorf("connection refused")
+	})
+	_, err = s.Delete(req)
+	assert.Error(t, err)
+}
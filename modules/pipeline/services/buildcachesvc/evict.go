@@ -0,0 +1,182 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildcachesvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/metrics"
+	"github.com/erda-project/erda/modules/pipeline/spec"
+	"github.com/erda-project/erda/pkg/dlock"
+	"github.com/erda-project/erda/pkg/http/httpclient"
+)
+
+const (
+	// evictDLockPrefix serialises eviction of the same cluster among all pipeline instances
+	evictDLockPrefix = "/devops/pipeline/dlock/buildcache/evict/"
+	evictLockTimeout = 10 * time.Second
+)
+
+// BuildCacheEvictionEvent is sent to the eviction webhook.
+type BuildCacheEvictionEvent struct {
+	ClusterName string    `json:"clusterName"`
+	MaxSize     int64     `json:"maxSize"`
+	Evicted     []string  `json:"evicted"`
+	EvictedSize int64     `json:"evictedSize"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// upsertWithinQuota inserts the new cache after evicting least recently used caches to make room for it.
+// Eviction and insertion of the same cluster are serialised by a dlock, otherwise concurrent pushes
+// would see the same total size. Caches are inserted directly if the cluster has no size limit.
+func (s *BuildCacheSvc) upsertWithinQuota(cache *spec.CIV3BuildCache) error {
+	maxSize, ok := conf.BuildCacheMaxSizeOfCluster(cache.ClusterName)
+	if !ok {
+		return s.dbClient.UpsertBuildCache(cache)
+	}
+
+	lock, err := dlock.New(evictDLockPrefix+cache.ClusterName, nil, dlock.WithTTL(30))
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), evictLockTimeout)
+	defer cancel()
+	if err := lock.Lock(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			logrus.Errorf("failed to unlock build cache eviction, clusterName: %s, err: %v", cache.ClusterName, err)
+		}
+	}()
+
+	if err := s.evictIfExceedQuota(cache.ClusterName, maxSize, cache.Size); err != nil {
+		return err
+	}
+	return s.dbClient.UpsertBuildCache(cache)
+}
+
+// evictIfExceedQuota evicts least recently used caches until there is room for the new cache.
+// Images are deleted from registry before their records, same as gc, records of caches whose images
+// failed to be deleted are kept so that they can be evicted or reclaimed later.
+func (s *BuildCacheSvc) evictIfExceedQuota(clusterName string, maxSize, size int64) error {
+	total, err := s.dbClient.SumBuildCacheSize(clusterName)
+	if err != nil {
+		return err
+	}
+	if total+size <= maxSize {
+		return nil
+	}
+	caches, err := s.dbClient.ListBuildCachesOrderByLastUsed(clusterName)
+	if err != nil {
+		return err
+	}
+
+	toEvict := pickLRUBuildCachesToEvict(caches, total+size-maxSize)
+	var evicted []string
+	var evictedSize int64
+	for _, cache := range s.deleteImages(clusterName, toEvict) {
+		if err := s.dbClient.DeleteBuildCache(cache.ID); err != nil {
+			return err
+		}
+		evicted = append(evicted, cache.Name)
+		evictedSize += cache.Size
+	}
+	if len(evicted) == 0 {
+		logrus.Warnf("build cache size %d exceeds the max size %d of cluster %s", size, maxSize, clusterName)
+		return nil
+	}
+	metrics.BuildCacheEvictedAdd(clusterName, float64(len(evicted)))
+	logrus.Infof("clusterName: %s, evicted build caches: %v, evicted size: %d, max size: %d", clusterName, evicted, evictedSize, maxSize)
+
+	go s.afterEvict(BuildCacheEvictionEvent{
+		ClusterName: clusterName,
+		MaxSize:     maxSize,
+		Evicted:     evicted,
+		EvictedSize: evictedSize,
+		Timestamp:   time.Now(),
+	})
+	return nil
+}
+
+// pickLRUBuildCachesToEvict picks caches in order until the freed size reaches need,
+// caches without size are skipped because evicting them doesn't free any quota.
+func pickLRUBuildCachesToEvict(lruCaches []spec.CIV3BuildCache, need int64) []spec.CIV3BuildCache {
+	var picked []spec.CIV3BuildCache
+	var freed int64
+	for _, cache := range lruCaches {
+		if freed >= need {
+			break
+		}
+		if cache.Size <= 0 {
+			continue
+		}
+		picked = append(picked, cache)
+		freed += cache.Size
+	}
+	return picked
+}
+
+// deleteImages deletes images of caches from registry and returns the caches whose images are deleted.
+func (s *BuildCacheSvc) deleteImages(clusterName string, caches []spec.CIV3BuildCache) []spec.CIV3BuildCache {
+	if len(caches) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(caches))
+	for _, cache := range caches {
+		names = append(names, cache.Name)
+	}
+	result, err := s.bdl.DeleteImageManifests(clusterName, names)
+	if err != nil {
+		logrus.Errorf("[alert] failed to delete evicted build cache images, clusterName: %s, err: %v", clusterName, err)
+		return nil
+	}
+	for name, reason := range result.Failed {
+		logrus.Errorf("[alert] failed to delete evicted build cache image, clusterName: %s, image: %s, err: %s", clusterName, name, reason)
+	}
+	succeed := make(map[string]bool, len(result.Succeed))
+	for _, name := range result.Succeed {
+		succeed[name] = true
+	}
+	var deleted []spec.CIV3BuildCache
+	for _, cache := range caches {
+		if succeed[cache.Name] {
+			deleted = append(deleted, cache)
+		}
+	}
+	return deleted
+}
+
+// afterEvict notifies the webhook if configured.
+func (s *BuildCacheSvc) afterEvict(event BuildCacheEvictionEvent) {
+	webhook := conf.BuildCacheEvictionWebhook()
+	if webhook == "" {
+		return
+	}
+	resp, err := httpclient.New(httpclient.WithTimeout(time.Second*5, time.Second*10)).
+		Post(webhook).JSONBody(&event).Do().DiscardBody()
+	if err != nil {
+		logrus.Errorf("failed to notify build cache eviction webhook, clusterName: %s, err: %v", event.ClusterName, err)
+		return
+	}
+	if !resp.IsOK() {
+		logrus.Errorf("failed to notify build cache eviction webhook, clusterName: %s, statusCode: %d", event.ClusterName, resp.StatusCode())
+	}
+}
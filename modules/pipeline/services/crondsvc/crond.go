@@ -23,7 +23,9 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/spec"
 	"github.com/erda-project/erda/pkg/cron"
+	"github.com/erda-project/erda/pkg/parser/pipelineyml"
 	"github.com/erda-project/erda/pkg/strutil"
 )
 
@@ -58,9 +60,7 @@ func (s *CrondSvc) ListenCrond(pipelineCronFunc func(id uint64)) {
 
 				// determine whether there is a scheduled task
 				if pc.Enable != nil && *pc.Enable && pc.CronExpr != "" {
-					err = s.crond.AddFunc(pc.CronExpr, func() {
-						pipelineCronFunc(pc.ID)
-					}, makePipelineCronName(pc.ID))
+					err = s.addPipelineCron(pc, pipelineCronFunc)
 					if err != nil {
 						logrus.Errorf("crond: failed to update cron cronID: %v cronExpr: %v  error: %v", cronID, pc.CronExpr, err)
 						continue
@@ -139,13 +139,13 @@ func (s *CrondSvc) ReloadCrond(pipelineCronFunc func(uint64)) ([]string, error)
 		pc := pcs[i]
 		//todo 校验pc.CronExpr是否合法
 		if pc.Enable != nil && *pc.Enable && pc.CronExpr != "" {
-			if err = s.crond.AddFunc(pc.CronExpr, func() { pipelineCronFunc(pc.ID) }, makePipelineCronName(pc.ID)); err != nil {
-				l := fmt.Sprintf("failed to load pipeline cron item: %s, cronExpr: %v, err: %v", makePipelineCronName(pc.ID), pc.CronExpr, err)
+			if err = s.addPipelineCron(pc, pipelineCronFunc); err != nil {
+				l := fmt.Sprintf("failed to load pipeline cron item: %s, cronExpr: %v, timeZone: %v, err: %v", makePipelineCronName(pc.ID), pc.CronExpr, pc.Extra.TimeZone, err)
 				logs = append(logs, l)
 				logrus.Errorln("[alert]", l)
 				continue
 			}
-			logs = append(logs, fmt.Sprintf("loaded pipeline cron item: %s, cronExpr: %v", makePipelineCronName(pc.ID), pc.CronExpr))
+			logs = append(logs, fmt.Sprintf("loaded pipeline cron item: %s, cronExpr: %v, timeZone: %v", makePipelineCronName(pc.ID), pc.CronExpr, pc.Extra.TimeZone))
 		}
 	}
 
@@ -165,6 +165,15 @@ func (s *CrondSvc) ReloadCrond(pipelineCronFunc func(uint64)) ([]string, error)
 	return logs, nil
 }
 
+// addPipelineCron 将定时配置加入 crond，按配置的时区解析 cron 表达式，未配置时区时使用服务端时区
+func (s *CrondSvc) addPipelineCron(pc spec.PipelineCron, pipelineCronFunc func(uint64)) error {
+	location, err := pipelineyml.LoadCronLocation(pc.Extra.TimeZone)
+	if err != nil {
+		return err
+	}
+	return s.crond.AddFuncWithLocation(pc.CronExpr, location, func() { pipelineCronFunc(pc.ID) }, makePipelineCronName(pc.ID))
+}
+
 func (s *CrondSvc) CrondSnapshot() []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
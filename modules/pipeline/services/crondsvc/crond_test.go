@@ -63,7 +63,7 @@ func TestCrondSvc_ListenCrond(t *testing.T) {
 		return nil
 	})
 
-	patch2 := monkey.PatchInstanceMethod(reflect.TypeOf(cr), "AddFunc", func(cr *cron.Cron, spec string, cmd func(), names ...string) error {
+	patch2 := monkey.PatchInstanceMethod(reflect.TypeOf(cr), "AddFuncWithLocation", func(cr *cron.Cron, spec string, location *time.Location, cmd func(), names ...string) error {
 		assert.NotZero(t, names)
 		assert.Equal(t, names[0], makePipelineCronName(1), "AddFunc")
 		return nil
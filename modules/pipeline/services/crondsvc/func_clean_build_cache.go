@@ -21,6 +21,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/erda-project/erda/modules/pipeline/conf"
+	"github.com/erda-project/erda/modules/pipeline/metrics"
 	"github.com/erda-project/erda/modules/pipeline/spec"
 )
 
@@ -32,11 +33,13 @@ func (s *CrondSvc) CleanBuildCacheImages() {
 	alertErrWithCluster := func(err error, clusterName string) {
 		logrus.Errorf("[alert] failed to clean build cache images, clusterName: %s, err: %v", clusterName, err)
 	}
-	//假如时间多少天都没有变更
-	date := time.Now().Add(-conf.BuildCacheExpireIn())
+	// 使用最短的失效时间筛选，再按集群各自的失效时间过滤
+	now := time.Now()
+	date := now.Add(-conf.BuildCacheMinExpireIn())
 
 	var toDeleteCacheImages []spec.CIV3BuildCache
-	if err := s.dbClient.Where("last_pull_at is null and created_at < ?", date).Or("last_pull_at < ?", date).
+	if err := s.dbClient.Where("COALESCE(last_pull_at, created_at) < ?", date).
+		And("(last_push_at IS NULL OR last_push_at < ?)", date).
 		Find(&toDeleteCacheImages); err != nil {
 		alertErr(err)
 		return
@@ -48,20 +51,29 @@ func (s *CrondSvc) CleanBuildCacheImages() {
 
 	imageMap := make(map[string][]spec.CIV3BuildCache, 0)
 	for _, v := range toDeleteCacheImages {
-		images, ok := imageMap[v.ClusterName]
-		if ok {
-			images = append(images, v)
-			imageMap[v.ClusterName] = images
-		} else {
-			imageMap[v.ClusterName] = []spec.CIV3BuildCache{v}
+		if !isBuildCacheExpired(v, now.Add(-conf.BuildCacheExpireInOfCluster(v.ClusterName))) {
+			continue
 		}
+		imageMap[v.ClusterName] = append(imageMap[v.ClusterName], v)
 	}
 
 	for clusterName, images := range imageMap {
+		expiredBefore := now.Add(-conf.BuildCacheExpireInOfCluster(clusterName))
+
+		// 删除镜像前重新查询，跳过筛选后被重新推送或拉取的缓存
 		var imageNames []string
 		for _, v := range images {
+			latest, err := s.dbClient.GetBuildCache(clusterName, v.Name)
+			if err != nil || !isBuildCacheExpired(latest, expiredBefore) {
+				continue
+			}
 			imageNames = append(imageNames, v.Name)
 		}
+		if len(imageNames) == 0 {
+			continue
+		}
+
+		// 先删除镜像，成功后再删除记录；镜像删除失败时保留记录，下次回收时重试，避免镜像无记录而无法回收
 		result, err := s.bdl.DeleteImageManifests(clusterName, imageNames)
 		if err != nil {
 			alertErrWithCluster(err, clusterName)
@@ -70,21 +82,41 @@ func (s *CrondSvc) CleanBuildCacheImages() {
 		bytes, err := json.Marshal(result)
 		if err != nil {
 			alertErrWithCluster(err, clusterName)
-			continue
 		}
 
-		logrus.Errorf("[alert] clusterName: %s, delete build cache success: %s", clusterName, string(bytes))
-
+		// 仅删除仍然失效的记录，镜像删除期间被重新推送或拉取的缓存保留记录
+		succeed := make(map[string]bool, len(result.Succeed))
 		for _, name := range result.Succeed {
-			cache, err := s.dbClient.GetBuildCache(clusterName, name)
-			if err != nil {
-				alertErrWithCluster(err, clusterName)
+			succeed[name] = true
+		}
+		var reclaimed int
+		for _, v := range images {
+			if !succeed[v.Name] {
 				continue
 			}
-			if err = s.dbClient.DeleteBuildCache(cache.ID); err != nil {
+			deleted, err := s.dbClient.DeleteExpiredBuildCache(v.ID, expiredBefore)
+			if err != nil {
 				alertErrWithCluster(err, clusterName)
 				continue
 			}
+			if deleted {
+				reclaimed++
+			}
 		}
+		metrics.BuildCacheReclaimedAdd(clusterName, float64(reclaimed))
+
+		logrus.Infof("clusterName: %s, reclaimed build cache records: %d, delete build cache images result: %s",
+			clusterName, reclaimed, string(bytes))
+	}
+}
+
+// isBuildCacheExpired 判断缓存是否失效，创建、最近拉取及推送时间均早于 expiredBefore 才算失效
+func isBuildCacheExpired(cache spec.CIV3BuildCache, expiredBefore time.Time) bool {
+	if !cache.LastPushAt.IsZero() && !cache.LastPushAt.Before(expiredBefore) {
+		return false
+	}
+	if cache.LastPullAt.IsZero() {
+		return cache.CreatedAt.Before(expiredBefore)
 	}
+	return cache.LastPullAt.Before(expiredBefore)
 }
@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crondsvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/modules/pipeline/spec"
+)
+
+func TestIsBuildCacheExpired(t *testing.T) {
+	expiredBefore := time.Date(2021, 9, 10, 0, 0, 0, 0, time.UTC)
+	old := expiredBefore.Add(-time.Hour)
+	recent := expiredBefore.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		cache spec.CIV3BuildCache
+		want  bool
+	}{
+		{name: "never used", cache: spec.CIV3BuildCache{CreatedAt: old}, want: true},
+		{name: "created recently", cache: spec.CIV3BuildCache{CreatedAt: recent}, want: false},
+		{name: "pulled long ago", cache: spec.CIV3BuildCache{CreatedAt: old, LastPullAt: old}, want: true},
+		{name: "pulled recently", cache: spec.CIV3BuildCache{CreatedAt: old, LastPullAt: recent}, want: false},
+		{name: "pushed recently", cache: spec.CIV3BuildCache{CreatedAt: old, LastPullAt: old, LastPushAt: recent}, want: false},
+		{name: "pushed recently never pulled", cache: spec.CIV3BuildCache{CreatedAt: old, LastPushAt: recent}, want: false},
+		{name: "pushed long ago", cache: spec.CIV3BuildCache{CreatedAt: old, LastPushAt: old}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isBuildCacheExpired(tt.cache, expiredBefore))
+		})
+	}
+}
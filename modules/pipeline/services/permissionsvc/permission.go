@@ -71,6 +71,33 @@ func (s *PermissionSvc) CheckApp(identityInfo apistructs.IdentityInfo, appID uin
 	})
 }
 
+// CheckCluster 校验用户在 集群 下是否有 ${action} 权限: 集群需关联到该企业, 且用户在企业下有集群的 ${action} 权限
+func (s *PermissionSvc) CheckCluster(identityInfo apistructs.IdentityInfo, orgID uint64, clusterName, action string) error {
+	if identityInfo.IsInternalClient() {
+		return nil
+	}
+	relations, err := s.bdl.GetOrgClusterRelationsByOrg(orgID)
+	if err != nil {
+		return apierrors.ErrCheckPermission.InternalError(err)
+	}
+	var related bool
+	for _, relation := range relations {
+		if relation.ClusterName == clusterName {
+			related = true
+			break
+		}
+	}
+	if !related {
+		return apierrors.ErrCheckPermission.AccessDenied()
+	}
+	return s.Check(identityInfo, &apistructs.PermissionCheckRequest{
+		Scope:    apistructs.OrgScope,
+		ScopeID:  orgID,
+		Resource: apistructs.ClusterResource,
+		Action:   action,
+	})
+}
+
 // CheckBranch 校验用户在 应用对应分支 下是否有 ${action} 权限
 func (s *PermissionSvc) CheckBranch(identityInfo apistructs.IdentityInfo, appIDStr, branch, action string) error {
 	if identityInfo.IsInternalClient() {
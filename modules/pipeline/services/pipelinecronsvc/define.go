@@ -60,6 +60,11 @@ func (s *PipelineCronSvc) operate(cronID uint64, enable bool) (*spec.PipelineCro
 	if enable && cron.CronExpr == "" {
 		return &cron, nil
 	}
+	if enable {
+		if _, err := pipelineyml.LoadCronLocation(cron.Extra.TimeZone); err != nil {
+			return nil, apierrors.ErrStartPipelineCron.InvalidParameter(err)
+		}
+	}
 	if err = s.dbClient.UpdatePipelineCron(cron.ID, &cron); err != nil {
 		return nil, apierrors.ErrOperatePipeline.InternalError(err)
 	}
@@ -82,9 +87,10 @@ func (s *PipelineCronSvc) Create(req apistructs.PipelineCronCreateRequest) (*spe
 	if req.PipelineCreateRequest.PipelineYml == "" {
 		return nil, apierrors.ErrCreatePipelineCron.InvalidParameter(errors.Errorf("missing pipelineYml"))
 	}
+	// 解析时会校验 cron 表达式与时区
 	pipelineYml, err := pipelineyml.New([]byte(req.PipelineCreateRequest.PipelineYml))
 	if err != nil {
-		return nil, err
+		return nil, apierrors.ErrCreatePipelineCron.InvalidParameter(err)
 	}
 	if pipelineYml.Spec().Cron == "" {
 		return nil, apierrors.ErrCreatePipelineCron.InvalidParameter(errors.Errorf("not cron pipeline"))
@@ -103,6 +109,7 @@ func (s *PipelineCronSvc) Create(req apistructs.PipelineCronCreateRequest) (*spe
 			NormalLabels:  req.PipelineCreateRequest.NormalLabels,
 			Envs:          req.PipelineCreateRequest.Envs,
 			CronStartFrom: req.PipelineCreateRequest.CronStartFrom,
+			TimeZone:      pipelineYml.Spec().CronTimeZone,
 			Version:       "v2",
 		},
 	}
@@ -146,8 +153,19 @@ func (s *PipelineCronSvc) PipelineCronUpdate(req apistructs.PipelineCronUpdateRe
 	if err != nil {
 		return err
 	}
+	if _, err := pipelineyml.LoadCronLocation(req.TimeZone); err != nil {
+		return apierrors.ErrUpdatePipelineCron.InvalidParameter(err)
+	}
 	cron.CronExpr = req.CronExpr
 	cron.Extra.PipelineYml = req.PipelineYml
-	err = s.dbClient.UpdatePipelineCronWillUseDefault(cron.ID, &cron, []string{spec.PipelineCronCronExpr, spec.Extra})
-	return err
+	cron.Extra.TimeZone = req.TimeZone
+	if err := s.dbClient.UpdatePipelineCronWillUseDefault(cron.ID, &cron, []string{spec.PipelineCronCronExpr, spec.Extra}); err != nil {
+		return err
+	}
+
+	// 表达式或时区变化后重新加载定时任务
+	if err := s.crondSvc.AddIntoPipelineCrond(cron.ID); err != nil {
+		return apierrors.ErrReloadCrond.InternalError(err)
+	}
+	return nil
 }
@@ -173,7 +173,7 @@ func (s *PipelineSvc) makePipelineFromRequest(req *apistructs.PipelineCreateRequ
 		return nil, apierrors.ErrParsePipelineYml.InternalError(err)
 	}
 	p.Extra.CronExpr = pipelineYml.Spec().Cron
-	if err := s.UpdatePipelineCron(p, nil, nil, pipelineYml.Spec().CronCompensator); err != nil {
+	if err := s.UpdatePipelineCron(p, nil, nil, pipelineYml.Spec().CronCompensator, pipelineYml.Spec().CronTimeZone); err != nil {
 		return nil, apierrors.ErrCreatePipeline.InternalError(err)
 	}
 
@@ -44,7 +44,7 @@ func (s *PipelineSvc) makeNormalPipelineTask(p *spec.Pipeline, ps *spec.Pipeline
 	task.Type = action.Type.String()
 	task.Extra.Namespace = p.Extra.Namespace
 	task.Extra.ClusterName = p.ClusterName
-	task.Extra.AllowFailure = false
+	task.Extra.AllowFailure = allowFailureByAction(action)
 	task.Extra.Pause = false
 	task.Extra.Timeout = time.Duration(action.Timeout * int64(time.Second))
 	if action.Timeout < 0 {
@@ -147,6 +147,11 @@ func (s *PipelineSvc) makeSnippetPipelineTask(p *spec.Pipeline, stage *spec.Pipe
 	return &task, nil
 }
 
+// allowFailureByAction 自动化测试步骤的失败处理策略为继续执行时, 步骤失败或超时不影响流水线的状态
+func allowFailureByAction(action *pipelineyml.Action) bool {
+	return action.Labels[apistructs.AutotestStepOnError] == string(apistructs.AutoTestStepOnErrorContinue)
+}
+
 func (s *PipelineSvc) genSnippetTaskExtra(p *spec.Pipeline, action *pipelineyml.Action) (spec.PipelineTaskExtra, error) {
 	var ex spec.PipelineTaskExtra
 	ex.Namespace = p.Extra.Namespace
@@ -263,3 +263,15 @@ func TestPipelineSvc_judgeTaskExecutor(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowFailureByAction(t *testing.T) {
+	if allowFailureByAction(&pipelineyml.Action{}) {
+		t.Fatal("action without labels should not allow failure")
+	}
+	if allowFailureByAction(&pipelineyml.Action{Labels: map[string]string{apistructs.AutotestStepOnError: string(apistructs.AutoTestStepOnErrorFail)}}) {
+		t.Fatal("step failing on error should not allow failure")
+	}
+	if !allowFailureByAction(&pipelineyml.Action{Labels: map[string]string{apistructs.AutotestStepOnError: string(apistructs.AutoTestStepOnErrorContinue)}}) {
+		t.Fatal("step continuing on error should allow failure")
+	}
+}
@@ -277,7 +277,7 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 	// gc
 	p.Extra.GC = req.GC
 
-	if err := s.UpdatePipelineCron(p, req.CronStartFrom, req.ConfigManageNamespaces, pipelineYml.Spec().CronCompensator); err != nil {
+	if err := s.UpdatePipelineCron(p, req.CronStartFrom, req.ConfigManageNamespaces, pipelineYml.Spec().CronCompensator, pipelineYml.Spec().CronTimeZone); err != nil {
 		return nil, apierrors.ErrCreatePipeline.InternalError(err)
 	}
 
@@ -312,7 +312,7 @@ func (s *PipelineSvc) makePipelineFromRequestV2(req *apistructs.PipelineCreateRe
 
 // 非定时触发的，如果有定时配置，需要插入或更新 pipeline_crons enable 配置
 // 不管是定时还是非定时，只要定时配置是空的，就将pipeline_crons disable
-func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator) error {
+func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator, cronTimeZone string) error {
 
 	var cron *spec.PipelineCron
 	var cronID uint64
@@ -320,7 +320,7 @@ func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.T
 	//是定时类型的流水线，切定时的表达式不为空，更新cron的配置
 	if p.TriggerMode != apistructs.PipelineTriggerModeCron && p.Extra.CronExpr != "" {
 
-		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator)
+		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator, cronTimeZone)
 
 		if err := s.dbClient.InsertOrUpdatePipelineCron(cron); err != nil {
 			return apierrors.ErrUpdatePipelineCron.InternalError(err)
@@ -333,7 +333,7 @@ func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.T
 	if p.Extra.CronExpr == "" {
 		var err error
 
-		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator)
+		cron = constructPipelineCron(p, cronStartFrom, configManageNamespaces, cronCompensator, cronTimeZone)
 		if cronID, err = s.dbClient.DisablePipelineCron(cron); err != nil {
 			return apierrors.ErrUpdatePipelineCron.InternalError(err)
 		}
@@ -347,7 +347,7 @@ func (s *PipelineSvc) UpdatePipelineCron(p *spec.Pipeline, cronStartFrom *time.T
 	return nil
 }
 
-func constructPipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator) *spec.PipelineCron {
+func constructPipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configManageNamespaces []string, cronCompensator *pipelineyml.CronCompensator, cronTimeZone string) *spec.PipelineCron {
 	appID, _ := strconv.ParseUint(p.Labels[apistructs.LabelAppID], 10, 64)
 	var compensator *apistructs.CronCompensator
 	if cronCompensator != nil {
@@ -371,6 +371,7 @@ func constructPipelineCron(p *spec.Pipeline, cronStartFrom *time.Time, configMan
 			Envs:                   p.Snapshot.Envs,
 			ConfigManageNamespaces: configManageNamespaces,
 			CronStartFrom:          cronStartFrom,
+			TimeZone:               cronTimeZone,
 			Version:                "v2",
 			Compensator:            compensator,
 			LastCompensateAt:       nil,
@@ -184,6 +184,7 @@ func (s *PipelineSvc) cronInterruptCompensate(pc spec.PipelineCron) error {
 	needTriggerTimes, err := pipelineyml.ListNextCronTime(pc.CronExpr,
 		pipelineyml.WithCronStartEndTime(&beforeCompensateFromTime, &thisCompensateFromTime),
 		pipelineyml.WithListNextScheduleCount(100),
+		pipelineyml.WithCronTimeZone(pc.Extra.TimeZone),
 	)
 	if err != nil {
 		return errors.Errorf("[alert] failed to list next crontimes, cronID: %d, err: %v", pc.ID, err)
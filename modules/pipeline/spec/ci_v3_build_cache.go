@@ -16,6 +16,8 @@ package spec
 
 import (
 	"time"
+
+	"github.com/erda-project/erda/apistructs"
 )
 
 type CIV3BuildCache struct {
@@ -23,6 +25,10 @@ type CIV3BuildCache struct {
 	Name        string    `json:"name"`
 	ClusterName string    `json:"clusterName"`
 	LastPullAt  time.Time `json:"lastPullAt"`
+	LastPushAt  time.Time `json:"lastPushAt"`
+	Size        int64     `json:"size"`
+	HitCount    int64     `json:"hitCount"`
+	MissCount   int64     `json:"missCount"`
 	CreatedAt   time.Time `json:"createdAt" xorm:"created"`
 	UpdatedAt   time.Time `json:"updatedAt" xorm:"updated"`
 	DeletedAt   time.Time `xorm:"deleted"`
@@ -31,3 +37,41 @@ type CIV3BuildCache struct {
 func (*CIV3BuildCache) TableName() string {
 	return "ci_v3_build_caches"
 }
+
+// CIV3BuildCacheStat is the daily hit/miss statistics of a build cache.
+type CIV3BuildCacheStat struct {
+	ID          int64     `json:"id" xorm:"pk autoincr"`
+	Name        string    `json:"name"`
+	ClusterName string    `json:"clusterName"`
+	StatDate    string    `json:"statDate"`
+	HitCount    int64     `json:"hitCount"`
+	MissCount   int64     `json:"missCount"`
+	CreatedAt   time.Time `json:"createdAt" xorm:"created"`
+	UpdatedAt   time.Time `json:"updatedAt" xorm:"updated"`
+}
+
+func (*CIV3BuildCacheStat) TableName() string {
+	return "ci_v3_build_cache_stats"
+}
+
+func (c *CIV3BuildCache) Convert2DTO() *apistructs.BuildCacheImageDTO {
+	dto := apistructs.BuildCacheImageDTO{
+		ID:          c.ID,
+		Name:        c.Name,
+		ClusterName: c.ClusterName,
+		Size:        c.Size,
+		HitCount:    c.HitCount,
+		MissCount:   c.MissCount,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if !c.LastPullAt.IsZero() {
+		lastPullAt := c.LastPullAt
+		dto.LastPullAt = &lastPullAt
+	}
+	if !c.LastPushAt.IsZero() {
+		lastPushAt := c.LastPushAt
+		dto.LastPushAt = &lastPushAt
+	}
+	return &dto
+}
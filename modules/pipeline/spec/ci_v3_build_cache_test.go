@@ -0,0 +1,40 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIV3BuildCache_Convert2DTO(t *testing.T) {
+	now := time.Now()
+	cache := CIV3BuildCache{ID: 1, Name: "cache", ClusterName: "dev", Size: 10, CreatedAt: now, UpdatedAt: now}
+
+	dto := cache.Convert2DTO()
+	assert.Equal(t, int64(1), dto.ID)
+	assert.Equal(t, "cache", dto.Name)
+	assert.Equal(t, "dev", dto.ClusterName)
+	assert.Equal(t, int64(10), dto.Size)
+	assert.Nil(t, dto.LastPullAt)
+	assert.Nil(t, dto.LastPushAt)
+
+	cache.LastPullAt, cache.LastPushAt = now, now.Add(-time.Hour)
+	dto = cache.Convert2DTO()
+	assert.Equal(t, now, *dto.LastPullAt)
+	assert.Equal(t, now.Add(-time.Hour), *dto.LastPushAt)
+}
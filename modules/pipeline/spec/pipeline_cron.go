@@ -58,6 +58,8 @@ type PipelineCronExtra struct {
 	Envs                   map[string]string `json:"envs"`
 	ConfigManageNamespaces []string          `json:"configManageNamespaces,omitempty"`
 	CronStartFrom          *time.Time        `json:"cronStartFrom,omitempty"`
+	// TimeZone 解析 cron 表达式使用的 IANA 时区，为空时使用服务端时区
+	TimeZone string `json:"timeZone,omitempty"`
 	// 新版为 v2
 	Version string `json:"version"`
 
@@ -84,6 +86,8 @@ func (pc *PipelineCron) Convert2DTO() *apistructs.PipelineCronDTO {
 		Branch:          pc.Branch,
 		CronExpr:        pc.CronExpr,
 		CronStartTime:   pc.Extra.CronStartFrom,
+		PipelineSource:  pc.PipelineSource,
+		TimeZone:        pc.Extra.TimeZone,
 		PipelineYmlName: pc.PipelineYmlName,
 		BasePipelineID:  pc.BasePipelineID,
 		Enable:          pc.Enable,
@@ -202,6 +202,7 @@ func (at *APITest) Invoke(httpClient *http.Client, testEnv *apistructs.APITestEn
 	apiReq.Body.Content = reqBody
 
 	// use netportal
+	viaNetportal := useNetportal(apiReq.URL, at.opt.netportalOption)
 	customReq, err := handleCustomNetportalRequest(&apiReq, at.opt.netportalOption)
 	if err != nil {
 		return nil, nil, err
@@ -210,11 +211,27 @@ func (at *APITest) Invoke(httpClient *http.Client, testEnv *apistructs.APITestEn
 	// polish headers for compression
 	apiReq.Headers = polishHeadersForCompression(apiReq.Headers)
 
+	// proxy, netportal 转发的请求不使用代理
+	hcOptions := []httpclient.OpOption{httpclient.WithCompleteRedirect()}
+	if !viaNetportal {
+		proxyURL, err := resolveProxy(proxyConfigOf(at.API, testEnv), customReq.URL)
+		if err != nil {
+			return &apiReq, nil, err
+		}
+		if proxyURL != nil {
+			hcOptions = append(hcOptions, httpclient.WithProxy(proxyURL.String()))
+			apiReq.Proxy = redactProxyURL(proxyURL)
+		}
+	}
+
 	var buffer bytes.Buffer
-	req := httpclient.New(httpclient.WithCompleteRedirect()).
+	req := httpclient.New(hcOptions...).
 		Method(apiReq.Method, customReq.URL.Scheme+"://"+customReq.URL.Host, httpclient.NoRetry).
 		Path(customReq.URL.Path).
 		Headers(apiReq.Headers)
+	if at.opt.ctx != nil {
+		req.WithContext(at.opt.ctx)
+	}
 	httpResp, err := req.Params(apiReq.Params).
 		RawBody(bytes.NewBufferString(apiReq.Body.Content.(string))).
 		Do().Body(&buffer)
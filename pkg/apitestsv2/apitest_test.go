@@ -15,12 +15,18 @@
 package apitestsv2
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/pkg/encoding/jsonpath"
 )
 
@@ -31,3 +37,43 @@ func TestJsonPath(t *testing.T) {
 	assert.NoError(t, err)
 	spew.Dump(data)
 }
+
+func TestAPITest_Invoke_Cancel(t *testing.T) {
+	requestAborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(requestAborted)
+		case <-time.After(30 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	at := New(&apistructs.APIInfo{
+		URL:    server.URL + "/slow",
+		Method: http.MethodGet,
+	}, WithContext(ctx))
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := at.Invoke(&http.Client{}, nil, nil)
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected err: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request is not torn down after cancel")
+	}
+	select {
+	case <-requestAborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server side request is not aborted after cancel")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitestsv2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// JSONPath 断言支持的操作符
+const (
+	JsonPathOpEquals    = "equals"
+	JsonPathOpNotEquals = "not_equals"
+	JsonPathOpContains  = "contains"
+	JsonPathOpExists    = "exists"
+	JsonPathOpNotExists = "not_exists"
+	JsonPathOpGt        = "gt"
+	JsonPathOpGte       = "gte"
+	JsonPathOpLt        = "lt"
+	JsonPathOpLte       = "lte"
+	JsonPathOpRegex     = "regex"
+)
+
+// jsonPathAssertBodyKey 存在性断言需要在完整的响应体上判断路径是否存在
+const jsonPathAssertBodyKey = "jsonpath_assert_body"
+
+// 支持的 JSONPath: 以 $ 开头, 由 .key 和 key 后的 [index] 组成, 如 $.data.list[0].id
+var jsonPathSegmentRe = regexp.MustCompile(`^[^.\[\]\s]+(\[\d+\])?$`)
+
+var jsonPathOperators = map[string]string{
+	JsonPathOpEquals:    "=",
+	JsonPathOpNotEquals: "!=",
+	JsonPathOpContains:  "contains",
+	JsonPathOpExists:    "exist",
+	JsonPathOpNotExists: "not_exist",
+	JsonPathOpGt:        ">",
+	JsonPathOpGte:       ">=",
+	JsonPathOpLt:        "<",
+	JsonPathOpLte:       "<=",
+	JsonPathOpRegex:     "regex",
+}
+
+// ValidateJsonPathAssert 校验 JSONPath 断言的路径语法、操作符和期望值
+func ValidateJsonPathAssert(ast apistructs.APIJsonPathAssert) error {
+	if _, err := jsonPathToExpression(ast.Path); err != nil {
+		return err
+	}
+	if _, ok := jsonPathOperators[ast.Operator]; !ok {
+		return fmt.Errorf("invalid operator %q of jsonpath %s", ast.Operator, ast.Path)
+	}
+	if ast.Operator == JsonPathOpRegex {
+		if _, err := regexp.Compile(ast.Value); err != nil {
+			return fmt.Errorf("invalid regex %q of jsonpath %s: %v", ast.Value, ast.Path, err)
+		}
+	}
+	return nil
+}
+
+// CompileJsonPathAsserts 将 JSONPath 断言转换为出参和断言, 执行时与普通出参和断言一起计算, 每个断言单独报告结果;
+// 指定了 OutputKey 的断言, 提取的值以 OutputKey 作为出参
+func CompileJsonPathAsserts(asserts []apistructs.APIJsonPathAssert) ([]apistructs.APIOutParam, []apistructs.APIAssert, error) {
+	var (
+		outParams []apistructs.APIOutParam
+		results   []apistructs.APIAssert
+		extracted = make(map[string]bool)
+	)
+	addOutParam := func(key, expression string) {
+		if extracted[key] {
+			return
+		}
+		extracted[key] = true
+		outParams = append(outParams, apistructs.APIOutParam{
+			Key:        key,
+			Source:     apistructs.APIOutParamSourceBodyJson,
+			Expression: expression,
+		})
+	}
+	for _, ast := range asserts {
+		if err := ValidateJsonPathAssert(ast); err != nil {
+			return nil, nil, err
+		}
+		expression, _ := jsonPathToExpression(ast.Path)
+		key := ast.OutputKey
+		if key == "" {
+			key = ast.Path
+		}
+		addOutParam(key, expression)
+
+		switch ast.Operator {
+		case JsonPathOpExists, JsonPathOpNotExists:
+			addOutParam(jsonPathAssertBodyKey, "")
+			results = append(results, apistructs.APIAssert{
+				Arg:      jsonPathAssertBodyKey,
+				Operator: jsonPathOperators[ast.Operator],
+				Value:    expression,
+			})
+		case JsonPathOpContains:
+			results = append(results, apistructs.APIAssert{
+				Arg:      key,
+				Operator: jsonPathOperators[ast.Operator],
+				Value:    regexp.QuoteMeta(ast.Value),
+			})
+		default:
+			results = append(results, apistructs.APIAssert{
+				Arg:      key,
+				Operator: jsonPathOperators[ast.Operator],
+				Value:    ast.Value,
+			})
+		}
+	}
+	return outParams, results, nil
+}
+
+// jsonPathToExpression 将 JSONPath 转换为出参使用的路径表达式, 如 $.data.list[0].id 转换为 data.list[0].id
+func jsonPathToExpression(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "$" {
+		return "", nil
+	}
+	if !strings.HasPrefix(path, "$.") {
+		return "", fmt.Errorf("invalid jsonpath %q: must start with $.", path)
+	}
+	expression := strings.TrimPrefix(path, "$.")
+	for _, segment := range strings.Split(expression, ".") {
+		if !jsonPathSegmentRe.MatchString(segment) {
+			return "", fmt.Errorf("invalid jsonpath %q: unsupported segment %q", path, segment)
+		}
+	}
+	return expression, nil
+}
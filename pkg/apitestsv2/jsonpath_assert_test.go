@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitestsv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+func TestValidateJsonPathAssert(t *testing.T) {
+	assert.NoError(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "$.data.list[0].id", Operator: JsonPathOpEquals, Value: "1"}))
+	assert.NoError(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "$", Operator: JsonPathOpContains, Value: "ok"}))
+	assert.Error(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "data.id", Operator: JsonPathOpEquals}))
+	assert.Error(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "$.data..id", Operator: JsonPathOpEquals}))
+	assert.Error(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "$.data[a]", Operator: JsonPathOpEquals}))
+	assert.Error(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "$.data", Operator: "like"}))
+	assert.Error(t, ValidateJsonPathAssert(apistructs.APIJsonPathAssert{Path: "$.data", Operator: JsonPathOpRegex, Value: "(["}))
+}
+
+func TestCompileJsonPathAsserts(t *testing.T) {
+	outParams, asserts, err := CompileJsonPathAsserts([]apistructs.APIJsonPathAssert{
+		{Path: "$.data.id", Operator: JsonPathOpGt, Value: "0", OutputKey: "id"},
+		{Path: "$.data.name", Operator: JsonPathOpContains, Value: "a.b"},
+		{Path: "$.data.list[0]", Operator: JsonPathOpExists},
+		{Path: "$.data.id", Operator: JsonPathOpLt, Value: "100", OutputKey: "id"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []apistructs.APIOutParam{
+		{Key: "id", Source: apistructs.APIOutParamSourceBodyJson, Expression: "data.id"},
+		{Key: "$.data.name", Source: apistructs.APIOutParamSourceBodyJson, Expression: "data.name"},
+		{Key: "$.data.list[0]", Source: apistructs.APIOutParamSourceBodyJson, Expression: "data.list[0]"},
+		{Key: jsonPathAssertBodyKey, Source: apistructs.APIOutParamSourceBodyJson, Expression: ""},
+	}, outParams)
+	assert.Equal(t, []apistructs.APIAssert{
+		{Arg: "id", Operator: ">", Value: "0"},
+		{Arg: "$.data.name", Operator: "contains", Value: `a\.b`},
+		{Arg: jsonPathAssertBodyKey, Operator: "exist", Value: "data.list[0]"},
+		{Arg: "id", Operator: "<", Value: "100"},
+	}, asserts)
+
+	_, _, err = CompileJsonPathAsserts([]apistructs.APIJsonPathAssert{{Path: "$.", Operator: JsonPathOpEquals}})
+	assert.Error(t, err)
+}
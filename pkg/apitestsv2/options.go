@@ -14,9 +14,12 @@
 
 package apitestsv2
 
+import "context"
+
 type option struct {
 	tryV1RenderJsonBodyFirst bool
 	netportalOption          *netportalOption
+	ctx                      context.Context
 }
 
 type netportalOption struct {
@@ -43,3 +46,10 @@ func WithNetportalConfigs(netportalURL string, blacklistOfK8sNamespaceAccess []s
 		}
 	}
 }
+
+// WithContext 设置执行 API 测试时使用的 context，context 取消时正在进行的请求会被立即中断。
+func WithContext(ctx context.Context) OpOption {
+	return func(opt *option) {
+		opt.ctx = ctx
+	}
+}
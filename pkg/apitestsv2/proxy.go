@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitestsv2
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/erda-project/erda/apistructs"
+)
+
+// proxyConfigOf 返回请求使用的代理配置, 步骤上的配置优先于测试环境的配置
+func proxyConfigOf(api *apistructs.APIInfo, testEnv *apistructs.APITestEnvData) *apistructs.APIProxyConfig {
+	if api != nil && api.Proxy != nil && api.Proxy.URL != "" {
+		return api.Proxy
+	}
+	if testEnv != nil && testEnv.Proxy != nil && testEnv.Proxy.URL != "" {
+		return testEnv.Proxy
+	}
+	return nil
+}
+
+// resolveProxy 返回访问 reqURL 时使用的代理地址, 不使用代理时返回 nil; 代理认证信息设置在返回地址的 userinfo 中
+func resolveProxy(cfg *apistructs.APIProxyConfig, reqURL *url.URL) (*url.URL, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  cfg.URL,
+		HTTPSProxy: cfg.URL,
+		NoProxy:    strings.Join(cfg.NoProxy, ","),
+	}).ProxyFunc()
+	proxyURL, err := proxyFunc(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve proxy, err: %v", err)
+	}
+	if proxyURL == nil {
+		return nil, nil
+	}
+	if cfg.Username != "" {
+		proxyURL.User = url.UserPassword(cfg.Username, cfg.Password)
+	}
+	return proxyURL, nil
+}
+
+// redactProxyURL 返回不包含认证信息的代理地址, 用于记录到执行结果中
+func redactProxyURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	return redacted.String()
+}
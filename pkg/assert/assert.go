@@ -62,6 +62,14 @@ func DoAssert(value interface{}, op string, expect string) (bool, error) {
 			return false, err
 		}
 		return !isMatch, nil
+	case "regex":
+		return matchRegex(value, expect)
+	case "not_regex":
+		ret, err := matchRegex(value, expect)
+		if err != nil {
+			return false, err
+		}
+		return !ret, nil
 	case "belong":
 		return isBelong(value, expect)
 	case "not_belong":
@@ -293,6 +301,15 @@ func getIntervalValue(p, str string) (int, int, bool, error) {
 	return 0, 0, false, nil
 }
 
+// matchRegex 字符串直接匹配, 其他类型匹配其 json 格式
+func matchRegex(value interface{}, expect string) (bool, error) {
+	s, ok := value.(string)
+	if !ok {
+		s = jsonparse.JsonOneLine(value)
+	}
+	return regexp.MatchString(expect, s)
+}
+
 func isEmpty(value interface{}) (bool, error) {
 	if value == nil {
 		return true, nil
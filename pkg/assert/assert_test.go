@@ -62,6 +62,19 @@ func TestAssert(t *testing.T) {
 	ast.Equal(t, err, nil)
 	ast.Equal(t, ret, true)
 
+	// 测试 regex
+	v = "order-20210901"
+	op = "regex"
+	e = `^order-\d+$`
+	ret, err = DoAssert(v, op, e)
+	ast.Equal(t, err, nil)
+	ast.Equal(t, ret, true)
+
+	op = "not_regex"
+	ret, err = DoAssert(v, op, e)
+	ast.Equal(t, err, nil)
+	ast.Equal(t, ret, false)
+
 	// 测试 !=
 	v = "200.00"
 	op = "!="
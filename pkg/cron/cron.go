@@ -150,6 +150,16 @@ type FuncJob func()
 
 func (f FuncJob) Run() { f() }
 
+// locationSchedule computes the activation times in a fixed location
+type locationSchedule struct {
+	Schedule
+	location *time.Location
+}
+
+func (s locationSchedule) Next(t time.Time) time.Time {
+	return s.Schedule.Next(t.In(s.location))
+}
+
 // AddFunc adds a func to the Cron to be run on the given schedule.
 func (c *Cron) AddFunc(spec string, cmd func(), names ...string) error {
 	var name string
@@ -180,16 +190,40 @@ func (c *Cron) AddOnceFunc(spec string, cmd func(), names ...string) error {
 	return c.AddJob(spec, FuncJob(onceCmd), name)
 }
 
-// AddJob adds a Job to the Cron to be run on the given schedule.
-func (c *Cron) AddJob(spec string, cmd Job, names ...string) error {
-	var name string
-	var schedule Schedule
-	var err error
+// ParseSpec parses the spec in the same way as AddJob:
+// 5 fields use the standard crontab format, otherwise the optional seconds field is supported.
+func ParseSpec(spec string) (Schedule, error) {
 	if len(strings.Fields(spec)) == 5 {
-		schedule, err = cron.ParseStandard(spec)
+		return cron.ParseStandard(spec)
+	}
+	return cron.Parse(spec)
+}
+
+// AddFuncWithLocation adds a func to the Cron to be run on the given schedule,
+// the spec is interpreted in the given location instead of the location of the Cron.
+func (c *Cron) AddFuncWithLocation(spec string, location *time.Location, cmd func(), names ...string) error {
+	var name string
+	schedule, err := ParseSpec(spec)
+	if err != nil {
+		return err
+	}
+	if location != nil {
+		schedule = locationSchedule{Schedule: schedule, location: location}
+	}
+	if len(names) <= 0 {
+		name = fmt.Sprintf("%d", time.Now().Unix())
 	} else {
-		schedule, err = cron.Parse(spec)
+		name = names[0]
 	}
+
+	c.Schedule(schedule, FuncJob(cmd), name)
+	return nil
+}
+
+// AddJob adds a Job to the Cron to be run on the given schedule.
+func (c *Cron) AddJob(spec string, cmd Job, names ...string) error {
+	var name string
+	schedule, err := ParseSpec(spec)
 	if err != nil {
 		return err
 	}
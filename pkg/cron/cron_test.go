@@ -556,3 +556,14 @@ func TestRun(t *testing.T) {
 	cron.Stop()
 	assert.Equal(t, true, flag)
 }
+
+func TestLocationSchedule(t *testing.T) {
+	schedule, err := ParseSpec("0 9 * * *")
+	assert.NoError(t, err)
+
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	s := locationSchedule{Schedule: schedule, location: tokyo}
+	next := s.Next(time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC))
+	// 09:00 in UTC+9 is 00:00 UTC of the next day
+	assert.True(t, next.Equal(time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)), next.String())
+}
@@ -16,6 +16,7 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -47,6 +48,7 @@ type Request struct {
 	proto    string
 	cli      *http.Client
 	internal *http.Request
+	ctx      context.Context
 
 	option *Option
 
@@ -101,6 +103,9 @@ func (r *Request) Do() AfterDo {
 		r.err = err
 		return AfterDo{r}
 	}
+	if r.ctx != nil {
+		req = req.WithContext(r.ctx)
+	}
 
 	for k, v := range r.header {
 		req.Header.Set(k, v)
@@ -134,11 +139,11 @@ func dupRequest(req *http.Request) (*http.Request, *http.Request, error) {
 	}
 	dupbody1 := bytes.NewReader(bodybuf)
 	dupbody2 := bytes.NewReader(bodybuf)
-	req1, err := http.NewRequest(req.Method, req.URL.String(), dupbody1)
+	req1, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), dupbody1)
 	if err != nil {
 		return nil, nil, err
 	}
-	req2, err := http.NewRequest(req.Method, req.URL.String(), dupbody2)
+	req2, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), dupbody2)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -361,6 +366,13 @@ func (r *Request) Headers(hs http.Header) *Request {
 	return r
 
 }
+
+// WithContext 设置请求的 context，context 取消时正在进行的请求会被中断
+func (r *Request) WithContext(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
 func (r *Request) Cookie(v *http.Cookie) *Request {
 	r.cookie = append(r.cookie, v)
 	return r
@@ -34,6 +34,7 @@ var (
 	templateInternalError         = i18n.NewTemplate("InternalError", "异常 %s")
 	templateErrorVerificationCode = i18n.NewTemplate("ErrorVerificationCode", "验证码错误 %s")
 	templateAuthorizedRoles       = i18n.NewTemplate("AuthorizedRoles", "有权限的角色: %s")
+	templateTooManyRequests       = i18n.NewTemplate("TooManyRequests", "请求过于频繁 %s")
 )
 
 // MissingParameter 缺少参数
@@ -93,6 +94,12 @@ func (e *APIError) InternalError(err error) *APIError {
 		appendLocaleTemplate(templateInternalError, err.Error())
 }
 
+// TooManyRequests 请求过于频繁
+func (e *APIError) TooManyRequests(err string) *APIError {
+	return e.dup().appendCode(http.StatusTooManyRequests, "TooManyRequests").
+		appendLocaleTemplate(templateTooManyRequests, err)
+}
+
 // ErrorVerificationCode 验证码错误
 func (e *APIError) ErrorVerificationCode(err error) *APIError {
 	return e.dup().appendCode(http.StatusInternalServerError, "ErrorVerificationCode").
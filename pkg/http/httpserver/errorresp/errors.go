@@ -16,6 +16,9 @@
 package errorresp
 
 import (
+	"net/http"
+
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/pkg/i18n"
 	"github.com/erda-project/erda/pkg/strutil"
 )
@@ -33,23 +36,27 @@ type APIError struct {
 	msg                string
 	localeMetaMessages []MetaMessage
 	ctx                interface{}
+	details            []apistructs.ErrorDetail
 }
 
 // Error 错误信息
 func (e *APIError) Error() string {
-	if e.msg == "" {
-		e.Render(&i18n.LocaleResource{})
-	}
-	return e.msg
+	return e.Render(&i18n.LocaleResource{})
 }
 
-// Code 错误码
+// Code 错误码, 未指定时使用错误模板名
 func (e *APIError) Code() string {
+	if e.code == "" && len(e.localeMetaMessages) > 0 {
+		return e.localeMetaMessages[0].Key
+	}
 	return e.code
 }
 
-// HttpCode HTTP错误码
+// HttpCode HTTP错误码, 未指定时为 500
 func (e *APIError) HttpCode() int {
+	if e.httpCode == 0 {
+		return http.StatusInternalServerError
+	}
 	return e.httpCode
 }
 
@@ -58,6 +65,11 @@ func (e *APIError) Ctx() interface{} {
 	return e.ctx
 }
 
+// Details 字段级错误详情
+func (e *APIError) Details() []apistructs.ErrorDetail {
+	return e.details
+}
+
 // Option Optional parameters
 type Option func(*APIError)
 
@@ -84,6 +96,13 @@ func WithTemplateMessage(template, defaultValue string, args ...interface{}) Opt
 	}
 }
 
+// WithHttpCode 初始化默认 HTTP 错误码, 调用 InvalidParameter 等方法时会被覆盖
+func WithHttpCode(httpCode int) Option {
+	return func(a *APIError) {
+		a.httpCode = httpCode
+	}
+}
+
 func WithCode(httpCode int, code string) Option {
 	return func(a *APIError) {
 		_ = a.appendCode(httpCode, code)
@@ -135,7 +154,9 @@ func (e *APIError) setCtx(ctx interface{}) *APIError {
 	return e
 }
 
+// Render 按语言渲染错误信息, 语言资源中不存在对应模板时使用默认文案
 func (e *APIError) Render(localeResource *i18n.LocaleResource) string {
+	msg := e.msg
 	for _, metaMessage := range e.localeMetaMessages {
 		var template *i18n.Template
 		// 不存在key使用默认值
@@ -144,14 +165,13 @@ func (e *APIError) Render(localeResource *i18n.LocaleResource) string {
 		} else {
 			template = localeResource.GetTemplate(metaMessage.Key)
 		}
-		msg := template.Render(metaMessage.Args...)
-		if e.msg == "" {
-			e.msg = msg
+		if msg == "" {
+			msg = template.Render(metaMessage.Args...)
 		} else {
-			e.msg = strutil.Concat(e.msg, ": ", msg)
+			msg = strutil.Concat(msg, ": ", template.Render(metaMessage.Args...))
 		}
 	}
-	return e.msg
+	return msg
 }
 
 func (e *APIError) dup() *APIError {
@@ -159,11 +179,19 @@ func (e *APIError) dup() *APIError {
 		httpCode:           e.httpCode,
 		code:               e.code,
 		msg:                e.msg,
-		localeMetaMessages: e.localeMetaMessages,
+		localeMetaMessages: append([]MetaMessage(nil), e.localeMetaMessages...),
 		ctx:                e.ctx,
+		details:            append([]apistructs.ErrorDetail(nil), e.details...),
 	}
 }
 
+// AppendDetail 追加字段级错误详情, eg: AppendDetail("name", "required")
+func (e *APIError) AppendDetail(field, reason string) *APIError {
+	n := e.dup()
+	n.details = append(n.details, apistructs.ErrorDetail{Field: field, Reason: reason})
+	return n
+}
+
 // SetCtx Set ctx
 func (e *APIError) SetCtx(ctx interface{}) *APIError {
 	return e.dup().setCtx(ctx)
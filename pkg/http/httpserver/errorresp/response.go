@@ -27,13 +27,14 @@ import (
 func (e *APIError) ToResp() httpserver.Responser {
 	return &httpserver.HTTPResponse{
 		Error:  e,
-		Status: e.httpCode,
+		Status: e.HttpCode(),
 		Content: httpserver.Resp{
 			Success: false,
 			Err: apistructs.ErrorResponse{
-				Code: e.code,
-				Msg:  e.msg,
-				Ctx:  e.ctx,
+				Code:    e.Code(),
+				Msg:     e.Render(&i18n.LocaleResource{}),
+				Ctx:     e.ctx,
+				Details: e.details,
 			},
 		},
 	}
@@ -53,12 +54,13 @@ func ErrResp(e error) (httpserver.Responser, error) {
 // Write 将错误写入 http.ResponseWriter
 func (e *APIError) Write(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(e.httpCode)
+	w.WriteHeader(e.HttpCode())
 	return json.NewEncoder(w).Encode(httpserver.Resp{
 		Success: false,
 		Err: apistructs.ErrorResponse{
-			Code: e.code,
-			Msg:  e.Render(&i18n.LocaleResource{}),
+			Code:    e.Code(),
+			Msg:     e.Render(&i18n.LocaleResource{}),
+			Details: e.details,
 		},
 	})
 }
@@ -15,6 +15,7 @@
 package ierror
 
 import (
+	"github.com/erda-project/erda/apistructs"
 	"github.com/erda-project/erda/pkg/i18n"
 )
 
@@ -23,4 +24,5 @@ type IAPIError interface {
 	Code() string
 	HttpCode() int
 	Ctx() interface{}
+	Details() []apistructs.ErrorDetail
 }
@@ -59,9 +59,10 @@ func (r HTTPResponse) GetLocaledResp(locale *i18n.LocaleResource) HTTPResponse {
 			Content: Resp{
 				Success: false,
 				Err: apistructs.ErrorResponse{
-					Code: r.Error.Code(),
-					Msg:  r.Error.Render(locale),
-					Ctx:  r.Error.Ctx(),
+					Code:    r.Error.Code(),
+					Msg:     r.Error.Render(locale),
+					Ctx:     r.Error.Ctx(),
+					Details: r.Error.Details(),
 				},
 			},
 		}
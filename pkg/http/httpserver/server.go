@@ -165,8 +165,9 @@ func (s *Server) internal(handler func(context.Context, *http.Request, map[strin
 					Content: Resp{
 						Success: false,
 						Err: apistructs.ErrorResponse{
-							Code: apiError.Code(),
-							Msg:  apiError.Render(locale),
+							Code:    apiError.Code(),
+							Msg:     apiError.Render(locale),
+							Details: apiError.Details(),
 						},
 					},
 				}
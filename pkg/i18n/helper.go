@@ -14,14 +14,18 @@
 
 package i18n
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
 
 const ZH = "zh-CN"
 const EN = "en-US"
 
 // GetLocaleNameByRequest 从request获取语言名称
 func GetLocaleNameByRequest(request *http.Request) string {
-	// 优先querystring 其次header
+	// 优先querystring 其次header, 最后 Accept-Language
 	lang := request.URL.Query().Get("lang")
 	if lang != "" {
 		return lang
@@ -30,5 +34,36 @@ func GetLocaleNameByRequest(request *http.Request) string {
 	if lang != "" {
 		return lang
 	}
-	return ""
+	return getLocaleNameByAcceptLanguage(request.Header.Get("Accept-Language"))
+}
+
+// getLocaleNameByAcceptLanguage 取 Accept-Language 中权重最高的语言, eg: en-US,en;q=0.9,zh-CN;q=0.8
+func getLocaleNameByAcceptLanguage(acceptLanguage string) string {
+	var (
+		lang string
+		maxQ = 0.0
+	)
+	for _, item := range strings.Split(acceptLanguage, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ";")
+		name := strings.TrimSpace(parts[0])
+		if name == "" || name == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				continue
+			}
+			q = v
+		}
+		if q > maxQ {
+			lang, maxQ = name, q
+		}
+	}
+	return lang
 }
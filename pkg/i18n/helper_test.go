@@ -0,0 +1,43 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLocaleNameByRequest(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "/api/test?lang=zh-CN", nil)
+	r.Header.Set("Lang", "en-US")
+	assert.Equal(t, "zh-CN", GetLocaleNameByRequest(r))
+
+	r, _ = http.NewRequest(http.MethodGet, "/api/test", nil)
+	r.Header.Set("Lang", "en-US")
+	r.Header.Set("Accept-Language", "zh-CN")
+	assert.Equal(t, "en-US", GetLocaleNameByRequest(r))
+
+	r, _ = http.NewRequest(http.MethodGet, "/api/test", nil)
+	assert.Equal(t, "", GetLocaleNameByRequest(r))
+}
+
+func TestGetLocaleNameByAcceptLanguage(t *testing.T) {
+	assert.Equal(t, "en-US", getLocaleNameByAcceptLanguage("en-US,en;q=0.9,zh-CN;q=0.8"))
+	assert.Equal(t, "zh-CN", getLocaleNameByAcceptLanguage("en;q=0.5, zh-CN"))
+	assert.Equal(t, "en", getLocaleNameByAcceptLanguage("*, en;q=0.1"))
+	assert.Equal(t, "", getLocaleNameByAcceptLanguage(""))
+}
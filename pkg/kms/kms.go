@@ -36,6 +36,10 @@ type Manager struct {
 
 	pluginCtx context.Context
 	storeCtx  context.Context
+
+	rateLimitCfg RateLimitConfig
+	limiter      *rateLimiter
+	usage        *usageTracker
 }
 
 func GetManager(ops ...Option) (*Manager, error) {
@@ -60,6 +64,13 @@ func WithStoreConfigs(configs map[string]string) Option {
 	}
 }
 
+// WithRateLimitConfig set the default rate limit of keys
+func WithRateLimitConfig(cfg RateLimitConfig) Option {
+	return func(mgr *Manager) {
+		mgr.rateLimitCfg = cfg
+	}
+}
+
 func (m *Manager) initialize(ops ...Option) error {
 	initOnce.Do(func() {
 		m.pluginCtx = context.Background()
@@ -77,6 +88,9 @@ func (m *Manager) initialize(ops ...Option) error {
 			m.plugins[kind] = createFn(m.pluginCtx)
 		}
 
+		m.limiter = newRateLimiter(m.rateLimitCfg)
+		m.usage = newUsageTracker()
+
 		// store
 		m.storeFactory = kmstypes.StoreFactory
 		m.stores = make(map[kmstypes.StoreKind]kmstypes.Store)
@@ -111,3 +125,12 @@ func (m *Manager) GetStore(storeKind kmstypes.StoreKind) (kmstypes.Store, error)
 	}
 	return store, nil
 }
+
+// CheckRateLimit check whether caller can use the key now, return ErrRateLimited if exceeded.
+// caller is the source address of the request.
+func (m *Manager) CheckRateLimit(keyInfo kmstypes.KeyInfo, caller string) error {
+	if m.limiter == nil {
+		return nil
+	}
+	return m.limiter.allow(keyInfo.GetKeyID(), keyInfo.GetRateLimit(), caller)
+}
@@ -30,10 +30,13 @@ type (
 		KeyUsage              KeyUsage              `json:"keyUsage,omitempty"`
 		KeyState              KeyState              `json:"keyState,omitempty"`
 		Description           string                `json:"description,omitempty"`
+		RateLimit             *RateLimit            `json:"rateLimit,omitempty"`
+		Tags                  map[string]string     `json:"tags,omitempty"`
 	}
 
 	KeyListEntry struct {
-		KeyID string `json:"keyID,omitempty"`
+		KeyID string            `json:"keyID,omitempty"`
+		Tags  map[string]string `json:"tags,omitempty"`
 	}
 )
 
@@ -42,6 +45,8 @@ type CreateKeyRequest struct {
 	CustomerMasterKeySpec CustomerMasterKeySpec `json:"customerMasterKeySpec,omitempty"`
 	KeyUsage              KeyUsage              `json:"keyUsage,omitempty"`
 	Description           string                `json:"description,omitempty"`
+	RateLimit             *RateLimit            `json:"rateLimit,omitempty"`
+	Tags                  map[string]string     `json:"tags,omitempty"`
 }
 
 func (req *CreateKeyRequest) ValidateRequest() error {
@@ -54,7 +59,10 @@ func (req *CreateKeyRequest) ValidateRequest() error {
 	if req.KeyUsage == "" {
 		req.KeyUsage = KeyUsage_ENCRYPT_DECRYPT
 	}
-	return nil
+	if err := ValidateTags(req.Tags); err != nil {
+		return err
+	}
+	return req.RateLimit.Validate()
 }
 
 type CreateKeyResponse struct {
@@ -95,8 +103,65 @@ type DescribeKeyResponse struct {
 }
 
 type ListKeysRequest struct {
+	PluginKind PluginKind `json:"pluginKind,omitempty"`
+	// Tags filter keys which have all the tags, empty value matches any value of the tag
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+func (req *ListKeysRequest) ValidateRequest() error {
+	if req.PluginKind == "" {
+		req.PluginKind = PluginKind_DICE_KMS
+	}
+	return nil
 }
 
 type ListKeysResponse struct {
 	Keys []KeyListEntry `json:"keys,omitempty"`
 }
+
+type UpdateKeyRateLimitRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// RateLimit is the new rate limit of key, nil means using the default limit
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+}
+
+func (req *UpdateKeyRateLimitRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	return req.RateLimit.Validate()
+}
+
+type UpdateKeyRateLimitResponse struct {
+	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
+}
+
+type UpdateKeyTagsRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// Tags replace all tags of key
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+func (req *UpdateKeyTagsRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	return ValidateTags(req.Tags)
+}
+
+type UpdateKeyTagsResponse struct {
+	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
+}
+
+type DeleteKeyRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+}
+
+func (req *DeleteKeyRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	return nil
+}
+
+type DeleteKeyResponse struct{}
@@ -14,7 +14,10 @@
 
 package kmstypes
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type RotateKeyVersionRequest struct {
 	KeyID string `json:"keyID,omitempty"`
@@ -30,3 +33,49 @@ func (req *RotateKeyVersionRequest) ValidateRequest() error {
 type RotateKeyVersionResponse struct {
 	KeyMetadata KeyMetadata `json:"keyMetadata,omitempty"`
 }
+
+// RotateAllKeysRequest rotate all enabled keys of a plugin kind
+type RotateAllKeysRequest struct {
+	PluginKind PluginKind `json:"pluginKind,omitempty"`
+	// CorrelationID identifies the bulk operation in audit logs, generated if empty.
+	// Pass the previous one when re-running after a partial failure.
+	CorrelationID string `json:"correlationID,omitempty"`
+	// NotRotatedSince skips keys whose primary key version is created at or after it,
+	// so re-running with the previous response's startedAt only rotates the keys left over.
+	NotRotatedSince *time.Time `json:"notRotatedSince,omitempty"`
+}
+
+func (req *RotateAllKeysRequest) ValidateRequest() error {
+	if req.PluginKind == "" {
+		req.PluginKind = PluginKind_DICE_KMS
+	}
+	if !req.PluginKind.Validate() {
+		return fmt.Errorf("invalid pluginKind: %s", req.PluginKind)
+	}
+	return nil
+}
+
+type RotateKeyStatus string
+
+var (
+	RotateKeyStatusSuccess RotateKeyStatus = "Success"
+	RotateKeyStatusFailed  RotateKeyStatus = "Failed"
+	RotateKeyStatusSkipped RotateKeyStatus = "Skipped"
+)
+
+type RotateAllKeysResponse struct {
+	CorrelationID string            `json:"correlationID"`
+	StartedAt     time.Time         `json:"startedAt"`
+	Total         int               `json:"total"`
+	Succeeded     int               `json:"succeeded"`
+	Failed        int               `json:"failed"`
+	Skipped       int               `json:"skipped"`
+	Results       []RotateKeyResult `json:"results"`
+}
+
+type RotateKeyResult struct {
+	KeyID               string          `json:"keyID"`
+	Status              RotateKeyStatus `json:"status"`
+	PrimaryKeyVersionID string          `json:"primaryKeyVersionID,omitempty"`
+	Error               string          `json:"error,omitempty"`
+}
@@ -41,6 +41,12 @@ type KeyInfo interface {
 	GetDescription() string
 	SetDescription(string)
 
+	GetRateLimit() *RateLimit
+	SetRateLimit(*RateLimit)
+
+	GetTags() map[string]string
+	SetTags(map[string]string)
+
 	GetCreatedAt() *time.Time
 	SetCreatedAt(time.Time)
 	GetUpdatedAt() *time.Time
@@ -55,6 +61,8 @@ func GetKeyMetadata(keyInfo KeyInfo) KeyMetadata {
 		KeyUsage:              keyInfo.GetKeyUsage(),
 		KeyState:              keyInfo.GetKeyState(),
 		Description:           keyInfo.GetDescription(),
+		RateLimit:             keyInfo.GetRateLimit(),
+		Tags:                  keyInfo.GetTags(),
 	}
 }
 
@@ -104,6 +112,8 @@ type Key struct {
 	KeyUsage          KeyUsage              `json:"keyUsage,omitempty"`
 	KeyState          KeyState              `json:"keyState,omitempty"`
 	Description       string                `json:"description,omitempty"`
+	RateLimit         *RateLimit            `json:"rateLimit,omitempty"`
+	Tags              map[string]string     `json:"tags,omitempty"`
 	CreatedAt         *time.Time            `json:"createdAt,omitempty"`
 	UpdatedAt         *time.Time            `json:"updatedAt,omitempty"`
 }
@@ -121,6 +131,10 @@ func (k *Key) GetKeyState() KeyState                 { return k.KeyState }
 func (k *Key) SetKeyState(state KeyState)            { k.KeyState = state }
 func (k *Key) GetDescription() string                { return k.Description }
 func (k *Key) SetDescription(desc string)            { k.Description = desc }
+func (k *Key) GetRateLimit() *RateLimit              { return k.RateLimit }
+func (k *Key) SetRateLimit(limit *RateLimit)         { k.RateLimit = limit }
+func (k *Key) GetTags() map[string]string            { return k.Tags }
+func (k *Key) SetTags(tags map[string]string)        { k.Tags = tags }
 func (k *Key) GetCreatedAt() *time.Time              { return k.CreatedAt }
 func (k *Key) SetCreatedAt(t time.Time)              { k.CreatedAt = &t }
 func (k *Key) GetUpdatedAt() *time.Time              { return k.UpdatedAt }
@@ -152,3 +166,62 @@ func (k *KeyVersion) GetCreatedAt() *time.Time       { return k.CreatedAt }
 func (k *KeyVersion) SetCreatedAt(t time.Time)       { k.CreatedAt = &t }
 func (k *KeyVersion) GetUpdatedAt() *time.Time       { return k.UpdatedAt }
 func (k *KeyVersion) SetUpdatedAt(t time.Time)       { k.UpdatedAt = &t }
+
+// RateLimit limits the usage (encrypt, decrypt, generate data key) of a key.
+// Zero value of a field means using the default limit of kms.
+type RateLimit struct {
+	// QPS and Burst limit all callers of the key
+	QPS   float64 `json:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+	// PerCallerQPS and PerCallerBurst limit each caller of the key
+	PerCallerQPS   float64 `json:"perCallerQPS,omitempty"`
+	PerCallerBurst int     `json:"perCallerBurst,omitempty"`
+}
+
+func (l *RateLimit) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if l.QPS < 0 || l.Burst < 0 || l.PerCallerQPS < 0 || l.PerCallerBurst < 0 {
+		return fmt.Errorf("rate limit can not be negative")
+	}
+	return nil
+}
+
+// limits of key tags
+const (
+	MaxKeyTags           = 20
+	MaxKeyTagKeyLength   = 128
+	MaxKeyTagValueLength = 256
+)
+
+// ValidateTags check the number and size of key tags
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxKeyTags {
+		return fmt.Errorf("too many tags, at most %d", MaxKeyTags)
+	}
+	for k, v := range tags {
+		if k == "" {
+			return fmt.Errorf("tag key can not be empty")
+		}
+		if len(k) > MaxKeyTagKeyLength {
+			return fmt.Errorf("tag key %s is longer than %d", k, MaxKeyTagKeyLength)
+		}
+		if len(v) > MaxKeyTagValueLength {
+			return fmt.Errorf("value of tag %s is longer than %d", k, MaxKeyTagValueLength)
+		}
+	}
+	return nil
+}
+
+// MatchTags return true if key has all the tags, empty value matches any value
+func MatchTags(keyInfo KeyInfo, tags map[string]string) bool {
+	keyTags := keyInfo.GetTags()
+	for k, v := range tags {
+		value, ok := keyTags[k]
+		if !ok || (v != "" && value != v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmstypes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxKeyTags; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr bool
+	}{
+		{name: "nil", tags: nil},
+		{name: "valid", tags: map[string]string{"env": "prod", "owner": ""}},
+		{name: "too many", tags: tooMany, wantErr: true},
+		{name: "empty key", tags: map[string]string{"": "v"}, wantErr: true},
+		{name: "key too long", tags: map[string]string{strings.Repeat("k", MaxKeyTagKeyLength+1): "v"}, wantErr: true},
+		{name: "max key length", tags: map[string]string{strings.Repeat("k", MaxKeyTagKeyLength): "v"}},
+		{name: "value too long", tags: map[string]string{"k": strings.Repeat("v", MaxKeyTagValueLength+1)}, wantErr: true},
+		{name: "max value length", tags: map[string]string{"k": strings.Repeat("v", MaxKeyTagValueLength)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTags(tt.tags)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestMatchTags(t *testing.T) {
+	key := &Key{Tags: map[string]string{"env": "prod", "team": "dop"}}
+	tests := []struct {
+		name string
+		key  KeyInfo
+		tags map[string]string
+		want bool
+	}{
+		{name: "no filter", key: key, want: true},
+		{name: "match value", key: key, tags: map[string]string{"env": "prod"}, want: true},
+		{name: "match all", key: key, tags: map[string]string{"env": "prod", "team": "dop"}, want: true},
+		{name: "empty value matches any", key: key, tags: map[string]string{"team": ""}, want: true},
+		{name: "value mismatch", key: key, tags: map[string]string{"env": "test"}, want: false},
+		{name: "missing tag", key: key, tags: map[string]string{"owner": ""}, want: false},
+		{name: "partial match", key: key, tags: map[string]string{"env": "prod", "owner": "a"}, want: false},
+		{name: "key without tags", key: &Key{}, tags: map[string]string{"env": ""}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchTags(tt.key, tt.tags))
+		})
+	}
+}
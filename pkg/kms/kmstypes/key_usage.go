@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmstypes
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyOperation is the operation which uses a key
+type KeyOperation string
+
+const (
+	KeyOperationEncrypt         KeyOperation = "Encrypt"
+	KeyOperationDecrypt         KeyOperation = "Decrypt"
+	KeyOperationGenerateDataKey KeyOperation = "GenerateDataKey"
+)
+
+// KeyUsageRetention is how long the hourly usage counters are kept
+const KeyUsageRetention = 90 * 24 * time.Hour
+
+// KeyUsageStats records the usage of a key
+type KeyUsageStats struct {
+	KeyID      string     `json:"keyID"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	// Hourly counters, key is the unix timestamp (seconds) of the start of hour
+	Hourly map[int64]map[KeyOperation]int64 `json:"hourly,omitempty"`
+}
+
+func NewKeyUsageStats(keyID string) *KeyUsageStats {
+	return &KeyUsageStats{KeyID: keyID, Hourly: make(map[int64]map[KeyOperation]int64)}
+}
+
+// Add count one usage of operation at t
+func (s *KeyUsageStats) Add(op KeyOperation, t time.Time) {
+	s.add(t.Truncate(time.Hour).Unix(), op, 1)
+	if s.LastUsedAt == nil || t.After(*s.LastUsedAt) {
+		s.LastUsedAt = &t
+	}
+}
+
+func (s *KeyUsageStats) add(hour int64, op KeyOperation, count int64) {
+	if s.Hourly == nil {
+		s.Hourly = make(map[int64]map[KeyOperation]int64)
+	}
+	if s.Hourly[hour] == nil {
+		s.Hourly[hour] = make(map[KeyOperation]int64)
+	}
+	s.Hourly[hour][op] += count
+}
+
+// Merge add counters of other into s
+func (s *KeyUsageStats) Merge(other *KeyUsageStats) {
+	if other == nil {
+		return
+	}
+	for hour, ops := range other.Hourly {
+		for op, count := range ops {
+			s.add(hour, op, count)
+		}
+	}
+	if other.LastUsedAt != nil && (s.LastUsedAt == nil || other.LastUsedAt.After(*s.LastUsedAt)) {
+		t := *other.LastUsedAt
+		s.LastUsedAt = &t
+	}
+}
+
+// Prune remove counters before t
+func (s *KeyUsageStats) Prune(t time.Time) {
+	for hour := range s.Hourly {
+		if hour < t.Truncate(time.Hour).Unix() {
+			delete(s.Hourly, hour)
+		}
+	}
+}
+
+// Count sum counters of each operation in hours which start in [start, end)
+func (s *KeyUsageStats) Count(start, end time.Time) map[KeyOperation]int64 {
+	counts := make(map[KeyOperation]int64)
+	for hour, ops := range s.Hourly {
+		if hour < start.Truncate(time.Hour).Unix() || hour >= end.Unix() {
+			continue
+		}
+		for op, count := range ops {
+			counts[op] += count
+		}
+	}
+	return counts
+}
+
+type KeyUsageRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// time window in unix seconds, default is the last 7 days
+	StartTime int64 `json:"startTime,omitempty"`
+	EndTime   int64 `json:"endTime,omitempty"`
+}
+
+func (req *KeyUsageRequest) ValidateRequest() error {
+	if req.KeyID == "" {
+		return fmt.Errorf("missing keyID")
+	}
+	if req.EndTime <= 0 {
+		req.EndTime = time.Now().Unix()
+	}
+	if req.StartTime <= 0 {
+		req.StartTime = time.Unix(req.EndTime, 0).Add(-7 * 24 * time.Hour).Unix()
+	}
+	if req.StartTime >= req.EndTime {
+		return fmt.Errorf("startTime must be before endTime")
+	}
+	return nil
+}
+
+type KeyUsageResponse struct {
+	KeyID      string                 `json:"keyID"`
+	LastUsedAt *time.Time             `json:"lastUsedAt,omitempty"`
+	StartTime  int64                  `json:"startTime"`
+	EndTime    int64                  `json:"endTime"`
+	Total      int64                  `json:"total"`
+	Operations map[KeyOperation]int64 `json:"operations"`
+}
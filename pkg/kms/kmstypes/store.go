@@ -36,4 +36,16 @@ type Store interface {
 
 	// RotateKeyVersion rotate key version
 	RotateKeyVersion(keyID string, newKeyVersionInfo KeyVersionInfo) (KeyVersionInfo, error)
+
+	// UpdateKeyRateLimit update rate limit of CMK
+	UpdateKeyRateLimit(keyID string, limit *RateLimit) (KeyInfo, error)
+
+	// UpdateKeyTags replace tags of CMK
+	UpdateKeyTags(keyID string, tags map[string]string) (KeyInfo, error)
+
+	// GetKeyUsage get usage stats of CMK, return empty stats if never used
+	GetKeyUsage(keyID string) (*KeyUsageStats, error)
+
+	// AddKeyUsage merge usage delta into stored usage stats of CMK
+	AddKeyUsage(delta *KeyUsageStats) error
 }
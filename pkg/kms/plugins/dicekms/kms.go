@@ -94,6 +94,8 @@ func (d *Dice) CreateKey(ctx context.Context, req *kmstypes.CreateKeyRequest) (*
 		KeyUsage:          req.KeyUsage,
 		KeyState:          kmstypes.KeyStateEnabled,
 		Description:       req.Description,
+		RateLimit:         req.RateLimit,
+		Tags:              req.Tags,
 	}
 	err := d.store.CreateKey(&key)
 	if err != nil {
@@ -122,7 +124,14 @@ func (d *Dice) ListKeys(ctx context.Context, req *kmstypes.ListKeysRequest) (*km
 	}
 	var resp kmstypes.ListKeysResponse
 	for _, id := range keyIDs {
-		resp.Keys = append(resp.Keys, kmstypes.KeyListEntry{KeyID: id})
+		keyInfo, err := d.store.GetKey(id)
+		if err != nil {
+			return nil, err
+		}
+		if !kmstypes.MatchTags(keyInfo, req.Tags) {
+			continue
+		}
+		resp.Keys = append(resp.Keys, kmstypes.KeyListEntry{KeyID: id, Tags: keyInfo.GetTags()})
 	}
 	return &resp, nil
 }
@@ -0,0 +1,82 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicekms
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+type fakeKeyStore struct {
+	kmstypes.Store
+	keys []*kmstypes.Key
+}
+
+func (s *fakeKeyStore) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
+	var keyIDs []string
+	for _, key := range s.keys {
+		if key.PluginKind == kind {
+			keyIDs = append(keyIDs, key.KeyID)
+		}
+	}
+	return keyIDs, nil
+}
+
+func (s *fakeKeyStore) GetKey(keyID string) (kmstypes.KeyInfo, error) {
+	for _, key := range s.keys {
+		if key.KeyID == keyID {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key not exist")
+}
+
+func TestDice_ListKeys(t *testing.T) {
+	d := &Dice{}
+	d.SetStore(&fakeKeyStore{keys: []*kmstypes.Key{
+		{PluginKind: kmstypes.PluginKind_DICE_KMS, KeyID: "k1", Tags: map[string]string{"env": "prod", "team": "dop"}},
+		{PluginKind: kmstypes.PluginKind_DICE_KMS, KeyID: "k2", Tags: map[string]string{"env": "test"}},
+		{PluginKind: kmstypes.PluginKind_DICE_KMS, KeyID: "k3"},
+		{PluginKind: kmstypes.PluginKind_AWS_KMS, KeyID: "k4", Tags: map[string]string{"env": "prod"}},
+	}})
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		want []string
+	}{
+		{name: "no filter", want: []string{"k1", "k2", "k3"}},
+		{name: "filter by value", tags: map[string]string{"env": "prod"}, want: []string{"k1"}},
+		{name: "filter by key", tags: map[string]string{"env": ""}, want: []string{"k1", "k2"}},
+		{name: "filter by multiple tags", tags: map[string]string{"env": "prod", "team": "dop"}, want: []string{"k1"}},
+		{name: "no match", tags: map[string]string{"env": "staging"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := d.ListKeys(context.Background(), &kmstypes.ListKeysRequest{Tags: tt.tags})
+			assert.NoError(t, err)
+			var got []string
+			for _, entry := range resp.Keys {
+				got = append(got, entry.KeyID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
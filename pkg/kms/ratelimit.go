@@ -0,0 +1,195 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// ErrRateLimited is returned when the usage of a key exceeds its rate limit.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// IsRateLimited return if err is caused by rate limit.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// RateLimitConfig is the default rate limit of keys which have no custom limit.
+type RateLimitConfig struct {
+	QPS            float64
+	Burst          int
+	PerCallerQPS   float64
+	PerCallerBurst int
+	// ExemptCallers are source addresses or CIDRs of internal system callers which are never limited.
+	// Callers are identified by the source address of the connection, the Internal-Client header
+	// can be set by anyone and is not used to identify callers.
+	ExemptCallers []string
+}
+
+const (
+	// bucketIdleTTL is how long an unused bucket is kept, it is full again long before that
+	bucketIdleTTL = 10 * time.Minute
+	// bucketSweepInterval is the interval of evicting idle buckets
+	bucketSweepInterval = time.Minute
+)
+
+var rateLimitedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "kms_key_rate_limited",
+	Help: "the number of key usages rejected by rate limit",
+}, []string{"key_id", "scope"})
+
+func init() {
+	prometheus.MustRegister(rateLimitedCounter)
+}
+
+// rateLimiter limits the key usage in process with token buckets.
+// All buckets are read and updated under mu.
+type rateLimiter struct {
+	mu         sync.Mutex
+	cfg        RateLimitConfig
+	exemptIPs  map[string]bool
+	exemptNets []*net.IPNet
+	buckets    map[string]*tokenBucket
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{
+		cfg:       cfg,
+		exemptIPs: make(map[string]bool),
+		buckets:   make(map[string]*tokenBucket),
+		now:       time.Now,
+	}
+	for _, caller := range cfg.ExemptCallers {
+		if strings.Contains(caller, "/") {
+			if _, network, err := net.ParseCIDR(caller); err == nil {
+				l.exemptNets = append(l.exemptNets, network)
+				continue
+			}
+		}
+		if ip := net.ParseIP(caller); ip != nil {
+			l.exemptIPs[ip.String()] = true
+		}
+	}
+	return l
+}
+
+// isExempt return if the caller address is never limited.
+func (l *rateLimiter) isExempt(caller string) bool {
+	ip := net.ParseIP(caller)
+	if ip == nil {
+		return false
+	}
+	if l.exemptIPs[ip.String()] {
+		return true
+	}
+	for _, network := range l.exemptNets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allow check both the key limit and the caller limit of the key, caller is the source address of the request.
+func (l *rateLimiter) allow(keyID string, limit *kmstypes.RateLimit, caller string) error {
+	if l.isExempt(caller) {
+		return nil
+	}
+	qps, burst := l.cfg.QPS, l.cfg.Burst
+	perCallerQPS, perCallerBurst := l.cfg.PerCallerQPS, l.cfg.PerCallerBurst
+	if limit != nil {
+		if limit.QPS > 0 {
+			qps, burst = limit.QPS, limit.Burst
+		}
+		if limit.PerCallerQPS > 0 {
+			perCallerQPS, perCallerBurst = limit.PerCallerQPS, limit.PerCallerBurst
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.evictIdleBuckets(now)
+	if perCallerQPS > 0 && !l.bucket("caller/"+keyID+"/"+caller, perCallerQPS, perCallerBurst, now).take(now) {
+		rateLimitedCounter.WithLabelValues(keyID, "caller").Inc()
+		return fmt.Errorf("%w: key %s, caller %s", ErrRateLimited, keyID, caller)
+	}
+	if qps > 0 && !l.bucket("key/"+keyID, qps, burst, now).take(now) {
+		rateLimitedCounter.WithLabelValues(keyID, "key").Inc()
+		return fmt.Errorf("%w: key %s", ErrRateLimited, keyID)
+	}
+	return nil
+}
+
+// bucket return the bucket of name, limit of the bucket follows the latest limit of key.
+func (l *rateLimiter) bucket(name string, qps float64, burst int, now time.Time) *tokenBucket {
+	if burst <= 0 {
+		burst = 1
+	}
+	b, ok := l.buckets[name]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets[name] = b
+	}
+	b.qps, b.burst = qps, float64(burst)
+	return b
+}
+
+// evictIdleBuckets remove buckets which are not used for bucketIdleTTL, so that buckets of
+// deleted keys and gone callers do not grow forever.
+func (l *rateLimiter) evictIdleBuckets(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for name, b := range l.buckets {
+		if now.Sub(b.last) >= bucketIdleTTL {
+			delete(l.buckets, name)
+		}
+	}
+}
+
+type tokenBucket struct {
+	qps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.qps
+		b.last = now
+	}
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(RateLimitConfig{QPS: 1, Burst: 2, ExemptCallers: []string{"10.0.0.1", "172.16.0.0/12", "system"}})
+	l.now = func() time.Time { return now }
+
+	// default limit
+	assert.NoError(t, l.allow("k1", nil, "bundle"))
+	assert.NoError(t, l.allow("k1", nil, "bundle"))
+	err := l.allow("k1", nil, "bundle")
+	assert.True(t, IsRateLimited(err))
+
+	// exempt caller
+	assert.NoError(t, l.allow("k1", nil, "10.0.0.1"))
+	assert.NoError(t, l.allow("k1", nil, "172.20.3.4"))
+	// non address entries are ignored
+	assert.True(t, IsRateLimited(l.allow("k1", nil, "system")))
+
+	// other key has its own bucket
+	assert.NoError(t, l.allow("k2", nil, "bundle"))
+
+	// refill
+	now = now.Add(time.Second)
+	assert.NoError(t, l.allow("k1", nil, "bundle"))
+	assert.True(t, IsRateLimited(l.allow("k1", nil, "bundle")))
+
+	// per caller limit of key
+	limit := &kmstypes.RateLimit{QPS: 100, Burst: 100, PerCallerQPS: 1, PerCallerBurst: 1}
+	assert.NoError(t, l.allow("k3", limit, "a"))
+	assert.True(t, IsRateLimited(l.allow("k3", limit, "a")))
+	assert.NoError(t, l.allow("k3", limit, "b"))
+}
+
+func TestRateLimiterEvictIdleBuckets(t *testing.T) {
+	now := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(RateLimitConfig{QPS: 1, Burst: 1, PerCallerQPS: 1, PerCallerBurst: 1})
+	l.now = func() time.Time { return now }
+
+	assert.NoError(t, l.allow("k1", nil, "10.0.0.1"))
+	assert.Len(t, l.buckets, 2)
+
+	now = now.Add(bucketIdleTTL - time.Second)
+	assert.NoError(t, l.allow("k2", nil, "10.0.0.1"))
+	assert.Len(t, l.buckets, 4)
+
+	// idle buckets are evicted once per bucketSweepInterval
+	now = now.Add(time.Second)
+	assert.NoError(t, l.allow("k2", nil, "10.0.0.1"))
+	assert.Len(t, l.buckets, 4)
+	now = now.Add(bucketSweepInterval)
+	assert.NoError(t, l.allow("k2", nil, "10.0.0.1"))
+	assert.Len(t, l.buckets, 2)
+	_, ok := l.buckets["key/k1"]
+	assert.False(t, ok)
+}
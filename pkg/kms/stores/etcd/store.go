@@ -105,6 +105,8 @@ func (s *Store) CreateKey(keyInfo kmstypes.KeyInfo) error {
 		KeyUsage:          keyInfo.GetKeyUsage(),
 		KeyState:          keyInfo.GetKeyState(),
 		Description:       keyInfo.GetDescription(),
+		RateLimit:         keyInfo.GetRateLimit(),
+		Tags:              keyInfo.GetTags(),
 		CreatedAt:         &now,
 		UpdatedAt:         &now,
 	}
@@ -162,8 +164,22 @@ func (s *Store) ListKeysByKind(kind kmstypes.PluginKind) ([]string, error) {
 
 func (s *Store) DeleteByKeyID(keyID string) error {
 	ctx := context.Background()
-	_, err := s.etcdClient.Remove(ctx, makeEtcdKeyID(keyID))
-	// TODO delete relation key
+	key, err := getKeyFromEtcd(ctx, keyID, s.etcdClient)
+	if err != nil {
+		if isNotFoundErr(err) {
+			return nil
+		}
+		return err
+	}
+	// 主数据、插件类型引用、所有密钥版本及使用统计一并删除
+	_, err = s.etcdClient.GetClient().Txn(ctx).
+		Then(
+			clientv3.OpDelete(makeEtcdKeyID(keyID)),
+			clientv3.OpDelete(makeEtcdKeyIDUnderPlugin(keyID, key.GetPluginKind())),
+			clientv3.OpDelete(makeEtcdKeyVersionPrefix(keyID), clientv3.WithPrefix()),
+			clientv3.OpDelete(makeEtcdKeyUsage(keyID)),
+		).
+		Commit()
 	return err
 }
 
@@ -230,10 +246,121 @@ func (s *Store) RotateKeyVersion(keyID string, newKeyVersionInfo kmstypes.KeyVer
 	return newKeyVersionInfo, nil
 }
 
+func (s *Store) UpdateKeyRateLimit(keyID string, limit *kmstypes.RateLimit) (kmstypes.KeyInfo, error) {
+	return s.updateKey(keyID, func(keyInfo kmstypes.KeyInfo) { keyInfo.SetRateLimit(limit) })
+}
+
+func (s *Store) UpdateKeyTags(keyID string, tags map[string]string) (kmstypes.KeyInfo, error) {
+	return s.updateKey(keyID, func(keyInfo kmstypes.KeyInfo) { keyInfo.SetTags(tags) })
+}
+
+// updateKeyAttempts is the max attempts of compare-and-swap when key is updated by other kms instances concurrently
+const updateKeyAttempts = 5
+
+// updateKey apply update to stored key with compare-and-swap,
+// so that concurrent updates of different fields (e.g. rate limit and tags) won't overwrite each other.
+func (s *Store) updateKey(keyID string, update func(kmstypes.KeyInfo)) (kmstypes.KeyInfo, error) {
+	ctx := context.Background()
+	etcdKey := makeEtcdKeyID(keyID)
+	for i := 0; i < updateKeyAttempts; i++ {
+		resp, err := s.etcdClient.GetClient().Get(ctx, etcdKey)
+		if err != nil {
+			return nil, fmt.Errorf("get key from etcd failed, err: %v", err)
+		}
+		if len(resp.Kvs) == 0 {
+			return nil, fmt.Errorf("key not exist")
+		}
+		var keyInfo kmstypes.Key
+		if err := json.Unmarshal(resp.Kvs[0].Value, &keyInfo); err != nil {
+			return nil, err
+		}
+		update(&keyInfo)
+		keyInfo.SetUpdatedAt(time.Now())
+
+		keyJSON, err := json.Marshal(&keyInfo)
+		if err != nil {
+			return nil, err
+		}
+		txnResp, err := s.etcdClient.GetClient().Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(etcdKey), "=", resp.Kvs[0].ModRevision)).
+			Then(clientv3.OpPut(etcdKey, string(keyJSON))).
+			Commit()
+		if err != nil {
+			return nil, err
+		}
+		if txnResp.Succeeded {
+			return &keyInfo, nil
+		}
+	}
+	return nil, fmt.Errorf("key %s is updated concurrently, retry later", keyID)
+}
+
+func (s *Store) GetKeyUsage(keyID string) (*kmstypes.KeyUsageStats, error) {
+	ctx := context.Background()
+	value, err := s.etcdClient.Get(ctx, makeEtcdKeyUsage(keyID))
+	if err != nil {
+		if isNotFoundErr(err) {
+			return kmstypes.NewKeyUsageStats(keyID), nil
+		}
+		return nil, err
+	}
+	var usage kmstypes.KeyUsageStats
+	if err := json.Unmarshal(value.Value, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// addKeyUsageAttempts is the max attempts of compare-and-swap when usage is updated by other kms instances concurrently
+const addKeyUsageAttempts = 5
+
+// AddKeyUsage merge delta into stored usage with compare-and-swap,
+// so that usage flushed by multiple kms instances at the same time won't be overwritten.
+func (s *Store) AddKeyUsage(delta *kmstypes.KeyUsageStats) error {
+	ctx := context.Background()
+	etcdKey := makeEtcdKeyUsage(delta.KeyID)
+	for i := 0; i < addKeyUsageAttempts; i++ {
+		resp, err := s.etcdClient.GetClient().Get(ctx, etcdKey)
+		if err != nil {
+			return err
+		}
+		usage := kmstypes.NewKeyUsageStats(delta.KeyID)
+		// modRevision is 0 when usage doesn't exist
+		var modRevision int64
+		if len(resp.Kvs) > 0 {
+			modRevision = resp.Kvs[0].ModRevision
+			if err := json.Unmarshal(resp.Kvs[0].Value, usage); err != nil {
+				return err
+			}
+		}
+		usage.Merge(delta)
+		usage.Prune(time.Now().Add(-kmstypes.KeyUsageRetention))
+		usageJSON, err := json.Marshal(usage)
+		if err != nil {
+			return err
+		}
+		txnResp, err := s.etcdClient.GetClient().Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(etcdKey), "=", modRevision)).
+			Then(clientv3.OpPut(etcdKey, string(usageJSON))).
+			Commit()
+		if err != nil {
+			return err
+		}
+		if txnResp.Succeeded {
+			return nil
+		}
+	}
+	return fmt.Errorf("usage of key %s is updated concurrently, retry later", delta.KeyID)
+}
+
 func makeEtcdKeyID(keyID string) string {
 	return fmt.Sprintf("/dice/kms/cmk/%s", keyID)
 }
 
+func makeEtcdKeyUsage(keyID string) string {
+	return fmt.Sprintf("/dice/kms/usage/%s", keyID)
+}
+
 func makeEtcdKeyIDUnderPlugin(keyID string, pluginKind kmstypes.PluginKind) string {
 	return makeEtcdPluginKindPrefix(pluginKind) + keyID
 }
@@ -243,7 +370,11 @@ func makeEtcdPluginKindPrefix(pluginKind kmstypes.PluginKind) string {
 }
 
 func makeEtcdKeyVersionID(keyID, keyVersion string) string {
-	return fmt.Sprintf("%s/version/%s", makeEtcdKeyID(keyID), keyVersion)
+	return makeEtcdKeyVersionPrefix(keyID) + keyVersion
+}
+
+func makeEtcdKeyVersionPrefix(keyID string) string {
+	return fmt.Sprintf("%s/version/", makeEtcdKeyID(keyID))
 }
 
 func getKeyFromEtcd(ctx context.Context, keyID string, etcdClient *etcd.Store) (*kmstypes.Key, error) {
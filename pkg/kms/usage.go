@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+// usageTracker accumulates key usage in memory and flushes it to store in batch,
+// so that using a key does not write the store every time.
+type usageTracker struct {
+	mu      sync.Mutex
+	pending map[string]*kmstypes.KeyUsageStats
+	// store is where usage is flushed to, set when periodical flush starts
+	store kmstypes.Store
+	// flushMu serialises flushes, so close waits for the running flush
+	flushMu sync.Mutex
+	now     func() time.Time
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{
+		pending: make(map[string]*kmstypes.KeyUsageStats),
+		now:     time.Now,
+	}
+}
+
+func (t *usageTracker) record(keyID string, op kmstypes.KeyOperation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage, ok := t.pending[keyID]
+	if !ok {
+		usage = kmstypes.NewKeyUsageStats(keyID)
+		t.pending[keyID] = usage
+	}
+	usage.Add(op, t.now())
+}
+
+// peek return a copy of pending usage of key
+func (t *usageTracker) peek(keyID string) *kmstypes.KeyUsageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage := kmstypes.NewKeyUsageStats(keyID)
+	usage.Merge(t.pending[keyID])
+	return usage
+}
+
+// flush write all pending usage to store, failed ones are kept for next flush
+func (t *usageTracker) flush(store kmstypes.Store) {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]*kmstypes.KeyUsageStats)
+	t.mu.Unlock()
+
+	for keyID, usage := range pending {
+		if err := store.AddKeyUsage(usage); err != nil {
+			logrus.Errorf("failed to flush usage of key %s, err: %v", keyID, err)
+			t.mu.Lock()
+			if current, ok := t.pending[keyID]; ok {
+				usage.Merge(current)
+			}
+			t.pending[keyID] = usage
+			t.mu.Unlock()
+		}
+	}
+}
+
+// RecordKeyUsage count one usage of key, the usage is flushed to store periodically
+func (m *Manager) RecordKeyUsage(keyID string, op kmstypes.KeyOperation) {
+	if m.usage == nil {
+		return
+	}
+	m.usage.record(keyID, op)
+}
+
+// GetKeyUsage return usage of key, including the usage not flushed yet
+func (m *Manager) GetKeyUsage(storeKind kmstypes.StoreKind, keyID string) (*kmstypes.KeyUsageStats, error) {
+	store, err := m.GetStore(storeKind)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := store.GetKeyUsage(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if m.usage != nil {
+		usage.Merge(m.usage.peek(keyID))
+	}
+	return usage, nil
+}
+
+// FlushKeyUsage flush key usage to store every interval until ctx is done
+func (m *Manager) FlushKeyUsage(ctx context.Context, storeKind kmstypes.StoreKind, interval time.Duration) {
+	store, err := m.GetStore(storeKind)
+	if err != nil {
+		logrus.Errorf("failed to get store to flush key usage, err: %v", err)
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	m.usage.mu.Lock()
+	m.usage.store = store
+	m.usage.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.usage.flush(store)
+			return
+		case <-ticker.C:
+			m.usage.flush(store)
+		}
+	}
+}
+
+// Close flush all pending key usage to store, it should be called before exit,
+// otherwise usage recorded since last flush is lost.
+func (m *Manager) Close() error {
+	if m.usage == nil {
+		return nil
+	}
+	m.usage.mu.Lock()
+	store := m.usage.store
+	m.usage.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+	m.usage.flush(store)
+	return nil
+}
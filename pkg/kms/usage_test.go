@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kms
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/kms/kmstypes"
+)
+
+type fakeUsageStore struct {
+	kmstypes.Store
+	usages map[string]*kmstypes.KeyUsageStats
+	fail   bool
+	writes int
+}
+
+func (s *fakeUsageStore) AddKeyUsage(delta *kmstypes.KeyUsageStats) error {
+	if s.fail {
+		return fmt.Errorf("fake error")
+	}
+	s.writes++
+	if s.usages[delta.KeyID] == nil {
+		s.usages[delta.KeyID] = kmstypes.NewKeyUsageStats(delta.KeyID)
+	}
+	s.usages[delta.KeyID].Merge(delta)
+	return nil
+}
+
+func TestUsageTracker(t *testing.T) {
+	now := time.Date(2021, 9, 1, 10, 30, 0, 0, time.UTC)
+	tracker := newUsageTracker()
+	tracker.now = func() time.Time { return now }
+	store := &fakeUsageStore{usages: make(map[string]*kmstypes.KeyUsageStats)}
+
+	tracker.record("k1", kmstypes.KeyOperationEncrypt)
+	tracker.record("k1", kmstypes.KeyOperationEncrypt)
+	now = now.Add(time.Hour)
+	tracker.record("k1", kmstypes.KeyOperationDecrypt)
+
+	pending := tracker.peek("k1")
+	assert.Equal(t, now, *pending.LastUsedAt)
+
+	// failed flush keeps the pending usage
+	store.fail = true
+	tracker.flush(store)
+	store.fail = false
+	tracker.record("k1", kmstypes.KeyOperationDecrypt)
+	tracker.flush(store)
+	assert.Equal(t, 1, store.writes)
+	assert.Empty(t, tracker.pending)
+
+	usage := store.usages["k1"]
+	start := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, map[kmstypes.KeyOperation]int64{
+		kmstypes.KeyOperationEncrypt: 2,
+		kmstypes.KeyOperationDecrypt: 2,
+	}, usage.Count(start, start.Add(24*time.Hour)))
+	assert.Equal(t, map[kmstypes.KeyOperation]int64{
+		kmstypes.KeyOperationDecrypt: 2,
+	}, usage.Count(start.Add(11*time.Hour), start.Add(24*time.Hour)))
+
+	usage.Prune(start.Add(11 * time.Hour))
+	assert.Equal(t, 1, len(usage.Hourly))
+}
+
+func TestManagerCloseFlushUsage(t *testing.T) {
+	store := &fakeUsageStore{usages: make(map[string]*kmstypes.KeyUsageStats)}
+	m := &Manager{usage: newUsageTracker()}
+
+	// nothing to flush before the flush store is known
+	m.RecordKeyUsage("k1", kmstypes.KeyOperationEncrypt)
+	assert.NoError(t, m.Close())
+	assert.Equal(t, 0, store.writes)
+
+	m.usage.store = store
+	assert.NoError(t, m.Close())
+	assert.Equal(t, 1, store.writes)
+	assert.Empty(t, m.usage.pending)
+}
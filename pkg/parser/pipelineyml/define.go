@@ -39,6 +39,7 @@ type Spec struct {
 	Envs map[string]string `yaml:"envs,omitempty"`
 
 	Cron            string           `yaml:"cron,omitempty"`
+	CronTimeZone    string           `yaml:"cron_time_zone,omitempty"` // IANA 时区，如 Asia/Shanghai，为空时使用服务端时区
 	CronCompensator *CronCompensator `yaml:"cron_compensator,omitempty"`
 
 	Stages []*Stage `yaml:"stages"`
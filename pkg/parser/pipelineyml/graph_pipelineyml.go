@@ -30,6 +30,7 @@ func ConvertGraphPipelineYmlContent(data []byte) ([]byte, error) {
 	s.Version = frontendYmlSpec.Version
 	s.Envs = frontendYmlSpec.Envs
 	s.Cron = frontendYmlSpec.Cron
+	s.CronTimeZone = frontendYmlSpec.CronTimeZone
 	if frontendYmlSpec.CronCompensator != nil {
 		s.CronCompensator = &CronCompensator{
 			Enable:               frontendYmlSpec.CronCompensator.Enable,
@@ -162,13 +163,14 @@ func ConvertToGraphPipelineYml(data []byte) (*apistructs.PipelineYml, error) {
 		}
 	}
 	result := &apistructs.PipelineYml{
-		Version:     pipelineYml.Spec().Version,
-		Envs:        pipelineYml.Spec().Envs,
-		Cron:        pipelineYml.Spec().Cron,
-		NeedUpgrade: pipelineYml.needUpgrade,
-		Params:      pipelineParams,
-		Outputs:     pipelineOutputs,
-		On:          on,
+		Version:      pipelineYml.Spec().Version,
+		Envs:         pipelineYml.Spec().Envs,
+		Cron:         pipelineYml.Spec().Cron,
+		CronTimeZone: pipelineYml.Spec().CronTimeZone,
+		NeedUpgrade:  pipelineYml.needUpgrade,
+		Params:       pipelineParams,
+		Outputs:      pipelineOutputs,
+		On:           on,
 	}
 
 	var lifecycle []*apistructs.NetworkHookInfo
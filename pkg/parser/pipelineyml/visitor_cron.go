@@ -15,6 +15,7 @@
 package pipelineyml
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -37,6 +38,8 @@ type CronVisitor struct {
 	cronStartTime *time.Time
 	cronEndTime   *time.Time
 	count         int
+	// timeZone 未指定时使用 spec 中的 cron_time_zone
+	timeZone string
 
 	// result
 	nextTimes []time.Time
@@ -71,6 +74,12 @@ func WithListNextScheduleCount(count int) CronVisitorOption {
 	}
 }
 
+func WithCronTimeZone(timeZone string) CronVisitorOption {
+	return func(v *CronVisitor) {
+		v.timeZone = timeZone
+	}
+}
+
 func (v *CronVisitor) Visit(s *Spec) {
 	if s.Cron == "" {
 		s.CronCompensator = nil
@@ -80,10 +89,17 @@ func (v *CronVisitor) Visit(s *Spec) {
 
 	v.isCron = true
 
-	var (
-		schedule cron.Schedule
-		err      error
-	)
+	timeZone := v.timeZone
+	if timeZone == "" {
+		timeZone = s.CronTimeZone
+	}
+	location, err := LoadCronLocation(timeZone)
+	if err != nil {
+		s.appendError(err)
+		return
+	}
+
+	var schedule cron.Schedule
 
 	switch fields := strings.Fields(s.Cron); len(fields) {
 	case 7:
@@ -105,6 +121,10 @@ func (v *CronVisitor) Visit(s *Spec) {
 	if v.cronStartTime != nil {
 		scheduleFrom = *v.cronStartTime
 	}
+	// 指定了定时时区时按该时区计算触发时间
+	if timeZone != "" {
+		scheduleFrom = scheduleFrom.In(location)
+	}
 
 	for {
 		if len(v.nextTimes) >= maxListNextScheduleCount {
@@ -127,6 +147,18 @@ func (v *CronVisitor) Visit(s *Spec) {
 	}
 }
 
+// LoadCronLocation 根据 IANA 时区名获取定时使用的时区，为空时使用服务端时区
+func LoadCronLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron time zone %q, must be an IANA time zone name such as Asia/Shanghai", timeZone)
+	}
+	return location, nil
+}
+
 func ListNextCronTime(cronExpr string, ops ...CronVisitorOption) ([]time.Time, error) {
 	s := Spec{Cron: cronExpr}
 	v := NewCronVisitor(ops...)
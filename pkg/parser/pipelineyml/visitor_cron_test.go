@@ -103,3 +103,21 @@ func TestListNextCronTime2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, len(nextTimes) == 9)
 }
+
+func TestCronTimeZone(t *testing.T) {
+	cronStartTime := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	s := Spec{Cron: "0 9 * * *", CronTimeZone: "Asia/Tokyo"}
+	v := NewCronVisitor(WithCronStartEndTime(&cronStartTime, nil), WithListNextScheduleCount(1))
+	s.Accept(v)
+	assert.NoError(t, s.mergeErrors())
+	assert.Len(t, v.nextTimes, 1)
+	// 09:00 Asia/Tokyo is 00:00 UTC
+	assert.True(t, v.nextTimes[0].Equal(time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)), v.nextTimes[0].String())
+
+	s = Spec{Cron: "0 9 * * *", CronTimeZone: "Mars/Olympus"}
+	s.Accept(NewCronVisitor())
+	assert.Error(t, s.mergeErrors())
+
+	_, err := LoadCronLocation("")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,385 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ChangeLevel 变更的兼容性级别
+type ChangeLevel string
+
+const (
+	ChangeLevelBreaking    ChangeLevel = "breaking"
+	ChangeLevelNonBreaking ChangeLevel = "non-breaking"
+)
+
+// ChangeKind 变更类型
+type ChangeKind string
+
+const (
+	ChangeKindOperationAdded        ChangeKind = "operation-added"
+	ChangeKindOperationRemoved      ChangeKind = "operation-removed"
+	ChangeKindParameterAdded        ChangeKind = "parameter-added"
+	ChangeKindParameterRemoved      ChangeKind = "parameter-removed"
+	ChangeKindParameterRequired     ChangeKind = "parameter-required"
+	ChangeKindParameterOptional     ChangeKind = "parameter-optional"
+	ChangeKindRequestBodyAdded      ChangeKind = "request-body-added"
+	ChangeKindRequestBodyRemoved    ChangeKind = "request-body-removed"
+	ChangeKindRequestBodyRequired   ChangeKind = "request-body-required"
+	ChangeKindPropertyAdded         ChangeKind = "property-added"
+	ChangeKindPropertyRemoved       ChangeKind = "property-removed"
+	ChangeKindPropertyRequired      ChangeKind = "property-required"
+	ChangeKindPropertyOptional      ChangeKind = "property-optional"
+	ChangeKindTypeChanged           ChangeKind = "type-changed"
+	ChangeKindEnumValueRemoved      ChangeKind = "enum-value-removed"
+	ChangeKindResponseStatusAdded   ChangeKind = "response-status-added"
+	ChangeKindResponseStatusRemoved ChangeKind = "response-status-removed"
+)
+
+// 比较 schema 的最大深度, 避免循环引用
+const diffSchemaMaxDepth = 16
+
+// Change 两个版本间的一处变更
+type Change struct {
+	Level    ChangeLevel `json:"level"`
+	Kind     ChangeKind  `json:"kind"`
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Location string      `json:"location,omitempty"` // 变更在 operation 中的位置, 如 parameters.query.name, requestBody.name
+	Message  string      `json:"message"`
+}
+
+// DiffReport 两个版本的结构化差异
+type DiffReport struct {
+	Breaking         bool      `json:"breaking"`
+	BreakingCount    int       `json:"breakingCount"`
+	NonBreakingCount int       `json:"nonBreakingCount"`
+	Changes          []*Change `json:"changes"`
+}
+
+// Diff 比较 base 和 target 两个文档, 返回从 base 变为 target 的所有接口变更.
+// 调用方视角下会导致原有调用失败的变更为 breaking, 如删除接口, 新增必填参数, 参数类型变化, 删除响应字段等.
+func Diff(base, target *openapi3.Swagger) *DiffReport {
+	var d = differ{report: &DiffReport{Changes: []*Change{}}}
+
+	var paths = make(map[string]struct{})
+	for path := range base.Paths {
+		paths[path] = struct{}{}
+	}
+	for path := range target.Paths {
+		paths[path] = struct{}{}
+	}
+	var sorted []string
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	for _, path := range sorted {
+		basePathItem, targetPathItem := base.Paths[path], target.Paths[path]
+		for _, method := range diffMethods {
+			var baseOperation, targetOperation *openapi3.Operation
+			if basePathItem != nil {
+				baseOperation = basePathItem.GetOperation(method)
+			}
+			if targetPathItem != nil {
+				targetOperation = targetPathItem.GetOperation(method)
+			}
+			d.method, d.path = method, path
+			switch {
+			case baseOperation == nil && targetOperation == nil:
+				continue
+			case baseOperation == nil:
+				d.add(ChangeLevelNonBreaking, ChangeKindOperationAdded, "", "operation added")
+			case targetOperation == nil:
+				d.add(ChangeLevelBreaking, ChangeKindOperationRemoved, "", "operation removed")
+			default:
+				d.diffOperation(basePathItem, targetPathItem, baseOperation, targetOperation)
+			}
+		}
+	}
+
+	return d.report
+}
+
+var diffMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodConnect,
+}
+
+type differ struct {
+	report *DiffReport
+	method string
+	path   string
+}
+
+func (d *differ) add(level ChangeLevel, kind ChangeKind, location, format string, a ...interface{}) {
+	d.report.Changes = append(d.report.Changes, &Change{
+		Level:    level,
+		Kind:     kind,
+		Method:   d.method,
+		Path:     d.path,
+		Location: location,
+		Message:  fmt.Sprintf(format, a...),
+	})
+	if level == ChangeLevelBreaking {
+		d.report.Breaking = true
+		d.report.BreakingCount++
+	} else {
+		d.report.NonBreakingCount++
+	}
+}
+
+func (d *differ) diffOperation(basePathItem, targetPathItem *openapi3.PathItem, base, target *openapi3.Operation) {
+	baseKeys, baseParams := operationParameters(basePathItem, base)
+	targetKeys, targetParams := operationParameters(targetPathItem, target)
+	d.diffParameters(baseKeys, baseParams, targetKeys, targetParams)
+	d.diffRequestBody(base.RequestBody, target.RequestBody)
+	d.diffResponses(base.Responses, target.Responses)
+}
+
+// operationParameters 合并 path item 和 operation 上的参数, operation 上的同名参数优先
+func operationParameters(pathItem *openapi3.PathItem, operation *openapi3.Operation) ([]string, map[string]*openapi3.Parameter) {
+	var (
+		keys   []string
+		params = make(map[string]*openapi3.Parameter)
+	)
+	for _, parameters := range []openapi3.Parameters{pathItem.Parameters, operation.Parameters} {
+		for _, ref := range parameters {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			key := ref.Value.In + "." + ref.Value.Name
+			if _, ok := params[key]; !ok {
+				keys = append(keys, key)
+			}
+			params[key] = ref.Value
+		}
+	}
+	sort.Strings(keys)
+	return keys, params
+}
+
+func (d *differ) diffParameters(baseKeys []string, base map[string]*openapi3.Parameter, targetKeys []string, target map[string]*openapi3.Parameter) {
+	for _, key := range baseKeys {
+		location := "parameters." + key
+		baseParam := base[key]
+		targetParam, ok := target[key]
+		if !ok {
+			d.add(ChangeLevelNonBreaking, ChangeKindParameterRemoved, location, "parameter %s removed", key)
+			continue
+		}
+		switch {
+		case !baseParam.Required && targetParam.Required:
+			d.add(ChangeLevelBreaking, ChangeKindParameterRequired, location, "parameter %s became required", key)
+		case baseParam.Required && !targetParam.Required:
+			d.add(ChangeLevelNonBreaking, ChangeKindParameterOptional, location, "parameter %s became optional", key)
+		}
+		d.diffSchema(location, schemaValue(baseParam.Schema), schemaValue(targetParam.Schema), true, 0)
+	}
+	for _, key := range targetKeys {
+		if _, ok := base[key]; ok {
+			continue
+		}
+		location := "parameters." + key
+		if target[key].Required {
+			d.add(ChangeLevelBreaking, ChangeKindParameterAdded, location, "required parameter %s added", key)
+		} else {
+			d.add(ChangeLevelNonBreaking, ChangeKindParameterAdded, location, "optional parameter %s added", key)
+		}
+	}
+}
+
+func (d *differ) diffRequestBody(baseRef, targetRef *openapi3.RequestBodyRef) {
+	var base, target *openapi3.RequestBody
+	if baseRef != nil {
+		base = baseRef.Value
+	}
+	if targetRef != nil {
+		target = targetRef.Value
+	}
+	const location = "requestBody"
+	switch {
+	case base == nil && target == nil:
+		return
+	case base == nil:
+		if target.Required {
+			d.add(ChangeLevelBreaking, ChangeKindRequestBodyAdded, location, "required request body added")
+		} else {
+			d.add(ChangeLevelNonBreaking, ChangeKindRequestBodyAdded, location, "optional request body added")
+		}
+		return
+	case target == nil:
+		d.add(ChangeLevelNonBreaking, ChangeKindRequestBodyRemoved, location, "request body removed")
+		return
+	}
+	if !base.Required && target.Required {
+		d.add(ChangeLevelBreaking, ChangeKindRequestBodyRequired, location, "request body became required")
+	}
+	for _, contentType := range sortedContentTypes(base.Content) {
+		targetMediaType, ok := target.Content[contentType]
+		if !ok || targetMediaType == nil || base.Content[contentType] == nil {
+			continue
+		}
+		d.diffSchema(location, schemaValue(base.Content[contentType].Schema), schemaValue(targetMediaType.Schema), true, 0)
+	}
+}
+
+func (d *differ) diffResponses(base, target openapi3.Responses) {
+	var statuses []string
+	for status := range base {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		location := "responses." + status
+		targetRef, ok := target[status]
+		if !ok {
+			// 删除成功响应会导致调用方无法解析结果
+			if strings.HasPrefix(status, "2") {
+				d.add(ChangeLevelBreaking, ChangeKindResponseStatusRemoved, location, "response status %s removed", status)
+			} else {
+				d.add(ChangeLevelNonBreaking, ChangeKindResponseStatusRemoved, location, "response status %s removed", status)
+			}
+			continue
+		}
+		baseRef := base[status]
+		if baseRef == nil || baseRef.Value == nil || targetRef == nil || targetRef.Value == nil {
+			continue
+		}
+		for _, contentType := range sortedContentTypes(baseRef.Value.Content) {
+			targetMediaType, ok := targetRef.Value.Content[contentType]
+			if !ok || targetMediaType == nil || baseRef.Value.Content[contentType] == nil {
+				continue
+			}
+			d.diffSchema(location, schemaValue(baseRef.Value.Content[contentType].Schema), schemaValue(targetMediaType.Schema), false, 0)
+		}
+	}
+	var added []string
+	for status := range target {
+		if _, ok := base[status]; !ok {
+			added = append(added, status)
+		}
+	}
+	sort.Strings(added)
+	for _, status := range added {
+		d.add(ChangeLevelNonBreaking, ChangeKindResponseStatusAdded, "responses."+status, "response status %s added", status)
+	}
+}
+
+// diffSchema 比较两个 schema. request 为 true 表示 schema 描述的是调用方发送的数据,
+// 此时新增必填字段, 删除枚举值为 breaking; 否则 schema 描述的是调用方接收的数据, 删除字段为 breaking.
+func (d *differ) diffSchema(location string, base, target *openapi3.Schema, request bool, depth int) {
+	if base == nil || target == nil || depth > diffSchemaMaxDepth {
+		return
+	}
+	if base.Type != "" && target.Type != "" && base.Type != target.Type {
+		d.add(ChangeLevelBreaking, ChangeKindTypeChanged, location, "type changed from %s to %s", base.Type, target.Type)
+		return
+	}
+	if request && len(base.Enum) > 0 && len(target.Enum) > 0 {
+		for _, v := range base.Enum {
+			if !enumContains(target.Enum, v) {
+				d.add(ChangeLevelBreaking, ChangeKindEnumValueRemoved, location, "enum value %v removed", v)
+			}
+		}
+	}
+
+	if base.Items != nil && target.Items != nil {
+		d.diffSchema(location+"[]", base.Items.Value, target.Items.Value, request, depth+1)
+	}
+
+	var names []string
+	for name := range base.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propLocation := location + "." + name
+		targetRef, ok := target.Properties[name]
+		if !ok {
+			if request {
+				d.add(ChangeLevelNonBreaking, ChangeKindPropertyRemoved, propLocation, "property %s removed", name)
+			} else {
+				d.add(ChangeLevelBreaking, ChangeKindPropertyRemoved, propLocation, "property %s removed", name)
+			}
+			continue
+		}
+		if request {
+			switch baseRequired, targetRequired := stringsContain(base.Required, name), stringsContain(target.Required, name); {
+			case !baseRequired && targetRequired:
+				d.add(ChangeLevelBreaking, ChangeKindPropertyRequired, propLocation, "property %s became required", name)
+			case baseRequired && !targetRequired:
+				d.add(ChangeLevelNonBreaking, ChangeKindPropertyOptional, propLocation, "property %s became optional", name)
+			}
+		}
+		d.diffSchema(propLocation, schemaValue(base.Properties[name]), schemaValue(targetRef), request, depth+1)
+	}
+
+	var added []string
+	for name := range target.Properties {
+		if _, ok := base.Properties[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		if request && stringsContain(target.Required, name) {
+			d.add(ChangeLevelBreaking, ChangeKindPropertyAdded, location+"."+name, "required property %s added", name)
+		} else {
+			d.add(ChangeLevelNonBreaking, ChangeKindPropertyAdded, location+"."+name, "property %s added", name)
+		}
+	}
+}
+
+func schemaValue(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+func sortedContentTypes(content openapi3.Content) []string {
+	var contentTypes []string
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+	return contentTypes
+}
+
+func enumContains(enum []interface{}, v interface{}) bool {
+	for _, item := range enum {
+		if fmt.Sprint(item) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringsContain(ss []string, s string) bool {
+	for _, item := range ss {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
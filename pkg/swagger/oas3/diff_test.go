@@ -0,0 +1,136 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+const diffBaseSpec = `
+openapi: 3.0.0
+info: {title: users, version: "1.0"}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: integer}
+                  name: {type: string}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+                role: {type: string, enum: [admin, guest]}
+      responses:
+        "200": {description: ok}
+  /users/{id}:
+    delete:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200": {description: ok}
+`
+
+const diffTargetSpec = `
+openapi: 3.0.0
+info: {title: users, version: "2.0"}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: page, in: query, schema: {type: string}}
+        - {name: size, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: integer}
+                  email: {type: string}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name, email]
+              properties:
+                name: {type: string}
+                email: {type: string}
+                role: {type: string, enum: [admin]}
+      responses:
+        "200": {description: ok}
+`
+
+func TestDiff(t *testing.T) {
+	base, err := oas3.LoadFromData([]byte(diffBaseSpec))
+	assert.NoError(t, err)
+	target, err := oas3.LoadFromData([]byte(diffTargetSpec))
+	assert.NoError(t, err)
+
+	report := oas3.Diff(base, target)
+	assert.True(t, report.Breaking)
+
+	var changes = make(map[string]*oas3.Change)
+	for _, change := range report.Changes {
+		changes[change.Method+" "+change.Path+" "+change.Location] = change
+	}
+
+	for key, want := range map[string]struct {
+		kind  oas3.ChangeKind
+		level oas3.ChangeLevel
+	}{
+		"DELETE /users/{id} ":              {oas3.ChangeKindOperationRemoved, oas3.ChangeLevelBreaking},
+		"GET /users parameters.query.page": {oas3.ChangeKindTypeChanged, oas3.ChangeLevelBreaking},
+		"GET /users parameters.query.size": {oas3.ChangeKindParameterAdded, oas3.ChangeLevelNonBreaking},
+		"GET /users responses.200.name":    {oas3.ChangeKindPropertyRemoved, oas3.ChangeLevelBreaking},
+		"GET /users responses.200.email":   {oas3.ChangeKindPropertyAdded, oas3.ChangeLevelNonBreaking},
+		"POST /users requestBody.email":    {oas3.ChangeKindPropertyAdded, oas3.ChangeLevelBreaking},
+		"POST /users requestBody.role":     {oas3.ChangeKindEnumValueRemoved, oas3.ChangeLevelBreaking},
+	} {
+		change, ok := changes[key]
+		if !assert.True(t, ok, key) {
+			continue
+		}
+		assert.Equal(t, want.kind, change.Kind, key)
+		assert.Equal(t, want.level, change.Level, key)
+	}
+	assert.Equal(t, len(report.Changes), report.BreakingCount+report.NonBreakingCount)
+	assert.Equal(t, 5, report.BreakingCount)
+
+	report = oas3.Diff(base, base)
+	assert.False(t, report.Breaking)
+	assert.Empty(t, report.Changes)
+}
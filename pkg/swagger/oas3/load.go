@@ -18,6 +18,11 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// LoadFromData 加载 OpenAPI 3.0/3.1 文档, 3.1 文档会先转换为 3.0 兼容的结构
 func LoadFromData(data []byte) (*openapi3.Swagger, error) {
+	data, err := downgradeOAS31Data(data)
+	if err != nil {
+		return nil, err
+	}
 	return openapi3.NewSwaggerLoader().LoadSwaggerFromData(data)
 }
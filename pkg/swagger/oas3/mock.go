@@ -0,0 +1,266 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/erda-project/erda/pkg/swagger/oasconv"
+)
+
+const (
+	// MockStatusHeader 调用方通过该请求头指定 mock 响应的状态码, 须为文档中声明的状态码
+	MockStatusHeader = "X-Mock-Status"
+	// MockLatencyHeader 调用方通过该请求头指定 mock 响应的延迟, 单位毫秒
+	MockLatencyHeader = "X-Mock-Latency"
+	// MockLatencyExtension 在 operation 上声明 mock 响应的默认延迟, 单位毫秒
+	MockLatencyExtension = "x-mock-latency"
+
+	MaxMockLatency = time.Minute
+)
+
+// MockResponse mock 响应
+type MockResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	Latency     time.Duration
+}
+
+// FindOperation 按请求的 method 和 path 查找接口, 返回匹配的路径模板和 operation.
+// 字面量片段多的路径模板优先; 匹配不到时去掉 servers 中声明的 base path 再匹配.
+func FindOperation(v3 *openapi3.Swagger, method, path string) (string, *openapi3.Operation) {
+	candidates := []string{path}
+	for _, server := range v3.Servers {
+		if server == nil {
+			continue
+		}
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); base != "" && strings.HasPrefix(path, base+"/") {
+			candidates = append(candidates, strings.TrimPrefix(path, base))
+		}
+	}
+
+	for _, candidate := range candidates {
+		var (
+			matched  string
+			literals = -1
+		)
+		for template, pathItem := range v3.Paths {
+			if pathItem == nil || pathItem.GetOperation(method) == nil {
+				continue
+			}
+			n, ok := matchPathTemplate(template, candidate)
+			if !ok {
+				continue
+			}
+			if n > literals || n == literals && template < matched {
+				matched, literals = template, n
+			}
+		}
+		if matched != "" {
+			return matched, v3.Paths[matched].GetOperation(method)
+		}
+	}
+	return "", nil
+}
+
+// matchPathTemplate 判断 path 是否匹配路径模板, 并返回模板中字面量片段的数量
+func matchPathTemplate(template, path string) (int, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return 0, false
+	}
+	var literals int
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// Mock 为 operation 生成 mock 响应.
+// status 为空时取声明的最小的 2xx 状态码, 没有 2xx 时取 default 或最小的状态码;
+// accept 为调用方的 Accept 头, 按其选择响应的 content type, 没有匹配时 json 优先.
+func Mock(operation *openapi3.Operation, status, accept string) (*MockResponse, error) {
+	if len(operation.Responses) == 0 {
+		return &MockResponse{StatusCode: http.StatusOK}, nil
+	}
+
+	key, code, err := mockStatus(operation.Responses, status)
+	if err != nil {
+		return nil, err
+	}
+	var mock = MockResponse{StatusCode: code}
+
+	ref := operation.Responses[key]
+	if ref == nil || ref.Value == nil || len(ref.Value.Content) == 0 {
+		return &mock, nil
+	}
+
+	contentType := mockContentType(ref.Value.Content, accept)
+	mock.ContentType = contentType
+	example := oasconv.MediaTypeExample(ref.Value.Content[contentType])
+	if example == nil {
+		return &mock, nil
+	}
+	switch v := example.(type) {
+	case string:
+		if strings.Contains(contentType, "json") && !json.Valid([]byte(v)) {
+			mock.Body, _ = json.Marshal(v)
+		} else {
+			mock.Body = []byte(v)
+		}
+	case json.RawMessage:
+		mock.Body = v
+	default:
+		if mock.Body, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &mock, nil
+}
+
+// mockStatus 返回响应在 responses 中的 key 及实际的状态码
+func mockStatus(responses openapi3.Responses, status string) (string, int, error) {
+	if status != "" {
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 100 || code > 599 {
+			return "", 0, fmt.Errorf("invalid %s: %s", MockStatusHeader, status)
+		}
+		if _, ok := responses[status]; ok {
+			return status, code, nil
+		}
+		if _, ok := responses["default"]; ok {
+			return "default", code, nil
+		}
+		return "", 0, fmt.Errorf("status %s is not declared", status)
+	}
+
+	var codes []int
+	for key := range responses {
+		if code, err := strconv.Atoi(key); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return strconv.Itoa(code), code, nil
+		}
+	}
+	if _, ok := responses["default"]; ok {
+		return "default", http.StatusOK, nil
+	}
+	if len(codes) > 0 {
+		return strconv.Itoa(codes[0]), codes[0], nil
+	}
+	// 只声明了 2XX 这样的范围
+	var keys []string
+	for key := range responses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	code, _ := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(keys[0]), "X", "0"))
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return keys[0], code, nil
+}
+
+func mockContentType(content openapi3.Content, accept string) string {
+	var contentTypes []string
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	for _, item := range strings.Split(accept, ",") {
+		accepted, _, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil || accepted == "*/*" {
+			continue
+		}
+		for _, contentType := range contentTypes {
+			if matchMediaRange(accepted, contentType) {
+				return contentType
+			}
+		}
+	}
+	for _, contentType := range contentTypes {
+		if strings.Contains(contentType, "json") {
+			return contentType
+		}
+	}
+	return contentTypes[0]
+}
+
+func matchMediaRange(accepted, contentType string) bool {
+	if strings.HasSuffix(accepted, "/*") {
+		return strings.HasPrefix(contentType, strings.TrimSuffix(accepted, "*"))
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return accepted == contentType
+	}
+	return accepted == mediaType
+}
+
+// MockLatency 返回 mock 响应的延迟, 调用方指定的延迟优先于 operation 上声明的延迟, 最大为 MaxMockLatency
+func MockLatency(operation *openapi3.Operation, latency string) (time.Duration, error) {
+	if latency == "" {
+		if raw, ok := operation.Extensions[MockLatencyExtension].(json.RawMessage); ok {
+			var ms json.Number
+			if err := json.Unmarshal(raw, &ms); err != nil {
+				return 0, fmt.Errorf("invalid %s: %s", MockLatencyExtension, string(raw))
+			}
+			latency = ms.String()
+		}
+	}
+	if latency == "" {
+		return 0, nil
+	}
+	ms, err := strconv.ParseUint(latency, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mock latency: %s", latency)
+	}
+	d := time.Duration(ms) * time.Millisecond
+	if d > MaxMockLatency {
+		d = MaxMockLatency
+	}
+	return d, nil
+}
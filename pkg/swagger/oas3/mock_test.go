@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+func TestMock(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/petstore-oas3.json")
+	assert.NoError(t, err)
+	v3, err := oas3.LoadFromData(data)
+	assert.NoError(t, err)
+
+	template, operation := oas3.FindOperation(v3, http.MethodGet, "/pet/findByStatus")
+	assert.Equal(t, "/pet/findByStatus", template)
+	template, operation = oas3.FindOperation(v3, http.MethodGet, "/v2/pet/1")
+	assert.Equal(t, "/pet/{petId}", template)
+	_, notFound := oas3.FindOperation(v3, http.MethodGet, "/not/found")
+	assert.Nil(t, notFound)
+
+	mock, err := oas3.Mock(operation, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, mock.StatusCode)
+	assert.Equal(t, "application/json", mock.ContentType)
+	var pet map[string]interface{}
+	assert.NoError(t, json.Unmarshal(mock.Body, &pet))
+	assert.Contains(t, pet, "name")
+
+	mock, err = oas3.Mock(operation, "", "application/xml;q=0.9")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/xml", mock.ContentType)
+
+	mock, err = oas3.Mock(operation, "404", "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, mock.StatusCode)
+	assert.Empty(t, mock.Body)
+
+	_, err = oas3.Mock(operation, "418", "")
+	assert.Error(t, err)
+
+	latency, err := oas3.MockLatency(operation, "1500")
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, latency)
+	operation.Extensions[oas3.MockLatencyExtension] = json.RawMessage("200")
+	latency, err = oas3.MockLatency(operation, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 200*time.Millisecond, latency)
+	latency, err = oas3.MockLatency(operation, "3600000")
+	assert.NoError(t, err)
+	assert.Equal(t, oas3.MaxMockLatency, latency)
+}
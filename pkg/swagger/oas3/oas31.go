@@ -0,0 +1,276 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/erda-project/erda/pkg/swagger/oasconv"
+)
+
+// OpenAPI 3.1 的 schema 基于 JSON Schema 2020-12, 与 3.0 不兼容的部分在加载前被转换为 3.0 的等价形式:
+//   type: [string, "null"]         => type: string, nullable: true
+//   type: [string, integer]        => oneOf: [{type: string}, {type: integer}]
+//   exclusiveMinimum: 1            => minimum: 1, exclusiveMinimum: true
+//   const: a                       => enum: [a]
+//   examples: [a, b] (schema 中)    => example: a
+// webhooks 与 3.0 中 paths 的结构一致, 保留在扩展字段中, 由 ValidateOAS3 校验.
+
+var jsonSchemaTypes = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true, "array": true, "object": true, "null": true,
+}
+
+// 值为单个 schema 的关键字
+var schemaKeywords = map[string]bool{
+	"items": true, "additionalProperties": true, "not": true, "contains": true, "propertyNames": true,
+	"if": true, "then": true, "else": true, "unevaluatedItems": true, "unevaluatedProperties": true,
+}
+
+// 值为 schema 数组的关键字
+var schemaArrayKeywords = map[string]bool{
+	"allOf": true, "anyOf": true, "oneOf": true, "prefixItems": true,
+}
+
+// 值为 name -> schema 的关键字
+var schemaMapKeywords = map[string]bool{
+	"properties": true, "patternProperties": true, "$defs": true, "dependentSchemas": true,
+}
+
+// IsOAS31 判断文档是否为 OpenAPI 3.1.x
+func IsOAS31(m map[string]interface{}) bool {
+	version, ok := m["openapi"].(string)
+	return ok && strings.HasPrefix(version, "3.1")
+}
+
+// DowngradeOAS31 将 OpenAPI 3.1 文档转换为 3.0 兼容的结构, 以复用 3.0 的解析与校验
+func DowngradeOAS31(m map[string]interface{}) error {
+	return downgradeNode(m, nil, false)
+}
+
+// downgradeOAS31Data 3.1 文档返回转换后的 json, 其他文档原样返回
+func downgradeOAS31Data(data []byte) ([]byte, error) {
+	j, err := oasconv.YAMLToJSON(data)
+	if err != nil {
+		return data, nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(j, &m); err != nil || !IsOAS31(m) {
+		return data, nil
+	}
+	if err := DowngradeOAS31(m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func downgradeNode(node interface{}, path []string, isSchema bool) error {
+	switch v := node.(type) {
+	case []interface{}:
+		for i, item := range v {
+			if err := downgradeNode(item, appendPath(path, fmt.Sprintf("%d", i)), isSchema); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		if isSchema {
+			return downgradeSchema(v, path)
+		}
+		for k, child := range v {
+			switch k {
+			case "example", "examples", "default", "enum", "const":
+				// 示例和取值均为数据, 不是 schema
+				continue
+			case "schema":
+				if err := downgradeNode(child, appendPath(path, k), true); err != nil {
+					return err
+				}
+			case "schemas":
+				if err := downgradeSchemaMap(child, appendPath(path, k)); err != nil {
+					return err
+				}
+			default:
+				if err := downgradeNode(child, appendPath(path, k), false); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func downgradeSchemaMap(node interface{}, path []string) error {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for name, schema := range m {
+		if err := downgradeNode(schema, appendPath(path, name), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downgradeSchema(schema map[string]interface{}, path []string) error {
+	if err := downgradeSchemaType(schema, path); err != nil {
+		return err
+	}
+	for _, k := range []string{"exclusiveMinimum", "exclusiveMaximum"} {
+		if err := downgradeExclusiveBound(schema, k, path); err != nil {
+			return err
+		}
+	}
+	if c, ok := schema["const"]; ok {
+		if _, ok := schema["enum"]; !ok {
+			schema["enum"] = []interface{}{c}
+		}
+		delete(schema, "const")
+	}
+	if examples, ok := schema["examples"].([]interface{}); ok {
+		if _, ok := schema["example"]; !ok && len(examples) > 0 {
+			schema["example"] = examples[0]
+		}
+		delete(schema, "examples")
+	}
+
+	for k, child := range schema {
+		switch {
+		case schemaKeywords[k]:
+			if _, ok := child.(bool); ok {
+				continue
+			}
+			if err := downgradeNode(child, appendPath(path, k), true); err != nil {
+				return err
+			}
+		case schemaArrayKeywords[k]:
+			if err := downgradeNode(child, appendPath(path, k), true); err != nil {
+				return err
+			}
+		case schemaMapKeywords[k]:
+			if err := downgradeSchemaMap(child, appendPath(path, k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func downgradeSchemaType(schema map[string]interface{}, path []string) error {
+	typ, ok := schema["type"]
+	if !ok {
+		return nil
+	}
+	ve := ValidateError{path_: appendPath(path, "type")}
+	var types []string
+	switch t := typ.(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		if len(t) == 0 {
+			ve.error = "type 数组不能为空"
+			return &ve
+		}
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				ve.error = fmt.Sprintf("type 数组的元素必须是 string, 实际为 %v", item)
+				return &ve
+			}
+			types = append(types, s)
+		}
+	default:
+		ve.error = fmt.Sprintf("type 必须是 string 或 string 数组, 实际为 %v", typ)
+		return &ve
+	}
+
+	var (
+		nullable bool
+		nonNull  []string
+		seen     = make(map[string]bool)
+	)
+	for _, s := range types {
+		if !jsonSchemaTypes[s] {
+			ve.error = fmt.Sprintf("不支持的 type '%s'", s)
+			return &ve
+		}
+		if seen[s] {
+			ve.error = fmt.Sprintf("type '%s' 重复", s)
+			return &ve
+		}
+		seen[s] = true
+		if s == "null" {
+			nullable = true
+			continue
+		}
+		nonNull = append(nonNull, s)
+	}
+
+	delete(schema, "type")
+	if nullable {
+		schema["nullable"] = true
+	}
+	switch len(nonNull) {
+	case 0:
+	case 1:
+		schema["type"] = nonNull[0]
+	default:
+		sort.Strings(nonNull)
+		var oneOf []interface{}
+		for _, s := range nonNull {
+			oneOf = append(oneOf, map[string]interface{}{"type": s})
+		}
+		if existing, ok := schema["oneOf"]; ok {
+			// 已有 oneOf 时, 两个约束需要同时满足
+			schema["allOf"] = append(toSlice(schema["allOf"]), map[string]interface{}{"oneOf": existing})
+		}
+		schema["oneOf"] = oneOf
+	}
+	return nil
+}
+
+func downgradeExclusiveBound(schema map[string]interface{}, key string, path []string) error {
+	v, ok := schema[key]
+	if !ok {
+		return nil
+	}
+	switch v.(type) {
+	case bool:
+		// 3.0 的写法, 保持不变
+		return nil
+	case float64:
+	default:
+		ve := ValidateError{path_: appendPath(path, key), error: fmt.Sprintf("%s 必须是 number, 实际为 %v", key, v)}
+		return &ve
+	}
+	bound := "minimum"
+	if key == "exclusiveMaximum" {
+		bound = "maximum"
+	}
+	schema[bound] = v
+	schema[key] = true
+	return nil
+}
+
+func toSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+func appendPath(path []string, elem string) []string {
+	return append(append([]string{}, path...), elem)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oas3_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+)
+
+func TestLoadOAS31(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/petstore-oas31.yaml")
+	assert.NoError(t, err)
+
+	v3, err := oas3.LoadFromData(data)
+	assert.NoError(t, err)
+	assert.NoError(t, oas3.ValidateOAS3(context.TODO(), *v3))
+
+	pet := v3.Components.Schemas["Pet"].Value
+	tag := pet.Properties["tag"].Value
+	assert.Equal(t, "string", tag.Type)
+	assert.True(t, tag.Nullable)
+	assert.Equal(t, 2, len(pet.Properties["age"].Value.OneOf))
+	assert.Equal(t, []interface{}{"pet"}, pet.Properties["kind"].Value.Enum)
+	assert.Equal(t, "kitty", pet.Properties["name"].Value.Example)
+
+	petID := v3.Paths["/pets/{petId}"].Get.Parameters[0].Value.Schema.Value
+	assert.True(t, petID.ExclusiveMin)
+	assert.Equal(t, float64(0), *petID.Min)
+
+	assert.Contains(t, v3.Extensions, "webhooks")
+}
+
+func TestLoadOAS31InvalidType(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/petstore-oas31.yaml")
+	assert.NoError(t, err)
+	data = []byte(strings.Replace(string(data), `type: [string, "null"]`, `type: [string, text]`, 1))
+
+	_, err = oas3.LoadFromData(data)
+	ve, ok := err.(*oas3.ValidateError)
+	assert.True(t, ok)
+	assert.Equal(t, "components.schemas.Pet.properties.tag.type", ve.Path())
+}
+
+func TestValidateOAS31Webhooks(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/petstore-oas31.yaml")
+	assert.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "#/components/schemas/Pet'\n      responses", "#/components/schemas/Dog'\n      responses", 1))
+
+	v3, err := oas3.LoadFromData(data)
+	assert.NoError(t, err)
+	err = oas3.ValidateOAS3(context.TODO(), *v3)
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "webhooks"))
+}
+
+func TestLoadOAS30Unchanged(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/petstore-oas3.json")
+	assert.NoError(t, err)
+	_, err = oas3.LoadFromData(data)
+	assert.NoError(t, err)
+}
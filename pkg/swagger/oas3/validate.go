@@ -16,6 +16,7 @@ package oas3
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -41,6 +42,16 @@ func (e *ValidateError) Error() string {
 	return strings.Join(e.path_, ".") + ": " + e.error
 }
 
+// Path 出错的位置, eg: components.schemas.Pet.type
+func (e *ValidateError) Path() string {
+	return strings.Join(e.path_, ".")
+}
+
+// Reason 出错的原因
+func (e *ValidateError) Reason() string {
+	return e.error
+}
+
 func (e *ValidateError) Wrap(err error) error {
 	switch err.(type) {
 	case nil:
@@ -106,6 +117,56 @@ func ValidateOAS3(ctx context.Context, oas3 openapi3.Swagger) error {
 		}
 	}
 
+	// 校验 webhooks (OpenAPI 3.1)
+	ve.path_ = []string{"webhooks"}
+	if err := ValidateWebhooks(ctx, oas3); err != nil {
+		return ve.Wrap(err)
+	}
+
+	return nil
+}
+
+// ValidateWebhooks 校验 OpenAPI 3.1 的 webhooks, 其结构为 name -> PathItem, 加载时保留在扩展字段中
+func ValidateWebhooks(ctx context.Context, oas3 openapi3.Swagger) error {
+	webhooks, ok := oas3.Extensions["webhooks"]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(webhooks)
+	if err != nil {
+		return err
+	}
+	var items map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return errors.Errorf("webhooks 格式错误: %v", err)
+	}
+
+	// 借助 paths 加载 webhooks, 以解析其中对 components 的引用
+	var names = make(map[string]string, len(items))
+	var paths = make(map[string]interface{}, len(items))
+	for name, item := range items {
+		names["/"+name] = name
+		paths["/"+name] = item
+	}
+	doc, err := json.Marshal(map[string]interface{}{
+		"openapi":    oas3.OpenAPI,
+		"info":       oas3.Info,
+		"components": oas3.Components,
+		"paths":      paths,
+	})
+	if err != nil {
+		return err
+	}
+	loaded, err := openapi3.NewSwaggerLoader().LoadSwaggerFromData(doc)
+	if err != nil {
+		return errors.Errorf("webhooks 格式错误: %v", err)
+	}
+	for path, item := range loaded.Paths {
+		var ve = ValidateError{path_: []string{names[path]}}
+		if err := ValidatePathItem(ctx, item); err != nil {
+			return ve.Wrap(err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oasconv
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// 生成示例时 schema 的最大展开深度, 避免循环引用
+const exampleMaxDepth = 8
+
+// SchemaExample 为 schema 生成示例值, 不修改 schema 本身.
+// 与 oas3.GenExample 不同, 生成的 object 和 array 示例为 Go 值而非 JSON 文本.
+func SchemaExample(schema *openapi3.Schema) interface{} {
+	return schemaExample(schema, 0)
+}
+
+// MediaTypeExample 依次使用 media type 的 example, examples 中按名称排序的第一个, schema 生成示例值
+func MediaTypeExample(mediaType *openapi3.MediaType) interface{} {
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+	var names []string
+	for name, example := range mediaType.Examples {
+		if example != nil && example.Value != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return mediaType.Examples[names[0]].Value.Value
+	}
+	if mediaType.Schema != nil {
+		return SchemaExample(mediaType.Schema.Value)
+	}
+	return nil
+}
+
+// schemaExample 依次使用 schema 的 example, default, enum 生成示例值, 都没有时按类型生成
+func schemaExample(schema *openapi3.Schema, depth int) interface{} {
+	if schema == nil || depth > exampleMaxDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		if len(refs) == 0 {
+			continue
+		}
+		// allOf 合并各个 object 的属性, oneOf/anyOf 取第一个
+		merged := make(map[string]interface{})
+		for _, ref := range refs {
+			if ref == nil {
+				continue
+			}
+			v := schemaExample(ref.Value, depth+1)
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return v
+			}
+			for k, item := range m {
+				merged[k] = item
+			}
+			if len(schema.AllOf) == 0 {
+				break
+			}
+		}
+		return merged
+	}
+
+	switch schema.Type {
+	case "boolean":
+		return true
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "string":
+		return stringExample(schema.Format)
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{schemaExample(schema.Items.Value, depth+1)}
+	default:
+		m := make(map[string]interface{})
+		for name, ref := range schema.Properties {
+			if ref == nil {
+				continue
+			}
+			m[name] = schemaExample(ref.Value, depth+1)
+		}
+		return m
+	}
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date":
+		return "2006-01-02"
+	case "date-time":
+		return "2006-01-02T15:04:05Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oasconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	PostmanSchemaV21  = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	PostmanBaseURLVar = "baseUrl"
+)
+
+// PostmanCollection Postman Collection v2.1
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []*PostmanItem    `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// PostmanItem 为 folder 时 Item 非空, 为 request 时 Request 非空
+type PostmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []*PostmanItem  `json:"item,omitempty"`
+	Request     *PostmanRequest `json:"request,omitempty"`
+	Response    []interface{}   `json:"response,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method      string       `json:"method"`
+	Header      []PostmanKV  `json:"header"`
+	URL         PostmanURL   `json:"url"`
+	Body        *PostmanBody `json:"body,omitempty"`
+	Description string       `json:"description,omitempty"`
+}
+
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []PostmanKV       `json:"query,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode       string                 `json:"mode"`
+	Raw        string                 `json:"raw,omitempty"`
+	URLEncoded []PostmanKV            `json:"urlencoded,omitempty"`
+	FormData   []PostmanKV            `json:"formdata,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+type PostmanKV struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type PostmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+var postmanMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodConnect,
+}
+
+// OAS3ConvToPostman 将 OpenAPI 3 文档转换为 Postman Collection v2.1, 接口按第一个 tag 分组.
+// 文档的 servers 转换为集合变量 baseUrl, 有多个 server 时取第一个, 其余写在变量描述中.
+// 转换前文档中的引用应当已被加载器解析.
+func OAS3ConvToPostman(v3 *openapi3.Swagger, name string) (*PostmanCollection, error) {
+	if v3 == nil {
+		return nil, fmt.Errorf("swagger is nil")
+	}
+	if name == "" && v3.Info != nil {
+		name = v3.Info.Title
+	}
+
+	var collection = PostmanCollection{
+		Info:     PostmanInfo{Name: name, Schema: PostmanSchemaV21},
+		Variable: []PostmanVariable{postmanBaseURL(v3.Servers)},
+	}
+	if v3.Info != nil {
+		collection.Info.Description = v3.Info.Description
+	}
+
+	var (
+		folders     = make(map[string]*PostmanItem)
+		folderNames []string
+	)
+	var paths []string
+	for path := range v3.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := v3.Paths[path]
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range postmanMethods {
+			operation := pathItem.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+			item := postmanRequestItem(path, method, pathItem, operation)
+			if len(operation.Tags) == 0 {
+				collection.Item = append(collection.Item, item)
+				continue
+			}
+			tag := operation.Tags[0]
+			folder, ok := folders[tag]
+			if !ok {
+				folder = &PostmanItem{Name: tag}
+				folders[tag] = folder
+				folderNames = append(folderNames, tag)
+			}
+			folder.Item = append(folder.Item, item)
+		}
+	}
+
+	// folder 在前, 未分组的接口在后
+	var folderItems []*PostmanItem
+	for _, tag := range v3.Tags {
+		if folder, ok := folders[tag.Name]; ok {
+			folder.Description = tag.Description
+			folderItems = append(folderItems, folder)
+			delete(folders, tag.Name)
+		}
+	}
+	for _, tag := range folderNames {
+		if folder, ok := folders[tag]; ok {
+			folderItems = append(folderItems, folder)
+		}
+	}
+	collection.Item = append(folderItems, collection.Item...)
+
+	return &collection, nil
+}
+
+func postmanBaseURL(servers openapi3.Servers) PostmanVariable {
+	var urls []string
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		url := server.URL
+		for k, v := range server.Variables {
+			if v != nil {
+				url = strings.ReplaceAll(url, "{"+k+"}", fmt.Sprintf("%v", v.Default))
+			}
+		}
+		urls = append(urls, strings.TrimSuffix(url, "/"))
+	}
+	variable := PostmanVariable{Key: PostmanBaseURLVar}
+	if len(urls) > 0 {
+		variable.Value = urls[0]
+	}
+	if len(urls) > 1 {
+		variable.Description = "servers: " + strings.Join(urls, ", ")
+	}
+	return variable
+}
+
+func postmanRequestItem(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) *PostmanItem {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = method + " " + path
+	}
+
+	var request = PostmanRequest{
+		Method:      method,
+		Header:      []PostmanKV{},
+		Description: operation.Description,
+		URL: PostmanURL{
+			Host: []string{"{{" + PostmanBaseURLVar + "}}"},
+		},
+	}
+
+	// path 参数转换为 Postman 的 :param 形式
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = ":" + strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+		request.URL.Path = append(request.URL.Path, segment)
+	}
+
+	// operation 上的参数覆盖 path item 上的同名参数
+	var params = make(map[string]*openapi3.Parameter)
+	var keys []string
+	for _, parameters := range []openapi3.Parameters{pathItem.Parameters, operation.Parameters} {
+		for _, ref := range parameters {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			key := ref.Value.In + "." + ref.Value.Name
+			if _, ok := params[key]; !ok {
+				keys = append(keys, key)
+			}
+			params[key] = ref.Value
+		}
+	}
+	for _, key := range keys {
+		param := params[key]
+		value := postmanParamExample(param)
+		switch param.In {
+		case openapi3.ParameterInPath:
+			request.URL.Variable = append(request.URL.Variable, PostmanVariable{
+				Key: param.Name, Value: value, Description: param.Description,
+			})
+		case openapi3.ParameterInQuery:
+			request.URL.Query = append(request.URL.Query, PostmanKV{
+				Key: param.Name, Value: value, Description: param.Description, Disabled: !param.Required,
+			})
+		case openapi3.ParameterInHeader:
+			request.Header = append(request.Header, PostmanKV{
+				Key: param.Name, Value: value, Description: param.Description, Disabled: !param.Required,
+			})
+		case openapi3.ParameterInCookie:
+			request.Header = append(request.Header, PostmanKV{
+				Key: "Cookie", Value: param.Name + "=" + value, Description: param.Description, Disabled: !param.Required,
+			})
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		contentType, body := postmanBody(operation.RequestBody.Value.Content)
+		if body != nil {
+			request.Body = body
+			request.Header = append(request.Header, PostmanKV{Key: "Content-Type", Value: contentType})
+		}
+	}
+
+	request.URL.Raw = postmanRawURL(request.URL)
+	return &PostmanItem{Name: name, Request: &request, Response: []interface{}{}}
+}
+
+func postmanRawURL(url PostmanURL) string {
+	raw := strings.Join(url.Host, "")
+	if len(url.Path) > 0 {
+		raw += "/" + strings.Join(url.Path, "/")
+	}
+	var query []string
+	for _, kv := range url.Query {
+		if !kv.Disabled {
+			query = append(query, kv.Key+"="+kv.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
+
+// postmanBody 按 json, form, 其他的优先级选择一种 content type 生成请求体
+func postmanBody(content openapi3.Content) (string, *PostmanBody) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	var contentTypes []string
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Slice(contentTypes, func(i, j int) bool {
+		return postmanContentTypeRank(contentTypes[i]) < postmanContentTypeRank(contentTypes[j]) ||
+			postmanContentTypeRank(contentTypes[i]) == postmanContentTypeRank(contentTypes[j]) && contentTypes[i] < contentTypes[j]
+	})
+	contentType := contentTypes[0]
+	mediaType := content[contentType]
+	if mediaType == nil {
+		return "", nil
+	}
+
+	example := MediaTypeExample(mediaType)
+	switch {
+	case strings.Contains(contentType, "x-www-form-urlencoded"), strings.Contains(contentType, "form-data"):
+		var kvs []PostmanKV
+		if m, ok := example.(map[string]interface{}); ok {
+			var keys []string
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				kvs = append(kvs, PostmanKV{Key: k, Value: postmanString(m[k]), Type: "text"})
+			}
+		}
+		if strings.Contains(contentType, "form-data") {
+			return contentType, &PostmanBody{Mode: "formdata", FormData: kvs}
+		}
+		return contentType, &PostmanBody{Mode: "urlencoded", URLEncoded: kvs}
+	case strings.Contains(contentType, "json"):
+		data, _ := json.MarshalIndent(example, "", "  ")
+		return contentType, &PostmanBody{
+			Mode:    "raw",
+			Raw:     string(data),
+			Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+		}
+	default:
+		return contentType, &PostmanBody{Mode: "raw", Raw: postmanString(example)}
+	}
+}
+
+func postmanContentTypeRank(contentType string) int {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return 0
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		return 1
+	case strings.Contains(contentType, "form-data"):
+		return 2
+	default:
+		return 3
+	}
+}
+
+func postmanParamExample(param *openapi3.Parameter) string {
+	if param.Example != nil {
+		return postmanString(param.Example)
+	}
+	for _, example := range param.Examples {
+		if example != nil && example.Value != nil {
+			return postmanString(example.Value.Value)
+		}
+	}
+	if param.Schema != nil && param.Schema.Value != nil {
+		schema := param.Schema.Value
+		if schema.Example != nil {
+			return postmanString(schema.Example)
+		}
+		if schema.Default != nil {
+			return postmanString(schema.Default)
+		}
+		if len(schema.Enum) > 0 {
+			return postmanString(schema.Enum[0])
+		}
+	}
+	return ""
+}
+
+func postmanString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oasconv_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/erda-project/erda/pkg/swagger/oas3"
+	"github.com/erda-project/erda/pkg/swagger/oasconv"
+)
+
+const petstoreOAS3 = "../oas3/testdata/petstore-oas3.json"
+
+// go test -v -run TestOAS3ConvToPostman
+func TestOAS3ConvToPostman(t *testing.T) {
+	data, err := ioutil.ReadFile(petstoreOAS3)
+	if err != nil {
+		t.Fatalf("failed to ReadFile, filename: %s, err: %v", petstoreOAS3, err)
+	}
+	v3, err := oas3.LoadFromData(data)
+	if err != nil {
+		t.Fatalf("failed to LoadFromData, filename: %s, err: %v", petstoreOAS3, err)
+	}
+	v3.Servers = append(v3.Servers, &openapi3.Server{URL: "https://{env}.petstore.io/v2", Variables: map[string]*openapi3.ServerVariable{
+		"env": {Default: "staging"},
+	}})
+
+	collection, err := oasconv.OAS3ConvToPostman(v3, "")
+	if err != nil {
+		t.Fatalf("failed to OAS3ConvToPostman: %v", err)
+	}
+	if collection.Info.Schema != oasconv.PostmanSchemaV21 {
+		t.Fatalf("schema error: %s", collection.Info.Schema)
+	}
+	if len(collection.Variable) != 1 || collection.Variable[0].Value != "https://petstore.swagger.io/v2" {
+		t.Fatalf("baseUrl variable error: %+v", collection.Variable)
+	}
+	if collection.Variable[0].Description != "servers: https://petstore.swagger.io/v2, https://staging.petstore.io/v2" {
+		t.Fatalf("baseUrl description error: %s", collection.Variable[0].Description)
+	}
+
+	var pet *oasconv.PostmanItem
+	for _, item := range collection.Item {
+		if item.Name == "pet" {
+			pet = item
+		}
+	}
+	if pet == nil {
+		t.Fatal("folder pet not found")
+	}
+
+	var getPet, addPet *oasconv.PostmanRequest
+	for _, item := range pet.Item {
+		switch item.Name {
+		case "Find pet by ID":
+			getPet = item.Request
+		case "Add a new pet to the store":
+			addPet = item.Request
+		}
+	}
+	if getPet == nil || addPet == nil {
+		t.Fatal("requests not found")
+	}
+	if getPet.URL.Raw != "{{baseUrl}}/pet/:petId" {
+		t.Fatalf("url error: %s", getPet.URL.Raw)
+	}
+	if len(getPet.URL.Variable) != 1 || getPet.URL.Variable[0].Key != "petId" {
+		t.Fatalf("path variable error: %+v", getPet.URL.Variable)
+	}
+	if addPet.Body == nil || addPet.Body.Mode != "raw" {
+		t.Fatalf("body error: %+v", addPet.Body)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(addPet.Body.Raw), &body); err != nil {
+		t.Fatalf("body is not json: %v", err)
+	}
+	if _, ok := body["name"]; !ok {
+		t.Fatalf("body example has no name: %s", addPet.Body.Raw)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmind
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+var (
+	xmlNameContent  = xml.Name{Local: "xmap-content"}
+	xmlNameSheet    = xml.Name{Local: "sheet"}
+	xmlNameTopic    = xml.Name{Local: "topic"}
+	xmlNameTitle    = xml.Name{Local: "title"}
+	xmlNameChildren = xml.Name{Local: "children"}
+	xmlNameTopics   = xml.Name{Local: "topics"}
+)
+
+// StreamWriter 流式生成 .xmind 文件
+// topic 按深度优先的顺序边生成边写入，不需要在内存中构造完整的 XMLContent
+type StreamWriter struct {
+	zw  *zip.Writer
+	enc *xml.Encoder
+	// frames 当前打开的 topic 栈，frames[0] 为根节点
+	frames []streamFrame
+}
+
+type streamFrame struct {
+	// hasChildren 是否已经写入 <children><topics>
+	hasChildren bool
+}
+
+// NewStreamWriter 写入固定的 manifest、meta 文件，并以 rootTitle 打开根节点
+func NewStreamWriter(w io.Writer, rootTitle string) (*StreamWriter, error) {
+	zw := zip.NewWriter(w)
+	fixedFiles := []struct {
+		name    string
+		content string
+	}{
+		{"META-INF/", ""},
+		{"META-INF/manifest.xml", fixedManifestXmlFileContent},
+		{"meta.xml", fixedMetaXmlFileContent},
+	}
+	for _, file := range fixedFiles {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s, err: %v", file.name, err)
+		}
+		if _, err := io.WriteString(fw, file.content); err != nil {
+			return nil, fmt.Errorf("failed to write %s, err: %v", file.name, err)
+		}
+	}
+	cw, err := zw.Create("content.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content.xml, err: %v", err)
+	}
+
+	s := &StreamWriter{zw: zw, enc: xml.NewEncoder(cw)}
+	if err := s.enc.EncodeToken(xml.StartElement{Name: xmlNameContent}); err != nil {
+		return nil, err
+	}
+	if err := s.enc.EncodeToken(xml.StartElement{Name: xmlNameSheet}); err != nil {
+		return nil, err
+	}
+	if err := s.openTopic(rootTitle); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// StartTopic 在当前节点下打开一个子节点，后续写入的节点都挂在该节点下，直到调用 EndTopic
+func (s *StreamWriter) StartTopic(title string) error {
+	if err := s.beginChild(); err != nil {
+		return err
+	}
+	return s.openTopic(title)
+}
+
+// EndTopic 关闭最近一次 StartTopic 打开的节点
+func (s *StreamWriter) EndTopic() error {
+	if len(s.frames) <= 1 {
+		return fmt.Errorf("no opened topic to end")
+	}
+	return s.closeTopic()
+}
+
+// WriteTopic 在当前节点下写入一棵完整的子树
+func (s *StreamWriter) WriteTopic(topic *XMLTopic) error {
+	if err := s.beginChild(); err != nil {
+		return err
+	}
+	return s.enc.EncodeElement(topic, xml.StartElement{Name: xmlNameTopic})
+}
+
+// Close 关闭所有打开的节点并完成 .xmind 文件
+func (s *StreamWriter) Close() error {
+	for len(s.frames) > 0 {
+		if err := s.closeTopic(); err != nil {
+			return err
+		}
+	}
+	if err := s.enc.EncodeToken(xml.EndElement{Name: xmlNameSheet}); err != nil {
+		return err
+	}
+	if err := s.enc.EncodeToken(xml.EndElement{Name: xmlNameContent}); err != nil {
+		return err
+	}
+	if err := s.enc.Flush(); err != nil {
+		return err
+	}
+	return s.zw.Close()
+}
+
+func (s *StreamWriter) openTopic(title string) error {
+	if err := s.enc.EncodeToken(xml.StartElement{Name: xmlNameTopic}); err != nil {
+		return err
+	}
+	if err := s.enc.EncodeElement(title, xml.StartElement{Name: xmlNameTitle}); err != nil {
+		return err
+	}
+	s.frames = append(s.frames, streamFrame{})
+	return nil
+}
+
+func (s *StreamWriter) closeTopic() error {
+	top := s.frames[len(s.frames)-1]
+	if top.hasChildren {
+		if err := s.enc.EncodeToken(xml.EndElement{Name: xmlNameTopics}); err != nil {
+			return err
+		}
+		if err := s.enc.EncodeToken(xml.EndElement{Name: xmlNameChildren}); err != nil {
+			return err
+		}
+	}
+	if err := s.enc.EncodeToken(xml.EndElement{Name: xmlNameTopic}); err != nil {
+		return err
+	}
+	s.frames = s.frames[:len(s.frames)-1]
+	return nil
+}
+
+// beginChild 当前节点写入第一个子节点前，先写入 <children><topics type="attached">
+func (s *StreamWriter) beginChild() error {
+	top := &s.frames[len(s.frames)-1]
+	if top.hasChildren {
+		return nil
+	}
+	if err := s.enc.EncodeToken(xml.StartElement{Name: xmlNameChildren}); err != nil {
+		return err
+	}
+	if err := s.enc.EncodeToken(xml.StartElement{
+		Name: xmlNameTopics,
+		Attr: []xml.Attr{{Name: xml.Name{Local: "type"}, Value: string(TopicsTypeAttached)}},
+	}); err != nil {
+		return err
+	}
+	top.hasChildren = true
+	return nil
+}
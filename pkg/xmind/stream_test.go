@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Terminus, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmind
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sw, err := NewStreamWriter(&buf, "root")
+	assert.NoError(t, err)
+
+	assert.NoError(t, sw.StartTopic("dir1"))
+	assert.NoError(t, sw.StartTopic("dir2"))
+	tc := &XMLTopic{Title: "tc:P3__case"}
+	tc.AddAttachedChildTopic("step").AddAttachedChildTopic("result")
+	assert.NoError(t, sw.WriteTopic(tc))
+	assert.NoError(t, sw.EndTopic())
+	assert.NoError(t, sw.EndTopic())
+	assert.NoError(t, sw.StartTopic("empty"))
+	assert.NoError(t, sw.Close())
+	assert.Error(t, (&StreamWriter{frames: []streamFrame{{}}}).EndTopic())
+
+	// 与 xml.Marshal 生成的内容一致
+	expected := XMLContent{Sheet: XMLSheet{Topic: &XMLTopic{Title: "root"}}}
+	dir2 := expected.Sheet.Topic.AddAttachedChildTopic("dir1").AddAttachedChildTopic("dir2")
+	dir2.Children = &XMLTopicChildren{TypedTopics: &XMLTypedTopics{Type: TopicsTypeAttached, Topics: []*XMLTopic{tc}}}
+	expected.Sheet.Topic.AddAttachedChildTopic("empty")
+	expectedBytes, err := xml.Marshal(&expected)
+	assert.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name != "content.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		var content bytes.Buffer
+		_, err = content.ReadFrom(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expectedBytes), content.String())
+	}
+	assert.ElementsMatch(t, []string{"META-INF/", "META-INF/manifest.xml", "meta.xml", "content.xml"}, names)
+}